@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/lisanmuaddib/agent-go/internal/personality/traits"
+	agent "github.com/lisanmuaddib/agent-go/pkg"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// accountRuntime holds the per-account clients and state for one bot persona
+type accountRuntime struct {
+	account       config.AccountConfig
+	twitterClient *twitter.TwitterClient
+	tweetStore    *memory.TweetStore
+	personality   traits.Persona
+}
+
+// setupAccount initializes the Twitter client, tweet store partition and persona for
+// an account. When it is the only account, tweets stored before per-account
+// partitioning are claimed for it.
+func setupAccount(ctx context.Context, log *logrus.Logger, database *gorm.DB, cfg *config.Config, account config.AccountConfig, bus *events.Bus, only bool) (*accountRuntime, error) {
+	accountLog := log.WithField("account", account.Name)
+	accountLog.Info("Initializing account")
+
+	twitterOpts := []twitter.ClientOption{
+		twitter.WithEventBus(bus),
+		twitter.WithRequestLogging(twitter.RequestLogOptions{
+			SampleRate: cfg.Debug.HTTPLogSample,
+			BodyLimit:  cfg.Debug.HTTPLogBodyLimit,
+		}),
+	}
+	if budget := account.Twitter.Budget; budget.Enabled() {
+		shares := budget.Shares
+		if len(shares) == 0 {
+			shares = twitter.DefaultBudgetShares
+		}
+		twitterOpts = append(twitterOpts, twitter.WithBudget(twitter.NewBudget(budget.Requests, budget.Window, shares)))
+	}
+	if account.Twitter.OAuth2.Enabled() {
+		tokens, err := oauth2Tokens(database, account)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errTwitterConfig, err)
+		}
+		twitterOpts = append(twitterOpts, twitter.WithOAuth2Tokens(tokens))
+	}
+	if cfg.Debug.HTTPRecord {
+		path := filepath.Join(cfg.Debug.HTTPRecordDir, account.Name+".jsonl")
+		recorder, err := twitter.NewFileRecorder(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errTwitterConfig, err)
+		}
+		twitterOpts = append(twitterOpts, twitter.WithHTTPRecorder(recorder))
+		accountLog.WithField("path", path).Warn("Recording Twitter API requests and responses")
+	}
+
+	twitterClient, botID, err := agent.ConnectTwitter(ctx, log, account.Twitter, twitterOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tweetStore, err := memory.NewTweetStore(log, database, botID, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errTweetStore, err)
+	}
+	tweetStore.SetIdentity(account.DisplayName, account.Username)
+	tweetStore.SetSpamThreshold(cfg.Filters.SpamThreshold)
+	tweetStore.SetReplyPriority(memory.ReplyPriority{
+		Weights: memory.PriorityWeights{
+			Recency:   cfg.Replies.Priority.Recency,
+			Followers: cfg.Replies.Priority.Followers,
+			Heat:      cfg.Replies.Priority.Heat,
+			Addressed: cfg.Replies.Priority.Addressed,
+		},
+		FollowerSaturation: cfg.Replies.PriorityFollowers,
+	})
+
+	if cfg.Agent.DryRun {
+		twitterClient.SetDryRun(tweetStore)
+		accountLog.Warn("Dry run: tweets are recorded in dry_run_posts instead of being posted")
+	}
+
+	if only {
+		claimed, err := tweetStore.ClaimUnassignedTweets(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errTweetStore, err)
+		}
+		if claimed > 0 {
+			accountLog.WithField("tweets", claimed).Info("Assigned previously stored tweets to account")
+		}
+	}
+
+	personality, err := traits.LoadPersona(account.PersonalityFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errPersonality, err)
+	}
+
+	accountLog.WithField("bot_id", botID).Info("Account initialized")
+
+	return &accountRuntime{
+		account:       account,
+		twitterClient: twitterClient,
+		tweetStore:    tweetStore,
+		personality:   personality,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/ipfs"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// setupIPFS creates the IPFS client shared by Lens and the archive, or nil
+// when no IPFS API is configured
+func setupIPFS(log *logrus.Logger, cfg *config.Config) (*ipfs.Client, error) {
+	if !cfg.IPFS.Enabled() {
+		return nil, nil
+	}
+
+	client, err := ipfs.NewClient(&ipfs.Config{
+		APIURL:     cfg.IPFS.APIURL,
+		Username:   cfg.IPFS.Username,
+		Password:   cfg.IPFS.Password,
+		Token:      cfg.IPFS.Token,
+		GatewayURL: cfg.IPFS.GatewayURL,
+		Logger:     log,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IPFS client: %w", err)
+	}
+	return client, nil
+}
+
+// setupArchivePublisher creates the publisher for the configured archive
+// backend, or nil when archiving is off
+func setupArchivePublisher(log *logrus.Logger, cfg *config.Config, pinner *ipfs.Client) (archive.Publisher, error) {
+	switch cfg.Archive.Backend {
+	case "":
+		return nil, nil
+	case "ipfs":
+		if pinner == nil {
+			return nil, fmt.Errorf("the ipfs archive backend requires ipfs.api_url")
+		}
+		log.Info("Archiving completed conversations to IPFS")
+		return archive.NewIPFSPublisher(pinner), nil
+	case "s3":
+		publisher, err := archive.NewS3Publisher(archive.S3Config{
+			Bucket:          cfg.Archive.S3Bucket,
+			Region:          cfg.Archive.S3Region,
+			Endpoint:        cfg.Archive.S3Endpoint,
+			AccessKeyID:     cfg.Archive.S3AccessKeyID,
+			SecretAccessKey: cfg.Archive.S3SecretAccessKey,
+			SessionToken:    cfg.Archive.S3SessionToken,
+			Prefix:          cfg.Archive.S3Prefix,
+			PublicURL:       cfg.Archive.S3PublicURL,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 publisher: %w", err)
+		}
+		log.WithField("bucket", cfg.Archive.S3Bucket).Info("Archiving completed conversations to S3")
+		return publisher, nil
+	default:
+		return nil, fmt.Errorf("unknown archive backend %q", cfg.Archive.Backend)
+	}
+}
+
+// setupRetention creates the retention options of the configured policy. The
+// policies are empty when every kind of row is kept forever.
+func setupRetention(log *logrus.Logger, cfg *config.Config) (actions.RetentionOptions, error) {
+	retention := cfg.Retention
+	options := actions.RetentionOptions{BatchSize: retention.BatchSize}
+	if !retention.Enabled() {
+		return options, nil
+	}
+	options.Policies = []actions.RetentionPolicy{
+		{Table: memory.RetainTweets, MaxAge: retention.Conversations},
+		{Table: memory.RetainJudgments, MaxAge: retention.Judgments},
+		{Table: memory.RetainAnalytics, MaxAge: retention.Analytics},
+		{Table: memory.RetainLatency, MaxAge: retention.Analytics},
+		{Table: memory.RetainStages, MaxAge: retention.Analytics},
+		{Table: memory.RetainQuota, MaxAge: retention.Quota},
+	}
+
+	switch retention.Sink {
+	case "":
+		log.Warn("Pruning expired rows without archiving them; set retention.sink to keep a copy")
+	case "file":
+		sink, err := archive.NewFilePublisher(retention.Dir)
+		if err != nil {
+			return options, fmt.Errorf("failed to create retention sink: %w", err)
+		}
+		options.Sink = sink
+	case "s3":
+		s3 := archive.S3Config{
+			Bucket:          retention.S3Bucket,
+			Region:          retention.S3Region,
+			Endpoint:        retention.S3Endpoint,
+			AccessKeyID:     retention.S3AccessKeyID,
+			SecretAccessKey: retention.S3SecretAccessKey,
+			SessionToken:    retention.S3SessionToken,
+			Prefix:          retention.S3Prefix,
+		}
+		// Region, endpoint and credentials default to the archive bucket's
+		if s3.Region == "" {
+			s3.Region = cfg.Archive.S3Region
+			if s3.Endpoint == "" {
+				s3.Endpoint = cfg.Archive.S3Endpoint
+			}
+		}
+		if s3.AccessKeyID == "" || s3.SecretAccessKey == "" {
+			s3.AccessKeyID = cfg.Archive.S3AccessKeyID
+			s3.SecretAccessKey = cfg.Archive.S3SecretAccessKey
+			s3.SessionToken = cfg.Archive.S3SessionToken
+		}
+		sink, err := archive.NewS3Publisher(s3)
+		if err != nil {
+			return options, fmt.Errorf("failed to create retention sink: %w", err)
+		}
+		options.Sink = sink
+		log.WithField("bucket", s3.Bucket).Info("Archiving pruned rows to S3")
+	default:
+		return options, fmt.Errorf("unknown retention sink %q", retention.Sink)
+	}
+	return options, nil
+}
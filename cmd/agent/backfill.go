@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lisanmuaddib/agent-go/internal/agentconfig"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newBackfillCommand creates the backfill subcommand, which stores new
+// mentions and queues every tweet still needing a reply for the reply workers
+func newBackfillCommand(app *cli) *cobra.Command {
+	var account string
+	var skipMentions bool
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Queue every tweet still needing a reply",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			app.code = runBackfillCommand(app.log, app.cfg, account, skipMentions)
+		},
+	}
+	cmd.Flags().StringVar(&account, "account", "", "account to backfill; defaults to the first account")
+	cmd.Flags().BoolVar(&skipMentions, "skip-mentions", false, "only queue stored tweets, without fetching new mentions")
+	return cmd
+}
+
+// runBackfillCommand backfills the account's reply queue and returns the
+// process exit code
+func runBackfillCommand(log *logrus.Logger, cfg *config.Config, account string, skipMentions bool) int {
+	ctx := context.Background()
+	svc, runtime, code := startAccountCommand(ctx, log, cfg, account)
+	if code != ExitCleanShutdown {
+		return code
+	}
+	defer svc.Close(log)
+
+	actionConfig := svc.actionConfig(log, cfg, runtime)
+	if !skipMentions {
+		mentions, err := agentconfig.NewMentionsHandler(actionConfig)
+		if err != nil {
+			log.WithError(err).Error("Failed to create mentions handler")
+			return ExitConfigError
+		}
+		if err := mentions.CheckMentions(ctx); err != nil {
+			log.WithError(err).Error("Failed to fetch mentions")
+			return ExitFatalTaskError
+		}
+	}
+
+	if err := agentconfig.NewReplyWorkers(actionConfig).Backfill(ctx); err != nil {
+		log.WithError(err).Error("Failed to backfill reply queue")
+		return ExitFatalTaskError
+	}
+
+	queued, err := runtime.tweetStore.QueuedReplies(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to count queued replies")
+		return ExitCleanShutdown
+	}
+	log.WithFields(logrus.Fields{
+		"account": runtime.account.Name,
+		"queued":  queued,
+	}).Info("Reply queue backfilled")
+
+	return ExitCleanShutdown
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// cli is what every command shares: the logger, the global flags and the
+// configuration they resolve to, and the exit code the command finished with
+type cli struct {
+	log   *logrus.Logger
+	flags config.Flags
+	cfg   *config.Config
+	code  int
+}
+
+// newRootCommand creates the agent command. Without a subcommand it runs the
+// agent, like run; the other subcommands replace the agent and only need the
+// settings they use.
+func newRootCommand(app *cli) *cobra.Command {
+	root := &cobra.Command{
+		Use:   "agent",
+		Short: "Run the agent, or one of its maintenance commands",
+		// Errors are logged with the exit code they map to
+		SilenceErrors: true,
+		PersistentPreRun: func(*cobra.Command, []string) {
+			app.loadConfig()
+		},
+		Run: func(*cobra.Command, []string) {
+			runAgent(app.log, app.cfg, app.flags)
+		},
+	}
+	root.CompletionOptions.DisableDefaultCmd = true
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&app.flags.ConfigFile, "config", "", "path to a YAML config file")
+	flags.StringVar(&app.flags.LogLevel, "log-level", "", "log level (debug, info, warn, error)")
+	flags.StringVar(&app.flags.LogFormat, "log-format", "", "log format (text, json)")
+	flags.BoolVar(&app.flags.DryRun, "dry-run", false, "record tweets and transfers instead of publishing them")
+	flags.BoolVar(&app.flags.SkipPreflight, "skip-preflight", false, "start without checking the database, credentials and RPC endpoints")
+
+	root.AddCommand(
+		&cobra.Command{
+			Use:   "run",
+			Short: "Run every account's actions until a shutdown signal arrives",
+			Args:  cobra.NoArgs,
+			Run: func(*cobra.Command, []string) {
+				runAgent(app.log, app.cfg, app.flags)
+			},
+		},
+		newBackfillCommand(app),
+		newScrapeCommand(app),
+		newPostThoughtCommand(app),
+		newReplyCommand(app),
+		newWalletCommand(app),
+		newConfigCommand(app),
+		newMigrateCommand(app),
+		newModerationCommand(app),
+		newNotesCommand(app),
+		newExperimentsCommand(app),
+		newExportDatasetCommand(app),
+		newOAuth2Command(app),
+		newDryRunCommand(app),
+	)
+	return root
+}
+
+// commandGroup creates a command that only holds subcommands. Running it
+// without one, or with an unknown one, is a usage error.
+func commandGroup(use, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return fmt.Errorf("%s requires a subcommand", cmd.CommandPath())
+		},
+	}
+}
+
+// subcommand creates a command that hands its name and positional arguments
+// to run, for commands whose subcommands share one implementation
+func subcommand(use, short string, args cobra.PositionalArgs, run func(command string, args []string)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		Run: func(cmd *cobra.Command, args []string) {
+			run(cmd.Name(), args)
+		},
+	}
+}
+
+// loadConfig resolves the configuration from defaults, the config file, the
+// environment and the global flags, and applies its log settings
+func (app *cli) loadConfig() {
+	cfg, err := config.Resolve(app.flags)
+	if err != nil {
+		exitWithError(app.log, ExitConfigError, "config", "Failed to load configuration", err)
+	}
+	if level, err := logrus.ParseLevel(cfg.Log.Level); err == nil {
+		app.log.SetLevel(level)
+	}
+	formatter, err := logging.NewFormatter(cfg.Log.Format, "agent-go")
+	if err != nil {
+		exitWithError(app.log, ExitConfigError, "config", "Invalid log format", err)
+	}
+	app.log.SetFormatter(formatter)
+	app.cfg = cfg
+}
+
+// taskSettingsReloader re-reads the configuration with the same flags, so
+// that tasks enabled at runtime pick up changed intervals and restart policies
+func taskSettingsReloader(flags config.Flags) func() (config.TasksConfig, error) {
+	return func() (config.TasksConfig, error) {
+		cfg, err := config.Resolve(flags)
+		if err != nil {
+			return config.TasksConfig{}, err
+		}
+		if err := cfg.ValidateTasks(); err != nil {
+			return config.TasksConfig{}, err
+		}
+		return cfg.Tasks, nil
+	}
+}
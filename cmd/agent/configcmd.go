@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newConfigCommand creates the config subcommand
+func newConfigCommand(app *cli) *cobra.Command {
+	var offline bool
+	validate := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration and everything it connects to",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			app.code = runConfigValidateCommand(app.log, app.cfg, offline)
+		},
+	}
+	validate.Flags().BoolVar(&offline, "offline", false, "only check the settings, without connecting to anything")
+
+	cmd := commandGroup("config", "Inspect the configuration")
+	cmd.AddCommand(validate)
+	return cmd
+}
+
+// runConfigValidateCommand checks the merged configuration and, unless
+// offline, runs the preflight checks against the database, Twitter, OpenAI
+// and the wallet RPC endpoints. It returns the process exit code.
+func runConfigValidateCommand(log *logrus.Logger, cfg *config.Config, offline bool) int {
+	if offline {
+		if err := cfg.Validate(); err != nil {
+			log.WithError(err).Error("Invalid configuration")
+			return ExitConfigError
+		}
+		fmt.Printf("configuration is valid (%d account(s))\n", len(cfg.ResolvedAccounts()))
+		return ExitCleanShutdown
+	}
+
+	report := runPreflight(context.Background(), log, cfg)
+	if !report.OK() {
+		log.WithError(report.Err()).Error("Preflight checks failed")
+		return preflightExitCode(report)
+	}
+	return ExitCleanShutdown
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/dataset"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// exportDatasetOptions are the flags of the export-dataset subcommand
+type exportDatasetOptions struct {
+	botID         string
+	username      string
+	out           string
+	days          int
+	format        string
+	minEngagement int
+	contextTweets int
+	validation    float64
+	keepPII       bool
+}
+
+// newExportDatasetCommand creates the export-dataset subcommand, which writes
+// the bot's replies as fine-tuning examples to train.jsonl and val.jsonl
+func newExportDatasetCommand(app *cli) *cobra.Command {
+	var opts exportDatasetOptions
+	cmd := &cobra.Command{
+		Use:   "export-dataset --bot-id id",
+		Short: "Export the bot's replies as fine-tuning examples",
+		Args:  cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			switch {
+			case opts.days < 1:
+				return fmt.Errorf("--days must be at least 1, got %d", opts.days)
+			case opts.validation < 0 || opts.validation >= 1:
+				return fmt.Errorf("--val must be in [0, 1), got %g", opts.validation)
+			case opts.format != dataset.FormatRaw && opts.format != dataset.FormatChat:
+				return fmt.Errorf("unknown --format %q", opts.format)
+			}
+			app.code = runExportDatasetCommand(app.log, app.cfg, opts)
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.botID, "bot-id", "", "bot account whose replies are exported")
+	flags.StringVar(&opts.username, "username", "", "bot's handle, kept when handles are redacted")
+	flags.StringVar(&opts.out, "out", "data/dataset", "directory the JSONL files are written to")
+	flags.IntVar(&opts.days, "days", 90, "how many days of replies to include")
+	flags.StringVar(&opts.format, "format", dataset.FormatRaw, "raw for context, reply and engagement; chat for chat messages only")
+	flags.IntVar(&opts.minEngagement, "min-engagement", 0, "skip replies with a lower engagement score")
+	flags.IntVar(&opts.contextTweets, "context", dataset.DefaultContextTweets, "conversation tweets included before each reply")
+	flags.Float64Var(&opts.validation, "val", dataset.DefaultValidationFraction, "share of conversations held out for validation")
+	flags.BoolVar(&opts.keepPII, "keep-pii", false, "keep handles, URLs, emails and phone numbers")
+	cmd.MarkFlagRequired("bot-id")
+	return cmd
+}
+
+// runExportDatasetCommand writes the dataset and returns the process exit code
+func runExportDatasetCommand(log *logrus.Logger, cfg *config.Config, opts exportDatasetOptions) int {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.WithError(err).Error("Invalid database configuration")
+		return ExitConfigError
+	}
+
+	ctx := context.Background()
+	database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+	if err != nil {
+		log.WithError(err).Error("Failed to setup database connection")
+		return ExitDBUnreachable
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	store, err := memory.NewTweetStore(log, database, opts.botID, cfg)
+	if err != nil {
+		log.WithError(err).Error("Failed to create tweet store")
+		return ExitDBUnreachable
+	}
+	store.SetIdentity("", opts.username)
+
+	replies, err := store.TrainingReplies(ctx, time.Now().AddDate(0, 0, -opts.days))
+	if err != nil {
+		log.WithError(err).Error("Failed to load replies")
+		return ExitFatalTaskError
+	}
+	examples, err := dataset.Build(ctx, store, replies, dataset.Options{
+		ContextTweets: opts.contextTweets,
+		MinEngagement: opts.minEngagement,
+		KeepPII:       opts.keepPII,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to build examples")
+		return ExitFatalTaskError
+	}
+	train, val := dataset.Split(examples, opts.validation)
+
+	if err := os.MkdirAll(opts.out, 0o755); err != nil {
+		log.WithError(err).Error("Failed to create output directory")
+		return ExitFatalTaskError
+	}
+	for name, examples := range map[string][]dataset.Example{"train.jsonl": train, "val.jsonl": val} {
+		if err := writeDataset(filepath.Join(opts.out, name), examples, opts.format); err != nil {
+			log.WithError(err).Error("Failed to write dataset")
+			return ExitFatalTaskError
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"replies":    len(replies),
+		"train":      len(train),
+		"validation": len(val),
+		"out":        opts.out,
+	}).Info("Exported dataset")
+	return ExitCleanShutdown
+}
+
+// writeDataset writes examples to a new file at path
+func writeDataset(path string, examples []dataset.Example, format string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := dataset.Write(file, examples, format); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
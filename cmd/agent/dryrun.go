@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newDryRunCommand creates the dry-run subcommand, which prints what the agent
+// would have posted while running with AGENT_DRY_RUN
+func newDryRunCommand(app *cli) *cobra.Command {
+	var botID string
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "dry-run",
+		Short: "Show what the agent would have posted in dry-run mode",
+		Args:  cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			if limit < 1 {
+				return fmt.Errorf("--limit must be at least 1, got %d", limit)
+			}
+			app.code = runDryRunCommand(app.log, app.cfg, botID, limit)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&botID, "bot-id", "", "only show posts of this bot account")
+	cmd.Flags().IntVar(&limit, "limit", 50, "how many recent posts to show")
+	return cmd
+}
+
+// runDryRunCommand prints the latest recorded posts and returns the process
+// exit code
+func runDryRunCommand(log *logrus.Logger, cfg *config.Config, botID string, limit int) int {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.WithError(err).Error("Invalid database configuration")
+		return ExitConfigError
+	}
+
+	ctx := context.Background()
+	database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+	if err != nil {
+		log.WithError(err).Error("Failed to setup database connection")
+		return ExitDBUnreachable
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	store, err := memory.NewTweetStore(log, database, botID, cfg)
+	if err != nil {
+		log.WithError(err).Error("Failed to create tweet store")
+		return ExitDBUnreachable
+	}
+
+	posts, err := store.ListDryRunPosts(ctx, limit)
+	if err != nil {
+		log.WithError(err).Error("Failed to list dry-run posts")
+		return ExitFatalTaskError
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tBOT\tKIND\tTARGET\tCONTENT")
+	for _, post := range posts {
+		content := strings.Join(strings.Fields(post.Content), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", post.CreatedAt.Format("2006-01-02 15:04:05"),
+			post.BotID, post.Kind, post.Target, content)
+	}
+	if err := w.Flush(); err != nil {
+		log.WithError(err).Error("Failed to print dry-run posts")
+		return ExitFatalTaskError
+	}
+
+	return ExitCleanShutdown
+}
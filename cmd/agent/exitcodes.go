@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Process exit codes emitted by the agent so that supervisors (systemd, Kubernetes,
+// docker restart policies) can tell failures apart without parsing logs. The values
+// follow sysexits.h where a matching code exists.
+const (
+	// ExitCleanShutdown is returned after a signal-triggered graceful shutdown
+	ExitCleanShutdown = 0
+	// ExitFatalTaskError means a running action failed; restarting is usually safe
+	ExitFatalTaskError = 1
+	// ExitDBUnreachable means the database could not be reached or migrated, or
+	// another service such as the Twitter API was unavailable; retry with backoff
+	ExitDBUnreachable = 69 // EX_UNAVAILABLE
+	// ExitAuthFailure means API credentials were rejected; restarting will not help
+	ExitAuthFailure = 77 // EX_NOPERM
+	// ExitConfigError means required configuration is missing or invalid; restarting will not help
+	ExitConfigError = 78 // EX_CONFIG
+)
+
+// Restart hints written to the crash-state file
+const (
+	RestartNever     = "never"
+	RestartBackoff   = "backoff"
+	RestartImmediate = "immediate"
+)
+
+// crashStateFile is the optional file the agent writes before exiting with an error.
+// It is read from the environment up front so that configuration failures are recorded
+// too, and replaced by the loaded configuration once available.
+var crashStateFile = os.Getenv("AGENT_CRASH_STATE_FILE")
+
+// CrashState describes why the agent exited, for consumption by orchestrators
+type CrashState struct {
+	ExitCode    int       `json:"exit_code"`
+	Component   string    `json:"component"`
+	Message     string    `json:"message"`
+	Error       string    `json:"error,omitempty"`
+	RestartHint string    `json:"restart_hint"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// restartHint maps an exit code to the restart policy a supervisor should apply
+func restartHint(code int) string {
+	switch code {
+	case ExitConfigError, ExitAuthFailure:
+		return RestartNever
+	case ExitDBUnreachable:
+		return RestartBackoff
+	default:
+		return RestartImmediate
+	}
+}
+
+// exitWithError logs the failure, records the crash state if configured and exits
+// with the given code
+func exitWithError(log *logrus.Logger, code int, component, message string, err error) {
+	state := CrashState{
+		ExitCode:    code,
+		Component:   component,
+		Message:     message,
+		RestartHint: restartHint(code),
+		Timestamp:   time.Now().UTC(),
+	}
+	if err != nil {
+		state.Error = err.Error()
+	}
+
+	log.WithError(err).WithFields(logrus.Fields{
+		"exit_code":    code,
+		"component":    component,
+		"restart_hint": state.RestartHint,
+	}).Error(message)
+
+	writeCrashState(log, state)
+	os.Exit(code)
+}
+
+// writeCrashState persists the crash state to the configured crash-state file
+func writeCrashState(log *logrus.Logger, state CrashState) {
+	path := crashStateFile
+	if path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal crash state")
+		return
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.WithError(err).WithField("path", path).Error("Failed to write crash state file")
+	}
+}
+
+// clearCrashState removes a crash-state file left over from a previous run
+func clearCrashState(log *logrus.Logger) {
+	path := crashStateFile
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).WithField("path", path).Warn("Failed to remove stale crash state file")
+	}
+}
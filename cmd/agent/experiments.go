@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newExperimentsCommand creates the experiments subcommand, which prints how
+// the replies of each prompt variant performed
+func newExperimentsCommand(app *cli) *cobra.Command {
+	var botID string
+	var days int
+	cmd := &cobra.Command{
+		Use:   "experiments --bot-id id",
+		Short: "Compare how the replies of each prompt variant performed",
+		Args:  cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			if days < 1 {
+				return fmt.Errorf("--days must be at least 1, got %d", days)
+			}
+			app.code = runExperimentsCommand(app.log, app.cfg, botID, days)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&botID, "bot-id", "", "bot account whose replies are compared")
+	cmd.Flags().IntVar(&days, "days", 14, "how many days of replies to include")
+	cmd.MarkFlagRequired("bot-id")
+	return cmd
+}
+
+// runExperimentsCommand prints the prompt variant performance of the last days
+// and returns the process exit code
+func runExperimentsCommand(log *logrus.Logger, cfg *config.Config, botID string, days int) int {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.WithError(err).Error("Invalid database configuration")
+		return ExitConfigError
+	}
+
+	ctx := context.Background()
+	database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+	if err != nil {
+		log.WithError(err).Error("Failed to setup database connection")
+		return ExitDBUnreachable
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	store, err := memory.NewTweetStore(log, database, botID, cfg)
+	if err != nil {
+		log.WithError(err).Error("Failed to create tweet store")
+		return ExitDBUnreachable
+	}
+
+	stats, err := store.VariantPerformance(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.WithError(err).Error("Failed to compute variant performance")
+		return ExitFatalTaskError
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VARIANT\tREPLIES\tLIKES\tRETWEETS\tREPLIES RECEIVED\tQUOTES\tAVG ENGAGEMENT")
+	for _, variant := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%.2f\n", variant.Variant, variant.Replies,
+			variant.Likes, variant.Retweets, variant.Answers, variant.Quotes, variant.AvgEngagement())
+	}
+	if err := w.Flush(); err != nil {
+		log.WithError(err).Error("Failed to print variant performance")
+		return ExitFatalTaskError
+	}
+
+	return ExitCleanShutdown
+}
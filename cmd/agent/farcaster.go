@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/farcaster"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// setupFarcaster creates the Farcaster client and the tweet store partition
+// its casts are kept in, keyed by the bot's namespaced FID
+func setupFarcaster(log *logrus.Logger, database *gorm.DB, cfg *config.Config) (*farcaster.Client, *memory.TweetStore, error) {
+	log.WithField("fid", cfg.Farcaster.FID).Info("Initializing Farcaster client")
+
+	farcasterConfig, err := farcaster.NewConfigFrom(cfg.Farcaster, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Farcaster config: %w", err)
+	}
+
+	client, err := farcaster.NewClient(farcasterConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Farcaster client: %w", err)
+	}
+
+	store, err := memory.NewTweetStore(log, database, farcaster.UserID(cfg.Farcaster.FID), cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", errTweetStore, err)
+	}
+	store.SetIdentity(cfg.Farcaster.Username, cfg.Farcaster.Username)
+	store.SetSpamThreshold(cfg.Filters.SpamThreshold)
+
+	if cfg.Agent.DryRun {
+		client.SetDryRun(store)
+		log.Warn("Dry run: casts are recorded in dry_run_posts instead of being published")
+	}
+
+	return client, store, nil
+}
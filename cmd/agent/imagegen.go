@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/imagegen"
+	"github.com/sirupsen/logrus"
+)
+
+// setupImageGen creates the meme image generator, or nil when no provider is
+// configured
+func setupImageGen(log *logrus.Logger, cfg *config.Config) (imagegen.Generator, error) {
+	if cfg.ImageGen.Provider == "" {
+		return nil, nil
+	}
+
+	apiKey := cfg.ImageGen.APIKey
+	if apiKey == "" && cfg.ImageGen.Provider == imagegen.ProviderOpenAI {
+		apiKey = cfg.OpenAI.APIKey
+	}
+
+	generator, err := imagegen.New(&imagegen.Config{
+		Provider: cfg.ImageGen.Provider,
+		APIKey:   apiKey,
+		Model:    cfg.ImageGen.Model,
+		Logger:   log,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image generator: %w", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"provider":       cfg.ImageGen.Provider,
+		"probability":    cfg.ImageGen.Probability,
+		"min_engagement": cfg.ImageGen.MinEngagement,
+	}).Info("Attaching generated memes to high-engagement replies")
+	return generator, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/lens"
+	"github.com/lisanmuaddib/agent-go/pkg/ipfs"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+// setupLens creates the Lens client, signing with the wallet key, pinning
+// metadata with pinner and recording publications in the given tweet store
+func setupLens(log *logrus.Logger, cfg *config.Config, pinner *ipfs.Client, store *memory.TweetStore) (*lens.Client, error) {
+	log.WithField("profile_id", cfg.Lens.ProfileID).Info("Initializing Lens client")
+
+	signer, err := wallet.NewKeyManager(cfg.Wallet.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet key: %w", err)
+	}
+
+	lensConfig, err := lens.NewConfigFrom(cfg.Lens, signer, pinner, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Lens config: %w", err)
+	}
+	lensConfig.Recorder = store
+
+	client, err := lens.NewClient(lensConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Lens client: %w", err)
+	}
+
+	if cfg.Agent.DryRun {
+		client.SetDryRun(store)
+		log.Warn("Dry run: Lens publications are recorded in dry_run_posts instead of being broadcast")
+	}
+
+	log.WithField("address", signer.GetAddress().Hex()).Info("Lens publications will be signed by wallet")
+	return client, nil
+}
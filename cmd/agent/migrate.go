@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCommand creates the migrate subcommand
+func newMigrateCommand(app *cli) *cobra.Command {
+	run := func(command string, args []string) {
+		app.code = runMigrateCommand(app.log, app.cfg, command, args)
+	}
+	cmd := commandGroup("migrate", "Apply or inspect the database migrations")
+	cmd.AddCommand(
+		subcommand("up", "Apply every pending migration", cobra.NoArgs, run),
+		subcommand("down [n]", "Roll back the last n migrations, 1 by default", cobra.MaximumNArgs(1), run),
+		subcommand("goto version", "Migrate up or down to a version", cobra.ExactArgs(1), run),
+		subcommand("force version", "Set the version without migrating, clearing the dirty flag", cobra.ExactArgs(1), run),
+		subcommand("status", "Show the current version", cobra.NoArgs, run),
+	)
+	return cmd
+}
+
+// runMigrateCommand runs one migrate subcommand and returns the process exit code
+func runMigrateCommand(log *logrus.Logger, cfg *config.Config, command string, args []string) int {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.WithError(err).Error("Invalid database configuration")
+		return ExitConfigError
+	}
+
+	migrator, err := db.NewMigrator(log, cfg.Database)
+	if err != nil {
+		log.WithError(err).Error("Failed to open migrator")
+		return ExitDBUnreachable
+	}
+	defer migrator.Close()
+
+	switch command {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			steps, err = strconv.Atoi(args[0])
+			if err != nil || steps < 1 {
+				log.Errorf("invalid step count %q", args[0])
+				return ExitConfigError
+			}
+		}
+		err = migrator.Down(steps)
+	case "goto":
+		version, parseErr := versionArg(args[0])
+		if parseErr != nil {
+			log.WithError(parseErr).Error("Invalid goto version")
+			return ExitConfigError
+		}
+		err = migrator.Goto(uint(version))
+	case "force":
+		version, parseErr := versionArg(args[0])
+		if parseErr != nil {
+			log.WithError(parseErr).Error("Invalid force version")
+			return ExitConfigError
+		}
+		err = migrator.Force(version)
+	case "status":
+	}
+	if err != nil {
+		log.WithError(err).WithField("command", command).Error("Migration failed")
+		return ExitFatalTaskError
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		log.WithError(err).Error("Failed to read migration version")
+		return ExitFatalTaskError
+	}
+	log.WithFields(logrus.Fields{
+		"driver":  cfg.Database.Driver,
+		"version": version,
+		"dirty":   dirty,
+	}).Info("Database migration status")
+
+	return ExitCleanShutdown
+}
+
+// versionArg parses the version argument of goto and force
+func versionArg(arg string) (int, error) {
+	version, err := strconv.Atoi(arg)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid version %q", arg)
+	}
+	return version, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newModerationCommand creates the moderation subcommand, which manages the
+// blocked users and muted conversations the agent skips. Without --bot-id,
+// entries apply to every bot account.
+func newModerationCommand(app *cli) *cobra.Command {
+	var botID string
+	run := func(command string, args []string) {
+		app.code = runModerationCommand(app.log, app.cfg, botID, command, args)
+	}
+	cmd := commandGroup("moderation", "Manage blocked users and muted conversations")
+	cmd.PersistentFlags().StringVar(&botID, "bot-id", "", "bot account the entry applies to (default: all accounts)")
+	cmd.AddCommand(
+		subcommand("block user_id [reason]", "Stop replying to a user", cobra.MinimumNArgs(1), run),
+		subcommand("unblock user_id", "Reply to a blocked user again", cobra.ExactArgs(1), run),
+		subcommand("mute conversation_id [reason]", "Stop replying in a conversation", cobra.MinimumNArgs(1), run),
+		subcommand("unmute conversation_id", "Reply in a muted conversation again", cobra.ExactArgs(1), run),
+		subcommand("list", "Show the blocked users and muted conversations", cobra.NoArgs, run),
+	)
+	return cmd
+}
+
+// runModerationCommand runs one moderation subcommand and returns the process
+// exit code
+func runModerationCommand(log *logrus.Logger, cfg *config.Config, botID, command string, args []string) int {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.WithError(err).Error("Invalid database configuration")
+		return ExitConfigError
+	}
+
+	var target, reason string
+	if command != "list" {
+		if strings.TrimSpace(args[0]) == "" {
+			log.Errorf("%s requires an ID", command)
+			return ExitConfigError
+		}
+		target = args[0]
+		reason = strings.Join(args[1:], " ")
+	}
+
+	ctx := context.Background()
+	database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+	if err != nil {
+		log.WithError(err).Error("Failed to setup database connection")
+		return ExitDBUnreachable
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	store, err := memory.NewTweetStore(log, database, botID, cfg)
+	if err != nil {
+		log.WithError(err).Error("Failed to create tweet store")
+		return ExitDBUnreachable
+	}
+
+	var found bool
+	switch command {
+	case "block":
+		err = store.BlockUser(ctx, target, "", reason)
+		found = true
+	case "unblock":
+		found, err = store.UnblockUser(ctx, target)
+	case "mute":
+		err = store.MuteConversation(ctx, target, reason)
+		found = true
+	case "unmute":
+		found, err = store.UnmuteConversation(ctx, target)
+	case "list":
+		err = printModerationList(ctx, store)
+		found = true
+	}
+	if err != nil {
+		log.WithError(err).WithField("command", command).Error("Moderation command failed")
+		return ExitFatalTaskError
+	}
+	if !found {
+		log.WithFields(logrus.Fields{
+			"command": command,
+			"id":      target,
+			"bot_id":  botID,
+		}).Warn("No matching moderation entry")
+	}
+
+	return ExitCleanShutdown
+}
+
+// printModerationList writes the blocked users and muted conversations as a table
+func printModerationList(ctx context.Context, store *memory.TweetStore) error {
+	users, err := store.ListBlockedUsers(ctx)
+	if err != nil {
+		return err
+	}
+	conversations, err := store.ListMutedConversations(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tID\tBOT\tSINCE\tREASON")
+	for _, user := range users {
+		fmt.Fprintf(w, "blocked\t%s\t%s\t%s\t%s\n", user.UserID, botLabel(user.BotID), user.CreatedAt.Format("2006-01-02"), user.Reason)
+	}
+	for _, conversation := range conversations {
+		fmt.Fprintf(w, "muted\t%s\t%s\t%s\t%s\n", conversation.ConversationID, botLabel(conversation.BotID), conversation.CreatedAt.Format("2006-01-02"), conversation.Reason)
+	}
+	return w.Flush()
+}
+
+// botLabel names the account a moderation entry applies to
+func botLabel(botID string) string {
+	if botID == "" {
+		return "all"
+	}
+	return botID
+}
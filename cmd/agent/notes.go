@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newNotesCommand creates the notes subcommand, which manages the operator
+// notes included in reply prompts of a conversation. Without --bot-id, notes
+// apply to every bot account.
+func newNotesCommand(app *cli) *cobra.Command {
+	var botID string
+	var ttl time.Duration
+	run := func(command string, args []string) {
+		app.code = runNotesCommand(app.log, app.cfg, botID, ttl, command, args)
+	}
+	add := subcommand("add conversation_id note", "Add a note to a conversation's reply prompts", cobra.MinimumNArgs(2), run)
+	add.Flags().DurationVar(&ttl, "for", 0, "how long the note applies (default: until removed)")
+
+	cmd := commandGroup("notes", "Manage the operator notes of conversations")
+	cmd.PersistentFlags().StringVar(&botID, "bot-id", "", "bot account the note applies to (default: all accounts)")
+	cmd.AddCommand(
+		add,
+		subcommand("remove note_id", "Remove a note", cobra.ExactArgs(1), run),
+		subcommand("list [conversation_id]", "Show the notes, of one conversation or all", cobra.MaximumNArgs(1), run),
+	)
+	return cmd
+}
+
+// runNotesCommand runs one notes subcommand and returns the process exit code
+func runNotesCommand(log *logrus.Logger, cfg *config.Config, botID string, ttl time.Duration, command string, args []string) int {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.WithError(err).Error("Invalid database configuration")
+		return ExitConfigError
+	}
+
+	var conversationID, note string
+	var noteID int64
+	switch command {
+	case "add":
+		conversationID = args[0]
+		note = strings.TrimSpace(strings.Join(args[1:], " "))
+		if conversationID == "" || note == "" {
+			log.Error("add requires a conversation ID and a note")
+			return ExitConfigError
+		}
+	case "remove":
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Errorf("invalid note ID %q", args[0])
+			return ExitConfigError
+		}
+		noteID = id
+	case "list":
+		if len(args) > 0 {
+			conversationID = args[0]
+		}
+	}
+
+	ctx := context.Background()
+	database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+	if err != nil {
+		log.WithError(err).Error("Failed to setup database connection")
+		return ExitDBUnreachable
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	store, err := memory.NewTweetStore(log, database, botID, cfg)
+	if err != nil {
+		log.WithError(err).Error("Failed to create tweet store")
+		return ExitDBUnreachable
+	}
+
+	found := true
+	switch command {
+	case "add":
+		var added *memory.ConversationNote
+		if added, err = store.AddConversationNote(ctx, conversationID, note, ttl); err == nil {
+			fmt.Println(added.ID)
+		}
+	case "remove":
+		found, err = store.RemoveConversationNote(ctx, noteID)
+	case "list":
+		err = printNotes(ctx, store, conversationID)
+	}
+	if err != nil {
+		log.WithError(err).WithField("command", command).Error("Notes command failed")
+		return ExitFatalTaskError
+	}
+	if !found {
+		log.WithFields(logrus.Fields{
+			"note_id": noteID,
+			"bot_id":  botID,
+		}).Warn("No matching conversation note")
+	}
+
+	return ExitCleanShutdown
+}
+
+// printNotes writes the conversation notes as a table
+func printNotes(ctx context.Context, store *memory.TweetStore, conversationID string) error {
+	notes, err := store.ListConversationNotes(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCONVERSATION\tBOT\tUNTIL\tNOTE")
+	for _, note := range notes {
+		until := "-"
+		switch {
+		case !note.Active(now):
+			until = "expired"
+		case note.ExpiresAt != nil:
+			until = note.ExpiresAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", note.ID, note.ConversationID, botLabel(note.BotID), until, note.Note)
+	}
+	return w.Flush()
+}
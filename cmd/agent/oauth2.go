@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// oauth2Tokens returns the encrypted store of the account's OAuth 2.0 token
+func oauth2Tokens(database *gorm.DB, account config.AccountConfig) (*memory.OAuth2Tokens, error) {
+	key, err := account.Twitter.OAuth2.Key()
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewOAuth2Tokens(database, account.Name, key)
+}
+
+// newOAuth2Command creates the oauth2 subcommand, which authorizes the app in
+// the OAuth 2.0 user context of an account or shows the stored token
+func newOAuth2Command(app *cli) *cobra.Command {
+	var name string
+	var timeout time.Duration
+	run := func(command string, _ []string) {
+		app.code = runOAuth2Command(app.log, app.cfg, name, timeout, command)
+	}
+	login := subcommand("login", "Authorize the app and store the token", cobra.NoArgs, run)
+	login.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "how long login waits for the redirect")
+
+	cmd := commandGroup("oauth2", "Manage the OAuth 2.0 user context of an account")
+	cmd.PersistentFlags().StringVar(&name, "account", "", "account to authorize; defaults to the first account")
+	cmd.AddCommand(
+		login,
+		subcommand("status", "Show the stored token", cobra.NoArgs, run),
+	)
+	return cmd
+}
+
+// runOAuth2Command runs login or status for the account and returns the
+// process exit code
+func runOAuth2Command(log *logrus.Logger, cfg *config.Config, name string, timeout time.Duration, command string) int {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.WithError(err).Error("Invalid database configuration")
+		return ExitConfigError
+	}
+	account, ok := findAccount(cfg, name)
+	if !ok {
+		log.WithField("account", name).Error("Unknown account")
+		return ExitConfigError
+	}
+	if !account.Twitter.OAuth2.Enabled() {
+		log.WithField("account", account.Name).Error("No OAuth 2.0 client configured; set twitter.oauth2.client_id")
+		return ExitConfigError
+	}
+	settings, err := twitter.NewTwitterConfigFrom(account.Twitter, log)
+	if err != nil {
+		log.WithError(err).Error("Invalid Twitter configuration")
+		return ExitConfigError
+	}
+
+	ctx := context.Background()
+	database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+	if err != nil {
+		log.WithError(err).Error("Failed to setup database connection")
+		return ExitDBUnreachable
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	tokens, err := oauth2Tokens(database, account)
+	if err != nil {
+		log.WithError(err).Error("Invalid OAuth 2.0 token key")
+		return ExitConfigError
+	}
+
+	if command == "status" {
+		return printOAuth2Status(ctx, log, account, settings.OAuth2, tokens)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	token, err := oauth2Login(ctx, log, settings, tokens)
+	if err != nil {
+		log.WithError(err).Error("OAuth 2.0 login failed")
+		if errors.Is(err, twitter.ErrOAuth2Scopes) {
+			return ExitAuthFailure
+		}
+		return ExitFatalTaskError
+	}
+	log.WithFields(logrus.Fields{
+		"account":    account.Name,
+		"scopes":     strings.Join(token.Scopes, " "),
+		"expires_at": token.Expiry.UTC(),
+	}).Info("Authorized OAuth 2.0 user context")
+	return ExitCleanShutdown
+}
+
+// findAccount returns the account called name, or the first account when
+// name is empty
+func findAccount(cfg *config.Config, name string) (config.AccountConfig, bool) {
+	for _, account := range cfg.ResolvedAccounts() {
+		if name == "" || account.Name == name {
+			return account, true
+		}
+	}
+	return config.AccountConfig{}, false
+}
+
+// oauth2Login runs the Authorization Code flow with PKCE: it prints the
+// authorize URL, waits for the redirect on the configured redirect URL and
+// stores the token the code is exchanged for
+func oauth2Login(ctx context.Context, log *logrus.Logger, settings *twitter.TwitterConfig, tokens twitter.OAuth2TokenStore) (twitter.OAuth2Token, error) {
+	redirect, err := url.Parse(settings.OAuth2.RedirectURL)
+	if err != nil {
+		return twitter.OAuth2Token{}, fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	pkce, err := twitter.NewPKCE()
+	if err != nil {
+		return twitter.OAuth2Token{}, err
+	}
+	state, err := twitter.NewOAuth2State()
+	if err != nil {
+		return twitter.OAuth2Token{}, err
+	}
+	httpClient, err := twitter.NewHTTPClient(settings)
+	if err != nil {
+		return twitter.OAuth2Token{}, err
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return twitter.OAuth2Token{}, fmt.Errorf("failed to listen on the redirect URL: %w", err)
+	}
+
+	type result struct {
+		token twitter.OAuth2Token
+		err   error
+	}
+	results := make(chan result, 1)
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "state does not match the login request", http.StatusBadRequest)
+			return
+		}
+		var res result
+		if denied := query.Get("error"); denied != "" {
+			res.err = fmt.Errorf("authorization denied: %s", denied)
+		} else {
+			res.token, res.err = twitter.ExchangeOAuth2Code(r.Context(), httpClient, settings.OAuth2, query.Get("code"), pkce)
+		}
+		if res.err == nil {
+			res.err = tokens.SaveOAuth2Token(r.Context(), res.token)
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "The agent is authorized. You can close this page.")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Println("Open this URL while signed in as the account and authorize the app:")
+	fmt.Println(settings.OAuth2.AuthCodeURL(state, pkce))
+	log.WithField("redirect_url", redirect.String()).Info("Waiting for the authorization redirect")
+
+	select {
+	case res := <-results:
+		return res.token, res.err
+	case <-ctx.Done():
+		return twitter.OAuth2Token{}, fmt.Errorf("no authorization redirect received: %w", ctx.Err())
+	}
+}
+
+// printOAuth2Status logs the stored token's expiry and scopes
+func printOAuth2Status(ctx context.Context, log *logrus.Logger, account config.AccountConfig, settings twitter.OAuth2Settings, tokens twitter.OAuth2TokenStore) int {
+	token, err := tokens.LoadOAuth2Token(ctx)
+	if err != nil {
+		log.WithError(err).Error("Failed to load OAuth 2.0 token")
+		return ExitFatalTaskError
+	}
+	if token == nil {
+		log.WithField("account", account.Name).Warn(twitter.ErrOAuth2NotAuthorized.Error())
+		return ExitAuthFailure
+	}
+
+	missing := twitter.MissingScopes(token.Scopes, settings.RequestedScopes())
+	entry := log.WithFields(logrus.Fields{
+		"account":        account.Name,
+		"scopes":         strings.Join(token.Scopes, " "),
+		"expires_at":     token.Expiry.UTC(),
+		"refreshable":    token.RefreshToken != "",
+		"missing_scopes": strings.Join(missing, " "),
+	})
+	if len(missing) > 0 {
+		entry.Warn("Stored OAuth 2.0 token lacks required scopes; run oauth2 login again")
+		return ExitAuthFailure
+	}
+	entry.Info("OAuth 2.0 token stored")
+	return ExitCleanShutdown
+}
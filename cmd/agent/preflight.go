@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	agent "github.com/lisanmuaddib/agent-go/pkg"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/llm/openai"
+	"github.com/lisanmuaddib/agent-go/pkg/preflight"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+// preflightChecks returns the startup checks of the configuration: the
+// settings themselves, the database, the OpenAI key, every account's Twitter
+// credentials and the wallet RPC endpoints. Wallet checks are optional unless
+// the agent watches for incoming transfers.
+func preflightChecks(log *logrus.Logger, cfg *config.Config) []preflight.Check {
+	checks := []preflight.Check{
+		{
+			Name: "config",
+			Run: func(ctx context.Context) (string, error) {
+				if err := cfg.Validate(); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d account(s)", len(cfg.ResolvedAccounts())), nil
+			},
+		},
+		{
+			Name: "database",
+			Run: func(ctx context.Context) (string, error) {
+				if err := cfg.ValidateDatabase(); err != nil {
+					return "", err
+				}
+				if err := db.CheckConnection(ctx, log, cfg.Database); err != nil {
+					return "", err
+				}
+				return cfg.Database.Driver, nil
+			},
+		},
+		{
+			Name: "openai",
+			Run: func(ctx context.Context) (string, error) {
+				openaiConfig, err := openai.NewOpenAIConfigFrom(cfg.OpenAI, log)
+				if err != nil {
+					return "", err
+				}
+				if err := openai.CheckKey(ctx, openaiConfig); err != nil {
+					return "", err
+				}
+				return openaiConfig.Model, nil
+			},
+		},
+	}
+
+	for _, account := range cfg.ResolvedAccounts() {
+		account := account
+		checks = append(checks, preflight.Check{
+			Name: "twitter:" + account.Name,
+			Run: func(ctx context.Context) (string, error) {
+				var opts []twitter.ClientOption
+				if account.Twitter.OAuth2.Enabled() {
+					database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+					if err != nil {
+						return "", err
+					}
+					if sqlDB, err := database.DB(); err == nil {
+						defer sqlDB.Close()
+					}
+					tokens, err := oauth2Tokens(database, account)
+					if err != nil {
+						return "", err
+					}
+					opts = append(opts, twitter.WithOAuth2Tokens(tokens))
+				}
+				_, botID, err := agent.ConnectTwitter(ctx, log, account.Twitter, opts...)
+				if err != nil {
+					return "", err
+				}
+				return "authenticated as " + botID, nil
+			},
+		})
+	}
+
+	return append(checks, walletPreflightChecks(cfg)...)
+}
+
+// walletPreflightChecks checks the RPC endpoint of every wallet network
+func walletPreflightChecks(cfg *config.Config) []preflight.Check {
+	optional := !cfg.Wallet.WatchTransfers
+
+	networks, err := wallet.NetworkConfigsFromSettings(cfg.Wallet)
+	if err != nil || len(networks) == 0 {
+		return []preflight.Check{{
+			Name:     "wallet",
+			Optional: optional,
+			Run: func(ctx context.Context) (string, error) {
+				if err != nil {
+					return "", err
+				}
+				return "", preflight.Skip("no network RPC URL configured")
+			},
+		}}
+	}
+
+	checks := make([]preflight.Check, 0, len(networks))
+	for _, network := range networks {
+		network := network
+		checks = append(checks, preflight.Check{
+			Name:     "wallet:" + strings.ToLower(string(network.Type)),
+			Optional: optional,
+			Run: func(ctx context.Context) (string, error) {
+				head, err := wallet.CheckNetwork(ctx, network)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("block %d", head), nil
+			},
+		})
+	}
+	return checks
+}
+
+// runPreflight performs the preflight checks and prints the report
+func runPreflight(ctx context.Context, log *logrus.Logger, cfg *config.Config) *preflight.Report {
+	log.Info("Running preflight checks")
+	report := preflight.Run(ctx, preflightChecks(log, cfg), preflight.Options{})
+	if err := report.Print(os.Stdout); err != nil {
+		log.WithError(err).Warn("Failed to print preflight report")
+	}
+	report.Log(log)
+
+	log.WithFields(logrus.Fields{
+		"failed":   report.Names(preflight.StatusFailed),
+		"warnings": report.Names(preflight.StatusWarning),
+		"duration": report.Duration.Round(time.Millisecond).String(),
+	}).Info("Preflight checks finished")
+	return report
+}
+
+// preflightExitCode maps the failed checks of a report to the process exit
+// code, preferring the failures a restart cannot fix
+func preflightExitCode(report *preflight.Report) int {
+	code := ExitCleanShutdown
+	for _, result := range report.Failed() {
+		switch {
+		case result.Name == "config":
+			return ExitConfigError
+		case errors.Is(result.Err, errTwitterConfig):
+			return ExitConfigError
+		case result.Name == "openai", errors.Is(result.Err, errTwitterAuth):
+			code = ExitAuthFailure
+		case code == ExitCleanShutdown:
+			code = ExitDBUnreachable
+		}
+	}
+	return code
+}
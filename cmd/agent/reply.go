@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/internal/agentconfig"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/model"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newReplyCommand creates the reply subcommand, which answers one tweet right
+// away instead of waiting for the reply workers
+func newReplyCommand(app *cli) *cobra.Command {
+	var tweetID, account string
+	cmd := &cobra.Command{
+		Use:   "reply --tweet-id id",
+		Short: "Reply to one tweet right away",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			app.code = runReplyCommand(app.log, app.cfg, tweetID, account)
+		},
+	}
+	cmd.Flags().StringVar(&tweetID, "tweet-id", "", "tweet to reply to")
+	cmd.Flags().StringVar(&account, "account", "", "account to reply as; defaults to the first account")
+	cmd.MarkFlagRequired("tweet-id")
+	return cmd
+}
+
+// runReplyCommand replies to a tweet as the account and returns the process
+// exit code
+func runReplyCommand(log *logrus.Logger, cfg *config.Config, tweetID, account string) int {
+	ctx := context.Background()
+	svc, runtime, code := startAccountCommand(ctx, log, cfg, account)
+	if code != ExitCleanShutdown {
+		return code
+	}
+	defer svc.Close(log)
+
+	// Store the tweet first so the reply sees it like a fetched mention
+	tweet, err := fetchTweet(ctx, runtime.twitterClient, tweetID)
+	if err != nil {
+		log.WithError(err).WithField("tweet_id", tweetID).Error("Failed to fetch tweet")
+		return ExitFatalTaskError
+	}
+	if err := runtime.tweetStore.SaveTweets(ctx, []memory.TweetWithMeta{memory.NewTweetWithMeta(tweet, "")}); err != nil {
+		log.WithError(err).WithField("tweet_id", tweetID).Error("Failed to store tweet")
+		return ExitDBUnreachable
+	}
+
+	thread, err := runtime.tweetStore.ReplyThread(ctx, tweetID)
+	if err != nil {
+		log.WithError(err).WithField("tweet_id", tweetID).Error("Failed to load conversation")
+		return ExitDBUnreachable
+	}
+	if thread == nil {
+		log.WithField("tweet_id", tweetID).
+			Warn("Tweet does not need a reply: it was answered, is not addressed to the account, or was moderated")
+		return ExitCleanShutdown
+	}
+
+	responder := agentconfig.NewTweetResponder(svc.actionConfig(log, cfg, runtime))
+	posted, err := responder.ReplyToThread(ctx, *thread)
+	if err != nil {
+		log.WithError(err).WithField("tweet_id", tweetID).Error("Failed to reply")
+		return ExitFatalTaskError
+	}
+	if !posted {
+		log.WithField("tweet_id", tweetID).Warn("Reply deferred: the conversation is throttled or answered by another instance")
+		return ExitCleanShutdown
+	}
+
+	// The reply answers the tweet, so the workers need not queue it again
+	if err := runtime.tweetStore.CompleteReply(ctx, tweetID); err != nil {
+		log.WithError(err).WithField("tweet_id", tweetID).Warn("Failed to remove tweet from the reply queue")
+	}
+	log.WithField("tweet_id", tweetID).Info("Replied to tweet")
+	return ExitCleanShutdown
+}
+
+// fetchTweet loads a tweet and its author from Twitter
+func fetchTweet(ctx context.Context, client *twitter.TwitterClient, tweetID string) (model.Tweet, error) {
+	dataChan, errChan := client.GetTweetByID(ctx, twitter.GetTweetByIDParams{TweetID: tweetID})
+	select {
+	case <-ctx.Done():
+		return model.Tweet{}, ctx.Err()
+	case err := <-errChan:
+		if err == nil {
+			err = fmt.Errorf("tweet %s not found", tweetID)
+		}
+		return model.Tweet{}, err
+	case resp, ok := <-dataChan:
+		if !ok {
+			return model.Tweet{}, fmt.Errorf("tweet %s not found", tweetID)
+		}
+		tweet, err := resp.UnmarshalTweet()
+		if err != nil {
+			return model.Tweet{}, err
+		}
+		return model.FromTwitter(*tweet, resp.Includes.User(tweet.AuthorID)), nil
+	}
+}
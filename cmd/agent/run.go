@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lisanmuaddib/agent-go/internal/agentconfig"
+	agent "github.com/lisanmuaddib/agent-go/pkg"
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/farcaster"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/lens"
+	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/tools"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+// runAgent implements the run command, which is also the default: it starts
+// every account's actions and runs them until a shutdown signal arrives
+func runAgent(log *logrus.Logger, cfg *config.Config, flags config.Flags) {
+	if err := cfg.Validate(); err != nil {
+		exitWithError(log, ExitConfigError, "config", "Invalid configuration", err)
+	}
+	if cfg.Agent.CrashStateFile != "" {
+		crashStateFile = cfg.Agent.CrashStateFile
+	}
+
+	// Remove any crash state left by a previous run
+	clearCrashState(log)
+
+	// Create context with cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Catch bad credentials and unreachable dependencies before any rate
+	// limit is spent
+	if !cfg.Agent.SkipPreflight {
+		report := runPreflight(ctx, log, cfg)
+		if !report.OK() {
+			exitWithError(log, preflightExitCode(report), "preflight", "Preflight checks failed", report.Err())
+		}
+	}
+
+	// Database, LLM and the clients shared by every account
+	svc, err := setupServices(ctx, log, cfg)
+	if err != nil {
+		exitWithError(log, startupExitCode(err), "startup", "Failed to initialize agent services", err)
+	}
+	defer svc.Close(log)
+
+	// Watch the connection so a database restart is recovered without restarting the agent
+	go db.NewHealthMonitor(log, svc.sqlDB, cfg.Database).Run(ctx)
+
+	// Initialize each bot account: its own Twitter client, tweet partition and persona
+	runtimes, err := svc.setupAccounts(ctx, log, cfg)
+	if err != nil {
+		exitWithError(log, startupExitCode(err), "account", "Failed to initialize account", err)
+	}
+	primary := runtimes[0]
+
+	// The accounts must not get stuck answering each other
+	if len(runtimes) > 1 {
+		for _, runtime := range runtimes {
+			svc.bots.AddKnown(runtime.tweetStore.BotID())
+		}
+	}
+
+	// Run the persona on Farcaster too, alongside the primary account
+	var farcasterClient *farcaster.Client
+	var farcasterStore *memory.TweetStore
+	if cfg.Farcaster.Enabled() {
+		farcasterClient, farcasterStore, err = setupFarcaster(log, svc.database, cfg)
+		if err != nil {
+			code := ExitConfigError
+			if errors.Is(err, errTweetStore) {
+				code = ExitDBUnreachable
+			}
+			exitWithError(log, code, "farcaster", "Failed to initialize Farcaster", err)
+		}
+	}
+
+	// IPFS pins Lens metadata and archived conversations
+	pinner, err := setupIPFS(log, cfg)
+	if err != nil {
+		exitWithError(log, ExitConfigError, "ipfs", "Failed to initialize IPFS", err)
+	}
+
+	// Cross-post the primary account's thoughts to Lens
+	var lensClient *lens.Client
+	if cfg.Lens.Enabled() {
+		lensClient, err = setupLens(log, cfg, pinner, primary.tweetStore)
+		if err != nil {
+			exitWithError(log, ExitConfigError, "lens", "Failed to initialize Lens", err)
+		}
+	}
+
+	archivePublisher, err := setupArchivePublisher(log, cfg, pinner)
+	if err != nil {
+		exitWithError(log, ExitConfigError, "archive", "Failed to initialize conversation archive", err)
+	}
+
+	retention, err := setupRetention(log, cfg)
+	if err != nil {
+		exitWithError(log, ExitConfigError, "retention", "Failed to initialize retention", err)
+	}
+
+	memeGenerator, err := setupImageGen(log, cfg)
+	if err != nil {
+		exitWithError(log, ExitConfigError, "imagegen", "Failed to initialize image generation", err)
+	}
+
+	// Watch the wallet for incoming transfers on behalf of the primary account
+	walletClient, err := setupWalletWatch(ctx, log, cfg, primary.tweetStore, svc.events)
+	if err != nil {
+		exitWithError(log, ExitConfigError, "wallet", "Failed to initialize wallet", err)
+	}
+	if walletClient != nil {
+		defer walletClient.Close()
+	}
+
+	// Register tools available to the reasoning loop
+	registeredTools := []tools.Tool{
+		tools.NewLookupUserTool(primary.twitterClient),
+		tools.NewTokenPriceTool(svc.market),
+	}
+	// The query tools and tips share the transfer watch's client when there is one
+	chainClient := walletClient
+	if chainClient == nil && (cfg.Wallet.QueryTools || cfg.Tips.Enabled) {
+		chainClient, err = newWalletClient(ctx, log, cfg, primary.tweetStore)
+		if err != nil {
+			exitWithError(log, ExitConfigError, "wallet", "Failed to initialize wallet", err)
+		}
+		chainClient.SetEventBus(svc.events)
+		defer chainClient.Close()
+	}
+	if chainClient != nil {
+		// Pick up the transactions the last run left pending; gaps are only
+		// reported, repairing them costs gas
+		if _, err := chainClient.ReconcileNonces(ctx); err != nil {
+			log.WithError(err).Warn("Failed to reconcile wallet nonces")
+		}
+	}
+	if cfg.Tips.Enabled {
+		// Re-price stuck tips and publish the receipts the follow-up replies need
+		go chainClient.StartMonitor(ctx, wallet.MonitorOptions{})
+	}
+	if cfg.Wallet.QueryTools {
+		registeredTools = append(registeredTools, walletQueryTools(chainClient, cfg)...)
+	}
+	if cfg.Masa.SearchTool {
+		masaConfig, err := masatwitter.NewConfigFrom(cfg.Masa, log)
+		if err != nil {
+			exitWithError(log, ExitConfigError, "masa", "Invalid Masa configuration", err)
+		}
+		registeredTools = append(registeredTools, tools.NewSearchTweetsTool(masatwitter.NewClient(masaConfig)))
+	}
+	toolRegistry, err := tools.NewRegistry(registeredTools...)
+	if err != nil {
+		exitWithError(log, ExitConfigError, "tools", "Failed to register tools", err)
+	}
+
+	// Look up the facts each mention needs before replying to it
+	var researcher actions.Researcher
+	if cfg.Replies.Research {
+		researcher, err = agent.NewReasoner(agent.ReasonerConfig{
+			LLM:    svc.llm,
+			Tools:  toolRegistry,
+			Logger: log,
+		})
+		if err != nil {
+			exitWithError(log, ExitConfigError, "tools", "Failed to create reply research loop", err)
+		}
+	}
+
+	// Configure the actions of every account
+	log.Info("Configuring agent actions")
+	var configured []actions.Action
+	exampleLibraries := make(map[string]admin.ExampleLibrary, len(runtimes))
+	replyRedoers := make(map[string]admin.ReplyRedoer, len(runtimes))
+	complianceHandlers := make(map[string]admin.ComplianceHandler, len(runtimes))
+	credentials := make(map[string]admin.CredentialChecker, len(runtimes))
+	quotas := make(map[string]admin.QuotaReporter, len(runtimes))
+	quotaHistory := make(map[string]admin.QuotaHistory, len(runtimes))
+	tweetBrowsers := make(map[string]admin.TweetBrowser, len(runtimes))
+	latencies := make(map[string]admin.LatencyReporter, len(runtimes))
+	replyStages := make(map[string]admin.ReplyStageLog, len(runtimes))
+	tippers := make(map[string]admin.TipManager, 1)
+	for _, runtime := range runtimes {
+		actionConfig := svc.actionConfig(log, cfg, runtime)
+		exampleLibraries[runtime.account.Name] = actionConfig.Examples
+		actionConfig.Retention = retention
+		actionConfig.Researcher = researcher
+		actionConfig.Credentials = agentconfig.NewCredentialMonitor(actionConfig)
+		credentials[runtime.account.Name] = actionConfig.Credentials
+		quotas[runtime.account.Name] = runtime.twitterClient
+		quotaHistory[runtime.account.Name] = runtime.tweetStore
+		tweetBrowsers[runtime.account.Name] = runtime.tweetStore
+		latencies[runtime.account.Name] = runtime.tweetStore
+		replyStages[runtime.account.Name] = runtime.tweetStore
+		if archivePublisher != nil {
+			actionConfig.ArchivePublisher = archivePublisher
+			actionConfig.ArchiveOptions = archive.Options{QuietPeriod: cfg.Archive.QuietPeriod}
+		}
+		if memeGenerator != nil {
+			actionConfig.MemeGenerator = memeGenerator
+			actionConfig.MemeOptions = actions.MemeOptions{
+				Probability:   cfg.ImageGen.Probability,
+				MinEngagement: cfg.ImageGen.MinEngagement,
+			}
+		}
+		if len(runtimes) > 1 {
+			actionConfig.AccountName = runtime.account.Name
+		}
+		if runtime == primary {
+			actionConfig.FarcasterClient = farcasterClient
+			actionConfig.FarcasterStore = farcasterStore
+			actionConfig.LensClient = lensClient
+			if walletClient != nil {
+				actionConfig.Wallet = walletClient
+				actionConfig.TransferWatch = actions.TransferWatchOptions{
+					Thank:     cfg.Wallet.ThankTransfers,
+					MinAmount: cfg.Wallet.ThankMinAmount,
+				}
+			}
+			if cfg.Tips.Enabled {
+				actionConfig.Tipper = newTipper(chainClient, runtime.twitterClient, runtime.tweetStore, log, cfg, svc.events, svc.schedule)
+				tippers[runtime.account.Name] = actionConfig.Tipper
+			}
+		}
+
+		replyRedoers[runtime.account.Name] = agentconfig.NewTweetResponder(actionConfig)
+		complianceHandlers[runtime.account.Name] = agentconfig.NewComplianceAction(actionConfig)
+
+		accountActions, err := agentconfig.ConfigureActions(actionConfig)
+		if err != nil {
+			exitWithError(log, ExitConfigError, "actions", "Failed to configure actions", err)
+		}
+		configured = append(configured, accountActions...)
+	}
+
+	// Initialize agent
+	log.Info("Initializing agent")
+	agent, err := agent.NewBuilder().
+		WithLLM(svc.llm).
+		WithTwitter(primary.twitterClient).
+		WithStore(primary.tweetStore).
+		WithLogger(log).
+		WithTools(toolRegistry).
+		WithTasks(cfg.Tasks, taskSettingsReloader(flags)).
+		WithActions(configured...).
+		Build()
+	if err != nil {
+		exitWithError(log, ExitConfigError, "agent", "Failed to create agent", err)
+	}
+
+	// Serve task health and other operator endpoints
+	if cfg.Admin.Addr != "" {
+		adminServer := admin.NewServer(cfg.Admin, log)
+		adminServer.HandleTasks(agent)
+		adminServer.HandleExamples(exampleLibraries)
+		adminServer.HandleReplies(replyRedoers)
+		adminServer.HandleCompliance(complianceHandlers)
+		adminServer.HandleLLMUsage(svc.usage)
+		adminServer.HandleReadiness(credentials)
+		adminServer.HandleQuota(quotas, quotaHistory)
+		adminServer.HandleTweets(tweetBrowsers)
+		adminServer.HandleLatency(latencies, cfg.Replies.LatencySLO)
+		adminServer.HandleReplyStages(replyStages)
+		adminServer.HandleTips(tippers)
+		if chainClient != nil {
+			adminServer.HandleWallet(chainClient)
+		}
+		go func() {
+			if err := adminServer.Run(ctx); err != nil {
+				log.WithError(err).Error("Admin API stopped")
+			}
+		}()
+	}
+
+	log.Info("Starting Twitter mention monitoring")
+
+	// Run the agent until a shutdown signal arrives; the deferred Close then
+	// closes the database once every action has stopped
+	if err := agent.RunUntilSignal(ctx); err != nil && err != context.Canceled {
+		exitWithError(log, ExitFatalTaskError, "agent", "Agent stopped with error", err)
+	}
+
+	log.Info("Agent shutdown complete")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
+	"github.com/lisanmuaddib/agent-go/pkg/masa/scraper"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/model"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newScrapeCommand creates the scrape subcommand, which runs a Masa scrape
+// campaign and prints the tweets as JSON lines or stores them
+func newScrapeCommand(app *cli) *cobra.Command {
+	var file, botID string
+	var workers int
+	var save bool
+	cmd := &cobra.Command{
+		Use:   "scrape",
+		Short: "Run a Masa scrape campaign",
+		Args:  cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			if workers < 1 {
+				return fmt.Errorf("--workers must be at least 1, got %d", workers)
+			}
+			app.code = runScrapeCommand(app.log, app.cfg, file, workers, save, botID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "pkg/masa/scraper/list.json", "JSON file with the queries to scrape")
+	cmd.Flags().IntVar(&workers, "workers", scraper.DefaultWorkerCount, "maximum concurrent searches")
+	cmd.Flags().BoolVar(&save, "save", false, "store the tweets instead of printing them")
+	cmd.Flags().StringVar(&botID, "bot-id", "", "bot account whose tweet partition stores the tweets")
+	return cmd
+}
+
+// runScrapeCommand runs the campaign in file and returns the process exit code
+func runScrapeCommand(log *logrus.Logger, cfg *config.Config, file string, workers int, save bool, botID string) int {
+	campaign, err := scraper.LoadConfig(file)
+	if err != nil {
+		log.WithError(err).WithField("file", file).Error("Failed to load scrape queries")
+		return ExitConfigError
+	}
+	campaign.WorkerCount = workers
+
+	masaConfig, err := masatwitter.NewConfigFrom(cfg.Masa, log)
+	if err != nil {
+		log.WithError(err).Error("Invalid Masa configuration")
+		return ExitConfigError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var store *memory.TweetStore
+	if save {
+		if err := cfg.ValidateDatabase(); err != nil {
+			log.WithError(err).Error("Invalid database configuration")
+			return ExitConfigError
+		}
+		database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+		if err != nil {
+			log.WithError(err).Error("Failed to setup database connection")
+			return ExitDBUnreachable
+		}
+		if sqlDB, err := database.DB(); err == nil {
+			defer sqlDB.Close()
+		}
+		if store, err = memory.NewTweetStore(log, database, botID, cfg); err != nil {
+			log.WithError(err).Error("Failed to create tweet store")
+			return ExitDBUnreachable
+		}
+	}
+
+	scr := scraper.NewScraper(masatwitter.NewClient(masaConfig), log)
+	results := scr.Results()
+
+	// Handle tweets as tasks finish; the channel closes when the campaign ends
+	done := make(chan int)
+	go func() {
+		code := ExitCleanShutdown
+		out := json.NewEncoder(os.Stdout)
+		for result := range results {
+			if result.Err != nil || len(result.Tweets) == 0 {
+				continue
+			}
+			tweets := model.FromMasaTweets(result.Tweets)
+			if store == nil {
+				for _, tweet := range tweets {
+					if err := out.Encode(tweet); err != nil {
+						log.WithError(err).Error("Failed to print tweet")
+						code = ExitFatalTaskError
+					}
+				}
+				continue
+			}
+
+			page := make([]memory.TweetWithMeta, 0, len(tweets))
+			for _, tweet := range tweets {
+				page = append(page, memory.NewTweetWithMeta(tweet, ""))
+			}
+			if err := store.SaveTweets(ctx, page); err != nil {
+				log.WithError(err).WithField("query", result.Task.Query).Error("Failed to store scraped tweets")
+				code = ExitDBUnreachable
+			}
+		}
+		done <- code
+	}()
+
+	err = scr.ProcessTasks(ctx, campaign)
+	code := <-done
+	if errors.Is(err, context.Canceled) {
+		log.Info("Scrape interrupted")
+		return code
+	}
+	if err != nil {
+		log.WithError(err).Error("Scrape failed")
+		return ExitFatalTaskError
+	}
+
+	status := scr.GetStatus()
+	log.WithFields(logrus.Fields{
+		"tasks":  status.TotalTasks,
+		"failed": status.FailedTasks,
+		"tweets": status.UniqueTweets,
+		"saved":  save,
+	}).Info("Scrape complete")
+	return code
+}
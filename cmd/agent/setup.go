@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/internal/agentconfig"
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/experiments"
+	"github.com/lisanmuaddib/agent-go/pkg/filters"
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	"github.com/lisanmuaddib/agent-go/pkg/llm/openai"
+	"github.com/lisanmuaddib/agent-go/pkg/lock"
+	"github.com/lisanmuaddib/agent-go/pkg/market"
+	"github.com/lisanmuaddib/agent-go/pkg/plugins"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+	"gorm.io/gorm"
+)
+
+// Sentinel errors classifying shared startup failures for exit codes
+var (
+	errInvalidSetting = errors.New("invalid configuration")
+	errDatabase       = errors.New("database unreachable")
+)
+
+// services are the clients shared by the agent and the commands that act as
+// one of its accounts
+type services struct {
+	database   *gorm.DB
+	sqlDB      *sql.DB
+	locker     lock.Locker
+	llm        llms.Model
+	embedder   llm.Embedder
+	market     *market.Client
+	spamFilter *filters.SpamFilter
+	bots       *filters.BotGuard
+	sentiment  *filters.SentimentAnalyzer
+	experiment *experiments.Experiment
+	events     *events.Bus
+	schedule   *schedule.Policy
+	context    *thoughts.ContextBuilder
+
+	// replyLLM, thoughtLLM and judgmentLLM are llm, the routed model or the
+	// generator's fine-tune
+	replyLLM    llms.Model
+	thoughtLLM  llms.Model
+	judgmentLLM llms.Model
+	// usage accounts the calls and tokens of every model
+	usage admin.UsageReporter
+}
+
+// setupServices connects to the database and creates the LLM, market, filter
+// and event clients. Close releases them.
+func setupServices(ctx context.Context, log *logrus.Logger, cfg *config.Config) (*services, error) {
+	log.Info("Initializing database connection")
+	database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to setup database connection: %v", errDatabase, err)
+	}
+
+	// Get underlying *sql.DB to ensure clean shutdown
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get underlying database connection: %v", errDatabase, err)
+	}
+	s := &services{database: database, sqlDB: sqlDB}
+
+	// Instances sharing the database take turns on mentions and conversations
+	s.locker, err = lock.New(database, log)
+	if err != nil {
+		s.Close(log)
+		return nil, fmt.Errorf("%w: failed to set up locks: %v", errDatabase, err)
+	}
+
+	log.Info("Initializing OpenAI client")
+	openaiConfig, err := openai.NewOpenAIConfigFrom(cfg.OpenAI, log)
+	if err != nil {
+		s.Close(log)
+		return nil, fmt.Errorf("%w: failed to create OpenAI config: %v", errInvalidSetting, err)
+	}
+	llmClient, err := openai.NewOpenAIClient(openaiConfig)
+	if err != nil {
+		s.Close(log)
+		return nil, fmt.Errorf("%w: failed to create OpenAI client: %v", errInvalidSetting, err)
+	}
+	s.llm = llmClient.GetLLM()
+	s.embedder = llmClient.Embedder()
+	s.replyLLM = llmClient.ModelFor(openai.GeneratorReplies)
+	s.thoughtLLM = llmClient.ModelFor(openai.GeneratorThoughts)
+	s.judgmentLLM = llmClient.ModelFor(openai.GeneratorJudgments)
+	s.usage = llmClient
+
+	// Initialize market data client for price commentary
+	s.market = market.NewClient(market.NewConfigFrom(cfg.Market, log))
+
+	// Share one spam filter across accounts so copy-paste campaigns are spotted
+	// whichever account they target
+	s.spamFilter = filters.NewSpamFilter(filters.NewConfigFrom(cfg.Filters, s.llm, log))
+	s.bots = filters.NewBotGuard(cfg.Filters.KnownBots, cfg.Filters.BotHeuristic)
+
+	// Score mention sentiment so replies can match hostility or praise
+	var sentimentClassifier llms.Model
+	if cfg.Filters.SentimentLLM {
+		sentimentClassifier = s.llm
+	}
+	s.sentiment = filters.NewSentimentAnalyzer(sentimentClassifier, log)
+
+	// Reply prompt A/B test, if configured
+	s.experiment, err = experiments.NewFromConfig(cfg.Experiment)
+	if err != nil {
+		s.Close(log)
+		return nil, fmt.Errorf("%w: invalid experiment: %v", errInvalidSetting, err)
+	}
+
+	// Conversation context of reply prompts is counted in the model's tokens
+	s.context = thoughts.NewContextBuilder(cfg.OpenAI.Model, cfg.Replies.ContextTokens)
+
+	// Quiet hours and embargoes shared by every account
+	s.schedule, err = schedule.New(cfg.Posting.Timezone, cfg.Posting.QuietHours, cfg.Posting.Embargoes)
+	if err != nil {
+		s.Close(log)
+		return nil, fmt.Errorf("%w: invalid posting schedule: %v", errInvalidSetting, err)
+	}
+
+	// Event bus for operator alerting and dashboards
+	s.events, err = events.NewBusFrom(cfg.Events, log)
+	if err != nil {
+		s.Close(log)
+		return nil, fmt.Errorf("%w: failed to set up event publishing: %v", errInvalidSetting, err)
+	}
+
+	return s, nil
+}
+
+// Close flushes the event publishers and closes the database connection
+func (s *services) Close(log *logrus.Logger) {
+	if s.events != nil {
+		if err := s.events.Close(); err != nil {
+			log.WithError(err).Error("Error closing event publishers")
+		}
+	}
+	if err := s.sqlDB.Close(); err != nil {
+		log.WithError(err).Error("Error closing database connection")
+	}
+	log.Info("Database connection closed")
+}
+
+// setupAccounts initializes every configured account
+func (s *services) setupAccounts(ctx context.Context, log *logrus.Logger, cfg *config.Config) ([]*accountRuntime, error) {
+	accounts := cfg.ResolvedAccounts()
+	runtimes := make([]*accountRuntime, 0, len(accounts))
+	for _, account := range accounts {
+		runtime, err := setupAccount(ctx, log, s.database, cfg, account, s.events, len(accounts) == 1)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", account.Name, err)
+		}
+		runtimes = append(runtimes, runtime)
+	}
+	return runtimes, nil
+}
+
+// setupNamedAccount initializes the account called name, or the first
+// account when name is empty, for commands acting as a single account
+func (s *services) setupNamedAccount(ctx context.Context, log *logrus.Logger, cfg *config.Config, name string) (*accountRuntime, error) {
+	accounts := cfg.ResolvedAccounts()
+	for _, account := range accounts {
+		if name == "" || account.Name == name {
+			runtime, err := setupAccount(ctx, log, s.database, cfg, account, s.events, len(accounts) == 1)
+			if err != nil {
+				return nil, fmt.Errorf("account %s: %w", account.Name, err)
+			}
+			return runtime, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown account %q", errInvalidSetting, name)
+}
+
+// actionConfig returns the action settings of an account that do not depend
+// on optional integrations such as Farcaster, Lens or the wallet
+func (s *services) actionConfig(log *logrus.Logger, cfg *config.Config, runtime *accountRuntime) agentconfig.ActionConfig {
+	return agentconfig.ActionConfig{
+		TwitterClient:   runtime.twitterClient,
+		LLM:             s.llm,
+		ReplyLLM:        s.replyLLM,
+		ThoughtLLM:      s.thoughtLLM,
+		JudgmentLLM:     s.judgmentLLM,
+		Logger:          log,
+		TweetStore:      runtime.tweetStore,
+		Market:          s.market,
+		SpamFilter:      s.spamFilter,
+		Bots:            s.bots,
+		Sentiment:       s.sentiment,
+		Experiment:      s.experiment,
+		Events:          s.events,
+		Locker:          s.locker,
+		Schedule:        s.schedule,
+		ContextBuilder:  s.context,
+		Examples:        actions.NewReplyExamples(runtime.tweetStore, s.embedder, log, cfg.Replies.Examples),
+		Personality:     runtime.personality,
+		TweetsPerWindow: runtime.account.TweetsPerWindow,
+		Throttle: actions.ThrottleOptions{
+			MaxRepliesPerUser:    cfg.Replies.MaxPerUserPerDay,
+			MaxConversationDepth: cfg.Replies.MaxConversationDepth,
+			ThreadCooldown:       cfg.Replies.ThreadCooldown,
+		},
+		Loops: actions.LoopOptions{
+			MaxBotReplies: cfg.Replies.Loops.MaxBotReplies,
+			Repeats:       cfg.Replies.Loops.Repeats,
+			Similarity:    cfg.Replies.Loops.Similarity,
+			SignOff:       cfg.Replies.Loops.SignOff,
+		},
+		LatencySLO:   cfg.Replies.LatencySLO,
+		SelfCritique: cfg.Replies.SelfCritique,
+		ReplyWorkers: actions.ReplyWorkerOptions{
+			Workers:           cfg.Replies.Workers,
+			VisibilityTimeout: cfg.Replies.VisibilityTimeout,
+			MaxAttempts:       cfg.Replies.MaxAttempts,
+			RetryDelay:        cfg.Replies.RetryDelay,
+			Recovery:          actions.RecoveryOptions{Lookback: cfg.Replies.RecoveryLookback},
+		},
+		MentionsPolling: actions.AdaptiveInterval{
+			Min: cfg.Replies.PollMinInterval,
+			Max: cfg.Replies.PollMaxInterval,
+		},
+		Plugins:      pluginSpecs(cfg.Plugins),
+		BudgetShares: agentconfig.BudgetShares(runtime.account.Twitter.Budget.Actions),
+	}
+}
+
+// pluginSpecs returns the plugins to load for every account
+func pluginSpecs(configured []config.PluginConfig) []plugins.Spec {
+	specs := make([]plugins.Spec, 0, len(configured))
+	for _, plugin := range configured {
+		specs = append(specs, plugins.Spec{
+			Name:     plugin.Name,
+			Path:     plugin.Path,
+			Settings: plugin.Settings,
+		})
+	}
+	return specs
+}
+
+// startupExitCode maps a setup failure to the process exit code
+func startupExitCode(err error) int {
+	switch {
+	case errors.Is(err, errTwitterConfig), errors.Is(err, errPersonality), errors.Is(err, errInvalidSetting):
+		return ExitConfigError
+	case errors.Is(err, errTwitterAuth):
+		return ExitAuthFailure
+	case errors.Is(err, errTweetStore), errors.Is(err, errDatabase), errors.Is(err, errTwitterUnavailable):
+		return ExitDBUnreachable
+	default:
+		return ExitFatalTaskError
+	}
+}
+
+// startAccountCommand validates the configuration and sets up the services
+// and the account a one-shot command acts as. It returns a non-zero exit code
+// when setup failed; otherwise the caller must close the services.
+func startAccountCommand(ctx context.Context, log *logrus.Logger, cfg *config.Config, account string) (*services, *accountRuntime, int) {
+	if err := cfg.Validate(); err != nil {
+		log.WithError(err).Error("Invalid configuration")
+		return nil, nil, ExitConfigError
+	}
+
+	svc, err := setupServices(ctx, log, cfg)
+	if err != nil {
+		log.WithError(err).Error("Failed to initialize agent services")
+		return nil, nil, startupExitCode(err)
+	}
+
+	runtime, err := svc.setupNamedAccount(ctx, log, cfg, account)
+	if err != nil {
+		svc.Close(log)
+		log.WithError(err).Error("Failed to initialize account")
+		return nil, nil, startupExitCode(err)
+	}
+	return svc, runtime, ExitCleanShutdown
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/internal/agentconfig"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newPostThoughtCommand creates the post-thought subcommand, which posts one
+// original thought about a given topic
+func newPostThoughtCommand(app *cli) *cobra.Command {
+	var topic, account string
+	cmd := &cobra.Command{
+		Use:   "post-thought --topic topic",
+		Short: "Post one original thought about a topic",
+		Args:  cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			if strings.TrimSpace(topic) == "" {
+				return fmt.Errorf("--topic cannot be empty")
+			}
+			app.code = runPostThoughtCommand(app.log, app.cfg, topic, account)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&topic, "topic", "", "what the thought is about")
+	cmd.Flags().StringVar(&account, "account", "", "account to post as; defaults to the first account")
+	cmd.MarkFlagRequired("topic")
+	return cmd
+}
+
+// runPostThoughtCommand posts a thought about topic as the account and returns
+// the process exit code
+func runPostThoughtCommand(log *logrus.Logger, cfg *config.Config, topic, account string) int {
+	ctx := context.Background()
+	svc, runtime, code := startAccountCommand(ctx, log, cfg, account)
+	if code != ExitCleanShutdown {
+		return code
+	}
+	defer svc.Close(log)
+
+	tweet, err := agentconfig.NewThoughtAction(svc.actionConfig(log, cfg, runtime)).PostTopic(ctx, topic)
+	if errors.Is(err, thoughts.ErrDuplicateThought) {
+		log.WithError(err).WithField("topic", topic).Warn("Every thought repeated a recent tweet; nothing posted")
+		return ExitCleanShutdown
+	}
+	if err != nil {
+		log.WithError(err).WithField("topic", topic).Error("Failed to post thought")
+		return ExitFatalTaskError
+	}
+
+	log.WithFields(logrus.Fields{
+		"topic":    topic,
+		"tweet_id": tweet.ID,
+		"text":     tweet.Text,
+	}).Info("Posted thought")
+	return ExitCleanShutdown
+}
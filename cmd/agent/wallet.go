@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/lisanmuaddib/agent-go/pkg/tools"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// setupWalletWatch creates the wallet client that watches for incoming
+// transfers, or nil when the watch is off
+func setupWalletWatch(ctx context.Context, log *logrus.Logger, cfg *config.Config, store *memory.TweetStore, bus *events.Bus) (*wallet.Client, error) {
+	if !cfg.Wallet.WatchTransfers {
+		return nil, nil
+	}
+
+	client, err := newWalletClient(ctx, log, cfg, store)
+	if err != nil {
+		return nil, err
+	}
+	client.SetEventBus(bus)
+
+	log.WithFields(logrus.Fields{
+		"networks": client.Networks(),
+		"thank":    cfg.Wallet.ThankTransfers,
+	}).Info("Watching wallet for incoming transfers")
+	return client, nil
+}
+
+// newWalletClient connects the wallet to every network with an RPC URL. The
+// nonces it issues are persisted in store, and with AGENT_DRY_RUN transfers
+// are recorded in its dry_run_posts instead of being sent. One-off commands
+// running live may pass a nil store.
+func newWalletClient(ctx context.Context, log *logrus.Logger, cfg *config.Config, store *memory.TweetStore) (*wallet.Client, error) {
+	networks, err := wallet.NetworkConfigsFromSettings(cfg.Wallet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure wallet networks: %w", err)
+	}
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("no wallet network has an RPC URL")
+	}
+
+	client, err := wallet.NewClient(ctx, log, networks, cfg.Wallet.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wallet client: %w", err)
+	}
+	if store == nil {
+		return client, nil
+	}
+	client.SetNonceStore(store)
+	if cfg.Agent.DryRun {
+		client.SetDryRun(store)
+		log.Warn("Dry run: wallet transfers are recorded in dry_run_posts instead of being sent")
+	}
+	return client, nil
+}
+
+// walletQueryTools returns the tools that let the LLM read the balances and
+// distributions of the treasury, the wallet's own address unless configured
+func walletQueryTools(client *wallet.Client, cfg *config.Config) []tools.Tool {
+	treasury := cfg.Wallet.TreasuryAddress
+	if treasury == "" {
+		treasury = client.Address().Hex()
+	}
+	return []tools.Tool{
+		tools.NewWalletBalanceTool(client, treasury),
+		tools.NewTokenBalanceTool(client, cfg.Wallet.TokenContractAddress, treasury),
+		tools.NewRecentDistributionsTool(client, cfg.Wallet.TokenContractAddress, treasury),
+	}
+}
+
+// newTipper creates the tipper running tip and wallet commands for an
+// account, paying tips in the configured token from the wallet and holding
+// its replies while posting is closed
+func newTipper(client *wallet.Client, twitterClient *twitter.TwitterClient, store *memory.TweetStore, log *logrus.Logger, cfg *config.Config, bus *events.Bus, policy *schedule.Policy) *actions.Tipper {
+	log.WithFields(logrus.Fields{
+		"network":        cfg.Tips.Network,
+		"max_amount":     cfg.Tips.MaxAmount,
+		"daily_limit":    cfg.Tips.DailyLimit,
+		"approval_above": cfg.Tips.ApprovalAbove,
+	}).Info("Tip commands enabled")
+	return actions.NewTipper(client, twitterClient, store, log, actions.TipOptions{
+		Network: wallet.NetworkType(strings.ToUpper(cfg.Tips.Network)),
+		Token:   common.HexToAddress(cfg.Wallet.TokenContractAddress),
+		Policy: actions.TipPolicy{
+			MaxAmount:     cfg.Tips.MaxAmount,
+			DailyLimit:    cfg.Tips.DailyLimit,
+			ApprovalAbove: cfg.Tips.ApprovalAbove,
+		},
+		Schedule: policy,
+	}).WithEvents(bus)
+}
+
+// newWalletCommand creates the wallet subcommand, which shows balances or
+// sends funds from the agent's wallet
+func newWalletCommand(app *cli) *cobra.Command {
+	var network, token, to, amount string
+
+	balance := &cobra.Command{
+		Use:   "balance [address]",
+		Short: "Show the native and token balances of an address, the wallet's own by default",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !cmd.Flags().Changed("token") {
+				token = app.cfg.Wallet.TokenContractAddress
+			}
+			var address string
+			if len(args) == 1 {
+				address = args[0]
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			app.code = runWalletBalance(ctx, app.log, app.cfg, network, token, address)
+		},
+	}
+	balance.Flags().StringVar(&network, "network", "", "only show this network; defaults to every configured network")
+	balance.Flags().StringVar(&token, "token", "", "ERC20 token to show besides the native balance; defaults to wallet.token_contract_address")
+
+	send := &cobra.Command{
+		Use:   "send --network name --to address --amount n",
+		Short: "Send native currency, or an ERC20 token, from the wallet",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			app.code = runWalletSend(ctx, app.log, app.cfg, network, to, amount, token)
+		},
+	}
+	send.Flags().StringVar(&network, "network", "", "network to send on")
+	send.Flags().StringVar(&to, "to", "", "recipient address or ENS name")
+	send.Flags().StringVar(&amount, "amount", "", "amount in whole tokens, e.g. 0.5")
+	send.Flags().StringVar(&token, "token", "", "ERC20 token to send; defaults to the native currency")
+	for _, name := range []string{"network", "to", "amount"} {
+		send.MarkFlagRequired(name)
+	}
+
+	cmd := commandGroup("wallet", "Show balances or send funds from the agent's wallet")
+	cmd.AddCommand(balance, send)
+	return cmd
+}
+
+// runWalletBalance prints the native and token balances of an address,
+// the wallet's own when address is empty
+func runWalletBalance(ctx context.Context, log *logrus.Logger, cfg *config.Config, network, token, address string) int {
+	client, err := newWalletClient(ctx, log, cfg, nil)
+	if err != nil {
+		log.WithError(err).Error("Failed to initialize wallet")
+		return ExitConfigError
+	}
+	defer client.Close()
+
+	networks := client.Networks()
+	if network != "" {
+		networks = []wallet.NetworkType{wallet.NetworkType(strings.ToUpper(network))}
+	}
+
+	if address == "" {
+		address = client.Address().Hex()
+	} else {
+		resolved, err := client.ResolveAddress(ctx, networks[0], address)
+		if err != nil {
+			log.WithError(err).WithField("address", address).Error("Invalid address")
+			return ExitConfigError
+		}
+		address = resolved.Hex()
+	}
+
+	tokens := wallet.TokenList{}
+	if token != "" {
+		if !common.IsHexAddress(token) {
+			log.WithField("token", token).Error("Invalid token address")
+			return ExitConfigError
+		}
+		for _, n := range networks {
+			tokens[n] = []common.Address{common.HexToAddress(token)}
+		}
+	}
+
+	snapshot, err := client.GetPortfolio(ctx, address, networks, tokens)
+	if err != nil {
+		log.WithError(err).Error("Failed to get balances")
+		return ExitFatalTaskError
+	}
+	for _, lookupErr := range snapshot.Errors {
+		log.WithField("error", lookupErr).Warn("Balance lookup failed")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ADDRESS\t%s\n", address)
+	fmt.Fprintln(w, "NETWORK\tSYMBOL\tBALANCE")
+	for _, balance := range snapshot.Balances {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", balance.Network, balance.Symbol, balance.Formatted)
+	}
+	if err := w.Flush(); err != nil {
+		log.WithError(err).Error("Failed to print balances")
+		return ExitFatalTaskError
+	}
+	return ExitCleanShutdown
+}
+
+// runWalletSend sends native currency, or an ERC20 token, from the wallet.
+// With AGENT_DRY_RUN the transfer is recorded in dry_run_posts instead.
+func runWalletSend(ctx context.Context, log *logrus.Logger, cfg *config.Config, network, to, amount, token string) int {
+	if token != "" && !common.IsHexAddress(token) {
+		log.WithField("token", token).Error("Invalid token address")
+		return ExitConfigError
+	}
+
+	var store *memory.TweetStore
+	if cfg.Agent.DryRun {
+		if err := cfg.ValidateDatabase(); err != nil {
+			log.WithError(err).Error("Invalid database configuration")
+			return ExitConfigError
+		}
+		database, err := db.SetupDatabaseContext(ctx, log, cfg.Database)
+		if err != nil {
+			log.WithError(err).Error("Failed to setup database connection")
+			return ExitDBUnreachable
+		}
+		if sqlDB, err := database.DB(); err == nil {
+			defer sqlDB.Close()
+		}
+		store, err = memory.NewTweetStore(log, database, "", cfg)
+		if err != nil {
+			log.WithError(err).Error("Failed to create tweet store")
+			return ExitDBUnreachable
+		}
+	}
+
+	client, err := newWalletClient(ctx, log, cfg, store)
+	if err != nil {
+		log.WithError(err).Error("Failed to initialize wallet")
+		return ExitConfigError
+	}
+	defer client.Close()
+
+	chain := wallet.NetworkType(strings.ToUpper(network))
+	recipient, err := client.ResolveAddress(ctx, chain, to)
+	if err != nil {
+		log.WithError(err).WithField("to", to).Error("Invalid recipient")
+		return ExitConfigError
+	}
+
+	sendLog := log.WithFields(logrus.Fields{
+		"network": chain,
+		"to":      recipient.Hex(),
+		"amount":  amount,
+	})
+
+	var hash common.Hash
+	if token == "" {
+		value, err := wallet.ParseUnits(amount, 18)
+		if err != nil {
+			sendLog.WithError(err).Error("Invalid amount")
+			return ExitConfigError
+		}
+		status, err := client.SendTransaction(ctx, chain, recipient, nil, value)
+		if err != nil {
+			sendLog.WithError(err).Error("Failed to send transaction")
+			return ExitFatalTaskError
+		}
+		hash = status.Hash
+	} else {
+		tokenAddress := common.HexToAddress(token)
+		metadata, err := client.GetTokenMetadata(ctx, chain, tokenAddress)
+		if err != nil {
+			sendLog.WithError(err).WithField("token", token).Error("Failed to read token metadata")
+			return ExitFatalTaskError
+		}
+		value, err := wallet.ParseUnits(amount, metadata.Decimals)
+		if err != nil {
+			sendLog.WithError(err).Error("Invalid amount")
+			return ExitConfigError
+		}
+		sent, err := client.TransferERC20(ctx, chain, tokenAddress, recipient, value)
+		if err != nil {
+			sendLog.WithError(err).WithField("token", metadata.Symbol).Error("Failed to transfer token")
+			return ExitFatalTaskError
+		}
+		hash = *sent
+		sendLog = sendLog.WithField("token", metadata.Symbol)
+	}
+
+	sendLog.WithField("hash", hash.Hex()).Info("Transfer sent")
+	return ExitCleanShutdown
+}
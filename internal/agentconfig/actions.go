@@ -1,13 +1,27 @@
 package agentconfig
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/lisanmuaddib/agent-go/internal/personality/traits"
 	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/experiments"
+	"github.com/lisanmuaddib/agent-go/pkg/filters"
+	"github.com/lisanmuaddib/agent-go/pkg/imagegen"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/farcaster"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/lens"
 	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/lock"
+	"github.com/lisanmuaddib/agent-go/pkg/market"
 	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/plugins"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
 	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
 	"github.com/sirupsen/logrus"
 	"github.com/tmc/langchaingo/llms"
 )
@@ -22,20 +36,253 @@ const (
 	// Example: OriginalThoughtInterval = 30 * time.Minute
 	OriginalThoughtInterval = 30 * time.Minute
 
-	// TweetResponseInterval is how often the agent processes and responds to pending tweets
-	// Example: TweetResponseInterval = 5 * time.Minute
-	TweetResponseInterval = 15 * time.Second
+	// ThoughtTopicAvoidDays is how many days a thought topic is not repeated
+	// Example: ThoughtTopicAvoidDays = 3
+	ThoughtTopicAvoidDays = 7
+
+	// ReplyQueuePollInterval is how often idle reply workers check the queue for tweets to answer
+	// Example: ReplyQueuePollInterval = 30 * time.Second
+	ReplyQueuePollInterval = 5 * time.Second
+
+	// AnalyticsInterval is how often mention volume and conversation engagement are recorded
+	// Example: AnalyticsInterval = 1 * time.Hour
+	AnalyticsInterval = 1 * time.Hour
+
+	// MetricsRefreshInterval is how often public metrics of the bot's own tweets are re-fetched
+	// Example: MetricsRefreshInterval = 30 * time.Minute
+	MetricsRefreshInterval = 1 * time.Hour
+
+	// ReplyQualityInterval is how often the bot's replies are checked for
+	// deletion or missing engagement
+	// Example: ReplyQualityInterval = 6 * time.Hour
+	ReplyQualityInterval = 2 * time.Hour
+
+	// AnalyticsReportInterval is how often daily engagement reports are rebuilt
+	// Example: AnalyticsReportInterval = 24 * time.Hour
+	AnalyticsReportInterval = 6 * time.Hour
+
+	// RoastInterval is how often the Judgment Throne judges a recent mention author
+	// Example: RoastInterval = 12 * time.Hour
+	RoastInterval = 6 * time.Hour
+
+	// CuratorInterval is how often highly scored judgments are curated into a list and bookmarks
+	// Example: CuratorInterval = 24 * time.Hour
+	CuratorInterval = 6 * time.Hour
+
+	// FarcasterMentionsInterval is how often Farcaster mentions are checked and answered
+	// Example: FarcasterMentionsInterval = 5 * time.Minute
+	FarcasterMentionsInterval = time.Minute
+
+	// ConversationArchiveInterval is how often completed conversations are archived
+	// Example: ConversationArchiveInterval = 6 * time.Hour
+	ConversationArchiveInterval = time.Hour
+
+	// EditWatchInterval is how often recently stored mentions are checked for edits
+	// Example: EditWatchInterval = 15 * time.Minute
+	EditWatchInterval = 10 * time.Minute
+
+	// ComplianceInterval is how often tweets waiting for a reply are checked
+	// for deletion
+	// Example: ComplianceInterval = 30 * time.Minute
+	ComplianceInterval = 15 * time.Minute
+
+	// RetentionInterval is how often rows past their retention age are pruned
+	// Example: RetentionInterval = 24 * time.Hour
+	RetentionInterval = 6 * time.Hour
+
+	// CredentialCheckInterval is how often each account's credentials are
+	// checked with a users/me call
+	// Example: CredentialCheckInterval = 15 * time.Minute
+	CredentialCheckInterval = 5 * time.Minute
+
+	// QuotaRecordInterval is how often the API consumption per endpoint is
+	// saved to the database
+	// Example: QuotaRecordInterval = 5 * time.Minute
+	QuotaRecordInterval = time.Minute
+
+	// SummaryInterval is how often conversation and user summaries are
+	// updated with the tweets stored since
+	// Example: SummaryInterval = 12 * time.Hour
+	SummaryInterval = 24 * time.Hour
 )
 
+// DefaultBudgetShares assigns the built-in actions, by name, to the budget
+// shares their Twitter requests count against
+var DefaultBudgetShares = map[string]string{
+	"reply_workers":           twitter.BudgetReplies,
+	"original_thought_poster": twitter.BudgetReplies,
+	"judgment_throne":         twitter.BudgetReplies,
+	"transfer_watch":          twitter.BudgetReplies,
+	"receipt_notifier":        twitter.BudgetReplies,
+	"mentions_handler":        twitter.BudgetReads,
+	"edit_watcher":            twitter.BudgetReads,
+	"compliance":              twitter.BudgetReads,
+	"credential_monitor":      twitter.BudgetReads,
+	"curator":                 twitter.BudgetReads,
+	"analytics":               twitter.BudgetAnalytics,
+	"analytics_report":        twitter.BudgetAnalytics,
+	"metrics_refresher":       twitter.BudgetAnalytics,
+	"reply_quality_tracker":   twitter.BudgetAnalytics,
+}
+
+// BudgetShares returns DefaultBudgetShares with the given assignments added
+func BudgetShares(assignments map[string]string) map[string]string {
+	shares := make(map[string]string, len(DefaultBudgetShares)+len(assignments))
+	for action, share := range DefaultBudgetShares {
+		shares[action] = share
+	}
+	for action, share := range assignments {
+		shares[action] = share
+	}
+	return shares
+}
+
 type ActionConfig struct {
 	TwitterClient *twitter.TwitterClient
 	LLM           llms.Model
 	Logger        *logrus.Logger
 	TweetStore    *memory.TweetStore
+	Market        *market.Client
+	// ReplyLLM, ThoughtLLM and JudgmentLLM generate replies, original thoughts
+	// and judgments, e.g. with fine-tuned models; nil uses LLM
+	ReplyLLM    llms.Model
+	ThoughtLLM  llms.Model
+	JudgmentLLM llms.Model
+	// SpamFilter scores incoming mentions; nil stores every mention unscored
+	SpamFilter *filters.SpamFilter
+	// Bots skips mentions by known or suspected bots; nil only skips the
+	// bot's own content
+	Bots *filters.BotGuard
+	// Sentiment scores stored mentions so replies adapt their tone; nil keeps
+	// the default tone
+	Sentiment *filters.SentimentAnalyzer
+	// Experiment A/B tests reply prompt variants; nil uses the base prompt only
+	Experiment *experiments.Experiment
+	// Events receives agent activity for external subscribers; nil disables events
+	Events *events.Bus
+	// Locker keeps instances sharing the database from handling the same
+	// mentions or conversations; nil assumes a single instance
+	Locker lock.Locker
+
+	// AccountName namespaces action names when several accounts run in one process
+	AccountName string
+	// Personality overrides the base persona for this account, with the
+	// sections some interaction categories use instead
+	Personality traits.Persona
+	// TweetsPerWindow gives this account its own reply budget per 15 minutes
+	TweetsPerWindow int
+	// Throttle limits replies per user and per conversation
+	Throttle actions.ThrottleOptions
+	// Loops closes conversations that go in circles
+	Loops actions.LoopOptions
+	// LatencySLO is the longest a mention should wait for its reply; slower
+	// replies emit an event, 0 emits none
+	LatencySLO time.Duration
+	// SelfCritique critiques and revises every drafted reply once before it
+	// is posted
+	SelfCritique bool
+	// Researcher looks up the facts each mention needs with the tool-calling
+	// loop before its reply is generated; nil skips the lookup
+	Researcher actions.Researcher
+	// Tipper runs tip and wallet commands in mentions instead of answering
+	// them with the model, and with Events follows up once the tips' transactions
+	// confirm; nil answers every mention
+	Tipper *actions.Tipper
+	// ReplyWorkers sizes the pool answering queued mentions; its budget is
+	// TweetsPerWindow
+	ReplyWorkers actions.ReplyWorkerOptions
+	// MentionsPolling bounds the mention polling interval, which adapts to
+	// activity; the zero value polls every MentionsCheckInterval
+	MentionsPolling actions.AdaptiveInterval
+	// Schedule holds original thoughts, replies, judgments, receipts and
+	// thank-yous during quiet hours and embargoes; nil posts at any time
+	Schedule *schedule.Policy
+	// Credentials checks the account's credentials and holds original
+	// thoughts and replies while they fail; nil posts regardless
+	Credentials *actions.CredentialMonitor
+	// ContextBuilder fits earlier posts of a conversation into reply prompts;
+	// nil uses thoughts.DefaultContextTokens
+	ContextBuilder *thoughts.ContextBuilder
+	// Examples puts the curated reply examples closest to each answered tweet
+	// in the reply prompt and collects failed replies as negative examples;
+	// nil leaves examples out
+	Examples *actions.ReplyExamples
+
+	// FarcasterClient and FarcasterStore run the persona on Farcaster as well;
+	// nil leaves Farcaster off
+	FarcasterClient *farcaster.Client
+	FarcasterStore  *memory.TweetStore
+	// LensClient cross-posts original thoughts to Lens; nil leaves Lens off
+	LensClient *lens.Client
+	// ArchivePublisher receives completed conversations of the account's
+	// stores; nil leaves archiving off
+	ArchivePublisher archive.Publisher
+	ArchiveOptions   archive.Options
+	// Retention prunes the account's old rows; without policies every row is
+	// kept
+	Retention actions.RetentionOptions
+	// MemeGenerator attaches generated memes to some replies; nil keeps replies
+	// text-only
+	MemeGenerator imagegen.Generator
+	MemeOptions   actions.MemeOptions
+	// Wallet watches for incoming token transfers and thanks their senders
+	// when TransferWatch.Thank is set; nil leaves the watch off
+	Wallet        *wallet.Client
+	TransferWatch actions.TransferWatchOptions
+	// Plugins add out-of-tree actions to the account's actions
+	Plugins []plugins.Spec
+	// BudgetShares assigns actions, by name, to the budget shares their
+	// Twitter requests count against; nil uses DefaultBudgetShares
+	BudgetShares map[string]string
 }
 
-// ConfigureActions sets up all agent actions
-func ConfigureActions(config ActionConfig) ([]actions.Action, error) {
+// generator returns the model of a generator, or LLM when it has none
+func (c ActionConfig) generator(model llms.Model) llms.Model {
+	if model != nil {
+		return model
+	}
+	return c.LLM
+}
+
+// accountAction prefixes an action's name with its account so that actions of
+// different accounts can be registered side by side
+type accountAction struct {
+	actions.Action
+	account string
+}
+
+func (a *accountAction) Name() string {
+	return a.account + "/" + a.Action.Name()
+}
+
+// SetInterval forwards to the wrapped action when it runs on a ticker
+func (a *accountAction) SetInterval(interval time.Duration) {
+	if setter, ok := a.Action.(actions.IntervalSetter); ok {
+		setter.SetInterval(interval)
+	}
+}
+
+// budgetAction runs an action with its Twitter requests counted against a
+// budget share
+type budgetAction struct {
+	actions.Action
+	share string
+}
+
+func (a *budgetAction) Execute(ctx context.Context) error {
+	return a.Action.Execute(twitter.WithBudgetShare(ctx, a.share))
+}
+
+// SetInterval forwards to the wrapped action when it runs on a ticker
+func (a *budgetAction) SetInterval(interval time.Duration) {
+	if setter, ok := a.Action.(actions.IntervalSetter); ok {
+		setter.SetInterval(interval)
+	}
+}
+
+// NewMentionsHandler creates the handler that stores mentions and queues them
+// for reply
+func NewMentionsHandler(config ActionConfig) (*actions.MentionsHandler, error) {
 	mentionsHandler, err := actions.NewMentionsHandler(
 		config.TwitterClient,
 		config.LLM,
@@ -44,42 +291,313 @@ func ConfigureActions(config ActionConfig) ([]actions.Action, error) {
 		actions.MentionsOptions{
 			Interval:   MentionsCheckInterval,
 			MaxResults: 100,
+			SpamFilter: config.SpamFilter,
+			Bots:       config.Bots,
+			Sentiment:  config.Sentiment,
+			Events:     config.Events,
+			Locker:     config.Locker,
+			Adaptive:   config.MentionsPolling,
+			Authors:    actions.NewAuthorResolver(config.TwitterClient, config.Logger, actions.DefaultAuthorCacheTTL),
 		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create mentions handler: %w", err)
 	}
+	return mentionsHandler, nil
+}
+
+// NewThoughtAction creates the action posting original thoughts on topics
+// chosen by the topic planner
+func NewThoughtAction(config ActionConfig) *actions.OriginalThoughtAction {
+	var watchlist []string
+	if config.Market != nil {
+		watchlist = config.Market.Watchlist()
+	}
+	topicPlanner := actions.NewTopicPlanner(config.TweetStore, config.Logger, actions.TopicPlannerOptions{
+		AvoidDays: ThoughtTopicAvoidDays,
+		Tokens:    watchlist,
+	})
 
-	thoughtAction := actions.NewOriginalThoughtAction(
-		thoughts.NewOriginalThoughtGenerator(config.LLM),
+	return actions.NewOriginalThoughtAction(
+		thoughts.NewOriginalThoughtGenerator(config.generator(config.ThoughtLLM)),
 		config.TwitterClient,
 		config.Logger,
 		actions.ThoughtOptions{
-			Interval: OriginalThoughtInterval,
+			Interval:    OriginalThoughtInterval,
+			Planner:     topicPlanner,
+			Store:       config.TweetStore,
+			Market:      config.Market,
+			Personality: config.Personality.Sections,
+			Lens:        config.LensClient,
+			Schedule:    config.Schedule,
+			Credentials: config.Credentials,
+		},
+	)
+}
+
+// NewTweetResponder creates the responder answering conversations of the account
+func NewTweetResponder(config ActionConfig) *actions.TweetResponder {
+	replyLLM := config.generator(config.ReplyLLM)
+	var critic thoughts.ReplyCritic
+	if config.SelfCritique {
+		critic = thoughts.NewReplyCritic(replyLLM)
+	}
+	return actions.NewTweetResponder(
+		config.TweetStore,
+		config.TwitterClient,
+		config.Logger,
+		thoughts.NewMentionReplyGenerator(replyLLM),
+	).WithRateLimit(config.TweetsPerWindow).
+		WithPersonality(config.Personality).
+		WithExperiment(config.Experiment).
+		WithEvents(config.Events).
+		WithThrottle(config.Throttle).
+		WithLoopDetection(config.Loops).
+		WithLatencySLO(config.LatencySLO).
+		WithCritic(critic).
+		WithResearcher(config.Researcher).
+		WithTipper(config.Tipper).
+		WithLocker(config.Locker).
+		WithContextBuilder(config.ContextBuilder).
+		WithExamples(config.Examples).
+		WithMemes(config.MemeGenerator, config.MemeOptions)
+}
+
+// NewReplyWorkers creates the pool answering queued mentions
+func NewReplyWorkers(config ActionConfig) *actions.ReplyWorkerPool {
+	workerOptions := config.ReplyWorkers
+	workerOptions.TweetsPerWindow = config.TweetsPerWindow
+	workerOptions.PollInterval = ReplyQueuePollInterval
+	workerOptions.Schedule = config.Schedule
+	workerOptions.Credentials = config.Credentials
+	return actions.NewReplyWorkerPool(NewTweetResponder(config), config.Logger, workerOptions)
+}
+
+// NewComplianceAction creates the action that removes deleted tweets from the
+// account's store
+func NewComplianceAction(config ActionConfig) *actions.ComplianceAction {
+	return actions.NewComplianceAction(
+		config.TwitterClient,
+		config.TweetStore,
+		config.Logger,
+		actions.ComplianceOptions{Interval: ComplianceInterval},
+	)
+}
+
+// NewCredentialMonitor creates the monitor of the account's credentials
+func NewCredentialMonitor(config ActionConfig) *actions.CredentialMonitor {
+	return actions.NewCredentialMonitor(
+		config.TwitterClient,
+		config.TweetStore,
+		config.Logger,
+		actions.CredentialMonitorOptions{
+			Interval: CredentialCheckInterval,
+			Events:   config.Events,
+		},
+	)
+}
+
+// ConfigureActions sets up all agent actions
+func ConfigureActions(config ActionConfig) ([]actions.Action, error) {
+	mentionsHandler, err := NewMentionsHandler(config)
+	if err != nil {
+		return nil, err
+	}
+	thoughtAction := NewThoughtAction(config)
+	replyWorkers := NewReplyWorkers(config)
+
+	analyticsAction := actions.NewAnalyticsAction(
+		config.TwitterClient,
+		config.TweetStore,
+		config.Logger,
+		actions.AnalyticsOptions{
+			Interval:     AnalyticsInterval,
+			LookbackDays: 2,
 		},
 	)
 
-	replyGenerator := thoughts.NewMentionReplyGenerator(config.LLM)
+	reportAction := actions.NewAnalyticsReportAction(
+		config.TwitterClient,
+		config.TweetStore,
+		config.Logger,
+		actions.AnalyticsReportOptions{
+			Interval: AnalyticsReportInterval,
+		},
+	)
 
-	tweetResponder := actions.NewTweetResponder(
+	metricsRefresher := actions.NewMetricsRefresher(
+		config.TwitterClient,
 		config.TweetStore,
+		config.Logger,
+		actions.MetricsRefreshOptions{
+			Interval: MetricsRefreshInterval,
+			Lookback: 72 * time.Hour,
+		},
+	)
+
+	editWatcher := actions.NewEditWatcher(
 		config.TwitterClient,
+		config.TweetStore,
 		config.Logger,
-		replyGenerator,
+		actions.EditWatchOptions{
+			Interval: EditWatchInterval,
+			Lookback: 2 * time.Hour,
+			Events:   config.Events,
+		},
 	)
 
-	tweetResponseAction := actions.NewTweetResponseAction(
-		tweetResponder,
+	roastAction := actions.NewRoastAction(
+		config.TwitterClient,
+		config.TweetStore,
+		thoughts.NewStructuredJudgment(config.generator(config.JudgmentLLM)),
 		config.Logger,
-		actions.TweetResponseOptions{
-			Interval:    TweetResponseInterval,
-			BatchConfig: actions.DefaultBatchConfig(),
+		actions.RoastOptions{
+			Interval:    RoastInterval,
+			Cooldown:    actions.DefaultRoastCooldown,
+			Lookback:    24 * time.Hour,
+			Personality: config.Personality.For(actions.RoastCategory),
+			Schedule:    config.Schedule,
 		},
 	)
 
-	return []actions.Action{
+	curatorAction := actions.NewCuratorAction(
+		config.TwitterClient,
+		config.TweetStore,
+		config.Logger,
+		actions.CuratorOptions{Interval: CuratorInterval},
+	)
+
+	configured := []actions.Action{
 		mentionsHandler,
 		thoughtAction,
-		tweetResponseAction,
-	}, nil
+		replyWorkers,
+		analyticsAction,
+		reportAction,
+		metricsRefresher,
+		editWatcher,
+		NewComplianceAction(config),
+		roastAction,
+		curatorAction,
+		actions.NewQuotaRecorder(
+			config.TwitterClient,
+			config.TweetStore,
+			config.Logger,
+			actions.QuotaRecorderOptions{Interval: QuotaRecordInterval},
+		),
+		actions.NewMemorySummarizer(
+			config.TweetStore,
+			thoughts.NewSummaryGenerator(config.LLM),
+			config.Logger,
+			actions.SummarizerOptions{Interval: SummaryInterval},
+		),
+	}
+
+	if config.Credentials != nil {
+		configured = append(configured, config.Credentials)
+	}
+
+	// Negative examples are recorded into the example library
+	if config.Examples != nil {
+		configured = append(configured, actions.NewReplyQualityTracker(
+			config.TwitterClient,
+			config.TweetStore,
+			config.Examples,
+			config.Logger,
+			actions.ReplyQualityOptions{
+				Interval: ReplyQualityInterval,
+				MinAge:   24 * time.Hour,
+				Lookback: 72 * time.Hour,
+			},
+		))
+	}
+
+	if config.FarcasterClient != nil && config.FarcasterStore != nil {
+		configured = append(configured, actions.NewFarcasterMentionsHandler(
+			config.FarcasterClient,
+			config.FarcasterStore,
+			thoughts.NewMentionReplyGenerator(config.generator(config.ReplyLLM)),
+			config.Logger,
+			actions.FarcasterMentionsOptions{
+				Interval:    FarcasterMentionsInterval,
+				Personality: config.Personality,
+				Events:      config.Events,
+				Sentiment:   config.Sentiment,
+				Context:     config.ContextBuilder,
+			},
+		))
+	}
+
+	if config.ArchivePublisher != nil {
+		archivers := []*archive.Archiver{
+			archive.New(config.TweetStore, config.ArchivePublisher, config.Logger, config.ArchiveOptions),
+		}
+		if config.FarcasterStore != nil {
+			archivers = append(archivers, archive.New(config.FarcasterStore, config.ArchivePublisher, config.Logger, config.ArchiveOptions))
+		}
+		configured = append(configured, actions.NewConversationArchiveAction(
+			archivers,
+			config.Logger,
+			actions.ConversationArchiveOptions{Interval: ConversationArchiveInterval},
+		))
+	}
+
+	if len(config.Retention.Policies) > 0 {
+		retention := config.Retention
+		retention.Interval = RetentionInterval
+		configured = append(configured, actions.NewRetentionAction(config.TweetStore, config.Logger, retention))
+	}
+
+	if config.Wallet != nil {
+		transferWatch := config.TransferWatch
+		transferWatch.Schedule = config.Schedule
+		configured = append(configured, actions.NewTransferWatchAction(
+			config.Wallet,
+			config.TwitterClient,
+			config.Logger,
+			transferWatch,
+		))
+	}
+
+	// Tips are followed up once their transactions confirm
+	if config.Tipper != nil && config.Events != nil {
+		configured = append(configured, actions.NewReceiptNotifier(
+			config.Events,
+			config.TwitterClient,
+			config.TweetStore,
+			config.Logger,
+		).WithSchedule(config.Schedule))
+	}
+
+	if len(config.Plugins) > 0 {
+		pluginActions, err := plugins.Load(config.Plugins, plugins.Host{
+			Account:       config.AccountName,
+			Logger:        config.Logger,
+			TwitterClient: config.TwitterClient,
+			TweetStore:    config.TweetStore,
+			LLM:           config.LLM,
+			Events:        config.Events,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plugins: %w", err)
+		}
+		configured = append(configured, pluginActions...)
+	}
+
+	shares := config.BudgetShares
+	if shares == nil {
+		shares = DefaultBudgetShares
+	}
+	for i, action := range configured {
+		if share, ok := shares[action.Name()]; ok {
+			configured[i] = &budgetAction{Action: action, share: share}
+		}
+	}
+
+	if config.AccountName != "" {
+		for i, action := range configured {
+			configured[i] = &accountAction{Action: action, account: config.AccountName}
+		}
+	}
+
+	return configured, nil
 }
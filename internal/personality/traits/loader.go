@@ -0,0 +1,71 @@
+package traits
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// categoriesKey holds the per-category section sets in a personality file
+const categoriesKey = "categories"
+
+// LoadPromptSections reads a persona from a YAML file mapping section names to
+// prompt text, in the same shape as BasePromptSections. An empty path returns
+// the built-in persona. Category section sets in the file are ignored; use
+// LoadPersona for those.
+func LoadPromptSections(path string) (map[string]string, error) {
+	persona, err := LoadPersona(path)
+	if err != nil {
+		return nil, err
+	}
+	return persona.Sections, nil
+}
+
+// LoadPersona reads a persona from a YAML file mapping section names to
+// prompt text. The optional categories key maps an interaction category, such
+// as "quote" or "roast", to sections that replace or add to the others for
+// that category:
+//
+//	Personality: ...
+//	Interaction Style: ...
+//	categories:
+//	  roast:
+//	    Interaction Style: ...
+//
+// An empty path returns the built-in persona.
+func LoadPersona(path string) (Persona, error) {
+	if path == "" {
+		return Persona{Sections: BasePromptSections}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Persona{}, fmt.Errorf("failed to read personality file %s: %w", path, err)
+	}
+
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Persona{}, fmt.Errorf("failed to parse personality file %s: %w", path, err)
+	}
+
+	persona := Persona{Sections: make(map[string]string, len(raw))}
+	for name, node := range raw {
+		if name == categoriesKey {
+			if err := node.Decode(&persona.Categories); err != nil {
+				return Persona{}, fmt.Errorf("failed to parse categories of personality file %s: %w", path, err)
+			}
+			continue
+		}
+		var text string
+		if err := node.Decode(&text); err != nil {
+			return Persona{}, fmt.Errorf("failed to parse section %q of personality file %s: %w", name, path, err)
+		}
+		persona.Sections[name] = text
+	}
+	if len(persona.Sections) == 0 {
+		return Persona{}, fmt.Errorf("personality file %s defines no sections", path)
+	}
+
+	return persona, nil
+}
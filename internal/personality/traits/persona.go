@@ -0,0 +1,34 @@
+package traits
+
+// Persona is the set of prompt sections an account speaks with, and the
+// section sets that replace some of them for one kind of interaction
+type Persona struct {
+	// Sections are used for every interaction
+	Sections map[string]string
+	// Categories maps an interaction category, such as "mention", "quote",
+	// "dm" or "roast", to sections that replace or add to Sections for that
+	// category. An empty section leaves it out.
+	Categories map[string]map[string]string
+}
+
+// For returns the sections to use for category. Without a set for the
+// category, Sections is returned as is.
+func (p Persona) For(category string) map[string]string {
+	overrides, ok := p.Categories[category]
+	if !ok || len(overrides) == 0 {
+		return p.Sections
+	}
+
+	sections := make(map[string]string, len(p.Sections)+len(overrides))
+	for name, text := range p.Sections {
+		sections[name] = text
+	}
+	for name, text := range overrides {
+		if text == "" {
+			delete(sections, name)
+			continue
+		}
+		sections[name] = text
+	}
+	return sections
+}
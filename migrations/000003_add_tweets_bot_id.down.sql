@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_tweets_bot_id_conversation_id;
+
+ALTER TABLE tweets DROP CONSTRAINT tweets_pkey;
+DELETE FROM tweets a USING tweets b
+    WHERE a.id = b.id AND a.bot_id > b.bot_id;
+ALTER TABLE tweets ADD PRIMARY KEY (id);
+
+ALTER TABLE tweets DROP COLUMN bot_id;
@@ -0,0 +1,8 @@
+-- Partition stored tweets by the bot account that observed them so several
+-- personas can share one database
+ALTER TABLE tweets ADD COLUMN bot_id TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE tweets DROP CONSTRAINT tweets_pkey;
+ALTER TABLE tweets ADD PRIMARY KEY (bot_id, id);
+
+CREATE INDEX idx_tweets_bot_id_conversation_id ON tweets(bot_id, conversation_id);
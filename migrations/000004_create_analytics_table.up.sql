@@ -0,0 +1,20 @@
+-- Daily analytics per bot account. Mention volume rows leave conversation_id
+-- empty; conversation engagement rows hold one conversation each.
+CREATE TABLE analytics (
+    bot_id TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    conversation_id TEXT NOT NULL DEFAULT '',
+
+    tweet_count INTEGER NOT NULL DEFAULT 0,
+    bot_reply_count INTEGER NOT NULL DEFAULT 0,
+    like_count INTEGER NOT NULL DEFAULT 0,
+    retweet_count INTEGER NOT NULL DEFAULT 0,
+    reply_count INTEGER NOT NULL DEFAULT 0,
+    quote_count INTEGER NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, metric, period_start, conversation_id)
+);
+
+CREATE INDEX idx_analytics_period_start ON analytics(period_start);
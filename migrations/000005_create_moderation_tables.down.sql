@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS muted_conversations;
+DROP TABLE IF EXISTS blocked_users;
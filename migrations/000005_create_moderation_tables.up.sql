@@ -0,0 +1,20 @@
+-- Users the agent never replies to and conversations it has disengaged from.
+-- An empty bot_id applies the entry to every bot account.
+CREATE TABLE blocked_users (
+    bot_id TEXT NOT NULL DEFAULT '',
+    user_id TEXT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, user_id)
+);
+
+CREATE TABLE muted_conversations (
+    bot_id TEXT NOT NULL DEFAULT '',
+    conversation_id TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, conversation_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE tweets DROP COLUMN spam_score;
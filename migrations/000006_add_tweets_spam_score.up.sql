@@ -0,0 +1,3 @@
+-- Spam likelihood of a stored mention between 0 and 1, set by the spam filter.
+-- Tweets at or above the configured threshold are excluded from replies.
+ALTER TABLE tweets ADD COLUMN spam_score DOUBLE PRECISION NOT NULL DEFAULT 0;
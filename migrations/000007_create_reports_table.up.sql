@@ -0,0 +1,15 @@
+-- Daily engagement report per bot account, built by the analytics report action
+CREATE TABLE reports (
+    bot_id TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+
+    replies_posted INTEGER NOT NULL DEFAULT 0,
+    avg_response_seconds INTEGER NOT NULL DEFAULT 0,
+    likes_received INTEGER NOT NULL DEFAULT 0,
+    retweets_received INTEGER NOT NULL DEFAULT 0,
+    replies_received INTEGER NOT NULL DEFAULT 0,
+    quotes_received INTEGER NOT NULL DEFAULT 0,
+    generated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, period_start)
+);
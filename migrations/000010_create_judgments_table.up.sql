@@ -0,0 +1,14 @@
+-- Structured ratings the agent gave users (e.g. Bio Cringe Factor 0-10), for roasts
+CREATE TABLE judgments (
+    id BIGSERIAL PRIMARY KEY,
+    bot_id TEXT NOT NULL,
+    subject_id TEXT NOT NULL DEFAULT '',
+    subject_username TEXT NOT NULL,
+    tweet_id TEXT NOT NULL DEFAULT '',
+    scores JSONB NOT NULL,
+    overall DOUBLE PRECISION NOT NULL DEFAULT 0,
+    verdict TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_judgments_bot_subject ON judgments (bot_id, subject_username, created_at);
@@ -0,0 +1,13 @@
+-- Where completed conversations were published for the public archive
+CREATE TABLE conversation_archives (
+    id BIGSERIAL PRIMARY KEY,
+    bot_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    tweet_count INTEGER NOT NULL DEFAULT 0,
+    last_tweet_at TIMESTAMP NOT NULL,
+    uri TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    sha256 TEXT NOT NULL DEFAULT '',
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (bot_id, conversation_id)
+);
@@ -0,0 +1,16 @@
+-- Tweets waiting for a reply worker. A claimed item stays hidden until
+-- visible_at, so it is picked up again if its worker dies before finishing.
+CREATE TABLE reply_queue (
+    bot_id TEXT NOT NULL,
+    tweet_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    visible_at TIMESTAMP NOT NULL,
+    claimed_by TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (bot_id, tweet_id)
+);
+
+CREATE INDEX idx_reply_queue_ready ON reply_queue (bot_id, visible_at);
@@ -0,0 +1,2 @@
+ALTER TABLE judgments DROP COLUMN curated_at;
+ALTER TABLE judgments DROP COLUMN subject_tweet_id;
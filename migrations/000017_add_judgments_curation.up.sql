@@ -0,0 +1,4 @@
+-- The judged user's tweet, and when the curator added the subject to its list
+-- and bookmarked that tweet
+ALTER TABLE judgments ADD COLUMN subject_tweet_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE judgments ADD COLUMN curated_at TIMESTAMP NULL;
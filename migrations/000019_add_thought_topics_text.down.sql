@@ -0,0 +1 @@
+ALTER TABLE thought_topics DROP COLUMN text;
@@ -0,0 +1,3 @@
+-- Text of each posted thought, so new thoughts can be checked for
+-- near-duplicates before they are posted
+ALTER TABLE thought_topics ADD COLUMN text TEXT NOT NULL DEFAULT '';
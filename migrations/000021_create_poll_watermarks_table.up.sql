@@ -0,0 +1,9 @@
+-- Newest tweet ID each bot has seen per polled timeline, passed as since_id so
+-- a poll only fetches what is new
+CREATE TABLE poll_watermarks (
+    bot_id TEXT NOT NULL,
+    stream TEXT NOT NULL,
+    newest_id TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (bot_id, stream)
+);
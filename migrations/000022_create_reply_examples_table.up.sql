@@ -0,0 +1,13 @@
+-- Curated tweets and the ideal reply to each, shown to the model as examples
+-- of the bot's voice. The embedding of the tweet is stored as a JSON array.
+CREATE TABLE reply_examples (
+    id BIGSERIAL PRIMARY KEY,
+    bot_id TEXT NOT NULL,
+    tweet_text TEXT NOT NULL,
+    reply_text TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    embedding TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reply_examples_bot ON reply_examples (bot_id);
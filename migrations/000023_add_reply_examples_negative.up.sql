@@ -0,0 +1,8 @@
+-- Negative examples are replies of the bot that were deleted or got no
+-- engagement; prompts steer away from them. source_tweet_id is the failed
+-- reply, so each one is recorded only once.
+ALTER TABLE reply_examples ADD COLUMN negative BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE reply_examples ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE reply_examples ADD COLUMN source_tweet_id TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX idx_reply_examples_source ON reply_examples (bot_id, source_tweet_id) WHERE source_tweet_id <> '';
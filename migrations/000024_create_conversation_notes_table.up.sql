@@ -0,0 +1,13 @@
+-- Operator instructions for one conversation, included in the prompt of every
+-- reply in it until they expire. An empty bot_id applies the note to every
+-- bot account; a NULL expires_at keeps it until it is removed.
+CREATE TABLE conversation_notes (
+    id BIGSERIAL PRIMARY KEY,
+    bot_id TEXT NOT NULL DEFAULT '',
+    conversation_id TEXT NOT NULL,
+    note TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX idx_conversation_notes_conversation ON conversation_notes (conversation_id, bot_id);
@@ -0,0 +1,2 @@
+ALTER TABLE tweets DROP COLUMN edited_after_reply;
+ALTER TABLE tweets DROP COLUMN edited_at;
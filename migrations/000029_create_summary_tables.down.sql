@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_summaries;
+DROP TABLE IF EXISTS conversation_summaries;
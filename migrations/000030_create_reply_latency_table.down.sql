@@ -0,0 +1 @@
+DROP TABLE IF EXISTS reply_latency;
@@ -0,0 +1,15 @@
+-- The draft, critique and revision of each reply posted in self-critique
+-- mode, for comparing what the critique changed with how replies perform.
+CREATE TABLE reply_stages (
+    bot_id TEXT NOT NULL,
+    reply_id TEXT NOT NULL,
+    tweet_id TEXT NOT NULL,
+    draft TEXT NOT NULL,
+    critique TEXT NOT NULL DEFAULT '',
+    revision TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, reply_id)
+);
+
+CREATE INDEX idx_reply_stages_created_at ON reply_stages(bot_id, created_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_tips_tx_hash;
@@ -0,0 +1,2 @@
+-- Find the tip a confirmed transaction paid, to reply with its receipt
+CREATE INDEX idx_tips_tx_hash ON tips(bot_id, tx_hash) WHERE tx_hash <> '';
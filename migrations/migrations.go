@@ -0,0 +1,17 @@
+// Package migrations embeds the versioned SQL schema migrations so the agent
+// binary can apply them without access to the source tree. Postgres migrations
+// live at the top level and SQLite migrations in the sqlite directory.
+package migrations
+
+import "embed"
+
+// FS holds every migration file
+//
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
+
+// Directories within FS for each database driver
+const (
+	PostgresDir = "."
+	SQLiteDir   = "sqlite"
+)
@@ -0,0 +1,69 @@
+-- SQLite schema for the tweets table. Postgres jsonb columns are stored as JSON
+-- text, the tweet_category enum becomes a CHECK constraint and the text[]
+-- edit history keeps the Postgres array literal format ({id1,id2}).
+CREATE TABLE tweets (
+    -- Primary Key
+    bot_id TEXT NOT NULL DEFAULT '',
+    id TEXT NOT NULL,
+
+    -- Core Tweet Fields
+    text TEXT NOT NULL,
+    conversation_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Author Information
+    author_id TEXT NOT NULL,
+    author_name TEXT,
+    author_username TEXT,
+
+    -- Operational Fields (for internal processing)
+    category TEXT NOT NULL CHECK (category IN ('mention', 'reply', 'quote', 'retweet', 'dm', 'conversation')),
+    processed_at TIMESTAMP NOT NULL,
+    last_updated TIMESTAMP NOT NULL,
+    process_count INTEGER DEFAULT 0,
+    conversation_ref TEXT,
+    needs_reply BOOLEAN DEFAULT TRUE,
+    is_participating BOOLEAN DEFAULT FALSE,
+
+    -- Reply Tracking Fields
+    replied_to BOOLEAN DEFAULT FALSE,
+    last_reply_id TEXT,
+    last_reply_time TIMESTAMP,
+    reply_count INTEGER DEFAULT 0,
+    unread_replies INTEGER DEFAULT 0,
+    in_reply_to_user_id TEXT,
+
+    -- Twitter API Response Fields
+    attachments TEXT,
+    context_annotations TEXT,
+    edit_controls TEXT,
+    edit_history_tweet_ids TEXT,
+    entities TEXT,
+    geo TEXT,
+    lang TEXT,
+    non_public_metrics TEXT,
+    organic_metrics TEXT,
+    possibly_sensitive BOOLEAN,
+    promoted_metrics TEXT,
+    public_metrics TEXT,
+    referenced_tweets TEXT,
+    reply_settings TEXT,
+    source TEXT,
+    withheld TEXT,
+
+    PRIMARY KEY (bot_id, id)
+);
+
+-- Core Indexes
+CREATE INDEX idx_tweets_conversation_id ON tweets(conversation_id);
+CREATE INDEX idx_tweets_bot_id_conversation_id ON tweets(bot_id, conversation_id);
+CREATE INDEX idx_tweets_author_id ON tweets(author_id);
+CREATE INDEX idx_tweets_author_name ON tweets(author_name);
+CREATE INDEX idx_tweets_author_username ON tweets(author_username);
+CREATE INDEX idx_tweets_category ON tweets(category);
+CREATE INDEX idx_tweets_created_at ON tweets(created_at);
+
+-- Reply Processing Indexes
+CREATE INDEX idx_tweets_needs_reply ON tweets(needs_reply) WHERE needs_reply = TRUE;
+CREATE INDEX idx_tweets_replied_to ON tweets(replied_to);
+CREATE INDEX idx_tweets_last_reply_time ON tweets(last_reply_time);
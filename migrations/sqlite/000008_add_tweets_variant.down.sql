@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tweets_variant;
+ALTER TABLE tweets DROP COLUMN variant;
@@ -0,0 +1,4 @@
+-- Prompt variant (experiment/variant) that generated one of the bot's replies
+ALTER TABLE tweets ADD COLUMN variant TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_tweets_variant ON tweets(bot_id, variant) WHERE variant <> '';
@@ -0,0 +1,12 @@
+-- Tweets and transfers the agent would have published while running in dry-run mode
+CREATE TABLE dry_run_posts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bot_id TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    target TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    details TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dry_run_posts_bot_created ON dry_run_posts (bot_id, created_at);
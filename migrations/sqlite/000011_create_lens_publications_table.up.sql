@@ -0,0 +1,14 @@
+-- Posts and comments the agent published on Lens, with their IPFS metadata and transaction
+CREATE TABLE lens_publications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bot_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    comment_on TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    content_uri TEXT NOT NULL,
+    tx_hash TEXT NOT NULL DEFAULT '',
+    tx_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_lens_publications_bot_created ON lens_publications (bot_id, created_at);
@@ -0,0 +1,3 @@
+-- Sentiment of a stored mention towards the bot between -1 (hostile) and 1
+-- (praise), set when the mention is saved. Replies adapt their tone to it.
+ALTER TABLE tweets ADD COLUMN sentiment REAL NOT NULL DEFAULT 0;
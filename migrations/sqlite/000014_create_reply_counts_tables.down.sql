@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS conversation_reply_counts;
+DROP TABLE IF EXISTS user_reply_counts;
@@ -0,0 +1,18 @@
+-- Replies the bot posted to each user per UTC day, for the per-user daily cap
+CREATE TABLE user_reply_counts (
+    bot_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    day TIMESTAMP NOT NULL,
+    replies INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (bot_id, user_id, day)
+);
+
+-- Replies the bot posted in each conversation and when it last did, for the
+-- thread depth cap and the cooldown between replies in a thread
+CREATE TABLE conversation_reply_counts (
+    bot_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    replies INTEGER NOT NULL DEFAULT 0,
+    last_reply_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (bot_id, conversation_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE tweets DROP COLUMN author_verified;
+ALTER TABLE tweets DROP COLUMN author_followers;
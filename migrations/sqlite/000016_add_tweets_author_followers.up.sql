@@ -0,0 +1,4 @@
+-- Follower count and verification of a mention's author when it was saved,
+-- used to answer widely followed authors first
+ALTER TABLE tweets ADD COLUMN author_followers INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tweets ADD COLUMN author_verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,12 @@
+-- Topics of the bot's original thoughts, so the topic planner can rotate its
+-- content calendar without repeating itself
+CREATE TABLE thought_topics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bot_id TEXT NOT NULL,
+    category TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    tweet_id TEXT NOT NULL DEFAULT '',
+    posted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_thought_topics_bot_posted ON thought_topics (bot_id, posted_at);
@@ -0,0 +1,11 @@
+-- Nonces the wallet issued whose transactions have not been mined, so pending
+-- transactions and nonce gaps can be recovered after a restart
+CREATE TABLE wallet_nonces (
+    network TEXT NOT NULL,
+    address TEXT NOT NULL,
+    nonce BIGINT NOT NULL,
+    tx_hash TEXT NOT NULL DEFAULT '',
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL,
+    PRIMARY KEY (network, address, nonce)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_reply_examples_source;
+ALTER TABLE reply_examples DROP COLUMN source_tweet_id;
+ALTER TABLE reply_examples DROP COLUMN reason;
+ALTER TABLE reply_examples DROP COLUMN negative;
@@ -0,0 +1,4 @@
+-- When an edit of a stored tweet was last picked up, and whether it was edited
+-- after the bot replied to it, so operators can revisit the reply
+ALTER TABLE tweets ADD COLUMN edited_at TIMESTAMP;
+ALTER TABLE tweets ADD COLUMN edited_after_reply BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- When a stored tweet was found deleted or had its content purged for
+-- compliance. Such tweets keep their row, so replies stay linked, but are
+-- never answered or used as conversation context again.
+ALTER TABLE tweets ADD COLUMN deleted_at TIMESTAMP;
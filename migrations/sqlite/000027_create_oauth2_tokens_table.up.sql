@@ -0,0 +1,10 @@
+-- OAuth 2.0 tokens each account authorized the app with. The access and
+-- refresh tokens are encrypted with the configured token key.
+CREATE TABLE oauth2_tokens (
+    account TEXT PRIMARY KEY,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
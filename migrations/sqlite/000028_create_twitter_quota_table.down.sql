@@ -0,0 +1 @@
+DROP TABLE IF EXISTS twitter_quota;
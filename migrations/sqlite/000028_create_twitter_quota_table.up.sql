@@ -0,0 +1,17 @@
+-- Twitter API consumption per bot account, endpoint and rate limit window,
+-- from the x-rate-limit headers of the responses. Windows are identified by
+-- the time their limit resets.
+CREATE TABLE twitter_quota (
+    bot_id TEXT NOT NULL,
+    endpoint TEXT NOT NULL,
+    window_reset TIMESTAMP NOT NULL,
+
+    quota_limit INTEGER NOT NULL DEFAULT 0,
+    remaining INTEGER NOT NULL DEFAULT 0,
+    requests INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, endpoint, window_reset)
+);
+
+CREATE INDEX idx_twitter_quota_window_reset ON twitter_quota(window_reset);
@@ -0,0 +1,30 @@
+-- Compact LLM summaries of the bot's conversations and of the users it talks
+-- to, used as reply context instead of the full history. Each summary folds
+-- in the tweets up to last_tweet_id, so only newer tweets are read to update
+-- it, and it outlives tweets pruned by the retention policy.
+CREATE TABLE conversation_summaries (
+    bot_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+
+    summary TEXT NOT NULL,
+    tweet_count INTEGER NOT NULL DEFAULT 0,
+    last_tweet_id TEXT NOT NULL,
+    last_tweet_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, conversation_id)
+);
+
+CREATE TABLE user_summaries (
+    bot_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+
+    username TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL,
+    tweet_count INTEGER NOT NULL DEFAULT 0,
+    last_tweet_id TEXT NOT NULL,
+    last_tweet_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, user_id)
+);
@@ -0,0 +1,15 @@
+-- Time from each mention being stored to the bot's reply being posted, one
+-- row per reply, for the response latency percentiles and SLO.
+CREATE TABLE reply_latency (
+    bot_id TEXT NOT NULL,
+    reply_id TEXT NOT NULL,
+    tweet_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL DEFAULT '',
+    mentioned_at TIMESTAMP NOT NULL,
+    replied_at TIMESTAMP NOT NULL,
+    latency_ms BIGINT NOT NULL,
+
+    PRIMARY KEY (bot_id, reply_id)
+);
+
+CREATE INDEX idx_reply_latency_replied_at ON reply_latency(bot_id, replied_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tips;
+DROP TABLE IF EXISTS tip_wallets;
+DROP TABLE IF EXISTS command_roles;
@@ -0,0 +1,47 @@
+-- Users allowed to give the bot commands such as tips, by role
+CREATE TABLE command_roles (
+    bot_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, user_id, role)
+);
+
+-- The address each user registered for receiving tips
+CREATE TABLE tip_wallets (
+    bot_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (bot_id, user_id)
+);
+
+-- Tips given with tip commands, one per command tweet. Amounts are in token
+-- base units; amount_units is the amount in whole tokens as written.
+CREATE TABLE tips (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bot_id TEXT NOT NULL,
+    tweet_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL DEFAULT '',
+    sender_id TEXT NOT NULL,
+    recipient_id TEXT NOT NULL,
+    recipient_username TEXT NOT NULL DEFAULT '',
+    recipient_address TEXT NOT NULL,
+    network TEXT NOT NULL,
+    token TEXT NOT NULL,
+    symbol TEXT NOT NULL DEFAULT '',
+    amount TEXT NOT NULL,
+    amount_units REAL NOT NULL,
+    status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    tx_hash TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tips_tweet ON tips(bot_id, tweet_id);
+CREATE INDEX idx_tips_status ON tips(bot_id, status);
+CREATE INDEX idx_tips_sender ON tips(bot_id, sender_id, created_at);
@@ -0,0 +1,44 @@
+package actions
+
+import "time"
+
+// AdaptiveInterval adapts a polling interval to activity: it halves after a
+// poll that found something and grows by half after one that came back
+// empty, staying between Min and Max. The zero value keeps the interval fixed.
+type AdaptiveInterval struct {
+	// Min is the shortest interval, used while conversations are active
+	Min time.Duration
+	// Max is the longest interval, reached after a quiet period
+	Max time.Duration
+}
+
+// Enabled reports whether the interval adapts
+func (a AdaptiveInterval) Enabled() bool {
+	return a.Min > 0 && a.Max >= a.Min
+}
+
+// Clamp keeps interval within the bounds
+func (a AdaptiveInterval) Clamp(interval time.Duration) time.Duration {
+	if !a.Enabled() {
+		return interval
+	}
+	if interval < a.Min {
+		return a.Min
+	}
+	if interval > a.Max {
+		return a.Max
+	}
+	return interval
+}
+
+// Next returns the interval to wait after a poll that found activity new items;
+// a poll that failed counts as quiet
+func (a AdaptiveInterval) Next(current time.Duration, activity int) time.Duration {
+	if !a.Enabled() {
+		return current
+	}
+	if activity > 0 {
+		return a.Clamp(current / 2)
+	}
+	return a.Clamp(current + current/2)
+}
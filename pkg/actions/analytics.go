@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// maxCountsLookbackDays is how far back the recent tweet counts endpoint reaches
+const maxCountsLookbackDays = 7
+
+// AnalyticsOptions configures the analytics action
+type AnalyticsOptions struct {
+	Interval time.Duration
+	// LookbackDays is how many days, including today, are refreshed on each run (max 7)
+	LookbackDays int
+}
+
+// AnalyticsAction periodically records daily mention volume and per-conversation
+// engagement to the analytics table
+type AnalyticsAction struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    AnalyticsOptions
+}
+
+// NewAnalyticsAction creates a new analytics action
+func NewAnalyticsAction(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options AnalyticsOptions,
+) *AnalyticsAction {
+	if options.LookbackDays < 1 {
+		options.LookbackDays = 2
+	}
+	if options.LookbackDays > maxCountsLookbackDays {
+		options.LookbackDays = maxCountsLookbackDays
+	}
+	return &AnalyticsAction{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (a *AnalyticsAction) Name() string {
+	return "analytics"
+}
+
+// Execute implements the Action interface
+func (a *AnalyticsAction) Execute(ctx context.Context) error {
+	log := a.logger.WithContext(ctx).WithField("action", a.Name())
+
+	ticker := time.NewTicker(a.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting analytics action")
+
+	// Record once at startup so a fresh deployment has data before the first tick
+	if err := a.Record(ctx); err != nil {
+		log.WithError(err).Error("Failed to record analytics")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Analytics action stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := a.Record(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to record analytics")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Record refreshes mention volume and conversation engagement for the lookback window
+func (a *AnalyticsAction) Record(ctx context.Context) error {
+	var errs []error
+	if err := a.recordMentionVolume(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	today := time.Now().UTC()
+	for i := 0; i < a.options.LookbackDays; i++ {
+		day := today.AddDate(0, 0, -i)
+		if _, err := a.tweetStore.RecordConversationEngagement(ctx, day); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recordMentionVolume counts daily mentions of the bot's handle, excluding its own tweets
+func (a *AnalyticsAction) recordMentionVolume(ctx context.Context) error {
+	username := a.tweetStore.Username()
+	query := fmt.Sprintf("@%s -from:%s", username, username)
+
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(a.options.LookbackDays - 1))
+	// The endpoint rejects a start time older than its seven day window
+	if earliest := time.Now().Add(-maxCountsLookbackDays*24*time.Hour + time.Minute); start.Before(earliest) {
+		start = earliest
+	}
+
+	counts, err := a.client.GetTweetCounts(ctx, query, twitter.GranularityDay, twitter.TimeRange{Start: start})
+	if err != nil {
+		return fmt.Errorf("failed to get mention counts: %w", err)
+	}
+
+	for _, bucket := range counts.Data {
+		day, err := time.Parse(time.RFC3339, bucket.Start)
+		if err != nil {
+			a.logger.WithContext(ctx).WithError(err).WithField("start", bucket.Start).Warn("Skipping tweet count bucket with invalid start")
+			continue
+		}
+		if err := a.tweetStore.RecordMentionVolume(ctx, day, bucket.TweetCount); err != nil {
+			return err
+		}
+	}
+
+	a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"query":   query,
+		"days":    len(counts.Data),
+		"account": username,
+	}).Info("Recorded mention volume")
+
+	return nil
+}
+
+// Stop implements the Action interface
+func (a *AnalyticsAction) Stop() {
+	log := a.logger.WithField("action", a.Name())
+	log.Info("Stopping analytics action")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (a *AnalyticsAction) SetInterval(interval time.Duration) {
+	a.options.Interval = interval
+}
@@ -0,0 +1,145 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// AnalyticsReportOptions configures the analytics report action
+type AnalyticsReportOptions struct {
+	Interval time.Duration
+	// PostSummary tweets the previous day's summary instead of only logging it
+	PostSummary bool
+}
+
+// AnalyticsReportAction periodically stores daily engagement reports and logs or
+// posts a summary. Engagement counts come from the metrics kept current by the
+// MetricsRefresher.
+type AnalyticsReportAction struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    AnalyticsReportOptions
+	// lastPosted is the day of the last summary tweeted, so each day is posted once
+	lastPosted time.Time
+}
+
+// NewAnalyticsReportAction creates a new analytics report action
+func NewAnalyticsReportAction(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options AnalyticsReportOptions,
+) *AnalyticsReportAction {
+	return &AnalyticsReportAction{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+		// Treat yesterday as already posted so a restart does not repost its summary
+		lastPosted: time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1),
+	}
+}
+
+// Name implements the Action interface
+func (a *AnalyticsReportAction) Name() string {
+	return "analytics_report"
+}
+
+// Execute implements the Action interface
+func (a *AnalyticsReportAction) Execute(ctx context.Context) error {
+	log := a.logger.WithContext(ctx).WithField("action", a.Name())
+
+	ticker := time.NewTicker(a.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting analytics report action")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Analytics report action stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := a.Report(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to build analytics report")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Report stores the reports for yesterday and today and publishes yesterday's summary
+func (a *AnalyticsReportAction) Report(ctx context.Context) error {
+	var errs []error
+
+	today := time.Now().UTC()
+	yesterday := today.AddDate(0, 0, -1)
+
+	var completed *memory.DailyReport
+	for i, day := range []time.Time{yesterday, today} {
+		report, err := a.tweetStore.BuildDailyReport(ctx, day)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := a.tweetStore.SaveReport(ctx, report); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if i == 0 {
+			completed = report
+		}
+	}
+
+	if completed != nil {
+		if err := a.publish(ctx, completed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// publish logs the summary of a completed day and, when enabled, tweets it once
+func (a *AnalyticsReportAction) publish(ctx context.Context, report *memory.DailyReport) error {
+	summary := report.Summary()
+	a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"day":                  report.PeriodStart.Format("2006-01-02"),
+		"replies_posted":       report.RepliesPosted,
+		"avg_response_seconds": report.AvgResponseSeconds,
+		"likes_received":       report.LikesReceived,
+		"retweets_received":    report.RetweetsReceived,
+		"summary":              summary,
+	}).Info("Daily engagement report")
+
+	if !a.options.PostSummary || !report.PeriodStart.After(a.lastPosted) {
+		return nil
+	}
+
+	if _, err := a.client.PostTweet(ctx, "Daily report "+summary, &twitter.TweetOptions{}); err != nil {
+		return fmt.Errorf("failed to post report summary: %w", err)
+	}
+	a.lastPosted = report.PeriodStart
+	return nil
+}
+
+// Stop implements the Action interface
+func (a *AnalyticsReportAction) Stop() {
+	log := a.logger.WithField("action", a.Name())
+	log.Info("Stopping analytics report action")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (a *AnalyticsReportAction) SetInterval(interval time.Duration) {
+	a.options.Interval = interval
+}
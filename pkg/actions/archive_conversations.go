@@ -0,0 +1,89 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// ConversationArchiveOptions configures the conversation archiver action
+type ConversationArchiveOptions struct {
+	Interval time.Duration
+}
+
+// ConversationArchiveAction periodically publishes completed conversations to
+// the public archive, one archiver per tweet store (e.g. Twitter and Farcaster)
+type ConversationArchiveAction struct {
+	archivers []*archive.Archiver
+	logger    *logrus.Logger
+	options   ConversationArchiveOptions
+	done      chan struct{}
+}
+
+// NewConversationArchiveAction creates a new conversation archiver action
+func NewConversationArchiveAction(archivers []*archive.Archiver, logger *logrus.Logger, options ConversationArchiveOptions) *ConversationArchiveAction {
+	if options.Interval <= 0 {
+		options.Interval = time.Hour
+	}
+	return &ConversationArchiveAction{
+		archivers: archivers,
+		logger:    logger,
+		options:   options,
+		done:      make(chan struct{}),
+	}
+}
+
+// Name implements the Action interface
+func (a *ConversationArchiveAction) Name() string {
+	return "conversation_archiver"
+}
+
+// Execute implements the Action interface
+func (a *ConversationArchiveAction) Execute(ctx context.Context) error {
+	log := a.logger.WithContext(ctx).WithField("action", a.Name())
+	log.Info("Starting conversation archiver")
+
+	ticker := time.NewTicker(a.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-a.done:
+			return nil
+		case <-ticker.C:
+			a.ArchiveCompleted(logging.StartTrace(ctx))
+		}
+	}
+}
+
+// ArchiveCompleted runs every archiver once and returns how many
+// conversations were archived
+func (a *ConversationArchiveAction) ArchiveCompleted(ctx context.Context) int {
+	total := 0
+	for _, archiver := range a.archivers {
+		archived, err := archiver.ArchiveCompleted(ctx)
+		if err != nil {
+			a.logger.WithContext(ctx).WithError(err).Error("Failed to archive conversations")
+		}
+		total += archived
+	}
+	if total > 0 {
+		a.logger.WithContext(ctx).WithField("archived", total).Info("Archived completed conversations")
+	}
+	return total
+}
+
+// Stop implements the Action interface
+func (a *ConversationArchiveAction) Stop() {
+	close(a.done)
+}
+
+// SetInterval implements the IntervalSetter interface
+func (a *ConversationArchiveAction) SetInterval(interval time.Duration) {
+	a.options.Interval = interval
+}
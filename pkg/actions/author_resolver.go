@@ -0,0 +1,148 @@
+package actions
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultAuthorCacheTTL is how long a resolved author is reused before
+	// being looked up again
+	DefaultAuthorCacheTTL = 6 * time.Hour
+
+	// hydrateBatch is how many stored authors without a profile are resolved
+	// per hydration, one users lookup request
+	hydrateBatch = 100
+)
+
+// cachedAuthor is a resolved author; a nil user caches a failed lookup, so
+// suspended accounts are not looked up on every poll
+type cachedAuthor struct {
+	user    *twitter.User
+	expires time.Time
+}
+
+// AuthorResolver caches the profiles of tweet authors and looks up the ones
+// missing from a response's includes in batches through the users endpoint
+type AuthorResolver struct {
+	client *twitter.TwitterClient
+	logger *logrus.Logger
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedAuthor
+}
+
+// NewAuthorResolver creates an author resolver; a ttl of 0 uses DefaultAuthorCacheTTL
+func NewAuthorResolver(client *twitter.TwitterClient, logger *logrus.Logger, ttl time.Duration) *AuthorResolver {
+	if ttl <= 0 {
+		ttl = DefaultAuthorCacheTTL
+	}
+	return &AuthorResolver{
+		client: client,
+		logger: logger,
+		ttl:    ttl,
+		cache:  make(map[string]cachedAuthor),
+	}
+}
+
+// Remember caches users that came with a response, such as its includes
+func (r *AuthorResolver) Remember(users []twitter.User) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expires := time.Now().Add(r.ttl)
+	for i := range users {
+		user := users[i]
+		r.cache[user.ID] = cachedAuthor{user: &user, expires: expires}
+	}
+}
+
+// Resolve returns the profiles of the given authors, from the cache or from
+// one batched lookup. Authors that cannot be looked up are left out.
+func (r *AuthorResolver) Resolve(ctx context.Context, ids []string) (map[string]*twitter.User, error) {
+	resolved := make(map[string]*twitter.User, len(ids))
+	if r == nil || len(ids) == 0 {
+		return resolved, nil
+	}
+
+	now := time.Now()
+	var missing []string
+	seen := make(map[string]bool, len(ids))
+	r.mu.Lock()
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if cached, ok := r.cache[id]; ok && now.Before(cached.expires) {
+			if cached.user != nil {
+				resolved[id] = cached.user
+			}
+			continue
+		}
+		missing = append(missing, id)
+	}
+	r.mu.Unlock()
+
+	if len(missing) == 0 {
+		return resolved, nil
+	}
+
+	users, err := r.client.LookupUsers(ctx, missing)
+	if err != nil {
+		return resolved, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expires := now.Add(r.ttl)
+	for _, id := range missing {
+		r.cache[id] = cachedAuthor{expires: expires}
+	}
+	for i := range users {
+		user := users[i]
+		r.cache[user.ID] = cachedAuthor{user: &user, expires: expires}
+		resolved[user.ID] = &user
+	}
+
+	r.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"requested": len(missing),
+		"found":     len(users),
+	}).Debug("Resolved tweet authors")
+	return resolved, nil
+}
+
+// Hydrate fills in the profile of stored tweets saved without their author's
+// name and username. It returns how many authors were updated.
+func (r *AuthorResolver) Hydrate(ctx context.Context, store *memory.TweetStore) (int, error) {
+	if r == nil {
+		return 0, nil
+	}
+
+	ids, err := store.AuthorsWithoutProfile(ctx, hydrateBatch)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	authors, err := r.Resolve(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, author := range authors {
+		err := store.SetAuthorProfile(ctx, author.ID, author.Name, author.Username,
+			author.PublicMetrics.FollowersCount, author.Verified)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(authors), nil
+}
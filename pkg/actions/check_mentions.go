@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/filters"
 	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/lock"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
 	"github.com/lisanmuaddib/agent-go/pkg/memory"
 	"github.com/sirupsen/logrus"
 	"github.com/tmc/langchaingo/llms"
@@ -15,7 +19,6 @@ type MentionsHandler struct {
 	client     *twitter.TwitterClient
 	llm        llms.Model
 	logger     *logrus.Logger
-	ticker     *time.Ticker
 	options    MentionsOptions
 	done       chan struct{}
 	tweetStore *memory.TweetStore
@@ -24,6 +27,35 @@ type MentionsHandler struct {
 type MentionsOptions struct {
 	Interval   time.Duration
 	MaxResults int
+	// SpamFilter, when set, scores each mention so spam is kept out of the reply queue
+	SpamFilter *filters.SpamFilter
+	// Bots skips known and, optionally, suspected bots; the bot's own tweets
+	// and retweets or bare quotes of them are skipped even when it is nil
+	Bots *filters.BotGuard
+	// Events receives a mention_received event for every mention queued for a reply
+	Events *events.Bus
+	// Sentiment, when set, scores each mention so replies can adapt their tone
+	Sentiment *filters.SentimentAnalyzer
+	// Locker, when set, lets only one instance sharing the database poll
+	// mentions at a time, so a page is never stored and announced twice
+	Locker lock.Locker
+	// Overlap re-fetches mentions this much older than the newest one seen,
+	// so tweets indexed late are not missed; defaults to 2 minutes and a
+	// negative value polls strictly after the newest mention
+	Overlap time.Duration
+	// MaxPages bounds how many pages of new mentions one poll fetches;
+	// defaults to 5
+	MaxPages int
+	// Adaptive, when enabled, shortens the interval while polls find new
+	// mentions and lengthens it while they do not
+	Adaptive AdaptiveInterval
+	// ThreadDepth bounds how many levels of replied-to tweets above a
+	// mention are fetched and stored as context; defaults to 10 and a
+	// negative value stores only the mentions
+	ThreadDepth int
+	// Authors, when set, resolves authors missing from a page's includes
+	// and fills in stored mentions that were saved without their author
+	Authors *AuthorResolver
 }
 
 // NewMentionsHandler creates a new instance of MentionsHandler
@@ -34,12 +66,20 @@ func NewMentionsHandler(client *twitter.TwitterClient, llm llms.Model, logger *l
 	if options.MaxResults == 0 {
 		options.MaxResults = 100
 	}
+	if options.Overlap == 0 {
+		options.Overlap = 2 * time.Minute
+	}
+	if options.MaxPages == 0 {
+		options.MaxPages = 5
+	}
+	if options.ThreadDepth == 0 {
+		options.ThreadDepth = 10
+	}
 
 	return &MentionsHandler{
 		client:     client,
 		llm:        llm,
 		logger:     logger,
-		ticker:     time.NewTicker(options.Interval),
 		options:    options,
 		done:       make(chan struct{}),
 		tweetStore: tweetStore,
@@ -58,41 +98,98 @@ func (h *MentionsHandler) Execute(ctx context.Context) error {
 
 // Stop implements the Action interface
 func (h *MentionsHandler) Stop() {
-	h.ticker.Stop()
 	close(h.done)
 }
 
+// SetInterval implements the IntervalSetter interface
+func (h *MentionsHandler) SetInterval(interval time.Duration) {
+	h.options.Interval = interval
+}
+
+// Start polls mentions until the context is cancelled or the handler is
+// stopped. With Adaptive set, polls come faster while mentions keep arriving
+// and slow down while it is quiet.
 func (h *MentionsHandler) Start(ctx context.Context) error {
-	log := h.logger.WithField("interval", h.options.Interval)
+	interval := h.options.Adaptive.Clamp(h.options.Interval)
+	log := h.logger.WithContext(ctx).WithField("interval", interval)
+	if h.options.Adaptive.Enabled() {
+		log = log.WithFields(logrus.Fields{
+			"min_interval": h.options.Adaptive.Min,
+			"max_interval": h.options.Adaptive.Max,
+		})
+	}
 	log.Info("Starting mention monitoring")
 
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
-			h.ticker.Stop()
 			return ctx.Err()
 		case <-h.done:
 			return nil
-		case <-h.ticker.C:
-			if err := h.CheckMentions(ctx); err != nil {
-				log.WithError(err).Error("Failed to check mentions")
+		case <-timer.C:
+			tick := logging.StartTrace(ctx)
+			queued, err := h.checkMentions(tick)
+			if err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to check mentions")
+			}
+
+			next := h.options.Adaptive.Next(interval, queued)
+			if next != interval {
+				h.logger.WithContext(tick).WithFields(logrus.Fields{
+					"new_mentions": queued,
+					"interval":     next,
+				}).Debug("Adjusted mention polling interval")
+				interval = next
 			}
+			timer.Reset(interval)
 		}
 	}
 }
 
+// CheckMentions polls new mentions once, storing them and queueing them for a reply
 func (h *MentionsHandler) CheckMentions(ctx context.Context) error {
-	log := h.logger.WithField("method", "CheckMentions")
-	log.Debug("Checking for new mentions")
+	_, err := h.checkMentions(ctx)
+	return err
+}
+
+// checkMentions polls new mentions once and returns how many were queued
+func (h *MentionsHandler) checkMentions(ctx context.Context) (int, error) {
+	log := h.logger.WithContext(ctx).WithField("method", "CheckMentions")
+
+	if h.options.Locker != nil {
+		release, ok, err := h.options.Locker.TryLock(ctx, lock.Key("mentions", h.tweetStore.BotID()))
+		if err != nil {
+			return 0, fmt.Errorf("failed to lock mentions: %w", err)
+		}
+		if !ok {
+			log.Debug("Mentions are checked by another instance")
+			return 0, nil
+		}
+		defer release()
+	}
+
+	// Only mentions newer than the last poll are fetched; the first poll
+	// reads the latest page
+	watermark, err := h.tweetStore.Watermark(ctx, memory.StreamMentions)
+	if err != nil {
+		return 0, err
+	}
+
+	log.WithField("watermark", watermark).Debug("Checking for new mentions")
 
 	params := twitter.GetUserMentionsParams{
 		MaxResults: h.options.MaxResults,
+		SinceID:    h.sinceID(watermark),
 		TweetFields: []string{
 			"id",
 			"text",
 			"author_id",
 			"conversation_id",
 			"created_at",
+			"edit_history_tweet_ids",
 			"entities",
 			"geo",
 			"in_reply_to_user_id",
@@ -109,113 +206,301 @@ func (h *MentionsHandler) CheckMentions(ctx context.Context) error {
 			"entities.mentions.username",
 			"referenced_tweets.id.author_id",
 		},
+		// Account age and follower counts feed the spam filter
+		UserFields: []string{
+			"created_at",
+			"public_metrics",
+			"verified",
+		},
+	}
+
+	newest := watermark
+	queued := 0
+	for page := 1; ; page++ {
+		resp, err := h.fetchMentions(ctx, params)
+		if err != nil || resp == nil {
+			return queued, err
+		}
+		n, err := h.processMentions(ctx, resp, watermark)
+		queued += n
+		if err != nil {
+			return queued, err
+		}
+		for _, tweet := range resp.Data {
+			if twitter.CompareIDs(tweet.ID, newest) > 0 {
+				newest = tweet.ID
+			}
+		}
+
+		if watermark == "" || resp.Meta == nil || resp.Meta.NextToken == "" {
+			break
+		}
+		if page >= h.options.MaxPages {
+			log.WithField("pages", page).Warn("More new mentions than one poll fetches, skipping the oldest")
+			break
+		}
+		params.PaginationToken = resp.Meta.NextToken
+	}
+
+	// Advance only once every page is stored, so a failed poll is retried
+	if newest != watermark {
+		if err := h.tweetStore.SaveWatermark(ctx, memory.StreamMentions, newest); err != nil {
+			return queued, err
+		}
 	}
+	return queued, nil
+}
 
+// fetchMentions requests one page of mentions
+func (h *MentionsHandler) fetchMentions(ctx context.Context, params twitter.GetUserMentionsParams) (*twitter.MentionResponse, error) {
 	dataChan, errChan := h.client.GetUserMentions(ctx, params)
 
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return nil, ctx.Err()
 	case err := <-errChan:
-		return err
-	case resp, ok := <-dataChan:
-		if !ok {
-			return nil
+		return nil, err
+	case resp := <-dataChan:
+		return resp, nil
+	}
+}
+
+// sinceID returns the since_id of a poll: the watermark moved back by the
+// overlap, so that mentions the API indexed late are fetched again
+func (h *MentionsHandler) sinceID(watermark string) string {
+	if watermark == "" || h.options.Overlap < 0 {
+		return watermark
+	}
+	at, err := twitter.IDTime(watermark)
+	if err != nil {
+		h.logger.WithError(err).Warn("Ignoring invalid mentions watermark")
+		return ""
+	}
+	if sinceID := twitter.IDAt(at.Add(-h.options.Overlap)); sinceID != "0" {
+		return sinceID
+	}
+	return ""
+}
+
+// seen reports whether an earlier poll already handled a mention: it is no
+// newer than the watermark and was fetched again by the overlap, or it is an
+// edit of a tweet already stored
+func (h *MentionsHandler) seen(ctx context.Context, tweet twitter.Tweet, watermark string) (bool, error) {
+	if watermark != "" && twitter.CompareIDs(tweet.ID, watermark) <= 0 {
+		return true, nil
+	}
+	for _, id := range tweet.EditHistoryTweetIDs {
+		if id == tweet.ID {
+			continue
+		}
+		known, err := h.tweetStore.HasTweet(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if known {
+			return true, nil
 		}
-		return h.processMentions(ctx, resp)
 	}
+	return false, nil
 }
 
-func (h *MentionsHandler) processMentions(ctx context.Context, resp *twitter.MentionResponse) error {
+// processMentions stores a page of mentions and queues the new ones for a
+// reply, returning how many were queued. Mentions seen by an earlier poll are
+// stored again to refresh their metrics, but not queued or announced twice.
+func (h *MentionsHandler) processMentions(ctx context.Context, resp *twitter.MentionResponse, watermark string) (int, error) {
 	if resp == nil {
-		return nil
+		return 0, nil
 	}
 
 	tweets, err := resp.UnmarshalTweets()
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal tweets: %w", err)
+		return 0, fmt.Errorf("failed to unmarshal tweets: %w", err)
 	}
 
-	for _, tweet := range tweets {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			log := h.logger.WithFields(logrus.Fields{
-				"tweet_id":        tweet.ID,
-				"author_id":       tweet.AuthorID,
-				"text":            tweet.Text,
-				"conversation_id": tweet.ConversationID,
-				"created_at":      tweet.CreatedAt,
-				"reply_settings":  tweet.ReplySettings,
-			})
-
-			// Find author information from includes
-			var authorName, authorUsername string
-			if resp.Includes != nil {
-				for _, user := range resp.Includes.Users {
-					if user.ID == tweet.AuthorID {
-						authorName = user.Name
-						authorUsername = user.Username
-						break
-					}
-				}
-			}
+	blocklist, err := h.tweetStore.LoadBlocklist(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load blocklist: %w", err)
+	}
 
-			// Determine the category of the tweet
-			category := memory.DetermineTweetCategory(tweet)
-
-			// Prepare conversation reference
-			var conversationRef *memory.ConversationRef
-			if tweet.ConversationID != "" {
-				conversationRef = &memory.ConversationRef{
-					ConversationID: tweet.ConversationID,
-					LastReplyAt:    time.Now(),
-				}
-
-				if tweet.ReferencedTweets != nil {
-					for _, ref := range tweet.ReferencedTweets {
-						if ref.Type == "replied_to" {
-							conversationRef.ParentID = ref.ID
-							conversationRef.IsRoot = false
-							log.WithField("parent_id", ref.ID).Debug("Found parent tweet reference")
-							break
-						}
-					}
-				} else {
-					conversationRef.IsRoot = true
-					conversationRef.RootID = tweet.ID
-					log.Debug("Tweet marked as conversation root")
-				}
+	// Authors missing from the includes are looked up in one batch
+	var unknown []string
+	if h.options.Authors != nil {
+		if resp.Includes != nil {
+			h.options.Authors.Remember(resp.Includes.Users)
+		}
+		for _, tweet := range tweets {
+			if resp.Includes.User(tweet.AuthorID) == nil {
+				unknown = append(unknown, tweet.AuthorID)
 			}
+		}
+	}
+	resolved, err := h.options.Authors.Resolve(ctx, unknown)
+	if err != nil {
+		h.logger.WithContext(ctx).WithError(err).Warn("Failed to resolve mention authors, saving without them")
+	}
+
+	botID, botUsername := h.tweetStore.BotID(), h.tweetStore.Username()
+
+	// Collect the page first so it is stored in one batch
+	var page []memory.TweetWithMeta
+	authors := make(map[string]*twitter.User)
+	for _, tweet := range tweets {
+		log := h.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"tweet_id":        tweet.ID,
+			"author_id":       tweet.AuthorID,
+			"text":            tweet.Text,
+			"conversation_id": tweet.ConversationID,
+			"created_at":      tweet.CreatedAt,
+			"reply_settings":  tweet.ReplySettings,
+		})
+
+		if skip, reason := blocklist.Skip(tweet.AuthorID, tweet.ConversationID); skip {
+			log.WithField("reason", reason).Debug("Skipping mention")
+			continue
+		}
+
+		// Find author information from includes
+		var authorName, authorUsername string
+		user := resp.Includes.User(tweet.AuthorID)
+		if user == nil {
+			user = resolved[tweet.AuthorID]
+		}
+		if user != nil {
+			authorName = user.Name
+			authorUsername = user.Username
+			authors[tweet.ID] = user
+		}
+
+		// Answering itself or another bot could go back and forth forever
+		if skip, reason := h.options.Bots.Skip(tweet, user, resp.Includes, botID, botUsername); skip {
+			log.WithField("reason", reason).Info("Skipping mention")
+			continue
+		}
 
-			// Log before saving
-			log.WithFields(logrus.Fields{
-				"category":            category,
-				"has_conversation_id": tweet.ConversationID != "",
-				"author_name":         authorName,
-				"author_username":     authorUsername,
-				"conversation_ref":    conversationRef,
-				"referenced_tweets":   tweet.ReferencedTweets,
-				"public_metrics":      tweet.PublicMetrics,
-			}).Debug("Saving tweet to store")
-
-			// Store the tweet with all its metadata
-			if err := h.tweetStore.SaveTweet(tweet, category, authorName, authorUsername); err != nil {
-				log.WithError(err).Error("Failed to save tweet")
+		// Determine the category of the tweet
+		category := memory.DetermineTweetCategory(tweet)
+
+		log.WithFields(logrus.Fields{
+			"category":            category,
+			"has_conversation_id": tweet.ConversationID != "",
+			"author_name":         authorName,
+			"author_username":     authorUsername,
+			"referenced_tweets":   tweet.ReferencedTweets,
+			"public_metrics":      tweet.PublicMetrics,
+		}).Debug("Saving tweet to store")
+
+		meta := memory.TweetWithMeta{
+			Tweet:          tweet,
+			Category:       category,
+			AuthorName:     authorName,
+			AuthorUsername: authorUsername,
+		}
+		if author := authors[tweet.ID]; author != nil {
+			meta.AuthorFollowers = author.PublicMetrics.FollowersCount
+			meta.AuthorVerified = author.Verified
+		}
+		page = append(page, meta)
+	}
+
+	// Store the tweets with all their metadata
+	if err := h.tweetStore.SaveTweets(ctx, page); err != nil {
+		return 0, fmt.Errorf("failed to save mentions: %w", err)
+	}
+	if hydrated, err := h.options.Authors.Hydrate(ctx, h.tweetStore); err != nil {
+		h.logger.WithContext(ctx).WithError(err).Warn("Failed to hydrate stored mention authors")
+	} else if hydrated > 0 {
+		h.logger.WithContext(ctx).WithField("authors", hydrated).Info("Hydrated stored mention authors")
+	}
+	if stored, err := h.storeThreadContext(ctx, page, resp.Includes); err != nil {
+		h.logger.WithContext(ctx).WithError(err).Warn("Failed to store thread context of mentions")
+	} else if stored > 0 {
+		h.logger.WithContext(ctx).WithField("tweets", stored).Info("Stored thread context of mentions")
+	}
+
+	// Replies are generated by the reply workers, which work the queue
+	priority := h.tweetStore.ReplyPriority()
+	perConversation := make(map[string]int)
+	for _, saved := range page {
+		perConversation[saved.Tweet.ConversationID]++
+	}
+	var queue []memory.ReplyWorkItem
+	defer func() {
+		if err := h.tweetStore.EnqueueReplies(context.WithoutCancel(ctx), queue); err != nil {
+			h.logger.WithContext(ctx).WithError(err).Error("Failed to queue mentions for reply")
+		}
+	}()
+
+	for _, saved := range page {
+		if ctx.Err() != nil {
+			return len(queue), ctx.Err()
+		}
+
+		tweet := saved.Tweet
+		log := h.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"tweet_id":        tweet.ID,
+			"author_id":       tweet.AuthorID,
+			"conversation_id": tweet.ConversationID,
+		})
+
+		if seen, err := h.seen(ctx, tweet, watermark); err != nil {
+			log.WithError(err).Warn("Failed to check whether mention is new")
+		} else if seen {
+			log.Debug("Mention handled by an earlier poll")
+			continue
+		}
+
+		if h.options.SpamFilter != nil {
+			score := h.options.SpamFilter.Score(ctx, tweet, authors[tweet.ID])
+			if err := h.tweetStore.SetSpamScore(ctx, tweet.ID, score.Value); err != nil {
+				log.WithError(err).Error("Failed to record spam score")
+			} else if h.options.SpamFilter.IsSpam(score) {
+				log.WithFields(logrus.Fields{
+					"spam_score": score.Value,
+					"reasons":    score.Reasons,
+				}).Info("Mention flagged as spam, excluding from replies")
 				continue
 			}
+		}
 
-			log.WithFields(logrus.Fields{
-				"category":     category,
-				"author_name":  authorName,
-				"username":     authorUsername,
-				"tweet_id":     tweet.ID,
-				"needs_reply":  true,
-				"is_processed": true,
-			}).Info("Processed mention")
+		if h.options.Sentiment != nil {
+			sentiment := h.options.Sentiment.Score(ctx, tweet.Text)
+			if err := h.tweetStore.SetSentiment(ctx, tweet.ID, sentiment); err != nil {
+				log.WithError(err).Error("Failed to record sentiment")
+			} else {
+				log = log.WithField("sentiment", sentiment)
+			}
 		}
+
+		score := priority.Weights.Score(memory.ReplySignals{
+			// Mentions are queued as they arrive
+			Recency:   1,
+			Followers: memory.FollowerSignal(saved.AuthorFollowers, saved.AuthorVerified, priority.FollowerSaturation),
+			Heat:      memory.HeatSignal(perConversation[tweet.ConversationID]),
+			Addressed: memory.AddressedSignal(string(saved.Category), tweet.InReplyToUserID, botID),
+		})
+		queue = append(queue, memory.ReplyWorkItem{
+			TweetID:        tweet.ID,
+			ConversationID: tweet.ConversationID,
+			Priority:       memory.QueuePriority(score),
+		})
+
+		h.options.Events.Emit(events.MentionReceived, h.tweetStore.BotID(), map[string]interface{}{
+			"tweet_id":        tweet.ID,
+			"author_id":       tweet.AuthorID,
+			"author_username": saved.AuthorUsername,
+			"conversation_id": tweet.ConversationID,
+			"category":        saved.Category,
+			"text":            tweet.Text,
+		})
+
+		log.WithFields(logrus.Fields{
+			"category":     saved.Category,
+			"author_name":  saved.AuthorName,
+			"username":     saved.AuthorUsername,
+			"needs_reply":  true,
+			"is_processed": true,
+		}).Info("Processed mention")
 	}
 
-	return nil
+	return len(queue), nil
 }
@@ -0,0 +1,173 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// ComplianceOptions configures the compliance action
+type ComplianceOptions struct {
+	Interval time.Duration
+}
+
+// ComplianceAction keeps the store in line with Twitter's compliance
+// requirements. It periodically checks that the tweets waiting for a reply
+// still exist and soft-deletes the removed ones, and it applies compliance
+// events, such as deleted tweets or protected users, handed to it by operators.
+type ComplianceAction struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    ComplianceOptions
+}
+
+// NewComplianceAction creates a new compliance action
+func NewComplianceAction(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options ComplianceOptions,
+) *ComplianceAction {
+	return &ComplianceAction{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (c *ComplianceAction) Name() string {
+	return "compliance"
+}
+
+// Execute implements the Action interface
+func (c *ComplianceAction) Execute(ctx context.Context) error {
+	log := c.logger.WithContext(ctx).WithField("action", c.Name())
+
+	ticker := time.NewTicker(c.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting compliance checks")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Compliance checks stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := c.Verify(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to verify queued tweets")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Verify looks up the tweets waiting in the reply queue and soft-deletes the
+// ones that no longer exist
+func (c *ComplianceAction) Verify(ctx context.Context) error {
+	ids, err := c.tweetStore.QueuedTweetIDs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tweets, err := c.client.LookupTweets(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch queued tweets: %w", err)
+	}
+	if len(tweets) == 0 {
+		// Every tweet missing points at the lookup rather than at deletions
+		c.logger.WithContext(ctx).WithField("requested", len(ids)).Warn("No queued tweets found, skipping compliance check")
+		return nil
+	}
+
+	found := make(map[string]bool, len(tweets))
+	for _, tweet := range tweets {
+		found[tweet.ID] = true
+	}
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	deleted, err := c.tweetStore.DeleteTweets(ctx, missing)
+	if err != nil {
+		return err
+	}
+
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"checked": len(ids),
+		"deleted": deleted,
+	}).Info("Verified queued tweets")
+	return nil
+}
+
+// Apply acts on compliance events: deleted and dropped tweets, and all tweets
+// of deleted, protected or suspended users, are soft-deleted and purged, and
+// scrubbed tweets lose their location. Other event types are ignored. It
+// returns how many stored tweets were changed.
+func (c *ComplianceAction) Apply(ctx context.Context, events []twitter.Compliance) (int64, error) {
+	var deletedTweets, scrubbed []string
+	var changed int64
+	for _, event := range events {
+		switch event.EventType {
+		case twitter.ComplianceDelete, twitter.ComplianceDrop:
+			deletedTweets = append(deletedTweets, event.TweetID)
+		case twitter.ComplianceScrubGeo:
+			scrubbed = append(scrubbed, event.TweetID)
+		case twitter.ComplianceUserDelete, twitter.ComplianceUserProtect, twitter.ComplianceUserSuspend:
+			deleted, err := c.tweetStore.DeleteUserTweets(ctx, event.UserID)
+			if err != nil {
+				return changed, err
+			}
+			changed += deleted
+		default:
+			c.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"event_id":   event.ID,
+				"event_type": event.EventType,
+			}).Debug("Ignoring compliance event")
+		}
+	}
+
+	deleted, err := c.tweetStore.DeleteTweets(ctx, deletedTweets)
+	if err != nil {
+		return changed, err
+	}
+	changed += deleted
+	scrubbedCount, err := c.tweetStore.ScrubGeo(ctx, scrubbed)
+	if err != nil {
+		return changed, err
+	}
+	changed += scrubbedCount
+
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"events":  len(events),
+		"changed": changed,
+	}).Info("Applied compliance events")
+	return changed, nil
+}
+
+// Stop implements the Action interface
+func (c *ComplianceAction) Stop() {
+	log := c.logger.WithField("action", c.Name())
+	log.Info("Stopping compliance checks")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (c *ComplianceAction) SetInterval(interval time.Duration) {
+	c.options.Interval = interval
+}
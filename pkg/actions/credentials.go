@@ -0,0 +1,270 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// CredentialState is the health of an account's Twitter credentials
+type CredentialState string
+
+const (
+	// CredentialsOK means the last check authenticated
+	CredentialsOK CredentialState = "ok"
+	// CredentialsRevoked means the API rejected the credentials: the tokens
+	// were revoked or regenerated, or the OAuth 2.0 token expired
+	CredentialsRevoked CredentialState = "revoked"
+	// CredentialsSuspended means the credentials work but the account is
+	// suspended or locked
+	CredentialsSuspended CredentialState = "suspended"
+)
+
+// CredentialStatus is the last known health of an account's credentials
+type CredentialStatus struct {
+	State CredentialState `json:"state"`
+	// Error is the failure that put the credentials in their state
+	Error string `json:"error,omitempty"`
+	// Since is when the credentials entered their state
+	Since     time.Time `json:"since"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Healthy reports whether posting may go on
+func (s CredentialStatus) Healthy() bool {
+	return s.State == CredentialsOK
+}
+
+// CredentialMonitorOptions configures the credential monitor
+type CredentialMonitorOptions struct {
+	Interval time.Duration
+	// Events receives a credentials_changed event per state change; nil
+	// disables events
+	Events *events.Bus
+}
+
+// CredentialMonitor periodically makes a cheap authenticated call to find
+// revoked, expired or suspended credentials before the posting actions do.
+// While the credentials fail, the reply workers and the thought poster hold
+// instead of collecting 401s. A nil monitor always reports healthy
+// credentials.
+type CredentialMonitor struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    CredentialMonitorOptions
+
+	mu     sync.Mutex
+	status CredentialStatus
+	// recovered is closed while the credentials are healthy
+	recovered chan struct{}
+}
+
+// NewCredentialMonitor creates a new credential monitor. Credentials are
+// assumed healthy until a check or a posting action finds otherwise.
+func NewCredentialMonitor(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options CredentialMonitorOptions,
+) *CredentialMonitor {
+	recovered := make(chan struct{})
+	close(recovered)
+	return &CredentialMonitor{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+		status:     CredentialStatus{State: CredentialsOK, Since: time.Now()},
+		recovered:  recovered,
+	}
+}
+
+// Name implements the Action interface
+func (m *CredentialMonitor) Name() string {
+	return "credential_monitor"
+}
+
+// Execute implements the Action interface, checking the credentials at once
+// and then on every tick
+func (m *CredentialMonitor) Execute(ctx context.Context) error {
+	log := m.logger.WithContext(ctx).WithField("action", m.Name())
+
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting credential checks")
+
+	for {
+		tick := logging.StartTrace(ctx)
+		if _, err := m.Check(tick); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.WithContext(tick).WithError(err).Warn("Credential check inconclusive")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("Credential checks stopped")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop implements the Action interface
+func (m *CredentialMonitor) Stop() {
+	m.logger.WithField("action", m.Name()).Info("Stopping credential checks")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (m *CredentialMonitor) SetInterval(interval time.Duration) {
+	m.options.Interval = interval
+}
+
+// Check calls users/me and records the state it shows. Failures that say
+// nothing about the credentials, such as timeouts or 5xx responses, keep the
+// previous state and are returned.
+func (m *CredentialMonitor) Check(ctx context.Context) (CredentialStatus, error) {
+	_, err := m.client.CheckCredentials(ctx)
+	state, ok := credentialState(err)
+	if !ok {
+		return m.Status(), fmt.Errorf("failed to check credentials: %w", err)
+	}
+	return m.set(state, err), nil
+}
+
+// Report records a failure of a posting action, so rejected credentials hold
+// posting before the next check. Only 401s count: a 403 on a post can be a
+// duplicate or a protected conversation rather than a suspended account.
+func (m *CredentialMonitor) Report(err error) {
+	if m == nil {
+		return
+	}
+	if credentialsRejected(err) {
+		m.set(CredentialsRevoked, err)
+	}
+}
+
+// Status returns the last known health of the credentials
+func (m *CredentialMonitor) Status() CredentialStatus {
+	if m == nil {
+		return CredentialStatus{State: CredentialsOK}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// Healthy reports whether posting may go on
+func (m *CredentialMonitor) Healthy() bool {
+	return m.Status().Healthy()
+}
+
+// Wait blocks until the credentials are healthy or ctx is done
+func (m *CredentialMonitor) Wait(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	recovered := m.recovered
+	m.mu.Unlock()
+
+	select {
+	case <-recovered:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// set records the outcome of a check or report, announcing state changes
+func (m *CredentialMonitor) set(state CredentialState, cause error) CredentialStatus {
+	now := time.Now()
+
+	m.mu.Lock()
+	previous := m.status
+	m.status.CheckedAt = now
+	m.status.Error = ""
+	if cause != nil {
+		m.status.Error = cause.Error()
+	}
+	changed := state != previous.State
+	if changed {
+		m.status.State = state
+		m.status.Since = now
+		if state == CredentialsOK {
+			close(m.recovered)
+		} else if previous.State == CredentialsOK {
+			m.recovered = make(chan struct{})
+		}
+	}
+	status := m.status
+	m.mu.Unlock()
+
+	if !changed {
+		return status
+	}
+
+	log := m.logger.WithFields(logrus.Fields{
+		"action":   m.Name(),
+		"state":    state,
+		"previous": previous.State,
+	})
+	if state == CredentialsOK {
+		log.WithField("down_for", now.Sub(previous.Since).Round(time.Second)).Info("Twitter credentials work again, resuming posting")
+	} else {
+		log.WithError(cause).Error("Twitter credentials failing, holding posts")
+	}
+
+	data := map[string]interface{}{
+		"state":    string(state),
+		"previous": string(previous.State),
+	}
+	if status.Error != "" {
+		data["error"] = status.Error
+	}
+	m.options.Events.Emit(events.CredentialsChanged, m.tweetStore.BotID(), data)
+	return status
+}
+
+// credentialState classifies the outcome of an authenticated call. It
+// reports false when err says nothing about the credentials.
+func credentialState(err error) (CredentialState, bool) {
+	if err == nil {
+		return CredentialsOK, true
+	}
+	if errors.Is(err, twitter.ErrOAuth2NotAuthorized) || errors.Is(err, twitter.ErrOAuth2Expired) {
+		return CredentialsRevoked, true
+	}
+
+	var apiErr *twitter.APIError
+	if !errors.As(err, &apiErr) {
+		return "", false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		return CredentialsRevoked, true
+	case http.StatusForbidden:
+		return CredentialsSuspended, true
+	default:
+		return "", false
+	}
+}
+
+// credentialsRejected reports whether err is the API rejecting the
+// credentials themselves
+func credentialsRejected(err error) bool {
+	state, ok := credentialState(err)
+	return ok && state == CredentialsRevoked
+}
@@ -0,0 +1,185 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultCuratorListName is the list the curator keeps notable subjects in
+	DefaultCuratorListName = "Peasants of Note"
+
+	// curatorBatch is how many judgments one curation pass handles
+	curatorBatch = 20
+)
+
+// CuratorOptions configures the curator
+type CuratorOptions struct {
+	Interval time.Duration
+	// ListName is the list judged users are added to; it is created when the
+	// bot does not own a list of that name yet
+	ListName string
+	// ListDescription describes a newly created list
+	ListDescription string
+	// PrivateList keeps a newly created list private
+	PrivateList bool
+	// MinScore is the overall judgment score from which a subject is curated
+	MinScore float64
+}
+
+// CuratorAction collects the users the Judgment Throne scored highly in a
+// list and bookmarks the tweets they were judged for, as raw material for
+// later commentary
+type CuratorAction struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    CuratorOptions
+	listID     string
+}
+
+// NewCuratorAction creates a new curator
+func NewCuratorAction(client *twitter.TwitterClient, store *memory.TweetStore, logger *logrus.Logger, options CuratorOptions) *CuratorAction {
+	if options.Interval <= 0 {
+		options.Interval = 6 * time.Hour
+	}
+	if options.ListName == "" {
+		options.ListName = DefaultCuratorListName
+	}
+	if options.ListDescription == "" {
+		options.ListDescription = "Subjects the Judgment Throne found worthy of note"
+	}
+	if options.MinScore <= 0 {
+		options.MinScore = 7
+	}
+	return &CuratorAction{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (c *CuratorAction) Name() string {
+	return "curator"
+}
+
+// Execute implements the Action interface
+func (c *CuratorAction) Execute(ctx context.Context) error {
+	log := c.logger.WithContext(ctx).WithField("action", c.Name())
+
+	ticker := time.NewTicker(c.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting curator")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Curator stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := c.Curate(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to curate judgments")
+				continue
+			}
+		}
+	}
+}
+
+// Stop implements the Action interface
+func (c *CuratorAction) Stop() {
+	c.logger.WithField("action", c.Name()).Info("Stopping curator")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (c *CuratorAction) SetInterval(interval time.Duration) {
+	c.options.Interval = interval
+}
+
+// Curate adds the subjects of high-scoring judgments to the list and
+// bookmarks their judged tweets. A judgment is only marked curated once both
+// succeeded, so failures are retried on the next pass.
+func (c *CuratorAction) Curate(ctx context.Context) error {
+	judgments, err := c.tweetStore.UncuratedJudgments(ctx, c.options.MinScore, curatorBatch)
+	if err != nil {
+		return err
+	}
+	if len(judgments) == 0 {
+		c.logger.WithContext(ctx).WithField("action", c.Name()).Debug("Nothing to curate")
+		return nil
+	}
+
+	listID, err := c.ensureList(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, judgment := range judgments {
+		log := c.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"action":      c.Name(),
+			"judgment_id": judgment.ID,
+			"subject":     judgment.SubjectUsername,
+			"overall":     judgment.Overall,
+		})
+
+		if judgment.SubjectID != "" {
+			if _, err := c.client.AddListMember(ctx, listID, judgment.SubjectID); err != nil {
+				return fmt.Errorf("failed to add @%s to %s: %w", judgment.SubjectUsername, c.options.ListName, err)
+			}
+		}
+		if judgment.SubjectTweetID != "" {
+			if err := c.client.AddBookmark(ctx, judgment.SubjectTweetID); err != nil {
+				return fmt.Errorf("failed to bookmark tweet %s: %w", judgment.SubjectTweetID, err)
+			}
+		}
+		if err := c.tweetStore.MarkJudgmentCurated(ctx, judgment.ID); err != nil {
+			return err
+		}
+		log.Info("Curated judgment")
+	}
+	return nil
+}
+
+// ensureList returns the ID of the curator's list, creating the list when the
+// bot does not own one of that name yet
+func (c *CuratorAction) ensureList(ctx context.Context) (string, error) {
+	if c.listID != "" {
+		return c.listID, nil
+	}
+
+	userID, err := c.client.GetAuthenticatedUserID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve authenticated user: %w", err)
+	}
+	lists, err := c.client.GetOwnedLists(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, list := range lists {
+		if strings.EqualFold(list.Name, c.options.ListName) {
+			c.listID = list.ID
+			return c.listID, nil
+		}
+	}
+
+	list, err := c.client.CreateList(ctx, twitter.CreateListParams{
+		Name:        c.options.ListName,
+		Description: c.options.ListDescription,
+		Private:     c.options.PrivateList,
+	})
+	if err != nil {
+		return "", err
+	}
+	c.listID = list.ID
+	return c.listID, nil
+}
@@ -0,0 +1,179 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// EditWatchOptions configures the edit watcher
+type EditWatchOptions struct {
+	Interval time.Duration
+	// Lookback is how old stored mentions may be and still be checked for
+	// edits; tweets can only be edited for an hour after posting
+	Lookback time.Duration
+	// Events receives a tweet_edited event per edit found; nil disables events
+	Events *events.Bus
+}
+
+// EditWatcher periodically re-fetches recently stored mentions and picks up
+// their edits. The stored text is replaced by the latest version, so a mention
+// still waiting in the reply queue is answered as it reads now. Mentions the
+// bot already answered are flagged as edited after reply.
+type EditWatcher struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    EditWatchOptions
+}
+
+// NewEditWatcher creates a new edit watcher
+func NewEditWatcher(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options EditWatchOptions,
+) *EditWatcher {
+	if options.Lookback <= 0 {
+		options.Lookback = 2 * time.Hour
+	}
+	return &EditWatcher{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (w *EditWatcher) Name() string {
+	return "edit_watcher"
+}
+
+// Execute implements the Action interface
+func (w *EditWatcher) Execute(ctx context.Context) error {
+	log := w.logger.WithContext(ctx).WithField("action", w.Name())
+
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting edit watcher")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Edit watcher stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := w.Check(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to check mentions for edits")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Check re-fetches the mentions stored within the lookback window and records
+// the ones whose edit history grew since they were stored
+func (w *EditWatcher) Check(ctx context.Context) error {
+	stored, err := w.tweetStore.EditableMentions(ctx, time.Now().Add(-w.options.Lookback))
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(stored))
+	for i, tweet := range stored {
+		ids[i] = tweet.TweetID
+	}
+	tweets, err := w.client.LookupTweets(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch stored mentions: %w", err)
+	}
+	histories := make(map[string][]string, len(tweets))
+	for _, tweet := range tweets {
+		histories[tweet.ID] = tweet.EditHistoryTweetIDs
+	}
+
+	// The looked up tweets are the versions that were stored; the edited text
+	// is only on the latest version
+	edited := make(map[string]memory.EditableTweet)
+	var latestIDs []string
+	for _, tweet := range stored {
+		// Tweets stored without their history count as one version
+		known := len(tweet.EditHistory)
+		if known == 0 {
+			known = 1
+		}
+		history := histories[tweet.TweetID]
+		if len(history) <= known {
+			continue
+		}
+		tweet.EditHistory = history
+		edited[tweet.LatestVersionID()] = tweet
+		latestIDs = append(latestIDs, tweet.LatestVersionID())
+	}
+	if len(latestIDs) == 0 {
+		w.logger.WithContext(ctx).WithField("checked", len(stored)).Debug("No edited mentions")
+		return nil
+	}
+
+	versions, err := w.client.LookupTweets(ctx, latestIDs)
+	if err != nil {
+		return fmt.Errorf("failed to fetch edited mentions: %w", err)
+	}
+
+	var recorded, flagged int
+	for _, version := range versions {
+		tweet, ok := edited[version.ID]
+		if !ok {
+			continue
+		}
+		version.EditHistoryTweetIDs = tweet.EditHistory
+
+		afterReply, err := w.tweetStore.RecordTweetEdit(ctx, tweet.TweetID, version)
+		if err != nil {
+			return err
+		}
+		recorded++
+		if afterReply {
+			flagged++
+		}
+
+		w.options.Events.Emit(events.TweetEdited, w.tweetStore.BotID(), map[string]interface{}{
+			"tweet_id":           tweet.TweetID,
+			"version_id":         version.ID,
+			"conversation_id":    tweet.ConversationID,
+			"text":               version.Text,
+			"edited_after_reply": afterReply,
+		})
+	}
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"checked":            len(stored),
+		"edited":             recorded,
+		"edited_after_reply": flagged,
+	}).Info("Recorded edited mentions")
+	return nil
+}
+
+// Stop implements the Action interface
+func (w *EditWatcher) Stop() {
+	log := w.logger.WithField("action", w.Name())
+	log.Info("Stopping edit watcher")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (w *EditWatcher) SetInterval(interval time.Duration) {
+	w.options.Interval = interval
+}
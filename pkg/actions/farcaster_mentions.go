@@ -0,0 +1,296 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/internal/personality/traits"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/filters"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/farcaster"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+)
+
+// FarcasterMentionsHandler stores casts mentioning the bot and answers them
+// with the same reply generator as tweets. Casts live in their own tweet store
+// partition, keyed by the bot's farcaster.UserID, so recall, moderation and
+// analytics work as they do for Twitter.
+type FarcasterMentionsHandler struct {
+	client         *farcaster.Client
+	store          *memory.TweetStore
+	replyGenerator thoughts.MentionReplyGenerator
+	logger         *logrus.Logger
+	options        FarcasterMentionsOptions
+	done           chan struct{}
+}
+
+// FarcasterMentionsOptions configures the FarcasterMentionsHandler
+type FarcasterMentionsOptions struct {
+	Interval time.Duration
+	// MaxResults caps the mentions fetched per check
+	MaxResults int
+	// RepliesPerCheck caps the casts answered per check
+	RepliesPerCheck int
+	// Personality overrides the base persona for replies; its category
+	// sections are picked by the category of the cast being answered
+	Personality traits.Persona
+	// Events receives mention_received and reply_posted events; nil disables them
+	Events *events.Bus
+	// Sentiment, when set, scores each cast so replies can adapt their tone
+	Sentiment *filters.SentimentAnalyzer
+	// Context fits earlier casts of a thread into the reply prompt; defaults
+	// to thoughts.DefaultContextTokens
+	Context *thoughts.ContextBuilder
+}
+
+// NewFarcasterMentionsHandler creates a new instance of FarcasterMentionsHandler
+func NewFarcasterMentionsHandler(
+	client *farcaster.Client,
+	store *memory.TweetStore,
+	replyGenerator thoughts.MentionReplyGenerator,
+	logger *logrus.Logger,
+	options FarcasterMentionsOptions,
+) *FarcasterMentionsHandler {
+	if options.Interval == 0 {
+		options.Interval = time.Minute
+	}
+	if options.MaxResults == 0 {
+		options.MaxResults = 25
+	}
+	if options.RepliesPerCheck == 0 {
+		options.RepliesPerCheck = 5
+	}
+	if options.Context == nil {
+		options.Context = thoughts.NewContextBuilder("", thoughts.DefaultContextTokens)
+	}
+
+	return &FarcasterMentionsHandler{
+		client:         client,
+		store:          store,
+		replyGenerator: replyGenerator,
+		logger:         logger,
+		options:        options,
+		done:           make(chan struct{}),
+	}
+}
+
+// Name returns the unique identifier for this action
+func (h *FarcasterMentionsHandler) Name() string {
+	return "farcaster_mentions_handler"
+}
+
+// Execute implements the Action interface
+func (h *FarcasterMentionsHandler) Execute(ctx context.Context) error {
+	log := h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"interval": h.options.Interval,
+		"fid":      h.client.FID(),
+	})
+	log.Info("Starting Farcaster mention monitoring")
+
+	ticker := time.NewTicker(h.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-h.done:
+			return nil
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := h.CheckMentions(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to check Farcaster mentions")
+			}
+			if err := h.ReplyToMentions(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to reply to Farcaster mentions")
+			}
+		}
+	}
+}
+
+// Stop implements the Action interface
+func (h *FarcasterMentionsHandler) Stop() {
+	close(h.done)
+}
+
+// SetInterval implements the IntervalSetter interface
+func (h *FarcasterMentionsHandler) SetInterval(interval time.Duration) {
+	h.options.Interval = interval
+}
+
+// CheckMentions stores new casts mentioning or replying to the bot
+func (h *FarcasterMentionsHandler) CheckMentions(ctx context.Context) error {
+	log := h.logger.WithContext(ctx).WithField("method", "CheckMentions")
+
+	page, err := h.client.GetMentions(ctx, farcaster.GetMentionsParams{Limit: h.options.MaxResults})
+	if err != nil {
+		return fmt.Errorf("failed to get mentions: %w", err)
+	}
+
+	blocklist, err := h.store.LoadBlocklist(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load blocklist: %w", err)
+	}
+
+	stored := 0
+	for _, cast := range page.Casts {
+		tweet := cast.ToTweet()
+		castLog := log.WithFields(logrus.Fields{
+			"cast_hash":   cast.Hash,
+			"author_fid":  cast.Author.FID,
+			"thread_hash": tweet.ConversationID,
+		})
+
+		if cast.Author.FID == h.client.FID() {
+			continue
+		}
+		if skip, reason := blocklist.Skip(tweet.AuthorID, tweet.ConversationID); skip {
+			castLog.WithField("reason", reason).Debug("Skipping cast")
+			continue
+		}
+		// Mentions are fetched newest first on every check, so most are known
+		known, err := h.store.HasTweet(ctx, cast.Hash)
+		if err != nil {
+			castLog.WithError(err).Error("Failed to check for stored cast")
+			continue
+		}
+		if known {
+			continue
+		}
+
+		category := memory.DetermineTweetCategory(tweet)
+		if err := h.store.SaveTweet(ctx, tweet, category, cast.Author.DisplayName, cast.Author.Username); err != nil {
+			castLog.WithError(err).Error("Failed to save cast")
+			continue
+		}
+		stored++
+
+		if h.options.Sentiment != nil {
+			if err := h.store.SetSentiment(ctx, cast.Hash, h.options.Sentiment.Score(ctx, cast.Text)); err != nil {
+				castLog.WithError(err).Error("Failed to record sentiment")
+			}
+		}
+
+		h.options.Events.Emit(events.MentionReceived, h.store.BotID(), map[string]interface{}{
+			"platform":        "farcaster",
+			"tweet_id":        cast.Hash,
+			"author_id":       tweet.AuthorID,
+			"author_username": cast.Author.Username,
+			"conversation_id": tweet.ConversationID,
+			"category":        category,
+			"text":            cast.Text,
+		})
+	}
+
+	log.WithFields(logrus.Fields{
+		"casts":  len(page.Casts),
+		"stored": stored,
+	}).Debug("Checked Farcaster mentions")
+
+	return nil
+}
+
+// ReplyToMentions answers the stored casts that need a reply, oldest first
+func (h *FarcasterMentionsHandler) ReplyToMentions(ctx context.Context) error {
+	threads, err := h.store.RecallTweetsNeedingReplyWithOptions(ctx, h.client, memory.RecallOptions{
+		Limit: h.options.RepliesPerCheck,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to recall casts needing reply: %w", err)
+	}
+
+	for _, thread := range threads {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := h.reply(ctx, thread); err != nil {
+			h.logger.WithContext(ctx).WithError(err).WithField("thread_hash", thread.ConversationID).Error("Failed to reply to cast")
+		}
+	}
+	return nil
+}
+
+// reply answers the newest cast of the thread that needs a reply
+func (h *FarcasterMentionsHandler) reply(ctx context.Context, thread memory.ConversationThread) error {
+	latest, ok := thread.Latest()
+	if !ok {
+		return nil
+	}
+
+	var earlier []thoughts.ContextPost
+	for _, cast := range thread.Tweets {
+		if cast.CreatedAt.Before(latest.CreatedAt) {
+			earlier = append(earlier, thoughts.ContextPost{
+				AuthorUsername: cast.AuthorUsername,
+				AuthorName:     cast.AuthorName,
+				Text:           cast.Text,
+			})
+		}
+	}
+
+	text, err := h.replyGenerator.GenerateReply(routeReply(ctx, latest, thread.Tweets), thoughts.MentionReplyConfig{
+		TweetText:           latest.Text,
+		ConversationContext: h.options.Context.Build(earlier),
+		MaxLength:           farcaster.MaxCastBytes,
+		Temperature:         0.7,
+		AuthorUsername:      latest.AuthorUsername,
+		AuthorName:          latest.AuthorName,
+		Category:            latest.Category,
+		Personality:         h.options.Personality.Sections,
+		Sentiment:           latest.Sentiment,
+		Instructions:        "This is a Farcaster cast, not a tweet; do not use hashtags",
+
+		CategoryPersonalities: h.options.Personality.Categories,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate reply: %w", err)
+	}
+	text = fitCast(text)
+
+	cast, err := h.client.PublishCast(ctx, farcaster.PublishCastParams{
+		Text:       text,
+		ParentHash: latest.TweetID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The cast is public now, so record it even if shutdown cancelled ctx
+	if err := h.store.SaveAgentReply(context.WithoutCancel(ctx), latest.TweetID, cast.Hash, thread.ConversationID, text); err != nil {
+		return fmt.Errorf("failed to save reply: %w", err)
+	}
+
+	h.options.Events.Emit(events.ReplyPosted, h.store.BotID(), map[string]interface{}{
+		"platform":        "farcaster",
+		"reply_tweet_id":  cast.Hash,
+		"reply_to_id":     latest.TweetID,
+		"conversation_id": thread.ConversationID,
+		"text":            text,
+	})
+
+	h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"cast_hash":   cast.Hash,
+		"parent_hash": latest.TweetID,
+		"thread_hash": thread.ConversationID,
+	}).Info("Replied to Farcaster mention")
+
+	return nil
+}
+
+// fitCast shortens a generated reply until it fits the cast byte limit; the
+// reply generator counts characters, which can be more than one byte each
+func fitCast(text string) string {
+	for len(text) > farcaster.MaxCastBytes {
+		shorter := thoughts.TruncateText(text, thoughts.WeightedLength(text)-(len(text)-farcaster.MaxCastBytes))
+		if shorter == "" || len(shorter) >= len(text) {
+			return strings.ToValidUTF8(text[:farcaster.MaxCastBytes], "")
+		}
+		text = shorter
+	}
+	return text
+}
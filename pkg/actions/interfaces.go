@@ -15,6 +15,13 @@ type Action interface {
 	Stop()
 }
 
+// IntervalSetter is implemented by actions that run on a ticker. The new
+// interval takes effect the next time the action is executed, so it must only
+// be set while the action is not running.
+type IntervalSetter interface {
+	SetInterval(interval time.Duration)
+}
+
 // ActionConfig holds common configuration for actions
 type ActionConfig struct {
 	Name     string
@@ -0,0 +1,99 @@
+package actions
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/lisanmuaddib/agent-go/pkg/imagegen"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// Default meme settings
+const (
+	// DefaultMemeProbability attaches a meme to one in ten eligible replies
+	DefaultMemeProbability = 0.1
+	// DefaultMemeMinEngagement is the weighted engagement a tweet needs before
+	// its reply can carry a meme
+	DefaultMemeMinEngagement = 10
+)
+
+// MemeOptions configures generated images on replies
+type MemeOptions struct {
+	// Probability is the chance an eligible reply gets a meme, from 0 to 1
+	Probability float64
+	// MinEngagement is the weighted engagement (see memory.ReplyPerformance)
+	// the tweet being answered needs for its reply to be eligible
+	MinEngagement int
+	Prompts       imagegen.PromptBuilder
+}
+
+// memeAttacher generates and uploads memes for replies
+type memeAttacher struct {
+	generator imagegen.Generator
+	options   MemeOptions
+	roll      func() float64
+}
+
+// WithMemes occasionally attaches a generated cat lord meme to replies to
+// high-engagement tweets. A nil generator leaves replies text-only.
+func (tr *TweetResponder) WithMemes(generator imagegen.Generator, options MemeOptions) *TweetResponder {
+	if generator == nil {
+		tr.memes = nil
+		return tr
+	}
+	if options.Probability <= 0 {
+		options.Probability = DefaultMemeProbability
+	}
+	tr.memes = &memeAttacher{generator: generator, options: options, roll: rand.Float64}
+	return tr
+}
+
+// attachMeme returns the media IDs of a meme for the reply, or nil when the
+// tweet does not qualify or the meme could not be made. Failures only cost the
+// image, never the reply.
+func (tr *TweetResponder) attachMeme(ctx context.Context, tweet memory.TweetNeedingReply, replyText string) []string {
+	if tr.memes == nil {
+		return nil
+	}
+	log := tr.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"method":   "attachMeme",
+		"tweet_id": tweet.TweetID,
+	})
+
+	engagement, err := tr.tweetStore.TweetEngagement(ctx, tweet.TweetID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to load tweet engagement, replying without meme")
+		return nil
+	}
+	if engagement < tr.memes.options.MinEngagement || tr.memes.roll() >= tr.memes.options.Probability {
+		return nil
+	}
+
+	prompt := tr.memes.options.Prompts.Build(imagegen.MemeContext{
+		TweetText:      tweet.Text,
+		ReplyText:      replyText,
+		AuthorUsername: tweet.AuthorUsername,
+	})
+	image, err := tr.memes.generator.Generate(ctx, prompt)
+	if err != nil {
+		log.WithError(err).Warn("Failed to generate meme, replying without it")
+		return nil
+	}
+
+	media, err := tr.client.UploadMedia(ctx, twitter.UploadMediaParams{
+		Data:      image.Data,
+		MediaType: image.MediaType,
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to upload meme, replying without it")
+		return nil
+	}
+
+	log.WithFields(logrus.Fields{
+		"engagement": engagement,
+		"media_id":   media.ID,
+	}).Info("Attaching generated meme to reply")
+	return []string{media.ID}
+}
@@ -0,0 +1,106 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// storeThreadContext stores the root and the replied-to tweets above the
+// mentions of a page that are not stored yet, so a mention landing deep in a
+// thread is answered with the thread as context. Tweets in the page's includes
+// are used as they are; the rest are looked up one thread level at a time, up
+// to ThreadDepth levels. It returns how many tweets were stored.
+func (h *MentionsHandler) storeThreadContext(ctx context.Context, page []memory.TweetWithMeta, includes *twitter.TweetIncludes) (int64, error) {
+	if h.options.ThreadDepth < 0 {
+		return 0, nil
+	}
+
+	known := make(map[string]bool)
+	for _, saved := range page {
+		known[saved.Tweet.ID] = true
+	}
+	var wanted []string
+	want := func(id string) {
+		if id != "" && !known[id] {
+			known[id] = true
+			wanted = append(wanted, id)
+		}
+	}
+	for _, saved := range page {
+		want(repliedToID(saved.Tweet))
+		want(saved.Tweet.ConversationID)
+	}
+
+	authors := make(map[string]*twitter.User)
+	if includes != nil {
+		for i := range includes.Users {
+			authors[includes.Users[i].ID] = &includes.Users[i]
+		}
+	}
+
+	var thread []memory.TweetWithMeta
+	for level := 0; level < h.options.ThreadDepth && len(wanted) > 0; level++ {
+		stored, err := h.tweetStore.StoredTweetIDs(ctx, wanted)
+		if err != nil {
+			return 0, err
+		}
+
+		var found []twitter.Tweet
+		var lookup []string
+		for _, id := range wanted {
+			if stored[id] {
+				continue
+			}
+			if included := includes.Tweet(id); included != nil {
+				found = append(found, *included)
+			} else {
+				lookup = append(lookup, id)
+			}
+		}
+
+		if len(lookup) > 0 {
+			resp, err := h.client.LookupThreadTweets(ctx, lookup)
+			if err != nil {
+				return 0, err
+			}
+			found = append(found, resp.Data...)
+			for i := range resp.Includes.Users {
+				authors[resp.Includes.Users[i].ID] = &resp.Includes.Users[i]
+			}
+		}
+
+		wanted = nil
+		for _, tweet := range found {
+			meta := memory.TweetWithMeta{Tweet: tweet, Category: memory.CategoryConversation}
+			if author := authors[tweet.AuthorID]; author != nil {
+				meta.AuthorName = author.Name
+				meta.AuthorUsername = author.Username
+				meta.AuthorFollowers = author.PublicMetrics.FollowersCount
+				meta.AuthorVerified = author.Verified
+			}
+			thread = append(thread, meta)
+			want(repliedToID(tweet))
+		}
+	}
+
+	if len(thread) > 0 {
+		h.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"tweets":    len(thread),
+			"unvisited": len(wanted),
+		}).Debug("Storing thread context of mentions")
+	}
+	return h.tweetStore.SaveContextTweets(ctx, thread)
+}
+
+// repliedToID returns the ID of the tweet a tweet replies to, or ""
+func repliedToID(tweet twitter.Tweet) string {
+	for _, ref := range tweet.ReferencedTweets {
+		if ref.Type == "replied_to" {
+			return ref.ID
+		}
+	}
+	return ""
+}
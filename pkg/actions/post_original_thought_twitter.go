@@ -2,11 +2,17 @@ package actions
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/lisanmuaddib/agent-go/internal/personality/traits"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/lens"
 	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/market"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
 	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
 	"github.com/sirupsen/logrus"
 )
@@ -20,12 +26,19 @@ const (
 type OriginalThoughtConfig struct {
 	Topic       string
 	Temperature float64 // Controls randomness of thought generation
+	// RecentThoughts are earlier tweets the thought must not nearly repeat
+	RecentThoughts []string
+	// SimilarityThreshold overrides thoughts.DefaultSimilarityThreshold when set
+	SimilarityThreshold float64
 }
 
 // OriginalThoughtPoster handles posting thoughts to Twitter
 type OriginalThoughtPoster struct {
-	thoughtGen thoughts.OriginalThoughtGenerator
-	twitter    *twitter.TwitterClient
+	thoughtGen  thoughts.OriginalThoughtGenerator
+	twitter     *twitter.TwitterClient
+	market      *market.Client
+	personality map[string]string
+	lens        *lens.Client
 }
 
 // NewOriginalThoughtPoster creates a new thought poster instance
@@ -38,12 +51,27 @@ func NewOriginalThoughtPoster(thoughtGen thoughts.OriginalThoughtGenerator, twit
 
 // PostOriginalThought generates a thought and posts it to Twitter
 func (p *OriginalThoughtPoster) PostOriginalThought(ctx context.Context, config OriginalThoughtConfig) (*twitter.Tweet, error) {
-	// Generate the thought using base personality traits
+	// Give the model real prices to riff on when market data is available
+	var marketContext string
+	if p.market != nil {
+		marketContext = p.market.Summary(ctx)
+	}
+
+	personality := p.personality
+	if personality == nil {
+		personality = traits.BasePromptSections
+	}
+
+	// Generate the thought using the account's personality traits
 	thought, err := p.thoughtGen.GenerateOriginalThought(ctx, thoughts.OriginalThoughtConfig{
-		Topic:       config.Topic,
-		MaxLength:   MaxTweetLength,
-		Temperature: config.Temperature,
-		Personality: traits.BasePromptSections,
+		Topic:         config.Topic,
+		MaxLength:     MaxTweetLength,
+		Temperature:   config.Temperature,
+		Personality:   personality,
+		MarketContext: marketContext,
+
+		RecentThoughts:      config.RecentThoughts,
+		SimilarityThreshold: config.SimilarityThreshold,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error generating thought: %w", err)
@@ -66,14 +94,36 @@ func (p *OriginalThoughtPoster) PostOriginalThought(ctx context.Context, config
 		"text":     tweet.Text,
 	}).Info("successfully posted thought to Twitter")
 
+	// Cross-posting is best effort; the tweet is already out
+	if p.lens != nil {
+		if _, err := p.lens.Post(ctx, thought); err != nil {
+			logrus.WithError(err).WithField("tweet_id", tweet.ID).Error("failed to cross-post thought to Lens")
+		}
+	}
+
 	return tweet, nil
 }
 
 // ThoughtOptions configures the original thought posting action
 type ThoughtOptions struct {
-	Interval    time.Duration
-	Topic       string  // Default topic to post about
-	Temperature float64 // Controls randomness of thought generation
+	Interval time.Duration
+	Topic    string        // Default topic to post about
+	Planner  *TopicPlanner // Optional planner choosing a topic per run instead of Topic
+	// Store keeps posted thoughts; new thoughts too similar to those posted
+	// within DuplicateWindow (default 14 days) are regenerated
+	Store               *memory.TweetStore
+	DuplicateWindow     time.Duration
+	SimilarityThreshold float64           // Overrides thoughts.DefaultSimilarityThreshold when set
+	Temperature         float64           // Controls randomness of thought generation
+	Market              *market.Client    // Optional price source for market commentary
+	Personality         map[string]string // Optional persona; defaults to the base personality
+	Lens                *lens.Client      // Optional Lens client thoughts are cross-posted with
+	// Schedule holds thoughts that come due during quiet hours and embargoes
+	// until posting opens again; nil posts at any time
+	Schedule *schedule.Policy
+	// Credentials holds thoughts while the account's credentials fail; nil
+	// posts regardless
+	Credentials *CredentialMonitor
 }
 
 type OriginalThoughtAction struct {
@@ -89,8 +139,17 @@ func NewOriginalThoughtAction(
 	logger *logrus.Logger,
 	options ThoughtOptions,
 ) *OriginalThoughtAction {
+	poster := NewOriginalThoughtPoster(thoughtGen, twitterClient)
+	poster.market = options.Market
+	poster.personality = options.Personality
+	poster.lens = options.Lens
+
+	if options.DuplicateWindow <= 0 {
+		options.DuplicateWindow = 14 * 24 * time.Hour
+	}
+
 	return &OriginalThoughtAction{
-		poster:   NewOriginalThoughtPoster(thoughtGen, twitterClient),
+		poster:   poster,
 		options:  options,
 		stopChan: make(chan struct{}),
 		logger:   logger,
@@ -112,17 +171,109 @@ func (a *OriginalThoughtAction) Execute(ctx context.Context) error {
 		case <-a.stopChan:
 			return nil
 		case <-ticker.C:
-			_, err := a.poster.PostOriginalThought(ctx, OriginalThoughtConfig{
-				Topic:       a.options.Topic,
-				Temperature: a.options.Temperature,
-			})
-			if err != nil {
-				a.logger.WithError(err).Error("Failed to post original thought")
+			tick := logging.StartTrace(ctx)
+			held := false
+			if now := time.Now(); !a.options.Schedule.Open(now) {
+				a.logger.WithContext(tick).WithField("until", a.options.Schedule.NextOpen(now)).Info("Holding original thought until posting opens")
+				if err := a.options.Schedule.Wait(ctx); err != nil {
+					return err
+				}
+				held = true
+			}
+			if !a.options.Credentials.Healthy() {
+				a.logger.WithContext(tick).WithField("credentials", a.options.Credentials.Status().State).Warn("Holding original thought until the credentials work again")
+				if err := a.options.Credentials.Wait(ctx); err != nil {
+					return err
+				}
+				held = true
+			}
+			if held {
+				// Post the held thought once, not once per tick missed while waiting
+				select {
+				case <-ticker.C:
+				default:
+				}
+			}
+			if err := a.post(tick); err != nil {
+				a.logger.WithContext(tick).WithError(err).Error("Failed to post original thought")
+				a.options.Credentials.Report(err)
+			}
+		}
+	}
+}
+
+// post posts one thought about the planned topic, or the default topic when
+// there is no planner
+func (a *OriginalThoughtAction) post(ctx context.Context) error {
+	topic := PlannedTopic{Topic: a.options.Topic}
+	if a.options.Planner != nil {
+		var err error
+		if topic, err = a.options.Planner.Next(ctx); err != nil {
+			return fmt.Errorf("failed to plan topic: %w", err)
+		}
+	}
+
+	_, err := a.postTopic(ctx, topic)
+	if errors.Is(err, thoughts.ErrDuplicateThought) {
+		a.logger.WithContext(ctx).WithError(err).WithField("topic", topic.Topic).Warn("Skipping thought that repeats a recent tweet")
+		return nil
+	}
+	return err
+}
+
+// PostTopic posts one thought about topic right away, bypassing the planner.
+// It returns thoughts.ErrDuplicateThought when every generated thought
+// repeats a recent tweet.
+func (a *OriginalThoughtAction) PostTopic(ctx context.Context, topic string) (*twitter.Tweet, error) {
+	return a.postTopic(ctx, PlannedTopic{Topic: topic})
+}
+
+// postTopic generates, posts and records a thought about topic
+func (a *OriginalThoughtAction) postTopic(ctx context.Context, topic PlannedTopic) (*twitter.Tweet, error) {
+	var recent []string
+	if a.options.Store != nil {
+		records, err := a.options.Store.RecentThoughtTopics(ctx, time.Now().Add(-a.options.DuplicateWindow))
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if record.Text != "" {
+				recent = append(recent, record.Text)
 			}
 		}
 	}
+
+	tweet, err := a.poster.PostOriginalThought(ctx, OriginalThoughtConfig{
+		Topic:               topic.Topic,
+		Temperature:         a.options.Temperature,
+		RecentThoughts:      recent,
+		SimilarityThreshold: a.options.SimilarityThreshold,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case a.options.Planner != nil:
+		err = a.options.Planner.Posted(ctx, topic, tweet)
+	case a.options.Store != nil:
+		err = a.options.Store.SaveThoughtTopic(ctx, &memory.ThoughtTopicRecord{
+			Topic:   topic.Topic,
+			TweetID: tweet.ID,
+			Text:    tweet.Text,
+		})
+	}
+	if err != nil {
+		a.logger.WithContext(ctx).WithError(err).WithField("topic", topic.Topic).Warn("Failed to record posted thought")
+	}
+	return tweet, nil
 }
 
 func (a *OriginalThoughtAction) Stop() {
 	close(a.stopChan)
 }
+
+// SetInterval implements the IntervalSetter interface
+func (a *OriginalThoughtAction) SetInterval(interval time.Duration) {
+	a.options.Interval = interval
+}
@@ -3,12 +3,16 @@ package actions
 import (
 	"context"
 	"fmt"
-	"os"
-	"sort"
 	"strings"
 	"time"
 
+	"github.com/lisanmuaddib/agent-go/internal/personality/traits"
+	"github.com/lisanmuaddib/agent-go/pkg/commands"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/experiments"
 	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	"github.com/lisanmuaddib/agent-go/pkg/lock"
 	"github.com/lisanmuaddib/agent-go/pkg/memory"
 	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
 	"github.com/sirupsen/logrus"
@@ -22,6 +26,19 @@ type TweetResponder struct {
 	logger         *logrus.Logger
 	limiter        *rate.Limiter
 	replyGenerator thoughts.MentionReplyGenerator
+	personality    traits.Persona
+	experiment     *experiments.Experiment
+	events         *events.Bus
+	memes          *memeAttacher
+	throttle       ThrottleOptions
+	loops          LoopOptions
+	latencySLO     time.Duration
+	critic         thoughts.ReplyCritic
+	researcher     Researcher
+	tipper         *Tipper
+	locker         lock.Locker
+	context        *thoughts.ContextBuilder
+	examples       *ReplyExamples
 }
 
 // BatchProcessConfig holds configuration for batch processing
@@ -55,22 +72,77 @@ func NewTweetResponder(
 ) *TweetResponder {
 	// Twitter API v2 rate limit: 50 tweets per 15 minutes
 	// Using a more conservative rate of 45 tweets per 15 minutes
-	tweetsPerWindow := 45
-	windowDuration := 15 * time.Minute
-	r := rate.Every(windowDuration / time.Duration(tweetsPerWindow))
-
 	return &TweetResponder{
 		tweetStore:     store,
 		client:         client,
 		logger:         logger,
-		limiter:        rate.NewLimiter(r, 1), // burst size of 1 for conservative approach
+		limiter:        newReplyLimiter(DefaultBatchConfig().TweetsPerWindow),
 		replyGenerator: replyGenerator,
+		context:        thoughts.NewContextBuilder("", thoughts.DefaultContextTokens),
+	}
+}
+
+// WithRateLimit gives the responder its own reply budget, e.g. per bot account
+func (tr *TweetResponder) WithRateLimit(tweetsPerWindow int) *TweetResponder {
+	if tweetsPerWindow > 0 {
+		tr.limiter = newReplyLimiter(tweetsPerWindow)
 	}
+	return tr
+}
+
+// WithExperiment assigns each generated reply to one of the experiment's prompt
+// variants and records the variant on the posted reply
+func (tr *TweetResponder) WithExperiment(experiment *experiments.Experiment) *TweetResponder {
+	tr.experiment = experiment
+	return tr
+}
+
+// WithEvents publishes a reply_posted event for every reply the responder posts
+func (tr *TweetResponder) WithEvents(bus *events.Bus) *TweetResponder {
+	tr.events = bus
+	return tr
+}
+
+// WithLocker makes the responder lock each conversation before answering it,
+// so instances sharing a database never reply to the same tweet twice
+func (tr *TweetResponder) WithLocker(locker lock.Locker) *TweetResponder {
+	tr.locker = locker
+	return tr
+}
+
+// WithPersonality sets the persona used for generated replies; its category
+// sections are picked by the category of the tweet being answered
+func (tr *TweetResponder) WithPersonality(persona traits.Persona) *TweetResponder {
+	tr.personality = persona
+	return tr
+}
+
+// WithContextBuilder sets how earlier tweets of a conversation are fitted into
+// the reply prompt
+func (tr *TweetResponder) WithContextBuilder(builder *thoughts.ContextBuilder) *TweetResponder {
+	if builder != nil {
+		tr.context = builder
+	}
+	return tr
+}
+
+// WithExamples includes the curated reply examples most similar to each
+// answered tweet in the reply prompt
+func (tr *TweetResponder) WithExamples(examples *ReplyExamples) *TweetResponder {
+	tr.examples = examples
+	return tr
+}
+
+// newReplyLimiter spreads tweetsPerWindow replies evenly over a 15 minute window
+func newReplyLimiter(tweetsPerWindow int) *rate.Limiter {
+	windowDuration := 15 * time.Minute
+	r := rate.Every(windowDuration / time.Duration(tweetsPerWindow))
+	return rate.NewLimiter(r, 1) // burst size of 1 for conservative approach
 }
 
 // ProcessTweetsNeedingReply finds and responds to tweets needing replies
 func (tr *TweetResponder) ProcessTweetsNeedingReply(ctx context.Context) error {
-	log := tr.logger.WithField("method", "ProcessTweetsNeedingReply")
+	log := tr.logger.WithContext(ctx).WithField("method", "ProcessTweetsNeedingReply")
 
 	threads, err := tr.tweetStore.RecallTweetsNeedingReply(ctx, tr.client)
 	if err != nil {
@@ -108,107 +180,155 @@ func (tr *TweetResponder) ProcessTweetsNeedingReply(ctx context.Context) error {
 
 				if tr.isRateLimitError(err) {
 					log.Info("Rate limit reached, pausing processing")
-					time.Sleep(5 * time.Minute)
+					if err := sleepContext(ctx, 5*time.Minute); err != nil {
+						return err
+					}
 					continue
 				}
 			}
 
-			time.Sleep(100 * time.Millisecond)
+			if err := sleepContext(ctx, 100*time.Millisecond); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// replyOutcome is what became of a thread handed to replyToThread
+type replyOutcome int
+
+const (
+	// replySkipped means the thread needs no reply, e.g. it was answered already
+	replySkipped replyOutcome = iota
+	// replyPosted means a reply was posted
+	replyPosted
+	// replyDeferred means the thread still needs a reply, but not yet: it is
+	// throttled or another instance is answering it
+	replyDeferred
+)
+
 // handleSingleReply processes a single tweet that needs a reply
 func (tr *TweetResponder) handleSingleReply(ctx context.Context, thread memory.ConversationThread) error {
-	log := tr.logger.WithFields(logrus.Fields{
-		"method":          "handleSingleReply",
+	_, err := tr.replyToThread(ctx, thread)
+	return err
+}
+
+// ReplyToThread answers the newest tweet of a thread right away, subject to
+// the same throttling and conversation locking as queued replies. It reports
+// whether a reply was posted.
+func (tr *TweetResponder) ReplyToThread(ctx context.Context, thread memory.ConversationThread) (bool, error) {
+	outcome, err := tr.replyToThread(ctx, thread)
+	return outcome == replyPosted, err
+}
+
+// replyToThread answers the newest tweet of a thread that needs a reply
+func (tr *TweetResponder) replyToThread(ctx context.Context, thread memory.ConversationThread) (replyOutcome, error) {
+	log := tr.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"method":          "replyToThread",
 		"conversation_id": thread.ConversationID,
 		"tweets_count":    len(thread.Tweets),
 	})
 
-	// Sort tweets by creation time to ensure proper order
-	sort.Slice(thread.Tweets, func(i, j int) bool {
-		return thread.Tweets[i].CreatedAt.Before(thread.Tweets[j].CreatedAt)
-	})
-
-	// Find the latest tweet we should reply to
-	var lastTweet memory.TweetNeedingReply
-	var found bool
-	for i := len(thread.Tweets) - 1; i >= 0; i-- {
-		tweet := thread.Tweets[i]
-		botID := os.Getenv("TWITTER_USER_ID")
-
-		log := tr.logger.WithFields(logrus.Fields{
-			"method":         "handleSingleReply",
-			"bot_id":         botID,
-			"tweet_id":       tweet.TweetID,
-			"tweet_author":   tweet.AuthorID,
-			"category":       tweet.Category,
-			"replied_to":     tweet.RepliedTo,
-			"unread_replies": tweet.UnreadReplies,
-		})
-
-		if botID == "" {
-			log.Error("TWITTER_USER_ID environment variable not set")
-			return fmt.Errorf("TWITTER_USER_ID environment variable not set")
-		}
-
-		log.Debug("Checking tweet for reply eligibility")
-
-		// Check if this tweet needs a reply
-		if tweet.AuthorID != botID && // Not our own tweet
-			((tweet.Category == "mention" && !tweet.RepliedTo) || // New mention
-				(tweet.Category == "conversation" && !tweet.RepliedTo) || // New conversation tweet
-				tweet.UnreadReplies > 0) { // Has unread replies
-
-			log.Info("Found tweet needing reply")
-			lastTweet = tweet
-			found = true
-			break
-		}
+	botID := tr.tweetStore.BotID()
+	if botID == "" {
+		log.Error("Bot user ID not set")
+		return replySkipped, fmt.Errorf("bot user ID not set")
 	}
 
+	// Reply to the newest tweet the recall matched
+	lastTweet, found := thread.Latest()
 	if !found {
 		log.WithField("tweets", thread.Tweets).Debug("No suitable tweet found to reply to")
-		return fmt.Errorf("no suitable tweet found to reply to in thread")
+		return replySkipped, fmt.Errorf("no suitable tweet found to reply to in thread")
 	}
 
+	log.WithFields(logrus.Fields{
+		"bot_id":         botID,
+		"tweet_id":       lastTweet.TweetID,
+		"tweet_author":   lastTweet.AuthorID,
+		"category":       lastTweet.Category,
+		"replied_to":     lastTweet.RepliedTo,
+		"unread_replies": lastTweet.UnreadReplies,
+	}).Info("Found tweet needing reply")
+
 	// Validate tweet ID
 	if lastTweet.TweetID == "" {
 		log.Error("Tweet ID is empty")
-		return fmt.Errorf("invalid tweet_id: empty string")
-	}
-
-	// Build conversation context only from tweets before this one
-	var conversationContext strings.Builder
-	conversationContext.WriteString("Previous conversation:\n")
-	for _, tweet := range thread.Tweets {
-		if tweet.CreatedAt.Before(lastTweet.CreatedAt) {
-			conversationContext.WriteString(fmt.Sprintf("@%s (%s): %s\n",
-				tweet.AuthorUsername,
-				tweet.AuthorName,
-				tweet.Text,
-			))
+		return replySkipped, fmt.Errorf("invalid tweet_id: empty string")
+	}
+
+	// Another instance may be answering this conversation right now
+	if tr.locker != nil {
+		release, answered, err := tr.lockConversation(ctx, thread, lastTweet)
+		if err != nil {
+			return replySkipped, err
+		}
+		if release == nil {
+			log.Debug("Conversation is handled by another instance")
+			return replyDeferred, nil
+		}
+		defer release()
+		if answered {
+			log.Debug("Tweet was answered by another instance")
+			return replySkipped, nil
 		}
 	}
 
-	// Generate AI reply using the mention reply generator
-	config := thoughts.MentionReplyConfig{
-		TweetText:           lastTweet.Text,               // The tweet we're directly replying to
-		ConversationContext: conversationContext.String(), // Full conversation history
-		MaxLength:           280,                          // Twitter's character limit
-		Temperature:         0.7,                          // Adjust as needed
-		AuthorUsername:      lastTweet.AuthorUsername,     // Who we're replying to
-		AuthorName:          lastTweet.AuthorName,         // Their display name
-		Category:            lastTweet.Category,           // Type of interaction
-		Language:            lastTweet.Lang,               // Tweet language
+	// Leave the tweet for later while the user or thread is throttled
+	reason, err := tr.throttled(ctx, lastTweet, thread.ConversationID)
+	if err != nil {
+		return replySkipped, err
+	}
+	if reason != "" {
+		log.WithField("reason", reason).Debug("Reply throttled")
+		return replyDeferred, nil
+	}
+
+	// A conversation going in circles is closed instead of answered
+	if reason, err = tr.detectLoop(ctx, lastTweet, thread.ConversationID, botID); err != nil {
+		return replySkipped, err
+	}
+	if reason != "" {
+		return tr.closeConversation(ctx, log, lastTweet, thread.ConversationID, reason)
+	}
+
+	// Commands such as tips are run instead of answered by the model
+	if tr.tipper != nil {
+		if command, ok := commands.Parse(lastTweet.Text); ok {
+			if err := tr.tipper.Run(ctx, lastTweet, thread.ConversationID, command); err != nil {
+				return replySkipped, fmt.Errorf("failed to run %s command: %w", command.Name, err)
+			}
+			return replyPosted, nil
+		}
+	}
+
+	config, err := tr.replyConfig(ctx, log, lastTweet, thread.Tweets)
+	if err != nil {
+		return replySkipped, err
 	}
 
-	replyText, err := tr.replyGenerator.GenerateReply(ctx, config)
+	var variant string
+	if tr.experiment != nil {
+		assigned := tr.experiment.Assign(lastTweet.TweetID)
+		if assigned.Temperature > 0 {
+			config.Temperature = assigned.Temperature
+		}
+		config.Instructions = assigned.Instructions
+		variant = tr.experiment.Label(assigned)
+		log = log.WithField("variant", variant)
+	}
+
+	routed := routeReply(ctx, lastTweet, thread.Tweets)
+	config.Facts = tr.research(routed, log, config)
+	replyText, err := tr.replyGenerator.GenerateReply(routed, config)
 	if err != nil {
-		return fmt.Errorf("failed to generate reply: %w", err)
+		return replySkipped, fmt.Errorf("failed to generate reply: %w", err)
+	}
+	review := tr.review(routed, log, config, replyText)
+	if review != nil {
+		replyText = review.Final()
 	}
 
 	// Post the reply using existing PostReplyThread implementation
@@ -216,6 +336,7 @@ func (tr *TweetResponder) handleSingleReply(ctx context.Context, thread memory.C
 		Text:           replyText,
 		ReplyToID:      lastTweet.TweetID,
 		ConversationID: thread.ConversationID,
+		MediaIDs:       tr.attachMeme(ctx, lastTweet, replyText),
 	}
 
 	// Add debug logging
@@ -223,6 +344,7 @@ func (tr *TweetResponder) handleSingleReply(ctx context.Context, thread memory.C
 		"reply_to_id":     params.ReplyToID,
 		"conversation_id": params.ConversationID,
 		"text_length":     len(params.Text),
+		"media_count":     len(params.MediaIDs),
 	}).Debug("Preparing to post reply")
 
 	postedTweet, err := tr.client.PostReplyThread(ctx, params)
@@ -232,23 +354,50 @@ func (tr *TweetResponder) handleSingleReply(ctx context.Context, thread memory.C
 			"reply_to_id":     params.ReplyToID,
 			"conversation_id": params.ConversationID,
 		}).Error("Failed to post reply tweet")
-		return fmt.Errorf("failed to post reply: %w", err)
+		return replySkipped, fmt.Errorf("failed to post reply: %w", err)
 	}
 
+	// The reply is public now, so record it even if shutdown cancelled ctx;
+	// otherwise the mention would be answered again on the next run
+	saveCtx := context.WithoutCancel(ctx)
+
 	// Add error handling for SaveAgentReply
 	if postedTweet != nil {
-		if err := tr.tweetStore.SaveAgentReply(lastTweet.TweetID, postedTweet.ID, thread.ConversationID, replyText); err != nil {
+		if err := tr.tweetStore.SaveAgentReply(saveCtx, lastTweet.TweetID, postedTweet.ID, thread.ConversationID, replyText); err != nil {
 			log.WithError(err).Error("Failed to save agent reply to database")
 			// Don't return error as the tweet was still posted successfully
+		} else if variant != "" {
+			if err := tr.tweetStore.SetReplyVariant(saveCtx, postedTweet.ID, variant); err != nil {
+				log.WithError(err).Error("Failed to record reply variant")
+			}
+		}
+		if review != nil {
+			tr.saveReview(saveCtx, log, lastTweet.TweetID, postedTweet.ID, *review)
 		}
 	}
 
 	// Update the original tweet's status
-	if err := tr.tweetStore.UpdateTweetAfterReply(lastTweet.TweetID, postedTweet.ID); err != nil {
+	if err := tr.tweetStore.UpdateTweetAfterReply(saveCtx, lastTweet.TweetID, postedTweet.ID); err != nil {
 		log.WithError(err).Error("Failed to update tweet status after reply")
 		// Don't return error as the tweet was still posted successfully
 	}
 
+	if tr.throttle.enabled() {
+		if err := tr.tweetStore.CountReply(saveCtx, lastTweet.AuthorID, thread.ConversationID, time.Now()); err != nil {
+			log.WithError(err).Error("Failed to count reply for throttling")
+		}
+	}
+
+	tr.recordLatency(saveCtx, log, lastTweet, postedTweet.ID, thread.ConversationID, time.Now())
+
+	tr.events.Emit(events.ReplyPosted, tr.tweetStore.BotID(), map[string]interface{}{
+		"reply_tweet_id":  postedTweet.ID,
+		"reply_to_id":     lastTweet.TweetID,
+		"conversation_id": thread.ConversationID,
+		"text":            replyText,
+		"variant":         variant,
+	})
+
 	log.WithFields(logrus.Fields{
 		"reply_tweet_id": postedTweet.ID,
 		"reply_to_tweet": lastTweet.TweetID,
@@ -256,12 +405,12 @@ func (tr *TweetResponder) handleSingleReply(ctx context.Context, thread memory.C
 		"context_length": len(thread.Tweets),
 	}).Info("Successfully posted reply")
 
-	return nil
+	return replyPosted, nil
 }
 
 // ProcessTweetsInBatches processes tweets in controlled batches
 func (tr *TweetResponder) ProcessTweetsInBatches(ctx context.Context, config BatchProcessConfig) error {
-	log := tr.logger.WithField("method", "ProcessTweetsInBatches")
+	log := tr.logger.WithContext(ctx).WithField("method", "ProcessTweetsInBatches")
 
 	threads, err := tr.tweetStore.RecallTweetsNeedingReply(ctx, tr.client)
 	if err != nil {
@@ -296,7 +445,9 @@ func (tr *TweetResponder) ProcessTweetsInBatches(ctx context.Context, config Bat
 
 			if end < totalThreads {
 				log.WithField("delay", config.BatchDelay).Info("Waiting between batches")
-				time.Sleep(config.BatchDelay)
+				if err := sleepContext(ctx, config.BatchDelay); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -304,6 +455,43 @@ func (tr *TweetResponder) ProcessTweetsInBatches(ctx context.Context, config Bat
 	return nil
 }
 
+// lockConversation locks the thread's conversation. release is nil when
+// another instance holds it; answered reports that the tweet was replied to
+// since it was recalled, e.g. by the instance that held the lock before.
+func (tr *TweetResponder) lockConversation(ctx context.Context, thread memory.ConversationThread, tweet memory.TweetNeedingReply) (lock.Release, bool, error) {
+	conversationID := thread.ConversationID
+	if conversationID == "" {
+		conversationID = tweet.TweetID
+	}
+
+	release, ok, err := tr.locker.TryLock(ctx, lock.Key("reply", tr.tweetStore.BotID(), conversationID))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lock conversation: %w", err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	answered, err := tr.tweetStore.RepliedTo(ctx, tweet.TweetID)
+	if err != nil {
+		release()
+		return nil, false, err
+	}
+	return release, answered, nil
+}
+
+// sleepContext waits for d, returning early with ctx's error on shutdown
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // isRateLimitError checks if the error is related to rate limiting
 func (tr *TweetResponder) isRateLimitError(err error) bool {
 	if err == nil {
@@ -336,3 +524,98 @@ func (tr *TweetResponder) isRateLimitError(err error) bool {
 
 	return false
 }
+
+// replyConfig builds the prompt settings for answering target, with the
+// earlier tweets of its conversation as context. It fails only when the
+// conversation's operator notes cannot be loaded.
+func (tr *TweetResponder) replyConfig(
+	ctx context.Context,
+	log *logrus.Entry,
+	target memory.TweetNeedingReply,
+	conversation []memory.TweetNeedingReply,
+) (thoughts.MentionReplyConfig, error) {
+	// Summaries only save prompt space, so a reply goes out without them
+	summary, err := tr.tweetStore.ConversationSummary(ctx, target.ConversationID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to load conversation summary")
+	}
+	authorSummary, err := tr.tweetStore.UserSummary(ctx, target.AuthorID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to load author summary")
+	}
+
+	// Build conversation context only from tweets before this one, within the
+	// context token budget. The summary stands in for the tweets it covers,
+	// except the last few, which are kept word for word.
+	var earlier []thoughts.ContextPost
+	summarized := 0
+	for _, tweet := range conversation {
+		if tweet.CreatedAt.Before(target.CreatedAt) {
+			earlier = append(earlier, thoughts.ContextPost{
+				AuthorUsername: tweet.AuthorUsername,
+				AuthorName:     tweet.AuthorName,
+				Text:           tweet.Text,
+			})
+			if summary != nil && !tweet.CreatedAt.After(summary.LastTweetAt) {
+				summarized++
+			}
+		}
+	}
+	if skip := min(summarized, len(earlier)-summaryRecentPosts); skip > 0 {
+		earlier = earlier[skip:]
+	}
+	conversationContext := tr.context.Build(earlier)
+
+	// Generate AI reply using the mention reply generator
+	config := thoughts.MentionReplyConfig{
+		TweetText:           target.Text,             // The tweet we're directly replying to
+		ConversationContext: conversationContext,     // Conversation history within the token budget
+		MaxLength:           280,                     // Twitter's character limit
+		Temperature:         0.7,                     // Adjust as needed
+		AuthorUsername:      target.AuthorUsername,   // Who we're replying to
+		AuthorName:          target.AuthorName,       // Their display name
+		Category:            target.Category,         // Type of interaction
+		Language:            target.Lang,             // Tweet language
+		Personality:         tr.personality.Sections, // Account persona, nil for the default
+		Sentiment:           target.Sentiment,        // Adapts the tone to hostility or praise
+
+		CategoryPersonalities: tr.personality.Categories,
+	}
+
+	if summary != nil {
+		config.ConversationSummary = summary.Summary
+	}
+	if authorSummary != nil {
+		config.AuthorSummary = authorSummary.Summary
+	}
+
+	// Examples only steer the voice, so a reply goes out without them
+	examples, err := tr.examples.Select(ctx, target.Text, target.Category)
+	if err != nil {
+		log.WithError(err).Warn("Failed to select reply examples")
+	}
+	config.Examples = examples
+	if config.Avoid, err = tr.examples.Avoid(ctx, target.Text); err != nil {
+		log.WithError(err).Warn("Failed to select failed replies to avoid")
+	}
+	// Operator notes must not be left out, so the reply waits for them
+	if config.Notes, err = tr.tweetStore.ActiveConversationNotes(ctx, target.ConversationID); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// routeReply attaches what the model router picks the reply's model by
+func routeReply(ctx context.Context, target memory.TweetNeedingReply, conversation []memory.TweetNeedingReply) context.Context {
+	length := 1
+	for _, tweet := range conversation {
+		if tweet.CreatedAt.Before(target.CreatedAt) {
+			length++
+		}
+	}
+	return llm.WithRoute(ctx, llm.Route{
+		Category:        target.Category,
+		ThreadLength:    length,
+		AuthorFollowers: target.AuthorFollowers,
+	})
+}
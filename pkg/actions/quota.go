@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// quotaFlushTimeout bounds the last save when the recorder stops
+const quotaFlushTimeout = 5 * time.Second
+
+// QuotaRecorderOptions configures the quota recorder
+type QuotaRecorderOptions struct {
+	Interval time.Duration
+}
+
+// QuotaRecorder periodically persists the client's API consumption per
+// endpoint and rate limit window, so it can be compared across windows and
+// survives restarts. The retention policy prunes old windows.
+type QuotaRecorder struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    QuotaRecorderOptions
+}
+
+// NewQuotaRecorder creates a new quota recorder
+func NewQuotaRecorder(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options QuotaRecorderOptions,
+) *QuotaRecorder {
+	return &QuotaRecorder{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (r *QuotaRecorder) Name() string {
+	return "quota_recorder"
+}
+
+// Execute implements the Action interface
+func (r *QuotaRecorder) Execute(ctx context.Context) error {
+	log := r.logger.WithContext(ctx).WithField("action", r.Name())
+
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting quota recorder")
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Keep what the last interval consumed
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), quotaFlushTimeout)
+			if err := r.Record(flushCtx); err != nil {
+				log.WithError(err).Warn("Failed to record API quota on shutdown")
+			}
+			cancel()
+			log.Info("Quota recorder stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := r.Record(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to record API quota")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Stop implements the Action interface
+func (r *QuotaRecorder) Stop() {
+	r.logger.WithField("action", r.Name()).Info("Stopping quota recorder")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (r *QuotaRecorder) SetInterval(interval time.Duration) {
+	r.options.Interval = interval
+}
+
+// Record saves the windows that ended since the last call and the current
+// windows so far
+func (r *QuotaRecorder) Record(ctx context.Context) error {
+	windows := r.client.TakeQuotaWindows()
+	if err := r.tweetStore.SaveQuotaWindows(ctx, windows); err != nil {
+		return err
+	}
+
+	r.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"action":  r.Name(),
+		"windows": len(windows),
+	}).Debug("Recorded API quota")
+	return nil
+}
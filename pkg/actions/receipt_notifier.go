@@ -0,0 +1,191 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/sirupsen/logrus"
+)
+
+// ReceiptNotifier follows up in the conversation a wallet transaction was
+// sent for, such as a tip, once the transaction confirms. It listens for the
+// wallet_transfer_completed events the wallet's transaction monitor publishes
+// and replies with the explorer link and the gas used.
+type ReceiptNotifier struct {
+	bus      *events.Bus
+	client   *twitter.TwitterClient
+	store    *memory.TweetStore
+	logger   *logrus.Logger
+	schedule *schedule.Policy
+}
+
+// NewReceiptNotifier creates a new receipt notifier
+func NewReceiptNotifier(bus *events.Bus, client *twitter.TwitterClient, store *memory.TweetStore, logger *logrus.Logger) *ReceiptNotifier {
+	return &ReceiptNotifier{
+		bus:    bus,
+		client: client,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// WithSchedule holds receipt replies during quiet hours and embargoes until
+// posting opens again. Receipts confirmed meanwhile wait in the event
+// subscription and are answered in order.
+func (n *ReceiptNotifier) WithSchedule(policy *schedule.Policy) *ReceiptNotifier {
+	n.schedule = policy
+	return n
+}
+
+// Name implements the Action interface
+func (n *ReceiptNotifier) Name() string {
+	return "receipt_notifier"
+}
+
+// Execute implements the Action interface
+func (n *ReceiptNotifier) Execute(ctx context.Context) error {
+	log := n.logger.WithContext(ctx).WithField("action", n.Name())
+	log.Info("Starting transaction receipt notifications")
+
+	unsubscribe := n.bus.Subscribe(func(event events.Event) {
+		n.handleReceipt(logging.StartTrace(ctx), event)
+	}, events.WalletTransferCompleted)
+	defer unsubscribe()
+
+	<-ctx.Done()
+	log.Info("Transaction receipt notifications stopped")
+	return ctx.Err()
+}
+
+// Stop implements the Action interface
+func (n *ReceiptNotifier) Stop() {
+	n.logger.WithField("action", n.Name()).Info("Stopping transaction receipt notifications")
+}
+
+// handleReceipt replies to the command of the tip a confirmed transaction
+// paid. Transactions sent for anything else are ignored.
+func (n *ReceiptNotifier) handleReceipt(ctx context.Context, event events.Event) {
+	if ctx.Err() != nil {
+		return
+	}
+	receipt := receiptFromEvent(event)
+	log := n.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"action":    n.Name(),
+		"network":   receipt.network,
+		"sent_hash": receipt.sentHash,
+		"hash":      receipt.hash,
+	})
+
+	tip, err := n.store.TipForTxHash(ctx, receipt.sentHash)
+	if err != nil {
+		log.WithError(err).Error("Failed to look up tip of confirmed transaction")
+		return
+	}
+	if tip == nil {
+		log.Debug("Confirmed transaction was not sent for a conversation")
+		return
+	}
+	log = log.WithFields(logrus.Fields{"tip_id": tip.ID, "tweet_id": tip.TweetID})
+
+	status, reason := memory.TipConfirmed, ""
+	if !receipt.success {
+		status, reason = memory.TipFailed, "transaction reverted"
+	}
+	// Only one instance, and only once, follows up on a receipt
+	moved, err := n.store.TransitionTip(ctx, tip.ID, memory.TipSent, status, reason, "")
+	if err != nil {
+		log.WithError(err).Error("Failed to record transaction receipt")
+		return
+	}
+	if !moved {
+		log.Debug("Receipt already handled")
+		return
+	}
+	tip.Status, tip.Reason = status, reason
+
+	text := receiptReply(tip, receipt)
+	if now := time.Now(); !n.schedule.Open(now) {
+		log.WithField("until", n.schedule.NextOpen(now)).Info("Holding receipt reply until posting opens")
+		if err := n.schedule.Wait(ctx); err != nil {
+			log.Warn("Receipt reply dropped, stopped while holding it")
+			return
+		}
+	}
+	posted, err := n.client.PostReplyThread(ctx, twitter.PostReplyThreadParams{
+		Text:           text,
+		ReplyToID:      tip.TweetID,
+		ConversationID: tip.ConversationID,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to post receipt reply")
+		return
+	}
+
+	// The reply is public now, so record it even if ctx is cancelled
+	if err := n.store.SaveAgentReply(context.WithoutCancel(ctx), tip.TweetID, posted.ID, tip.ConversationID, text); err != nil {
+		log.WithError(err).Error("Failed to save receipt reply")
+	}
+	log.WithFields(logrus.Fields{
+		"reply_id": posted.ID,
+		"success":  receipt.success,
+		"gas_used": receipt.gasUsed,
+	}).Info("Replied with transaction receipt")
+}
+
+// transactionReceipt is the part of a wallet_transfer_completed event a
+// follow-up reply needs
+type transactionReceipt struct {
+	network     string
+	hash        string
+	sentHash    string
+	success     bool
+	blockNumber string
+	gasUsed     string
+	explorerURL string
+}
+
+// receiptFromEvent reads a wallet_transfer_completed event. sentHash falls
+// back to the mined hash for events without one.
+func receiptFromEvent(event events.Event) transactionReceipt {
+	field := func(key string) string {
+		if value, ok := event.Data[key]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+	receipt := transactionReceipt{
+		network:     field("network"),
+		hash:        field("hash"),
+		sentHash:    field("sent_hash"),
+		blockNumber: field("block_number"),
+		gasUsed:     field("gas_used"),
+		explorerURL: field("explorer_url"),
+	}
+	receipt.success, _ = event.Data["success"].(bool)
+	if receipt.sentHash == "" {
+		receipt.sentHash = receipt.hash
+	}
+	return receipt
+}
+
+// receiptReply is the follow-up telling the conversation how the tip's
+// transaction ended
+func receiptReply(tip *memory.Tip, receipt transactionReceipt) string {
+	link := receipt.explorerURL
+	if link == "" {
+		link = "Tx: " + receipt.hash
+	}
+	amount := fmt.Sprintf("%s %s", formatUnits(tip.AmountUnits), tip.Symbol)
+	if !receipt.success {
+		return fmt.Sprintf("The tip of %s to @%s reverted on chain after using %s gas. The royal treasurer has been informed. %s",
+			amount, tip.RecipientUsername, receipt.gasUsed, link)
+	}
+	return fmt.Sprintf("Confirmed: %s reached @%s in block %s, %s gas used. 🐾 %s",
+		amount, tip.RecipientUsername, receipt.blockNumber, receipt.gasUsed, link)
+}
@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRecoveryTweets caps the bot's own tweets a recovery pass checks
+const DefaultRecoveryTweets = 200
+
+// RecoveryOptions configures the recovery pass reply workers run on startup
+type RecoveryOptions struct {
+	// Lookback is how far back the bot's own tweets are compared with the
+	// store; 0 skips the recovery
+	Lookback time.Duration
+	// MaxTweets caps the tweets fetched; defaults to DefaultRecoveryTweets
+	MaxTweets int
+}
+
+// RecoveryReport counts what a recovery pass repaired
+type RecoveryReport struct {
+	// Checked counts the bot's own tweets fetched from Twitter
+	Checked int
+	// Recorded counts replies found on Twitter but missing from the store
+	Recorded int
+	// Released counts queued tweets an earlier process claimed but never
+	// answered
+	Released int64
+}
+
+// Recover reconciles the store with what the bot actually posted before the
+// last shutdown or crash. Replies on the bot's timeline that the store does
+// not know are recorded, which marks their tweets as replied to and takes
+// them off the queue. Tweets claimed by an earlier process on this host are
+// handed back to the queue, and the backfill that follows queues the
+// remaining tweets still needing a reply.
+func (p *ReplyWorkerPool) Recover(ctx context.Context) (RecoveryReport, error) {
+	var report RecoveryReport
+	store := p.responder.tweetStore
+
+	if p.options.Recovery.Lookback > 0 {
+		if err := p.recordLostReplies(ctx, &report); err != nil {
+			return report, err
+		}
+	}
+
+	// Claims of answered tweets are gone by now, so only unanswered ones are
+	// handed back
+	released, err := store.ReleaseClaims(ctx, strings.TrimSuffix(p.host, strconv.Itoa(os.Getpid())), p.host)
+	if err != nil {
+		return report, err
+	}
+	report.Released = released
+	return report, nil
+}
+
+// recordLostReplies records the replies on the bot's timeline that the store
+// does not know
+func (p *ReplyWorkerPool) recordLostReplies(ctx context.Context, report *RecoveryReport) error {
+	store := p.responder.tweetStore
+	maxTweets := p.options.Recovery.MaxTweets
+	if maxTweets <= 0 {
+		maxTweets = DefaultRecoveryTweets
+	}
+
+	posted, err := p.responder.client.GetUserTimeline(ctx, store.BotID(), twitter.GetUserTimelineParams{
+		MaxTweets: maxTweets,
+		StartTime: time.Now().Add(-p.options.Recovery.Lookback).UTC().Format(time.RFC3339),
+		Exclude:   []string{twitter.ExcludeRetweets},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch the bot's recent tweets: %w", err)
+	}
+	report.Checked = len(posted)
+
+	for _, reply := range posted {
+		originalID := repliedToID(reply)
+		if originalID == "" {
+			continue
+		}
+		if stored, err := store.HasTweet(ctx, reply.ID); err != nil {
+			return err
+		} else if stored {
+			continue
+		}
+		// Only tweets the bot set out to answer are repaired
+		if stored, err := store.HasTweet(ctx, originalID); err != nil {
+			return err
+		} else if !stored {
+			continue
+		}
+
+		if err := store.SaveRecoveredReply(ctx, originalID, reply); err != nil {
+			return err
+		}
+		report.Recorded++
+		p.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"tweet_id": originalID,
+			"reply_id": reply.ID,
+		}).Warn("Recorded a reply posted before the agent stopped")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// Redo replaces a posted reply of the bot: it generates a new reply to the
+// same tweet, with the operator's guidance added to the prompt, deletes the
+// old reply and posts the new one. The old reply is kept as a negative
+// example. If posting fails after the deletion, the tweet is queued to be
+// answered again.
+func (tr *TweetResponder) Redo(ctx context.Context, replyID, guidance string) (*twitter.Tweet, error) {
+	log := tr.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"method":   "Redo",
+		"reply_id": replyID,
+	})
+
+	original, err := tr.tweetStore.RepliedTweet(ctx, replyID)
+	if err != nil {
+		return nil, err
+	}
+	old, err := tr.tweetStore.GetTweet(ctx, replyID)
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := tr.tweetStore.ConversationTweets(ctx, original.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate first, so a failure leaves the old reply in place
+	config, err := tr.replyConfig(ctx, log, *original, conversation)
+	if err != nil {
+		return nil, err
+	}
+	config.Instructions = strings.TrimSpace(guidance)
+	replyText, err := tr.replyGenerator.GenerateReply(routeReply(ctx, *original, conversation), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate reply: %w", err)
+	}
+
+	deleted, err := tr.client.DeleteTweet(ctx, replyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete reply %s: %w", replyID, err)
+	}
+	if !deleted {
+		return nil, fmt.Errorf("failed to delete reply %s: not deleted", replyID)
+	}
+
+	// The old reply is gone now, so record the outcome even if ctx is cancelled
+	saveCtx := context.WithoutCancel(ctx)
+
+	if tr.examples != nil {
+		if _, err := tr.examples.AddNegative(saveCtx, original.Text, old.Text, replyID, memory.ReasonDeleted); err != nil {
+			log.WithError(err).Warn("Failed to record replaced reply as negative example")
+		}
+	}
+
+	posted, err := tr.client.PostReplyThread(ctx, twitter.PostReplyThreadParams{
+		Text:           replyText,
+		ReplyToID:      original.TweetID,
+		ConversationID: original.ConversationID,
+	})
+	if err != nil {
+		resetErr := tr.tweetStore.ReplaceAgentReply(saveCtx, original.TweetID, replyID, "", original.ConversationID, "")
+		if resetErr == nil {
+			resetErr = tr.tweetStore.EnqueueReplies(saveCtx, []memory.ReplyWorkItem{{
+				TweetID:        original.TweetID,
+				ConversationID: original.ConversationID,
+			}})
+		}
+		if resetErr != nil {
+			log.WithError(resetErr).Error("Failed to queue tweet for a new reply")
+		}
+		return nil, fmt.Errorf("failed to post replacement reply: %w", err)
+	}
+
+	if err := tr.tweetStore.ReplaceAgentReply(saveCtx, original.TweetID, replyID, posted.ID, original.ConversationID, replyText); err != nil {
+		return posted, err
+	}
+
+	tr.events.Emit(events.ReplyPosted, tr.tweetStore.BotID(), map[string]interface{}{
+		"reply_tweet_id":  posted.ID,
+		"reply_to_id":     original.TweetID,
+		"conversation_id": original.ConversationID,
+		"text":            replyText,
+		"replaces":        replyID,
+	})
+
+	log.WithFields(logrus.Fields{
+		"reply_tweet_id": posted.ID,
+		"reply_to_tweet": original.TweetID,
+		"guided":         config.Instructions != "",
+	}).Info("Replaced reply")
+	return posted, nil
+}
+
+// EditedAfterReply lists the tweets that were edited after the bot replied to
+// them, whose replies may be worth redoing
+func (tr *TweetResponder) EditedAfterReply(ctx context.Context) ([]memory.EditedTweet, error) {
+	return tr.tweetStore.EditedAfterReply(ctx)
+}
@@ -0,0 +1,114 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// MetricsRefreshOptions configures the metrics refresher
+type MetricsRefreshOptions struct {
+	Interval time.Duration
+	// Lookback is how old the bot's tweets may be and still get their metrics refreshed
+	Lookback time.Duration
+}
+
+// MetricsRefresher periodically re-fetches the bot's recent tweets and stores
+// their current public metrics, so reports and prompt tuning can tell which
+// replies performed well
+type MetricsRefresher struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    MetricsRefreshOptions
+}
+
+// NewMetricsRefresher creates a new metrics refresher
+func NewMetricsRefresher(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options MetricsRefreshOptions,
+) *MetricsRefresher {
+	if options.Lookback <= 0 {
+		options.Lookback = 72 * time.Hour
+	}
+	return &MetricsRefresher{
+		client:     client,
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (r *MetricsRefresher) Name() string {
+	return "metrics_refresher"
+}
+
+// Execute implements the Action interface
+func (r *MetricsRefresher) Execute(ctx context.Context) error {
+	log := r.logger.WithContext(ctx).WithField("action", r.Name())
+
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting metrics refresher")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Metrics refresher stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := r.Refresh(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to refresh tweet metrics")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Refresh re-fetches the public metrics of the bot's tweets within the lookback window
+func (r *MetricsRefresher) Refresh(ctx context.Context) error {
+	ids, err := r.tweetStore.OwnTweetIDs(ctx, time.Now().Add(-r.options.Lookback))
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tweets, err := r.client.LookupTweets(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch own tweets: %w", err)
+	}
+	if err := r.tweetStore.UpdatePublicMetrics(ctx, tweets); err != nil {
+		return err
+	}
+
+	r.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"requested": len(ids),
+		"refreshed": len(tweets),
+		"lookback":  r.options.Lookback,
+	}).Info("Refreshed own tweet metrics")
+	return nil
+}
+
+// Stop implements the Action interface
+func (r *MetricsRefresher) Stop() {
+	log := r.logger.WithField("action", r.Name())
+	log.Info("Stopping metrics refresher")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (r *MetricsRefresher) SetInterval(interval time.Duration) {
+	r.options.Interval = interval
+}
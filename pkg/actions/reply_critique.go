@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+)
+
+// WithCritic has every generated reply critiqued and revised once before it
+// is posted. The draft, critique and revision are logged and stored with the
+// posted reply.
+func (tr *TweetResponder) WithCritic(critic thoughts.ReplyCritic) *TweetResponder {
+	tr.critic = critic
+	return tr
+}
+
+// review critiques the draft, returning nil without a critic. A review that
+// fails is logged and the draft is posted as it is.
+func (tr *TweetResponder) review(ctx context.Context, log *logrus.Entry, config thoughts.MentionReplyConfig, draft string) *thoughts.ReplyReview {
+	if tr.critic == nil {
+		return nil
+	}
+
+	review, err := tr.critic.Review(ctx, config, draft)
+	if err != nil {
+		log.WithError(err).WithField("draft", draft).Warn("Failed to critique reply, posting the draft")
+		return nil
+	}
+	log.WithFields(logrus.Fields{
+		"draft":    review.Draft,
+		"critique": review.Critique,
+		"revision": review.Revision,
+	}).Info("Critiqued reply draft")
+	return &review
+}
+
+// saveReview stores the stages behind a posted reply
+func (tr *TweetResponder) saveReview(ctx context.Context, log *logrus.Entry, tweetID, replyID string, review thoughts.ReplyReview) {
+	err := tr.tweetStore.SaveReplyStages(ctx, &memory.ReplyStages{
+		ReplyID:  replyID,
+		TweetID:  tweetID,
+		Draft:    review.Draft,
+		Critique: review.Critique,
+		Revision: review.Revision,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to save reply stages")
+	}
+}
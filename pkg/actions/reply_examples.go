@@ -0,0 +1,206 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+)
+
+// ReplyExamples manages an account's curated and negative reply examples
+// and picks the ones closest to a tweet for the reply prompt. Examples are
+// compared by the embedding of their tweet; without an embedder, or for
+// examples added without one, by trigram similarity.
+type ReplyExamples struct {
+	store    *memory.TweetStore
+	embedder llm.Embedder
+	logger   *logrus.Logger
+	limit    int
+}
+
+// NewReplyExamples creates the example library of the account store
+// belongs to. limit is how many examples Select and Avoid return; embedder may be nil.
+func NewReplyExamples(store *memory.TweetStore, embedder llm.Embedder, logger *logrus.Logger, limit int) *ReplyExamples {
+	return &ReplyExamples{
+		store:    store,
+		embedder: embedder,
+		logger:   logger,
+		limit:    limit,
+	}
+}
+
+// Add stores a curated tweet and its ideal reply, with the embedding of the tweet
+func (e *ReplyExamples) Add(ctx context.Context, tweet, reply, category string) (*memory.ReplyExample, error) {
+	tweet, reply = strings.TrimSpace(tweet), strings.TrimSpace(reply)
+	if tweet == "" || reply == "" {
+		return nil, fmt.Errorf("tweet and reply are required")
+	}
+
+	example := &memory.ReplyExample{
+		TweetText: tweet,
+		ReplyText: reply,
+		Category:  category,
+	}
+	if e.embedder != nil {
+		embedding, err := e.embed(ctx, tweet)
+		if err != nil {
+			return nil, err
+		}
+		example.Embedding = embedding
+	}
+
+	if err := e.store.SaveReplyExample(ctx, example); err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
+// List returns every curated example of the account
+func (e *ReplyExamples) List(ctx context.Context) ([]memory.ReplyExample, error) {
+	return e.store.ReplyExamples(ctx)
+}
+
+// Delete removes a curated example, reporting whether it existed
+func (e *ReplyExamples) Delete(ctx context.Context, id int64) (bool, error) {
+	return e.store.DeleteReplyExample(ctx, id)
+}
+
+// AddNegative records a failed reply of the bot as a negative example. tweet
+// is the tweet it answered and sourceID the reply itself.
+func (e *ReplyExamples) AddNegative(ctx context.Context, tweet, reply, sourceID, reason string) (*memory.ReplyExample, error) {
+	example := &memory.ReplyExample{
+		TweetText:     tweet,
+		ReplyText:     reply,
+		Negative:      true,
+		Reason:        reason,
+		SourceTweetID: sourceID,
+	}
+	if e.embedder != nil && strings.TrimSpace(tweet) != "" {
+		embedding, err := e.embed(ctx, tweet)
+		if err != nil {
+			e.logger.WithContext(ctx).WithError(err).Warn("Failed to embed tweet, storing negative example without embedding")
+		}
+		example.Embedding = embedding
+	}
+
+	if err := e.store.SaveReplyExample(ctx, example); err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
+// Select returns up to limit curated examples most similar to tweet,
+// skipping examples meant for another category. Failing to embed the tweet
+// falls back to trigram similarity rather than leaving the examples out.
+func (e *ReplyExamples) Select(ctx context.Context, tweet, category string) ([]thoughts.ReplyExample, error) {
+	closest, err := e.closest(ctx, tweet, func(example memory.ReplyExample) bool {
+		return !example.Negative && (example.Category == "" || example.Category == category)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]thoughts.ReplyExample, len(closest))
+	for i, example := range closest {
+		selected[i] = thoughts.ReplyExample{
+			Tweet: example.TweetText,
+			Reply: example.ReplyText,
+		}
+	}
+	return selected, nil
+}
+
+// Avoid returns the failed replies of up to limit negative examples whose
+// tweets are most similar to tweet
+func (e *ReplyExamples) Avoid(ctx context.Context, tweet string) ([]string, error) {
+	closest, err := e.closest(ctx, tweet, func(example memory.ReplyExample) bool {
+		return example.Negative
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replies := make([]string, len(closest))
+	for i, example := range closest {
+		replies[i] = example.ReplyText
+	}
+	return replies, nil
+}
+
+// closest returns up to limit of the examples matching keep, most similar to tweet first
+func (e *ReplyExamples) closest(ctx context.Context, tweet string, keep func(memory.ReplyExample) bool) ([]memory.ReplyExample, error) {
+	if e == nil || e.limit <= 0 {
+		return nil, nil
+	}
+
+	examples, err := e.store.ReplyExamples(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidates := examples[:0]
+	for _, example := range examples {
+		if keep(example) {
+			candidates = append(candidates, example)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var embedding []float32
+	if e.embedder != nil {
+		if embedding, err = e.embed(ctx, tweet); err != nil {
+			e.logger.WithContext(ctx).WithError(err).Warn("Failed to embed tweet, picking reply examples by text similarity")
+		}
+	}
+
+	scores := make(map[int64]float64, len(candidates))
+	for _, example := range candidates {
+		score := thoughts.Similarity(tweet, example.TweetText)
+		if embedding != nil && len(example.Embedding) == len(embedding) {
+			score = cosineSimilarity(embedding, example.Embedding)
+		}
+		scores[example.ID] = score
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i].ID] > scores[candidates[j].ID]
+	})
+	if len(candidates) > e.limit {
+		candidates = candidates[:e.limit]
+	}
+	return candidates, nil
+}
+
+// embed returns the embedding of one text
+func (e *ReplyExamples) embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.embedder.CreateEmbedding(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed text: %w", err)
+	}
+	if len(embeddings) == 0 || len(embeddings[0]) == 0 {
+		return nil, fmt.Errorf("failed to embed text: empty embedding")
+	}
+	return embeddings[0], nil
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors of
+// the same length, or 0 when either is all zeros
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// WithLatencySLO publishes a reply_latency_exceeded event for every reply
+// posted later than slo after its mention; 0 disables the events. Latencies
+// are recorded either way.
+func (tr *TweetResponder) WithLatencySLO(slo time.Duration) *TweetResponder {
+	tr.latencySLO = slo
+	return tr
+}
+
+// recordLatency stores how long the mention waited for its reply and reports
+// replies slower than the SLO
+func (tr *TweetResponder) recordLatency(ctx context.Context, log *logrus.Entry, target memory.TweetNeedingReply, replyID, conversationID string, repliedAt time.Time) {
+	if target.CreatedAt.IsZero() {
+		return
+	}
+
+	latency := &memory.ReplyLatency{
+		ReplyID:        replyID,
+		TweetID:        target.TweetID,
+		ConversationID: conversationID,
+		MentionedAt:    target.CreatedAt,
+		RepliedAt:      repliedAt,
+	}
+	if err := tr.tweetStore.RecordReplyLatency(ctx, latency); err != nil {
+		log.WithError(err).Error("Failed to record reply latency")
+	}
+
+	if tr.latencySLO <= 0 || latency.Latency() <= tr.latencySLO {
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"latency": latency.Latency().Round(time.Second),
+		"slo":     tr.latencySLO,
+	}).Warn("Reply latency exceeded the SLO")
+	tr.events.Emit(events.ReplyLatencyExceeded, tr.tweetStore.BotID(), map[string]interface{}{
+		"reply_tweet_id":  replyID,
+		"reply_to_id":     target.TweetID,
+		"conversation_id": conversationID,
+		"latency_ms":      latency.LatencyMillis,
+		"slo_ms":          tr.latencySLO.Milliseconds(),
+	})
+}
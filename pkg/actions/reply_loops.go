@@ -0,0 +1,151 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLoopSimilarity is the word overlap from which two tweets of a user
+// count as near-identical
+const DefaultLoopSimilarity = 0.8
+
+// LoopOptions closes conversations that go in circles. Zero disables a check.
+type LoopOptions struct {
+	// MaxBotReplies closes a conversation once the bot replied this many
+	// times in it
+	MaxBotReplies int
+	// Repeats closes a conversation once the user being answered has sent
+	// this many near-identical tweets in it
+	Repeats int
+	// Similarity is the word overlap, between 0 and 1, from which two tweets
+	// count as near-identical; defaults to DefaultLoopSimilarity
+	Similarity float64
+	// SignOff is the last reply posted to a closed conversation; empty
+	// closes it without a word
+	SignOff string
+}
+
+// enabled reports whether any check is set
+func (o LoopOptions) enabled() bool {
+	return o.MaxBotReplies > 0 || o.Repeats > 0
+}
+
+// WithLoopDetection closes conversations in which the bot keeps replying or
+// a user keeps sending the same tweet. A closed conversation gets the
+// sign-off reply and is muted, so operators can reopen it by unmuting it.
+func (tr *TweetResponder) WithLoopDetection(options LoopOptions) *TweetResponder {
+	if options.Similarity <= 0 || options.Similarity > 1 {
+		options.Similarity = DefaultLoopSimilarity
+	}
+	tr.loops = options
+	return tr
+}
+
+// detectLoop reports why the conversation of target goes in circles, or an
+// empty string when it does not
+func (tr *TweetResponder) detectLoop(ctx context.Context, target memory.TweetNeedingReply, conversationID, botID string) (string, error) {
+	if !tr.loops.enabled() {
+		return "", nil
+	}
+
+	conversation, err := tr.tweetStore.ConversationTweets(ctx, conversationID)
+	if err != nil {
+		return "", err
+	}
+
+	var botReplies, repeats int
+	targetWords := wordSet(target.Text)
+	for _, tweet := range conversation {
+		switch {
+		case tweet.AuthorID == botID:
+			botReplies++
+		case tweet.AuthorID == target.AuthorID && tweet.TweetID != target.TweetID:
+			if wordOverlap(targetWords, wordSet(tweet.Text)) >= tr.loops.Similarity {
+				repeats++
+			}
+		}
+	}
+
+	switch {
+	case tr.loops.MaxBotReplies > 0 && botReplies >= tr.loops.MaxBotReplies:
+		return fmt.Sprintf("bot replied %d times", botReplies), nil
+	case tr.loops.Repeats > 0 && repeats+1 >= tr.loops.Repeats:
+		return fmt.Sprintf("user sent %d near-identical tweets", repeats+1), nil
+	}
+	return "", nil
+}
+
+// closeConversation posts the sign-off reply to target, if any, and mutes the
+// conversation
+func (tr *TweetResponder) closeConversation(ctx context.Context, log *logrus.Entry, target memory.TweetNeedingReply, conversationID, reason string) (replyOutcome, error) {
+	log = log.WithField("reason", reason)
+
+	outcome := replySkipped
+	if tr.loops.SignOff != "" {
+		posted, err := tr.client.PostReplyThread(ctx, twitter.PostReplyThreadParams{
+			Text:           tr.loops.SignOff,
+			ReplyToID:      target.TweetID,
+			ConversationID: conversationID,
+		})
+		if err != nil {
+			return replySkipped, fmt.Errorf("failed to post sign-off reply: %w", err)
+		}
+		outcome = replyPosted
+
+		// The sign-off is public now, so record it even if ctx is cancelled
+		ctx = context.WithoutCancel(ctx)
+		if err := tr.tweetStore.SaveAgentReply(ctx, target.TweetID, posted.ID, conversationID, tr.loops.SignOff); err != nil {
+			log.WithError(err).Error("Failed to save sign-off reply")
+		}
+		if err := tr.tweetStore.UpdateTweetAfterReply(ctx, target.TweetID, posted.ID); err != nil {
+			log.WithError(err).Error("Failed to update tweet status after sign-off")
+		}
+	}
+
+	if err := tr.tweetStore.MuteConversation(ctx, conversationID, "loop detected: "+reason); err != nil {
+		return outcome, err
+	}
+
+	tr.events.Emit(events.ConversationClosed, tr.tweetStore.BotID(), map[string]interface{}{
+		"conversation_id": conversationID,
+		"tweet_id":        target.TweetID,
+		"author_id":       target.AuthorID,
+		"reason":          reason,
+	})
+	log.Info("Closed conversation going in circles")
+	return outcome, nil
+}
+
+// wordSet returns the distinct lowercased words of text, without handles
+// and links
+func wordSet(text string) map[string]struct{} {
+	words := make(map[string]struct{})
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if strings.HasPrefix(word, "@") || strings.HasPrefix(word, "http") {
+			continue
+		}
+		words[strings.Trim(word, ".,!?;:\"'")] = struct{}{}
+	}
+	delete(words, "")
+	return words
+}
+
+// wordOverlap is the Jaccard similarity of two word sets
+func wordOverlap(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	var shared int
+	for word := range a {
+		if _, ok := b[word]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
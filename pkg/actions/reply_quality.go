@@ -0,0 +1,173 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// ReplyQualityOptions configures the reply quality tracker
+type ReplyQualityOptions struct {
+	Interval time.Duration
+	// MinAge is how long a reply has to collect engagement before getting
+	// none makes it a negative example
+	MinAge time.Duration
+	// Lookback is how old the bot's replies may be and still get reviewed
+	Lookback time.Duration
+}
+
+// ReplyQualityTracker watches the bot's replies and records the ones that were
+// deleted by an operator, or got no engagement at all after MinAge, as
+// negative examples, so later replies steer away from similar phrasing
+type ReplyQualityTracker struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	examples   *ReplyExamples
+	logger     *logrus.Logger
+	options    ReplyQualityOptions
+}
+
+// NewReplyQualityTracker creates a new reply quality tracker
+func NewReplyQualityTracker(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	examples *ReplyExamples,
+	logger *logrus.Logger,
+	options ReplyQualityOptions,
+) *ReplyQualityTracker {
+	if options.MinAge <= 0 {
+		options.MinAge = 24 * time.Hour
+	}
+	if options.Lookback < options.MinAge {
+		options.Lookback = 2 * options.MinAge
+	}
+	return &ReplyQualityTracker{
+		client:     client,
+		tweetStore: store,
+		examples:   examples,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (q *ReplyQualityTracker) Name() string {
+	return "reply_quality_tracker"
+}
+
+// Execute implements the Action interface
+func (q *ReplyQualityTracker) Execute(ctx context.Context) error {
+	log := q.logger.WithContext(ctx).WithField("action", q.Name())
+
+	ticker := time.NewTicker(q.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting reply quality tracker")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Reply quality tracker stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if err := q.Review(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to review reply quality")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Review looks up the bot's unreviewed replies within the lookback window and
+// records the deleted and ignored ones as negative examples
+func (q *ReplyQualityTracker) Review(ctx context.Context) error {
+	now := time.Now()
+	replies, err := q.tweetStore.UnreviewedReplies(ctx, now.Add(-q.options.Lookback))
+	if err != nil {
+		return err
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(replies))
+	for i, reply := range replies {
+		ids[i] = reply.TweetID
+	}
+	tweets, err := q.client.LookupTweets(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch own replies: %w", err)
+	}
+	if len(tweets) == 0 {
+		// Every reply missing points at the lookup rather than at deletions
+		q.logger.WithContext(ctx).WithField("requested", len(ids)).Warn("No replies found, skipping reply quality review")
+		return nil
+	}
+	if err := q.tweetStore.UpdatePublicMetrics(ctx, tweets); err != nil {
+		return err
+	}
+
+	found := make(map[string]twitter.Tweet, len(tweets))
+	for _, tweet := range tweets {
+		found[tweet.ID] = tweet
+	}
+
+	var deleted, ignored int
+	for _, reply := range replies {
+		var reason string
+		tweet, ok := found[reply.TweetID]
+		switch {
+		case !ok:
+			// Lookups leave out tweets that no longer exist
+			reason = memory.ReasonDeleted
+		case now.Sub(reply.CreatedAt) >= q.options.MinAge && tweetEngagement(tweet) == 0:
+			reason = memory.ReasonNoEngagement
+		default:
+			continue
+		}
+
+		if _, err := q.examples.AddNegative(ctx, reply.ParentText, reply.Text, reply.TweetID, reason); err != nil {
+			return fmt.Errorf("failed to record negative example for reply %s: %w", reply.TweetID, err)
+		}
+		if reason == memory.ReasonDeleted {
+			deleted++
+		} else {
+			ignored++
+		}
+	}
+
+	q.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"reviewed": len(replies),
+		"deleted":  deleted,
+		"ignored":  ignored,
+	}).Info("Reviewed reply quality")
+	return nil
+}
+
+// tweetEngagement weighs a tweet's public metrics like memory.ReplyPerformance.Engagement
+func tweetEngagement(tweet twitter.Tweet) int {
+	return memory.ReplyPerformance{
+		Likes:    tweet.PublicMetrics.LikeCount,
+		Retweets: tweet.PublicMetrics.RetweetCount,
+		Replies:  tweet.PublicMetrics.ReplyCount,
+		Quotes:   tweet.PublicMetrics.QuoteCount,
+	}.Engagement()
+}
+
+// Stop implements the Action interface
+func (q *ReplyQualityTracker) Stop() {
+	log := q.logger.WithField("action", q.Name())
+	log.Info("Stopping reply quality tracker")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (q *ReplyQualityTracker) SetInterval(interval time.Duration) {
+	q.options.Interval = interval
+}
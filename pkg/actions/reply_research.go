@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+)
+
+// Researcher answers a request with the tool-calling loop, letting the model
+// call tools such as wallet lookups before it answers
+type Researcher interface {
+	Reason(ctx context.Context, input string) (string, error)
+}
+
+// WithResearcher has the tool-calling loop look up the facts each mention
+// needs before its reply is generated, so replies quote real figures such as
+// treasury balances
+func (tr *TweetResponder) WithResearcher(researcher Researcher) *TweetResponder {
+	tr.researcher = researcher
+	return tr
+}
+
+// research returns the facts the reply to config's tweet needs, or an empty
+// string without a researcher. A failed lookup is logged and the reply is
+// generated without facts.
+func (tr *TweetResponder) research(ctx context.Context, log *logrus.Entry, config thoughts.MentionReplyConfig) string {
+	if tr.researcher == nil {
+		return ""
+	}
+
+	request, err := thoughts.ResearchRequest(config)
+	if err != nil {
+		log.WithError(err).Warn("Failed to prepare reply research")
+		return ""
+	}
+	answer, err := tr.researcher.Reason(ctx, request)
+	if err != nil {
+		log.WithError(err).Warn("Failed to research reply, replying without facts")
+		return ""
+	}
+
+	facts := thoughts.ParseFacts(answer)
+	if facts != "" {
+		log.WithField("facts", facts).Info("Looked up facts for reply")
+	}
+	return facts
+}
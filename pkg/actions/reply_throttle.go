@@ -0,0 +1,56 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// ThrottleOptions limits how much the bot engages with one user or thread, so
+// it cannot be baited into endless back-and-forths. Zero values disable a limit.
+type ThrottleOptions struct {
+	// MaxRepliesPerUser caps the replies to one user per UTC day
+	MaxRepliesPerUser int
+	// MaxConversationDepth caps the bot's replies in one conversation
+	MaxConversationDepth int
+	// ThreadCooldown is the minimum time between two replies in the same conversation
+	ThreadCooldown time.Duration
+}
+
+// enabled reports whether any limit is set
+func (o ThrottleOptions) enabled() bool {
+	return o.MaxRepliesPerUser > 0 || o.MaxConversationDepth > 0 || o.ThreadCooldown > 0
+}
+
+// WithThrottle enforces per-user and per-conversation reply limits, counted in
+// the database so they hold across restarts
+func (tr *TweetResponder) WithThrottle(options ThrottleOptions) *TweetResponder {
+	tr.throttle = options
+	return tr
+}
+
+// throttled reports why a reply to the tweet is not allowed yet, or an empty
+// string when it is
+func (tr *TweetResponder) throttled(ctx context.Context, tweet memory.TweetNeedingReply, conversationID string) (string, error) {
+	if !tr.throttle.enabled() {
+		return "", nil
+	}
+
+	now := time.Now()
+	counts, err := tr.tweetStore.ReplyCounts(ctx, tweet.AuthorID, conversationID, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to load reply counts: %w", err)
+	}
+
+	switch {
+	case tr.throttle.MaxRepliesPerUser > 0 && counts.UserToday >= tr.throttle.MaxRepliesPerUser:
+		return fmt.Sprintf("already replied %d times to this user today", counts.UserToday), nil
+	case tr.throttle.MaxConversationDepth > 0 && counts.Conversation >= tr.throttle.MaxConversationDepth:
+		return fmt.Sprintf("already replied %d times in this conversation", counts.Conversation), nil
+	case tr.throttle.ThreadCooldown > 0 && now.Sub(counts.LastConversationReply) < tr.throttle.ThreadCooldown:
+		return fmt.Sprintf("replied in this conversation %s ago", now.Sub(counts.LastConversationReply).Round(time.Second)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,291 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitPause is how long a worker rests after Twitter rejects a reply for
+// rate limiting
+const rateLimitPause = 5 * time.Minute
+
+// ReplyWorkerOptions configures the reply worker pool
+type ReplyWorkerOptions struct {
+	// Workers is the number of concurrent reply workers
+	Workers int
+	// TweetsPerWindow is the account's reply budget per 15 minutes, split
+	// evenly between the workers
+	TweetsPerWindow int
+	// PollInterval is how long an idle worker waits before checking the queue again
+	PollInterval time.Duration
+	// VisibilityTimeout hides a claimed tweet from other workers; if its worker
+	// dies, the tweet is picked up again once it expires
+	VisibilityTimeout time.Duration
+	// MaxAttempts drops a tweet from the queue after this many failed replies
+	MaxAttempts int
+	// RetryDelay is how long a failed or deferred tweet waits before it is retried
+	RetryDelay time.Duration
+	// Schedule holds replies during quiet hours and embargoes; queued tweets
+	// are answered once posting opens again. Nil replies at any time.
+	Schedule *schedule.Policy
+	// Credentials holds replies while the account's credentials fail, and
+	// learns of rejected credentials from failed replies; nil replies
+	// regardless
+	Credentials *CredentialMonitor
+	// Recovery reconciles the store with the bot's timeline on startup
+	Recovery RecoveryOptions
+}
+
+// ReplyWorkerPool answers the tweets the mentions handler queues. Workers
+// claim the highest priority tweet, answer it through the responder and
+// remove it from the queue, or put it back to be retried later.
+type ReplyWorkerPool struct {
+	responder *TweetResponder
+	logger    *logrus.Logger
+	options   ReplyWorkerOptions
+	host      string
+}
+
+// NewReplyWorkerPool creates a reply worker pool
+func NewReplyWorkerPool(responder *TweetResponder, logger *logrus.Logger, options ReplyWorkerOptions) *ReplyWorkerPool {
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+	if options.TweetsPerWindow <= 0 {
+		options.TweetsPerWindow = DefaultBatchConfig().TweetsPerWindow
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = 5 * time.Second
+	}
+	if options.VisibilityTimeout <= 0 {
+		options.VisibilityTimeout = 5 * time.Minute
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 3
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = time.Minute
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "agent"
+	}
+
+	return &ReplyWorkerPool{
+		responder: responder,
+		logger:    logger,
+		options:   options,
+		host:      fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+// Name implements the Action interface
+func (p *ReplyWorkerPool) Name() string {
+	return "reply_workers"
+}
+
+// Execute implements the Action interface
+func (p *ReplyWorkerPool) Execute(ctx context.Context) error {
+	log := p.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"action":  p.Name(),
+		"workers": p.options.Workers,
+	})
+	log.Info("Starting reply workers")
+
+	// Repair what a crash left half done before anything is answered again
+	report, err := p.Recover(ctx)
+	if err != nil {
+		log.WithError(err).Error("Failed to recover reply state")
+	} else if report.Recorded > 0 || report.Released > 0 {
+		log.WithFields(logrus.Fields{
+			"checked":  report.Checked,
+			"recorded": report.Recorded,
+			"released": report.Released,
+		}).Info("Recovered reply state")
+	}
+
+	// Queue whatever was waiting for a reply before the queue existed or while
+	// the agent was down
+	if err := p.Backfill(ctx); err != nil {
+		log.WithError(err).Error("Failed to backfill reply queue")
+	}
+
+	perWorker := p.options.TweetsPerWindow / p.options.Workers
+	if perWorker < 1 {
+		perWorker = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.options.Workers; i++ {
+		wg.Add(1)
+		go func(worker string) {
+			defer wg.Done()
+			p.work(ctx, worker, newReplyLimiter(perWorker))
+		}(fmt.Sprintf("%s/%d", p.host, i))
+	}
+	wg.Wait()
+
+	log.Info("Reply workers stopped")
+	return ctx.Err()
+}
+
+// Stop implements the Action interface
+func (p *ReplyWorkerPool) Stop() {
+	p.logger.WithField("action", p.Name()).Info("Stopping reply workers")
+}
+
+// SetInterval implements the IntervalSetter interface and sets how often idle
+// workers poll the queue
+func (p *ReplyWorkerPool) SetInterval(interval time.Duration) {
+	p.options.PollInterval = interval
+}
+
+// work runs one worker until ctx is done
+func (p *ReplyWorkerPool) work(ctx context.Context, worker string, limiter *rate.Limiter) {
+	log := p.logger.WithContext(ctx).WithField("worker", worker)
+
+	for {
+		if now := time.Now(); !p.options.Schedule.Open(now) {
+			log.WithField("until", p.options.Schedule.NextOpen(now)).Info("Holding replies until posting opens")
+			if err := p.options.Schedule.Wait(ctx); err != nil {
+				return
+			}
+		}
+		if !p.options.Credentials.Healthy() {
+			log.WithField("credentials", p.options.Credentials.Status().State).Warn("Holding replies until the credentials work again")
+			if err := p.options.Credentials.Wait(ctx); err != nil {
+				return
+			}
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			return
+		}
+
+		// Each claimed tweet is traced from claim to reply
+		job := logging.StartTrace(ctx)
+		processed, err := p.ProcessNext(job, worker)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.WithContext(job).WithError(err).Error("Failed to process queued reply")
+			p.options.Credentials.Report(err)
+			if p.responder.isRateLimitError(err) {
+				log.WithContext(job).WithField("pause", rateLimitPause).Info("Rate limit reached, pausing worker")
+				if sleepContext(ctx, rateLimitPause) != nil {
+					return
+				}
+			}
+		}
+		if !processed {
+			if sleepContext(ctx, p.options.PollInterval) != nil {
+				return
+			}
+		}
+	}
+}
+
+// ProcessNext claims the next queued tweet for worker and answers it. It
+// reports false when no tweet was ready.
+func (p *ReplyWorkerPool) ProcessNext(ctx context.Context, worker string) (bool, error) {
+	store := p.responder.tweetStore
+
+	item, err := store.ClaimReply(ctx, worker, p.options.VisibilityTimeout)
+	if err != nil {
+		return false, err
+	}
+	if item == nil {
+		return false, nil
+	}
+
+	log := p.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"worker":          worker,
+		"tweet_id":        item.TweetID,
+		"conversation_id": item.ConversationID,
+		"priority":        item.Priority,
+		"attempts":        item.Attempts,
+	})
+
+	thread, err := store.ReplyThread(ctx, item.TweetID)
+	if err != nil {
+		return true, p.retry(ctx, item, err)
+	}
+	if thread == nil {
+		log.Debug("Queued tweet no longer needs a reply")
+		return true, store.CompleteReply(ctx, item.TweetID)
+	}
+
+	outcome, err := p.responder.replyToThread(ctx, *thread)
+	switch {
+	case err != nil && p.responder.isRateLimitError(err):
+		// Not the tweet's fault, so it keeps its attempts
+		if requeueErr := store.RetryReply(ctx, item.TweetID, rateLimitPause, nil); requeueErr != nil {
+			log.WithError(requeueErr).Error("Failed to requeue rate limited reply")
+		}
+		return true, err
+	case err != nil && credentialsRejected(err):
+		// Nor is it when the credentials fail; the workers hold until they work
+		if requeueErr := store.RetryReply(ctx, item.TweetID, p.options.RetryDelay, nil); requeueErr != nil {
+			log.WithError(requeueErr).Error("Failed to requeue reply held for credentials")
+		}
+		return true, err
+	case err != nil:
+		return true, p.retry(ctx, item, err)
+	case outcome == replyDeferred:
+		return true, store.RetryReply(ctx, item.TweetID, p.options.RetryDelay, nil)
+	default:
+		return true, store.CompleteReply(ctx, item.TweetID)
+	}
+}
+
+// retry puts a failed tweet back in the queue, or drops it once it has used
+// up its attempts. cause is returned so the worker logs it.
+func (p *ReplyWorkerPool) retry(ctx context.Context, item *memory.ReplyWorkItem, cause error) error {
+	store := p.responder.tweetStore
+
+	if item.Attempts+1 >= p.options.MaxAttempts {
+		p.logger.WithContext(ctx).WithError(cause).WithFields(logrus.Fields{
+			"tweet_id": item.TweetID,
+			"attempts": item.Attempts + 1,
+		}).Warn("Giving up on queued reply")
+		if err := store.CompleteReply(ctx, item.TweetID); err != nil {
+			return err
+		}
+		return cause
+	}
+
+	if err := store.RetryReply(ctx, item.TweetID, p.options.RetryDelay, cause); err != nil {
+		return err
+	}
+	return cause
+}
+
+// Backfill queues every tweet the recall finds needing a reply
+func (p *ReplyWorkerPool) Backfill(ctx context.Context) error {
+	threads, err := p.responder.tweetStore.RecallTweetsNeedingReply(ctx, p.responder.client)
+	if err != nil {
+		return fmt.Errorf("failed to recall tweets needing reply: %w", err)
+	}
+
+	var items []memory.ReplyWorkItem
+	for _, thread := range threads {
+		if tweet, ok := thread.Latest(); ok {
+			items = append(items, memory.ReplyWorkItem{
+				TweetID:        tweet.TweetID,
+				ConversationID: thread.ConversationID,
+				Priority:       memory.QueuePriority(thread.Priority),
+			})
+		}
+	}
+	return p.responder.tweetStore.EnqueueReplies(ctx, items)
+}
@@ -0,0 +1,228 @@
+package actions
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// Default retention settings
+const (
+	// DefaultRetentionBatchSize caps the rows pruned per batch
+	DefaultRetentionBatchSize = 500
+	// DefaultRetentionBatchPause is waited between batches
+	DefaultRetentionBatchPause = time.Second
+)
+
+// RetentionPolicy keeps the rows of one table for MaxAge; zero keeps them forever
+type RetentionPolicy struct {
+	// Table is one of memory.RetainTweets, memory.RetainJudgments,
+	// memory.RetainAnalytics, memory.RetainLatency, memory.RetainStages or
+	// memory.RetainQuota
+	Table  string
+	MaxAge time.Duration
+}
+
+// RetentionOptions configures the retention action
+type RetentionOptions struct {
+	Interval time.Duration
+	Policies []RetentionPolicy
+	// BatchSize caps the rows archived and deleted at a time
+	BatchSize int
+	// BatchPause is waited between batches, leaving the database room to
+	// vacuum and serve other queries
+	BatchPause time.Duration
+	// Sink receives pruned rows as gzipped JSON lines before they are
+	// deleted; nil prunes without a copy
+	Sink archive.Publisher
+}
+
+// RetentionAction keeps the database small by pruning rows older than their
+// policy allows. Rows are archived to the sink and deleted in small batches,
+// so no long transaction holds locks and deleted space is reclaimed
+// gradually. A batch that fails to archive is not deleted.
+type RetentionAction struct {
+	tweetStore *memory.TweetStore
+	logger     *logrus.Logger
+	options    RetentionOptions
+}
+
+// NewRetentionAction creates a new retention action
+func NewRetentionAction(
+	store *memory.TweetStore,
+	logger *logrus.Logger,
+	options RetentionOptions,
+) *RetentionAction {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultRetentionBatchSize
+	}
+	if options.BatchPause <= 0 {
+		options.BatchPause = DefaultRetentionBatchPause
+	}
+	return &RetentionAction{
+		tweetStore: store,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (r *RetentionAction) Name() string {
+	return "retention"
+}
+
+// Execute implements the Action interface
+func (r *RetentionAction) Execute(ctx context.Context) error {
+	log := r.logger.WithContext(ctx).WithField("action", r.Name())
+
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting retention")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Retention stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if _, err := r.Prune(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to prune expired rows")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Prune archives and deletes the rows past their policy's age and returns how
+// many were deleted per table
+func (r *RetentionAction) Prune(ctx context.Context) (map[string]int64, error) {
+	pruned := make(map[string]int64)
+	for _, policy := range r.options.Policies {
+		if policy.MaxAge <= 0 {
+			continue
+		}
+		deleted, err := r.pruneTable(ctx, policy.Table, time.Now().Add(-policy.MaxAge))
+		if deleted > 0 {
+			pruned[policy.Table] = deleted
+		}
+		if err != nil {
+			return pruned, err
+		}
+	}
+
+	if len(pruned) > 0 {
+		fields := logrus.Fields{}
+		for table, deleted := range pruned {
+			fields[table] = deleted
+		}
+		r.logger.WithContext(ctx).WithFields(fields).Info("Pruned expired rows")
+	}
+	return pruned, nil
+}
+
+// pruneTable works through the expired rows of table one batch at a time
+func (r *RetentionAction) pruneTable(ctx context.Context, table string, before time.Time) (int64, error) {
+	var total int64
+	for {
+		rows, err := r.tweetStore.ExpiredRows(ctx, table, before, r.options.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		if r.options.Sink != nil {
+			if err := r.archiveRows(ctx, table, rows); err != nil {
+				return total, err
+			}
+		}
+
+		deleted, err := r.tweetStore.DeleteRows(ctx, table, rows)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+		if deleted == 0 {
+			// The same batch would come back forever
+			return total, fmt.Errorf("none of %d expired %s rows were deleted", len(rows), table)
+		}
+		if len(rows) < r.options.BatchSize {
+			return total, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(r.options.BatchPause):
+		}
+	}
+}
+
+// archiveRows publishes a batch of rows as one gzipped JSON lines document
+func (r *RetentionAction) archiveRows(ctx context.Context, table string, rows []map[string]interface{}) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+	for _, row := range rows {
+		if err := encoder.Encode(archivedRow(row)); err != nil {
+			return fmt.Errorf("failed to encode %s row: %w", table, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s rows: %w", table, err)
+	}
+
+	key := fmt.Sprintf("%s/%s/%s.jsonl.gz",
+		r.tweetStore.BotID(), table, time.Now().UTC().Format("20060102T150405.000000000Z"))
+	location, err := r.options.Sink.Publish(ctx, key, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to archive %s rows: %w", table, err)
+	}
+
+	r.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"table": table,
+		"rows":  len(rows),
+		"uri":   location.URI,
+	}).Debug("Archived expired rows")
+	return nil
+}
+
+// archivedRow prepares a row for encoding. Drivers return text and JSON
+// columns as bytes, which would otherwise be encoded as base64.
+func archivedRow(row map[string]interface{}) map[string]interface{} {
+	for column, value := range row {
+		data, ok := value.([]byte)
+		if !ok {
+			continue
+		}
+		if json.Valid(data) {
+			row[column] = json.RawMessage(data)
+		} else {
+			row[column] = string(data)
+		}
+	}
+	return row
+}
+
+// Stop implements the Action interface
+func (r *RetentionAction) Stop() {
+	log := r.logger.WithField("action", r.Name())
+	log.Info("Stopping retention")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (r *RetentionAction) SetInterval(interval time.Duration) {
+	r.options.Interval = interval
+}
@@ -0,0 +1,284 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRoastCooldown keeps a user from being judged more than once a week
+	DefaultRoastCooldown = 7 * 24 * time.Hour
+	// RoastCategory is the personality category decrees are written in
+	RoastCategory = "roast"
+
+	// decreeHashtag closes every decree
+	decreeHashtag = "#CatLordJudgment"
+)
+
+// RoastOptions configures the Judgment Throne
+type RoastOptions struct {
+	Interval time.Duration
+	// Cooldown is how long a judged user is left alone; defaults to a week
+	Cooldown time.Duration
+	// Lookback is how recent a mention must be for its author to be judged
+	Lookback time.Duration
+	// TweetsPerSubject is how many of the subject's tweets the judgment is based on
+	TweetsPerSubject int
+	// Personality overrides the persona the verdict is written in
+	Personality map[string]string
+	// Schedule holds judgments that come due during quiet hours and
+	// embargoes until posting opens again; nil judges at any time
+	Schedule *schedule.Policy
+}
+
+// RoastAction periodically picks a user who recently mentioned the bot, rates
+// them with the structured judgment generator and replies with the decree
+type RoastAction struct {
+	client     *twitter.TwitterClient
+	tweetStore *memory.TweetStore
+	judge      thoughts.JudgmentGenerator
+	logger     *logrus.Logger
+	options    RoastOptions
+}
+
+// NewRoastAction creates a new Judgment Throne action
+func NewRoastAction(
+	client *twitter.TwitterClient,
+	store *memory.TweetStore,
+	judge thoughts.JudgmentGenerator,
+	logger *logrus.Logger,
+	options RoastOptions,
+) *RoastAction {
+	if options.Interval <= 0 {
+		options.Interval = 6 * time.Hour
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = DefaultRoastCooldown
+	}
+	if options.Lookback <= 0 {
+		options.Lookback = 24 * time.Hour
+	}
+	if options.TweetsPerSubject <= 0 {
+		options.TweetsPerSubject = 10
+	}
+	return &RoastAction{
+		client:     client,
+		tweetStore: store,
+		judge:      judge,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (r *RoastAction) Name() string {
+	return "judgment_throne"
+}
+
+// Execute implements the Action interface
+func (r *RoastAction) Execute(ctx context.Context) error {
+	log := r.logger.WithContext(ctx).WithField("action", r.Name())
+
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting Judgment Throne")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Judgment Throne stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if now := time.Now(); !r.options.Schedule.Open(now) {
+				log.WithContext(tick).WithField("until", r.options.Schedule.NextOpen(now)).Info("Holding judgment until posting opens")
+				if err := r.options.Schedule.Wait(ctx); err != nil {
+					return err
+				}
+				// Judge once, not once per tick missed while waiting
+				select {
+				case <-ticker.C:
+				default:
+				}
+			}
+			if err := r.Roast(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to pass judgment")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Stop implements the Action interface
+func (r *RoastAction) Stop() {
+	log := r.logger.WithField("action", r.Name())
+	log.Info("Stopping Judgment Throne")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (r *RoastAction) SetInterval(interval time.Duration) {
+	r.options.Interval = interval
+}
+
+// Roast judges the most recent mention author who is not on cooldown. It does
+// nothing when there is no such author.
+func (r *RoastAction) Roast(ctx context.Context) error {
+	now := time.Now()
+	candidates, err := r.tweetStore.RoastCandidates(ctx, now.Add(-r.options.Lookback), now.Add(-r.options.Cooldown), 1)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		r.logger.WithContext(ctx).WithField("action", r.Name()).Debug("No one to judge")
+		return nil
+	}
+	candidate := candidates[0]
+
+	log := r.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"action":   r.Name(),
+		"subject":  candidate.Username,
+		"tweet_id": candidate.TweetID,
+	})
+
+	profile, err := r.client.GetUserByUsername(ctx, candidate.Username)
+	if err != nil {
+		return fmt.Errorf("failed to fetch profile of @%s: %w", candidate.Username, err)
+	}
+
+	tweets, err := r.subjectTweets(ctx, candidate.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	judgment, err := r.judge.Judge(ctx, thoughts.JudgmentConfig{
+		SubjectUsername:  profile.Username,
+		Material:         judgmentMaterial(profile, tweets),
+		MaxVerdictLength: verdictBudget(profile.Username, thoughts.DefaultJudgmentCategories),
+		Temperature:      0.9,
+		Personality:      r.options.Personality,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to judge @%s: %w", profile.Username, err)
+	}
+
+	decree := formatDecree(profile.Username, judgment)
+	reply, err := r.client.PostReplyThread(ctx, twitter.PostReplyThreadParams{
+		Text:           decree,
+		ReplyToID:      candidate.TweetID,
+		ConversationID: candidate.ConversationID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post decree: %w", err)
+	}
+
+	// The decree answers the mention, so it must not be replied to again
+	if err := r.tweetStore.SaveAgentReply(context.WithoutCancel(ctx), candidate.TweetID, reply.ID, candidate.ConversationID, decree); err != nil {
+		log.WithError(err).Error("Failed to save decree")
+	}
+
+	err = r.tweetStore.SaveJudgment(ctx, &memory.JudgmentRecord{
+		SubjectID:       profile.ID,
+		SubjectUsername: profile.Username,
+		TweetID:         reply.ID,
+		SubjectTweetID:  candidate.TweetID,
+		Scores:          judgment.Scores,
+		Overall:         judgment.Overall(),
+		Verdict:         judgment.Verdict,
+	})
+	if err != nil {
+		// Without the record the cooldown does not apply, so surface it
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"reply_id": reply.ID,
+		"overall":  judgment.Overall(),
+	}).Info("Judgment passed")
+	return nil
+}
+
+// subjectTweets returns the texts of the subject's recent original tweets from
+// their timeline, or of their stored tweets when the timeline is unavailable
+func (r *RoastAction) subjectTweets(ctx context.Context, authorID string) ([]string, error) {
+	timeline, err := r.client.GetUserTimeline(ctx, authorID, twitter.GetUserTimelineParams{
+		MaxTweets: r.options.TweetsPerSubject,
+		Exclude:   []string{twitter.ExcludeRetweets, twitter.ExcludeReplies},
+	})
+	if err == nil && len(timeline) > 0 {
+		texts := make([]string, len(timeline))
+		for i, tweet := range timeline {
+			texts[i] = tweet.Text
+		}
+		return texts, nil
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).WithError(err).WithField("author_id", authorID).Warn("Failed to fetch timeline, judging stored tweets")
+	}
+
+	stored, err := r.tweetStore.AuthorTweets(ctx, authorID, r.options.TweetsPerSubject)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, len(stored))
+	for i, tweet := range stored {
+		texts[i] = tweet.Text
+	}
+	return texts, nil
+}
+
+// judgmentMaterial describes the subject for the judgment prompt
+func judgmentMaterial(profile *twitter.User, tweets []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Name: %s\n", profile.Name)
+	if profile.Description != "" {
+		fmt.Fprintf(&b, "Bio: %s\n", profile.Description)
+	}
+	if profile.Location != "" {
+		fmt.Fprintf(&b, "Location: %s\n", profile.Location)
+	}
+	fmt.Fprintf(&b, "Followers: %d, following: %d, tweets: %d\n",
+		profile.PublicMetrics.FollowersCount, profile.PublicMetrics.FollowingCount, profile.PublicMetrics.TweetCount)
+
+	if len(tweets) > 0 {
+		b.WriteString("Recent tweets:\n")
+		for _, tweet := range tweets {
+			fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(tweet, "\n", " "))
+		}
+	}
+	return b.String()
+}
+
+// formatDecree renders a judgment in the formal decree format, e.g.
+//
+//	ROYAL DECREE of the Judgment Throne
+//	@user is hereby judged:
+//	Bio Cringe Factor: 7/10
+//	...
+//	Verdict: ...
+//	#CatLordJudgment
+func formatDecree(username string, judgment *thoughts.Judgment) string {
+	return fmt.Sprintf("ROYAL DECREE of the Judgment Throne\n@%s is hereby judged:\n%s\nVerdict: %s\n%s",
+		username, judgment.ScoreLines(), judgment.Verdict, decreeHashtag)
+}
+
+// verdictBudget returns how long a verdict may be for the decree to fit in a
+// tweet, assuming every score takes two digits
+func verdictBudget(username string, categories []string) int {
+	scores := make(map[string]int, len(categories))
+	for _, category := range categories {
+		scores[category] = thoughts.MaxJudgmentScore
+	}
+	frame := formatDecree(username, &thoughts.Judgment{Categories: categories, Scores: scores})
+	return MaxTweetLength - thoughts.WeightedLength(frame)
+}
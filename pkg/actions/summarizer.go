@@ -0,0 +1,286 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/sirupsen/logrus"
+)
+
+// Default summarizer settings
+const (
+	// DefaultSummaryBatch caps the conversations and the users summarized per run
+	DefaultSummaryBatch = 50
+	// DefaultSummaryMinTweets is how many stored tweets a conversation or user
+	// needs before a summary is worth writing
+	DefaultSummaryMinTweets = 3
+	// DefaultSummaryChunk is how many new tweets are folded into a summary
+	// with one LLM call
+	DefaultSummaryChunk = 100
+
+	// summaryRecentPosts is how many of the posts a conversation summary
+	// covers stay in reply context word for word, so the reply still sees
+	// what it answers
+	summaryRecentPosts = 3
+)
+
+// SummarizerOptions configures the memory summarizer
+type SummarizerOptions struct {
+	Interval time.Duration
+	// Batch caps the conversations and the users summarized per run
+	Batch int
+	// MinTweets is how many stored tweets a conversation or user needs to be
+	// summarized
+	MinTweets int
+	// Chunk is how many new tweets one LLM call folds in
+	Chunk int
+}
+
+// MemorySummarizer periodically writes compact summaries of the bot's
+// conversations and of the users it talks to, so reply prompts can use them
+// instead of the full history. Each run folds only the tweets newer than the
+// previous summary into it, so a summary keeps what it learned after the
+// retention policy prunes the tweets it was written from.
+type MemorySummarizer struct {
+	tweetStore *memory.TweetStore
+	generator  thoughts.SummaryGenerator
+	logger     *logrus.Logger
+	options    SummarizerOptions
+}
+
+// NewMemorySummarizer creates a new memory summarizer
+func NewMemorySummarizer(
+	store *memory.TweetStore,
+	generator thoughts.SummaryGenerator,
+	logger *logrus.Logger,
+	options SummarizerOptions,
+) *MemorySummarizer {
+	if options.Interval <= 0 {
+		options.Interval = 24 * time.Hour
+	}
+	if options.Batch <= 0 {
+		options.Batch = DefaultSummaryBatch
+	}
+	if options.MinTweets <= 0 {
+		options.MinTweets = DefaultSummaryMinTweets
+	}
+	if options.Chunk <= 0 {
+		options.Chunk = DefaultSummaryChunk
+	}
+	return &MemorySummarizer{
+		tweetStore: store,
+		generator:  generator,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+// Name implements the Action interface
+func (m *MemorySummarizer) Name() string {
+	return "memory_summarizer"
+}
+
+// Execute implements the Action interface
+func (m *MemorySummarizer) Execute(ctx context.Context) error {
+	log := m.logger.WithContext(ctx).WithField("action", m.Name())
+
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+
+	log.Info("Starting memory summarizer")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Memory summarizer stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			tick := logging.StartTrace(ctx)
+			if _, _, err := m.SummarizeRecent(tick); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to summarize conversations and users")
+				// Continue running even if we encounter an error
+				continue
+			}
+		}
+	}
+}
+
+// Stop implements the Action interface
+func (m *MemorySummarizer) Stop() {
+	m.logger.WithField("action", m.Name()).Info("Stopping memory summarizer")
+}
+
+// SetInterval implements the IntervalSetter interface
+func (m *MemorySummarizer) SetInterval(interval time.Duration) {
+	m.options.Interval = interval
+}
+
+// SummarizeRecent updates the summaries of the conversations and users with
+// new tweets and returns how many of each were updated. A conversation or
+// user that fails is logged and left for the next run.
+func (m *MemorySummarizer) SummarizeRecent(ctx context.Context) (int, int, error) {
+	log := m.logger.WithContext(ctx).WithField("action", m.Name())
+
+	conversationIDs, err := m.tweetStore.ConversationsToSummarize(ctx, m.options.MinTweets, m.options.Batch)
+	if err != nil {
+		return 0, 0, err
+	}
+	conversations := 0
+	for _, conversationID := range conversationIDs {
+		if err := m.summarizeConversation(ctx, conversationID); err != nil {
+			if ctx.Err() != nil {
+				return conversations, 0, ctx.Err()
+			}
+			log.WithError(err).WithField("conversation_id", conversationID).Warn("Failed to summarize conversation")
+			continue
+		}
+		conversations++
+	}
+
+	userIDs, err := m.tweetStore.UsersToSummarize(ctx, m.options.MinTweets, m.options.Batch)
+	if err != nil {
+		return conversations, 0, err
+	}
+	users := 0
+	for _, userID := range userIDs {
+		if err := m.summarizeUser(ctx, userID); err != nil {
+			if ctx.Err() != nil {
+				return conversations, users, ctx.Err()
+			}
+			log.WithError(err).WithField("user_id", userID).Warn("Failed to summarize user")
+			continue
+		}
+		users++
+	}
+
+	log.WithFields(logrus.Fields{
+		"conversations": conversations,
+		"users":         users,
+	}).Info("Updated memory summaries")
+	return conversations, users, nil
+}
+
+// summarizeConversation folds the conversation's new tweets into its summary
+func (m *MemorySummarizer) summarizeConversation(ctx context.Context, conversationID string) error {
+	previous, err := m.tweetStore.ConversationSummary(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	summary := &memory.ConversationSummary{ConversationID: conversationID}
+	if previous != nil {
+		summary = previous
+	}
+
+	page := func(options memory.TweetPageOptions) (*memory.TweetPage, error) {
+		return m.tweetStore.GetConversation(ctx, conversationID, options)
+	}
+	folded, err := m.fold(ctx, "the conversation", page, &summary.Summary, &summary.LastTweetID, summary.LastTweetAt)
+	if err != nil || folded == 0 {
+		return err
+	}
+	summary.TweetCount += folded
+	return m.tweetStore.SaveConversationSummary(ctx, summary)
+}
+
+// summarizeUser folds the user's new tweets into their summary
+func (m *MemorySummarizer) summarizeUser(ctx context.Context, userID string) error {
+	previous, err := m.tweetStore.UserSummary(ctx, userID)
+	if err != nil {
+		return err
+	}
+	summary := &memory.UserSummary{UserID: userID}
+	if previous != nil {
+		summary = previous
+	}
+
+	var username string
+	page := func(options memory.TweetPageOptions) (*memory.TweetPage, error) {
+		page, err := m.tweetStore.GetTweetsByAuthor(ctx, userID, options)
+		if err == nil {
+			for _, tweet := range page.Tweets {
+				if tweet.AuthorUsername != "" {
+					username = tweet.AuthorUsername
+				}
+			}
+		}
+		return page, err
+	}
+	subject := "what this user has said to the bot and how they treat it"
+	if summary.Username != "" {
+		subject = fmt.Sprintf("what @%s has said to the bot and how they treat it", summary.Username)
+	}
+	folded, err := m.fold(ctx, subject, page, &summary.Summary, &summary.LastTweetID, summary.LastTweetAt)
+	if err != nil || folded == 0 {
+		return err
+	}
+	if username != "" {
+		summary.Username = username
+	}
+	summary.TweetCount += folded
+	return m.tweetStore.SaveUserSummary(ctx, summary)
+}
+
+// fold pages through the tweets after lastTweetID, oldest first, and folds
+// each page into text with one LLM call. When the last summarized tweet was
+// pruned since, it resumes at lastTweetAt. It returns how many tweets were
+// folded in and moves lastTweetID to the newest of them.
+func (m *MemorySummarizer) fold(
+	ctx context.Context,
+	subject string,
+	load func(memory.TweetPageOptions) (*memory.TweetPage, error),
+	text *string,
+	lastTweetID *string,
+	lastTweetAt time.Time,
+) (int, error) {
+	options := memory.TweetPageOptions{Limit: m.options.Chunk, After: *lastTweetID}
+	folded := 0
+	for {
+		page, err := load(options)
+		if errors.Is(err, memory.ErrInvalidCursor) {
+			options.After, options.Since = "", lastTweetAt
+			page, err = load(options)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		// Deleted tweets keep their place in the conversation without content
+		var posts []thoughts.ContextPost
+		for _, tweet := range page.Tweets {
+			if tweet.Text == "" {
+				continue
+			}
+			posts = append(posts, thoughts.ContextPost{
+				AuthorUsername: tweet.AuthorUsername,
+				AuthorName:     tweet.AuthorName,
+				Text:           tweet.Text,
+			})
+		}
+		if len(posts) > 0 {
+			summary, err := m.generator.Summarize(ctx, thoughts.SummaryConfig{
+				Subject:     subject,
+				Previous:    *text,
+				Posts:       posts,
+				Temperature: 0.3,
+			})
+			if err != nil {
+				return 0, err
+			}
+			*text = summary
+			folded += len(posts)
+		}
+		if len(page.Tweets) > 0 {
+			*lastTweetID = page.Tweets[len(page.Tweets)-1].ID
+		}
+
+		if page.Next == "" {
+			return folded, nil
+		}
+		options.After, options.Since = page.Next, time.Time{}
+	}
+}
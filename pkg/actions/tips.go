@@ -0,0 +1,428 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/commands"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+// TipSender sends tips on chain; *wallet.Client implements it
+type TipSender interface {
+	ResolveAddress(ctx context.Context, network wallet.NetworkType, addressOrName string) (common.Address, error)
+	DisplayAddress(ctx context.Context, address common.Address) string
+	GetTokenMetadata(ctx context.Context, network wallet.NetworkType, tokenAddress common.Address) (*wallet.TokenMetadata, error)
+	TransferERC20(ctx context.Context, network wallet.NetworkType, tokenAddress, to common.Address, amount *big.Int) (*common.Hash, error)
+}
+
+// TipPolicy limits what tippers can send, in whole tokens
+type TipPolicy struct {
+	// MaxAmount is the largest single tip
+	MaxAmount float64
+	// DailyLimit caps what one tipper sends in 24 hours; 0 means no cap
+	DailyLimit float64
+	// ApprovalAbove holds tips larger than this for an operator to approve;
+	// 0 sends every tip within the limits right away
+	ApprovalAbove float64
+}
+
+// Evaluate returns the status a new tip of amount starts in, given what its
+// sender tipped in the last 24 hours, and why it was held or rejected
+func (p TipPolicy) Evaluate(amount, tippedToday float64) (status, reason string) {
+	switch {
+	case amount > p.MaxAmount:
+		return memory.TipRejected, fmt.Sprintf("tip exceeds the maximum of %g", p.MaxAmount)
+	case p.DailyLimit > 0 && tippedToday+amount > p.DailyLimit:
+		return memory.TipRejected, fmt.Sprintf("tip exceeds the daily limit of %g", p.DailyLimit)
+	case p.ApprovalAbove > 0 && amount > p.ApprovalAbove:
+		return memory.TipPending, fmt.Sprintf("tips above %g need approval", p.ApprovalAbove)
+	default:
+		return memory.TipSending, ""
+	}
+}
+
+// TipOptions configures tipping
+type TipOptions struct {
+	// Network tips are sent on
+	Network wallet.NetworkType
+	// Token is the ERC20 contract tips are paid in
+	Token  common.Address
+	Policy TipPolicy
+	// Schedule holds command replies that come due during quiet hours and
+	// embargoes until posting opens again; nil replies at any time. Replies
+	// to operators approving or rejecting a tip are exempt and posted right
+	// away, as the operator chose when to act.
+	Schedule *schedule.Policy
+}
+
+// Tipper runs tip and wallet commands from mentions: users register the
+// address they receive tips at, and users with the tipper role send tokens
+// from the bot's wallet to them
+type Tipper struct {
+	sender  TipSender
+	client  *twitter.TwitterClient
+	store   *memory.TweetStore
+	logger  *logrus.Logger
+	events  *events.Bus
+	options TipOptions
+}
+
+// NewTipper creates a new Tipper
+func NewTipper(sender TipSender, client *twitter.TwitterClient, store *memory.TweetStore, logger *logrus.Logger, options TipOptions) *Tipper {
+	return &Tipper{
+		sender:  sender,
+		client:  client,
+		store:   store,
+		logger:  logger,
+		options: options,
+	}
+}
+
+// WithEvents publishes sent and held tips on the event bus
+func (t *Tipper) WithEvents(bus *events.Bus) *Tipper {
+	t.events = bus
+	return t
+}
+
+// WithTipper runs tip and wallet commands in mentions instead of replying to
+// them with the model
+func (tr *TweetResponder) WithTipper(tipper *Tipper) *TweetResponder {
+	tr.tipper = tipper
+	return tr
+}
+
+// Run executes the command of a mention and replies with the outcome
+func (t *Tipper) Run(ctx context.Context, mention memory.TweetNeedingReply, conversationID string, command commands.Command) error {
+	log := t.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"method":   "Tipper.Run",
+		"command":  command.Name,
+		"tweet_id": mention.TweetID,
+		"author":   mention.AuthorID,
+	})
+
+	var text string
+	var err error
+	switch command.Name {
+	case commands.Wallet:
+		text, err = t.registerWallet(ctx, log, mention, command)
+	case commands.Tip:
+		text, err = t.tip(ctx, log, mention, conversationID, command)
+	default:
+		return fmt.Errorf("unsupported command: %s", command.Name)
+	}
+	if err != nil {
+		return err
+	}
+	// Sending a tip can take long enough for posting to close meanwhile
+	if now := time.Now(); !t.options.Schedule.Open(now) {
+		log.WithField("until", t.options.Schedule.NextOpen(now)).Info("Holding command reply until posting opens")
+		if err := t.options.Schedule.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return t.reply(ctx, log, mention.TweetID, conversationID, text)
+}
+
+// registerWallet saves the address the author of a wallet command receives
+// tips at
+func (t *Tipper) registerWallet(ctx context.Context, log *logrus.Entry, mention memory.TweetNeedingReply, command commands.Command) (string, error) {
+	input, err := command.Address()
+	if err != nil {
+		return "The cat lord does not understand. " + capitalize(err.Error()), nil
+	}
+	address, err := t.sender.ResolveAddress(ctx, t.options.Network, input)
+	if err != nil {
+		log.WithError(err).WithField("address", input).Info("Could not resolve tip wallet")
+		return fmt.Sprintf("%s is not an address the cat lord can send to.", input), nil
+	}
+
+	if err := t.store.SetTipWallet(ctx, mention.AuthorID, mention.AuthorUsername, address.Hex()); err != nil {
+		return "", err
+	}
+	log.WithField("address", address.Hex()).Info("Registered tip wallet")
+	return fmt.Sprintf("Noted. Tips for you now go to %s. 🐾", t.sender.DisplayAddress(ctx, address)), nil
+}
+
+// tip records the tip of a tip command and sends it when the policy allows,
+// returning the reply to the command
+func (t *Tipper) tip(ctx context.Context, log *logrus.Entry, mention memory.TweetNeedingReply, conversationID string, command commands.Command) (string, error) {
+	// The command was handled before, e.g. the reply failed to post
+	existing, err := t.store.TipForTweet(ctx, mention.TweetID)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return tipOutcome(existing), nil
+	}
+
+	args, err := command.Tip()
+	if err != nil {
+		return "The cat lord does not understand. " + capitalize(err.Error()), nil
+	}
+
+	allowed, err := t.store.HasRole(ctx, mention.AuthorID, memory.RoleTipper)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		log.Info("Tip command from user without the tipper role")
+		return "Only the cat lord's treasurers may hand out tips. 😼", nil
+	}
+
+	metadata, err := t.sender.GetTokenMetadata(ctx, t.options.Network, t.options.Token)
+	if err != nil {
+		return "", fmt.Errorf("failed to load tip token: %w", err)
+	}
+	if !strings.EqualFold(args.Symbol, metadata.Symbol) {
+		return fmt.Sprintf("The cat lord only tips in %s.", metadata.Symbol), nil
+	}
+
+	recipient, err := t.client.GetUserByUsername(ctx, args.Recipient)
+	if err != nil {
+		log.WithError(err).WithField("recipient", args.Recipient).Info("Tip recipient not found")
+		return fmt.Sprintf("The cat lord knows no @%s.", args.Recipient), nil
+	}
+	if recipient.ID == mention.AuthorID {
+		return "Tipping yourself? Bold. Denied.", nil
+	}
+	tipWallet, err := t.store.GetTipWallet(ctx, recipient.ID)
+	if err != nil {
+		return "", err
+	}
+	if tipWallet == nil {
+		return fmt.Sprintf("@%s has no wallet on file. @%s, reply \"wallet 0x...\" to the cat lord to receive tips.",
+			recipient.Username, recipient.Username), nil
+	}
+
+	amount, err := wallet.ParseUnits(args.Amount, metadata.Decimals)
+	if err != nil {
+		return "The cat lord does not understand. " + capitalize(err.Error()), nil
+	}
+
+	tipped, err := t.store.TippedSince(ctx, mention.AuthorID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return "", err
+	}
+	status, reason := t.options.Policy.Evaluate(args.Units, tipped)
+
+	tip := &memory.Tip{
+		TweetID:           mention.TweetID,
+		ConversationID:    conversationID,
+		SenderID:          mention.AuthorID,
+		RecipientID:       recipient.ID,
+		RecipientUsername: recipient.Username,
+		RecipientAddress:  tipWallet.Address,
+		Network:           string(t.options.Network),
+		Token:             t.options.Token.Hex(),
+		Symbol:            metadata.Symbol,
+		Amount:            amount.String(),
+		AmountUnits:       args.Units,
+		Status:            status,
+		Reason:            reason,
+	}
+	created, err := t.store.CreateTip(ctx, tip)
+	if err != nil {
+		return "", err
+	}
+	if !created {
+		// Another instance recorded the same command first
+		existing, err := t.store.TipForTweet(ctx, mention.TweetID)
+		if err != nil || existing == nil {
+			return "", fmt.Errorf("tip for tweet %s vanished: %w", mention.TweetID, err)
+		}
+		return tipOutcome(existing), nil
+	}
+
+	log = log.WithFields(logrus.Fields{"tip_id": tip.ID, "status": status, "amount": args.Units})
+	switch status {
+	case memory.TipSending:
+		t.send(ctx, log, tip)
+	case memory.TipPending:
+		log.WithField("reason", reason).Info("Tip held for approval")
+		t.events.Emit(events.TipHeld, t.store.BotID(), tipEventData(tip))
+	default:
+		log.WithField("reason", reason).Info("Tip rejected by policy")
+	}
+	return tipOutcome(tip), nil
+}
+
+// send transfers a tip in the sending status and records the outcome on tip
+func (t *Tipper) send(ctx context.Context, log *logrus.Entry, tip *memory.Tip) {
+	// Once the transfer is submitted its outcome must be recorded
+	ctx = context.WithoutCancel(ctx)
+
+	amount, ok := new(big.Int).SetString(tip.Amount, 10)
+	if !ok {
+		t.finish(ctx, log, tip, memory.TipFailed, "invalid amount "+tip.Amount, "")
+		return
+	}
+	hash, err := t.sender.TransferERC20(ctx, wallet.NetworkType(tip.Network),
+		common.HexToAddress(tip.Token), common.HexToAddress(tip.RecipientAddress), amount)
+	if err != nil {
+		log.WithError(err).Error("Failed to send tip")
+		t.finish(ctx, log, tip, memory.TipFailed, err.Error(), "")
+		return
+	}
+
+	t.finish(ctx, log, tip, memory.TipSent, "", hash.Hex())
+	log.WithField("tx_hash", tip.TxHash).Info("Sent tip")
+	t.events.Emit(events.TipSent, t.store.BotID(), tipEventData(tip))
+}
+
+// finish moves a sending tip to its final status
+func (t *Tipper) finish(ctx context.Context, log *logrus.Entry, tip *memory.Tip, status, reason, txHash string) {
+	tip.Status, tip.Reason, tip.TxHash = status, reason, txHash
+	if _, err := t.store.TransitionTip(ctx, tip.ID, memory.TipSending, status, reason, txHash); err != nil {
+		log.WithError(err).WithField("status", status).Error("Failed to record tip outcome")
+	}
+}
+
+// PendingTips returns the tips waiting for approval, oldest first
+func (t *Tipper) PendingTips(ctx context.Context) ([]memory.Tip, error) {
+	return t.store.TipsByStatus(ctx, memory.TipPending)
+}
+
+// Approve sends a tip held for approval and replies to its command, even
+// during quiet hours
+func (t *Tipper) Approve(ctx context.Context, id int64) (*memory.Tip, error) {
+	tip, err := t.store.GetTip(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	moved, err := t.store.TransitionTip(ctx, id, memory.TipPending, memory.TipSending, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, memory.ErrTipNotPending
+	}
+	tip.Status, tip.Reason = memory.TipSending, ""
+
+	log := t.logger.WithContext(ctx).WithFields(logrus.Fields{"method": "Tipper.Approve", "tip_id": id})
+	t.send(ctx, log, tip)
+	if err := t.reply(ctx, log, tip.TweetID, tip.ConversationID, tipOutcome(tip)); err != nil {
+		log.WithError(err).Error("Failed to reply to approved tip")
+	}
+	return tip, nil
+}
+
+// Reject refuses a tip held for approval and replies to its command, even
+// during quiet hours
+func (t *Tipper) Reject(ctx context.Context, id int64, reason string) (*memory.Tip, error) {
+	tip, err := t.store.GetTip(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = "rejected by an operator"
+	}
+	moved, err := t.store.TransitionTip(ctx, id, memory.TipPending, memory.TipRejected, reason, "")
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, memory.ErrTipNotPending
+	}
+	tip.Status, tip.Reason = memory.TipRejected, reason
+
+	log := t.logger.WithContext(ctx).WithFields(logrus.Fields{"method": "Tipper.Reject", "tip_id": id})
+	log.WithField("reason", reason).Info("Tip rejected")
+	if err := t.reply(ctx, log, tip.TweetID, tip.ConversationID, tipOutcome(tip)); err != nil {
+		log.WithError(err).Error("Failed to reply to rejected tip")
+	}
+	return tip, nil
+}
+
+// Roles returns the users granted command roles
+func (t *Tipper) Roles(ctx context.Context) ([]memory.CommandRole, error) {
+	return t.store.ListRoles(ctx)
+}
+
+// GrantTipper lets a user send tips
+func (t *Tipper) GrantTipper(ctx context.Context, userID string) error {
+	return t.store.GrantRole(ctx, userID, memory.RoleTipper)
+}
+
+// RevokeTipper stops a user from sending tips, reporting whether they could
+func (t *Tipper) RevokeTipper(ctx context.Context, userID string) (bool, error) {
+	return t.store.RevokeRole(ctx, userID, memory.RoleTipper)
+}
+
+// reply posts text in reply to a command tweet and marks the tweet answered
+func (t *Tipper) reply(ctx context.Context, log *logrus.Entry, tweetID, conversationID, text string) error {
+	posted, err := t.client.PostReplyThread(ctx, twitter.PostReplyThreadParams{
+		Text:           text,
+		ReplyToID:      tweetID,
+		ConversationID: conversationID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post command reply: %w", err)
+	}
+
+	// The reply is public now, so record it even if ctx is cancelled
+	ctx = context.WithoutCancel(ctx)
+	if err := t.store.SaveAgentReply(ctx, tweetID, posted.ID, conversationID, text); err != nil {
+		log.WithError(err).Error("Failed to save command reply")
+	}
+	if err := t.store.UpdateTweetAfterReply(ctx, tweetID, posted.ID); err != nil {
+		log.WithError(err).Error("Failed to update tweet status after command reply")
+	}
+	return nil
+}
+
+// tipOutcome is the reply telling the tipper what became of their tip
+func tipOutcome(tip *memory.Tip) string {
+	amount := fmt.Sprintf("%s %s", formatUnits(tip.AmountUnits), tip.Symbol)
+	switch tip.Status {
+	case memory.TipSent:
+		return fmt.Sprintf("The cat lord has sent %s to @%s. 👑 Tx: %s", amount, tip.RecipientUsername, tip.TxHash)
+	case memory.TipPending:
+		return fmt.Sprintf("A tip of %s to @%s awaits the royal treasurer's approval.", amount, tip.RecipientUsername)
+	case memory.TipSending:
+		return fmt.Sprintf("A tip of %s to @%s is on its way.", amount, tip.RecipientUsername)
+	case memory.TipRejected:
+		return fmt.Sprintf("The tip of %s to @%s was refused: %s.", amount, tip.RecipientUsername, tip.Reason)
+	default:
+		return fmt.Sprintf("The tip of %s to @%s could not be sent. The royal treasurer has been informed.", amount, tip.RecipientUsername)
+	}
+}
+
+// tipEventData describes a tip for event subscribers
+func tipEventData(tip *memory.Tip) map[string]interface{} {
+	return map[string]interface{}{
+		"tip_id":          tip.ID,
+		"tweet_id":        tip.TweetID,
+		"conversation_id": tip.ConversationID,
+		"sender_id":       tip.SenderID,
+		"recipient_id":    tip.RecipientID,
+		"network":         tip.Network,
+		"symbol":          tip.Symbol,
+		"amount":          tip.AmountUnits,
+		"status":          tip.Status,
+		"reason":          tip.Reason,
+		"tx_hash":         tip.TxHash,
+	}
+}
+
+// formatUnits renders whole tokens without trailing zeros
+func formatUnits(units float64) string {
+	return fmt.Sprintf("%g", units)
+}
+
+// capitalize upper-cases the first letter of a message
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
@@ -0,0 +1,241 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// Categories of the content calendar
+const (
+	TopicCategoryDrama   = "drama"
+	TopicCategoryTrend   = "trend"
+	TopicCategoryToken   = "token"
+	TopicCategorySegment = "segment"
+)
+
+const (
+	// trendingHashtagLimit is how many trending hashtags are offered as topics
+	trendingHashtagLimit = 5
+	// segmentCooldown keeps a recurring segment from running twice on its day
+	segmentCooldown = 20 * time.Hour
+)
+
+// CalendarSlot is one entry of the content calendar
+type CalendarSlot struct {
+	Category string
+	// Topics are tried in order; trend and token slots also offer the current
+	// trending hashtags and watchlist tokens ahead of them
+	Topics []string
+	// Weekdays makes the slot a recurring segment: on these days its topics
+	// take precedence over the rotation
+	Weekdays []time.Weekday
+}
+
+// DefaultContentCalendar is the Cat Lord's rotation of drama categories,
+// trends, token updates and recurring segments
+func DefaultContentCalendar() []CalendarSlot {
+	return []CalendarSlot{
+		{
+			Category: TopicCategoryDrama,
+			Topics: []string{
+				"exchange implosions",
+				"founder escapades",
+				"regulatory hide and seek",
+				"network downtimes",
+				"token drama",
+				"memecoin migrations",
+			},
+		},
+		{
+			Category: TopicCategoryTrend,
+			Topics:   []string{"what the peasants are obsessing over today"},
+		},
+		{
+			Category: TopicCategoryToken,
+			Topics:   []string{"$LAFFY and the royal treasury"},
+		},
+		{
+			Category: TopicCategorySegment,
+			Topics:   []string{"Monday Market Meowdown: the week ahead in crypto"},
+			Weekdays: []time.Weekday{time.Monday},
+		},
+		{
+			Category: TopicCategorySegment,
+			Topics:   []string{"Caturday Court: the week's most judgeable moments"},
+			Weekdays: []time.Weekday{time.Saturday},
+		},
+	}
+}
+
+// PlannedTopic is the topic chosen for the next original thought
+type PlannedTopic struct {
+	Category string
+	Topic    string
+}
+
+// TopicPlannerOptions configures the topic planner
+type TopicPlannerOptions struct {
+	// Calendar defaults to DefaultContentCalendar
+	Calendar []CalendarSlot
+	// AvoidDays is how long a posted topic is not repeated; defaults to 7
+	AvoidDays int
+	// Tokens are the watchlist symbols offered as token updates
+	Tokens []string
+	// TrendWindow is how far back stored tweets are scanned for trending
+	// hashtags; defaults to 24 hours
+	TrendWindow time.Duration
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// TopicPlanner picks the topic of each original thought from a rotating
+// content calendar. Categories take turns, recurring segments run on their
+// weekdays, and topics posted in the last AvoidDays are skipped while there
+// is anything else to talk about.
+type TopicPlanner struct {
+	store   *memory.TweetStore
+	logger  *logrus.Logger
+	options TopicPlannerOptions
+}
+
+// NewTopicPlanner creates a planner that remembers posted topics in store
+func NewTopicPlanner(store *memory.TweetStore, logger *logrus.Logger, options TopicPlannerOptions) *TopicPlanner {
+	if len(options.Calendar) == 0 {
+		options.Calendar = DefaultContentCalendar()
+	}
+	if options.AvoidDays <= 0 {
+		options.AvoidDays = 7
+	}
+	if options.TrendWindow <= 0 {
+		options.TrendWindow = 24 * time.Hour
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	return &TopicPlanner{
+		store:   store,
+		logger:  logger,
+		options: options,
+	}
+}
+
+// Next selects the topic for the next thought
+func (p *TopicPlanner) Next(ctx context.Context) (PlannedTopic, error) {
+	now := p.options.Now()
+
+	recent, err := p.store.RecentThoughtTopics(ctx, now.AddDate(0, 0, -p.options.AvoidDays))
+	if err != nil {
+		return PlannedTopic{}, err
+	}
+	postedAt := make(map[string]time.Time, len(recent))
+	for _, record := range recent {
+		key := strings.ToLower(record.Topic)
+		if _, ok := postedAt[key]; !ok {
+			postedAt[key] = record.PostedAt
+		}
+	}
+
+	// A segment due today runs unless it already did
+	for _, slot := range p.options.Calendar {
+		if !slices.Contains(slot.Weekdays, now.Weekday()) {
+			continue
+		}
+		for _, topic := range slot.Topics {
+			if at, ok := postedAt[strings.ToLower(topic)]; !ok || now.Sub(at) >= segmentCooldown {
+				return PlannedTopic{Category: slot.Category, Topic: topic}, nil
+			}
+		}
+	}
+
+	rotation, err := p.rotation(ctx)
+	if err != nil {
+		return PlannedTopic{}, err
+	}
+
+	var fallback *PlannedTopic
+	var fallbackAt time.Time
+	for _, slot := range rotation {
+		for _, topic := range p.candidates(ctx, slot, now) {
+			at, posted := postedAt[strings.ToLower(topic)]
+			if !posted {
+				return PlannedTopic{Category: slot.Category, Topic: topic}, nil
+			}
+			if fallback == nil || at.Before(fallbackAt) {
+				fallback = &PlannedTopic{Category: slot.Category, Topic: topic}
+				fallbackAt = at
+			}
+		}
+	}
+
+	// Everything was covered recently; repeat the stalest topic
+	if fallback != nil {
+		return *fallback, nil
+	}
+	return PlannedTopic{}, fmt.Errorf("content calendar has no topics")
+}
+
+// Posted records that tweet was posted about topic
+func (p *TopicPlanner) Posted(ctx context.Context, topic PlannedTopic, tweet *twitter.Tweet) error {
+	return p.store.SaveThoughtTopic(ctx, &memory.ThoughtTopicRecord{
+		Category: topic.Category,
+		Topic:    topic.Topic,
+		TweetID:  tweet.ID,
+		Text:     tweet.Text,
+		PostedAt: p.options.Now(),
+	})
+}
+
+// rotation returns the calendar slots outside recurring segments, starting
+// after the category of the last posted topic
+func (p *TopicPlanner) rotation(ctx context.Context) ([]CalendarSlot, error) {
+	var slots []CalendarSlot
+	for _, slot := range p.options.Calendar {
+		if len(slot.Weekdays) == 0 {
+			slots = append(slots, slot)
+		}
+	}
+
+	last, err := p.store.LastThoughtTopic(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return slots, nil
+	}
+
+	start := 0
+	for i, slot := range slots {
+		if slot.Category == last.Category {
+			start = i + 1
+		}
+	}
+	start %= max(len(slots), 1)
+	return append(slots[start:], slots[:start]...), nil
+}
+
+// candidates returns the topics a slot offers right now
+func (p *TopicPlanner) candidates(ctx context.Context, slot CalendarSlot, now time.Time) []string {
+	var topics []string
+	switch slot.Category {
+	case TopicCategoryTrend:
+		hashtags, err := p.store.TrendingHashtags(ctx, now.Add(-p.options.TrendWindow), trendingHashtagLimit)
+		if err != nil {
+			p.logger.WithContext(ctx).WithError(err).Warn("Failed to load trending hashtags for topic planning")
+		}
+		for _, hashtag := range hashtags {
+			topics = append(topics, fmt.Sprintf("the %s trend", hashtag))
+		}
+	case TopicCategoryToken:
+		for _, token := range p.options.Tokens {
+			topics = append(topics, fmt.Sprintf("today's $%s price action", strings.ToUpper(strings.TrimPrefix(token, "$"))))
+		}
+	}
+	return append(topics, slot.Topics...)
+}
@@ -0,0 +1,174 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultThankCooldown is how long a sender waits for another thank-you
+const DefaultThankCooldown = 24 * time.Hour
+
+// thankTemplates are filled with the sender, the amount and the token symbol
+var thankTemplates = []string{
+	"%s has paid tribute of %s %s to the cat lord. The peasant may continue to exist. 🐾",
+	"The royal treasury grows. %s offered %s %s and has earned one (1) slow blink. 😼",
+	"%s sent %s %s to the cat lord. Acceptable. Your name will be remembered until my next nap. 👑",
+}
+
+// TransferWatchOptions configures the incoming transfer watch
+type TransferWatchOptions struct {
+	// Watch is passed to the wallet's transfer watcher; its OnTransfer is
+	// replaced by the action
+	Watch wallet.WatchOptions
+	// Thank tweets a thank-you for received transfers; when false transfers
+	// are only published as events
+	Thank bool
+	// MinAmount is the smallest transfer, in whole tokens, worth a thank-you
+	MinAmount float64
+	// Cooldown keeps a sender from being thanked more than once per period
+	Cooldown time.Duration
+	// Schedule holds thank-yous for transfers received during quiet hours
+	// and embargoes until posting opens again; nil thanks at any time
+	Schedule *schedule.Policy
+}
+
+// TransferWatchAction watches the wallet for incoming token transfers, which
+// the wallet publishes on the event bus, and optionally thanks the senders
+// publicly
+type TransferWatchAction struct {
+	wallet  *wallet.Client
+	client  *twitter.TwitterClient
+	logger  *logrus.Logger
+	options TransferWatchOptions
+
+	mu         sync.Mutex
+	lastThanks map[common.Address]time.Time
+}
+
+// NewTransferWatchAction creates a new incoming transfer watch
+func NewTransferWatchAction(walletClient *wallet.Client, client *twitter.TwitterClient, logger *logrus.Logger, options TransferWatchOptions) *TransferWatchAction {
+	if options.Cooldown <= 0 {
+		options.Cooldown = DefaultThankCooldown
+	}
+	return &TransferWatchAction{
+		wallet:     walletClient,
+		client:     client,
+		logger:     logger,
+		options:    options,
+		lastThanks: make(map[common.Address]time.Time),
+	}
+}
+
+// Name implements the Action interface
+func (t *TransferWatchAction) Name() string {
+	return "transfer_watch"
+}
+
+// Execute implements the Action interface
+func (t *TransferWatchAction) Execute(ctx context.Context) error {
+	log := t.logger.WithContext(ctx).WithField("action", t.Name())
+	log.WithField("thank", t.options.Thank).Info("Starting incoming transfer watch")
+
+	watch := t.options.Watch
+	watch.OnTransfer = func(ctx context.Context, transfer wallet.IncomingTransfer) {
+		t.handleTransfer(logging.StartTrace(ctx), transfer)
+	}
+	err := t.wallet.WatchIncomingTransfers(ctx, watch)
+	if ctx.Err() != nil {
+		log.Info("Incoming transfer watch stopped")
+		return ctx.Err()
+	}
+	return fmt.Errorf("failed to watch incoming transfers: %w", err)
+}
+
+// Stop implements the Action interface
+func (t *TransferWatchAction) Stop() {
+	t.logger.WithField("action", t.Name()).Info("Stopping incoming transfer watch")
+}
+
+// handleTransfer thanks the sender of a transfer if it is large enough and
+// the sender was not thanked recently
+func (t *TransferWatchAction) handleTransfer(ctx context.Context, transfer wallet.IncomingTransfer) {
+	if !t.options.Thank {
+		return
+	}
+
+	log := t.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"action":  t.Name(),
+		"network": transfer.Network,
+		"from":    transfer.From.Hex(),
+		"hash":    transfer.TxHash.Hex(),
+	})
+
+	amount, err := strconv.ParseFloat(transfer.FormattedAmount(), 64)
+	if err != nil || amount < t.options.MinAmount || amount == 0 {
+		log.WithField("amount", transfer.FormattedAmount()).Debug("Transfer too small to thank")
+		return
+	}
+	if !t.claimThanks(transfer.From) {
+		log.Debug("Sender was thanked recently")
+		return
+	}
+
+	symbol := transfer.Symbol
+	if symbol == "" {
+		symbol = "tokens"
+	}
+	sender := t.wallet.DisplayAddress(ctx, transfer.From)
+	text := fmt.Sprintf(thankTemplates[rand.Intn(len(thankTemplates))], sender, transfer.FormattedAmount(), symbol)
+
+	if now := time.Now(); !t.options.Schedule.Open(now) {
+		// Wait in the background so the watch keeps publishing transfers
+		log.WithField("until", t.options.Schedule.NextOpen(now)).Info("Holding thank-you until posting opens")
+		go func() {
+			if err := t.options.Schedule.Wait(ctx); err != nil {
+				t.releaseThanks(transfer.From)
+				return
+			}
+			t.postThanks(ctx, log, transfer.From, text)
+		}()
+		return
+	}
+	t.postThanks(ctx, log, transfer.From, text)
+}
+
+// postThanks tweets a thank-you to sender, letting them be thanked again if
+// it fails
+func (t *TransferWatchAction) postThanks(ctx context.Context, log *logrus.Entry, sender common.Address, text string) {
+	tweet, err := t.client.PostTweet(ctx, text, nil)
+	if err != nil {
+		log.WithError(err).Error("Failed to post thank-you tweet")
+		t.releaseThanks(sender)
+		return
+	}
+	log.WithField("tweet_id", tweet.ID).Info("Thanked sender of incoming transfer")
+}
+
+// claimThanks reserves a thank-you for sender unless the cooldown is running
+func (t *TransferWatchAction) claimThanks(sender common.Address) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.lastThanks[sender]; ok && time.Since(last) < t.options.Cooldown {
+		return false
+	}
+	t.lastThanks[sender] = time.Now()
+	return true
+}
+
+// releaseThanks lets a sender be thanked again after a failed post
+func (t *TransferWatchAction) releaseThanks(sender common.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.lastThanks, sender)
+}
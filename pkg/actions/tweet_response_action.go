@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
 	"github.com/sirupsen/logrus"
 )
 
@@ -40,7 +41,7 @@ func (t *TweetResponseAction) Name() string {
 
 // Execute implements the Action interface
 func (t *TweetResponseAction) Execute(ctx context.Context) error {
-	log := t.logger.WithField("action", t.Name())
+	log := t.logger.WithContext(ctx).WithField("action", t.Name())
 
 	ticker := time.NewTicker(t.options.Interval)
 	defer ticker.Stop()
@@ -53,8 +54,9 @@ func (t *TweetResponseAction) Execute(ctx context.Context) error {
 			log.Info("Tweet response action stopped")
 			return ctx.Err()
 		case <-ticker.C:
-			if err := t.responder.ProcessTweetsInBatches(ctx, t.options.BatchConfig); err != nil {
-				log.WithError(err).Error("Failed to process tweets needing reply")
+			tick := logging.StartTrace(ctx)
+			if err := t.responder.ProcessTweetsInBatches(tick, t.options.BatchConfig); err != nil {
+				log.WithContext(tick).WithError(err).Error("Failed to process tweets needing reply")
 				// Continue running even if we encounter an error
 				continue
 			}
@@ -67,3 +69,8 @@ func (t *TweetResponseAction) Stop() {
 	log := t.logger.WithField("action", t.Name())
 	log.Info("Stopping tweet response action")
 }
+
+// SetInterval implements the IntervalSetter interface
+func (t *TweetResponseAction) SetInterval(interval time.Duration) {
+	t.options.Interval = interval
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+)
+
+// ComplianceHandler applies Twitter compliance events to an account's store
+type ComplianceHandler interface {
+	Apply(ctx context.Context, events []twitter.Compliance) (int64, error)
+}
+
+// complianceRequest is the body of a compliance POST request
+type complianceRequest struct {
+	Events []twitter.Compliance `json:"events"`
+}
+
+// HandleCompliance adds the compliance endpoint. With several accounts, the
+// account query parameter picks the account whose store is purged:
+//
+//	POST /compliance    {"events": [{"event_type": "delete", "tweet_id": "..."}]}
+//	                    soft-deletes and purges deleted tweets and the tweets
+//	                    of deleted, protected or suspended users
+func (s *Server) HandleCompliance(handlers map[string]ComplianceHandler) {
+	s.Handle("POST /compliance", func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := forAccount(w, r, handlers)
+		if !ok {
+			return
+		}
+
+		var request complianceRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+
+		changed, err := handler.Apply(r.Context(), request.Events)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"events":  len(request.Events),
+			"changed": changed,
+		})
+	})
+}
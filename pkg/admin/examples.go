@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// ExampleLibrary stores an account's curated reply examples
+type ExampleLibrary interface {
+	Add(ctx context.Context, tweet, reply, category string) (*memory.ReplyExample, error)
+	List(ctx context.Context) ([]memory.ReplyExample, error)
+	Delete(ctx context.Context, id int64) (bool, error)
+}
+
+// exampleRequest is the body of an example POST request
+type exampleRequest struct {
+	Tweet    string `json:"tweet"`
+	Reply    string `json:"reply"`
+	Category string `json:"category"`
+}
+
+// HandleExamples adds the reply example endpoints. With several accounts,
+// the account query parameter picks the account's examples:
+//
+//	GET /examples            every curated example
+//	POST /examples           {"tweet": "...", "reply": "...", "category": "quote"}
+//	                         adds an example; category is optional
+//	DELETE /examples/{id}    removes an example
+func (s *Server) HandleExamples(libraries map[string]ExampleLibrary) {
+	s.Handle("GET /examples", func(w http.ResponseWriter, r *http.Request) {
+		examples, ok := forAccount(w, r, libraries)
+		if !ok {
+			return
+		}
+		list, err := examples.List(r.Context())
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"examples": list})
+	})
+
+	s.Handle("POST /examples", func(w http.ResponseWriter, r *http.Request) {
+		examples, ok := forAccount(w, r, libraries)
+		if !ok {
+			return
+		}
+
+		var request exampleRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		if request.Tweet == "" || request.Reply == "" {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("tweet and reply are required"))
+			return
+		}
+
+		example, err := examples.Add(r.Context(), request.Tweet, request.Reply, request.Category)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		s.logger.WithContext(r.Context()).WithField("example_id", example.ID).Info("Reply example added through admin API")
+		WriteJSON(w, http.StatusCreated, example)
+	})
+
+	s.Handle("DELETE /examples/{id}", func(w http.ResponseWriter, r *http.Request) {
+		examples, ok := forAccount(w, r, libraries)
+		if !ok {
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid example id %q", r.PathValue("id")))
+			return
+		}
+		deleted, err := examples.Delete(r.Context(), id)
+		switch {
+		case err != nil:
+			WriteError(w, http.StatusInternalServerError, err)
+		case !deleted:
+			WriteError(w, http.StatusNotFound, fmt.Errorf("example %d not found", id))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// defaultLatencyDays is how many days of latencies are shown unless asked
+const defaultLatencyDays = 7
+
+// LatencyReporter returns an account's response latency percentiles per day
+type LatencyReporter interface {
+	DailyLatencies(ctx context.Context, since time.Time) ([]memory.DailyLatency, error)
+}
+
+// dayLatency is a day's latency percentiles with whether its p95 met the SLO
+type dayLatency struct {
+	memory.DailyLatency
+	WithinSLO *bool `json:"within_slo,omitempty"`
+}
+
+// HandleLatency adds the response latency endpoint. With several accounts,
+// the account query parameter picks the account:
+//
+//	GET /replies/latency    p50, p95, mean and max time from mention to
+//	                        reply for each UTC day, oldest first; days picks
+//	                        how many days back to go (7 by default). With an
+//	                        SLO, each day tells whether its p95 met it.
+func (s *Server) HandleLatency(reporters map[string]LatencyReporter, slo time.Duration) {
+	s.Handle("GET /replies/latency", func(w http.ResponseWriter, r *http.Request) {
+		reporter, ok := forAccount(w, r, reporters)
+		if !ok {
+			return
+		}
+
+		days := defaultLatencyDays
+		if raw := r.URL.Query().Get("days"); raw != "" {
+			var err error
+			if days, err = strconv.Atoi(raw); err != nil || days <= 0 {
+				WriteError(w, http.StatusBadRequest, fmt.Errorf("days must be a positive number, got %q", raw))
+				return
+			}
+		}
+
+		since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+		latencies, err := reporter.DailyLatencies(r.Context(), since)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		result := make([]dayLatency, 0, len(latencies))
+		for _, latency := range latencies {
+			day := dayLatency{DailyLatency: latency}
+			if slo > 0 {
+				within := latency.P95() <= slo
+				day.WithinSLO = &within
+			}
+			result = append(result, day)
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"slo_ms": slo.Milliseconds(),
+			"days":   result,
+		})
+	})
+}
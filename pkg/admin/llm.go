@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+)
+
+// UsageReporter reports the calls and tokens of each LLM model
+type UsageReporter interface {
+	Usage() []llm.ModelUsage
+}
+
+// HandleLLMUsage adds the LLM usage endpoint:
+//
+//	GET /llm/usage    calls, errors, tokens and estimated cost per model
+//	                  since the agent started
+func (s *Server) HandleLLMUsage(usage UsageReporter) {
+	s.Handle("GET /llm/usage", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"models": usage.Usage()})
+	})
+}
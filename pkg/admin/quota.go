@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// defaultQuotaHistory is how far back the quota history goes unless asked
+const defaultQuotaHistory = 24 * time.Hour
+
+// QuotaReporter reports an account's Twitter API consumption in the current
+// rate limit windows and budget shares
+type QuotaReporter interface {
+	Quota() []twitter.EndpointQuota
+	BudgetUsage() []twitter.BudgetUsage
+}
+
+// QuotaHistory returns the recorded rate limit windows of an account
+type QuotaHistory interface {
+	QuotaWindows(ctx context.Context, endpoint string, since time.Time) ([]memory.QuotaWindow, error)
+}
+
+// endpointQuota is an endpoint's current window with the share of its limit
+// used up
+type endpointQuota struct {
+	twitter.EndpointQuota
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// HandleQuota adds the quota dashboard endpoints. With several accounts, the
+// account query parameter picks the account:
+//
+//	GET /twitter/quota            each endpoint's limit, remaining requests
+//	                              and reset in the current window, closest to
+//	                              its limit first, and the budget shares
+//	GET /twitter/quota/history    recorded windows, newest first; endpoint
+//	                              picks one endpoint, e.g.
+//	                              "GET /2/users/:id/mentions", and since how
+//	                              far back to go (24h by default)
+func (s *Server) HandleQuota(clients map[string]QuotaReporter, history map[string]QuotaHistory) {
+	s.Handle("GET /twitter/quota", func(w http.ResponseWriter, r *http.Request) {
+		client, ok := forAccount(w, r, clients)
+		if !ok {
+			return
+		}
+
+		quotas := client.Quota()
+		endpoints := make([]endpointQuota, 0, len(quotas))
+		for _, quota := range quotas {
+			endpoints = append(endpoints, endpointQuota{
+				EndpointQuota: quota,
+				UsedPercent:   100 * quota.UsedFraction(),
+			})
+		}
+		sort.SliceStable(endpoints, func(i, j int) bool {
+			return endpoints[i].UsedPercent > endpoints[j].UsedPercent
+		})
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"endpoints": endpoints,
+			"budget":    client.BudgetUsage(),
+		})
+	})
+
+	s.Handle("GET /twitter/quota/history", func(w http.ResponseWriter, r *http.Request) {
+		store, ok := forAccount(w, r, history)
+		if !ok {
+			return
+		}
+
+		lookback := defaultQuotaHistory
+		if raw := r.URL.Query().Get("since"); raw != "" {
+			var err error
+			if lookback, err = time.ParseDuration(raw); err != nil || lookback <= 0 {
+				WriteError(w, http.StatusBadRequest, fmt.Errorf("since must be a positive duration such as 24h, got %q", raw))
+				return
+			}
+		}
+
+		windows, err := store.QuotaWindows(r.Context(), r.URL.Query().Get("endpoint"), time.Now().Add(-lookback))
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"windows": windows})
+	})
+}
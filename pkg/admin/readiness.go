@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+)
+
+// CredentialChecker reports the last known health of an account's Twitter
+// credentials
+type CredentialChecker interface {
+	Status() actions.CredentialStatus
+}
+
+// HandleReadiness adds the readiness endpoint:
+//
+//	GET /readyz    200 while every account's credentials work; 503 when an
+//	               account's credentials are revoked, expired or suspended,
+//	               with the state of each account
+func (s *Server) HandleReadiness(credentials map[string]CredentialChecker) {
+	s.Handle("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready := true
+		accounts := make(map[string]actions.CredentialStatus, len(credentials))
+		for name, checker := range credentials {
+			status := checker.Status()
+			accounts[name] = status
+			if !status.Healthy() {
+				ready = false
+			}
+		}
+
+		code := http.StatusOK
+		if !ready {
+			code = http.StatusServiceUnavailable
+		}
+		WriteJSON(w, code, map[string]interface{}{
+			"ready":    ready,
+			"accounts": accounts,
+		})
+	})
+}
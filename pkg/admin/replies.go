@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// ReplyRedoer replaces a posted reply of an account and lists the tweets
+// edited after they were answered
+type ReplyRedoer interface {
+	Redo(ctx context.Context, replyID, guidance string) (*twitter.Tweet, error)
+	EditedAfterReply(ctx context.Context) ([]memory.EditedTweet, error)
+}
+
+// redoRequest is the optional body of a redo request
+type redoRequest struct {
+	Guidance string `json:"guidance"`
+}
+
+// HandleReplies adds the reply endpoints. With several accounts, the account
+// query parameter picks the account that posted the reply:
+//
+//	POST /replies/{id}/redo    {"guidance": "..."}
+//	                           deletes the reply and posts a regenerated one;
+//	                           guidance is optional and added to the prompt
+//	GET /replies/edited        tweets edited after the bot replied to them
+func (s *Server) HandleReplies(redoers map[string]ReplyRedoer) {
+	s.Handle("GET /replies/edited", func(w http.ResponseWriter, r *http.Request) {
+		redoer, ok := forAccount(w, r, redoers)
+		if !ok {
+			return
+		}
+		edited, err := redoer.EditedAfterReply(r.Context())
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"edited": edited})
+	})
+
+	s.Handle("POST /replies/{id}/redo", func(w http.ResponseWriter, r *http.Request) {
+		redoer, ok := forAccount(w, r, redoers)
+		if !ok {
+			return
+		}
+
+		var request redoRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+
+		replyID := r.PathValue("id")
+		posted, err := redoer.Redo(r.Context(), replyID, request.Guidance)
+		switch {
+		case errors.Is(err, memory.ErrReplyNotFound):
+			WriteError(w, http.StatusNotFound, err)
+			return
+		case err != nil && posted == nil:
+			WriteError(w, http.StatusBadGateway, err)
+			return
+		case err != nil:
+			// The new reply is public even though the store was not updated
+			s.logger.WithContext(r.Context()).WithError(err).WithField("reply_id", posted.ID).Error("Failed to record replacement reply")
+		}
+
+		s.logger.WithContext(r.Context()).WithField("replaced", replyID).WithField("reply_id", posted.ID).Info("Reply redone through admin API")
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"replaced": replyID,
+			"reply":    posted,
+		})
+	})
+}
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// defaultStagesHistory is how far back the reply stages go unless asked
+const defaultStagesHistory = 24 * time.Hour
+
+// ReplyStageLog returns the draft, critique and revision behind an account's
+// replies in self-critique mode
+type ReplyStageLog interface {
+	ReplyStagesSince(ctx context.Context, since time.Time) ([]memory.ReplyStages, error)
+}
+
+// HandleReplyStages adds the reply stages endpoint. With several accounts,
+// the account query parameter picks the account:
+//
+//	GET /replies/stages    draft, critique and revision of each reply posted
+//	                       in self-critique mode, newest first; since picks
+//	                       how far back to go (24h by default)
+func (s *Server) HandleReplyStages(logs map[string]ReplyStageLog) {
+	s.Handle("GET /replies/stages", func(w http.ResponseWriter, r *http.Request) {
+		store, ok := forAccount(w, r, logs)
+		if !ok {
+			return
+		}
+
+		lookback := defaultStagesHistory
+		if raw := r.URL.Query().Get("since"); raw != "" {
+			var err error
+			if lookback, err = time.ParseDuration(raw); err != nil || lookback <= 0 {
+				WriteError(w, http.StatusBadRequest, fmt.Errorf("since must be a positive duration such as 24h, got %q", raw))
+				return
+			}
+		}
+
+		stages, err := store.ReplyStagesSince(r.Context(), time.Now().Add(-lookback))
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"replies": stages})
+	})
+}
@@ -0,0 +1,122 @@
+// Package admin serves the operator HTTP API used to inspect and manage a
+// running agent.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// shutdownTimeout bounds how long in-flight requests may finish on shutdown
+const shutdownTimeout = 5 * time.Second
+
+// Server is the admin HTTP API. Routes are added with Handle before Run.
+type Server struct {
+	addr   string
+	token  string
+	logger *logrus.Logger
+	mux    *http.ServeMux
+}
+
+// NewServer creates an admin API listening on the configured address
+func NewServer(settings config.AdminConfig, logger *logrus.Logger) *Server {
+	return &Server{
+		addr:   settings.Addr,
+		token:  settings.Token,
+		logger: logger,
+		mux:    http.NewServeMux(),
+	}
+}
+
+// Handle registers a handler for a ServeMux pattern, e.g. "GET /tasks"
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// ServeHTTP serves a request after checking the bearer token. Each request
+// starts a trace, so handlers log through WithContext(r.Context()).
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid admin token"))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r.WithContext(logging.StartTrace(r.Context())))
+}
+
+// Run serves the API until the context is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.logger.WithError(err).Warn("Admin API did not shut down cleanly")
+		}
+	}()
+
+	s.logger.WithField("addr", s.addr).Info("Starting admin API")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve admin API: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes v as a JSON response
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes err as a JSON error response
+func WriteError(w http.ResponseWriter, status int, err error) {
+	WriteJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// forAccount picks the handler of the account named by the account query
+// parameter, which may be left out when there is only one account. It writes
+// the error response itself when the account is missing or unknown.
+func forAccount[T any](w http.ResponseWriter, r *http.Request, accounts map[string]T) (T, bool) {
+	name := r.URL.Query().Get("account")
+	if name == "" && len(accounts) == 1 {
+		for _, only := range accounts {
+			return only, true
+		}
+	}
+	if handler, ok := accounts[name]; ok {
+		return handler, true
+	}
+
+	if name == "" {
+		names := make([]string, 0, len(accounts))
+		for account := range accounts {
+			names = append(names, account)
+		}
+		sort.Strings(names)
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("account is required, one of %v", names))
+	} else {
+		WriteError(w, http.StatusNotFound, fmt.Errorf("account %q not found", name))
+	}
+	var none T
+	return none, false
+}
@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	agent "github.com/lisanmuaddib/agent-go/pkg"
+	"github.com/sirupsen/logrus"
+)
+
+// TaskSupervisor reports on and switches the agent's supervised tasks
+type TaskSupervisor interface {
+	TaskHealth() []agent.TaskHealth
+	EnableTask(name string) error
+	DisableTask(name string) error
+}
+
+// taskUpdate is the body of a task PATCH request
+type taskUpdate struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// HandleTasks adds the task endpoints:
+//
+//	GET /tasks           every task with its state and restart count
+//	GET /tasks/{name}    a single task; names may contain an account prefix
+//	PATCH /tasks/{name}  {"enabled": false} stops a task, {"enabled": true}
+//	                     starts it again with its settings re-read
+func (s *Server) HandleTasks(tasks TaskSupervisor) {
+	s.Handle("GET /tasks", func(w http.ResponseWriter, r *http.Request) {
+		health := tasks.TaskHealth()
+		healthy := 0
+		for _, task := range health {
+			if task.Healthy() {
+				healthy++
+			}
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"tasks":   health,
+			"healthy": healthy,
+			"total":   len(health),
+		})
+	})
+
+	s.Handle("GET /tasks/{name...}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		for _, task := range tasks.TaskHealth() {
+			if task.Name == name {
+				WriteJSON(w, http.StatusOK, task)
+				return
+			}
+		}
+		WriteError(w, http.StatusNotFound, fmt.Errorf("task %q not found", name))
+	})
+
+	s.Handle("PATCH /tasks/{name...}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		var update taskUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		if update.Enabled == nil {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("enabled is required"))
+			return
+		}
+
+		var err error
+		if *update.Enabled {
+			err = tasks.EnableTask(name)
+		} else {
+			err = tasks.DisableTask(name)
+		}
+		switch {
+		case errors.Is(err, agent.ErrTaskNotFound):
+			WriteError(w, http.StatusNotFound, err)
+			return
+		case err != nil:
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+			"task":    name,
+			"enabled": *update.Enabled,
+		}).Info("Task switched through admin API")
+
+		for _, task := range tasks.TaskHealth() {
+			if task.Name == name {
+				WriteJSON(w, http.StatusOK, task)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
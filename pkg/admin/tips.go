@@ -0,0 +1,169 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// TipManager approves held tips and manages who may send tips
+type TipManager interface {
+	PendingTips(ctx context.Context) ([]memory.Tip, error)
+	Approve(ctx context.Context, id int64) (*memory.Tip, error)
+	Reject(ctx context.Context, id int64, reason string) (*memory.Tip, error)
+	Roles(ctx context.Context) ([]memory.CommandRole, error)
+	GrantTipper(ctx context.Context, userID string) error
+	RevokeTipper(ctx context.Context, userID string) (bool, error)
+}
+
+// rejectRequest is the body of a tip reject request
+type rejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// HandleTips adds the tip endpoints. With several accounts, the account query
+// parameter picks the account:
+//
+//	GET /tips/pending              tips held for approval, oldest first
+//	POST /tips/{id}/approve        sends a held tip and replies to its command
+//	POST /tips/{id}/reject         {"reason": "..."} refuses a held tip and
+//	                               replies to its command; reason is optional
+//	GET /tips/roles                the users who may send tips
+//	PUT /tips/roles/{user_id}      lets a user send tips
+//	DELETE /tips/roles/{user_id}   stops a user from sending tips
+func (s *Server) HandleTips(managers map[string]TipManager) {
+	if len(managers) == 0 {
+		return
+	}
+
+	s.Handle("GET /tips/pending", func(w http.ResponseWriter, r *http.Request) {
+		manager, ok := forAccount(w, r, managers)
+		if !ok {
+			return
+		}
+		tips, err := manager.PendingTips(r.Context())
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"tips": tips})
+	})
+
+	s.Handle("POST /tips/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+		manager, ok := forAccount(w, r, managers)
+		if !ok {
+			return
+		}
+		id, ok := tipID(w, r)
+		if !ok {
+			return
+		}
+
+		tip, err := manager.Approve(r.Context(), id)
+		if writeTipError(w, err) {
+			return
+		}
+		s.logger.WithContext(r.Context()).WithField("tip_id", id).WithField("status", tip.Status).Info("Tip approved through admin API")
+		WriteJSON(w, http.StatusOK, tip)
+	})
+
+	s.Handle("POST /tips/{id}/reject", func(w http.ResponseWriter, r *http.Request) {
+		manager, ok := forAccount(w, r, managers)
+		if !ok {
+			return
+		}
+		id, ok := tipID(w, r)
+		if !ok {
+			return
+		}
+
+		var request rejectRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		tip, err := manager.Reject(r.Context(), id, request.Reason)
+		if writeTipError(w, err) {
+			return
+		}
+		s.logger.WithContext(r.Context()).WithField("tip_id", id).Info("Tip rejected through admin API")
+		WriteJSON(w, http.StatusOK, tip)
+	})
+
+	s.Handle("GET /tips/roles", func(w http.ResponseWriter, r *http.Request) {
+		manager, ok := forAccount(w, r, managers)
+		if !ok {
+			return
+		}
+		roles, err := manager.Roles(r.Context())
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"roles": roles})
+	})
+
+	s.Handle("PUT /tips/roles/{user_id}", func(w http.ResponseWriter, r *http.Request) {
+		manager, ok := forAccount(w, r, managers)
+		if !ok {
+			return
+		}
+		userID := r.PathValue("user_id")
+		if err := manager.GrantTipper(r.Context(), userID); err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		s.logger.WithContext(r.Context()).WithField("user_id", userID).Info("Tipper role granted through admin API")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.Handle("DELETE /tips/roles/{user_id}", func(w http.ResponseWriter, r *http.Request) {
+		manager, ok := forAccount(w, r, managers)
+		if !ok {
+			return
+		}
+		userID := r.PathValue("user_id")
+		revoked, err := manager.RevokeTipper(r.Context(), userID)
+		switch {
+		case err != nil:
+			WriteError(w, http.StatusInternalServerError, err)
+		case !revoked:
+			WriteError(w, http.StatusNotFound, fmt.Errorf("user %s is not a tipper", userID))
+		default:
+			s.logger.WithContext(r.Context()).WithField("user_id", userID).Info("Tipper role revoked through admin API")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+// tipID parses the tip ID of a request's path, answering 400 when it is invalid
+func tipID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid tip id %q", r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// writeTipError answers a failed approval or rejection, reporting whether
+// there was an error
+func writeTipError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, memory.ErrTipNotFound):
+		WriteError(w, http.StatusNotFound, err)
+	case errors.Is(err, memory.ErrTipNotPending):
+		WriteError(w, http.StatusConflict, err)
+	default:
+		WriteError(w, http.StatusInternalServerError, err)
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// TweetBrowser pages through an account's stored tweets
+type TweetBrowser interface {
+	GetTweetsByCategory(ctx context.Context, category memory.TweetCategory, options memory.TweetPageOptions) (*memory.TweetPage, error)
+	GetConversation(ctx context.Context, conversationID string, options memory.TweetPageOptions) (*memory.TweetPage, error)
+}
+
+// HandleTweets adds the stored tweet endpoints. With several accounts, the
+// account query parameter picks the account. Both take limit (100, at most
+// 1000), after (the next cursor of the previous page), since and until
+// (RFC 3339) and order (asc or desc):
+//
+//	GET /tweets?category=mention              stored tweets of a category
+//	GET /conversations/{id}/tweets            stored tweets of a conversation
+func (s *Server) HandleTweets(browsers map[string]TweetBrowser) {
+	s.Handle("GET /tweets", func(w http.ResponseWriter, r *http.Request) {
+		browser, ok := forAccount(w, r, browsers)
+		if !ok {
+			return
+		}
+		category := r.URL.Query().Get("category")
+		if category == "" {
+			WriteError(w, http.StatusBadRequest, errors.New("category is required"))
+			return
+		}
+		options, err := pageOptions(r.URL.Query())
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		page, err := browser.GetTweetsByCategory(r.Context(), memory.TweetCategory(category), options)
+		writePage(w, page, err)
+	})
+
+	s.Handle("GET /conversations/{id}/tweets", func(w http.ResponseWriter, r *http.Request) {
+		browser, ok := forAccount(w, r, browsers)
+		if !ok {
+			return
+		}
+		options, err := pageOptions(r.URL.Query())
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		page, err := browser.GetConversation(r.Context(), r.PathValue("id"), options)
+		writePage(w, page, err)
+	})
+}
+
+// pageOptions reads the paging query parameters
+func pageOptions(query url.Values) (memory.TweetPageOptions, error) {
+	options := memory.TweetPageOptions{
+		After: query.Get("after"),
+		Order: memory.TweetOrder(query.Get("order")),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return options, fmt.Errorf("limit must be a number, got %q", raw)
+		}
+		options.Limit = limit
+	}
+	for name, target := range map[string]*time.Time{"since": &options.Since, "until": &options.Until} {
+		if raw := query.Get(name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return options, fmt.Errorf("%s must be an RFC 3339 time, got %q", name, raw)
+			}
+			*target = parsed
+		}
+	}
+	return options, options.Validate()
+}
+
+// writePage answers with a page of tweets, or with 400 for a cursor that no
+// longer names a stored tweet
+func writePage(w http.ResponseWriter, page *memory.TweetPage, err error) {
+	switch {
+	case errors.Is(err, memory.ErrInvalidCursor):
+		WriteError(w, http.StatusBadRequest, err)
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, err)
+	default:
+		WriteJSON(w, http.StatusOK, page)
+	}
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+)
+
+// NonceRepairer reconciles and repairs the wallet's persisted nonces
+type NonceRepairer interface {
+	ReconcileNonces(ctx context.Context) ([]wallet.NonceReport, error)
+	RepairNonces(ctx context.Context, network wallet.NetworkType) (*wallet.NonceReport, error)
+}
+
+// HandleWallet adds the wallet endpoints:
+//
+//	GET /wallet/nonces                    reconciles the persisted nonces of every
+//	                                      network and reports pending nonces and gaps
+//	POST /wallet/nonces/{network}/repair  fills the gaps of a network and speeds
+//	                                      up a stuck transaction blocking the rest
+func (s *Server) HandleWallet(nonces NonceRepairer) {
+	s.Handle("GET /wallet/nonces", func(w http.ResponseWriter, r *http.Request) {
+		reports, err := nonces.ReconcileNonces(r.Context())
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"networks": reports})
+	})
+
+	s.Handle("POST /wallet/nonces/{network}/repair", func(w http.ResponseWriter, r *http.Request) {
+		network := wallet.NetworkType(r.PathValue("network"))
+		report, err := nonces.RepairNonces(r.Context(), network)
+		switch {
+		case wallet.IsWalletError(err, wallet.ErrCodeInvalidNetwork):
+			WriteError(w, http.StatusNotFound, fmt.Errorf("network %q is not configured", network))
+			return
+		case err != nil && report == nil:
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		case err != nil:
+			// Some repairs may have been sent before the failure
+			WriteJSON(w, http.StatusBadGateway, map[string]interface{}{"error": err.Error(), "report": report})
+			return
+		}
+
+		s.logger.WithContext(r.Context()).WithField("network", network).WithField("filled", len(report.Filled)).
+			Info("Wallet nonces repaired through admin API")
+		WriteJSON(w, http.StatusOK, report)
+	})
+}
@@ -2,22 +2,40 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
 	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
 	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/tools"
 	"github.com/sirupsen/logrus"
 	"github.com/tmc/langchaingo/llms"
 )
 
 type Agent struct {
-	client  *twitter.TwitterClient
-	llm     llms.Model
-	logger  *logrus.Logger
-	actions map[string]actions.Action
-	mu      sync.RWMutex
+	client   *twitter.TwitterClient
+	llm      llms.Model
+	logger   *logrus.Logger
+	actions  map[string]actions.Action
+	tasks    map[string]*task
+	policies config.TasksConfig
+	reload   func() (config.TasksConfig, error)
+	reasoner *Reasoner
+	mu       sync.RWMutex
+
+	// runCtx is the context passed to Run; tasks enabled later run under it.
+	// cancelRun cancels it when Stop is called.
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+	// running counts the tasks with a live supervisor; failures collects the
+	// errors of tasks that were given up on
+	running  int
+	failures []error
+	idle     chan struct{}
 }
 
 type Config struct {
@@ -25,6 +43,14 @@ type Config struct {
 	Logger        *logrus.Logger
 	TwitterClient *twitter.TwitterClient
 	TweetStore    *memory.TweetStore
+	// Tools enables the tool-calling reasoning loop when non-nil
+	Tools *tools.Registry
+	// Tasks sets whether each action runs, its interval and how it is
+	// restarted when it exits; unset values fall back to DefaultTaskPolicy
+	Tasks config.TasksConfig
+	// ReloadTasks re-reads the task settings when a task is enabled at
+	// runtime; nil keeps using Tasks
+	ReloadTasks func() (config.TasksConfig, error)
 }
 
 func New(config Config) (*Agent, error) {
@@ -38,16 +64,61 @@ func New(config Config) (*Agent, error) {
 		config.Logger = logrus.New()
 	}
 
-	return &Agent{
-		client:  config.TwitterClient,
-		llm:     config.LLM,
-		logger:  config.Logger,
-		actions: make(map[string]actions.Action),
-	}, nil
+	agent := &Agent{
+		client:   config.TwitterClient,
+		llm:      config.LLM,
+		logger:   config.Logger,
+		actions:  make(map[string]actions.Action),
+		tasks:    make(map[string]*task),
+		policies: config.Tasks,
+		reload:   config.ReloadTasks,
+		idle:     make(chan struct{}, 1),
+	}
+
+	if config.Tools != nil {
+		reasoner, err := NewReasoner(ReasonerConfig{
+			LLM:    config.LLM,
+			Tools:  config.Tools,
+			Logger: config.Logger,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create reasoner: %w", err)
+		}
+		agent.reasoner = reasoner
+	}
+
+	return agent, nil
 }
 
-// RegisterAction adds a new action to the agent
+// Reason runs the tool-calling reasoning loop for the given input
+func (a *Agent) Reason(ctx context.Context, input string) (string, error) {
+	if a.reasoner == nil {
+		return "", fmt.Errorf("no tools configured for reasoning")
+	}
+	return a.reasoner.Reason(ctx, input)
+}
+
+// RegisterAction adds a new action to the agent with the settings configured
+// for its name: whether it starts enabled, its interval and its restart policy
 func (a *Agent) RegisterAction(action actions.Action) error {
+	settings := a.policies.For(action.Name())
+	if setter, ok := action.(actions.IntervalSetter); ok && settings.Interval > 0 {
+		setter.SetInterval(settings.Interval)
+	}
+	if err := a.RegisterActionWithPolicy(action, TaskPolicyFrom(settings)); err != nil {
+		return err
+	}
+	if !settings.IsEnabled() {
+		a.mu.Lock()
+		a.tasks[action.Name()].enabled = false
+		a.tasks[action.Name()].update(func(h *TaskHealth) { h.State = TaskDisabled })
+		a.mu.Unlock()
+	}
+	return nil
+}
+
+// RegisterActionWithPolicy adds a new action supervised with the given restart policy
+func (a *Agent) RegisterActionWithPolicy(action actions.Action, policy TaskPolicy) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -57,41 +128,74 @@ func (a *Agent) RegisterAction(action actions.Action) error {
 	}
 
 	a.actions[name] = action
+	a.tasks[name] = newTask(action, policy)
 	return nil
 }
 
-// Run starts all registered actions
+// TaskHealth returns the state of every supervised action, sorted by name
+func (a *Agent) TaskHealth() []TaskHealth {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	health := make([]TaskHealth, 0, len(a.tasks))
+	for _, t := range a.tasks {
+		health = append(health, t.snapshot())
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Name < health[j].Name
+	})
+	return health
+}
+
+// Run starts all enabled actions under supervision. An action that exits is
+// restarted according to its policy; the others keep running. Run returns
+// when the context is cancelled or once every running action has ended by
+// itself. Actions disabled through DisableTask do not count as ended.
 func (a *Agent) Run(ctx context.Context) error {
 	a.logger.Info("Starting agent with registered actions")
 
-	// Create error channel for collecting errors from actions
-	errChan := make(chan error, len(a.actions))
-
-	// Start each action in its own goroutine
-	var wg sync.WaitGroup
-	for name, action := range a.actions {
-		wg.Add(1)
-		go func(name string, action actions.Action) {
-			defer wg.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-			a.logger.WithField("action", name).Info("Starting action")
-			if err := action.Execute(ctx); err != nil {
-				a.logger.WithError(err).WithField("action", name).Error("Action failed")
-				errChan <- fmt.Errorf("action %s failed: %w", name, err)
-			}
-		}(name, action)
+	a.mu.Lock()
+	a.runCtx = ctx
+	a.cancelRun = cancel
+	for _, t := range a.tasks {
+		if t.enabled {
+			a.startTask(t)
+		}
 	}
+	if a.running == 0 {
+		a.logger.Warn("No actions enabled; waiting for one to be enabled")
+	}
+	a.mu.Unlock()
 
-	// Wait for context cancellation or errors
+	// Wait for context cancellation or for every action to end
 	select {
 	case <-ctx.Done():
 		a.logger.Info("Context cancelled, stopping all actions")
 		a.stopAllActions()
 		return ctx.Err()
-	case err := <-errChan:
-		a.logger.WithError(err).Error("Action error occurred")
-		a.stopAllActions()
-		return err
+	case <-a.idle:
+		a.mu.RLock()
+		err := errors.Join(a.failures...)
+		a.mu.RUnlock()
+		if err != nil {
+			return fmt.Errorf("no actions left running: %w", err)
+		}
+		a.logger.Info("All actions exited")
+		return nil
+	}
+}
+
+// Stop ends a running agent: every action is stopped and Run returns
+// context.Canceled. It does nothing when the agent is not running.
+func (a *Agent) Stop() {
+	a.mu.RLock()
+	cancel := a.cancelRun
+	a.mu.RUnlock()
+	if cancel != nil {
+		cancel()
 	}
 }
 
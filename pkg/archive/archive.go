@@ -0,0 +1,178 @@
+// Package archive publishes completed conversations of the bot as JSON
+// documents, pinned to IPFS or uploaded to S3, giving a verifiable public
+// record of its interactions. Where each conversation was published is kept
+// in the tweet store.
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// DocumentVersion is the version of the archived document format
+const DocumentVersion = 1
+
+// Default archiver settings
+const (
+	// DefaultQuietPeriod is how long a conversation must go without new tweets
+	// before it counts as completed
+	DefaultQuietPeriod = 24 * time.Hour
+	// DefaultBatchSize caps the conversations archived per run
+	DefaultBatchSize = 20
+)
+
+// Document is the archived form of a conversation
+type Document struct {
+	Version        int       `json:"version"`
+	BotID          string    `json:"bot_id"`
+	BotUsername    string    `json:"bot_username,omitempty"`
+	ConversationID string    `json:"conversation_id"`
+	ArchivedAt     time.Time `json:"archived_at"`
+	Tweets         []Tweet   `json:"tweets"`
+}
+
+// Tweet is a tweet or cast of an archived conversation
+type Tweet struct {
+	ID              string    `json:"id"`
+	AuthorID        string    `json:"author_id"`
+	AuthorUsername  string    `json:"author_username,omitempty"`
+	AuthorName      string    `json:"author_name,omitempty"`
+	Text            string    `json:"text"`
+	CreatedAt       time.Time `json:"created_at"`
+	InReplyToUserID string    `json:"in_reply_to_user_id,omitempty"`
+}
+
+// Options configures the Archiver
+type Options struct {
+	// QuietPeriod is how long a conversation must be inactive to be archived
+	QuietPeriod time.Duration
+	// BatchSize caps the conversations archived per run
+	BatchSize int
+}
+
+// Archiver exports completed conversations from a tweet store
+type Archiver struct {
+	store     *memory.TweetStore
+	publisher Publisher
+	logger    *logrus.Logger
+	options   Options
+}
+
+// New creates an Archiver publishing the conversations of store
+func New(store *memory.TweetStore, publisher Publisher, logger *logrus.Logger, options Options) *Archiver {
+	if options.QuietPeriod <= 0 {
+		options.QuietPeriod = DefaultQuietPeriod
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+
+	return &Archiver{
+		store:     store,
+		publisher: publisher,
+		logger:    logger,
+		options:   options,
+	}
+}
+
+// ArchiveCompleted publishes the conversations that have gone quiet since
+// they were last archived and returns how many were archived. A conversation
+// that fails is logged and retried on the next run.
+func (a *Archiver) ArchiveCompleted(ctx context.Context) (int, error) {
+	conversationIDs, err := a.store.ConversationsToArchive(ctx, time.Now().Add(-a.options.QuietPeriod), a.options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, conversationID := range conversationIDs {
+		if ctx.Err() != nil {
+			return archived, ctx.Err()
+		}
+		if _, err := a.ArchiveConversation(ctx, conversationID); err != nil {
+			a.logger.WithError(err).WithField("conversation_id", conversationID).Error("Failed to archive conversation")
+			continue
+		}
+		archived++
+	}
+	return archived, nil
+}
+
+// ArchiveConversation publishes a conversation and records where it went
+func (a *Archiver) ArchiveConversation(ctx context.Context, conversationID string) (*memory.ConversationArchive, error) {
+	stored, err := a.store.ConversationTweets(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("conversation %s has no stored tweets", conversationID)
+	}
+
+	document := Document{
+		Version:        DocumentVersion,
+		BotID:          a.store.BotID(),
+		BotUsername:    a.store.Username(),
+		ConversationID: conversationID,
+		ArchivedAt:     time.Now().UTC(),
+		Tweets:         make([]Tweet, 0, len(stored)),
+	}
+	lastTweetAt := stored[0].CreatedAt
+	for _, tweet := range stored {
+		document.Tweets = append(document.Tweets, Tweet{
+			ID:              tweet.TweetID,
+			AuthorID:        tweet.AuthorID,
+			AuthorUsername:  tweet.AuthorUsername,
+			AuthorName:      tweet.AuthorName,
+			Text:            tweet.Text,
+			CreatedAt:       tweet.CreatedAt.UTC(),
+			InReplyToUserID: tweet.InReplyToUserID,
+		})
+		if tweet.CreatedAt.After(lastTweetAt) {
+			lastTweetAt = tweet.CreatedAt
+		}
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	digest := sha256.Sum256(data)
+
+	location, err := a.publisher.Publish(ctx, documentKey(document.BotID, conversationID), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish conversation: %w", err)
+	}
+
+	record := &memory.ConversationArchive{
+		ConversationID: conversationID,
+		TweetCount:     len(document.Tweets),
+		LastTweetAt:    lastTweetAt,
+		URI:            location.URI,
+		URL:            location.URL,
+		SHA256:         hex.EncodeToString(digest[:]),
+		ArchivedAt:     document.ArchivedAt,
+	}
+	if err := a.store.SaveConversationArchive(ctx, record); err != nil {
+		return nil, err
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"conversation_id": conversationID,
+		"tweets":          record.TweetCount,
+		"uri":             record.URI,
+	}).Info("Archived conversation")
+
+	return record, nil
+}
+
+// documentKey names the archived document of a conversation
+func documentKey(botID, conversationID string) string {
+	return fmt.Sprintf("%s/%s.json", botID, conversationID)
+}
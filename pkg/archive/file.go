@@ -0,0 +1,51 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FilePublisher writes archived documents to a local directory
+type FilePublisher struct {
+	dir string
+}
+
+// NewFilePublisher creates a publisher writing under dir, which is created
+// when missing
+func NewFilePublisher(dir string) (*FilePublisher, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("archive directory is required")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve archive directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &FilePublisher{dir: abs}, nil
+}
+
+// Publish implements Publisher. The file is written under a temporary name
+// and renamed, so a document is either complete or absent.
+func (p *FilePublisher) Publish(ctx context.Context, key string, data []byte) (*Location, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(p.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return &Location{URI: "file://" + filepath.ToSlash(path)}, nil
+}
@@ -0,0 +1,42 @@
+package archive
+
+import (
+	"context"
+	"path"
+
+	"github.com/lisanmuaddib/agent-go/pkg/ipfs"
+)
+
+// Location is where an archived document was published
+type Location struct {
+	// URI identifies the document, e.g. ipfs://<cid> or s3://bucket/key
+	URI string
+	// URL serves the document over HTTP
+	URL string
+}
+
+// Publisher stores archived documents
+type Publisher interface {
+	// Publish stores data under key, a relative path such as botID/conversationID.json
+	Publish(ctx context.Context, key string, data []byte) (*Location, error)
+}
+
+// IPFSPublisher pins archived documents to IPFS
+type IPFSPublisher struct {
+	client *ipfs.Client
+}
+
+// NewIPFSPublisher creates a publisher pinning through client
+func NewIPFSPublisher(client *ipfs.Client) *IPFSPublisher {
+	return &IPFSPublisher{client: client}
+}
+
+// Publish implements Publisher. The content is addressed by its CID, so the
+// key only names the pinned file.
+func (p *IPFSPublisher) Publish(ctx context.Context, key string, data []byte) (*Location, error) {
+	pin, err := p.client.Add(ctx, path.Base(key), data)
+	if err != nil {
+		return nil, err
+	}
+	return &Location{URI: pin.URI(), URL: p.client.GatewayURL(pin.CID)}, nil
+}
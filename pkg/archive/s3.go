@@ -0,0 +1,134 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/smithy-go/encoding/httpbinding"
+)
+
+// S3Config holds the bucket archived documents are uploaded to. Any
+// S3-compatible service works; requests use path-style addressing.
+type S3Config struct {
+	Bucket string
+	Region string
+	// Endpoint defaults to the AWS endpoint of the region, e.g.
+	// https://s3.us-east-1.amazonaws.com
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set with temporary credentials, e.g. from an assumed
+	// role, and sent as X-Amz-Security-Token
+	SessionToken string
+	// Prefix is prepended to every object key, e.g. archive/
+	Prefix string
+	// PublicURL, when set, replaces the endpoint and bucket in returned URLs,
+	// e.g. a CDN in front of the bucket
+	PublicURL string
+
+	RequestTimeout time.Duration
+}
+
+// S3Publisher uploads archived documents to an S3 bucket
+type S3Publisher struct {
+	config S3Config
+	http   *http.Client
+	signer *v4.Signer
+}
+
+// NewS3Publisher creates a publisher uploading to the configured bucket
+func NewS3Publisher(config S3Config) (*S3Publisher, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.Region == "" {
+		return nil, fmt.Errorf("S3 region is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 access key ID and secret access key are required")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	config.PublicURL = strings.TrimRight(config.PublicURL, "/")
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = time.Minute
+	}
+
+	return &S3Publisher{
+		config: config,
+		http:   &http.Client{Timeout: config.RequestTimeout},
+		// Keys are escaped once by escapeKey, as S3 expects
+		signer: v4.NewSigner(func(options *v4.SignerOptions) {
+			options.DisableURIPathEscaping = true
+		}),
+	}, nil
+}
+
+// Publish implements Publisher, uploading data as a JSON object
+func (p *S3Publisher) Publish(ctx context.Context, key string, data []byte) (*Location, error) {
+	key = p.config.Prefix + key
+	objectPath := "/" + p.config.Bucket + "/" + escapeKey(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.config.Endpoint+objectPath, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := p.sign(ctx, req, data); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("S3 upload failed (status %d): %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	location := &Location{
+		URI: fmt.Sprintf("s3://%s/%s", p.config.Bucket, key),
+		URL: p.config.Endpoint + objectPath,
+	}
+	if p.config.PublicURL != "" {
+		location.URL = p.config.PublicURL + "/" + escapeKey(key)
+	}
+	return location, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to the request
+func (p *S3Publisher) sign(ctx context.Context, req *http.Request, payload []byte) error {
+	sum := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	credentials := aws.Credentials{
+		AccessKeyID:     p.config.AccessKeyID,
+		SecretAccessKey: p.config.SecretAccessKey,
+		SessionToken:    p.config.SessionToken,
+	}
+	if err := p.signer.SignHTTP(ctx, credentials, req, payloadHash, "s3", p.config.Region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign S3 request: %w", err)
+	}
+	return nil
+}
+
+// escapeKey URI-encodes an object key the way SigV4 canonicalizes S3 paths:
+// everything but unreserved characters is percent-encoded, slashes are kept
+func escapeKey(key string) string {
+	return httpbinding.EscapePath(key, false)
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/tools"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Builder assembles an agent for programs embedding it. Only WithTwitter and
+// WithLLM are required; every action added with WithActions is registered
+// with the task settings of its name.
+//
+//	bot, err := agent.NewBuilder().
+//		WithTwitter(client).
+//		WithStore(store).
+//		WithLLM(model).
+//		WithActions(mentions, replies).
+//		Build()
+type Builder struct {
+	config  Config
+	actions []actions.Action
+}
+
+// NewBuilder starts an agent with no services and no actions
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// WithTwitter sets the client the agent acts through
+func (b *Builder) WithTwitter(client *twitter.TwitterClient) *Builder {
+	b.config.TwitterClient = client
+	return b
+}
+
+// WithStore sets the store of the agent's tweets and conversations
+func (b *Builder) WithStore(store *memory.TweetStore) *Builder {
+	b.config.TweetStore = store
+	return b
+}
+
+// WithLLM sets the model the agent reasons with
+func (b *Builder) WithLLM(model llms.Model) *Builder {
+	b.config.LLM = model
+	return b
+}
+
+// WithLogger sets the logger; without one the agent logs to a new logger
+func (b *Builder) WithLogger(logger *logrus.Logger) *Builder {
+	b.config.Logger = logger
+	return b
+}
+
+// WithTools enables the tool-calling reasoning loop with registry
+func (b *Builder) WithTools(registry *tools.Registry) *Builder {
+	b.config.Tools = registry
+	return b
+}
+
+// WithTasks sets whether each action runs, its interval and restart policy.
+// reload, which may be nil, re-reads them when a task is enabled at runtime.
+func (b *Builder) WithTasks(tasks config.TasksConfig, reload func() (config.TasksConfig, error)) *Builder {
+	b.config.Tasks = tasks
+	b.config.ReloadTasks = reload
+	return b
+}
+
+// WithActions adds actions for the agent to run
+func (b *Builder) WithActions(actions ...actions.Action) *Builder {
+	b.actions = append(b.actions, actions...)
+	return b
+}
+
+// Build creates the agent and registers its actions
+func (b *Builder) Build() (*Agent, error) {
+	agent, err := New(b.config)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range b.actions {
+		if err := agent.RegisterAction(action); err != nil {
+			return nil, fmt.Errorf("failed to register action: %w", err)
+		}
+	}
+	return agent, nil
+}
+
+// RunUntilSignal runs the agent like Run until ctx is cancelled or the process
+// receives SIGINT or SIGTERM
+func (a *Agent) RunUntilSignal(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			a.logger.WithField("signal", sig.String()).Info("Received shutdown signal")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return a.Run(ctx)
+}
@@ -0,0 +1,105 @@
+// Package commands parses the structured commands users give the bot in
+// mentions, such as "@CatLordLaffy tip @user 100 LAFFY". A command is the
+// first word after the handles a mention starts with; anything else is an
+// ordinary mention and gets an ordinary reply.
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Names of the supported commands
+const (
+	// Tip sends tokens from the bot's wallet: tip @user 100 LAFFY
+	Tip = "tip"
+	// Wallet registers the address tips to the sender go to: wallet 0x... or
+	// wallet name.eth
+	Wallet = "wallet"
+)
+
+// Command is a command found in a mention
+type Command struct {
+	Name string
+	// Args are the words after the command name
+	Args []string
+}
+
+// TipArgs are the arguments of a tip command
+type TipArgs struct {
+	// Recipient is the username to tip, without the @
+	Recipient string
+	// Amount is the amount in whole tokens as written, e.g. "100" or "2.5"
+	Amount string
+	// Units is Amount as a number
+	Units float64
+	// Symbol is the token's symbol in upper case, without a leading $
+	Symbol string
+}
+
+// Parse returns the command in a mention's text. ok is false when the text
+// does not start with a known command after its leading handles.
+func Parse(text string) (command Command, ok bool) {
+	words := strings.Fields(text)
+	for len(words) > 0 && strings.HasPrefix(words[0], "@") {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return Command{}, false
+	}
+
+	name := strings.ToLower(strings.TrimRight(words[0], ":,"))
+	switch name {
+	case Tip, Wallet:
+		return Command{Name: name, Args: words[1:]}, true
+	default:
+		return Command{}, false
+	}
+}
+
+// Tip validates the arguments of a tip command: a handle, a positive amount
+// and a token symbol, e.g. "@user 100 LAFFY"
+func (c Command) Tip() (TipArgs, error) {
+	if c.Name != Tip {
+		return TipArgs{}, fmt.Errorf("not a tip command: %s", c.Name)
+	}
+	if len(c.Args) < 3 {
+		return TipArgs{}, fmt.Errorf("usage: tip @user 100 LAFFY")
+	}
+
+	recipient := strings.TrimRight(c.Args[0], ".,!?:;")
+	if !strings.HasPrefix(recipient, "@") || len(recipient) < 2 {
+		return TipArgs{}, fmt.Errorf("tip needs an @handle to tip, got %q", c.Args[0])
+	}
+
+	amount := strings.TrimRight(c.Args[1], ".,")
+	// Only plain decimals; ParseFloat would also take "inf" or "1e9"
+	units, err := strconv.ParseFloat(amount, 64)
+	if err != nil || units <= 0 || strings.Trim(amount, "0123456789.") != "" {
+		return TipArgs{}, fmt.Errorf("tip amount must be a positive number, got %q", c.Args[1])
+	}
+
+	symbol := strings.ToUpper(strings.TrimLeft(strings.TrimRight(c.Args[2], ".,!?:;"), "$"))
+	if symbol == "" {
+		return TipArgs{}, fmt.Errorf("tip needs a token symbol, got %q", c.Args[2])
+	}
+
+	return TipArgs{
+		Recipient: strings.TrimPrefix(recipient, "@"),
+		Amount:    amount,
+		Units:     units,
+		Symbol:    symbol,
+	}, nil
+}
+
+// Address returns the address or ENS name of a wallet command
+func (c Command) Address() (string, error) {
+	if c.Name != Wallet {
+		return "", fmt.Errorf("not a wallet command: %s", c.Name)
+	}
+	if len(c.Args) == 0 {
+		return "", fmt.Errorf("usage: wallet 0x... or wallet name.eth")
+	}
+	return strings.TrimRight(c.Args[0], ".,!?:;"), nil
+}
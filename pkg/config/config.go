@@ -0,0 +1,883 @@
+// Package config provides the agent's central configuration. Values are resolved
+// in order of increasing precedence: built-in defaults, an optional YAML file,
+// environment variables (including a .env file) and command-line flags.
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// Config is the complete agent configuration
+type Config struct {
+	Log      LogConfig      `yaml:"log"`
+	Database DatabaseConfig `yaml:"database"`
+	Twitter  TwitterConfig  `yaml:"twitter"`
+	OpenAI   OpenAIConfig   `yaml:"openai"`
+	Masa     MasaConfig     `yaml:"masa"`
+	Market   MarketConfig   `yaml:"market"`
+	Wallet   WalletConfig   `yaml:"wallet"`
+	// Tips lets allowed users send tokens from the wallet with mentions such
+	// as "tip @user 100 LAFFY"
+	Tips    TipsConfig   `yaml:"tips"`
+	Filters FilterConfig `yaml:"filters"`
+	Events  EventsConfig `yaml:"events"`
+	Admin   AdminConfig  `yaml:"admin"`
+	Tasks   TasksConfig  `yaml:"tasks"`
+	// Replies limits how much the bot engages with one user or thread
+	Replies ReplyConfig `yaml:"replies"`
+	// Posting holds original thoughts and replies during quiet hours and embargoes
+	Posting PostingConfig `yaml:"posting"`
+	// Farcaster runs the persona on Farcaster next to Twitter when configured
+	Farcaster FarcasterConfig `yaml:"farcaster"`
+	// Lens cross-posts original thoughts to a Lens profile when configured
+	Lens LensConfig `yaml:"lens"`
+	// IPFS is the node or pinning service content is pinned to
+	IPFS IPFSConfig `yaml:"ipfs"`
+	// Archive publishes completed conversations to IPFS or S3 when configured
+	Archive ArchiveConfig `yaml:"archive"`
+	// Retention prunes old rows from the database when configured
+	Retention RetentionConfig `yaml:"retention"`
+	// ImageGen attaches generated memes to some replies when configured
+	ImageGen ImageGenConfig `yaml:"imagegen"`
+	// Experiment splits reply generation between prompt variants (YAML only)
+	Experiment ExperimentConfig `yaml:"experiment"`
+	// Plugins add out-of-tree actions to every account (YAML only)
+	Plugins []PluginConfig `yaml:"plugins"`
+	Agent   AgentConfig    `yaml:"agent"`
+	// Debug holds troubleshooting switches that are off in normal operation
+	Debug DebugConfig `yaml:"debug"`
+	// Accounts lists the bot personas to run. When empty, a single account is
+	// built from the top-level Twitter settings.
+	Accounts []AccountConfig `yaml:"accounts"`
+}
+
+// LogConfig controls logging output
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format is "text" for the colored development format or "json" for one
+	// JSON object per line, for log collectors in production
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+// DatabaseConfig holds database connection settings. Driver selects between
+// "postgres" (the default) and "sqlite"; Path is only used by SQLite.
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" env:"DB_DRIVER"`
+	Path            string        `yaml:"path" env:"DB_PATH"`
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            string        `yaml:"port" env:"DB_PORT"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD"`
+	SSLMode         string        `yaml:"ssl_mode" env:"DB_SSL_MODE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	// ConnectRetries is how many times the initial connection is retried with backoff
+	ConnectRetries int `yaml:"connect_retries" env:"DB_CONNECT_RETRIES"`
+	// HealthCheckInterval is how often the pool is pinged; zero disables the check
+	HealthCheckInterval time.Duration `yaml:"health_check_interval" env:"DB_HEALTH_CHECK_INTERVAL"`
+	// ReconnectMaxBackoff caps the delay between connection attempts
+	ReconnectMaxBackoff time.Duration `yaml:"reconnect_max_backoff" env:"DB_RECONNECT_MAX_BACKOFF"`
+	// StatementTimeout bounds every query issued through GORM; zero disables it
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// ReplicaDSNs are Postgres read replicas serving recall and analytics queries
+	ReplicaDSNs []string `yaml:"replica_dsns" env:"DB_REPLICA_DSNS" redact:"true"`
+}
+
+// TwitterConfig holds Twitter API credentials and limits
+type TwitterConfig struct {
+	ConsumerKey       string `yaml:"consumer_key" env:"TWITTER_CONSUMER_KEY"`
+	ConsumerSecret    string `yaml:"consumer_secret" env:"TWITTER_CONSUMER_SECRET"`
+	AccessToken       string `yaml:"access_token" env:"TWITTER_ACCESS_TOKEN"`
+	AccessTokenSecret string `yaml:"access_token_secret" env:"TWITTER_ACCESS_TOKEN_SECRET"`
+	BearerToken       string `yaml:"bearer_token" env:"TWITTER_BEARER_TOKEN"`
+	UserID            string `yaml:"user_id" env:"TWITTER_USER_ID"`
+	BaseURL           string `yaml:"base_url" env:"TWITTER_API_BASE_URL"`
+	RateLimit         int    `yaml:"rate_limit" env:"TWITTER_RATE_LIMIT"`
+	RateWindow        int    `yaml:"rate_window" env:"TWITTER_RATE_WINDOW"`
+	RetryAttempts     int    `yaml:"retry_attempts" env:"TWITTER_RETRY_ATTEMPTS"`
+	// RetryBackoff is the jittered wait before the first retry; it doubles
+	// with every further attempt
+	RetryBackoff time.Duration `yaml:"retry_backoff" env:"TWITTER_RETRY_BACKOFF"`
+	// ProxyURL routes API requests through a proxy; empty uses HTTPS_PROXY
+	ProxyURL            string        `yaml:"proxy_url" env:"TWITTER_PROXY_URL"`
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host" env:"TWITTER_MAX_IDLE_CONNS_PER_HOST"`
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout" env:"TWITTER_IDLE_CONN_TIMEOUT"`
+	// Budget splits the account's requests between kinds of work
+	Budget BudgetConfig `yaml:"budget"`
+	// OAuth2 authenticates in the OAuth 2.0 user context instead
+	OAuth2 OAuth2Config `yaml:"oauth2"`
+}
+
+// OAuth2Config holds the OAuth 2.0 client the account authorizes with the
+// oauth2 login command. Its tokens are stored encrypted in the database and
+// refreshed before they expire.
+type OAuth2Config struct {
+	// ClientID enables the OAuth 2.0 user context
+	ClientID string `yaml:"client_id" env:"TWITTER_OAUTH2_CLIENT_ID"`
+	// ClientSecret is only set for confidential clients
+	ClientSecret string `yaml:"client_secret" env:"TWITTER_OAUTH2_CLIENT_SECRET"`
+	// RedirectURL is registered with the app; the login command listens on it
+	RedirectURL string `yaml:"redirect_url" env:"TWITTER_OAUTH2_REDIRECT_URL"`
+	// Scopes are requested on login and required of the stored token; empty
+	// uses the built-in scopes
+	Scopes []string `yaml:"scopes" env:"TWITTER_OAUTH2_SCOPES"`
+	// TokenKey is the base64 AES-256 key tokens are encrypted with
+	TokenKey string `yaml:"token_key" env:"TWITTER_OAUTH2_TOKEN_KEY"`
+	// RefreshBefore is how long before it expires the access token is refreshed
+	RefreshBefore time.Duration `yaml:"refresh_before" env:"TWITTER_OAUTH2_REFRESH_BEFORE"`
+}
+
+// Enabled reports whether the OAuth 2.0 user context is configured
+func (c OAuth2Config) Enabled() bool {
+	return c.ClientID != ""
+}
+
+// Key decodes TokenKey
+func (c OAuth2Config) Key() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(c.TokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("token key is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("token key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// BudgetConfig partitions the requests an account makes per window between
+// shares, e.g. replies, reads and analytics. An action that used up its share
+// waits for the next window.
+type BudgetConfig struct {
+	// Requests is how many requests the account makes per Window; 0 leaves
+	// requests unpartitioned
+	Requests int           `yaml:"requests" env:"TWITTER_BUDGET_REQUESTS"`
+	Window   time.Duration `yaml:"window" env:"TWITTER_BUDGET_WINDOW"`
+	// Shares are the fractions of Requests each share may use (YAML only)
+	Shares map[string]float64 `yaml:"shares"`
+	// Actions assign actions, by name, to shares on top of the built-in
+	// assignments (YAML only)
+	Actions map[string]string `yaml:"actions"`
+}
+
+// Enabled reports whether requests are partitioned
+func (c BudgetConfig) Enabled() bool {
+	return c.Requests > 0
+}
+
+// FarcasterConfig holds Farcaster account and API settings. Casts are read
+// from a hub when HubURL is set and from Neynar otherwise; replies are always
+// published through Neynar with a managed signer.
+type FarcasterConfig struct {
+	// FID is the bot's Farcaster ID; 0 disables Farcaster
+	FID          int64  `yaml:"fid" env:"FARCASTER_FID"`
+	Username     string `yaml:"username" env:"FARCASTER_USERNAME"`
+	NeynarAPIKey string `yaml:"neynar_api_key" env:"FARCASTER_NEYNAR_API_KEY"`
+	NeynarURL    string `yaml:"neynar_url" env:"FARCASTER_NEYNAR_URL"`
+	SignerUUID   string `yaml:"signer_uuid" env:"FARCASTER_SIGNER_UUID"`
+	HubURL       string `yaml:"hub_url" env:"FARCASTER_HUB_URL"`
+}
+
+// Enabled reports whether a Farcaster account is configured
+func (c FarcasterConfig) Enabled() bool {
+	return c.FID != 0
+}
+
+// LensConfig holds Lens Protocol settings. Publications are signed with the
+// wallet private key, which must own or manage the profile, and their metadata
+// is pinned to IPFS.
+type LensConfig struct {
+	// ProfileID is the bot's Lens profile, e.g. 0x01a2b3; empty disables Lens
+	ProfileID string `yaml:"profile_id" env:"LENS_PROFILE_ID"`
+	APIURL    string `yaml:"api_url" env:"LENS_API_URL"`
+	AppID     string `yaml:"app_id" env:"LENS_APP_ID"`
+}
+
+// Enabled reports whether a Lens profile is configured
+func (c LensConfig) Enabled() bool {
+	return c.ProfileID != ""
+}
+
+// IPFSConfig holds the Kubo-compatible RPC API content is pinned through.
+// Hosted services authenticate with either basic auth or a bearer token.
+type IPFSConfig struct {
+	APIURL     string `yaml:"api_url" env:"IPFS_API_URL"`
+	Username   string `yaml:"username" env:"IPFS_USERNAME"`
+	Password   string `yaml:"password" env:"IPFS_PASSWORD"`
+	Token      string `yaml:"token" env:"IPFS_TOKEN" redact:"true"`
+	GatewayURL string `yaml:"gateway_url" env:"IPFS_GATEWAY_URL"`
+}
+
+// Enabled reports whether an IPFS API is configured
+func (c IPFSConfig) Enabled() bool {
+	return c.APIURL != ""
+}
+
+// ArchiveConfig holds the public conversation archive settings. The ipfs
+// backend pins through the IPFS settings; s3 uploads to the bucket below.
+type ArchiveConfig struct {
+	// Backend is "ipfs" or "s3"; empty disables archiving
+	Backend string `yaml:"backend" env:"ARCHIVE_BACKEND"`
+	// QuietPeriod is how long a conversation must be inactive to be archived
+	QuietPeriod time.Duration `yaml:"quiet_period" env:"ARCHIVE_QUIET_PERIOD"`
+
+	S3Bucket          string `yaml:"s3_bucket" env:"ARCHIVE_S3_BUCKET"`
+	S3Region          string `yaml:"s3_region" env:"ARCHIVE_S3_REGION"`
+	S3Endpoint        string `yaml:"s3_endpoint" env:"ARCHIVE_S3_ENDPOINT"`
+	S3AccessKeyID     string `yaml:"s3_access_key_id" env:"ARCHIVE_S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `yaml:"s3_secret_access_key" env:"ARCHIVE_S3_SECRET_ACCESS_KEY"`
+	S3SessionToken    string `yaml:"s3_session_token" env:"ARCHIVE_S3_SESSION_TOKEN"`
+	S3Prefix          string `yaml:"s3_prefix" env:"ARCHIVE_S3_PREFIX"`
+	S3PublicURL       string `yaml:"s3_public_url" env:"ARCHIVE_S3_PUBLIC_URL"`
+}
+
+// RetentionConfig holds how long rows are kept in the database. Each age
+// applies to one kind of row; zero keeps the rows forever. Pruned rows are
+// first written to the sink as gzipped JSON lines; without a sink they are
+// pruned without a copy.
+type RetentionConfig struct {
+	// Conversations is how long a conversation is kept after its last tweet
+	Conversations time.Duration `yaml:"conversations" env:"RETENTION_CONVERSATIONS"`
+	Judgments     time.Duration `yaml:"judgments" env:"RETENTION_JUDGMENTS"`
+	// Analytics is how long analytics, reply latencies and reply stages are kept
+	Analytics time.Duration `yaml:"analytics" env:"RETENTION_ANALYTICS"`
+	// Quota is how long the Twitter API consumption per endpoint and rate
+	// limit window is kept
+	Quota time.Duration `yaml:"quota" env:"RETENTION_QUOTA"`
+	// BatchSize caps the rows pruned per transaction
+	BatchSize int `yaml:"batch_size" env:"RETENTION_BATCH_SIZE"`
+
+	// Sink is "file" or "s3"; empty prunes without archiving
+	Sink string `yaml:"sink" env:"RETENTION_SINK"`
+	// Dir is where the file sink writes
+	Dir string `yaml:"dir" env:"RETENTION_DIR"`
+	// S3Bucket is the private bucket of the s3 sink; region, endpoint and
+	// credentials default to the archive settings
+	S3Bucket          string `yaml:"s3_bucket" env:"RETENTION_S3_BUCKET"`
+	S3Region          string `yaml:"s3_region" env:"RETENTION_S3_REGION"`
+	S3Endpoint        string `yaml:"s3_endpoint" env:"RETENTION_S3_ENDPOINT"`
+	S3AccessKeyID     string `yaml:"s3_access_key_id" env:"RETENTION_S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `yaml:"s3_secret_access_key" env:"RETENTION_S3_SECRET_ACCESS_KEY"`
+	S3SessionToken    string `yaml:"s3_session_token" env:"RETENTION_S3_SESSION_TOKEN"`
+	S3Prefix          string `yaml:"s3_prefix" env:"RETENTION_S3_PREFIX"`
+}
+
+// Enabled reports whether any kind of row is pruned
+func (c RetentionConfig) Enabled() bool {
+	return c.Conversations > 0 || c.Judgments > 0 || c.Analytics > 0 || c.Quota > 0
+}
+
+// ImageGenConfig holds the meme image generation settings. The openai
+// provider falls back to the OpenAI API key when APIKey is empty.
+type ImageGenConfig struct {
+	// Provider is "openai" or "stability"; empty disables memes
+	Provider string `yaml:"provider" env:"IMAGEGEN_PROVIDER"`
+	APIKey   string `yaml:"api_key" env:"IMAGEGEN_API_KEY"`
+	Model    string `yaml:"model" env:"IMAGEGEN_MODEL"`
+	// Probability is the chance an eligible reply gets a meme, from 0 to 1
+	Probability float64 `yaml:"probability" env:"IMAGEGEN_PROBABILITY"`
+	// MinEngagement is the weighted engagement a tweet needs before its reply
+	// can carry a meme
+	MinEngagement int `yaml:"min_engagement" env:"IMAGEGEN_MIN_ENGAGEMENT"`
+}
+
+// OpenAIConfig holds LLM provider settings
+type OpenAIConfig struct {
+	APIKey      string  `yaml:"api_key" env:"OPENAI_API_KEY"`
+	Model       string  `yaml:"model" env:"OPENAI_MODEL"`
+	Temperature float64 `yaml:"temperature" env:"OPENAI_TEMPERATURE"`
+	MaxTokens   int     `yaml:"max_tokens" env:"OPENAI_MAX_TOKENS"`
+	// BaseURL is the OpenAI-compatible API the client and the preflight
+	// check call
+	BaseURL string `yaml:"base_url" env:"OPENAI_BASE_URL"`
+	// EmbeddingModel creates the embeddings reply examples are picked by
+	EmbeddingModel string `yaml:"embedding_model" env:"OPENAI_EMBEDDING_MODEL"`
+	// Timeout limits each call. A timed out call is retried TimeoutRetries
+	// times with half the max tokens; -1 disables retries.
+	Timeout        time.Duration `yaml:"timeout" env:"OPENAI_TIMEOUT"`
+	TimeoutRetries int           `yaml:"timeout_retries" env:"OPENAI_TIMEOUT_RETRIES"`
+	// BreakerThreshold consecutive failed calls make every call fail fast for
+	// BreakerCooldown, so a provider outage doesn't stall each batch
+	BreakerThreshold int           `yaml:"breaker_threshold" env:"OPENAI_BREAKER_THRESHOLD"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" env:"OPENAI_BREAKER_COOLDOWN"`
+	// FineTuned replaces Model with fine-tuned models for some generators
+	FineTuned FineTunedConfig `yaml:"fine_tuned"`
+	// Routing sends short replies to a cheaper model
+	Routing RoutingConfig `yaml:"routing"`
+	// Prices estimate the cost of each model's usage
+	Prices map[string]ModelPrice `yaml:"prices"`
+}
+
+// RoutingConfig picks a model per reply. Replies in threads of at most
+// MaxCheapThread tweets go to CheapModel, unless their category is one of
+// StrongCategories or the author has at least StrongFollowers followers.
+// Everything else, including judgments, goes to StrongModel, which defaults
+// to the base model. An empty CheapModel turns routing off.
+type RoutingConfig struct {
+	CheapModel       string   `yaml:"cheap_model" env:"OPENAI_ROUTING_CHEAP_MODEL"`
+	StrongModel      string   `yaml:"strong_model" env:"OPENAI_ROUTING_STRONG_MODEL"`
+	MaxCheapThread   int      `yaml:"max_cheap_thread" env:"OPENAI_ROUTING_MAX_CHEAP_THREAD"`
+	StrongCategories []string `yaml:"strong_categories" env:"OPENAI_ROUTING_STRONG_CATEGORIES"`
+	StrongFollowers  int      `yaml:"strong_followers" env:"OPENAI_ROUTING_STRONG_FOLLOWERS"`
+}
+
+// Enabled reports whether replies are routed between two models
+func (c RoutingConfig) Enabled() bool {
+	return c.CheapModel != ""
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Prompt     float64 `yaml:"prompt"`
+	Completion float64 `yaml:"completion"`
+}
+
+// FineTunedConfig names the fine-tuned model of each generator; an empty ID
+// keeps the generator on the base model. With Fallback, a failed fine-tuned
+// call is retried on the base model, which the generator then keeps using for
+// FallbackCooldown.
+type FineTunedConfig struct {
+	Replies          string        `yaml:"replies" env:"OPENAI_FINE_TUNED_REPLIES"`
+	Thoughts         string        `yaml:"thoughts" env:"OPENAI_FINE_TUNED_THOUGHTS"`
+	Judgments        string        `yaml:"judgments" env:"OPENAI_FINE_TUNED_JUDGMENTS"`
+	Fallback         bool          `yaml:"fallback" env:"OPENAI_FINE_TUNED_FALLBACK"`
+	FallbackCooldown time.Duration `yaml:"fallback_cooldown" env:"OPENAI_FINE_TUNED_FALLBACK_COOLDOWN"`
+}
+
+// MasaConfig holds Masa Protocol API settings
+type MasaConfig struct {
+	APIEndpoint           string `yaml:"api_endpoint" env:"MASA_TWITTER_API_ENDPOINT"`
+	RequestTimeoutSeconds int    `yaml:"request_timeout_seconds" env:"MASA_TWITTER_REQUEST_TIMEOUT"`
+	TweetsPerRequest      int    `yaml:"tweets_per_request" env:"MASA_TWITTER_TWEETS_PER_REQUEST"`
+	// SearchTool registers a tool that lets the LLM search recent tweets
+	// through the Masa API
+	SearchTool bool `yaml:"search_tool" env:"MASA_SEARCH_TOOL"`
+}
+
+// MarketConfig holds token price oracle settings
+type MarketConfig struct {
+	CoinGeckoURL    string        `yaml:"coingecko_url" env:"MARKET_COINGECKO_URL"`
+	CoinGeckoAPIKey string        `yaml:"coingecko_api_key" env:"MARKET_COINGECKO_API_KEY"`
+	DexScreenerURL  string        `yaml:"dexscreener_url" env:"MARKET_DEXSCREENER_URL"`
+	CacheTTL        time.Duration `yaml:"cache_ttl" env:"MARKET_CACHE_TTL"`
+	Watchlist       []string      `yaml:"watchlist" env:"MARKET_WATCHLIST"`
+	// Contracts pins the token contracts DexScreener quotes come from, by
+	// DexScreener chain ID (e.g. base) and then symbol. Symbols CoinGecko
+	// does not list are only priced when pinned, since any token can take a
+	// symbol.
+	Contracts map[string]map[string]string `yaml:"contracts"`
+}
+
+// WalletConfig holds EVM RPC endpoints and signing settings
+type WalletConfig struct {
+	ETHRPCURL            string `yaml:"eth_rpc_url" env:"ETH_RPC_URL" redact:"true"`
+	BaseRPCURL           string `yaml:"base_rpc_url" env:"BASE_RPC_URL" redact:"true"`
+	BSCRPCURL            string `yaml:"bsc_rpc_url" env:"BSC_RPC_URL" redact:"true"`
+	PolygonRPCURL        string `yaml:"polygon_rpc_url" env:"POLYGON_RPC_URL" redact:"true"`
+	ArbitrumRPCURL       string `yaml:"arbitrum_rpc_url" env:"ARBITRUM_RPC_URL" redact:"true"`
+	OptimismRPCURL       string `yaml:"optimism_rpc_url" env:"OPTIMISM_RPC_URL" redact:"true"`
+	PrivateKey           string `yaml:"private_key" env:"WALLET_PRIVATE_KEY"`
+	TokenContractAddress string `yaml:"token_contract_address" env:"TOKEN_CONTRACT_ADDRESS"`
+	// WatchTransfers publishes ERC20 transfers to the wallet as
+	// wallet_transfer_received events
+	WatchTransfers bool `yaml:"watch_transfers" env:"WALLET_WATCH_TRANSFERS"`
+	// ThankTransfers tweets a thank-you to senders of watched transfers
+	ThankTransfers bool `yaml:"thank_transfers" env:"WALLET_THANK_TRANSFERS"`
+	// ThankMinAmount is the smallest transfer, in whole tokens, worth a thank-you
+	ThankMinAmount float64 `yaml:"thank_min_amount" env:"WALLET_THANK_MIN_AMOUNT"`
+	// QueryTools registers tools that let the LLM read the treasury's
+	// balances and recent distributions of the token
+	QueryTools bool `yaml:"query_tools" env:"WALLET_QUERY_TOOLS"`
+	// TreasuryAddress is the address or ENS name the query tools report on;
+	// defaults to the wallet's own address
+	TreasuryAddress string `yaml:"treasury_address" env:"WALLET_TREASURY_ADDRESS"`
+	// Networks adds EVM chains beyond the built-in ones; YAML only
+	Networks []CustomNetworkConfig `yaml:"networks"`
+}
+
+// TipsConfig holds the tip command settings. Tips are paid in the wallet's
+// token_contract_address by the primary account's wallet.
+type TipsConfig struct {
+	Enabled bool `yaml:"enabled" env:"TIPS_ENABLED"`
+	// Network the tips are sent on, e.g. BASE
+	Network string `yaml:"network" env:"TIPS_NETWORK"`
+	// MaxAmount is the largest tip in whole tokens; larger tips are refused
+	MaxAmount float64 `yaml:"max_amount" env:"TIPS_MAX_AMOUNT"`
+	// DailyLimit caps the whole tokens one sender can tip per 24 hours; 0
+	// means no cap
+	DailyLimit float64 `yaml:"daily_limit" env:"TIPS_DAILY_LIMIT"`
+	// ApprovalAbove holds tips larger than this, in whole tokens, until an
+	// operator approves them through the admin API; 0 holds none
+	ApprovalAbove float64 `yaml:"approval_above" env:"TIPS_APPROVAL_ABOVE"`
+}
+
+// CustomNetworkConfig describes an EVM chain the wallet has no built-in config for
+type CustomNetworkConfig struct {
+	Name    string `yaml:"name"`
+	RPCURL  string `yaml:"rpc_url" redact:"true"`
+	ChainID int64  `yaml:"chain_id"`
+	// NativeSymbol defaults to ETH
+	NativeSymbol string `yaml:"native_symbol"`
+	// GasLimitMultiplier defaults to 1.2
+	GasLimitMultiplier float64 `yaml:"gas_limit_multiplier"`
+	// L1Fee is "op-stack" or "arbitrum" for rollups charging an L1 data fee
+	L1Fee string `yaml:"l1_fee"`
+	// ExplorerURL links transactions in replies, e.g. https://lineascan.build
+	ExplorerURL string `yaml:"explorer_url"`
+}
+
+// FilterConfig holds spam and bot detection settings for incoming mentions
+type FilterConfig struct {
+	// SpamThreshold excludes mentions scoring at or above it from replies; 0 disables filtering
+	SpamThreshold   float64       `yaml:"spam_threshold" env:"FILTER_SPAM_THRESHOLD"`
+	MinAccountAge   time.Duration `yaml:"min_account_age" env:"FILTER_MIN_ACCOUNT_AGE"`
+	DuplicateWindow time.Duration `yaml:"duplicate_window" env:"FILTER_DUPLICATE_WINDOW"`
+	// LLMClassifier asks the LLM to rate mentions the heuristics find suspicious
+	LLMClassifier bool `yaml:"llm_classifier" env:"FILTER_LLM_CLASSIFIER"`
+	// SentimentLLM asks the LLM for mention sentiment instead of the local lexicon
+	SentimentLLM bool `yaml:"sentiment_llm" env:"FILTER_SENTIMENT_LLM"`
+	// KnownBots are user IDs or usernames of bots whose mentions are never answered
+	KnownBots []string `yaml:"known_bots" env:"FILTER_KNOWN_BOTS"`
+	// BotHeuristic also skips authors whose profile or tweet rate looks automated
+	BotHeuristic bool `yaml:"bot_heuristic" env:"FILTER_BOT_HEURISTIC"`
+}
+
+// ReplyConfig throttles replies per user and per conversation. Zero disables a limit.
+type ReplyConfig struct {
+	// MaxPerUserPerDay caps the replies to one user per UTC day
+	MaxPerUserPerDay int `yaml:"max_per_user_per_day" env:"REPLY_MAX_PER_USER_PER_DAY"`
+	// MaxConversationDepth caps the bot's replies in one conversation
+	MaxConversationDepth int `yaml:"max_conversation_depth" env:"REPLY_MAX_CONVERSATION_DEPTH"`
+	// ThreadCooldown is the minimum time between two replies in the same conversation
+	ThreadCooldown time.Duration `yaml:"thread_cooldown" env:"REPLY_THREAD_COOLDOWN"`
+	// Workers is the number of reply workers answering queued mentions
+	Workers int `yaml:"workers" env:"REPLY_WORKERS"`
+	// VisibilityTimeout is how long a claimed mention is hidden from other
+	// workers before it is retried
+	VisibilityTimeout time.Duration `yaml:"visibility_timeout" env:"REPLY_VISIBILITY_TIMEOUT"`
+	// MaxAttempts drops a queued mention after this many failed replies
+	MaxAttempts int `yaml:"max_attempts" env:"REPLY_MAX_ATTEMPTS"`
+	// RetryDelay is how long a failed or throttled mention waits in the queue
+	RetryDelay time.Duration `yaml:"retry_delay" env:"REPLY_RETRY_DELAY"`
+	// PriorityFollowers is the follower count at which an author's reach stops
+	// raising the priority of their mentions
+	PriorityFollowers int `yaml:"priority_followers" env:"REPLY_PRIORITY_FOLLOWERS"`
+	// Priority weighs the signals that decide which mentions are answered first
+	Priority ReplyPriorityConfig `yaml:"priority"`
+	// PollMinInterval is the shortest wait between mention polls, reached
+	// while conversations are active
+	PollMinInterval time.Duration `yaml:"poll_min_interval" env:"REPLY_POLL_MIN_INTERVAL"`
+	// PollMaxInterval is the longest wait between mention polls, reached after
+	// a quiet period; zero for both keeps the interval fixed
+	PollMaxInterval time.Duration `yaml:"poll_max_interval" env:"REPLY_POLL_MAX_INTERVAL"`
+	// ContextTokens is the token budget of the earlier posts included in a
+	// reply prompt; the oldest are left out first
+	ContextTokens int `yaml:"context_tokens" env:"REPLY_CONTEXT_TOKENS"`
+	// Examples is how many curated reply examples, the most similar to the
+	// tweet being answered, are included in a reply prompt; 0 leaves them out
+	Examples int `yaml:"examples" env:"REPLY_EXAMPLES"`
+	// Loops closes conversations that go in circles
+	Loops LoopConfig `yaml:"loops"`
+	// RecoveryLookback is how far back the bot's own tweets are compared with
+	// the database on startup, to record replies a crash kept from being
+	// saved; 0 skips the comparison
+	RecoveryLookback time.Duration `yaml:"recovery_lookback" env:"REPLY_RECOVERY_LOOKBACK"`
+	// LatencySLO is the longest a mention should wait for its reply; slower
+	// replies emit a reply_latency_exceeded event. 0 disables the events.
+	LatencySLO time.Duration `yaml:"latency_slo" env:"REPLY_LATENCY_SLO"`
+	// SelfCritique has every drafted reply critiqued and revised once before
+	// it is posted, at the cost of up to two more LLM calls per reply
+	SelfCritique bool `yaml:"self_critique" env:"REPLY_SELF_CRITIQUE"`
+	// Research runs the tool-calling loop on every mention before its reply
+	// is drafted, so facts it looks up, such as treasury balances, can be
+	// quoted. It costs at least one more LLM call per reply.
+	Research bool `yaml:"research" env:"REPLY_RESEARCH"`
+}
+
+// LoopConfig closes a conversation with a sign-off reply once the bot has
+// replied MaxBotReplies times in it or the user has sent Repeats tweets whose
+// words overlap by at least Similarity. A closed conversation is muted. Zero
+// disables a check.
+type LoopConfig struct {
+	MaxBotReplies int     `yaml:"max_bot_replies" env:"REPLY_LOOP_MAX_BOT_REPLIES"`
+	Repeats       int     `yaml:"repeats" env:"REPLY_LOOP_REPEATS"`
+	Similarity    float64 `yaml:"similarity" env:"REPLY_LOOP_SIMILARITY"`
+	// SignOff is the last reply; empty closes the conversation silently
+	SignOff string `yaml:"sign_off" env:"REPLY_LOOP_SIGN_OFF"`
+}
+
+// ReplyPriorityConfig weighs the signals of a mention's reply priority. Only
+// the ratios matter; all zero answers mentions oldest first.
+type ReplyPriorityConfig struct {
+	// Recency favours fresh tweets
+	Recency float64 `yaml:"recency" env:"REPLY_PRIORITY_RECENCY"`
+	// Followers favours widely followed and verified authors
+	Followers float64 `yaml:"followers" env:"REPLY_PRIORITY_FOLLOWERS_WEIGHT"`
+	// Heat favours conversations with a lot of recent activity
+	Heat float64 `yaml:"heat" env:"REPLY_PRIORITY_HEAT"`
+	// Addressed favours tweets that mention or reply to the bot directly
+	Addressed float64 `yaml:"addressed" env:"REPLY_PRIORITY_ADDRESSED"`
+}
+
+// PostingConfig restricts when the agent posts original thoughts and replies.
+// Posts that come due while posting is closed wait until it opens again.
+type PostingConfig struct {
+	// Timezone is the IANA timezone of the quiet hours; empty means UTC
+	Timezone string `yaml:"timezone" env:"POSTING_TIMEZONE"`
+	// QuietHours are daily "HH:MM-HH:MM" ranges without posts, such as
+	// "23:00-07:00"
+	QuietHours []string `yaml:"quiet_hours" env:"POSTING_QUIET_HOURS"`
+	// Embargoes are one-off RFC 3339 "start/end" periods without posts
+	Embargoes []string `yaml:"embargoes" env:"POSTING_EMBARGOES"`
+}
+
+// EventsConfig holds the external destinations for agent events
+type EventsConfig struct {
+	// WebhookURL receives every event as a JSON POST; empty disables the webhook
+	WebhookURL string `yaml:"webhook_url" env:"EVENTS_WEBHOOK_URL"`
+	// WebhookSecret signs webhook requests with HMAC-SHA256
+	WebhookSecret string `yaml:"webhook_secret" env:"EVENTS_WEBHOOK_SECRET"`
+	// NATSURL is the NATS server events are published to; empty disables NATS
+	NATSURL string `yaml:"nats_url" env:"EVENTS_NATS_URL"`
+	// NATSSubject prefixes the subjects, one per event type
+	NATSSubject string `yaml:"nats_subject" env:"EVENTS_NATS_SUBJECT"`
+}
+
+// AdminConfig holds the operator HTTP API settings
+type AdminConfig struct {
+	// Addr is where the admin API listens, e.g. 127.0.0.1:8090; empty disables it
+	Addr string `yaml:"addr" env:"ADMIN_ADDR"`
+	// Token must be sent as a bearer token with every request; it is required
+	// whenever Addr is set
+	Token string `yaml:"token" env:"ADMIN_TOKEN" redact:"true"`
+}
+
+// TaskConfig controls whether a task runs, how often, and how the supervisor
+// restarts it when it exits
+type TaskConfig struct {
+	// Enabled set to false keeps the task from starting; nil means enabled.
+	// Tasks can also be switched on and off at runtime through the admin API.
+	Enabled *bool `yaml:"enabled"`
+	// Interval overrides how often the task runs; zero keeps its built-in interval
+	Interval time.Duration `yaml:"interval"`
+	// Restart is always, on-failure or never
+	Restart string `yaml:"restart" env:"TASK_RESTART"`
+	// MaxRestarts is how many restarts are attempted before the task is given
+	// up on; negative means no limit
+	MaxRestarts int `yaml:"max_restarts" env:"TASK_MAX_RESTARTS"`
+	// Backoff is the delay before the first restart; it doubles up to MaxBackoff
+	Backoff    time.Duration `yaml:"backoff" env:"TASK_RESTART_BACKOFF"`
+	MaxBackoff time.Duration `yaml:"max_backoff" env:"TASK_RESTART_MAX_BACKOFF"`
+}
+
+// TasksConfig holds the supervision settings of the agent's tasks
+type TasksConfig struct {
+	// Defaults apply to every task
+	Defaults TaskConfig `yaml:"defaults"`
+	// Overrides are keyed by task name, e.g. mentions_handler, or
+	// catlord/mentions_handler when running several accounts (YAML only)
+	Overrides map[string]TaskConfig `yaml:"overrides"`
+}
+
+// For returns the settings of a task: its override, with fields left empty
+// taken from the defaults
+func (c TasksConfig) For(name string) TaskConfig {
+	settings := c.Defaults
+	override, ok := c.Overrides[name]
+	if !ok {
+		return settings
+	}
+	if override.Enabled != nil {
+		settings.Enabled = override.Enabled
+	}
+	if override.Interval != 0 {
+		settings.Interval = override.Interval
+	}
+	if override.Restart != "" {
+		settings.Restart = override.Restart
+	}
+	if override.MaxRestarts != 0 {
+		settings.MaxRestarts = override.MaxRestarts
+	}
+	if override.Backoff != 0 {
+		settings.Backoff = override.Backoff
+	}
+	if override.MaxBackoff != 0 {
+		settings.MaxBackoff = override.MaxBackoff
+	}
+	return settings
+}
+
+// IsEnabled reports whether the task should run
+func (c TaskConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// ExperimentConfig describes an A/B test of reply prompt variants
+type ExperimentConfig struct {
+	Enabled  bool            `yaml:"enabled"`
+	Name     string          `yaml:"name"`
+	Variants []VariantConfig `yaml:"variants"`
+}
+
+// VariantConfig is one prompt variant of an experiment
+type VariantConfig struct {
+	Name string `yaml:"name"`
+	// Temperature overrides the reply temperature; 0 keeps the default
+	Temperature float64 `yaml:"temperature"`
+	// Instructions are added to the reply prompt's requirements
+	Instructions string `yaml:"instructions"`
+	// Weight is the variant's relative share of replies; 0 counts as 1
+	Weight int `yaml:"weight"`
+}
+
+// PluginConfig selects a plugin providing extra actions
+type PluginConfig struct {
+	// Name identifies the plugin; without a path, it names a plugin compiled
+	// into the agent
+	Name string `yaml:"name"`
+	// Path is the .so file of a plugin built with -buildmode=plugin
+	Path string `yaml:"path"`
+	// Settings are handed to the plugin as they are
+	Settings map[string]string `yaml:"settings"`
+}
+
+// AccountConfig describes one bot persona run by the agent. Twitter settings left
+// empty are inherited from the top-level Twitter section.
+type AccountConfig struct {
+	Name        string        `yaml:"name"`
+	Username    string        `yaml:"username"`
+	DisplayName string        `yaml:"display_name"`
+	Twitter     TwitterConfig `yaml:"twitter"`
+	// PersonalityFile is a YAML map of prompt section to text, with optional
+	// per-category sections under categories; empty uses the built-in persona
+	PersonalityFile string `yaml:"personality_file"`
+	// TweetsPerWindow caps replies per 15 minute window for this account
+	TweetsPerWindow int `yaml:"tweets_per_window"`
+}
+
+// DefaultAccountName is used for the implicit account built from top-level settings
+const DefaultAccountName = "default"
+
+// ResolvedAccounts returns the configured accounts with inherited Twitter settings
+// applied, or a single default account when none are configured
+func (c *Config) ResolvedAccounts() []AccountConfig {
+	if len(c.Accounts) == 0 {
+		return []AccountConfig{{
+			Name:    DefaultAccountName,
+			Twitter: c.Twitter,
+		}}
+	}
+
+	accounts := make([]AccountConfig, len(c.Accounts))
+	for i, account := range c.Accounts {
+		tw := &account.Twitter
+		if tw.BaseURL == "" {
+			tw.BaseURL = c.Twitter.BaseURL
+		}
+		if tw.RateLimit == 0 {
+			tw.RateLimit = c.Twitter.RateLimit
+		}
+		if tw.RateWindow == 0 {
+			tw.RateWindow = c.Twitter.RateWindow
+		}
+		if tw.RetryAttempts == 0 {
+			tw.RetryAttempts = c.Twitter.RetryAttempts
+		}
+		if tw.RetryBackoff == 0 {
+			tw.RetryBackoff = c.Twitter.RetryBackoff
+		}
+		if tw.ProxyURL == "" {
+			tw.ProxyURL = c.Twitter.ProxyURL
+		}
+		if tw.MaxIdleConnsPerHost == 0 {
+			tw.MaxIdleConnsPerHost = c.Twitter.MaxIdleConnsPerHost
+		}
+		if tw.IdleConnTimeout == 0 {
+			tw.IdleConnTimeout = c.Twitter.IdleConnTimeout
+		}
+		if !tw.Budget.Enabled() {
+			tw.Budget = c.Twitter.Budget
+		}
+		// Accounts with credentials of their own keep them
+		hasOwn := tw.ConsumerKey != "" || tw.BearerToken != ""
+		if !tw.OAuth2.Enabled() && !hasOwn {
+			tw.OAuth2 = c.Twitter.OAuth2
+		}
+		accounts[i] = account
+	}
+	return accounts
+}
+
+// AgentConfig holds process-level agent settings
+type AgentConfig struct {
+	CrashStateFile string `yaml:"crash_state_file" env:"AGENT_CRASH_STATE_FILE"`
+	// DryRun records tweets and transfers in the database instead of publishing them
+	DryRun bool `yaml:"dry_run" env:"AGENT_DRY_RUN"`
+	// SkipPreflight starts the agent without first checking the database,
+	// credentials and RPC endpoints
+	SkipPreflight bool `yaml:"skip_preflight" env:"AGENT_SKIP_PREFLIGHT"`
+}
+
+// DebugConfig holds troubleshooting settings
+type DebugConfig struct {
+	// HTTPRecord writes every Twitter API request and response, with
+	// credentials redacted, to a JSON lines file per account in HTTPRecordDir
+	HTTPRecord    bool   `yaml:"http_record" env:"DEBUG_HTTP_RECORD"`
+	HTTPRecordDir string `yaml:"http_record_dir" env:"DEBUG_HTTP_RECORD_DIR"`
+	// HTTPLogSample logs one in every HTTPLogSample successful Twitter API
+	// requests at debug level; failed requests are always logged
+	HTTPLogSample int `yaml:"http_log_sample" env:"DEBUG_HTTP_LOG_SAMPLE"`
+	// HTTPLogBodyLimit truncates logged request and response bodies to this
+	// many bytes; 0 leaves bodies out
+	HTTPLogBodyLimit int `yaml:"http_log_body_limit" env:"DEBUG_HTTP_LOG_BODY_LIMIT"`
+}
+
+// Default returns a Config populated with built-in defaults
+func Default() *Config {
+	return &Config{
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Database: DatabaseConfig{
+			Driver:              "postgres",
+			Path:                "data/agent.db",
+			Host:                "localhost",
+			Port:                "5432",
+			SSLMode:             "disable",
+			MaxOpenConns:        25,
+			MaxIdleConns:        25,
+			ConnMaxLifetime:     15 * time.Minute,
+			ConnMaxIdleTime:     5 * time.Minute,
+			ConnectRetries:      5,
+			HealthCheckInterval: 30 * time.Second,
+			ReconnectMaxBackoff: time.Minute,
+			StatementTimeout:    30 * time.Second,
+		},
+		Twitter: TwitterConfig{
+			BaseURL:             "https://api.twitter.com/2",
+			RateLimit:           180,
+			RateWindow:          15,
+			RetryAttempts:       3,
+			RetryBackoff:        500 * time.Millisecond,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+			Budget: BudgetConfig{
+				Window: 15 * time.Minute,
+			},
+			OAuth2: OAuth2Config{
+				RedirectURL:   "http://127.0.0.1:8765/callback",
+				RefreshBefore: 5 * time.Minute,
+			},
+		},
+		Debug: DebugConfig{
+			HTTPRecordDir:    "data/http",
+			HTTPLogSample:    10,
+			HTTPLogBodyLimit: 2048,
+		},
+		Farcaster: FarcasterConfig{
+			NeynarURL: "https://api.neynar.com/v2/farcaster",
+		},
+		Lens: LensConfig{
+			APIURL: "https://api-v2.lens.dev",
+		},
+		IPFS: IPFSConfig{
+			GatewayURL: "https://ipfs.io/ipfs/",
+		},
+		Archive: ArchiveConfig{
+			QuietPeriod: 24 * time.Hour,
+		},
+		Retention: RetentionConfig{
+			BatchSize: 500,
+			Dir:       "data/retention",
+			S3Prefix:  "retention/",
+		},
+		ImageGen: ImageGenConfig{
+			Probability:   0.1,
+			MinEngagement: 10,
+		},
+		OpenAI: OpenAIConfig{
+			Model:          "gpt-4",
+			Temperature:    0.7,
+			MaxTokens:      1000,
+			BaseURL:        "https://api.openai.com/v1",
+			EmbeddingModel: "text-embedding-3-small",
+
+			Timeout:          30 * time.Second,
+			TimeoutRetries:   1,
+			BreakerThreshold: 5,
+			BreakerCooldown:  time.Minute,
+			FineTuned: FineTunedConfig{
+				Fallback:         true,
+				FallbackCooldown: 10 * time.Minute,
+			},
+			Routing: RoutingConfig{
+				MaxCheapThread:  2,
+				StrongFollowers: 10000,
+			},
+		},
+		Masa: MasaConfig{
+			APIEndpoint:           "http://localhost:8080/api/v1/data/twitter/tweets/recent",
+			RequestTimeoutSeconds: 120,
+			TweetsPerRequest:      5,
+		},
+		Market: MarketConfig{
+			CoinGeckoURL:   "https://api.coingecko.com/api/v3",
+			DexScreenerURL: "https://api.dexscreener.com/latest/dex",
+			CacheTTL:       5 * time.Minute,
+			Watchlist:      []string{"BTC", "ETH", "SOL"},
+		},
+		Filters: FilterConfig{
+			SpamThreshold:   0.7,
+			MinAccountAge:   30 * 24 * time.Hour,
+			DuplicateWindow: 24 * time.Hour,
+		},
+		Events: EventsConfig{
+			NATSSubject: "agent.events",
+		},
+		Replies: ReplyConfig{
+			MaxPerUserPerDay:     20,
+			MaxConversationDepth: 15,
+			ThreadCooldown:       time.Minute,
+			Workers:              2,
+			VisibilityTimeout:    5 * time.Minute,
+			MaxAttempts:          3,
+			RetryDelay:           time.Minute,
+			PriorityFollowers:    10000,
+			Priority: ReplyPriorityConfig{
+				Recency:   1,
+				Followers: 1,
+				Heat:      1,
+				Addressed: 1,
+			},
+			PollMinInterval: 30 * time.Second,
+			PollMaxInterval: 10 * time.Minute,
+			ContextTokens:   1500,
+			Examples:        3,
+			Loops: LoopConfig{
+				MaxBotReplies: 10,
+				Repeats:       3,
+				Similarity:    0.8,
+				SignOff:       "This audience is over. The cat lord has other subjects to attend to.",
+			},
+			RecoveryLookback: 24 * time.Hour,
+			LatencySLO:       15 * time.Minute,
+		},
+		Tips: TipsConfig{
+			MaxAmount: 1000,
+		},
+		Tasks: TasksConfig{
+			Defaults: TaskConfig{
+				Restart:     "on-failure",
+				MaxRestarts: 10,
+				Backoff:     5 * time.Second,
+				MaxBackoff:  5 * time.Minute,
+			},
+		},
+	}
+}
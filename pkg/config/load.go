@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the environment variable that points at a YAML config file
+const ConfigFileEnv = "AGENT_CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Flags are the command-line settings, which override the config file and
+// the environment
+type Flags struct {
+	// ConfigFile is the YAML config file; empty falls back to AGENT_CONFIG_FILE
+	ConfigFile    string
+	LogLevel      string
+	LogFormat     string
+	DryRun        bool
+	SkipPreflight bool
+}
+
+// Resolve builds the configuration from defaults, the YAML file given by
+// --config or AGENT_CONFIG_FILE, environment variables and flags, without
+// validating it, so commands that only need part of it (e.g. migrate) can
+// check just what they use.
+func Resolve(flags Flags) (*Config, error) {
+	// .env is optional; real environment variables take precedence over it
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error loading .env file: %w", err)
+	}
+
+	cfg := Default()
+
+	path := flags.ConfigFile
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if flags.LogLevel != "" {
+		cfg.Log.Level = flags.LogLevel
+	}
+	if flags.LogFormat != "" {
+		cfg.Log.Format = flags.LogFormat
+	}
+	if flags.DryRun {
+		cfg.Agent.DryRun = true
+	}
+	if flags.SkipPreflight {
+		cfg.Agent.SkipPreflight = true
+	}
+
+	return cfg, nil
+}
+
+// FromEnv returns the defaults overlaid with environment variables, without
+// validation. It backs the per-package env constructors kept for tests and tools.
+func FromEnv() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error loading .env file: %w", err)
+	}
+
+	cfg := Default()
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// GetString returns the configured value for an environment variable name, so the
+// Config can stand in wherever an env lookup is expected (e.g. memory.EnvConfig)
+func (c *Config) GetString(key string) string {
+	var result string
+	walkEnvFields(reflect.ValueOf(c).Elem(), func(name string, field reflect.Value) bool {
+		if name != key {
+			return true
+		}
+		result = formatValue(field)
+		return false
+	})
+	return result
+}
+
+// loadFile overlays a YAML config file onto the current values
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overlays every field tagged with `env` that is set in the environment
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	walkEnvFields(reflect.ValueOf(c).Elem(), func(name string, field reflect.Value) bool {
+		raw, ok := lookup(name)
+		if !ok || strings.TrimSpace(raw) == "" {
+			return true
+		}
+		if err := setValue(field, strings.TrimSpace(raw)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", name, err))
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// walkEnvFields calls fn for each env-tagged field until fn returns false
+func walkEnvFields(v reflect.Value, fn func(name string, field reflect.Value) bool) bool {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			if !walkEnvFields(field, fn) {
+				return false
+			}
+			continue
+		}
+		if name := t.Field(i).Tag.Get("env"); name != "" {
+			if !fn(name, field) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func setValue(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+	return nil
+}
+
+func formatValue(field reflect.Value) string {
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String()
+	}
+	switch field.Kind() {
+	case reflect.Slice:
+		return strings.Join(field.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(field.Interface())
+	}
+}
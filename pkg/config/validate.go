@@ -0,0 +1,616 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/sirupsen/logrus"
+)
+
+// Validate checks that required settings are present and values are in range.
+// All problems are reported together so they can be fixed in one pass.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "", "text", "json":
+	default:
+		errs = append(errs, fmt.Errorf("log.format: must be text or json, got %q", c.Log.Format))
+	}
+
+	errs = append(errs, c.validateDatabase()...)
+
+	seen := make(map[string]bool)
+	for _, account := range c.ResolvedAccounts() {
+		prefix := "twitter"
+		if len(c.Accounts) > 0 {
+			prefix = fmt.Sprintf("accounts[%s].twitter", account.Name)
+			if account.Name == "" {
+				errs = append(errs, fmt.Errorf("accounts: every account needs a name"))
+			} else if seen[account.Name] {
+				errs = append(errs, fmt.Errorf("accounts: duplicate account name %q", account.Name))
+			}
+			seen[account.Name] = true
+		}
+		errs = append(errs, validateTwitter(prefix, account.Twitter)...)
+	}
+
+	errs = append(errs, validateFarcaster(c.Farcaster)...)
+	errs = append(errs, validateLens(c)...)
+	errs = append(errs, validateArchive(c)...)
+	errs = append(errs, validateRetention(c)...)
+	errs = append(errs, validateImageGen(c.ImageGen)...)
+	errs = append(errs, validateWalletNetworks(c.Wallet)...)
+	errs = append(errs, validateWalletWatch(c.Wallet)...)
+	errs = append(errs, validateWalletTools(c.Wallet)...)
+	errs = append(errs, validateTips(c.Tips, c.Wallet)...)
+
+	if c.OpenAI.APIKey == "" {
+		errs = append(errs, fmt.Errorf("openai.api_key (OPENAI_API_KEY) is required"))
+	}
+	if c.OpenAI.Temperature < 0 || c.OpenAI.Temperature > 1 {
+		errs = append(errs, fmt.Errorf("openai.temperature must be between 0 and 1"))
+	}
+	if c.OpenAI.MaxTokens < 1 {
+		errs = append(errs, fmt.Errorf("openai.max_tokens must be positive"))
+	}
+	if c.OpenAI.Timeout < 0 || c.OpenAI.BreakerCooldown < 0 || c.OpenAI.BreakerThreshold < 0 {
+		errs = append(errs, fmt.Errorf("openai.timeout, openai.breaker_threshold and openai.breaker_cooldown cannot be negative"))
+	}
+	if c.OpenAI.TimeoutRetries < -1 {
+		errs = append(errs, fmt.Errorf("openai.timeout_retries must be -1 (no retries) or more"))
+	}
+	if c.OpenAI.FineTuned.FallbackCooldown < 0 {
+		errs = append(errs, fmt.Errorf("openai.fine_tuned.fallback_cooldown cannot be negative"))
+	}
+	if c.OpenAI.Routing.MaxCheapThread < 0 || c.OpenAI.Routing.StrongFollowers < 0 {
+		errs = append(errs, fmt.Errorf("openai.routing.max_cheap_thread and openai.routing.strong_followers cannot be negative"))
+	}
+	for model, price := range c.OpenAI.Prices {
+		if price.Prompt < 0 || price.Completion < 0 {
+			errs = append(errs, fmt.Errorf("openai.prices.%s cannot be negative", model))
+		}
+	}
+
+	if c.Masa.RequestTimeoutSeconds < 1 {
+		errs = append(errs, fmt.Errorf("masa.request_timeout_seconds must be at least 1"))
+	}
+	if c.Masa.TweetsPerRequest < 1 {
+		errs = append(errs, fmt.Errorf("masa.tweets_per_request must be positive"))
+	}
+
+	if c.Market.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("market.cache_ttl cannot be negative"))
+	}
+
+	if c.Replies.MaxPerUserPerDay < 0 || c.Replies.MaxConversationDepth < 0 || c.Replies.ThreadCooldown < 0 {
+		errs = append(errs, fmt.Errorf("replies limits cannot be negative"))
+	}
+	if c.Replies.Workers < 1 {
+		errs = append(errs, fmt.Errorf("replies.workers must be at least 1"))
+	}
+	if c.Replies.VisibilityTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("replies.visibility_timeout must be positive"))
+	}
+	if c.Replies.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("replies.max_attempts must be at least 1"))
+	}
+	if c.Replies.RetryDelay < 0 || c.Replies.PriorityFollowers < 0 {
+		errs = append(errs, fmt.Errorf("replies.retry_delay and replies.priority_followers cannot be negative"))
+	}
+	if c.Replies.RecoveryLookback < 0 || c.Replies.LatencySLO < 0 {
+		errs = append(errs, fmt.Errorf("replies.recovery_lookback and replies.latency_slo cannot be negative"))
+	}
+	if w := c.Replies.Priority; w.Recency < 0 || w.Followers < 0 || w.Heat < 0 || w.Addressed < 0 {
+		errs = append(errs, fmt.Errorf("replies.priority weights cannot be negative"))
+	}
+	if c.Replies.PollMinInterval < 0 || c.Replies.PollMaxInterval < c.Replies.PollMinInterval {
+		errs = append(errs, fmt.Errorf("replies.poll_min_interval cannot be negative or above replies.poll_max_interval"))
+	}
+	if c.Replies.ContextTokens < 0 || c.Replies.Examples < 0 {
+		errs = append(errs, fmt.Errorf("replies.context_tokens and replies.examples cannot be negative"))
+	}
+	if loops := c.Replies.Loops; loops.MaxBotReplies < 0 || loops.Repeats < 0 || loops.Repeats == 1 {
+		errs = append(errs, fmt.Errorf("replies.loops.max_bot_replies cannot be negative and replies.loops.repeats must be 0 or at least 2"))
+	}
+	if c.Replies.Loops.Similarity < 0 || c.Replies.Loops.Similarity > 1 {
+		errs = append(errs, fmt.Errorf("replies.loops.similarity must be between 0 and 1"))
+	}
+	if _, err := schedule.New(c.Posting.Timezone, c.Posting.QuietHours, c.Posting.Embargoes); err != nil {
+		errs = append(errs, fmt.Errorf("posting: %w", err))
+	}
+
+	if c.Filters.SpamThreshold < 0 || c.Filters.SpamThreshold > 1 {
+		errs = append(errs, fmt.Errorf("filters.spam_threshold must be between 0 and 1"))
+	}
+	if c.Filters.MinAccountAge < 0 || c.Filters.DuplicateWindow < 0 {
+		errs = append(errs, fmt.Errorf("filters durations cannot be negative"))
+	}
+
+	if c.Events.WebhookURL != "" {
+		if u, err := url.Parse(c.Events.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("events.webhook_url must be an http or https URL"))
+		}
+	}
+	if c.Events.NATSURL != "" && c.Events.NATSSubject == "" {
+		errs = append(errs, fmt.Errorf("events.nats_subject is required when events.nats_url is set"))
+	}
+
+	if c.Admin.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Admin.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("admin.addr must be host:port: %w", err))
+		}
+		// The admin API approves tips and edits replies, so it is never
+		// served without a token
+		if c.Admin.Token == "" {
+			errs = append(errs, fmt.Errorf("admin.token is required when admin.addr is set"))
+		}
+	}
+
+	if c.Debug.HTTPLogSample < 1 {
+		errs = append(errs, fmt.Errorf("debug.http_log_sample must be at least 1"))
+	}
+	if c.Debug.HTTPLogBodyLimit < 0 {
+		errs = append(errs, fmt.Errorf("debug.http_log_body_limit cannot be negative"))
+	}
+
+	errs = append(errs, c.validateTasks()...)
+
+	if c.Experiment.Enabled {
+		if c.Experiment.Name == "" {
+			errs = append(errs, fmt.Errorf("experiment.name is required when experiments are enabled"))
+		}
+		if len(c.Experiment.Variants) < 2 {
+			errs = append(errs, fmt.Errorf("experiment.variants needs at least two variants"))
+		}
+		for _, variant := range c.Experiment.Variants {
+			if variant.Temperature < 0 || variant.Temperature > 1 {
+				errs = append(errs, fmt.Errorf("experiment variant %q: temperature must be between 0 and 1", variant.Name))
+			}
+		}
+	}
+
+	plugins := make(map[string]bool, len(c.Plugins))
+	for i, plugin := range c.Plugins {
+		if plugin.Name == "" {
+			errs = append(errs, fmt.Errorf("plugins[%d].name is required", i))
+			continue
+		}
+		if plugins[plugin.Name] {
+			errs = append(errs, fmt.Errorf("plugin %q is configured twice", plugin.Name))
+		}
+		plugins[plugin.Name] = true
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// validateTwitter checks one set of Twitter credentials and limits
+func validateTwitter(prefix string, tw TwitterConfig) []error {
+	var errs []error
+	hasOAuth := tw.ConsumerKey != "" && tw.ConsumerSecret != "" && tw.AccessToken != "" && tw.AccessTokenSecret != ""
+	if !hasOAuth && tw.BearerToken == "" && !tw.OAuth2.Enabled() {
+		errs = append(errs, fmt.Errorf("%s: either OAuth 1.0a credentials, an OAuth 2.0 client or a bearer token must be provided", prefix))
+	}
+	if tw.RateLimit < 1 {
+		errs = append(errs, fmt.Errorf("%s.rate_limit must be positive", prefix))
+	}
+	if tw.RateWindow < 1 {
+		errs = append(errs, fmt.Errorf("%s.rate_window must be positive", prefix))
+	}
+	if tw.RetryAttempts < 0 {
+		errs = append(errs, fmt.Errorf("%s.retry_attempts cannot be negative", prefix))
+	}
+	if tw.RetryBackoff < 0 {
+		errs = append(errs, fmt.Errorf("%s.retry_backoff cannot be negative", prefix))
+	}
+	if tw.MaxIdleConnsPerHost < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_idle_conns_per_host cannot be negative", prefix))
+	}
+	if tw.IdleConnTimeout < 0 {
+		errs = append(errs, fmt.Errorf("%s.idle_conn_timeout cannot be negative", prefix))
+	}
+	if tw.ProxyURL != "" {
+		if u, err := url.Parse(tw.ProxyURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s.proxy_url must be an absolute URL", prefix))
+		}
+	}
+	errs = append(errs, validateOAuth2(prefix+".oauth2", tw.OAuth2)...)
+	return append(errs, validateBudget(prefix+".budget", tw.Budget)...)
+}
+
+// validateOAuth2 checks the OAuth 2.0 client of an account
+func validateOAuth2(prefix string, oauth2 OAuth2Config) []error {
+	if !oauth2.Enabled() {
+		return nil
+	}
+	var errs []error
+	if _, err := oauth2.Key(); err != nil {
+		errs = append(errs, fmt.Errorf("%s.token_key: %w", prefix, err))
+	}
+	if u, err := url.Parse(oauth2.RedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%s.redirect_url must be an absolute http(s) URL", prefix))
+	}
+	if oauth2.RefreshBefore < 0 {
+		errs = append(errs, fmt.Errorf("%s.refresh_before cannot be negative", prefix))
+	}
+	if len(oauth2.Scopes) > 0 && !slices.Contains(oauth2.Scopes, "offline.access") {
+		errs = append(errs, fmt.Errorf("%s.scopes must include offline.access so the token can be refreshed", prefix))
+	}
+	return errs
+}
+
+// validateBudget checks the partitioning of an account's requests
+func validateBudget(prefix string, budget BudgetConfig) []error {
+	var errs []error
+	if budget.Requests < 0 {
+		errs = append(errs, fmt.Errorf("%s.requests cannot be negative", prefix))
+	}
+	if budget.Enabled() && budget.Window <= 0 {
+		errs = append(errs, fmt.Errorf("%s.window must be positive", prefix))
+	}
+	var total float64
+	for share, fraction := range budget.Shares {
+		if fraction <= 0 || fraction > 1 {
+			errs = append(errs, fmt.Errorf("%s.shares[%s] must be above 0 and at most 1", prefix, share))
+		}
+		total += fraction
+	}
+	if total > 1.0001 {
+		errs = append(errs, fmt.Errorf("%s.shares add up to more than 1", prefix))
+	}
+	if len(budget.Shares) > 0 {
+		for action, share := range budget.Actions {
+			if _, ok := budget.Shares[share]; !ok {
+				errs = append(errs, fmt.Errorf("%s.actions[%s]: unknown share %q", prefix, action, share))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateTasks checks only the task settings, for reloading them at runtime
+func (c *Config) ValidateTasks() error {
+	if err := errors.Join(c.validateTasks()...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// validateTasks checks the default and per-task settings
+func (c *Config) validateTasks() []error {
+	errs := validateTask("tasks.defaults", c.Tasks.Defaults)
+	for name := range c.Tasks.Overrides {
+		errs = append(errs, validateTask(fmt.Sprintf("tasks.overrides[%s]", name), c.Tasks.For(name))...)
+	}
+	return errs
+}
+
+// validateTask checks one task's restart settings
+func validateTask(prefix string, task TaskConfig) []error {
+	var errs []error
+	switch task.Restart {
+	case "always", "on-failure", "never":
+	default:
+		errs = append(errs, fmt.Errorf("%s.restart must be always, on-failure or never, got %q", prefix, task.Restart))
+	}
+	if task.Backoff < 0 || task.MaxBackoff < 0 {
+		errs = append(errs, fmt.Errorf("%s restart backoff cannot be negative", prefix))
+	}
+	if task.Interval < 0 {
+		errs = append(errs, fmt.Errorf("%s.interval cannot be negative", prefix))
+	}
+	if task.MaxBackoff > 0 && task.MaxBackoff < task.Backoff {
+		errs = append(errs, fmt.Errorf("%s.max_backoff cannot be less than %s.backoff", prefix, prefix))
+	}
+	return errs
+}
+
+// ValidateDatabase checks only the database settings, for commands such as
+// migrate that never talk to Twitter or the LLM
+func (c *Config) ValidateDatabase() error {
+	if err := errors.Join(c.validateDatabase()...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// validateDatabase checks the settings required by the selected driver
+func (c *Config) validateDatabase() []error {
+	var errs []error
+	switch c.Database.Driver {
+	case "postgres":
+		if c.Database.Host == "" {
+			errs = append(errs, fmt.Errorf("database.host (DB_HOST) is required"))
+		}
+		if c.Database.Port == "" {
+			errs = append(errs, fmt.Errorf("database.port (DB_PORT) is required"))
+		}
+		if c.Database.Name == "" {
+			errs = append(errs, fmt.Errorf("database.name (DB_NAME) is required"))
+		}
+		if c.Database.User == "" {
+			errs = append(errs, fmt.Errorf("database.user (DB_USER) is required"))
+		}
+	case "sqlite":
+		if c.Database.Path == "" {
+			errs = append(errs, fmt.Errorf("database.path (DB_PATH) is required for sqlite"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("database.driver (DB_DRIVER) must be postgres or sqlite, got %q", c.Database.Driver))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("database connection limits cannot be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("database.max_idle_conns cannot exceed database.max_open_conns"))
+	}
+	if c.Database.ConnectRetries < 0 {
+		errs = append(errs, fmt.Errorf("database.connect_retries cannot be negative"))
+	}
+	if c.Database.HealthCheckInterval < 0 || c.Database.ReconnectMaxBackoff < 0 || c.Database.StatementTimeout < 0 {
+		errs = append(errs, fmt.Errorf("database health check and timeout durations cannot be negative"))
+	}
+	if len(c.Database.ReplicaDSNs) > 0 && c.Database.Driver == "sqlite" {
+		errs = append(errs, fmt.Errorf("database.replica_dsns requires the postgres driver"))
+	}
+	return errs
+}
+
+// validateFarcaster checks the Farcaster settings when an account is configured
+func validateFarcaster(settings FarcasterConfig) []error {
+	if !settings.Enabled() {
+		return nil
+	}
+
+	var errs []error
+	if settings.FID < 0 {
+		errs = append(errs, fmt.Errorf("farcaster.fid must be positive"))
+	}
+	if settings.NeynarAPIKey == "" {
+		errs = append(errs, fmt.Errorf("farcaster.neynar_api_key (FARCASTER_NEYNAR_API_KEY) is required"))
+	}
+	if settings.SignerUUID == "" {
+		errs = append(errs, fmt.Errorf("farcaster.signer_uuid (FARCASTER_SIGNER_UUID) is required to publish casts"))
+	}
+	for _, endpoint := range []struct{ name, url string }{
+		{"neynar_url", settings.NeynarURL},
+		{"hub_url", settings.HubURL},
+	} {
+		if endpoint.url == "" {
+			continue
+		}
+		if u, err := url.Parse(endpoint.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("farcaster.%s must be an http or https URL", endpoint.name))
+		}
+	}
+	return errs
+}
+
+// validateLens checks the Lens settings and the wallet and IPFS settings
+// publishing depends on
+func validateLens(c *Config) []error {
+	var errs []error
+	for _, endpoint := range []struct{ name, url string }{
+		{"ipfs.api_url", c.IPFS.APIURL},
+		{"ipfs.gateway_url", c.IPFS.GatewayURL},
+	} {
+		if endpoint.url == "" {
+			continue
+		}
+		if u, err := url.Parse(endpoint.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("%s must be an http or https URL", endpoint.name))
+		}
+	}
+
+	if !c.Lens.Enabled() {
+		return errs
+	}
+	if !strings.HasPrefix(c.Lens.ProfileID, "0x") {
+		errs = append(errs, fmt.Errorf("lens.profile_id must be a 0x-prefixed hex profile ID"))
+	}
+	if c.Wallet.PrivateKey == "" {
+		errs = append(errs, fmt.Errorf("wallet.private_key (WALLET_PRIVATE_KEY) is required to sign lens publications"))
+	}
+	if !c.IPFS.Enabled() {
+		errs = append(errs, fmt.Errorf("ipfs.api_url (IPFS_API_URL) is required to pin lens metadata"))
+	}
+	if c.Lens.APIURL != "" {
+		if u, err := url.Parse(c.Lens.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("lens.api_url must be an http or https URL"))
+		}
+	}
+	return errs
+}
+
+// validateArchive checks the settings of the configured archive backend
+func validateArchive(c *Config) []error {
+	var errs []error
+	if c.Archive.QuietPeriod < 0 {
+		errs = append(errs, fmt.Errorf("archive.quiet_period cannot be negative"))
+	}
+
+	switch c.Archive.Backend {
+	case "":
+	case "ipfs":
+		if !c.IPFS.Enabled() {
+			errs = append(errs, fmt.Errorf("ipfs.api_url (IPFS_API_URL) is required by the ipfs archive backend"))
+		}
+	case "s3":
+		if c.Archive.S3Bucket == "" || c.Archive.S3Region == "" {
+			errs = append(errs, fmt.Errorf("archive.s3_bucket and archive.s3_region are required by the s3 archive backend"))
+		}
+		if c.Archive.S3AccessKeyID == "" || c.Archive.S3SecretAccessKey == "" {
+			errs = append(errs, fmt.Errorf("archive.s3_access_key_id and archive.s3_secret_access_key are required by the s3 archive backend"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("archive.backend must be ipfs or s3, got %q", c.Archive.Backend))
+	}
+	return errs
+}
+
+// validateRetention checks the retention ages and the sink pruned rows are
+// archived to
+func validateRetention(c *Config) []error {
+	var errs []error
+	r := c.Retention
+	if r.Conversations < 0 || r.Judgments < 0 || r.Analytics < 0 || r.Quota < 0 {
+		errs = append(errs, fmt.Errorf("retention ages cannot be negative"))
+	}
+	if r.Enabled() && r.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("retention.batch_size must be positive"))
+	}
+
+	switch r.Sink {
+	case "":
+	case "file":
+		if r.Dir == "" {
+			errs = append(errs, fmt.Errorf("retention.dir is required by the file retention sink"))
+		}
+	case "s3":
+		if r.S3Bucket == "" {
+			errs = append(errs, fmt.Errorf("retention.s3_bucket is required by the s3 retention sink"))
+		}
+		if r.S3Region == "" && c.Archive.S3Region == "" {
+			errs = append(errs, fmt.Errorf("retention.s3_region or archive.s3_region is required by the s3 retention sink"))
+		}
+		if (r.S3AccessKeyID == "" || r.S3SecretAccessKey == "") && (c.Archive.S3AccessKeyID == "" || c.Archive.S3SecretAccessKey == "") {
+			errs = append(errs, fmt.Errorf("retention or archive S3 credentials are required by the s3 retention sink"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("retention.sink must be file or s3, got %q", r.Sink))
+	}
+	return errs
+}
+
+// validateImageGen checks the meme image generation settings
+func validateImageGen(c ImageGenConfig) []error {
+	var errs []error
+	switch c.Provider {
+	case "", "openai":
+	case "stability":
+		if c.APIKey == "" {
+			errs = append(errs, fmt.Errorf("imagegen.api_key (IMAGEGEN_API_KEY) is required by the stability provider"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("imagegen.provider must be openai or stability, got %q", c.Provider))
+	}
+	if c.Probability < 0 || c.Probability > 1 {
+		errs = append(errs, fmt.Errorf("imagegen.probability must be between 0 and 1"))
+	}
+	if c.MinEngagement < 0 {
+		errs = append(errs, fmt.Errorf("imagegen.min_engagement cannot be negative"))
+	}
+	return errs
+}
+
+// validateWalletNetworks checks the custom EVM chains
+func validateWalletNetworks(c WalletConfig) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, network := range c.Networks {
+		prefix := fmt.Sprintf("wallet.networks[%d]", i)
+		if network.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name is required", prefix))
+		} else if name := strings.ToUpper(network.Name); seen[name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate network name %q", prefix, network.Name))
+		} else {
+			seen[name] = true
+		}
+		if network.ChainID <= 0 {
+			errs = append(errs, fmt.Errorf("%s.chain_id must be positive", prefix))
+		}
+		if u, err := url.Parse(network.RPCURL); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss") {
+			errs = append(errs, fmt.Errorf("%s.rpc_url must be an http(s) or ws(s) URL", prefix))
+		}
+		if network.GasLimitMultiplier < 0 {
+			errs = append(errs, fmt.Errorf("%s.gas_limit_multiplier cannot be negative", prefix))
+		}
+		switch network.L1Fee {
+		case "", "op-stack", "arbitrum":
+		default:
+			errs = append(errs, fmt.Errorf("%s.l1_fee must be op-stack or arbitrum, got %q", prefix, network.L1Fee))
+		}
+	}
+	return errs
+}
+
+// validateWalletWatch checks the incoming transfer watch
+func validateWalletWatch(c WalletConfig) []error {
+	var errs []error
+	if c.ThankMinAmount < 0 {
+		errs = append(errs, fmt.Errorf("wallet.thank_min_amount cannot be negative"))
+	}
+	if c.ThankTransfers && !c.WatchTransfers {
+		errs = append(errs, fmt.Errorf("wallet.thank_transfers requires wallet.watch_transfers (WALLET_WATCH_TRANSFERS)"))
+	}
+	if !c.WatchTransfers {
+		return errs
+	}
+	if c.PrivateKey == "" {
+		errs = append(errs, fmt.Errorf("wallet.private_key (WALLET_PRIVATE_KEY) is required to watch incoming transfers"))
+	}
+	if c.ETHRPCURL == "" && c.BaseRPCURL == "" && c.BSCRPCURL == "" && c.PolygonRPCURL == "" &&
+		c.ArbitrumRPCURL == "" && c.OptimismRPCURL == "" && len(c.Networks) == 0 {
+		errs = append(errs, fmt.Errorf("wallet.watch_transfers needs at least one network RPC URL"))
+	}
+	return errs
+}
+
+// validateWalletTools checks the treasury query tools
+func validateWalletTools(c WalletConfig) []error {
+	var errs []error
+	if c.TreasuryAddress != "" && !common.IsHexAddress(c.TreasuryAddress) && !strings.Contains(c.TreasuryAddress, ".") {
+		errs = append(errs, fmt.Errorf("wallet.treasury_address must be an address or an ENS name, got %q", c.TreasuryAddress))
+	}
+	if !c.QueryTools {
+		return errs
+	}
+	if c.PrivateKey == "" {
+		errs = append(errs, fmt.Errorf("wallet.private_key (WALLET_PRIVATE_KEY) is required for the wallet query tools"))
+	}
+	if c.TokenContractAddress != "" && !common.IsHexAddress(c.TokenContractAddress) {
+		errs = append(errs, fmt.Errorf("wallet.token_contract_address must be a token contract address, got %q", c.TokenContractAddress))
+	}
+	if c.ETHRPCURL == "" && c.BaseRPCURL == "" && c.BSCRPCURL == "" && c.PolygonRPCURL == "" &&
+		c.ArbitrumRPCURL == "" && c.OptimismRPCURL == "" && len(c.Networks) == 0 {
+		errs = append(errs, fmt.Errorf("wallet.query_tools needs at least one network RPC URL"))
+	}
+	return errs
+}
+
+// validateTips checks the tip command
+func validateTips(c TipsConfig, w WalletConfig) []error {
+	var errs []error
+	if c.MaxAmount < 0 || c.DailyLimit < 0 || c.ApprovalAbove < 0 {
+		errs = append(errs, fmt.Errorf("tips.max_amount, tips.daily_limit and tips.approval_above cannot be negative"))
+	}
+	if !c.Enabled {
+		return errs
+	}
+	if c.MaxAmount == 0 {
+		errs = append(errs, fmt.Errorf("tips.max_amount (TIPS_MAX_AMOUNT) must be positive to enable tips"))
+	}
+	if c.Network == "" {
+		errs = append(errs, fmt.Errorf("tips.network (TIPS_NETWORK) is required to enable tips"))
+	}
+	if w.PrivateKey == "" {
+		errs = append(errs, fmt.Errorf("wallet.private_key (WALLET_PRIVATE_KEY) is required to send tips"))
+	}
+	if !common.IsHexAddress(w.TokenContractAddress) {
+		errs = append(errs, fmt.Errorf("wallet.token_contract_address (TOKEN_CONTRACT_ADDRESS) must be the token to tip in"))
+	}
+	return errs
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/sirupsen/logrus"
+)
+
+// Errors of ConnectTwitter, telling unusable settings and rejected credentials
+// from an API that could not be reached
+var (
+	ErrTwitterConfig      = errors.New("invalid Twitter configuration")
+	ErrTwitterAuth        = errors.New("Twitter authentication failed")
+	ErrTwitterUnavailable = errors.New("Twitter API unavailable")
+)
+
+// ConnectTwitter creates a Twitter client from settings and resolves the
+// user ID of the account it authenticates as, which the account's store is
+// created with
+func ConnectTwitter(ctx context.Context, logger *logrus.Logger, settings config.TwitterConfig, opts ...twitter.ClientOption) (*twitter.TwitterClient, string, error) {
+	logger.Info("Initializing Twitter client")
+	twitterConfig, err := twitter.NewTwitterConfigFrom(settings, logger)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to create Twitter config: %v", ErrTwitterConfig, err)
+	}
+
+	twitterClient, err := twitter.NewTwitterClient(twitterConfig, opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to create Twitter client: %v", ErrTwitterConfig, err)
+	}
+
+	botID, err := twitterClient.GetAuthenticatedUserID(ctx)
+	if err != nil {
+		if credentialsRejected(err) {
+			return nil, "", fmt.Errorf("%w: failed to get bot user ID: %w", ErrTwitterAuth, err)
+		}
+		return nil, "", fmt.Errorf("%w: failed to get bot user ID: %w", ErrTwitterUnavailable, err)
+	}
+
+	logger.WithField("bot_id", botID).Info("Resolved bot user ID")
+
+	return twitterClient, botID, nil
+}
+
+// credentialsRejected reports whether err is the API refusing the account's
+// credentials, which no retry fixes, rather than an outage or a timeout
+func credentialsRejected(err error) bool {
+	if errors.Is(err, twitter.ErrOAuth2NotAuthorized) || errors.Is(err, twitter.ErrOAuth2Expired) {
+		return true
+	}
+	var apiErr *twitter.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,207 @@
+// Package dataset exports the bot's replies as fine-tuning examples: the
+// conversation leading up to each reply, the reply and the engagement it
+// received, split into training and validation sets.
+package dataset
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+)
+
+// Example formats
+const (
+	// FormatRaw writes the context, reply and engagement of each example
+	FormatRaw = "raw"
+	// FormatChat writes chat messages only, as fine-tuning APIs expect them
+	FormatChat = "chat"
+)
+
+// Default export settings
+const (
+	// DefaultContextTweets caps the conversation tweets before each reply
+	DefaultContextTweets = 10
+	// DefaultValidationFraction is the share of conversations held out for validation
+	DefaultValidationFraction = 0.1
+)
+
+// Options configures an export
+type Options struct {
+	// ContextTweets caps the conversation tweets preceding each reply
+	ContextTweets int
+	// MinEngagement skips replies with a lower engagement score
+	// (see memory.ReplyPerformance.Engagement)
+	MinEngagement int
+	// KeepPII leaves handles, URLs, emails and phone numbers in the text
+	KeepPII bool
+}
+
+// Turn is one tweet of an example's context
+type Turn struct {
+	// Role is "assistant" for the bot's tweets and "user" for everyone else's
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// Engagement is what a reply received
+type Engagement struct {
+	Likes    int `json:"likes"`
+	Retweets int `json:"retweets"`
+	Replies  int `json:"replies"`
+	Quotes   int `json:"quotes"`
+	Score    int `json:"score"`
+}
+
+// Example is a reply of the bot with the conversation it answered
+type Example struct {
+	ReplyID        string     `json:"reply_id"`
+	ConversationID string     `json:"conversation_id"`
+	Context        []Turn     `json:"context"`
+	Reply          string     `json:"reply"`
+	Engagement     Engagement `json:"engagement"`
+}
+
+// chatExample is an example as chat messages
+type chatExample struct {
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Build turns replies loaded with store.TrainingReplies into examples, in the
+// same order. Replies below the minimum engagement are left out.
+func Build(ctx context.Context, store *memory.TweetStore, replies []memory.TrainingReply, options Options) ([]Example, error) {
+	if options.ContextTweets <= 0 {
+		options.ContextTweets = DefaultContextTweets
+	}
+	redactor := newRedactor(store.Username())
+
+	conversations := make(map[string][]memory.TweetNeedingReply)
+	examples := make([]Example, 0, len(replies))
+	for _, reply := range replies {
+		if reply.Engagement() < options.MinEngagement {
+			continue
+		}
+
+		conversation, ok := conversations[reply.ConversationID]
+		if !ok && reply.ConversationID != "" {
+			tweets, err := store.ConversationTweets(ctx, reply.ConversationID)
+			if err != nil {
+				return nil, err
+			}
+			conversation = tweets
+			conversations[reply.ConversationID] = tweets
+		}
+
+		example := Example{
+			ReplyID:        reply.TweetID,
+			ConversationID: reply.ConversationID,
+			Context:        contextTurns(conversation, reply, store.BotID(), options.ContextTweets),
+			Reply:          reply.Text,
+			Engagement: Engagement{
+				Likes:    reply.Likes,
+				Retweets: reply.Retweets,
+				Replies:  reply.Replies,
+				Quotes:   reply.Quotes,
+				Score:    reply.Engagement(),
+			},
+		}
+		if !options.KeepPII {
+			for i := range example.Context {
+				example.Context[i].Text = redactor.redact(example.Context[i].Text)
+			}
+			example.Reply = redactor.redact(example.Reply)
+		}
+		examples = append(examples, example)
+	}
+	return examples, nil
+}
+
+// contextTurns returns the tweets of a conversation up to the one the reply
+// answered, at most limit of the latest. A parent missing from the stored
+// conversation is its only turn.
+func contextTurns(conversation []memory.TweetNeedingReply, reply memory.TrainingReply, botID string, limit int) []Turn {
+	var turns []Turn
+	found := false
+	for _, tweet := range conversation {
+		if tweet.TweetID == reply.TweetID || tweet.CreatedAt.After(reply.CreatedAt) {
+			break
+		}
+		if tweet.Text == "" {
+			continue
+		}
+		role := "user"
+		if tweet.AuthorID == botID {
+			role = "assistant"
+		}
+		turns = append(turns, Turn{Role: role, Text: tweet.Text})
+		if tweet.TweetID == reply.ParentID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		turns = []Turn{{Role: "user", Text: reply.ParentText}}
+	}
+	if len(turns) > limit {
+		turns = turns[len(turns)-limit:]
+	}
+	return turns
+}
+
+// Split divides examples between training and validation by conversation, so
+// no conversation is split across the sets. The assignment is deterministic.
+func Split(examples []Example, validationFraction float64) (train, validation []Example) {
+	for _, example := range examples {
+		key := example.ConversationID
+		if key == "" {
+			key = example.ReplyID
+		}
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		if float64(h.Sum32()%1000) < validationFraction*1000 {
+			validation = append(validation, example)
+		} else {
+			train = append(train, example)
+		}
+	}
+	return train, validation
+}
+
+// Write encodes examples as JSON lines in the given format
+func Write(w io.Writer, examples []Example, format string) error {
+	if format != FormatRaw && format != FormatChat {
+		return fmt.Errorf("unknown dataset format %q", format)
+	}
+
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	for _, example := range examples {
+		var record interface{} = example
+		if format == FormatChat {
+			record = example.chat()
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode example %s: %w", example.ReplyID, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// chat converts the example to chat messages ending with the reply
+func (e Example) chat() chatExample {
+	messages := make([]chatMessage, 0, len(e.Context)+1)
+	for _, turn := range e.Context {
+		messages = append(messages, chatMessage{Role: turn.Role, Content: turn.Text})
+	}
+	messages = append(messages, chatMessage{Role: "assistant", Content: e.Reply})
+	return chatExample{Messages: messages}
+}
@@ -0,0 +1,40 @@
+package dataset
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	urlPattern    = regexp.MustCompile(`https?://\S+`)
+	handlePattern = regexp.MustCompile(`@(\w{1,15})`)
+	// Phone numbers need a country code or separators, so prices and IDs stay
+	phonePattern   = regexp.MustCompile(`\+\d[\d\s().-]{7,}\d|\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b`)
+	spacingPattern = regexp.MustCompile(`[ \t]{2,}`)
+)
+
+// redactor replaces personal information in tweet text with placeholders.
+// The bot's own handle is kept so examples still show who is addressed.
+type redactor struct {
+	botUsername string
+}
+
+func newRedactor(botUsername string) *redactor {
+	return &redactor{botUsername: strings.ToLower(strings.TrimPrefix(botUsername, "@"))}
+}
+
+// redact replaces emails, URLs, phone numbers and handles other than the
+// bot's. Emails go first, so their domain is not mistaken for a handle.
+func (r *redactor) redact(text string) string {
+	text = emailPattern.ReplaceAllString(text, "<email>")
+	text = urlPattern.ReplaceAllString(text, "<url>")
+	text = phonePattern.ReplaceAllString(text, "<phone>")
+	text = handlePattern.ReplaceAllStringFunc(text, func(handle string) string {
+		if r.botUsername != "" && strings.EqualFold(handle[1:], r.botUsername) {
+			return handle
+		}
+		return "@user"
+	})
+	return strings.TrimSpace(spacingPattern.ReplaceAllString(text, " "))
+}
@@ -5,69 +5,53 @@ import (
 	"os"
 	"path/filepath"
 
-	"gorm.io/gorm"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
 )
 
-// findProjectRoot looks for go.mod file to determine project root
-func findProjectRoot() (string, error) {
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-
-	for {
-		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
-			return dir, nil
-		}
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
 
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			return "", fmt.Errorf("could not find project root (go.mod)")
-		}
-		dir = parent
+// driverName returns the configured driver, defaulting to Postgres
+func driverName(settings config.DatabaseConfig) string {
+	if settings.Driver == "" {
+		return DriverPostgres
 	}
+	return settings.Driver
 }
 
-// constructDBURL creates the database URL from environment variables
-func constructDBURL() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
+// ensureSQLiteDir creates the directory holding the SQLite database file
+func ensureSQLiteDir(settings config.DatabaseConfig) error {
+	if driverName(settings) != DriverSQLite {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(settings.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create sqlite data directory: %w", err)
+	}
+	return nil
 }
 
-// ensureTweetCategoryEnum ensures the tweet_category enum type exists
-func ensureTweetCategoryEnum(db *gorm.DB) error {
-	var exists bool
-	err := db.Raw(`
-		SELECT EXISTS (
-			SELECT 1 FROM pg_type 
-			WHERE typname = 'tweet_category'
-		);
-	`).Scan(&exists).Error
-
-	if err != nil {
-		return err
+// constructDBURL creates the database URL from the database configuration
+func constructDBURL(settings config.DatabaseConfig) string {
+	if driverName(settings) == DriverSQLite {
+		return fmt.Sprintf("sqlite3://%s", settings.Path)
 	}
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		settings.User,
+		settings.Password,
+		settings.Host,
+		settings.Port,
+		settings.Name,
+		sslMode(settings),
+	)
+}
 
-	if !exists {
-		err := db.Exec(`
-			CREATE TYPE tweet_category AS ENUM (
-				'mention',
-				'reply',
-				'quote',
-				'retweet',
-				'dm',
-				'conversation'
-			);
-		`).Error
-		if err != nil {
-			return err
-		}
+// sslMode returns the configured SSL mode, defaulting to disable
+func sslMode(settings config.DatabaseConfig) string {
+	if settings.SSLMode == "" {
+		return "disable"
 	}
-
-	return nil
+	return settings.SSLMode
 }
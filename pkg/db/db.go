@@ -1,59 +1,146 @@
 package db
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"time"
 
+	"github.com/lisanmuaddib/agent-go/pkg/config"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-
-	"github.com/lisanmuaddib/agent-go/pkg/db/models"
 )
 
-// SetupDatabase initializes the database connection and runs migrations
+// SetupDatabase initializes the database connection from environment variables and runs migrations
 func SetupDatabase(logger *logrus.Logger) (*gorm.DB, error) {
+	cfg, err := config.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return SetupDatabaseWithConfig(logger, cfg.Database)
+}
+
+// SetupDatabaseWithConfig runs pending migrations and opens the database connection.
+// The schema is owned by the versioned migrations; GORM never alters it.
+func SetupDatabaseWithConfig(logger *logrus.Logger, settings config.DatabaseConfig) (*gorm.DB, error) {
+	return SetupDatabaseContext(context.Background(), logger, settings)
+}
+
+// SetupDatabaseContext is SetupDatabaseWithConfig with a context bounding the
+// connection retries. A database that is still starting up is retried with
+// exponential backoff up to settings.ConnectRetries times.
+func SetupDatabaseContext(ctx context.Context, logger *logrus.Logger, settings config.DatabaseConfig) (*gorm.DB, error) {
 	logger.Debug("Starting database setup")
 
-	projectRoot, err := findProjectRoot()
-	if err != nil {
-		return nil, fmt.Errorf("failed to find project root: %w", err)
+	delay := initialBackoff
+	for attempt := 0; ; attempt++ {
+		db, err := connect(ctx, logger, settings)
+		if err == nil {
+			logger.Info("Database setup completed successfully")
+			return db, nil
+		}
+		if attempt >= settings.ConnectRetries {
+			return nil, err
+		}
+
+		logger.WithError(err).WithFields(logrus.Fields{
+			"attempt":  attempt + 1,
+			"retry_in": delay,
+		}).Warn("Database not ready, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = nextBackoff(delay, settings.ReconnectMaxBackoff)
 	}
+}
 
-	// Run migrations
-	if err := RunMigrations(logger, projectRoot); err != nil {
+// connect migrates the schema, opens the GORM connection, applies the pool
+// settings and verifies the connection with a ping
+func connect(ctx context.Context, logger *logrus.Logger, settings config.DatabaseConfig) (*gorm.DB, error) {
+	// Bring the schema up to the latest version before opening the GORM connection
+	if err := RunMigrations(logger, settings); err != nil {
 		return nil, err
 	}
 
-	// Construct DSN
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-	)
-
-	logger.Debug("Establishing GORM database connection")
+	logger.WithField("driver", driverName(settings)).Debug("Establishing GORM database connection")
 
-	// Connect to database
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector(settings), &gorm.Config{
 		Logger: NewGormLogrusLogger(logger),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Ensure enum type exists
-	if err := ensureTweetCategoryEnum(db); err != nil {
-		return nil, fmt.Errorf("failed to ensure tweet_category enum: %w", err)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying database connection: %w", err)
+	}
+	configurePool(sqlDB, settings)
+
+	if err := registerStatementTimeout(db, settings.StatementTimeout); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	if err := registerReplicas(db, logger, settings); err != nil {
+		sqlDB.Close()
+		return nil, err
 	}
 
-	// Auto-migrate the schema
-	if err := db.AutoMigrate(&models.Tweet{}); err != nil {
-		return nil, fmt.Errorf("failed to auto-migrate database schema: %w", err)
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(pingCtx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	logger.Info("Database setup completed successfully")
 	return db, nil
 }
+
+// CheckConnection opens a connection to the primary, pings it and closes it
+// again. Unlike SetupDatabaseContext it neither retries nor migrates, so
+// preflight checks can tell whether the database is reachable without
+// changing it.
+func CheckConnection(ctx context.Context, logger *logrus.Logger, settings config.DatabaseConfig) error {
+	db, err := gorm.Open(dialector(settings), &gorm.Config{
+		Logger: NewGormLogrusLogger(logger),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying database connection: %w", err)
+	}
+	defer sqlDB.Close()
+
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(pingCtx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// dialector returns the GORM dialector for the configured driver
+func dialector(settings config.DatabaseConfig) gorm.Dialector {
+	if driverName(settings) == DriverSQLite {
+		// Foreign keys and a busy timeout make SQLite behave closer to Postgres
+		// when several tasks write concurrently
+		return sqlite.Open(settings.Path + "?_foreign_keys=on&_busy_timeout=5000")
+	}
+
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		settings.Host,
+		settings.User,
+		settings.Password,
+		settings.Name,
+		settings.Port,
+		sslMode(settings),
+	)
+	return postgres.Open(dsn)
+}
@@ -18,7 +18,10 @@ type GormLogrusLogger struct {
 
 // NewGormLogrusLogger creates a new GORM logger that uses logrus
 func NewGormLogrusLogger(baseLogger *logrus.Logger) *GormLogrusLogger {
-	if _, ok := baseLogger.Formatter.(*logging.ColoredJSONFormatter); !ok {
+	// Keep a formatter chosen with LOG_FORMAT, replacing only the logrus default
+	switch baseLogger.Formatter.(type) {
+	case *logging.ColoredJSONFormatter, *logging.JSONFormatter:
+	default:
 		baseLogger.SetFormatter(logging.NewColoredJSONFormatter())
 	}
 
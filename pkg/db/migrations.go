@@ -1,60 +1,163 @@
 package db
 
 import (
+	"errors"
 	"fmt"
-	"path/filepath"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/lisanmuaddib/agent-go/migrations"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
 	"github.com/sirupsen/logrus"
 )
 
-// RunMigrations executes database migrations
-func RunMigrations(logger *logrus.Logger, projectRoot string) error {
-	migrationsPath := fmt.Sprintf("file://%s", filepath.Join(projectRoot, "migrations"))
-	dbURL := constructDBURL()
+// Migrator applies the versioned SQL migrations embedded in the binary. The applied
+// version is tracked by golang-migrate in the schema_migrations table.
+type Migrator struct {
+	m      *migrate.Migrate
+	logger *logrus.Logger
+}
 
-	logger.WithFields(logrus.Fields{
-		"migrations_path": migrationsPath,
-		"project_root":    projectRoot,
-	}).Debug("Running database migrations")
+// NewMigrator opens a migrator for the configured database
+func NewMigrator(logger *logrus.Logger, settings config.DatabaseConfig) (*Migrator, error) {
+	if err := ensureSQLiteDir(settings); err != nil {
+		return nil, err
+	}
+
+	dir := migrations.PostgresDir
+	if driverName(settings) == DriverSQLite {
+		dir = migrations.SQLiteDir
+	}
+
+	source, err := iofs.New(migrations.FS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedded migrations: %w", err)
+	}
 
-	m, err := migrate.New(migrationsPath, dbURL)
+	m, err := migrate.NewWithSourceInstance("iofs", source, constructDBURL(settings))
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
 	}
-	defer m.Close()
+	m.Log = &migrateLogger{logger: logger}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+	return &Migrator{m: m, logger: logger}, nil
+}
+
+// Up applies all pending migrations
+func (mg *Migrator) Up() error {
+	if err := mg.checkDirty(); err != nil {
+		return err
+	}
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
+	return nil
+}
+
+// Down rolls back the given number of migrations, one when steps is not positive
+func (mg *Migrator) Down(steps int) error {
+	if steps < 1 {
+		steps = 1
+	}
+	if err := mg.checkDirty(); err != nil {
+		return err
+	}
+	if err := mg.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+	return nil
+}
 
-	logger.Info("Database migrations completed successfully")
+// Goto migrates up or down to the given version
+func (mg *Migrator) Goto(version uint) error {
+	if err := mg.checkDirty(); err != nil {
+		return err
+	}
+	if err := mg.m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
 	return nil
 }
 
-// MigrationStatus returns the current migration version and dirty state
-func MigrationStatus(logger *logrus.Logger) (uint, bool, error) {
-	logger.Debug("Checking migration status")
+// Force records the given version as applied and clears the dirty flag without
+// running any migration. Use it after repairing a failed migration by hand.
+func (mg *Migrator) Force(version int) error {
+	if err := mg.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Version returns the applied migration version and dirty state. Version is 0
+// when no migration has been applied yet.
+func (mg *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Close releases the migrator's database and source handles
+func (mg *Migrator) Close() error {
+	sourceErr, dbErr := mg.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
 
-	projectRoot, err := findProjectRoot()
+// checkDirty refuses to migrate on top of a half-applied migration
+func (mg *Migrator) checkDirty() error {
+	version, dirty, err := mg.Version()
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to find project root: %w", err)
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database is at dirty migration version %d; fix the schema and run `agent migrate force <version>`", version)
 	}
+	return nil
+}
 
-	migrationsPath := fmt.Sprintf("file://%s", filepath.Join(projectRoot, "migrations"))
-	dbURL := constructDBURL()
+// RunMigrations applies all pending migrations
+func RunMigrations(logger *logrus.Logger, settings config.DatabaseConfig) error {
+	logger.WithField("driver", driverName(settings)).Debug("Running database migrations")
 
-	m, err := migrate.New(migrationsPath, dbURL)
+	migrator, err := NewMigrator(logger, settings)
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to create migrator: %w", err)
+		return err
 	}
-	defer m.Close()
+	defer migrator.Close()
 
-	version, dirty, err := m.Version()
-	if err != nil && err != migrate.ErrNilVersion {
-		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	if err := migrator.Up(); err != nil {
+		return err
+	}
+
+	version, _, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+
+	logger.WithField("version", version).Info("Database migrations completed successfully")
+	return nil
+}
+
+// MigrationStatus returns the current migration version and dirty state
+func MigrationStatus(logger *logrus.Logger, settings config.DatabaseConfig) (uint, bool, error) {
+	logger.Debug("Checking migration status")
+
+	migrator, err := NewMigrator(logger, settings)
+	if err != nil {
+		return 0, false, err
+	}
+	defer migrator.Close()
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return 0, false, err
 	}
 
 	logger.WithFields(logrus.Fields{
@@ -64,3 +167,18 @@ func MigrationStatus(logger *logrus.Logger) (uint, bool, error) {
 
 	return version, dirty, nil
 }
+
+// migrateLogger routes golang-migrate's progress output through logrus
+type migrateLogger struct {
+	logger *logrus.Logger
+}
+
+// Printf implements migrate.Logger
+func (l *migrateLogger) Printf(format string, v ...interface{}) {
+	l.logger.WithField("component", "migrate").Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+// Verbose implements migrate.Logger
+func (l *migrateLogger) Verbose() bool {
+	return l.logger.IsLevelEnabled(logrus.DebugLevel)
+}
@@ -19,6 +19,7 @@ const (
 // Tweet represents the database model for tweets
 type Tweet struct {
 	ID             string    `gorm:"primaryKey;column:id"`
+	BotID          string    `gorm:"primaryKey;column:bot_id;default:''"`
 	Text           string    `gorm:"column:text;not null"`
 	ConversationID string    `gorm:"column:conversation_id"`
 	CreatedAt      time.Time `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP"`
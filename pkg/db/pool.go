@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// pingTimeout bounds a single health check ping
+	pingTimeout = 5 * time.Second
+	// initialBackoff is the first delay between connection attempts
+	initialBackoff = time.Second
+	// defaultMaxBackoff caps the retry delay when none is configured
+	defaultMaxBackoff = time.Minute
+)
+
+// configurePool applies the configured connection pool limits
+func configurePool(sqlDB *sql.DB, settings config.DatabaseConfig) {
+	if settings.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(settings.MaxOpenConns)
+	}
+	if settings.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(settings.MaxIdleConns)
+	}
+	if settings.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	}
+	if settings.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+	}
+}
+
+// nextBackoff doubles delay up to max
+func nextBackoff(delay, max time.Duration) time.Duration {
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	delay *= 2
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// HealthMonitor pings the database on an interval. While the database is
+// unreachable it retries with exponential backoff and drops idle connections so
+// the pool reconnects once the server is back, without restarting the agent.
+type HealthMonitor struct {
+	db         *sql.DB
+	logger     *logrus.Logger
+	settings   config.DatabaseConfig
+	interval   time.Duration
+	maxBackoff time.Duration
+
+	mu      sync.RWMutex
+	healthy bool
+	lastErr error
+}
+
+// NewHealthMonitor creates a health monitor for the given pool
+func NewHealthMonitor(logger *logrus.Logger, sqlDB *sql.DB, settings config.DatabaseConfig) *HealthMonitor {
+	maxBackoff := settings.ReconnectMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	return &HealthMonitor{
+		db:         sqlDB,
+		logger:     logger,
+		settings:   settings,
+		interval:   settings.HealthCheckInterval,
+		maxBackoff: maxBackoff,
+		healthy:    true,
+	}
+}
+
+// Healthy reports whether the most recent check succeeded
+func (h *HealthMonitor) Healthy() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.healthy
+}
+
+// LastError returns the error of the most recent failed check, or nil when healthy
+func (h *HealthMonitor) LastError() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lastErr
+}
+
+// Check pings the database once and records the result
+func (h *HealthMonitor) Check(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	err := h.db.PingContext(pingCtx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.healthy = err == nil
+	h.lastErr = err
+	return err
+}
+
+// Run checks the database until ctx is cancelled. It does nothing when the
+// health check interval is zero.
+func (h *HealthMonitor) Run(ctx context.Context) {
+	if h.interval <= 0 {
+		return
+	}
+
+	log := h.logger.WithField("component", "db_health")
+	log.WithField("interval", h.interval).Debug("Starting database health checks")
+
+	delay := h.interval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		wasHealthy := h.Healthy()
+		if err := h.Check(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if wasHealthy {
+				delay = initialBackoff
+			} else {
+				delay = nextBackoff(delay, h.maxBackoff)
+			}
+			log.WithError(err).WithField("retry_in", delay).Warn("Database unreachable")
+			h.dropIdleConnections()
+			continue
+		}
+
+		if !wasHealthy {
+			log.Info("Database connection restored")
+		}
+		delay = h.interval
+	}
+}
+
+// dropIdleConnections closes pooled connections that may point at a server that
+// went away, so the next query dials a fresh one
+func (h *HealthMonitor) dropIdleConnections() {
+	h.db.SetMaxIdleConns(0)
+	maxIdle := h.settings.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = 2 // database/sql default
+	}
+	h.db.SetMaxIdleConns(maxIdle)
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// replicaResolver names the resolver serving queries marked with Replica
+const replicaResolver = "replica"
+
+// registerReplicas connects the configured read replicas. Only queries marked
+// with Replica are sent to them; everything else, including all writes, stays
+// on the primary so read-after-write paths never see replication lag.
+func registerReplicas(db *gorm.DB, logger *logrus.Logger, settings config.DatabaseConfig) error {
+	if len(settings.ReplicaDSNs) == 0 {
+		return nil
+	}
+	if driverName(settings) != DriverPostgres {
+		logger.Warn("Read replicas are only supported with postgres, ignoring replica DSNs")
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(settings.ReplicaDSNs))
+	for _, dsn := range settings.ReplicaDSNs {
+		replicas = append(replicas, postgres.Open(dsn))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}, replicaResolver)
+	if settings.MaxOpenConns > 0 {
+		resolver.SetMaxOpenConns(settings.MaxOpenConns)
+	}
+	if settings.MaxIdleConns > 0 {
+		resolver.SetMaxIdleConns(settings.MaxIdleConns)
+	}
+	if settings.ConnMaxLifetime > 0 {
+		resolver.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	}
+	if settings.ConnMaxIdleTime > 0 {
+		resolver.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+	}
+
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("failed to register read replicas: %w", err)
+	}
+
+	logger.WithField("replicas", len(replicas)).Info("Routing recall and analytics queries to read replicas")
+	return nil
+}
+
+// Replica marks tx's queries as safe to serve from a read replica. Without
+// replicas configured they run on the primary as usual.
+func Replica(tx *gorm.DB) *gorm.DB {
+	return tx.Clauses(dbresolver.Use(replicaResolver))
+}
@@ -0,0 +1,55 @@
+// Package dryrun defines the recording sink used when the agent runs in dry-run
+// mode: instead of posting tweets or sending transactions, clients hand the
+// content they would have published to a Recorder.
+package dryrun
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Kind identifies what would have been published
+type Kind string
+
+const (
+	KindTweet         Kind = "tweet"
+	KindReply         Kind = "reply"
+	KindQuote         Kind = "quote"
+	KindDelete        Kind = "delete"
+	KindCast          Kind = "cast"
+	KindLens          Kind = "lens_publication"
+	KindTransaction   Kind = "transaction"
+	KindERC20Transfer Kind = "erc20_transfer"
+)
+
+// Record is one action suppressed by dry-run mode
+type Record struct {
+	Kind Kind
+	// Target is what the action was aimed at: the tweet replied to or quoted,
+	// or the recipient address of a transfer
+	Target string
+	// Content is the tweet text or a description of the transfer
+	Content string
+	// Details holds the remaining request parameters
+	Details map[string]interface{}
+}
+
+// Recorder stores records of suppressed actions
+type Recorder interface {
+	RecordDryRun(ctx context.Context, record Record) error
+}
+
+// twitterEpoch is the start of Twitter's snowflake ID timestamps (2010-11-04)
+const twitterEpoch = 1288834974657
+
+var sequence atomic.Uint32
+
+// NewTweetID returns a unique ID shaped like a Twitter snowflake, so synthetic
+// tweets pass the same validation as real ones
+func NewTweetID() string {
+	millis := time.Now().UnixMilli() - twitterEpoch
+	seq := sequence.Add(1) & (1<<22 - 1)
+	return strconv.FormatInt(millis<<22|int64(seq), 10)
+}
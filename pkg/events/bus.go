@@ -0,0 +1,202 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// subscriberBuffer is how many events a slow subscriber may lag behind
+	// before further events are dropped for it
+	subscriberBuffer = 256
+
+	// publishTimeout bounds how long an external publisher may take per event
+	publishTimeout = 10 * time.Second
+)
+
+// Handler receives events from the bus
+type Handler func(Event)
+
+// Publisher forwards events to an external system
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// subscriber delivers events to one handler from its own goroutine, so a slow
+// handler never blocks the agent
+type subscriber struct {
+	handler Handler
+	types   map[Type]bool
+	queue   chan Event
+}
+
+func (s *subscriber) wants(eventType Type) bool {
+	return len(s.types) == 0 || s.types[eventType]
+}
+
+// Bus fans events out to subscribers. Publishing never blocks: events are
+// dropped (and logged) for subscribers whose queue is full. A nil *Bus is
+// valid and discards everything, so components can emit unconditionally.
+type Bus struct {
+	logger *logrus.Logger
+
+	mu          sync.RWMutex
+	subscribers map[int]*subscriber
+	nextID      int
+	publishers  []Publisher
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// NewBus creates an empty event bus
+func NewBus(logger *logrus.Logger) *Bus {
+	return &Bus{
+		logger:      logger,
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// Subscribe registers handler for the given event types, or for all events
+// when none are given. The returned function removes the subscription.
+func (b *Bus) Subscribe(handler Handler, types ...Type) (unsubscribe func()) {
+	sub := &subscriber{
+		handler: handler,
+		types:   make(map[Type]bool, len(types)),
+		queue:   make(chan Event, subscriberBuffer),
+	}
+	for _, eventType := range types {
+		sub.types[eventType] = true
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return func() {}
+	}
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	b.wg.Add(1)
+	b.mu.Unlock()
+
+	go func() {
+		defer b.wg.Done()
+		for event := range sub.queue {
+			b.deliver(sub.handler, event)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			if _, ok := b.subscribers[id]; ok {
+				delete(b.subscribers, id)
+				close(sub.queue)
+			}
+			b.mu.Unlock()
+		})
+	}
+}
+
+// deliver calls a handler, keeping a panicking subscriber from taking down the agent
+func (b *Bus) deliver(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.WithFields(logrus.Fields{
+				"event": event.Type,
+				"panic": r,
+			}).Error("Event handler panicked")
+		}
+	}()
+	handler(event)
+}
+
+// AddPublisher forwards every event to an external system. Each publisher
+// gets its own subscription so a slow endpoint only delays itself.
+func (b *Bus) AddPublisher(publisher Publisher) {
+	b.mu.Lock()
+	b.publishers = append(b.publishers, publisher)
+	b.mu.Unlock()
+
+	log := b.logger.WithField("publisher", publisher.Name())
+	b.Subscribe(func(event Event) {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		defer cancel()
+		if err := publisher.Publish(ctx, event); err != nil {
+			log.WithError(err).WithField("event", event.Type).Warn("Failed to publish event")
+		}
+	})
+	log.Info("Event publisher registered")
+}
+
+// Publish sends an event to every interested subscriber without blocking
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, sub := range b.subscribers {
+		if !sub.wants(event.Type) {
+			continue
+		}
+		select {
+		case sub.queue <- event:
+		default:
+			b.logger.WithField("event", event.Type).Warn("Event subscriber is falling behind, dropping event")
+		}
+	}
+}
+
+// Emit is shorthand for publishing a new event
+func (b *Bus) Emit(eventType Type, botID string, data map[string]interface{}) {
+	if b == nil {
+		return
+	}
+	b.Publish(New(eventType, botID, data))
+}
+
+// Close stops accepting events, waits for subscribers to drain their queues
+// and closes the external publishers
+func (b *Bus) Close() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for id, sub := range b.subscribers {
+		delete(b.subscribers, id)
+		close(sub.queue)
+	}
+	publishers := b.publishers
+	b.mu.Unlock()
+
+	b.wg.Wait()
+
+	var errs []error
+	for _, publisher := range publishers {
+		if err := publisher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
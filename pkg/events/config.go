@@ -0,0 +1,29 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// NewBusFrom creates an event bus with the external publishers enabled in the
+// agent configuration
+func NewBusFrom(settings config.EventsConfig, logger *logrus.Logger) (*Bus, error) {
+	bus := NewBus(logger)
+
+	if settings.WebhookURL != "" {
+		bus.AddPublisher(NewWebhookPublisher(settings.WebhookURL, settings.WebhookSecret))
+	}
+
+	if settings.NATSURL != "" {
+		publisher, err := NewNATSPublisher(settings.NATSURL, settings.NATSSubject)
+		if err != nil {
+			bus.Close()
+			return nil, fmt.Errorf("failed to create NATS publisher: %w", err)
+		}
+		bus.AddPublisher(publisher)
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,58 @@
+// Package events publishes agent activity (mentions received and edited,
+// replies posted and running late, rate limits hit, credentials failing,
+// wallet transfers sent and received, tips sent and held) to in-process subscribers and,
+// optionally, to external systems over HTTP webhooks or NATS so operators can
+// build alerting and dashboards.
+package events
+
+import "time"
+
+// Type identifies the kind of an event
+type Type string
+
+const (
+	// MentionReceived is emitted when a mention is stored for a reply
+	MentionReceived Type = "mention_received"
+	// ReplyPosted is emitted after the bot posts a reply
+	ReplyPosted Type = "reply_posted"
+	// ReplyLatencyExceeded is emitted when a reply is posted later after its
+	// mention than the response latency SLO allows
+	ReplyLatencyExceeded Type = "reply_latency_exceeded"
+	// RateLimitHit is emitted when the Twitter API answers with 429
+	RateLimitHit Type = "rate_limit_hit"
+	// WalletTransferCompleted is emitted when a sent transaction is confirmed
+	WalletTransferCompleted Type = "wallet_transfer_completed"
+	// WalletTransferReceived is emitted when tokens arrive at the agent's wallet
+	WalletTransferReceived Type = "wallet_transfer_received"
+	// TweetEdited is emitted when a stored mention turns out to have been edited
+	TweetEdited Type = "tweet_edited"
+	// ConversationClosed is emitted when a conversation going in circles is
+	// closed with a sign-off
+	ConversationClosed Type = "conversation_closed"
+	// TipSent is emitted when a tip command's transfer is submitted
+	TipSent Type = "tip_sent"
+	// TipHeld is emitted when a tip waits for an operator's approval
+	TipHeld Type = "tip_held"
+	// CredentialsChanged is emitted when an account's Twitter credentials
+	// are found revoked, expired or suspended, and when they work again
+	CredentialsChanged Type = "credentials_changed"
+)
+
+// Event is one occurrence of agent activity
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	// BotID is the bot account the event belongs to, empty for process-wide events
+	BotID string                 `json:"bot_id,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// New creates an event stamped with the current time
+func New(eventType Type, botID string, data map[string]interface{}) Event {
+	return Event{
+		Type:  eventType,
+		Time:  time.Now().UTC(),
+		BotID: botID,
+		Data:  data,
+	}
+}
@@ -0,0 +1,99 @@
+// Package experiments assigns reply generations to prompt variants so their
+// engagement can be compared (A/B testing).
+package experiments
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+)
+
+// Variant is one arm of an experiment: a temperature and extra prompt
+// instructions applied to the generations assigned to it
+type Variant struct {
+	Name string
+	// Temperature overrides the generation temperature; 0 keeps the default
+	Temperature float64
+	// Instructions are added to the prompt's requirements; empty keeps the base prompt
+	Instructions string
+	// Weight is the variant's relative share of assignments
+	Weight int
+}
+
+// Experiment splits generations between variants. Assignment is deterministic
+// per key, so retries for the same tweet land in the same variant.
+type Experiment struct {
+	Name     string
+	Variants []Variant
+
+	totalWeight int
+}
+
+// New creates an experiment, defaulting variant weights to 1
+func New(name string, variants []Variant) (*Experiment, error) {
+	if name == "" {
+		return nil, fmt.Errorf("experiment name is required")
+	}
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("experiment %s needs at least two variants", name)
+	}
+
+	exp := &Experiment{Name: name, Variants: make([]Variant, len(variants))}
+	seen := make(map[string]bool, len(variants))
+	for i, variant := range variants {
+		if variant.Name == "" {
+			return nil, fmt.Errorf("experiment %s: every variant needs a name", name)
+		}
+		if seen[variant.Name] {
+			return nil, fmt.Errorf("experiment %s: duplicate variant %q", name, variant.Name)
+		}
+		seen[variant.Name] = true
+
+		if variant.Weight <= 0 {
+			variant.Weight = 1
+		}
+		exp.Variants[i] = variant
+		exp.totalWeight += variant.Weight
+	}
+	return exp, nil
+}
+
+// NewFromConfig creates the experiment described by the agent configuration, or
+// returns nil when experiments are disabled
+func NewFromConfig(settings config.ExperimentConfig) (*Experiment, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	variants := make([]Variant, len(settings.Variants))
+	for i, variant := range settings.Variants {
+		variants[i] = Variant{
+			Name:         variant.Name,
+			Temperature:  variant.Temperature,
+			Instructions: variant.Instructions,
+			Weight:       variant.Weight,
+		}
+	}
+	return New(settings.Name, variants)
+}
+
+// Assign returns the variant for key, typically the ID of the tweet being replied to
+func (e *Experiment) Assign(key string) Variant {
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + ":" + key))
+	bucket := int(h.Sum32() % uint32(e.totalWeight))
+
+	for _, variant := range e.Variants {
+		if bucket < variant.Weight {
+			return variant
+		}
+		bucket -= variant.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// Label identifies a variant within this experiment, as recorded on tweets
+func (e *Experiment) Label(variant Variant) string {
+	return e.Name + "/" + variant.Name
+}
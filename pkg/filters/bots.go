@@ -0,0 +1,144 @@
+package filters
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+)
+
+// botTweetsPerDay is the average tweet rate from which the heuristic takes an
+// account for automated
+const botTweetsPerDay = 200
+
+var (
+	// botUsernamePattern matches handles such as price_bot or GMBot
+	botUsernamePattern = regexp.MustCompile(`(?i)(bot|_ai)$|_bot_`)
+	// botBioPattern matches profiles that say they are automated
+	botBioPattern = regexp.MustCompile(`(?i)\b(i am a bot|i'm a bot|bot account|automated account|automated replies|ai agent|autonomous agent)\b`)
+	// urlPattern strips links before checking whether a quote adds a comment
+	urlPattern = regexp.MustCompile(`https?://\S+`)
+)
+
+// BotGuard keeps the agent from answering itself and other bots, which could
+// otherwise keep two accounts replying to each other indefinitely. Besides
+// the bot's own tweets, it skips retweets of them and quotes of them without
+// a comment, the authors on its known list and, with the heuristic on,
+// authors whose profile or tweet rate looks automated.
+type BotGuard struct {
+	known     map[string]struct{}
+	heuristic bool
+}
+
+// NewBotGuard creates a guard skipping the known user IDs or usernames
+func NewBotGuard(known []string, heuristic bool) *BotGuard {
+	g := &BotGuard{
+		known:     make(map[string]struct{}, len(known)),
+		heuristic: heuristic,
+	}
+	g.AddKnown(known...)
+	return g
+}
+
+// AddKnown adds user IDs or usernames to the known bots, e.g. the other
+// accounts the agent runs. It must be called before the guard is used.
+func (g *BotGuard) AddKnown(accounts ...string) {
+	for _, account := range accounts {
+		if key := knownKey(account); key != "" {
+			g.known[key] = struct{}{}
+		}
+	}
+}
+
+// Skip reports whether a mention must not be answered by the account botID
+// (@botUsername), and why. author may be nil when it is unknown; includes,
+// which may be nil, resolve the tweets the mention retweets or quotes. A nil
+// guard still skips the bot's own content.
+func (g *BotGuard) Skip(tweet twitter.Tweet, author *twitter.User, includes *twitter.TweetIncludes, botID, botUsername string) (bool, string) {
+	if botID != "" && tweet.AuthorID == botID {
+		return true, "own tweet"
+	}
+	if reason := ownContent(tweet, includes, botID, botUsername); reason != "" {
+		return true, reason
+	}
+	if g == nil {
+		return false, ""
+	}
+
+	if g.isKnown(tweet.AuthorID) || (author != nil && g.isKnown(author.Username)) {
+		return true, "known bot"
+	}
+	if g.heuristic && author != nil {
+		if reason := looksAutomated(author); reason != "" {
+			return true, reason
+		}
+	}
+	return false, ""
+}
+
+// isKnown reports whether an ID or username is on the known list
+func (g *BotGuard) isKnown(account string) bool {
+	if account == "" {
+		return false
+	}
+	_, ok := g.known[knownKey(account)]
+	return ok
+}
+
+// ownContent returns why tweet repeats the bot's own content, or an empty
+// string when it does not
+func ownContent(tweet twitter.Tweet, includes *twitter.TweetIncludes, botID, botUsername string) string {
+	for _, ref := range tweet.ReferencedTweets {
+		switch ref.Type {
+		case "retweeted":
+			if isOwnTweet(ref.ID, includes, botID) ||
+				(botUsername != "" && strings.HasPrefix(strings.ToLower(tweet.Text), "rt @"+strings.ToLower(botUsername)+":")) {
+				return "retweet of own tweet"
+			}
+		case "quoted":
+			if isOwnTweet(ref.ID, includes, botID) && !hasComment(tweet.Text) {
+				return "quote of own tweet without comment"
+			}
+		}
+	}
+	return ""
+}
+
+// isOwnTweet reports whether the included tweet id was written by botID
+func isOwnTweet(id string, includes *twitter.TweetIncludes, botID string) bool {
+	if includes == nil || botID == "" {
+		return false
+	}
+	referenced := includes.Tweet(id)
+	return referenced != nil && referenced.AuthorID == botID
+}
+
+// hasComment reports whether text says anything besides handles and links
+func hasComment(text string) bool {
+	text = urlPattern.ReplaceAllString(text, "")
+	return normalizeText(text) != ""
+}
+
+// looksAutomated returns why an author's profile looks like a bot's, or an
+// empty string when it does not
+func looksAutomated(author *twitter.User) string {
+	if botUsernamePattern.MatchString(author.Username) {
+		return "bot-like username"
+	}
+	if botBioPattern.MatchString(author.Description) {
+		return "profile says it is automated"
+	}
+	if created, err := time.Parse(time.RFC3339, author.CreatedAt); err == nil {
+		days := time.Since(created).Hours() / 24
+		if days >= 1 && float64(author.PublicMetrics.TweetCount)/days >= botTweetsPerDay {
+			return "tweets at a bot's rate"
+		}
+	}
+	return ""
+}
+
+// knownKey normalizes a user ID or username for the known list
+func knownKey(account string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(account), "@"))
+}
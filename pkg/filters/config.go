@@ -0,0 +1,55 @@
+// Package filters scores incoming mentions for spam and bot activity so the agent
+// can skip them instead of spending replies on them.
+package filters
+
+import (
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Default configuration values
+const (
+	// DefaultSpamThreshold is the score at or above which a mention is treated as spam
+	DefaultSpamThreshold = 0.7
+	// DefaultMinAccountAge is the account age below which an author looks suspicious
+	DefaultMinAccountAge = 30 * 24 * time.Hour
+	// DefaultDuplicateWindow is how long mention text is remembered to spot copy-paste campaigns
+	DefaultDuplicateWindow = 24 * time.Hour
+)
+
+// Config holds the spam filter configuration
+type Config struct {
+	// Threshold is the score at or above which a mention is excluded from replies
+	Threshold       float64
+	MinAccountAge   time.Duration
+	DuplicateWindow time.Duration
+	// Classifier, when set, is asked to rate mentions the heuristics find suspicious
+	Classifier llms.Model
+	Logger     *logrus.Logger
+}
+
+// NewConfigFrom creates a filter Config from the central agent configuration,
+// filling unset values with defaults. The classifier is only used when enabled.
+func NewConfigFrom(settings config.FilterConfig, llm llms.Model, logger *logrus.Logger) *Config {
+	filterConfig := &Config{
+		Threshold:       settings.SpamThreshold,
+		MinAccountAge:   settings.MinAccountAge,
+		DuplicateWindow: settings.DuplicateWindow,
+		Logger:          logger,
+	}
+
+	if filterConfig.MinAccountAge <= 0 {
+		filterConfig.MinAccountAge = DefaultMinAccountAge
+	}
+	if filterConfig.DuplicateWindow <= 0 {
+		filterConfig.DuplicateWindow = DefaultDuplicateWindow
+	}
+	if settings.LLMClassifier {
+		filterConfig.Classifier = llm
+	}
+
+	return filterConfig
+}
@@ -0,0 +1,142 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// sentimentPrompt asks the LLM for a sentiment score
+const sentimentPrompt = `Rate the sentiment of the following tweet towards the account it replies to or mentions, from -1 (hostile, insulting) through 0 (neutral) to 1 (admiring, praising).
+
+Tweet: %s
+
+Respond with only a number between -1 and 1.`
+
+var (
+	// signedScorePattern extracts the first signed number from the LLM's answer
+	signedScorePattern = regexp.MustCompile(`-?[01](?:\.\d+)?`)
+	// wordPattern splits text into lowercase words for the lexicon
+	wordPattern = regexp.MustCompile(`[a-z']+|[\x{1F300}-\x{1FAFF}\x{2600}-\x{27BF}]`)
+)
+
+// positiveWords and negativeWords are the local lexicon. It is deliberately
+// small: it only has to tell praise and hostility apart from everything else.
+var (
+	positiveWords = wordSet("love", "loved", "loves", "lovely", "adore", "adorable", "cute", "amazing",
+		"awesome", "great", "best", "beautiful", "brilliant", "king", "legend", "legendary",
+		"genius", "based", "goat", "thank", "thanks", "grateful", "respect", "majestic", "perfect",
+		"fantastic", "wonderful", "incredible", "fan", "bless", "blessed", "wow", "nice", "cool",
+		"funny", "lol", "haha", "❤", "😍", "🥰", "🙏", "🔥", "👑", "😂")
+	negativeWords = wordSet("hate", "hated", "hates", "stupid", "dumb", "idiot", "idiotic", "ugly",
+		"worst", "trash", "garbage", "scam", "scammer", "fake", "fraud", "loser", "pathetic", "boring",
+		"cringe", "annoying", "useless", "shut", "clown", "liar", "terrible", "awful", "disgusting",
+		"fat", "lame", "sucks", "suck", "mid", "ratio", "rugged", "rug", "🤡", "💩", "🖕", "😡", "🤮")
+	negations = wordSet("not", "no", "never", "don't", "dont", "isn't", "isnt", "ain't", "aint", "nothing")
+)
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// SentimentAnalyzer scores how friendly a mention is towards the bot, with a
+// local lexicon or, when a classifier is set, the LLM
+type SentimentAnalyzer struct {
+	classifier llms.Model
+	logger     *logrus.Logger
+}
+
+// NewSentimentAnalyzer creates a sentiment analyzer. A nil classifier scores
+// with the local lexicon only.
+func NewSentimentAnalyzer(classifier llms.Model, logger *logrus.Logger) *SentimentAnalyzer {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &SentimentAnalyzer{
+		classifier: classifier,
+		logger:     logger,
+	}
+}
+
+// Score rates text between -1 (hostile) and 1 (praise). When the classifier
+// fails, the lexicon score is used instead.
+func (a *SentimentAnalyzer) Score(ctx context.Context, text string) float64 {
+	if a.classifier != nil {
+		score, err := a.classify(ctx, text)
+		if err == nil {
+			return score
+		}
+		a.logger.WithError(err).Debug("Sentiment classifier failed, using lexicon")
+	}
+	return LexiconSentiment(text)
+}
+
+// negationWindow is how many words after a negation it still applies to
+const negationWindow = 3
+
+// LexiconSentiment scores text with the local lexicon. A negation flips a
+// sentiment word shortly after it, so "not very cute" counts as negative.
+func LexiconSentiment(text string) float64 {
+	text = mentionPattern.ReplaceAllString(strings.ToLower(text), "")
+
+	var positive, negative int
+	negatedAt := -negationWindow - 1
+	for i, word := range wordPattern.FindAllString(text, -1) {
+		if negations[word] {
+			negatedAt = i
+			continue
+		}
+		polarity := 0
+		switch {
+		case positiveWords[word]:
+			polarity = 1
+		case negativeWords[word]:
+			polarity = -1
+		default:
+			continue
+		}
+		if i-negatedAt <= negationWindow {
+			polarity = -polarity
+			negatedAt = -negationWindow - 1
+		}
+		if polarity > 0 {
+			positive++
+		} else {
+			negative++
+		}
+	}
+
+	// The extra 1 in the denominator keeps a single word from scoring ±1
+	return float64(positive-negative) / float64(positive+negative+1)
+}
+
+// classify asks the LLM classifier for a sentiment score
+func (a *SentimentAnalyzer) classify(ctx context.Context, text string) (float64, error) {
+	answer, err := a.classifier.Call(ctx, fmt.Sprintf(sentimentPrompt, text),
+		llms.WithTemperature(0),
+		llms.WithMaxTokens(5),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to classify sentiment: %w", err)
+	}
+
+	match := signedScorePattern.FindString(answer)
+	if match == "" {
+		return 0, fmt.Errorf("unexpected classifier answer %q", answer)
+	}
+	score, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected classifier answer %q: %w", answer, err)
+	}
+	return math.Min(math.Max(score, -1), 1), nil
+}
@@ -0,0 +1,214 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Heuristic weights; a mention's score is their sum, capped at 1
+const (
+	weightNewAccount     = 0.3
+	weightVeryNewAccount = 0.15 // added on top of weightNewAccount for accounts under a week old
+	weightNoFollowers    = 0.15
+	weightFollowRatio    = 0.2
+	weightDuplicateText  = 0.4
+	weightLinkDensity    = 0.2
+	weightMentionSpam    = 0.15
+
+	// classifierMinScore is the heuristic score from which the LLM classifier is consulted
+	classifierMinScore = 0.2
+)
+
+// classifierPrompt asks the LLM for a spam probability
+const classifierPrompt = `You moderate replies for a Twitter bot. Rate how likely the following mention is spam, a scam, or automated bot activity (crypto giveaways, phishing links, copy-paste promotion, follow-for-follow).
+
+Author: @%s (%d followers, following %d, account created %s)
+Mention: %s
+
+Respond with only a number between 0 and 1.`
+
+var (
+	// scorePattern extracts the first number from the classifier's answer
+	scorePattern = regexp.MustCompile(`[01](?:\.\d+)?`)
+	// mentionPattern strips @handles before comparing mention text
+	mentionPattern = regexp.MustCompile(`@\w+`)
+)
+
+// Score is the spam assessment of a single mention
+type Score struct {
+	Value   float64
+	Reasons []string
+}
+
+// seenText records the first author of a normalized mention text
+type seenText struct {
+	authorID string
+	seenAt   time.Time
+}
+
+// SpamFilter scores mentions using account and content heuristics, optionally
+// refined by an LLM classifier. It is safe for concurrent use and remembers
+// recent mention text across calls to detect the same message from many authors.
+type SpamFilter struct {
+	config *Config
+	logger *logrus.Logger
+
+	mu   sync.Mutex
+	seen map[string]seenText
+}
+
+// NewSpamFilter creates a new spam filter
+func NewSpamFilter(config *Config) *SpamFilter {
+	if config.Logger == nil {
+		config.Logger = logrus.StandardLogger()
+	}
+	return &SpamFilter{
+		config: config,
+		logger: config.Logger,
+		seen:   make(map[string]seenText),
+	}
+}
+
+// Threshold returns the score at or above which mentions are excluded, or 0 when
+// filtering is disabled
+func (f *SpamFilter) Threshold() float64 {
+	return f.config.Threshold
+}
+
+// IsSpam reports whether score reaches the configured threshold
+func (f *SpamFilter) IsSpam(score Score) bool {
+	return f.config.Threshold > 0 && score.Value >= f.config.Threshold
+}
+
+// Score rates a mention between 0 (legitimate) and 1 (spam). author may be nil
+// when the user was not included in the API response, in which case only the
+// content heuristics apply.
+func (f *SpamFilter) Score(ctx context.Context, tweet twitter.Tweet, author *twitter.User) Score {
+	var score Score
+	add := func(weight float64, reason string) {
+		score.Value += weight
+		score.Reasons = append(score.Reasons, reason)
+	}
+
+	if author != nil {
+		if created, err := time.Parse(time.RFC3339, author.CreatedAt); err == nil {
+			age := time.Since(created)
+			if age < f.config.MinAccountAge {
+				add(weightNewAccount, "new account")
+			}
+			if age < 7*24*time.Hour {
+				add(weightVeryNewAccount, "account under a week old")
+			}
+		}
+
+		followers := author.PublicMetrics.FollowersCount
+		following := author.PublicMetrics.FollowingCount
+		if followers == 0 {
+			add(weightNoFollowers, "no followers")
+		}
+		if following > 100 && following > 20*followers {
+			add(weightFollowRatio, "follows far more accounts than follow it")
+		}
+	}
+
+	if f.isDuplicate(tweet) {
+		add(weightDuplicateText, "same text posted by another author")
+	}
+
+	words := len(strings.Fields(tweet.Text))
+	if links := len(tweet.Entities.URLs); links >= 2 || (links == 1 && words <= 4) {
+		add(weightLinkDensity, "link heavy")
+	}
+	if len(tweet.Entities.Mentions) > 5 {
+		add(weightMentionSpam, "mentions many accounts")
+	}
+
+	if f.config.Classifier != nil && score.Value >= classifierMinScore && !f.IsSpam(score) {
+		if rating, err := f.classify(ctx, tweet, author); err != nil {
+			f.logger.WithError(err).WithField("tweet_id", tweet.ID).Debug("Spam classifier failed, using heuristics only")
+		} else {
+			score.Value = (score.Value + rating) / 2
+			if rating >= 0.5 {
+				score.Reasons = append(score.Reasons, "classifier flagged")
+			}
+		}
+	}
+
+	score.Value = math.Min(score.Value, 1)
+	return score
+}
+
+// isDuplicate records the mention's text and reports whether a different author
+// posted the same text within the duplicate window
+func (f *SpamFilter) isDuplicate(tweet twitter.Tweet) bool {
+	text := normalizeText(tweet.Text)
+	if len(text) < 20 {
+		// Short replies like "gm" are legitimately repeated by many people
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range f.seen {
+		if now.Sub(entry.seenAt) > f.config.DuplicateWindow {
+			delete(f.seen, key)
+		}
+	}
+
+	entry, ok := f.seen[text]
+	if !ok {
+		f.seen[text] = seenText{authorID: tweet.AuthorID, seenAt: now}
+		return false
+	}
+	return entry.authorID != tweet.AuthorID
+}
+
+// classify asks the LLM classifier for a spam probability
+func (f *SpamFilter) classify(ctx context.Context, tweet twitter.Tweet, author *twitter.User) (float64, error) {
+	username, created := "unknown", "unknown"
+	var followers, following int
+	if author != nil {
+		username = author.Username
+		created = author.CreatedAt
+		followers = author.PublicMetrics.FollowersCount
+		following = author.PublicMetrics.FollowingCount
+	}
+
+	prompt := fmt.Sprintf(classifierPrompt, username, followers, following, created, tweet.Text)
+	answer, err := f.config.Classifier.Call(ctx, prompt,
+		llms.WithTemperature(0),
+		llms.WithMaxTokens(5),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to classify mention: %w", err)
+	}
+
+	match := scorePattern.FindString(answer)
+	if match == "" {
+		return 0, fmt.Errorf("unexpected classifier answer %q", answer)
+	}
+	rating, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected classifier answer %q: %w", answer, err)
+	}
+	return math.Min(math.Max(rating, 0), 1), nil
+}
+
+// normalizeText lowercases text and removes handles and extra whitespace so
+// copies addressed to different accounts compare equal
+func normalizeText(text string) string {
+	text = mentionPattern.ReplaceAllString(strings.ToLower(text), "")
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,217 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+)
+
+// testMention builds a mention with the given number of links and mentioned accounts
+func testMention(t *testing.T, authorID, text string, links, mentions int) twitter.Tweet {
+	t.Helper()
+	entities := map[string]any{}
+	var urls, handles []map[string]any
+	for range links {
+		urls = append(urls, map[string]any{"url": "https://t.co/abc"})
+	}
+	for range mentions {
+		handles = append(handles, map[string]any{"username": "someone"})
+	}
+	entities["urls"], entities["mentions"] = urls, handles
+
+	data, err := json.Marshal(map[string]any{"id": "1", "author_id": authorID, "text": text, "entities": entities})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tweet twitter.Tweet
+	if err := json.Unmarshal(data, &tweet); err != nil {
+		t.Fatal(err)
+	}
+	return tweet
+}
+
+// testAuthor builds an account created age ago with the given follow counts
+func testAuthor(age time.Duration, followers, following int) *twitter.User {
+	user := &twitter.User{ID: "42", Username: "someone", CreatedAt: time.Now().Add(-age).Format(time.RFC3339)}
+	user.PublicMetrics.FollowersCount = followers
+	user.PublicMetrics.FollowingCount = following
+	return user
+}
+
+func newTestSpamFilter() *SpamFilter {
+	return NewSpamFilter(&Config{
+		Threshold:       DefaultSpamThreshold,
+		MinAccountAge:   DefaultMinAccountAge,
+		DuplicateWindow: DefaultDuplicateWindow,
+	})
+}
+
+func TestSpamScore(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		text     string
+		links    int
+		mentions int
+		author   *twitter.User
+		score    float64
+		reasons  []string
+		spam     bool
+	}{
+		{
+			name:   "established account asking a question",
+			text:   "gm cat lord, what do you make of the market today?",
+			author: testAuthor(700*day, 500, 300),
+			score:  0,
+		},
+		{
+			name:    "new account with a normal reply",
+			text:    "this is the funniest bot on the timeline",
+			author:  testAuthor(20*day, 10, 50),
+			score:   0.3,
+			reasons: []string{"new account"},
+		},
+		{
+			name:    "established account sharing a link",
+			text:    "claim now https://t.co/abc",
+			links:   1,
+			author:  testAuthor(700*day, 500, 300),
+			score:   0.2,
+			reasons: []string{"link heavy"},
+		},
+		{
+			name:   "link with enough words around it",
+			text:   "wrote up why the cat lord is right about fees https://t.co/abc",
+			links:  1,
+			author: testAuthor(700*day, 500, 300),
+			score:  0,
+		},
+		{
+			name:     "unknown author tagging many accounts with links",
+			text:     "airdrop live https://t.co/abc https://t.co/def",
+			links:    2,
+			mentions: 6,
+			score:    0.35,
+			reasons:  []string{"link heavy", "mentions many accounts"},
+		},
+		{
+			name:    "day-old follow-for-follow account",
+			text:    "follow me back please",
+			author:  testAuthor(2*day, 0, 3000),
+			score:   0.8,
+			reasons: []string{"new account", "account under a week old", "no followers", "follows far more accounts than follow it"},
+			spam:    true,
+		},
+		{
+			name:    "weights adding up to exactly the threshold",
+			text:    "gm https://t.co/abc",
+			links:   1,
+			author:  testAuthor(20*day, 20, 500),
+			score:   0.7,
+			reasons: []string{"new account", "follows far more accounts than follow it", "link heavy"},
+			spam:    true,
+		},
+		{
+			name:     "giveaway blast from a new account",
+			text:     "FREE 1000 $LAFFY giveaway https://t.co/abc https://t.co/def",
+			links:    2,
+			mentions: 6,
+			author:   testAuthor(10*day, 5, 800),
+			score:    0.85,
+			reasons:  []string{"new account", "follows far more accounts than follow it", "link heavy", "mentions many accounts"},
+			spam:     true,
+		},
+		{
+			name:     "everything at once is capped at 1",
+			text:     "FREE giveaway https://t.co/abc https://t.co/def",
+			links:    2,
+			mentions: 6,
+			author:   testAuthor(day, 0, 3000),
+			score:    1,
+			spam:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newTestSpamFilter()
+			score := filter.Score(context.Background(), testMention(t, "42", tt.text, tt.links, tt.mentions), tt.author)
+
+			if math.Abs(score.Value-tt.score) > 1e-9 {
+				t.Errorf("score = %v, want %v (%v)", score.Value, tt.score, score.Reasons)
+			}
+			if tt.reasons != nil && !slices.Equal(score.Reasons, tt.reasons) {
+				t.Errorf("reasons = %q, want %q", score.Reasons, tt.reasons)
+			}
+			if got := filter.IsSpam(score); got != tt.spam {
+				t.Errorf("IsSpam = %v, want %v at threshold %v", got, tt.spam, filter.Threshold())
+			}
+		})
+	}
+}
+
+func TestSpamThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		score     float64
+		spam      bool
+	}{
+		{"below the threshold", 0.7, 0.69, false},
+		{"at the threshold", 0.7, 0.7, true},
+		{"above the threshold", 0.7, 0.85, true},
+		{"filtering disabled", 0, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewSpamFilter(&Config{Threshold: tt.threshold})
+			if got := filter.IsSpam(Score{Value: tt.score}); got != tt.spam {
+				t.Errorf("IsSpam(%v) = %v, want %v", tt.score, got, tt.spam)
+			}
+		})
+	}
+}
+
+func TestSpamScoreDuplicateText(t *testing.T) {
+	const campaign = "Congrats! You won 500 $LAFFY, claim it in my bio before it expires"
+
+	tests := []struct {
+		name  string
+		posts []twitter.Tweet
+		score float64
+	}{
+		{
+			name:  "same text from another author",
+			posts: []twitter.Tweet{testMention(t, "1", "@catlord "+campaign, 0, 0), testMention(t, "2", "@someone_else "+strings.ToUpper(campaign), 0, 0)},
+			score: weightDuplicateText,
+		},
+		{
+			name:  "same author repeating themselves",
+			posts: []twitter.Tweet{testMention(t, "1", campaign, 0, 0), testMention(t, "1", campaign, 0, 0)},
+			score: 0,
+		},
+		{
+			name:  "short replies everyone sends",
+			posts: []twitter.Tweet{testMention(t, "1", "gm cat lord", 0, 0), testMention(t, "2", "gm cat lord", 0, 0)},
+			score: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newTestSpamFilter()
+			var score Score
+			for _, post := range tt.posts {
+				score = filter.Score(context.Background(), post, nil)
+			}
+			if math.Abs(score.Value-tt.score) > 1e-9 {
+				t.Errorf("score of the last post = %v, want %v (%v)", score.Value, tt.score, score.Reasons)
+			}
+		})
+	}
+}
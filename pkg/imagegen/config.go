@@ -0,0 +1,112 @@
+// Package imagegen generates images from text prompts through the OpenAI
+// (DALL·E) or Stability AI image APIs.
+package imagegen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Supported providers
+const (
+	ProviderOpenAI    = "openai"
+	ProviderStability = "stability"
+)
+
+// Default configuration values
+const (
+	DefaultOpenAIURL      = "https://api.openai.com/v1"
+	DefaultOpenAIModel    = "dall-e-3"
+	DefaultOpenAISize     = "1024x1024"
+	DefaultStabilityURL   = "https://api.stability.ai"
+	DefaultAspectRatio    = "1:1"
+	DefaultRequestTimeout = 2 * time.Minute
+)
+
+// Image is a generated image
+type Image struct {
+	Data []byte
+	// MediaType is the MIME type of Data, e.g. image/png
+	MediaType string
+	// RevisedPrompt is the prompt the provider actually used, when it rewrites
+	// prompts as DALL·E 3 does
+	RevisedPrompt string
+}
+
+// Generator turns a prompt into an image
+type Generator interface {
+	Generate(ctx context.Context, prompt string) (*Image, error)
+}
+
+// Config holds the image generation settings
+type Config struct {
+	// Provider is openai or stability
+	Provider string
+	APIKey   string
+	// BaseURL overrides the provider's API root, e.g. for a proxy
+	BaseURL string
+	// Model is the DALL·E model; unused by Stability
+	Model string
+	// Size is the DALL·E image size, e.g. 1024x1024
+	Size string
+	// AspectRatio is the Stability aspect ratio, e.g. 1:1
+	AspectRatio string
+
+	RequestTimeout time.Duration
+	Logger         *logrus.Logger
+}
+
+// Validate checks the required settings and fills unset values with defaults
+func (c *Config) Validate() error {
+	if c.Logger == nil {
+		c.Logger = logrus.StandardLogger()
+	}
+	if c.APIKey == "" {
+		return fmt.Errorf("image generation API key is required")
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = DefaultRequestTimeout
+	}
+
+	switch c.Provider {
+	case ProviderOpenAI:
+		if c.BaseURL == "" {
+			c.BaseURL = DefaultOpenAIURL
+		}
+		if c.Model == "" {
+			c.Model = DefaultOpenAIModel
+		}
+		if c.Size == "" {
+			c.Size = DefaultOpenAISize
+		}
+	case ProviderStability:
+		if c.BaseURL == "" {
+			c.BaseURL = DefaultStabilityURL
+		}
+		if c.AspectRatio == "" {
+			c.AspectRatio = DefaultAspectRatio
+		}
+	default:
+		return fmt.Errorf("unknown image generation provider %q", c.Provider)
+	}
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	return nil
+}
+
+// New creates a generator for the configured provider
+func New(config *Config) (Generator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid image generation config: %w", err)
+	}
+
+	switch config.Provider {
+	case ProviderStability:
+		return newStability(config), nil
+	default:
+		return newOpenAI(config), nil
+	}
+}
@@ -0,0 +1,90 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// openAIClient generates images with the OpenAI images API
+type openAIClient struct {
+	config *Config
+	http   *http.Client
+	logger *logrus.Logger
+}
+
+func newOpenAI(config *Config) *openAIClient {
+	return &openAIClient{
+		config: config,
+		http:   &http.Client{Timeout: config.RequestTimeout},
+		logger: config.Logger,
+	}
+}
+
+// Generate implements Generator. Images are requested as base64 so they do
+// not have to be downloaded from a short-lived URL.
+func (c *openAIClient) Generate(ctx context.Context, prompt string) (*Image, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"model":           c.config.Model,
+		"prompt":          prompt,
+		"n":               1,
+		"size":            c.config.Size,
+		"response_format": "b64_json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+"/images/generations", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+
+	c.logger.WithFields(logrus.Fields{
+		"provider": ProviderOpenAI,
+		"model":    c.config.Model,
+	}).Debug("Generating image")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("image generation failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data []struct {
+			B64JSON       string `json:"b64_json"`
+			RevisedPrompt string `json:"revised_prompt"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Data) == 0 || result.Data[0].B64JSON == "" {
+		return nil, fmt.Errorf("image generation returned no image")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Data[0].B64JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return &Image{
+		Data:          data,
+		MediaType:     http.DetectContentType(data),
+		RevisedPrompt: result.Data[0].RevisedPrompt,
+	}, nil
+}
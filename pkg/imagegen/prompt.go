@@ -0,0 +1,62 @@
+package imagegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMemeStyle describes the look of the cat lord memes
+const DefaultMemeStyle = "an adorably grumpy Scottish Fold cat with folded ears and big round eyes, " +
+	"dressed as a medieval lord on a velvet throne, digital illustration, bold colors, meme composition"
+
+// maxContextChars keeps quoted tweets from drowning out the style in the prompt
+const maxContextChars = 240
+
+// MemeContext is the conversation a meme illustrates
+type MemeContext struct {
+	// TweetText is the tweet being replied to
+	TweetText string
+	// ReplyText is the agent's reply the image accompanies
+	ReplyText      string
+	AuthorUsername string
+}
+
+// PromptBuilder turns a reply's context into an image prompt
+type PromptBuilder struct {
+	// Style describes the subject and look of every image; DefaultMemeStyle when empty
+	Style string
+}
+
+// Build returns the image prompt for the given reply context. Image models
+// render text poorly, so the prompt asks for a scene without captions; the
+// reply itself carries the words.
+func (b PromptBuilder) Build(meme MemeContext) string {
+	style := b.Style
+	if style == "" {
+		style = DefaultMemeStyle
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("A meme image of %s.", style))
+	if text := clip(meme.TweetText); text != "" {
+		who := "a human subject"
+		if meme.AuthorUsername != "" {
+			who = "a human subject called @" + meme.AuthorUsername
+		}
+		prompt.WriteString(fmt.Sprintf(" The cat lord is reacting to %s who said: %q.", who, text))
+	}
+	if text := clip(meme.ReplyText); text != "" {
+		prompt.WriteString(fmt.Sprintf(" The cat lord's expression and pose convey this reply: %q.", text))
+	}
+	prompt.WriteString(" Humorous and regal. No text, letters or captions in the image.")
+	return prompt.String()
+}
+
+// clip flattens whitespace and shortens text to maxContextChars
+func clip(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxContextChars {
+		text = string(runes[:maxContextChars]) + "…"
+	}
+	return text
+}
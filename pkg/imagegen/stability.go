@@ -0,0 +1,80 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stabilityClient generates images with the Stability AI Stable Image Core API
+type stabilityClient struct {
+	config *Config
+	http   *http.Client
+	logger *logrus.Logger
+}
+
+func newStability(config *Config) *stabilityClient {
+	return &stabilityClient{
+		config: config,
+		http:   &http.Client{Timeout: config.RequestTimeout},
+		logger: config.Logger,
+	}
+}
+
+// Generate implements Generator. The API takes a multipart form and answers
+// with the raw image when asked for image/*.
+func (c *stabilityClient) Generate(ctx context.Context, prompt string) (*Image, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := map[string]string{
+		"prompt":        prompt,
+		"aspect_ratio":  c.config.AspectRatio,
+		"output_format": "png",
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+"/v2beta/stable-image/generate/core", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	req.Header.Set("Accept", "image/*")
+
+	c.logger.WithField("provider", ProviderStability).Debug("Generating image")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image generation failed with status %d: %s", resp.StatusCode, string(data))
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("image generation returned no image")
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+	return &Image{Data: data, MediaType: mediaType}, nil
+}
@@ -0,0 +1,137 @@
+package farcaster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/sirupsen/logrus"
+)
+
+// Client reads mentions of the bot and publishes its casts
+type Client struct {
+	config *Config
+	http   *http.Client
+	logger *logrus.Logger
+	// dryRun, when set, receives casts instead of Neynar
+	dryRun dryrun.Recorder
+}
+
+// NewClient creates a new Farcaster client
+func NewClient(config *Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid farcaster config: %w", err)
+	}
+
+	return &Client{
+		config: config,
+		http:   &http.Client{Timeout: config.RequestTimeout},
+		logger: config.Logger,
+	}, nil
+}
+
+// FID returns the bot's Farcaster ID
+func (c *Client) FID() int64 {
+	return c.config.FID
+}
+
+// Username returns the bot's configured Farcaster username
+func (c *Client) Username() string {
+	return c.config.Username
+}
+
+// GetAuthenticatedUserID returns the bot's namespaced user ID (see UserID),
+// matching the Twitter client so the tweet store can recall casts
+func (c *Client) GetAuthenticatedUserID(ctx context.Context) (string, error) {
+	return UserID(c.config.FID), nil
+}
+
+// neynarRequest calls a Neynar endpoint and decodes the JSON response into out
+func (c *Client) neynarRequest(ctx context.Context, method, endpoint string, query url.Values, body, out interface{}) error {
+	u := c.config.NeynarURL + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("x-api-key", c.config.NeynarAPIKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.do(req, out)
+}
+
+// hubRequest calls a hub HTTP API endpoint and decodes the JSON response into out
+func (c *Client) hubRequest(ctx context.Context, endpoint string, query url.Values, out interface{}) error {
+	u := c.config.HubURL + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	return c.do(req, out)
+}
+
+// do sends the request and decodes a successful JSON response into out
+func (c *Client) do(req *http.Request, out interface{}) error {
+	log := c.logger.WithFields(logrus.Fields{
+		"method": req.Method,
+		"path":   req.URL.Path,
+	})
+	log.Debug("Making request to Farcaster API")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(data)}
+		var body neynarError
+		if json.Unmarshal(data, &body) == nil && body.Message != "" {
+			apiErr.Code, apiErr.Message = body.Code, body.Message
+		}
+		log.WithField("status", resp.StatusCode).Warn("Farcaster API request failed")
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// fidParam formats an FID as a query parameter
+func fidParam(fid int64) string {
+	return strconv.FormatInt(fid, 10)
+}
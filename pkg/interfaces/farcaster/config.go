@@ -0,0 +1,78 @@
+// Package farcaster is a client for reading mentions from and publishing casts
+// to Farcaster, through a hub's HTTP API or the Neynar API.
+package farcaster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Default configuration values
+const (
+	// DefaultNeynarURL is the Neynar v2 Farcaster API base URL
+	DefaultNeynarURL = "https://api.neynar.com/v2/farcaster"
+	// DefaultRequestTimeout bounds each HTTP request to Neynar or a hub
+	DefaultRequestTimeout = 30 * time.Second
+	// MaxCastBytes is the longest cast text the protocol accepts
+	MaxCastBytes = 320
+)
+
+// Config holds the Farcaster client configuration
+type Config struct {
+	// FID is the bot's Farcaster ID
+	FID      int64
+	Username string
+
+	NeynarAPIKey string
+	NeynarURL    string
+	// SignerUUID is the Neynar managed signer that publishes the bot's casts
+	SignerUUID string
+	// HubURL, when set, is used to read mentions instead of Neynar, e.g.
+	// http://localhost:2281
+	HubURL string
+
+	RequestTimeout time.Duration
+	Logger         *logrus.Logger
+}
+
+// NewConfigFrom creates a Farcaster Config from the central agent configuration
+func NewConfigFrom(settings config.FarcasterConfig, logger *logrus.Logger) (*Config, error) {
+	farcasterConfig := &Config{
+		FID:            settings.FID,
+		Username:       settings.Username,
+		NeynarAPIKey:   settings.NeynarAPIKey,
+		NeynarURL:      settings.NeynarURL,
+		SignerUUID:     settings.SignerUUID,
+		HubURL:         settings.HubURL,
+		RequestTimeout: DefaultRequestTimeout,
+		Logger:         logger,
+	}
+
+	if err := farcasterConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return farcasterConfig, nil
+}
+
+// Validate checks the required settings and fills unset values with defaults
+func (c *Config) Validate() error {
+	if c.Logger == nil {
+		c.Logger = logrus.StandardLogger()
+	}
+	if c.FID <= 0 {
+		return fmt.Errorf("farcaster FID is required")
+	}
+	if c.NeynarAPIKey == "" {
+		return fmt.Errorf("neynar API key is required")
+	}
+	if c.NeynarURL == "" {
+		c.NeynarURL = DefaultNeynarURL
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = DefaultRequestTimeout
+	}
+	return nil
+}
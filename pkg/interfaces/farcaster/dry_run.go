@@ -0,0 +1,52 @@
+package farcaster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+)
+
+// SetDryRun makes the client hand every cast it would publish to recorder
+// instead of Neynar. Mentions are still read, so the agent sees live data.
+// Call it before the client is shared between goroutines.
+func (c *Client) SetDryRun(recorder dryrun.Recorder) {
+	c.dryRun = recorder
+}
+
+// DryRun reports whether casts are recorded instead of published
+func (c *Client) DryRun() bool {
+	return c.dryRun != nil
+}
+
+// recordDryRunCast records a cast and returns the cast Neynar would have
+// published, with a synthetic hash so callers can proceed as if it was sent
+func (c *Client) recordDryRunCast(ctx context.Context, params PublishCastParams) (*Cast, error) {
+	record := dryrun.Record{
+		Kind:    dryrun.KindCast,
+		Target:  params.ParentHash,
+		Content: params.Text,
+		Details: map[string]interface{}{},
+	}
+	if len(params.EmbedURLs) > 0 {
+		record.Details["embed_urls"] = params.EmbedURLs
+	}
+	if err := c.dryRun.RecordDryRun(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to record dry-run cast: %w", err)
+	}
+
+	hash := make([]byte, 20)
+	if _, err := rand.Read(hash); err != nil {
+		return nil, fmt.Errorf("failed to generate dry-run cast hash: %w", err)
+	}
+	return &Cast{
+		Hash:       "0x" + hex.EncodeToString(hash),
+		ParentHash: params.ParentHash,
+		Author:     User{FID: c.config.FID, Username: c.config.Username},
+		Text:       params.Text,
+		Timestamp:  time.Now().UTC(),
+	}, nil
+}
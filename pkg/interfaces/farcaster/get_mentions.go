@@ -0,0 +1,105 @@
+package farcaster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxNeynarNotifications is the most notifications Neynar returns per page
+const maxNeynarNotifications = 25
+
+// GetMentionsParams holds the parameters for the GetMentions request
+type GetMentionsParams struct {
+	// Limit caps the casts per page; Neynar returns at most 25
+	Limit int
+	// Cursor continues from a previous page
+	Cursor string
+}
+
+// MentionsPage is one page of casts mentioning or replying to the bot
+type MentionsPage struct {
+	Casts []Cast
+	// Cursor fetches the next page; empty on the last page
+	Cursor string
+}
+
+// GetMentions returns casts mentioning the bot, newest first. Neynar also
+// returns replies to the bot's casts; a hub only reports explicit mentions.
+func (c *Client) GetMentions(ctx context.Context, params GetMentionsParams) (*MentionsPage, error) {
+	if c.config.HubURL != "" {
+		return c.getHubMentions(ctx, params)
+	}
+	return c.getNeynarMentions(ctx, params)
+}
+
+func (c *Client) getNeynarMentions(ctx context.Context, params GetMentionsParams) (*MentionsPage, error) {
+	query := url.Values{}
+	query.Set("fid", fidParam(c.config.FID))
+	query.Set("type", "mentions,replies")
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(min(params.Limit, maxNeynarNotifications)))
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+
+	var resp neynarNotifications
+	if err := c.neynarRequest(ctx, http.MethodGet, "/notifications", query, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+
+	page := &MentionsPage{Cursor: resp.Next.Cursor}
+	for _, notification := range resp.Notifications {
+		if notification.Cast.Hash == "" {
+			continue
+		}
+		page.Casts = append(page.Casts, notification.Cast.cast())
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"method":        "GetMentions",
+		"source":        "neynar",
+		"notifications": len(resp.Notifications),
+		"casts":         len(page.Casts),
+	}).Debug("Fetched Farcaster mentions")
+
+	return page, nil
+}
+
+func (c *Client) getHubMentions(ctx context.Context, params GetMentionsParams) (*MentionsPage, error) {
+	query := url.Values{}
+	query.Set("fid", fidParam(c.config.FID))
+	query.Set("reverse", "true")
+	if params.Limit > 0 {
+		query.Set("pageSize", strconv.Itoa(params.Limit))
+	}
+	if params.Cursor != "" {
+		query.Set("pageToken", params.Cursor)
+	}
+
+	var resp hubMessages
+	if err := c.hubRequest(ctx, "/v1/castsByMention", query, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch casts by mention: %w", err)
+	}
+
+	page := &MentionsPage{Cursor: resp.NextPageToken}
+	for _, message := range resp.Messages {
+		if cast, ok := message.cast(); ok {
+			page.Casts = append(page.Casts, cast)
+		}
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"method":   "GetMentions",
+		"source":   "hub",
+		"messages": len(resp.Messages),
+		"casts":    len(page.Casts),
+	}).Debug("Fetched Farcaster mentions")
+
+	return page, nil
+}
@@ -0,0 +1,78 @@
+package farcaster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PublishCastParams holds the parameters for publishing a cast
+type PublishCastParams struct {
+	Text string
+	// ParentHash makes the cast a reply to that cast
+	ParentHash string
+	// EmbedURLs are attached to the cast, at most two
+	EmbedURLs []string
+}
+
+// PublishCast publishes a cast as the bot through its Neynar managed signer.
+// Hubs only accept messages signed by the client, so casts are never sent to
+// the configured hub. In dry-run mode (SetDryRun) the cast is recorded instead.
+func (c *Client) PublishCast(ctx context.Context, params PublishCastParams) (*Cast, error) {
+	if c.config.SignerUUID == "" {
+		return nil, fmt.Errorf("a signer UUID is required to publish casts")
+	}
+	if params.Text == "" {
+		return nil, fmt.Errorf("cast text is required")
+	}
+	if len(params.Text) > MaxCastBytes {
+		return nil, fmt.Errorf("cast text is %d bytes, the limit is %d", len(params.Text), MaxCastBytes)
+	}
+	if c.dryRun != nil {
+		return c.recordDryRunCast(ctx, params)
+	}
+
+	body := map[string]interface{}{
+		"signer_uuid": c.config.SignerUUID,
+		"text":        params.Text,
+	}
+	if params.ParentHash != "" {
+		body["parent"] = params.ParentHash
+	}
+	if len(params.EmbedURLs) > 0 {
+		embeds := make([]map[string]string, len(params.EmbedURLs))
+		for i, u := range params.EmbedURLs {
+			embeds[i] = map[string]string{"url": u}
+		}
+		body["embeds"] = embeds
+	}
+
+	var resp struct {
+		Success bool       `json:"success"`
+		Cast    neynarCast `json:"cast"`
+	}
+	if err := c.neynarRequest(ctx, http.MethodPost, "/cast", nil, body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to publish cast: %w", err)
+	}
+	if !resp.Success || resp.Cast.Hash == "" {
+		return nil, fmt.Errorf("failed to publish cast: no cast in response")
+	}
+
+	cast := resp.Cast.cast()
+	if cast.ParentHash == "" {
+		cast.ParentHash = params.ParentHash
+	}
+	if cast.Author.FID == 0 {
+		cast.Author = User{FID: c.config.FID, Username: c.config.Username}
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"method":      "PublishCast",
+		"cast_hash":   cast.Hash,
+		"parent_hash": params.ParentHash,
+	}).Info("Published cast")
+
+	return &cast, nil
+}
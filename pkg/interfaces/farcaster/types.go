@@ -0,0 +1,176 @@
+package farcaster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+)
+
+// userIDPrefix namespaces Farcaster IDs where they share storage with Twitter
+// user IDs, so an FID never matches a Twitter account
+const userIDPrefix = "fc:"
+
+// farcasterEpoch is when hub message timestamps start counting, 2021-01-01 UTC
+var farcasterEpoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// UserID returns the ID under which a Farcaster user is stored, e.g. as the
+// author of a cast or the bot ID of a tweet store
+func UserID(fid int64) string {
+	return userIDPrefix + strconv.FormatInt(fid, 10)
+}
+
+// User is a Farcaster account
+type User struct {
+	FID           int64  `json:"fid"`
+	Username      string `json:"username"`
+	DisplayName   string `json:"display_name"`
+	FollowerCount int    `json:"follower_count"`
+}
+
+// Cast is a Farcaster post
+type Cast struct {
+	Hash string `json:"hash"`
+	// ThreadHash is the hash of the cast that started the thread; empty when
+	// read from a hub, which only knows the parent
+	ThreadHash string `json:"thread_hash"`
+	// ParentHash is the cast this one replies to, empty for top-level casts
+	ParentHash      string    `json:"parent_hash"`
+	ParentAuthorFID int64     `json:"-"`
+	Author          User      `json:"author"`
+	Text            string    `json:"text"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// IsReply reports whether the cast replies to another cast
+func (c Cast) IsReply() bool {
+	return c.ParentHash != ""
+}
+
+// ToTweet converts the cast into the tweet shape used by the memory and
+// thoughts layers. Author IDs are namespaced with UserID and the thread hash
+// serves as the conversation ID.
+func (c Cast) ToTweet() twitter.Tweet {
+	tweet := twitter.Tweet{
+		ID:             c.Hash,
+		Text:           c.Text,
+		AuthorID:       UserID(c.Author.FID),
+		ConversationID: c.ThreadHash,
+	}
+	if !c.Timestamp.IsZero() {
+		tweet.CreatedAt = c.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if tweet.ConversationID == "" {
+		// Hubs don't report threads; group a reply with its parent instead
+		tweet.ConversationID = c.Hash
+		if c.IsReply() {
+			tweet.ConversationID = c.ParentHash
+		}
+	}
+	if c.IsReply() {
+		tweet.ReferencedTweets = []twitter.ReferencedTweet{{Type: "replied_to", ID: c.ParentHash}}
+		if c.ParentAuthorFID != 0 {
+			tweet.InReplyToUserID = UserID(c.ParentAuthorFID)
+		}
+	}
+	return tweet
+}
+
+// neynarCast is a cast as returned by the Neynar API
+type neynarCast struct {
+	Hash         string `json:"hash"`
+	ThreadHash   string `json:"thread_hash"`
+	ParentHash   string `json:"parent_hash"`
+	ParentAuthor struct {
+		FID int64 `json:"fid"`
+	} `json:"parent_author"`
+	Author    User      `json:"author"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (c neynarCast) cast() Cast {
+	return Cast{
+		Hash:            c.Hash,
+		ThreadHash:      c.ThreadHash,
+		ParentHash:      c.ParentHash,
+		ParentAuthorFID: c.ParentAuthor.FID,
+		Author:          c.Author,
+		Text:            c.Text,
+		Timestamp:       c.Timestamp,
+	}
+}
+
+// neynarNotifications is the response of the Neynar notifications endpoint
+type neynarNotifications struct {
+	Notifications []struct {
+		Type string     `json:"type"` // "mention" or "reply", among others
+		Cast neynarCast `json:"cast"`
+	} `json:"notifications"`
+	Next struct {
+		Cursor string `json:"cursor"`
+	} `json:"next"`
+}
+
+// neynarError is the error body returned by the Neynar API
+type neynarError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// hubMessage is a message as returned by a hub's HTTP API
+type hubMessage struct {
+	Hash string `json:"hash"`
+	Data struct {
+		Type        string `json:"type"`
+		FID         int64  `json:"fid"`
+		Timestamp   int64  `json:"timestamp"` // seconds since the Farcaster epoch
+		CastAddBody *struct {
+			Text         string `json:"text"`
+			ParentCastID *struct {
+				FID  int64  `json:"fid"`
+				Hash string `json:"hash"`
+			} `json:"parentCastId"`
+		} `json:"castAddBody"`
+	} `json:"data"`
+}
+
+// hubMessages is a page of hub messages
+type hubMessages struct {
+	Messages      []hubMessage `json:"messages"`
+	NextPageToken string       `json:"nextPageToken"`
+}
+
+// cast converts a CastAdd message; ok is false for any other message type
+func (m hubMessage) cast() (Cast, bool) {
+	if m.Data.Type != "MESSAGE_TYPE_CAST_ADD" || m.Data.CastAddBody == nil {
+		return Cast{}, false
+	}
+	cast := Cast{
+		Hash:      m.Hash,
+		Author:    User{FID: m.Data.FID},
+		Text:      m.Data.CastAddBody.Text,
+		Timestamp: farcasterEpoch.Add(time.Duration(m.Data.Timestamp) * time.Second),
+	}
+	if parent := m.Data.CastAddBody.ParentCastID; parent != nil {
+		cast.ParentHash = parent.Hash
+		cast.ParentAuthorFID = parent.FID
+	}
+	return cast, true
+}
+
+// APIError is a non-success response from Neynar or a hub
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("farcaster API error (status %d, %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("farcaster API error (status %d): %s", e.StatusCode, strings.TrimSpace(e.Message))
+}
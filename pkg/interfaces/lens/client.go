@@ -0,0 +1,194 @@
+package lens
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/sirupsen/logrus"
+)
+
+// accessTokenTTL is how long an access token is reused; the API issues them
+// for 30 minutes
+const accessTokenTTL = 25 * time.Minute
+
+const challengeQuery = `query Challenge($request: ChallengeRequest!) {
+  challenge(request: $request) { id text }
+}`
+
+const authenticateMutation = `mutation Authenticate($request: SignedAuthChallenge!) {
+  authenticate(request: $request) { accessToken refreshToken }
+}`
+
+// Client publishes the bot's posts and comments on Lens
+type Client struct {
+	config *Config
+	http   *http.Client
+	logger *logrus.Logger
+	// dryRun, when set, receives publications instead of IPFS and the relayer
+	dryRun dryrun.Recorder
+
+	mu           sync.Mutex
+	accessToken  string
+	tokenExpires time.Time
+}
+
+// NewClient creates a new Lens client
+func NewClient(config *Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid lens config: %w", err)
+	}
+
+	return &Client{
+		config: config,
+		http:   &http.Client{Timeout: config.RequestTimeout},
+		logger: config.Logger,
+	}, nil
+}
+
+// ProfileID returns the bot's Lens profile ID
+func (c *Client) ProfileID() string {
+	return c.config.ProfileID
+}
+
+// login returns a valid access token, signing a new challenge with the wallet
+// when the cached one has expired
+func (c *Client) login(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.tokenExpires) {
+		return c.accessToken, nil
+	}
+
+	var challenge struct {
+		Challenge struct {
+			ID   string `json:"id"`
+			Text string `json:"text"`
+		} `json:"challenge"`
+	}
+	err := c.graphql(ctx, "", challengeQuery, map[string]interface{}{
+		"request": map[string]string{
+			"signedBy": c.config.Signer.GetAddress().Hex(),
+			"for":      c.config.ProfileID,
+		},
+	}, &challenge)
+	if err != nil {
+		return "", fmt.Errorf("failed to get login challenge: %w", err)
+	}
+
+	signature, err := c.config.Signer.SignMessage([]byte(challenge.Challenge.Text))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign login challenge: %w", err)
+	}
+
+	var authenticated struct {
+		Authenticate struct {
+			AccessToken string `json:"accessToken"`
+		} `json:"authenticate"`
+	}
+	err = c.graphql(ctx, "", authenticateMutation, map[string]interface{}{
+		"request": map[string]string{
+			"id":        challenge.Challenge.ID,
+			"signature": hexutil.Encode(signature),
+		},
+	}, &authenticated)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	c.accessToken = authenticated.Authenticate.AccessToken
+	c.tokenExpires = time.Now().Add(accessTokenTTL)
+	c.logger.WithField("profile_id", c.config.ProfileID).Debug("Authenticated with Lens API")
+
+	return c.accessToken, nil
+}
+
+// logout drops the cached access token so the next call signs in again
+func (c *Client) logout() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = ""
+}
+
+// authenticatedGraphQL runs an operation as the bot's profile, signing in
+// again once if the API rejects the cached token
+func (c *Client) authenticatedGraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		token, err := c.login(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = c.graphql(ctx, token, query, variables, out)
+		var apiErr *APIError
+		if attempt == 0 && errors.As(err, &apiErr) && apiErr.unauthenticated() {
+			c.logout()
+			continue
+		}
+		return err
+	}
+}
+
+// graphql runs a query or mutation and decodes its data into out
+func (c *Client) graphql(ctx context.Context, accessToken, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.APIURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("x-access-token", "Bearer "+accessToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	result := graphQLResponse{Data: out}
+	if err := json.Unmarshal(data, &result); err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return &APIError{StatusCode: resp.StatusCode, Message: string(data)}
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		c.logger.WithFields(logrus.Fields{
+			"status": resp.StatusCode,
+			"code":   result.Errors[0].Extensions.Code,
+		}).Warn("Lens API request failed")
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       result.Errors[0].Extensions.Code,
+			Message:    result.Errors[0].Message,
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Message: string(data)}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Package lens publishes posts and comments to Lens Protocol. Content is pinned
+// to IPFS as Lens publication metadata, and each publication is signed with the
+// agent's EVM wallet and broadcast through the Lens API relayer.
+package lens
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/ipfs"
+	"github.com/sirupsen/logrus"
+)
+
+// Default configuration values
+const (
+	// DefaultAPIURL is the Lens v2 GraphQL API
+	DefaultAPIURL = "https://api-v2.lens.dev"
+	// DefaultAppID tags publications with the app that created them
+	DefaultAppID = "agent-go"
+	// DefaultRequestTimeout bounds each request to the Lens API
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+// Signer signs Lens login challenges and publication typed data, as
+// implemented by wallet.KeyManager
+type Signer interface {
+	GetAddress() common.Address
+	SignMessage(message []byte) ([]byte, error)
+	SignTypedData(typedData apitypes.TypedData) ([]byte, error)
+}
+
+// Recorder stores the publications the client broadcasts, e.g. a memory.TweetStore
+type Recorder interface {
+	SaveLensPublication(ctx context.Context, publication *Publication) error
+}
+
+// Config holds the Lens client configuration
+type Config struct {
+	// ProfileID is the bot's Lens profile, e.g. 0x01a2b3; the signer must own
+	// the profile or be one of its profile managers
+	ProfileID string
+	APIURL    string
+	AppID     string
+
+	Signer Signer
+	IPFS   *ipfs.Client
+	// Recorder, when set, stores every broadcast publication
+	Recorder Recorder
+
+	RequestTimeout time.Duration
+	Logger         *logrus.Logger
+}
+
+// NewConfigFrom creates a Lens Config from the central agent configuration
+func NewConfigFrom(settings config.LensConfig, signer Signer, pinner *ipfs.Client, logger *logrus.Logger) (*Config, error) {
+	lensConfig := &Config{
+		ProfileID:      settings.ProfileID,
+		APIURL:         settings.APIURL,
+		AppID:          settings.AppID,
+		Signer:         signer,
+		IPFS:           pinner,
+		RequestTimeout: DefaultRequestTimeout,
+		Logger:         logger,
+	}
+
+	if err := lensConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return lensConfig, nil
+}
+
+// Validate checks the required settings and fills unset values with defaults
+func (c *Config) Validate() error {
+	if c.Logger == nil {
+		c.Logger = logrus.StandardLogger()
+	}
+	if c.ProfileID == "" {
+		return fmt.Errorf("lens profile ID is required")
+	}
+	if c.Signer == nil {
+		return fmt.Errorf("lens signer is required")
+	}
+	if c.IPFS == nil {
+		return fmt.Errorf("IPFS client is required to pin lens metadata")
+	}
+	if c.APIURL == "" {
+		c.APIURL = DefaultAPIURL
+	}
+	c.APIURL = strings.TrimRight(c.APIURL, "/")
+	if c.AppID == "" {
+		c.AppID = DefaultAppID
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = DefaultRequestTimeout
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package lens
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+)
+
+// SetDryRun makes the client hand every publication to recorder instead of
+// pinning its metadata and broadcasting it. Call it before the client is
+// shared between goroutines.
+func (c *Client) SetDryRun(recorder dryrun.Recorder) {
+	c.dryRun = recorder
+}
+
+// DryRun reports whether publications are recorded instead of broadcast
+func (c *Client) DryRun() bool {
+	return c.dryRun != nil
+}
+
+// recordDryRunPublication records a publication and returns it as if it had
+// been broadcast; it has no content URI or transaction
+func (c *Client) recordDryRunPublication(ctx context.Context, publication *Publication) (*Publication, error) {
+	record := dryrun.Record{
+		Kind:    dryrun.KindLens,
+		Target:  publication.CommentOn,
+		Content: publication.Content,
+		Details: map[string]interface{}{
+			"profile_id": c.config.ProfileID,
+			"kind":       publication.Kind,
+		},
+	}
+	if err := c.dryRun.RecordDryRun(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to record dry-run publication: %w", err)
+	}
+
+	publication.CreatedAt = time.Now()
+	return publication, nil
+}
@@ -0,0 +1,157 @@
+package lens
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const typedDataDomainFields = `domain { name chainId version verifyingContract }`
+
+const postTypedDataMutation = `mutation CreateOnchainPostTypedData($request: OnchainPostRequest!) {
+  createOnchainPostTypedData(request: $request) {
+    id
+    typedData {
+      types { Post { name type } }
+      ` + typedDataDomainFields + `
+      value {
+        nonce deadline profileId contentURI
+        actionModules actionModulesInitDatas referenceModule referenceModuleInitData
+      }
+    }
+  }
+}`
+
+const commentTypedDataMutation = `mutation CreateOnchainCommentTypedData($request: OnchainCommentRequest!) {
+  createOnchainCommentTypedData(request: $request) {
+    id
+    typedData {
+      types { Comment { name type } }
+      ` + typedDataDomainFields + `
+      value {
+        nonce deadline profileId contentURI
+        pointedProfileId pointedPubId referrerProfileIds referrerPubIds referenceModuleData
+        actionModules actionModulesInitDatas referenceModule referenceModuleInitData
+      }
+    }
+  }
+}`
+
+const broadcastMutation = `mutation BroadcastOnchain($request: BroadcastRequest!) {
+  broadcastOnchain(request: $request) {
+    __typename
+    ... on RelaySuccess { txHash txId }
+    ... on RelayError { reason }
+  }
+}`
+
+// Post publishes a text post from the bot's profile
+func (c *Client) Post(ctx context.Context, content string) (*Publication, error) {
+	return c.publish(ctx, &Publication{Kind: KindPost, Content: content})
+}
+
+// Comment publishes a text comment on another publication, e.g. 0x01a2-0x3f
+func (c *Client) Comment(ctx context.Context, publicationID, content string) (*Publication, error) {
+	if publicationID == "" {
+		return nil, fmt.Errorf("publication ID is required to comment")
+	}
+	return c.publish(ctx, &Publication{Kind: KindComment, CommentOn: publicationID, Content: content})
+}
+
+// publish pins the metadata, signs the typed data the API builds for it and
+// has the Lens relayer broadcast the transaction. In dry-run mode nothing is
+// pinned or broadcast.
+func (c *Client) publish(ctx context.Context, publication *Publication) (*Publication, error) {
+	if strings.TrimSpace(publication.Content) == "" {
+		return nil, fmt.Errorf("publication content is empty")
+	}
+	if c.dryRun != nil {
+		return c.recordDryRunPublication(ctx, publication)
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"profile_id": c.config.ProfileID,
+		"kind":       publication.Kind,
+		"comment_on": publication.CommentOn,
+	})
+
+	pin, err := c.config.IPFS.PinJSON(ctx, "metadata.json", c.metadata(publication.Content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin metadata: %w", err)
+	}
+	publication.ContentURI = pin.URI()
+
+	request := map[string]interface{}{"contentURI": publication.ContentURI}
+	mutation, field, primaryType := postTypedDataMutation, "createOnchainPostTypedData", "Post"
+	if publication.Kind == KindComment {
+		request["commentOn"] = publication.CommentOn
+		mutation, field, primaryType = commentTypedDataMutation, "createOnchainCommentTypedData", "Comment"
+	}
+
+	var created map[string]typedDataResult
+	if err := c.authenticatedGraphQL(ctx, mutation, map[string]interface{}{"request": request}, &created); err != nil {
+		return nil, fmt.Errorf("failed to create typed data: %w", err)
+	}
+	result, ok := created[field]
+	if !ok {
+		return nil, fmt.Errorf("response has no %s", field)
+	}
+
+	typedData, err := result.typedData(primaryType)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := c.config.Signer.SignTypedData(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign typed data: %w", err)
+	}
+
+	var broadcast struct {
+		BroadcastOnchain relayResult `json:"broadcastOnchain"`
+	}
+	err = c.authenticatedGraphQL(ctx, broadcastMutation, map[string]interface{}{
+		"request": map[string]string{
+			"id":        result.ID,
+			"signature": hexutil.Encode(signature),
+		},
+	}, &broadcast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast publication: %w", err)
+	}
+	if relay := broadcast.BroadcastOnchain; relay.TypeName == "RelayError" || relay.Reason != "" {
+		return nil, fmt.Errorf("lens relayer rejected publication: %s", relay.Reason)
+	}
+
+	publication.TxHash = broadcast.BroadcastOnchain.TxHash
+	publication.TxID = broadcast.BroadcastOnchain.TxID
+	publication.CreatedAt = time.Now()
+
+	log.WithFields(logrus.Fields{
+		"content_uri": publication.ContentURI,
+		"tx_hash":     publication.TxHash,
+	}).Info("Published to Lens")
+
+	if c.config.Recorder != nil {
+		if err := c.config.Recorder.SaveLensPublication(ctx, publication); err != nil {
+			log.WithError(err).Error("Failed to record Lens publication")
+		}
+	}
+
+	return publication, nil
+}
+
+// metadata builds the text-only publication metadata pinned for the content
+func (c *Client) metadata(content string) textOnlyMetadata {
+	metadata := textOnlyMetadata{Schema: textOnlySchema}
+	metadata.Lens.ID = uuid.NewString()
+	metadata.Lens.MainContentFocus = "TEXT_ONLY"
+	metadata.Lens.Content = content
+	metadata.Lens.Locale = "en"
+	metadata.Lens.AppID = c.config.AppID
+	return metadata
+}
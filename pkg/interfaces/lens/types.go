@@ -0,0 +1,115 @@
+package lens
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Publication kinds
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+// Publication is a post or comment the client broadcast
+type Publication struct {
+	Kind string `json:"kind"`
+	// CommentOn is the publication a comment replies to, e.g. 0x01a2-0x3f
+	CommentOn string `json:"comment_on,omitempty"`
+	Content   string `json:"content"`
+	// ContentURI is the ipfs:// URI of the pinned publication metadata
+	ContentURI string `json:"content_uri"`
+	// TxHash is the relayed transaction; TxID tracks it in the Lens API until
+	// the hash is known
+	TxHash    string    `json:"tx_hash"`
+	TxID      string    `json:"tx_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// textOnlyMetadata is the Lens v3 metadata for a publication without media
+type textOnlyMetadata struct {
+	Schema string `json:"$schema"`
+	Lens   struct {
+		ID               string `json:"id"`
+		MainContentFocus string `json:"mainContentFocus"`
+		Content          string `json:"content"`
+		Locale           string `json:"locale"`
+		AppID            string `json:"appId,omitempty"`
+	} `json:"lens"`
+}
+
+const textOnlySchema = "https://json-schemas.lens.dev/publications/text-only/3.0.0.json"
+
+// graphQLResponse is the envelope of every Lens API response
+type graphQLResponse struct {
+	Data   interface{} `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// typedDataResult is the response of the create*TypedData mutations
+type typedDataResult struct {
+	ID        string `json:"id"`
+	TypedData struct {
+		Types   map[string][]apitypes.Type `json:"types"`
+		Domain  apitypes.TypedDataDomain   `json:"domain"`
+		Message map[string]interface{}     `json:"value"`
+	} `json:"typedData"`
+}
+
+// typedData converts the result into EIP-712 typed data with the given
+// primary type, adding the domain type the API leaves out
+func (r typedDataResult) typedData(primaryType string) (apitypes.TypedData, error) {
+	fields, ok := r.TypedData.Types[primaryType]
+	if !ok {
+		return apitypes.TypedData{}, fmt.Errorf("typed data has no %s type", primaryType)
+	}
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": []apitypes.Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			primaryType: fields,
+		},
+		PrimaryType: primaryType,
+		Domain:      r.TypedData.Domain,
+		Message:     r.TypedData.Message,
+	}, nil
+}
+
+// relayResult is the response of broadcastOnchain, a RelaySuccess or RelayError
+type relayResult struct {
+	TypeName string `json:"__typename"`
+	TxHash   string `json:"txHash"`
+	TxID     string `json:"txId"`
+	Reason   string `json:"reason"`
+}
+
+// APIError is a GraphQL error returned by the Lens API
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("lens API error (status %d, %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("lens API error (status %d): %s", e.StatusCode, strings.TrimSpace(e.Message))
+}
+
+// unauthenticated reports whether the access token was rejected
+func (e *APIError) unauthenticated() bool {
+	return e.StatusCode == 401 || e.Code == "UNAUTHENTICATED"
+}
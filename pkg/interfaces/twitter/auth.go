@@ -3,7 +3,6 @@ package twitter
 import (
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/mrjones/oauth"
 	"github.com/sirupsen/logrus"
@@ -24,6 +23,8 @@ type Authenticator struct {
 	accessToken       string
 	accessTokenSecret string
 	bearerToken       string
+	// oauth2 is set when requests are made in the OAuth 2.0 user context
+	oauth2 *oauth2Source
 }
 
 func NewAuthenticator(config *TwitterConfig) (*Authenticator, error) {
@@ -32,12 +33,30 @@ func NewAuthenticator(config *TwitterConfig) (*Authenticator, error) {
 		"method":    "NewAuthenticator",
 	})
 
-	log.Debug("Creating new authenticator with config", "config", config)
+	log.WithField("config", config).Debug("Creating new authenticator")
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		var err error
+		if httpClient, err = NewHTTPClient(config); err != nil {
+			return nil, err
+		}
+	}
+
+	// The OAuth 2.0 user context, when configured, takes precedence
+	if config.OAuth2.Enabled() {
+		if config.OAuth2.Tokens == nil {
+			return nil, fmt.Errorf("OAuth 2.0 requires a token store")
+		}
+		log.Debug("Using OAuth 2.0 user context authentication")
+		return newOAuth2Authenticator(httpClient, config)
+	}
 
 	// For write operations (POST tweets), we need OAuth 1.0a
 	if config.ConsumerKey != "" && config.AccessToken != "" {
 		log.Debug("Using OAuth 1.0a authentication")
 		return newUserAuthenticator(
+			httpClient,
 			config.ConsumerKey,
 			config.ConsumerSecret,
 			config.AccessToken,
@@ -48,14 +67,34 @@ func NewAuthenticator(config *TwitterConfig) (*Authenticator, error) {
 	// For read-only operations, we can use Bearer token
 	if config.BearerToken != "" {
 		log.Debug("Using Bearer token authentication")
-		return newAppAuthenticator(config.BearerToken)
+		return newAppAuthenticator(httpClient, config.BearerToken)
 	}
 
 	log.Error("No valid authentication credentials provided")
 	return nil, fmt.Errorf("either OAuth 1.0a credentials or Bearer token must be provided")
 }
 
-func newAppAuthenticator(bearerToken string) (*Authenticator, error) {
+func newOAuth2Authenticator(httpClient *http.Client, config *TwitterConfig) (*Authenticator, error) {
+	source := &oauth2Source{
+		settings: config.OAuth2,
+		client:   httpClient,
+		logger:   config.Logger,
+	}
+
+	client := *httpClient
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &oauth2Transport{next: next, source: source}
+
+	return &Authenticator{
+		client: &client,
+		oauth2: source,
+	}, nil
+}
+
+func newAppAuthenticator(client *http.Client, bearerToken string) (*Authenticator, error) {
 	log := logrus.WithFields(logrus.Fields{
 		"component": "Authenticator",
 		"method":    "newAppAuthenticator",
@@ -63,10 +102,6 @@ func newAppAuthenticator(bearerToken string) (*Authenticator, error) {
 
 	log.Debug("Creating new app authenticator")
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
 	auth := &Authenticator{
 		client:      client,
 		bearerToken: bearerToken,
@@ -76,7 +111,7 @@ func newAppAuthenticator(bearerToken string) (*Authenticator, error) {
 	return auth, nil
 }
 
-func newUserAuthenticator(consumerKey, consumerSecret, accessToken, accessTokenSecret string) (*Authenticator, error) {
+func newUserAuthenticator(httpClient *http.Client, consumerKey, consumerSecret, accessToken, accessTokenSecret string) (*Authenticator, error) {
 	log := logrus.WithFields(logrus.Fields{
 		"component": "Authenticator",
 		"method":    "newUserAuthenticator",
@@ -90,9 +125,7 @@ func newUserAuthenticator(consumerKey, consumerSecret, accessToken, accessTokenS
 		AccessTokenUrl:    AccessTokenURL,
 	})
 
-	consumer.HttpClient = &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	consumer.HttpClient = httpClient
 
 	token := oauth.AccessToken{
 		Token:  accessToken,
@@ -130,6 +163,11 @@ func (a *Authenticator) SetAuthHeader(req *http.Request) error {
 
 	log.Debug("Setting authentication header")
 
+	if a.oauth2 != nil {
+		log.Debug("Using OAuth 2.0 user context authentication")
+		return nil
+	}
+
 	if a.bearerToken != "" {
 		log.Debug("Using Bearer token authentication")
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.bearerToken))
@@ -21,8 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-
-	"github.com/sirupsen/logrus"
 )
 
 // TweetMedia represents media attachments in a tweet
@@ -55,6 +53,7 @@ type BaseOptions struct {
 	ConversationID        string            `json:"conversation_id,omitempty"` // Thread conversation ID
 	Poll                  *Poll             `json:"poll,omitempty"`            // Poll configuration
 	Media                 []Media           `json:"media,omitempty"`           // Media attachments
+	MediaIDs              []string          `json:"media_ids,omitempty"`       // IDs returned by UploadMedia
 	ReplySettings         string            `json:"reply_settings,omitempty"`  // Reply permission settings
 	ForSuperFollowersOnly bool              `json:"for_super_followers_only,omitempty"`
 	ReplyOptions          *ReplyOptions     `json:"reply,omitempty"`
@@ -84,6 +83,12 @@ func buildBaseRequest(text string, opts *BaseOptions) BaseTweetRequest {
 				request.Media.MediaIDs[i] = m.MediaKey
 			}
 		}
+		if len(opts.MediaIDs) > 0 {
+			if request.Media == nil {
+				request.Media = &TweetMedia{}
+			}
+			request.Media.MediaIDs = append(request.Media.MediaIDs, opts.MediaIDs...)
+		}
 	}
 
 	return request
@@ -94,8 +99,16 @@ func buildBaseRequest(text string, opts *BaseOptions) BaseTweetRequest {
 // - 200 tweets per 15-minute window (user auth)
 // - 50 tweet deletions per 15-minute window (user auth)
 func (c *TwitterClient) postTweetHelper(ctx context.Context, endpoint string, request interface{}) (*Tweet, error) {
-	// Log the request payload
-	c.logger.WithField("request", request).Debug("sending tweet request")
+	if c.dryRun != nil {
+		switch req := request.(type) {
+		case CreateTweetRequest:
+			return c.recordDryRunTweet(ctx, req.BaseTweetRequest)
+		case BaseTweetRequest:
+			return c.recordDryRunTweet(ctx, req)
+		default:
+			return nil, fmt.Errorf("dry run: unsupported tweet request %T", request)
+		}
+	}
 
 	resp, err := c.makeRequest(ctx, "POST", endpoint, request)
 	if err != nil {
@@ -111,13 +124,6 @@ func (c *TwitterClient) postTweetHelper(ctx context.Context, endpoint string, re
 		return nil, err
 	}
 
-	// Log the raw response
-	c.logger.WithFields(logrus.Fields{
-		"status_code": resp.StatusCode,
-		"response":    string(bodyBytes),
-		"endpoint":    endpoint,
-	}).Debug("received twitter API response")
-
 	// Recreate the response body for JSON decoding
 	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
@@ -0,0 +1,92 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// bookmarkResponse represents the response from the bookmark endpoints
+type bookmarkResponse struct {
+	Data struct {
+		Bookmarked bool `json:"bookmarked"`
+	} `json:"data"`
+	Errors []TwitterError `json:"errors,omitempty"`
+}
+
+// AddBookmark bookmarks a tweet for the authenticated user
+// Rate limit: 50/15m (user)
+func (c *TwitterClient) AddBookmark(ctx context.Context, tweetID string) error {
+	userID, err := c.GetAuthenticatedUserID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve authenticated user: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/bookmarks", c.config.UserEndpoint, userID)
+	bookmarked, err := c.bookmarkRequest(ctx, "AddBookmark", http.MethodPost, endpoint, tweetID, map[string]string{"tweet_id": tweetID})
+	if err != nil {
+		return err
+	}
+	if !bookmarked {
+		return fmt.Errorf("tweet %s was not bookmarked", tweetID)
+	}
+	return nil
+}
+
+// RemoveBookmark removes a tweet from the authenticated user's bookmarks
+// Rate limit: 50/15m (user)
+func (c *TwitterClient) RemoveBookmark(ctx context.Context, tweetID string) error {
+	userID, err := c.GetAuthenticatedUserID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve authenticated user: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/bookmarks/%s", c.config.UserEndpoint, userID, tweetID)
+	bookmarked, err := c.bookmarkRequest(ctx, "RemoveBookmark", http.MethodDelete, endpoint, tweetID, nil)
+	if err != nil {
+		return err
+	}
+	if bookmarked {
+		return fmt.Errorf("tweet %s is still bookmarked", tweetID)
+	}
+	return nil
+}
+
+// bookmarkRequest sends a bookmark change and returns whether the tweet is
+// bookmarked afterwards. In dry-run mode nothing is sent.
+func (c *TwitterClient) bookmarkRequest(ctx context.Context, method, httpMethod, endpoint, tweetID string, body interface{}) (bool, error) {
+	log := c.logger.WithFields(logrus.Fields{
+		"method":   method,
+		"tweet_id": tweetID,
+	})
+
+	if c.dryRun != nil {
+		log.Info("Dry run: skipping bookmark change")
+		return httpMethod == http.MethodPost, nil
+	}
+
+	if err := c.requireScopes(ctx, ScopeBookmarkWrite); err != nil {
+		return false, err
+	}
+
+	resp, err := c.makeRequest(ctx, httpMethod, endpoint, body)
+	if err != nil {
+		log.WithError(err).Error("Failed to change bookmark")
+		return false, fmt.Errorf("failed to change bookmark: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var bookmarkResp bookmarkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bookmarkResp); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(bookmarkResp.Errors) > 0 {
+		return false, &bookmarkResp.Errors[0]
+	}
+
+	log.WithField("bookmarked", bookmarkResp.Data.Bookmarked).Debug("Changed bookmark")
+	return bookmarkResp.Data.Bookmarked, nil
+}
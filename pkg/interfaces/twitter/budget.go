@@ -0,0 +1,151 @@
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Budget shares used by the built-in actions
+const (
+	// BudgetReplies covers posting: replies, original thoughts and judgments
+	BudgetReplies = "replies"
+	// BudgetReads covers mention polling and conversation lookups
+	BudgetReads = "reads"
+	// BudgetAnalytics covers metrics and engagement tracking
+	BudgetAnalytics = "analytics"
+)
+
+// DefaultBudgetShares splits a budget when no shares are configured
+var DefaultBudgetShares = map[string]float64{
+	BudgetReplies:   0.6,
+	BudgetReads:     0.3,
+	BudgetAnalytics: 0.1,
+}
+
+// Budget partitions the requests an account may make per window between
+// shares, so that one kind of work cannot use up the API limits the others
+// need. A request counts against the share attached to its context with
+// WithBudgetShare; requests without a share are not limited.
+type Budget struct {
+	mu     sync.Mutex
+	window time.Duration
+	shares map[string]*budgetShare
+}
+
+// budgetShare counts the requests of one share in the current window
+type budgetShare struct {
+	limit int
+	used  int
+	reset time.Time
+}
+
+// BudgetUsage is the state of one share in the current window
+type BudgetUsage struct {
+	Share string    `json:"share"`
+	Limit int       `json:"limit"`
+	Used  int       `json:"used"`
+	Reset time.Time `json:"reset"`
+}
+
+// NewBudget splits requests per window between shares by fraction. Every
+// share with a positive fraction may make at least one request per window.
+func NewBudget(requests int, window time.Duration, shares map[string]float64) *Budget {
+	b := &Budget{
+		window: window,
+		shares: make(map[string]*budgetShare, len(shares)),
+	}
+	for name, fraction := range shares {
+		if fraction <= 0 {
+			continue
+		}
+		limit := int(float64(requests) * fraction)
+		if limit < 1 {
+			limit = 1
+		}
+		b.shares[name] = &budgetShare{limit: limit}
+	}
+	return b
+}
+
+// WithBudget makes the client's requests count against budget
+func WithBudget(budget *Budget) ClientOption {
+	return func(c *TwitterClient) {
+		c.budget = budget
+	}
+}
+
+type budgetShareKey struct{}
+
+// WithBudgetShare attaches the budget share the requests made with ctx count
+// against
+func WithBudgetShare(ctx context.Context, share string) context.Context {
+	return context.WithValue(ctx, budgetShareKey{}, share)
+}
+
+// BudgetShareFrom returns the budget share attached to ctx, if any
+func BudgetShareFrom(ctx context.Context) string {
+	share, _ := ctx.Value(budgetShareKey{}).(string)
+	return share
+}
+
+// Reserve takes one request from the share of ctx. When the share is used
+// up, it blocks until the share's window ends or ctx is done. A nil budget,
+// and a share it does not know, do not limit requests.
+func (b *Budget) Reserve(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	name := BudgetShareFrom(ctx)
+	share, ok := b.shares[name]
+	if !ok {
+		return nil
+	}
+
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		if !now.Before(share.reset) {
+			share.used = 0
+			share.reset = now.Add(b.window)
+		}
+		if share.used < share.limit {
+			share.used++
+			b.mu.Unlock()
+			return nil
+		}
+		wait := share.reset.Sub(now)
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("budget share %s used up: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Usage returns the state of every share, sorted by name
+func (b *Budget) Usage() []BudgetUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	usage := make([]BudgetUsage, 0, len(b.shares))
+	for name, share := range b.shares {
+		u := BudgetUsage{Share: name, Limit: share.limit}
+		if now.Before(share.reset) {
+			u.Used = share.used
+			u.Reset = share.reset
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Share < usage[j].Share
+	})
+	return usage
+}
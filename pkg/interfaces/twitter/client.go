@@ -9,8 +9,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,41 +25,72 @@ type TwitterClient struct {
 	auth   *Authenticator
 	logger *logrus.Logger
 	log    *logrus.Logger
+
+	userIDMu sync.Mutex
+	userID   string // cached authenticated user ID
+
+	events *events.Bus
+	// dryRun, when set, receives posts instead of the API
+	dryRun dryrun.Recorder
+	// requestLog samples and truncates the debug log of API requests
+	requestLog RequestLogOptions
+	// budget limits the requests of each budget share; nil leaves them unlimited
+	budget *Budget
+	// recorder, when set, receives every API exchange
+	recorder HTTPRecorder
+	// quota follows the rate limit windows of the endpoints called
+	quota *quotaTracker
 }
 
-// NewTwitterClient creates a new Twitter API client
-func NewTwitterClient(config *TwitterConfig, opts ...ClientOption) (*TwitterClient, error) {
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
-	}
-
-	auth, err := NewAuthenticator(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create authenticator: %w", err)
+// WithEventBus publishes client events such as rate limit hits to bus
+func WithEventBus(bus *events.Bus) ClientOption {
+	return func(c *TwitterClient) {
+		c.events = bus
 	}
+}
 
+// NewTwitterClient creates a new Twitter API client
+func NewTwitterClient(config *TwitterConfig, opts ...ClientOption) (*TwitterClient, error) {
 	client := &TwitterClient{
 		config: config,
-		auth:   auth,
 		logger: config.Logger,
 		log:    logrus.New(),
+		quota:  newQuotaTracker(),
+		requestLog: RequestLogOptions{
+			SampleRate: DefaultLogSampleRate,
+			BodyLimit:  DefaultLogBodyLimit,
+		},
 	}
 
+	// Options may add credentials, so they apply before validation
 	for _, opt := range opts {
 		opt(client)
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	auth, err := NewAuthenticator(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticator: %w", err)
+	}
+	auth.client = withQuota(auth.client, client.quota)
+	auth.client = withRetries(auth.client, config.RetryAttempts, config.RetryBackoff, config.Logger)
+	if client.recorder != nil {
+		auth.client = withRecording(auth.client, client.recorder, config.Logger)
+	}
+	// Outermost, so that a request is logged once whatever its retries
+	auth.client = withRequestLogging(auth.client, client.requestLog, config.Logger,
+		config.ConsumerSecret, config.AccessToken, config.AccessTokenSecret, config.BearerToken,
+		config.OAuth2.ClientSecret)
+	client.auth = auth
+
 	return client, nil
 }
 
 // handleResponse checks for API errors in the response
 func (c *TwitterClient) handleResponse(resp *http.Response) error {
-	// Log response headers and status
-	c.logger.WithFields(logrus.Fields{
-		"status_code": resp.StatusCode,
-		"headers":     resp.Header,
-	}).Debug("Received API response")
-
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		return nil
 	}
@@ -67,9 +101,6 @@ func (c *TwitterClient) handleResponse(resp *http.Response) error {
 		return fmt.Errorf("failed to read error response: %w", err)
 	}
 
-	// Log raw response body
-	c.logger.WithField("response_body", string(body)).Debug("Error response body")
-
 	var errResp struct {
 		Errors []struct {
 			Message string `json:"message"`
@@ -78,7 +109,7 @@ func (c *TwitterClient) handleResponse(resp *http.Response) error {
 	}
 
 	if err := json.Unmarshal(body, &errResp); err != nil {
-		return fmt.Errorf("twitter api error: status=%d body=%s", resp.StatusCode, string(body))
+		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	if len(errResp.Errors) > 0 {
@@ -87,11 +118,14 @@ func (c *TwitterClient) handleResponse(resp *http.Response) error {
 			"error_code":  errResp.Errors[0].Code,
 			"message":     errResp.Errors[0].Message,
 		}).Error("Twitter API error")
-		return fmt.Errorf("twitter api error: code=%d message=%s",
-			errResp.Errors[0].Code, errResp.Errors[0].Message)
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       errResp.Errors[0].Code,
+			Message:    errResp.Errors[0].Message,
+		}
 	}
 
-	return fmt.Errorf("twitter api error: status=%d", resp.StatusCode)
+	return &APIError{StatusCode: resp.StatusCode}
 }
 
 func (c *TwitterClient) handleRateLimits(resp *http.Response) error {
@@ -134,9 +168,54 @@ func (c *TwitterClient) handleRateLimits(resp *http.Response) error {
 		"wait_duration":      waitDuration.Round(time.Second),
 	}).Warning("Rate limit exceeded")
 
-	return fmt.Errorf("rate limit exceeded, reset in %v at %v",
-		waitDuration.Round(time.Second),
-		resetTime.Format(time.RFC3339))
+	// The user ID lookup holds the lock while it hits the API; if that is the
+	// request being limited, the bot ID is not known yet
+	var botID string
+	if c.userIDMu.TryLock() {
+		botID = c.userID
+		c.userIDMu.Unlock()
+	}
+	c.events.Emit(events.RateLimitHit, botID, map[string]interface{}{
+		"endpoint":           resp.Request.URL.Path,
+		"endpoint_remaining": endpointRemaining,
+		"daily_remaining":    dailyRemaining,
+		"reset_time":         resetTime.UTC(),
+	})
+
+	return &RateLimitError{Reset: resetTime}
+}
+
+// APIError is returned when the API answers with an error status other than
+// 429 Too Many Requests
+type APIError struct {
+	StatusCode int
+	// Code and Message are those of the first error in the response, if any
+	Code    int
+	Message string
+	// Body is the raw response when it has no error list
+	Body string
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Code != 0 || e.Message != "":
+		return fmt.Sprintf("twitter api error: code=%d message=%s", e.Code, e.Message)
+	case e.Body != "":
+		return fmt.Sprintf("twitter api error: status=%d body=%s", e.StatusCode, e.Body)
+	default:
+		return fmt.Sprintf("twitter api error: status=%d", e.StatusCode)
+	}
+}
+
+// RateLimitError is returned when the API responds with 429 Too Many Requests
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, reset in %v at %v",
+		time.Until(e.Reset).Round(time.Second),
+		e.Reset.Format(time.RFC3339))
 }
 
 func (c *TwitterClient) makeRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
@@ -145,6 +224,11 @@ func (c *TwitterClient) makeRequest(ctx context.Context, method, endpoint string
 		"endpoint": endpoint,
 	}).Debug("Preparing API request")
 
+	// Waiting for the budget does not count against the request timeout
+	if err := c.budget.Reserve(ctx); err != nil {
+		return nil, err
+	}
+
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
@@ -159,10 +243,6 @@ func (c *TwitterClient) makeRequest(ctx context.Context, method, endpoint string
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		bodyReader = bytes.NewReader(jsonBody)
-		c.logger.WithFields(logrus.Fields{
-			"request_body": string(jsonBody),
-			"content_type": "application/json",
-		}).Debug("Request payload")
 	}
 
 	fullURL := c.config.BaseURL + endpoint
@@ -176,11 +256,6 @@ func (c *TwitterClient) makeRequest(ctx context.Context, method, endpoint string
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	// Log request headers
-	c.logger.WithFields(logrus.Fields{
-		"headers": req.Header,
-	}).Debug("Request headers")
-
 	// OAuth 1.0a client will handle the authentication headers
 	resp, err := c.auth.GetClient().Do(req)
 	if err != nil {
@@ -214,6 +289,10 @@ func (c *TwitterClient) makeRequest(ctx context.Context, method, endpoint string
 
 // makeRequestWithParams makes a request to the Twitter API with query parameters
 func (c *TwitterClient) makeRequestWithParams(ctx context.Context, method, endpoint string, queryParams map[string]string) (*http.Response, error) {
+	if err := c.budget.Reserve(ctx); err != nil {
+		return nil, err
+	}
+
 	url := fmt.Sprintf("%s%s", c.config.BaseURL, endpoint)
 
 	req, err := http.NewRequestWithContext(ctx, method, url, nil)
@@ -264,12 +343,39 @@ func (c *TwitterClient) makeRequestWithParams(ctx context.Context, method, endpo
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("twitter API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return resp, nil
 }
 
+// tweetQueryParams returns the tweet.fields and expansions query parameters:
+// the configured defaults plus the given extras, each listed once
+func (c *TwitterClient) tweetQueryParams(fields, expansions []string) map[string]string {
+	params := map[string]string{
+		"tweet.fields": joinUnique(c.config.GetTweetFields(fields...)),
+	}
+	if expansions := joinUnique(append(append([]string{}, c.config.GetExpansions()...), expansions...)); expansions != "" {
+		params["expansions"] = expansions
+	}
+	return params
+}
+
+// joinUnique joins values with commas, dropping empty and repeated values,
+// which the API rejects
+func joinUnique(values []string) string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return strings.Join(unique, ",")
+}
+
 // Helper functions to parse headers
 func parseIntHeader(value string) int {
 	if value == "" {
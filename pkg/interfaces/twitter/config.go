@@ -2,10 +2,10 @@ package twitter
 
 import (
 	"fmt"
-	"os"
-	"strconv"
+	"net/http"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,6 +17,8 @@ type TwitterConfig struct {
 	AccessTokenSecret string
 	BearerToken       string
 	UserID            string
+	// OAuth2, when enabled, authenticates in the OAuth 2.0 user context
+	OAuth2 OAuth2Settings
 
 	// API Endpoints
 	BaseURL          string
@@ -25,11 +27,26 @@ type TwitterConfig struct {
 	StreamEndpoint   string
 	SearchEndpoint   string
 	TimelineEndpoint string
+	MediaEndpoint    string
+	ListEndpoint     string
 
 	// Rate Limiting
 	RateLimit     int
 	RateWindow    int
 	RetryAttempts int
+	// RetryBackoff is the wait before the first retry of a request that
+	// failed transiently; it doubles with every further attempt
+	RetryBackoff time.Duration
+
+	// HTTP transport. HTTPClient, when set, is used as is instead of a client
+	// built from the settings below.
+	HTTPClient *http.Client
+	// ProxyURL routes requests through a proxy; empty uses HTTPS_PROXY
+	ProxyURL string
+	// MaxIdleConnsPerHost is how many keep-alive connections are kept open
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections idle for longer
+	IdleConnTimeout time.Duration
 
 	// API Fields Configuration (based on Twitter v2 data dictionary)
 	DefaultFields   []string
@@ -40,41 +57,63 @@ type TwitterConfig struct {
 	Logger *logrus.Logger
 }
 
+// NewTwitterConfig creates a TwitterConfig from environment variables
 func NewTwitterConfig() (*TwitterConfig, error) {
-	if err := godotenv.Load(); err != nil {
-		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("error loading .env file: %w", err)
-		}
+	cfg, err := config.FromEnv()
+	if err != nil {
+		return nil, err
 	}
 
-	// Load rate limiting from env or use defaults
-	rateLimit, _ := strconv.Atoi(getEnvOrDefault("TWITTER_RATE_LIMIT", "180"))
-	rateWindow, _ := strconv.Atoi(getEnvOrDefault("TWITTER_RATE_WINDOW", "15"))
-	retryAttempts, _ := strconv.Atoi(getEnvOrDefault("TWITTER_RETRY_ATTEMPTS", "3"))
+	logger := logrus.New()
+	if level, err := logrus.ParseLevel(cfg.Log.Level); err == nil {
+		logger.SetLevel(level)
+	}
 
-	userID := os.Getenv("TWITTER_USER_ID")
+	return NewTwitterConfigFrom(cfg.Twitter, logger)
+}
+
+// NewTwitterConfigFrom creates a TwitterConfig from the central agent configuration
+func NewTwitterConfigFrom(settings config.TwitterConfig, logger *logrus.Logger) (*TwitterConfig, error) {
+	if logger == nil {
+		logger = logrus.New()
+	}
 
 	config := &TwitterConfig{
 		// API Authentication
-		ConsumerKey:       os.Getenv("TWITTER_CONSUMER_KEY"),
-		ConsumerSecret:    os.Getenv("TWITTER_CONSUMER_SECRET"),
-		AccessToken:       os.Getenv("TWITTER_ACCESS_TOKEN"),
-		AccessTokenSecret: os.Getenv("TWITTER_ACCESS_TOKEN_SECRET"),
-		BearerToken:       os.Getenv("TWITTER_BEARER_TOKEN"),
-		UserID:            userID,
+		ConsumerKey:       settings.ConsumerKey,
+		ConsumerSecret:    settings.ConsumerSecret,
+		AccessToken:       settings.AccessToken,
+		AccessTokenSecret: settings.AccessTokenSecret,
+		BearerToken:       settings.BearerToken,
+		UserID:            settings.UserID,
+		OAuth2: OAuth2Settings{
+			ClientID:      settings.OAuth2.ClientID,
+			ClientSecret:  settings.OAuth2.ClientSecret,
+			RedirectURL:   settings.OAuth2.RedirectURL,
+			Scopes:        settings.OAuth2.Scopes,
+			RefreshBefore: settings.OAuth2.RefreshBefore,
+		},
 
 		// API Endpoints
-		BaseURL:          getEnvOrDefault("TWITTER_API_BASE_URL", "https://api.twitter.com/2"),
+		BaseURL:          settings.BaseURL,
 		TweetEndpoint:    "/tweets",
 		UserEndpoint:     "/users",
 		StreamEndpoint:   "/tweets/search/stream",
 		SearchEndpoint:   "/tweets/search/recent",
 		TimelineEndpoint: "/users/:id/tweets",
+		MediaEndpoint:    "/media/upload",
+		ListEndpoint:     "/lists",
 
 		// Rate Limiting
-		RateLimit:     rateLimit,
-		RateWindow:    rateWindow,
-		RetryAttempts: retryAttempts,
+		RateLimit:     settings.RateLimit,
+		RateWindow:    settings.RateWindow,
+		RetryAttempts: settings.RetryAttempts,
+		RetryBackoff:  settings.RetryBackoff,
+
+		// HTTP transport
+		ProxyURL:            settings.ProxyURL,
+		MaxIdleConnsPerHost: settings.MaxIdleConnsPerHost,
+		IdleConnTimeout:     settings.IdleConnTimeout,
 
 		// Default API Fields (based on Twitter v2 data dictionary)
 		DefaultFields: []string{"id", "text", "created_at"},
@@ -95,21 +134,13 @@ func NewTwitterConfig() (*TwitterConfig, error) {
 			"entities.mentions.username",
 		},
 
-		Logger: func() *logrus.Logger {
-			log := logrus.New()
-			// Set log level from environment variable
-			if level := os.Getenv("LOG_LEVEL"); level != "" {
-				if parsedLevel, err := logrus.ParseLevel(level); err == nil {
-					log.SetLevel(parsedLevel)
-				}
-			}
-			return log
-		}(),
+		Logger: logger,
 	}
 
 	config.Logger.WithFields(logrus.Fields{
 		"consumer_key_exists": config.ConsumerKey != "",
 		"bearer_token_exists": config.BearerToken != "",
+		"oauth2_enabled":      config.OAuth2.Enabled(),
 		"base_url":            config.BaseURL,
 		"rate_limit":          config.RateLimit,
 	}).Debug("Twitter config initialized")
@@ -139,9 +170,10 @@ func (c *TwitterConfig) Validate() error {
 			"access_token_secret_exists": c.AccessTokenSecret != "",
 		}).Debug("OAuth credentials validation")
 
-		// If no OAuth credentials, require bearer token for read-only operations
-		if c.BearerToken == "" {
-			return fmt.Errorf("either OAuth 1.0a credentials or Bearer token must be provided")
+		// If no OAuth credentials, require an OAuth 2.0 client or a bearer
+		// token for read-only operations
+		if c.BearerToken == "" && !c.OAuth2.Enabled() {
+			return fmt.Errorf("either OAuth 1.0a credentials, an OAuth 2.0 client or Bearer token must be provided")
 		}
 	}
 
@@ -155,6 +187,18 @@ func (c *TwitterConfig) Validate() error {
 	if c.RetryAttempts < 0 {
 		return fmt.Errorf("retry attempts cannot be negative")
 	}
+	if c.RetryBackoff < 0 || c.MaxIdleConnsPerHost < 0 || c.IdleConnTimeout < 0 {
+		return fmt.Errorf("retry backoff and connection pool settings cannot be negative")
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = 10
+	}
+	if c.IdleConnTimeout == 0 {
+		c.IdleConnTimeout = 90 * time.Second
+	}
 
 	// Set default endpoints if not provided
 	if c.BaseURL == "" {
@@ -163,19 +207,41 @@ func (c *TwitterConfig) Validate() error {
 	if c.TweetEndpoint == "" {
 		c.TweetEndpoint = "/tweets"
 	}
+	if c.UserEndpoint == "" {
+		c.UserEndpoint = "/users"
+	}
+	if c.SearchEndpoint == "" {
+		c.SearchEndpoint = "/tweets/search/recent"
+	}
+	if c.TimelineEndpoint == "" {
+		c.TimelineEndpoint = "/users/:id/tweets"
+	}
+	if c.MediaEndpoint == "" {
+		c.MediaEndpoint = "/media/upload"
+	}
+	if c.ListEndpoint == "" {
+		c.ListEndpoint = "/lists"
+	}
+
+	if c.OAuth2.Enabled() {
+		if c.OAuth2.RefreshBefore < 0 {
+			return fmt.Errorf("OAuth 2.0 refresh window cannot be negative")
+		}
+		if c.OAuth2.RefreshBefore == 0 {
+			c.OAuth2.RefreshBefore = 5 * time.Minute
+		}
+		if c.OAuth2.AuthorizeURL == "" {
+			c.OAuth2.AuthorizeURL = OAuth2AuthorizeURL
+		}
+		if c.OAuth2.TokenURL == "" {
+			c.OAuth2.TokenURL = c.BaseURL + "/oauth2/token"
+		}
+	}
 
 	c.Logger.Debug("Twitter configuration validation completed successfully")
 	return nil
 }
 
-// Helper function to get environment variable with default value
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
 // GetEndpoint returns the full URL for a given endpoint
 func (c *TwitterConfig) GetEndpoint(endpoint string) string {
 	fullURL := c.BaseURL + endpoint
@@ -206,13 +272,14 @@ func (c *TwitterConfig) GetExpansions() []string {
 	return c.ExpansionFields
 }
 
-// HasWriteAccess returns true if OAuth 1.0a credentials are configured
+// HasWriteAccess returns true if OAuth 1.0a credentials or an OAuth 2.0
+// client are configured
 func (c *TwitterConfig) HasWriteAccess() bool {
-	return c.ConsumerKey != "" && c.ConsumerSecret != "" &&
+	return c.OAuth2.Enabled() || c.ConsumerKey != "" && c.ConsumerSecret != "" &&
 		c.AccessToken != "" && c.AccessTokenSecret != ""
 }
 
-// HasReadAccess returns true if either OAuth 1.0a or Bearer token is configured
+// HasReadAccess returns true if OAuth 1.0a, OAuth 2.0 or Bearer token is configured
 func (c *TwitterConfig) HasReadAccess() bool {
 	return c.HasWriteAccess() || c.BearerToken != ""
 }
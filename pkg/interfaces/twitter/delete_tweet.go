@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,13 +18,22 @@ type DeleteTweetResponse struct {
 	} `json:"data"`
 }
 
-// deleteTweetHelper handles the common tweet deletion logic
+// deleteTweetHelper handles the common tweet deletion logic. In dry-run mode
+// the deletion is recorded and the tweet is left in place.
 func (c *TwitterClient) deleteTweetHelper(ctx context.Context, tweetID string) (bool, error) {
 	c.logger.WithFields(logrus.Fields{
 		"tweet_id": tweetID,
 		"method":   "DELETE",
 	}).Debug("attempting to delete tweet")
 
+	if c.dryRun != nil {
+		record := dryrun.Record{Kind: dryrun.KindDelete, Target: tweetID}
+		if err := c.dryRun.RecordDryRun(ctx, record); err != nil {
+			return false, fmt.Errorf("failed to record dry-run deletion: %w", err)
+		}
+		return true, nil
+	}
+
 	endpoint := fmt.Sprintf("%s/%s", c.config.TweetEndpoint, tweetID)
 
 	// Log before making request
@@ -0,0 +1,63 @@
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+)
+
+// SetDryRun makes the client hand every tweet it would post to recorder
+// instead of the API. Reads still go to the API, so the agent sees live data.
+// Call it before the client is shared between goroutines.
+func (c *TwitterClient) SetDryRun(recorder dryrun.Recorder) {
+	c.dryRun = recorder
+}
+
+// DryRun reports whether posts are recorded instead of published
+func (c *TwitterClient) DryRun() bool {
+	return c.dryRun != nil
+}
+
+// recordDryRunTweet records a tweet request and returns the tweet the API would
+// have created, with a synthetic ID so callers can proceed as if it was posted
+func (c *TwitterClient) recordDryRunTweet(ctx context.Context, request BaseTweetRequest) (*Tweet, error) {
+	record := dryrun.Record{
+		Kind:    dryrun.KindTweet,
+		Content: request.Text,
+		Details: map[string]interface{}{},
+	}
+	switch {
+	case request.ReplyTo != "":
+		record.Kind = dryrun.KindReply
+		record.Target = request.ReplyTo
+	case request.QuoteTweetID != "":
+		record.Kind = dryrun.KindQuote
+		record.Target = request.QuoteTweetID
+	}
+	if request.ConversationID != "" {
+		record.Details["conversation_id"] = request.ConversationID
+	}
+	if request.Media != nil {
+		record.Details["media_ids"] = request.Media.MediaIDs
+	}
+	if request.Poll != nil {
+		record.Details["poll"] = request.Poll
+	}
+
+	if err := c.dryRun.RecordDryRun(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to record dry-run tweet: %w", err)
+	}
+
+	tweet := &Tweet{
+		ID:             dryrun.NewTweetID(),
+		Text:           request.Text,
+		ConversationID: request.ConversationID,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+	if tweet.ConversationID == "" {
+		tweet.ConversationID = tweet.ID
+	}
+	return tweet, nil
+}
@@ -0,0 +1,110 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxUserIDRateLimitWait caps how long GetAuthenticatedUserID waits for a rate limit
+// reset when there is no configured user ID to fall back to
+const maxUserIDRateLimitWait = 15 * time.Minute
+
+// GetAuthenticatedUserID returns the ID of the account the client is authenticated as.
+// The ID is looked up once via GET /2/users/me and cached. If the lookup fails, the
+// configured user ID (TWITTER_USER_ID) is used instead; without one, rate-limited
+// lookups are retried after the limit resets.
+// Rate limit: 75/15m (user)
+func (c *TwitterClient) GetAuthenticatedUserID(ctx context.Context) (string, error) {
+	c.userIDMu.Lock()
+	defer c.userIDMu.Unlock()
+
+	if c.userID != "" {
+		return c.userID, nil
+	}
+
+	log := c.logger.WithField("method", "GetAuthenticatedUserID")
+
+	attempts := c.config.RetryAttempts + 1
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		user, err := c.getMe(ctx)
+		if err == nil {
+			log.WithFields(logrus.Fields{
+				"source":   "api",
+				"user_id":  user.ID,
+				"username": user.Username,
+			}).Debug("Resolved authenticated user")
+			c.userID = user.ID
+			return c.userID, nil
+		}
+		lastErr = err
+
+		if c.config.UserID != "" {
+			log.WithError(err).WithFields(logrus.Fields{
+				"source":  "config",
+				"user_id": c.config.UserID,
+			}).Warn("users/me lookup failed, using configured user ID")
+			c.userID = c.config.UserID
+			return c.userID, nil
+		}
+
+		var rateLimitErr *RateLimitError
+		if !errors.As(err, &rateLimitErr) || attempt == attempts {
+			break
+		}
+
+		wait := time.Until(rateLimitErr.Reset)
+		if wait > maxUserIDRateLimitWait {
+			break
+		}
+		log.WithFields(logrus.Fields{
+			"attempt":       attempt,
+			"wait_duration": wait.Round(time.Second),
+		}).Warn("users/me rate limited, waiting for reset")
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	return "", fmt.Errorf("failed to resolve authenticated user ID: %w", lastErr)
+}
+
+// CheckCredentials makes the cheapest authenticated call, GET /2/users/me,
+// without the cached user ID, and returns the account the credentials belong
+// to. Revoked or expired credentials fail with an *APIError of status 401, a
+// suspended or locked account with status 403.
+// Rate limit: 75/15m (user)
+func (c *TwitterClient) CheckCredentials(ctx context.Context) (*User, error) {
+	return c.getMe(ctx)
+}
+
+// getMe fetches the authenticated user's profile
+func (c *TwitterClient) getMe(ctx context.Context) (*User, error) {
+	resp, err := c.makeRequest(ctx, http.MethodGet, c.config.UserEndpoint+"/me", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var userResp UserResponse
+	if err := json.NewDecoder(resp.Body).Decode(&userResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(userResp.Errors) > 0 {
+		return nil, &userResp.Errors[0]
+	}
+	if userResp.Data == nil || userResp.Data.ID == "" {
+		return nil, fmt.Errorf("users/me returned no user")
+	}
+
+	return userResp.Data, nil
+}
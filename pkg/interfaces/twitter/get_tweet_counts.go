@@ -0,0 +1,80 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Granularity values accepted by the tweet counts endpoint
+const (
+	GranularityMinute = "minute"
+	GranularityHour   = "hour"
+	GranularityDay    = "day"
+)
+
+// TimeRange bounds a tweet counts query. Zero times are omitted, in which case
+// the API counts the last seven days up to now.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// GetTweetCounts returns the number of recent tweets matching a search query,
+// bucketed by granularity (minute, hour or day; empty defaults to hour).
+// Rate limit: 300/15m (app)
+func (c *TwitterClient) GetTweetCounts(ctx context.Context, query, granularity string, timeRange TimeRange) (*TweetCountsResponse, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	switch granularity {
+	case "", GranularityMinute, GranularityHour, GranularityDay:
+	default:
+		return nil, fmt.Errorf("invalid granularity %q", granularity)
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"method":      "GetTweetCounts",
+		"query":       query,
+		"granularity": granularity,
+	})
+
+	endpoint := c.config.TweetEndpoint + "/counts/recent"
+	queryParams := map[string]string{
+		"query": query,
+	}
+	if granularity != "" {
+		queryParams["granularity"] = granularity
+	}
+	if !timeRange.Start.IsZero() {
+		queryParams["start_time"] = timeRange.Start.UTC().Format(time.RFC3339)
+	}
+	if !timeRange.End.IsZero() {
+		queryParams["end_time"] = timeRange.End.UTC().Format(time.RFC3339)
+	}
+
+	resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch tweet counts")
+		return nil, fmt.Errorf("failed to fetch tweet counts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var countsResp TweetCountsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&countsResp); err != nil {
+		log.WithError(err).Error("Failed to decode response")
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(countsResp.Errors) > 0 {
+		return nil, &countsResp.Errors[0]
+	}
+
+	log.WithField("buckets", len(countsResp.Data)).Debug("Retrieved tweet counts")
+
+	return &countsResp, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -19,8 +20,8 @@ type GetTweetsParams struct {
 
 // GetTweets retrieves information about specific tweets by their IDs
 // Rate limit: 300/15m (app), 900/15m (user)
-func (c *TwitterClient) GetTweets(ctx context.Context, params GetTweetsParams) (chan *TweetResponse, chan error) {
-	dataChan := make(chan *TweetResponse)
+func (c *TwitterClient) GetTweets(ctx context.Context, params GetTweetsParams) (chan *TweetsResponse, chan error) {
+	dataChan := make(chan *TweetsResponse)
 	errChan := make(chan error)
 
 	go func() {
@@ -41,24 +42,21 @@ func (c *TwitterClient) GetTweets(ctx context.Context, params GetTweetsParams) (
 				errChan <- ctx.Err()
 				return
 			default:
-				// Prepare query parameters
-				body := map[string]interface{}{
-					"ids":              strings.Join(params.TweetIDs, ","),
-					"pagination_token": params.PaginationToken,
-					"max_results":      params.MaxResults,
-					"tweet.fields": strings.Join(append(
-						c.config.GetTweetFields(),
-						"conversation_id",
-					), ","),
-					"expansions": c.config.GetExpansions(),
+				queryParams := c.tweetQueryParams([]string{"conversation_id"}, nil)
+				queryParams["ids"] = strings.Join(params.TweetIDs, ",")
+				if params.PaginationToken != "" {
+					queryParams["pagination_token"] = params.PaginationToken
+				}
+				if params.MaxResults > 0 {
+					queryParams["max_results"] = strconv.Itoa(params.MaxResults)
 				}
 
 				log.WithFields(logrus.Fields{
 					"endpoint": endpoint,
-					"params":   body,
+					"params":   queryParams,
 				}).Debug("Fetching tweets")
 
-				resp, err := c.makeRequest(ctx, http.MethodGet, endpoint, body)
+				resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
 				if err != nil {
 					log.WithError(err).Error("Failed to fetch tweets")
 					errChan <- fmt.Errorf("failed to fetch tweets: %w", err)
@@ -66,7 +64,7 @@ func (c *TwitterClient) GetTweets(ctx context.Context, params GetTweetsParams) (
 				}
 				defer resp.Body.Close()
 
-				var tweetResp TweetResponse
+				var tweetResp TweetsResponse
 				if err := json.NewDecoder(resp.Body).Decode(&tweetResp); err != nil {
 					log.WithError(err).Error("Failed to decode response")
 					errChan <- fmt.Errorf("failed to decode response: %w", err)
@@ -74,16 +72,13 @@ func (c *TwitterClient) GetTweets(ctx context.Context, params GetTweetsParams) (
 				}
 
 				// Log the response details
-				log.WithFields(logrus.Fields{
-					"tweet_received": tweetResp.Data != nil,
-					"result_count":   tweetResp.Meta.ResultCount,
-				}).Debug("Received tweets response")
+				log.WithField("tweets_received", len(tweetResp.Data)).Debug("Received tweets response")
 
 				// Send the response to the data channel
 				dataChan <- &tweetResp
 
 				// Check if we have more pages
-				if tweetResp.Meta.NextToken == "" {
+				if tweetResp.Meta == nil || tweetResp.Meta.NextToken == "" {
 					log.Debug("No more pages to fetch")
 					return
 				}
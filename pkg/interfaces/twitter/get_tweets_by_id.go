@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/sirupsen/logrus"
 )
@@ -37,27 +36,17 @@ func (c *TwitterClient) GetTweetByID(ctx context.Context, params GetTweetByIDPar
 			errChan <- ctx.Err()
 			return
 		default:
-			// Prepare query parameters
-			body := map[string]interface{}{
-				"tweet.fields": strings.Join(append(
-					c.config.GetTweetFields(),
-					"conversation_id",
-					"in_reply_to_user_id",
-					"referenced_tweets",
-				), ","),
-				"expansions": strings.Join(append(
-					c.config.GetExpansions(),
-					"referenced_tweets.id",
-					"in_reply_to_user_id",
-				), ","),
-			}
+			queryParams := c.tweetQueryParams(
+				[]string{"conversation_id", "in_reply_to_user_id", "referenced_tweets"},
+				[]string{"referenced_tweets.id", "in_reply_to_user_id"},
+			)
 
 			log.WithFields(logrus.Fields{
 				"endpoint": endpoint,
-				"params":   body,
+				"params":   queryParams,
 			}).Debug("Fetching tweet")
 
-			resp, err := c.makeRequest(ctx, http.MethodGet, endpoint, body)
+			resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
 			if err != nil {
 				log.WithError(err).Error("Failed to fetch tweet")
 				errChan <- fmt.Errorf("failed to fetch tweet: %w", err)
@@ -0,0 +1,61 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UserResponse represents the response from the user lookup endpoints
+type UserResponse struct {
+	Data     *User          `json:"data"`
+	Includes *TweetIncludes `json:"includes,omitempty"`
+	Errors   []TwitterError `json:"errors,omitempty"`
+}
+
+// GetUserByUsername retrieves a user's public profile by their @handle
+// Rate limit: 300/15m (app), 900/15m (user)
+func (c *TwitterClient) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"method":   "GetUserByUsername",
+		"username": username,
+	})
+
+	endpoint := fmt.Sprintf("%s/by/username/%s", c.config.UserEndpoint, username)
+	queryParams := map[string]string{
+		"user.fields": "created_at,description,location,public_metrics,verified,protected",
+	}
+
+	resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch user")
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var userResp UserResponse
+	if err := json.NewDecoder(resp.Body).Decode(&userResp); err != nil {
+		log.WithError(err).Error("Failed to decode response")
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(userResp.Errors) > 0 {
+		return nil, &userResp.Errors[0]
+	}
+	if userResp.Data == nil {
+		return nil, fmt.Errorf("user not found: %s", username)
+	}
+
+	log.WithField("user_id", userResp.Data.ID).Debug("Retrieved user")
+
+	return userResp.Data, nil
+}
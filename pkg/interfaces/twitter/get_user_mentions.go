@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -28,34 +27,6 @@ type GetUserMentionsParams struct {
 	PollFields      []string `json:"poll.fields,omitempty"`
 }
 
-// GetConversationID returns the conversation ID from the first tweet in the response
-func (mr *MentionResponse) GetConversationID() string {
-	if mr == nil || len(mr.Data) == 0 {
-		return ""
-	}
-	return mr.Data[0].ConversationID
-}
-
-// Convert MentionResponse to TweetResponse for compatibility
-func (mr *MentionResponse) ToTweetResponse() (*TweetResponse, error) {
-	if mr == nil {
-		return nil, nil
-	}
-
-	// Convert the tweets array to json.RawMessage
-	rawData, err := json.Marshal(mr.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal tweets: %w", err)
-	}
-
-	return &TweetResponse{
-		Data:     json.RawMessage(rawData),
-		Includes: mr.Includes,
-		Errors:   mr.Errors,
-		Meta:     mr.Meta,
-	}, nil
-}
-
 // GetUserMentions retrieves tweets mentioning a specific user
 func (c *TwitterClient) GetUserMentions(ctx context.Context, params GetUserMentionsParams) (<-chan *MentionResponse, <-chan error) {
 	dataChan := make(chan *MentionResponse)
@@ -65,18 +36,17 @@ func (c *TwitterClient) GetUserMentions(ctx context.Context, params GetUserMenti
 		defer close(dataChan)
 		defer close(errChan)
 
-		// Get user ID from environment if not provided
+		// Fall back to the configured user ID if not provided
 		if params.UserID == "" {
-			envUserID := os.Getenv("TWITTER_USER_ID")
-			if envUserID == "" {
-				errChan <- fmt.Errorf("TWITTER_USER_ID environment variable not set")
+			if c.config.UserID == "" {
+				errChan <- fmt.Errorf("twitter user ID not configured")
 				return
 			}
-			params.UserID = envUserID
+			params.UserID = c.config.UserID
 			c.logger.WithFields(logrus.Fields{
-				"source":  "env",
-				"user_id": envUserID,
-			}).Debug("Using configured Twitter user ID from environment")
+				"source":  "config",
+				"user_id": params.UserID,
+			}).Debug("Using configured Twitter user ID")
 		}
 
 		// Validate MaxResults
@@ -127,12 +97,30 @@ func (c *TwitterClient) GetUserMentions(ctx context.Context, params GetUserMenti
 		}
 		queryParams["expansions"] = strings.Join(expansions, ",")
 
+		if len(params.UserFields) > 0 {
+			queryParams["user.fields"] = strings.Join(params.UserFields, ",")
+		}
+
+		// Window and paging parameters
+		for name, value := range map[string]string{
+			"since_id":         params.SinceID,
+			"until_id":         params.UntilID,
+			"start_time":       params.StartTime,
+			"end_time":         params.EndTime,
+			"pagination_token": params.PaginationToken,
+		} {
+			if value != "" {
+				queryParams[name] = value
+			}
+		}
+
 		// Log the final query parameters
 		c.logger.WithFields(logrus.Fields{
 			"endpoint":     fmt.Sprintf("/users/%s/mentions", params.UserID),
 			"tweet_fields": queryParams["tweet.fields"],
 			"expansions":   queryParams["expansions"],
 			"max_results":  queryParams["max_results"],
+			"since_id":     params.SinceID,
 		}).Debug("Final API request parameters")
 
 		endpoint := fmt.Sprintf("/users/%s/mentions", params.UserID)
@@ -168,18 +156,3 @@ func (c *TwitterClient) GetUserMentions(ctx context.Context, params GetUserMenti
 
 	return dataChan, errChan
 }
-
-// GetAuthenticatedUserID retrieves the authenticated user's ID from environment
-func (c *TwitterClient) GetAuthenticatedUserID(ctx context.Context) (string, error) {
-	userID := os.Getenv("TWITTER_USER_ID")
-	if userID == "" {
-		return "", fmt.Errorf("TWITTER_USER_ID environment variable not set")
-	}
-
-	c.logger.WithFields(logrus.Fields{
-		"source":  "env",
-		"user_id": userID,
-	}).Debug("Using configured Twitter user ID from environment")
-
-	return userID, nil
-}
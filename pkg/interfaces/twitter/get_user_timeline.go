@@ -0,0 +1,133 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Timeline exclusions accepted by GetUserTimelineParams.Exclude
+const (
+	ExcludeRetweets = "retweets"
+	ExcludeReplies  = "replies"
+)
+
+const (
+	// minTimelinePage and maxTimelinePage bound max_results of a timeline request
+	minTimelinePage = 5
+	maxTimelinePage = 100
+)
+
+// GetUserTimelineParams holds the parameters for the GetUserTimeline request
+type GetUserTimelineParams struct {
+	// MaxTweets is how many tweets to collect across pages; 0 fetches one
+	// full page
+	MaxTweets int
+	// SinceID and UntilID only return tweets newer or older than the given IDs
+	SinceID string
+	UntilID string
+	// StartTime and EndTime bound the tweets by creation time, in RFC3339
+	StartTime string
+	EndTime   string
+	// Exclude leaves out ExcludeRetweets and/or ExcludeReplies
+	Exclude []string
+	// PaginationToken resumes from a page returned by an earlier call
+	PaginationToken string
+}
+
+// GetUserTimeline returns the most recent tweets of a user, newest first,
+// following pages until params.MaxTweets tweets are collected or the
+// timeline ends.
+// Rate limit: 1500/15m (app), 900/15m (user)
+func (c *TwitterClient) GetUserTimeline(ctx context.Context, userID string, params GetUserTimelineParams) ([]Tweet, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID is required")
+	}
+	for _, exclude := range params.Exclude {
+		if exclude != ExcludeRetweets && exclude != ExcludeReplies {
+			return nil, fmt.Errorf("invalid timeline exclusion: %s", exclude)
+		}
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"method":     "GetUserTimeline",
+		"user_id":    userID,
+		"max_tweets": params.MaxTweets,
+		"exclude":    params.Exclude,
+	})
+
+	endpoint := strings.Replace(c.config.TimelineEndpoint, ":id", userID, 1)
+
+	var tweets []Tweet
+	token := params.PaginationToken
+	for {
+		pageSize := maxTimelinePage
+		if params.MaxTweets > 0 {
+			pageSize = params.MaxTweets - len(tweets)
+		}
+		if pageSize < minTimelinePage {
+			pageSize = minTimelinePage
+		}
+		if pageSize > maxTimelinePage {
+			pageSize = maxTimelinePage
+		}
+
+		queryParams := c.tweetQueryParams(
+			[]string{"conversation_id", "created_at", "public_metrics", "referenced_tweets"},
+			nil,
+		)
+		queryParams["max_results"] = strconv.Itoa(pageSize)
+		if len(params.Exclude) > 0 {
+			queryParams["exclude"] = joinUnique(params.Exclude)
+		}
+		for key, value := range map[string]string{
+			"since_id":         params.SinceID,
+			"until_id":         params.UntilID,
+			"start_time":       params.StartTime,
+			"end_time":         params.EndTime,
+			"pagination_token": token,
+		} {
+			if value != "" {
+				queryParams[key] = value
+			}
+		}
+
+		log.WithField("params", queryParams).Debug("Fetching user timeline")
+
+		resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
+		if err != nil {
+			log.WithError(err).Error("Failed to fetch user timeline")
+			return nil, fmt.Errorf("failed to fetch user timeline: %w", err)
+		}
+
+		var page TweetsResponse
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			log.WithError(err).Error("Failed to decode response")
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(page.Data) == 0 && len(page.Errors) > 0 {
+			return nil, &page.Errors[0]
+		}
+
+		tweets = append(tweets, page.Data...)
+		if params.MaxTweets > 0 && len(tweets) >= params.MaxTweets {
+			tweets = tweets[:params.MaxTweets]
+			break
+		}
+		if params.MaxTweets <= 0 || page.Meta == nil || page.Meta.NextToken == "" {
+			break
+		}
+		token = page.Meta.NextToken
+	}
+
+	log.WithField("found", len(tweets)).Debug("Retrieved user timeline")
+
+	return tweets, nil
+}
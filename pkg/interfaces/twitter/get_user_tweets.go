@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/sirupsen/logrus"
 )
@@ -19,8 +19,8 @@ type GetUserTweetsParams struct {
 
 // GetUserTweets retrieves tweets posted by a specific user
 // Rate limit: 1500/15m (app), 900/15m (user)
-func (c *TwitterClient) GetUserTweets(ctx context.Context, params GetUserTweetsParams) (chan *TweetResponse, chan error) {
-	dataChan := make(chan *TweetResponse)
+func (c *TwitterClient) GetUserTweets(ctx context.Context, params GetUserTweetsParams) (chan *TweetsResponse, chan error) {
+	dataChan := make(chan *TweetsResponse)
 	errChan := make(chan error)
 
 	go func() {
@@ -32,11 +32,7 @@ func (c *TwitterClient) GetUserTweets(ctx context.Context, params GetUserTweetsP
 			"userID": params.UserID,
 		})
 
-		endpoint := fmt.Sprintf("%s/%s/%s/tweets",
-			c.config.BaseURL,
-			c.config.UserEndpoint,
-			params.UserID,
-		)
+		endpoint := fmt.Sprintf("%s/%s/tweets", c.config.UserEndpoint, params.UserID)
 
 		for {
 			select {
@@ -44,29 +40,23 @@ func (c *TwitterClient) GetUserTweets(ctx context.Context, params GetUserTweetsP
 				errChan <- ctx.Err()
 				return
 			default:
-				// Prepare query parameters
-				body := map[string]interface{}{
-					"pagination_token": params.PaginationToken,
-					"max_results":      params.MaxResults,
-					"tweet.fields": strings.Join(append(
-						c.config.GetTweetFields(),
-						"conversation_id",
-						"in_reply_to_user_id",
-						"referenced_tweets",
-					), ","),
-					"expansions": strings.Join(append(
-						c.config.GetExpansions(),
-						"referenced_tweets.id",
-						"in_reply_to_user_id",
-					), ","),
+				queryParams := c.tweetQueryParams(
+					[]string{"conversation_id", "in_reply_to_user_id", "referenced_tweets"},
+					[]string{"referenced_tweets.id", "in_reply_to_user_id"},
+				)
+				if params.PaginationToken != "" {
+					queryParams["pagination_token"] = params.PaginationToken
+				}
+				if params.MaxResults > 0 {
+					queryParams["max_results"] = strconv.Itoa(params.MaxResults)
 				}
 
 				log.WithFields(logrus.Fields{
 					"endpoint": endpoint,
-					"params":   body,
+					"params":   queryParams,
 				}).Debug("Fetching user tweets")
 
-				resp, err := c.makeRequest(ctx, http.MethodGet, endpoint, body)
+				resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
 				if err != nil {
 					log.WithError(err).Error("Failed to fetch user tweets")
 					errChan <- fmt.Errorf("failed to fetch user tweets: %w", err)
@@ -74,7 +64,7 @@ func (c *TwitterClient) GetUserTweets(ctx context.Context, params GetUserTweetsP
 				}
 				defer resp.Body.Close()
 
-				var tweetResp TweetResponse
+				var tweetResp TweetsResponse
 				if err := json.NewDecoder(resp.Body).Decode(&tweetResp); err != nil {
 					log.WithError(err).Error("Failed to decode response")
 					errChan <- fmt.Errorf("failed to decode response: %w", err)
@@ -85,7 +75,7 @@ func (c *TwitterClient) GetUserTweets(ctx context.Context, params GetUserTweetsP
 				dataChan <- &tweetResp
 
 				// Check if we have more pages
-				if tweetResp.Meta.NextToken == "" {
+				if tweetResp.Meta == nil || tweetResp.Meta.NextToken == "" {
 					log.Debug("No more pages to fetch")
 					return
 				}
@@ -0,0 +1,184 @@
+package twitter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxRetryBackoff caps the wait between two attempts of a request
+const maxRetryBackoff = 10 * time.Second
+
+// NewHTTPClient builds the HTTP client every request of a Twitter client goes
+// through: pooled keep-alive connections, the configured proxy or the one
+// from HTTPS_PROXY, and a 30 second timeout per request
+func NewHTTPClient(config *TwitterConfig) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          config.MaxIdleConnsPerHost * 2,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	}, nil
+}
+
+// withRetries returns a copy of client that retries transient failures
+func withRetries(client *http.Client, attempts int, backoff time.Duration, logger *logrus.Logger) *http.Client {
+	if attempts <= 0 {
+		return client
+	}
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	retrying := *client
+	retrying.Transport = &retryTransport{
+		next:     next,
+		attempts: attempts,
+		backoff:  backoff,
+		logger:   logger,
+	}
+	return &retrying
+}
+
+// retryTransport retries requests that failed for reasons unrelated to the
+// request itself: a 5xx response or a reset, refused or timed out connection.
+// POST requests may already have taken effect after such a failure, so they
+// are only retried when the connection could not be made or the API answered
+// 503 Service Unavailable. It wraps the OAuth transport, so every attempt is
+// signed afresh.
+type retryTransport struct {
+	next     http.RoundTripper
+	attempts int
+	backoff  time.Duration
+	logger   *logrus.Logger
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Keep the body so it can be sent again
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req.Clone(req.Context())
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt >= t.attempts || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := jitteredBackoff(t.backoff, attempt)
+		log := t.logger.WithFields(logrus.Fields{
+			"method":  req.Method,
+			"url":     req.URL.Redacted(),
+			"attempt": attempt + 1,
+			"wait":    wait,
+		})
+		if err != nil {
+			log = log.WithError(err)
+		} else {
+			log = log.WithField("status_code", resp.StatusCode)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		log.Warn("Retrying Twitter request after transient failure")
+
+		if err := sleepRequest(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether a failed attempt may be repeated
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	idempotent := req.Method != http.MethodPost && req.Method != http.MethodPatch
+
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		if !idempotent {
+			return false
+		}
+		var netErr net.Error
+		return errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, io.EOF) ||
+			(errors.As(err, &netErr) && netErr.Timeout())
+	}
+
+	switch resp.StatusCode {
+	case http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// jitteredBackoff doubles base with every attempt, up to maxRetryBackoff,
+// and spreads it by ±50% so clients do not retry in lockstep
+func jitteredBackoff(base time.Duration, attempt int) time.Duration {
+	wait := base << attempt
+	if wait <= 0 || wait > maxRetryBackoff {
+		wait = maxRetryBackoff
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait)+1))
+}
+
+// sleepRequest waits for d unless the request is cancelled first
+func sleepRequest(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,195 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateListParams holds the parameters for the CreateList request
+type CreateListParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Private     bool   `json:"private"`
+}
+
+// OwnedListsResponse represents the response from the owned lists endpoint
+type OwnedListsResponse struct {
+	Data   []List         `json:"data"`
+	Meta   *Meta          `json:"meta,omitempty"`
+	Errors []TwitterError `json:"errors,omitempty"`
+}
+
+// GetListTweetsParams holds the parameters for the GetListTweets request
+type GetListTweetsParams struct {
+	// MaxResults is the page size, between 1 and 100
+	MaxResults      int
+	PaginationToken string
+}
+
+// CreateList creates a list owned by the authenticated user
+// Rate limit: 300/15m (user)
+func (c *TwitterClient) CreateList(ctx context.Context, params CreateListParams) (*List, error) {
+	if params.Name == "" {
+		return nil, fmt.Errorf("list name is required")
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"method": "CreateList",
+		"name":   params.Name,
+	})
+
+	if c.dryRun != nil {
+		log.Info("Dry run: skipping list creation")
+		return &List{ID: dryrun.NewTweetID(), Name: params.Name, Description: params.Description, Private: params.Private}, nil
+	}
+
+	resp, err := c.makeRequest(ctx, http.MethodPost, c.config.ListEndpoint, params)
+	if err != nil {
+		log.WithError(err).Error("Failed to create list")
+		return nil, fmt.Errorf("failed to create list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var listResp ListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if listResp.Error != nil {
+		return nil, listResp.Error
+	}
+	if listResp.Data == nil || listResp.Data.ID == "" {
+		return nil, fmt.Errorf("list not created: %s", params.Name)
+	}
+
+	list := listResp.Data
+	if list.Name == "" {
+		list.Name = params.Name
+	}
+	log.WithField("list_id", list.ID).Info("Created list")
+	return list, nil
+}
+
+// GetOwnedLists returns the lists a user owns, following every page
+// Rate limit: 15/15m (app), 15/15m (user)
+func (c *TwitterClient) GetOwnedLists(ctx context.Context, userID string) ([]List, error) {
+	log := c.logger.WithFields(logrus.Fields{
+		"method":  "GetOwnedLists",
+		"user_id": userID,
+	})
+
+	endpoint := fmt.Sprintf("%s/%s/owned_lists", c.config.UserEndpoint, userID)
+	queryParams := map[string]string{
+		"list.fields": "description,member_count,private,owner_id",
+		"max_results": "100",
+	}
+
+	var lists []List
+	for {
+		resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
+		if err != nil {
+			log.WithError(err).Error("Failed to fetch owned lists")
+			return nil, fmt.Errorf("failed to fetch owned lists: %w", err)
+		}
+
+		var page OwnedListsResponse
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(page.Data) == 0 && len(page.Errors) > 0 {
+			return nil, &page.Errors[0]
+		}
+
+		lists = append(lists, page.Data...)
+		if page.Meta == nil || page.Meta.NextToken == "" {
+			break
+		}
+		queryParams["pagination_token"] = page.Meta.NextToken
+	}
+
+	log.WithField("found", len(lists)).Debug("Retrieved owned lists")
+	return lists, nil
+}
+
+// AddListMember adds a user to a list the authenticated user owns and reports
+// whether the user is now a member
+// Rate limit: 300/15m (user)
+func (c *TwitterClient) AddListMember(ctx context.Context, listID, userID string) (bool, error) {
+	log := c.logger.WithFields(logrus.Fields{
+		"method":  "AddListMember",
+		"list_id": listID,
+		"user_id": userID,
+	})
+
+	if c.dryRun != nil {
+		log.Info("Dry run: skipping list member")
+		return true, nil
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/members", c.config.ListEndpoint, listID)
+	resp, err := c.makeRequest(ctx, http.MethodPost, endpoint, map[string]string{"user_id": userID})
+	if err != nil {
+		log.WithError(err).Error("Failed to add list member")
+		return false, fmt.Errorf("failed to add list member: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var memberResp struct {
+		Data struct {
+			IsMember bool `json:"is_member"`
+		} `json:"data"`
+		Errors []TwitterError `json:"errors,omitempty"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&memberResp); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(memberResp.Errors) > 0 {
+		return false, &memberResp.Errors[0]
+	}
+
+	log.WithField("is_member", memberResp.Data.IsMember).Debug("Added list member")
+	return memberResp.Data.IsMember, nil
+}
+
+// GetListTweets returns one page of the most recent tweets of a list's members
+// Rate limit: 900/15m (app), 900/15m (user)
+func (c *TwitterClient) GetListTweets(ctx context.Context, listID string, params GetListTweetsParams) (*TweetsResponse, error) {
+	log := c.logger.WithFields(logrus.Fields{
+		"method":  "GetListTweets",
+		"list_id": listID,
+	})
+
+	endpoint := fmt.Sprintf("%s/%s/tweets", c.config.ListEndpoint, listID)
+	queryParams := c.tweetQueryParams([]string{"conversation_id", "created_at", "public_metrics"}, nil)
+	if params.MaxResults > 0 {
+		queryParams["max_results"] = strconv.Itoa(params.MaxResults)
+	}
+	if params.PaginationToken != "" {
+		queryParams["pagination_token"] = params.PaginationToken
+	}
+
+	resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch list tweets")
+		return nil, fmt.Errorf("failed to fetch list tweets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tweetsResp TweetsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tweetsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(tweetsResp.Data) == 0 && len(tweetsResp.Errors) > 0 {
+		return nil, &tweetsResp.Errors[0]
+	}
+
+	log.WithField("tweets_found", len(tweetsResp.Data)).Debug("Retrieved list tweets")
+	return &tweetsResp, nil
+}
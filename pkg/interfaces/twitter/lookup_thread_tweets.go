@@ -0,0 +1,63 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LookupThreadTweets fetches tweets by ID, batching requests of 100 IDs, with
+// the fields needed to follow a thread upwards: author, conversation, reply
+// references and creation time. Authors are returned in the includes. Tweets
+// that were deleted or are not visible are left out of the result.
+// Rate limit: 300/15m (app), 900/15m (user)
+func (c *TwitterClient) LookupThreadTweets(ctx context.Context, ids []string) (*TweetsResponse, error) {
+	log := c.logger.WithFields(logrus.Fields{
+		"method":    "LookupThreadTweets",
+		"num_ids":   len(ids),
+		"num_batch": (len(ids) + maxLookupIDs - 1) / maxLookupIDs,
+	})
+
+	result := &TweetsResponse{Includes: &TweetIncludes{}}
+	for start := 0; start < len(ids); start += maxLookupIDs {
+		end := start + maxLookupIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		resp, err := c.makeRequestWithParams(ctx, http.MethodGet, c.config.TweetEndpoint, map[string]string{
+			"ids":          strings.Join(ids[start:end], ","),
+			"tweet.fields": "id,text,created_at,conversation_id,in_reply_to_user_id,referenced_tweets,author_id,lang",
+			"expansions":   "author_id",
+			"user.fields":  "public_metrics,verified",
+		})
+		if err != nil {
+			log.WithError(err).Error("Failed to look up thread tweets")
+			return nil, fmt.Errorf("failed to look up thread tweets: %w", err)
+		}
+
+		var page TweetsResponse
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			log.WithError(err).Error("Failed to decode response")
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if len(page.Errors) > 0 {
+			log.WithField("errors", len(page.Errors)).Debug("Some thread tweets could not be looked up")
+		}
+		result.Data = append(result.Data, page.Data...)
+		if page.Includes != nil {
+			result.Includes.Users = append(result.Includes.Users, page.Includes.Users...)
+		}
+	}
+
+	log.WithField("found", len(result.Data)).Debug("Looked up thread tweets")
+
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxLookupIDs is the most tweet IDs the lookup endpoint accepts per request
+const maxLookupIDs = 100
+
+// LookupTweets fetches tweets by ID, batching requests of 100 IDs,
+// with public_metrics and created_at included. Tweets that were deleted or are
+// not visible are left out of the result rather than failing the lookup.
+// Rate limit: 300/15m (app), 900/15m (user)
+func (c *TwitterClient) LookupTweets(ctx context.Context, ids []string) ([]Tweet, error) {
+	log := c.logger.WithFields(logrus.Fields{
+		"method":    "LookupTweets",
+		"num_ids":   len(ids),
+		"num_batch": (len(ids) + maxLookupIDs - 1) / maxLookupIDs,
+	})
+
+	fields := strings.Join(c.config.GetTweetFields("public_metrics", "created_at", "conversation_id"), ",")
+
+	var tweets []Tweet
+	for start := 0; start < len(ids); start += maxLookupIDs {
+		end := start + maxLookupIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		resp, err := c.makeRequestWithParams(ctx, http.MethodGet, c.config.TweetEndpoint, map[string]string{
+			"ids":          strings.Join(ids[start:end], ","),
+			"tweet.fields": fields,
+		})
+		if err != nil {
+			log.WithError(err).Error("Failed to look up tweets")
+			return nil, fmt.Errorf("failed to look up tweets: %w", err)
+		}
+
+		var lookupResp struct {
+			Data   []Tweet        `json:"data"`
+			Errors []TwitterError `json:"errors,omitempty"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&lookupResp)
+		resp.Body.Close()
+		if err != nil {
+			log.WithError(err).Error("Failed to decode response")
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if len(lookupResp.Errors) > 0 {
+			log.WithField("errors", len(lookupResp.Errors)).Debug("Some tweets could not be looked up")
+		}
+		tweets = append(tweets, lookupResp.Data...)
+	}
+
+	log.WithField("found", len(tweets)).Debug("Looked up tweets")
+
+	return tweets, nil
+}
@@ -0,0 +1,60 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LookupUsers fetches users by ID, batching requests of 100 IDs, with their
+// public metrics and verification. Suspended or deleted users are left out of
+// the result rather than failing the lookup.
+// Rate limit: 300/15m (app), 900/15m (user)
+func (c *TwitterClient) LookupUsers(ctx context.Context, ids []string) ([]User, error) {
+	log := c.logger.WithFields(logrus.Fields{
+		"method":    "LookupUsers",
+		"num_ids":   len(ids),
+		"num_batch": (len(ids) + maxLookupIDs - 1) / maxLookupIDs,
+	})
+
+	var users []User
+	for start := 0; start < len(ids); start += maxLookupIDs {
+		end := start + maxLookupIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		resp, err := c.makeRequestWithParams(ctx, http.MethodGet, c.config.UserEndpoint, map[string]string{
+			"ids":         strings.Join(ids[start:end], ","),
+			"user.fields": "created_at,description,public_metrics,verified,protected",
+		})
+		if err != nil {
+			log.WithError(err).Error("Failed to look up users")
+			return nil, fmt.Errorf("failed to look up users: %w", err)
+		}
+
+		var lookupResp struct {
+			Data   []User         `json:"data"`
+			Errors []TwitterError `json:"errors,omitempty"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&lookupResp)
+		resp.Body.Close()
+		if err != nil {
+			log.WithError(err).Error("Failed to decode response")
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if len(lookupResp.Errors) > 0 {
+			log.WithField("errors", len(lookupResp.Errors)).Debug("Some users could not be looked up")
+		}
+		users = append(users, lookupResp.Data...)
+	}
+
+	log.WithField("found", len(users)).Debug("Looked up users")
+
+	return users, nil
+}
@@ -0,0 +1,366 @@
+package twitter
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OAuth2AuthorizeURL is the page the account owner grants the app access on
+const OAuth2AuthorizeURL = "https://twitter.com/i/oauth2/authorize"
+
+// OAuth 2.0 scopes checked by the client
+const (
+	// ScopeOfflineAccess grants the refresh token that keeps the agent
+	// authorized after the first access token expires
+	ScopeOfflineAccess = "offline.access"
+	ScopeBookmarkWrite = "bookmark.write"
+)
+
+// DefaultOAuth2Scopes are requested when no scopes are configured
+var DefaultOAuth2Scopes = []string{
+	"tweet.read",
+	"tweet.write",
+	"users.read",
+	"media.write",
+	"bookmark.read",
+	ScopeBookmarkWrite,
+	ScopeOfflineAccess,
+}
+
+// Errors of the OAuth 2.0 user context flow
+var (
+	// ErrOAuth2NotAuthorized means no token is stored for the account yet
+	ErrOAuth2NotAuthorized = errors.New("account has not authorized the app; run the oauth2 login command")
+	// ErrOAuth2Expired means the token expired and could not be refreshed
+	ErrOAuth2Expired = errors.New("OAuth 2.0 token expired")
+	// ErrOAuth2Scopes means the token does not grant a scope the agent needs
+	ErrOAuth2Scopes = errors.New("OAuth 2.0 token lacks required scopes")
+)
+
+// OAuth2Settings configures the OAuth 2.0 Authorization Code flow with PKCE.
+// When ClientID is set, requests are made in the user context of the account
+// that authorized the app instead of with OAuth 1.0a or the app's bearer
+// token.
+type OAuth2Settings struct {
+	ClientID string
+	// ClientSecret is only set for confidential clients
+	ClientSecret string
+	RedirectURL  string
+	// Scopes requested on login and required of stored tokens
+	Scopes []string
+	// RefreshBefore is how long before it expires the access token is
+	// refreshed
+	RefreshBefore time.Duration
+	AuthorizeURL  string
+	TokenURL      string
+	// Tokens keeps the account's tokens between runs
+	Tokens OAuth2TokenStore
+}
+
+// Enabled reports whether the OAuth 2.0 user context is configured
+func (s OAuth2Settings) Enabled() bool {
+	return s.ClientID != ""
+}
+
+// OAuth2Token is an access token and the refresh token that renews it
+type OAuth2Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+	Scopes       []string
+}
+
+// OAuth2TokenStore keeps the OAuth 2.0 token of one account. Refresh tokens
+// are single use, so every refreshed token is saved before it is used.
+type OAuth2TokenStore interface {
+	// LoadOAuth2Token returns the stored token, or nil when there is none
+	LoadOAuth2Token(ctx context.Context) (*OAuth2Token, error)
+	SaveOAuth2Token(ctx context.Context, token OAuth2Token) error
+}
+
+// WithOAuth2Tokens makes requests in the OAuth 2.0 user context, with the
+// tokens kept in store
+func WithOAuth2Tokens(store OAuth2TokenStore) ClientOption {
+	return func(c *TwitterClient) {
+		c.config.OAuth2.Tokens = store
+	}
+}
+
+// PKCE is the proof key of one authorization request (RFC 7636)
+type PKCE struct {
+	Verifier  string
+	Challenge string
+}
+
+// NewPKCE generates a random code verifier and its S256 challenge
+func NewPKCE() (PKCE, error) {
+	verifier, err := randomToken(32)
+	if err != nil {
+		return PKCE{}, err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// NewOAuth2State generates the state that ties a redirect to its request
+func NewOAuth2State() (string, error) {
+	return randomToken(16)
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns the URL the account owner opens to authorize the app
+func (s OAuth2Settings) AuthCodeURL(state string, pkce PKCE) string {
+	authorizeURL := s.AuthorizeURL
+	if authorizeURL == "" {
+		authorizeURL = OAuth2AuthorizeURL
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.ClientID},
+		"redirect_uri":          {s.RedirectURL},
+		"scope":                 {strings.Join(s.RequestedScopes(), " ")},
+		"state":                 {state},
+		"code_challenge":        {pkce.Challenge},
+		"code_challenge_method": {"S256"},
+	}
+	return authorizeURL + "?" + query.Encode()
+}
+
+// RequestedScopes returns the configured scopes or the defaults
+func (s OAuth2Settings) RequestedScopes() []string {
+	if len(s.Scopes) == 0 {
+		return DefaultOAuth2Scopes
+	}
+	return s.Scopes
+}
+
+// ExchangeOAuth2Code trades the code the redirect carried for a token and
+// checks that the token grants every configured scope
+func ExchangeOAuth2Code(ctx context.Context, client *http.Client, settings OAuth2Settings, code string, pkce PKCE) (OAuth2Token, error) {
+	token, err := requestOAuth2Token(ctx, client, settings, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {settings.RedirectURL},
+		"code_verifier": {pkce.Verifier},
+	})
+	if err != nil {
+		return OAuth2Token{}, err
+	}
+	if err := checkScopes(token.Scopes, settings.RequestedScopes()); err != nil {
+		return OAuth2Token{}, err
+	}
+	return token, nil
+}
+
+// refreshOAuth2Token trades a refresh token for a new token
+func refreshOAuth2Token(ctx context.Context, client *http.Client, settings OAuth2Settings, refreshToken string) (OAuth2Token, error) {
+	return requestOAuth2Token(ctx, client, settings, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// requestOAuth2Token posts a grant to the token endpoint. Confidential
+// clients authenticate with their secret, public clients by their ID alone.
+func requestOAuth2Token(ctx context.Context, client *http.Client, settings OAuth2Settings, form url.Values) (OAuth2Token, error) {
+	if settings.ClientSecret == "" {
+		form.Set("client_id", settings.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if settings.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(settings.ClientID), url.QueryEscape(settings.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return OAuth2Token{}, fmt.Errorf("token request failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		Scope        string `json:"scope"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return OAuth2Token{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return OAuth2Token{}, fmt.Errorf("token response has no access token")
+	}
+
+	return OAuth2Token{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
+		Scopes:       strings.Fields(tokenResp.Scope),
+	}, nil
+}
+
+// MissingScopes returns the scopes of required that granted does not contain
+func MissingScopes(granted, required []string) []string {
+	have := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		have[scope] = true
+	}
+	var missing []string
+	for _, scope := range required {
+		if !have[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// checkScopes fails with ErrOAuth2Scopes when granted lacks a required scope
+func checkScopes(granted, required []string) error {
+	if missing := MissingScopes(granted, required); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrOAuth2Scopes, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// oauth2Source hands out the account's access token, refreshing it shortly
+// before it expires
+type oauth2Source struct {
+	settings OAuth2Settings
+	client   *http.Client
+	logger   *logrus.Logger
+
+	mu    sync.Mutex
+	token *OAuth2Token
+}
+
+// current returns a token valid for at least RefreshBefore, loading it from
+// the store on first use
+func (s *oauth2Source) current(ctx context.Context) (OAuth2Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		token, err := s.settings.Tokens.LoadOAuth2Token(ctx)
+		if err != nil {
+			return OAuth2Token{}, fmt.Errorf("failed to load OAuth 2.0 token: %w", err)
+		}
+		if token == nil {
+			return OAuth2Token{}, ErrOAuth2NotAuthorized
+		}
+		if err := checkScopes(token.Scopes, s.settings.RequestedScopes()); err != nil {
+			return OAuth2Token{}, err
+		}
+		s.token = token
+	}
+
+	if time.Until(s.token.Expiry) > s.settings.RefreshBefore {
+		return *s.token, nil
+	}
+	expired := !time.Now().Before(s.token.Expiry)
+	if s.token.RefreshToken == "" {
+		if expired {
+			return OAuth2Token{}, fmt.Errorf("%w and cannot be refreshed without the %s scope", ErrOAuth2Expired, ScopeOfflineAccess)
+		}
+		return *s.token, nil
+	}
+
+	refreshed, err := refreshOAuth2Token(ctx, s.client, s.settings, s.token.RefreshToken)
+	if err != nil {
+		if expired {
+			return OAuth2Token{}, fmt.Errorf("%w, failed to refresh it: %w", ErrOAuth2Expired, err)
+		}
+		s.logger.WithError(err).Warn("Failed to refresh OAuth 2.0 token, using it until it expires")
+		return *s.token, nil
+	}
+	// The API leaves out unchanged scopes and refresh tokens on some grants
+	if len(refreshed.Scopes) == 0 {
+		refreshed.Scopes = s.token.Scopes
+	}
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = s.token.RefreshToken
+	}
+	// The old refresh token is spent, so the new one must survive a restart
+	if err := s.settings.Tokens.SaveOAuth2Token(ctx, refreshed); err != nil {
+		s.logger.WithError(err).Error("Failed to save refreshed OAuth 2.0 token")
+	}
+	s.token = &refreshed
+
+	s.logger.WithField("expires_at", refreshed.Expiry.UTC()).Info("Refreshed OAuth 2.0 token")
+	return refreshed, nil
+}
+
+// requireScopes fails with ErrOAuth2Scopes when the token does not grant
+// every scope in required
+func (s *oauth2Source) requireScopes(ctx context.Context, required ...string) error {
+	token, err := s.current(ctx)
+	if err != nil {
+		return err
+	}
+	return checkScopes(token.Scopes, required)
+}
+
+// oauth2Transport sets the account's access token on every request
+type oauth2Transport struct {
+	next   http.RoundTripper
+	source *oauth2Source
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *oauth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.current(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return t.next.RoundTrip(authorized)
+}
+
+// requireScopes fails with ErrOAuth2Scopes when the client makes requests in
+// the OAuth 2.0 user context and its token does not grant every scope in
+// required. Other authentication methods are not checked.
+func (c *TwitterClient) requireScopes(ctx context.Context, required ...string) error {
+	if c.auth.oauth2 == nil {
+		return nil
+	}
+	return c.auth.oauth2.requireScopes(ctx, required...)
+}
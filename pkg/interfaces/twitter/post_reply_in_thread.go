@@ -14,6 +14,8 @@ type PostReplyThreadParams struct {
 	Text           string
 	ReplyToID      string
 	ConversationID string
+	// MediaIDs attaches media uploaded with UploadMedia
+	MediaIDs []string
 }
 
 // PostReplyThread creates a reply that maintains the conversation thread
@@ -60,6 +62,7 @@ func (c *TwitterClient) PostReplyThread(ctx context.Context, params PostReplyThr
 		ReplyOptions: &ReplyOptions{
 			InReplyToTweetId: params.ReplyToID,
 		},
+		MediaIDs: params.MediaIDs,
 	}
 
 	// Log the request body before sending
@@ -63,6 +63,14 @@ func (c *TwitterClient) PostTweetAsync(ctx context.Context, text string, opts *T
 }
 
 func (c *TwitterClient) PostTweet(ctx context.Context, text string, opts *TweetOptions) (*Tweet, error) {
+	if c.dryRun != nil {
+		request := buildBaseRequest(text, opts)
+		if opts != nil && opts.ReplyOptions != nil {
+			request.ReplyTo = opts.ReplyOptions.InReplyToTweetId
+		}
+		return c.recordDryRunTweet(ctx, request)
+	}
+
 	// Create the request body structure
 	requestBody := map[string]interface{}{
 		"text": text,
@@ -75,12 +83,10 @@ func (c *TwitterClient) PostTweet(ctx context.Context, text string, opts *TweetO
 		}
 	}
 
-	// Debug log the final request body
-	requestJSON, _ := json.MarshalIndent(requestBody, "", "  ")
-	logrus.WithFields(logrus.Fields{
-		"endpoint":     c.config.TweetEndpoint,
-		"request_body": string(requestJSON),
-	}).Debug("sending tweet request to Twitter API")
+	// Attach uploaded media
+	if media := buildBaseRequest(text, opts).Media; media != nil {
+		requestBody["media"] = media
+	}
 
 	// Make the request
 	resp, err := c.makeRequest(ctx, http.MethodPost, c.config.TweetEndpoint, requestBody)
@@ -0,0 +1,211 @@
+package twitter
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxClosedQuotaWindows bounds the ended windows kept until they are taken,
+// so a client nobody takes them from does not grow without limit
+const maxClosedQuotaWindows = 1000
+
+// EndpointQuota is the consumption of one endpoint in one rate limit window,
+// as reported by the x-rate-limit headers of its responses
+type EndpointQuota struct {
+	// Endpoint is the method and path with IDs replaced, e.g.
+	// "GET /2/users/:id/mentions"
+	Endpoint  string `json:"endpoint"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	// Used is how much of the limit is gone, including requests other
+	// clients made with the same credentials
+	Used  int       `json:"used"`
+	Reset time.Time `json:"reset"`
+	// Requests counts the responses this client got in the window
+	Requests  int       `json:"requests"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UsedFraction is how much of the limit is gone, from 0 to 1
+func (q EndpointQuota) UsedFraction() float64 {
+	if q.Limit <= 0 {
+		return 0
+	}
+	return float64(q.Used) / float64(q.Limit)
+}
+
+// quotaTracker follows the rate limit windows of every endpoint a client calls
+type quotaTracker struct {
+	mu      sync.Mutex
+	current map[string]*EndpointQuota
+	// closed holds ended windows until they are taken
+	closed []EndpointQuota
+}
+
+func newQuotaTracker() *quotaTracker {
+	return &quotaTracker{current: make(map[string]*EndpointQuota)}
+}
+
+// record counts a response against its endpoint's window. Responses without
+// rate limit headers count against the current window.
+func (t *quotaTracker) record(req *http.Request, resp *http.Response) {
+	endpoint := quotaEndpoint(req)
+	limit := resp.Header.Get("x-rate-limit-limit")
+	remaining := resp.Header.Get("x-rate-limit-remaining")
+	reset := parseInt64Header(resp.Header.Get("x-rate-limit-reset"))
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quota, ok := t.current[endpoint]
+	if !ok {
+		quota = &EndpointQuota{Endpoint: endpoint}
+		t.current[endpoint] = quota
+	}
+	if reset > 0 {
+		resetAt := time.Unix(reset, 0)
+		if !quota.Reset.IsZero() && resetAt.After(quota.Reset) {
+			t.close(*quota)
+			*quota = EndpointQuota{Endpoint: endpoint}
+		}
+		quota.Reset = resetAt
+	}
+	if limit != "" {
+		quota.Limit = parseIntHeader(limit)
+	}
+	if remaining != "" {
+		quota.Remaining = parseIntHeader(remaining)
+	}
+	quota.Used = quota.Limit - quota.Remaining
+	quota.Requests++
+	quota.UpdatedAt = now
+}
+
+// close keeps an ended window until it is taken, dropping the oldest when
+// too many pile up
+func (t *quotaTracker) close(quota EndpointQuota) {
+	if len(t.closed) >= maxClosedQuotaWindows {
+		t.closed = t.closed[1:]
+	}
+	t.closed = append(t.closed, quota)
+}
+
+// snapshot returns the current window of every endpoint, sorted by endpoint.
+// Endpoints whose window has ended show their full limit.
+func (t *quotaTracker) snapshot() []EndpointQuota {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	quotas := make([]EndpointQuota, 0, len(t.current))
+	for _, quota := range t.current {
+		q := *quota
+		if !q.Reset.IsZero() && !now.Before(q.Reset) {
+			q.Remaining = q.Limit
+			q.Used = 0
+			q.Requests = 0
+			q.Reset = time.Time{}
+		}
+		quotas = append(quotas, q)
+	}
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Endpoint < quotas[j].Endpoint
+	})
+	return quotas
+}
+
+// take returns the windows ended since the last take followed by the current
+// windows as they are
+func (t *quotaTracker) take() []EndpointQuota {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	windows := t.closed
+	t.closed = nil
+	for _, quota := range t.current {
+		if !quota.Reset.IsZero() {
+			windows = append(windows, *quota)
+		}
+	}
+	return windows
+}
+
+// quotaEndpoint names the endpoint of a request by its method and path, with
+// IDs and usernames replaced so that every call of an endpoint counts alike.
+// The first segment is the API version.
+func quotaEndpoint(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i, segment := range segments {
+		switch {
+		case i == 0:
+		case segments[i-1] == "username":
+			segments[i] = ":username"
+		case isNumeric(segment):
+			segments[i] = ":id"
+		}
+	}
+	return req.Method + " /" + strings.Join(segments, "/")
+}
+
+// isNumeric reports whether s is a non-empty run of digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// withQuota returns a copy of client that tracks the rate limit headers of
+// every response
+func withQuota(client *http.Client, tracker *quotaTracker) *http.Client {
+	tracking := *client
+	next := tracking.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	tracking.Transport = &quotaTransport{next: next, tracker: tracker}
+	return &tracking
+}
+
+// quotaTransport records each response with the tracker. It sits inside the
+// retries, since every attempt consumes the limit.
+type quotaTransport struct {
+	next    http.RoundTripper
+	tracker *quotaTracker
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.tracker.record(req, resp)
+	}
+	return resp, err
+}
+
+// Quota returns the consumption of every endpoint the client called in the
+// current rate limit window, sorted by endpoint
+func (c *TwitterClient) Quota() []EndpointQuota {
+	return c.quota.snapshot()
+}
+
+// TakeQuotaWindows returns the rate limit windows that ended since the last
+// call, followed by the current windows so far, for persisting
+func (c *TwitterClient) TakeQuotaWindows() []EndpointQuota {
+	return c.quota.take()
+}
+
+// BudgetUsage returns the state of every budget share, or nil when the
+// client has no budget
+func (c *TwitterClient) BudgetUsage() []BudgetUsage {
+	if c.budget == nil {
+		return nil
+	}
+	return c.budget.Usage()
+}
@@ -0,0 +1,286 @@
+package twitter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxRecordedBody caps how much of a body is kept in a recorded exchange
+const maxRecordedBody = 256 << 10
+
+// redactedHeaders are replaced before an exchange is recorded
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Exchange is one recorded API request with the response it got. Credentials
+// are redacted, so exchanges can be attached to bug reports.
+type Exchange struct {
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	StatusCode      int         `json:"status_code,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+	// Error is set when no response was received
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// HTTPRecorder persists recorded exchanges
+type HTTPRecorder interface {
+	RecordExchange(exchange Exchange) error
+}
+
+// FileRecorder appends exchanges to a file as JSON lines
+type FileRecorder struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileRecorder creates a recorder appending to path, creating its
+// directory when needed
+func NewFileRecorder(path string) (*FileRecorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	return &FileRecorder{path: path}, nil
+}
+
+// RecordExchange implements HTTPRecorder
+func (r *FileRecorder) RecordExchange(exchange Exchange) error {
+	line, err := json.Marshal(exchange)
+	if err != nil {
+		return fmt.Errorf("failed to encode exchange: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// WithHTTPRecorder records every API request and response to recorder
+func WithHTTPRecorder(recorder HTTPRecorder) ClientOption {
+	return func(c *TwitterClient) {
+		c.recorder = recorder
+	}
+}
+
+// withRecording returns a copy of client that hands every exchange to recorder
+func withRecording(client *http.Client, recorder HTTPRecorder, logger *logrus.Logger) *http.Client {
+	recording := *client
+	next := recording.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	recording.Transport = &recordingTransport{
+		next:     next,
+		recorder: recorder,
+		logger:   logger,
+	}
+	return &recording
+}
+
+// recordingTransport hands a sanitized copy of every exchange to a recorder.
+// It wraps the retries, so one exchange is recorded per API call with the
+// response the client finally saw.
+type recordingTransport struct {
+	next     http.RoundTripper
+	recorder HTTPRecorder
+	logger   *logrus.Logger
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := Exchange{
+		Time:           time.Now().UTC(),
+		Method:         req.Method,
+		URL:            req.URL.Redacted(),
+		RequestHeaders: redactHeaders(req.Header),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.RequestBody = recordedBody(req.Header.Get("Content-Type"), body)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	exchange.Duration = time.Since(exchange.Time)
+	if err != nil {
+		exchange.Error = err.Error()
+		t.record(exchange)
+		return resp, err
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	exchange.StatusCode = resp.StatusCode
+	exchange.ResponseHeaders = redactHeaders(resp.Header)
+	exchange.ResponseBody = recordedBody(resp.Header.Get("Content-Type"), body)
+	if readErr != nil {
+		exchange.Error = readErr.Error()
+	}
+	t.record(exchange)
+
+	return resp, readErr
+}
+
+// record persists an exchange; a failing recorder never fails the request
+func (t *recordingTransport) record(exchange Exchange) {
+	if err := t.recorder.RecordExchange(exchange); err != nil {
+		t.logger.WithError(err).WithFields(logrus.Fields{
+			"method": exchange.Method,
+			"url":    exchange.URL,
+		}).Warn("Failed to record HTTP exchange")
+	}
+}
+
+// redactHeaders copies header with credentials replaced
+func redactHeaders(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "REDACTED")
+		}
+	}
+	return redacted
+}
+
+// recordedBody returns body as recorded: text bodies as is up to
+// maxRecordedBody, uploads and other binary bodies as a size note
+func recordedBody(contentType string, body []byte) string {
+	return bodySummary(contentType, body, maxRecordedBody)
+}
+
+// bodySummary returns text bodies truncated to limit bytes and binary bodies,
+// such as media uploads, as a size note
+func bodySummary(contentType string, body []byte, limit int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "", "application/json", "application/x-www-form-urlencoded", "text/plain":
+	default:
+		return fmt.Sprintf("<%d bytes of %s>", len(body), mediaType)
+	}
+	if len(body) > limit {
+		return string(body[:limit]) + "...<truncated>"
+	}
+	return string(body)
+}
+
+// ReplayTransport answers requests from recorded exchanges instead of the
+// API, to reproduce a recorded session in tests. Requests are matched by
+// method, path and query string; when an endpoint was called several times
+// its responses are replayed in order and the last one is repeated.
+type ReplayTransport struct {
+	mu        sync.Mutex
+	exchanges map[string][]Exchange
+}
+
+// NewReplayTransport loads the exchanges a FileRecorder wrote to path
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	t := &ReplayTransport{exchanges: make(map[string][]Exchange)}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var exchange Exchange
+		if err := decoder.Decode(&exchange); err != nil {
+			return nil, fmt.Errorf("failed to decode recording: %w", err)
+		}
+		if exchange.Error != "" && exchange.StatusCode == 0 {
+			continue
+		}
+		key, err := replayKey(exchange.Method, exchange.URL)
+		if err != nil {
+			return nil, err
+		}
+		t.exchanges[key] = append(t.exchanges[key], exchange)
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key, err := replayKey(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	recorded := t.exchanges[key]
+	if len(recorded) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+	exchange := recorded[0]
+	if len(recorded) > 1 {
+		t.exchanges[key] = recorded[1:]
+	}
+	t.mu.Unlock()
+
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(exchange.ResponseBody))),
+		ContentLength: int64(len(exchange.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// replayKey identifies an endpoint call independently of the host it went to
+func replayKey(method, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid recorded URL %q: %w", rawURL, err)
+	}
+	return method + " " + u.Path + "?" + u.Query().Encode(), nil
+}
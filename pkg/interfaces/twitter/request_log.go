@@ -0,0 +1,154 @@
+package twitter
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/logging/redact"
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults of RequestLogOptions
+const (
+	DefaultLogSampleRate = 10
+	DefaultLogBodyLimit  = 2 << 10
+)
+
+// RequestLogOptions controls the debug log of API requests
+type RequestLogOptions struct {
+	// SampleRate logs one in every SampleRate successful requests; failed
+	// requests are always logged. 1 logs every request.
+	SampleRate int
+	// BodyLimit truncates logged request and response bodies to this many
+	// bytes; 0 leaves bodies out
+	BodyLimit int
+}
+
+// WithRequestLogging replaces the default sampling and truncation of the
+// debug request log
+func WithRequestLogging(opts RequestLogOptions) ClientOption {
+	return func(c *TwitterClient) {
+		c.requestLog = opts
+	}
+}
+
+// withRequestLogging returns a copy of client that logs requests at debug level
+func withRequestLogging(client *http.Client, opts RequestLogOptions, logger *logrus.Logger, secrets ...string) *http.Client {
+	if opts.SampleRate <= 0 {
+		opts.SampleRate = 1
+	}
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	var known []string
+	for _, secret := range secrets {
+		if secret != "" {
+			known = append(known, secret)
+		}
+	}
+
+	logging := *client
+	logging.Transport = &loggingTransport{
+		next:    next,
+		opts:    opts,
+		logger:  logger,
+		secrets: known,
+	}
+	return &logging
+}
+
+// loggingTransport logs a sample of the requests that succeed and every one
+// that fails, with credentials redacted and bodies truncated. Nothing is read
+// or logged unless the logger is at debug level.
+type loggingTransport struct {
+	next    http.RoundTripper
+	opts    RequestLogOptions
+	logger  *logrus.Logger
+	secrets []string
+	count   atomic.Uint64
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.logger.IsLevelEnabled(logrus.DebugLevel) {
+		return t.next.RoundTrip(req)
+	}
+
+	var requestBody []byte
+	if t.opts.BodyLimit > 0 && req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		requestBody = body
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+
+	failed := err != nil || resp.StatusCode >= http.StatusBadRequest
+	if !failed && (t.count.Add(1)-1)%uint64(t.opts.SampleRate) != 0 {
+		return resp, err
+	}
+
+	fields := logrus.Fields{
+		"method":      req.Method,
+		"url":         t.redact(req.URL.Redacted()),
+		"duration_ms": duration.Milliseconds(),
+		"headers":     redactHeaders(req.Header),
+	}
+	if !failed && t.opts.SampleRate > 1 {
+		fields["sample_rate"] = t.opts.SampleRate
+	}
+	if requestBody != nil {
+		fields["request_body"] = t.body(req.Header.Get("Content-Type"), requestBody)
+	}
+
+	if err != nil {
+		t.logger.WithFields(fields).WithError(err).Debug("Twitter API request failed")
+		return resp, err
+	}
+
+	fields["status_code"] = resp.StatusCode
+	fields["response_headers"] = redactHeaders(resp.Header)
+	if t.opts.BodyLimit > 0 {
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			err = readErr
+		}
+		fields["response_body"] = t.body(resp.Header.Get("Content-Type"), body)
+	}
+
+	if failed {
+		t.logger.WithFields(fields).Debug("Twitter API request failed")
+	} else {
+		t.logger.WithFields(fields).Debug("Twitter API request")
+	}
+	return resp, err
+}
+
+// body returns a body as logged: truncated and with credentials redacted
+func (t *loggingTransport) body(contentType string, body []byte) string {
+	return t.redact(bodySummary(contentType, body, t.opts.BodyLimit))
+}
+
+// redact replaces the client's own credentials and anything that looks like
+// a bearer token or OAuth secret
+func (t *loggingTransport) redact(s string) string {
+	for _, secret := range t.secrets {
+		s = strings.ReplaceAll(s, secret, redact.Mask)
+	}
+	return redact.String(s)
+}
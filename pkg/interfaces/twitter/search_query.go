@@ -0,0 +1,150 @@
+package twitter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxSearchQueryLength is the longest query the recent search endpoint accepts
+const MaxSearchQueryLength = 512
+
+// SearchQuery builds a query for the recent search endpoint. Its methods add
+// clauses that must all match, e.g.
+//
+//	NewSearchQuery().AnyOf("gm", "good morning").Lang("en").ExcludeRetweets()
+//
+// renders as `(gm OR "good morning") lang:en -is:retweet`.
+type SearchQuery struct {
+	clauses []string
+}
+
+// NewSearchQuery starts an empty search query
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{}
+}
+
+// Keywords requires every keyword; keywords with spaces match as phrases
+func (q *SearchQuery) Keywords(keywords ...string) *SearchQuery {
+	for _, keyword := range keywords {
+		if term := searchTerm(keyword); term != "" {
+			q.clauses = append(q.clauses, term)
+		}
+	}
+	return q
+}
+
+// AnyOf requires at least one of the keywords
+func (q *SearchQuery) AnyOf(keywords ...string) *SearchQuery {
+	var terms []string
+	for _, keyword := range keywords {
+		if term := searchTerm(keyword); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	switch len(terms) {
+	case 0:
+	case 1:
+		q.clauses = append(q.clauses, terms[0])
+	default:
+		q.clauses = append(q.clauses, "("+strings.Join(terms, " OR ")+")")
+	}
+	return q
+}
+
+// Without leaves out tweets containing any of the keywords
+func (q *SearchQuery) Without(keywords ...string) *SearchQuery {
+	for _, keyword := range keywords {
+		if term := searchTerm(keyword); term != "" {
+			q.clauses = append(q.clauses, "-"+term)
+		}
+	}
+	return q
+}
+
+// From matches tweets posted by username
+func (q *SearchQuery) From(username string) *SearchQuery {
+	return q.operator("from", strings.TrimPrefix(username, "@"))
+}
+
+// To matches replies to username
+func (q *SearchQuery) To(username string) *SearchQuery {
+	return q.operator("to", strings.TrimPrefix(username, "@"))
+}
+
+// Mentioning matches tweets that mention username
+func (q *SearchQuery) Mentioning(username string) *SearchQuery {
+	if username = strings.TrimPrefix(strings.TrimSpace(username), "@"); username != "" {
+		q.clauses = append(q.clauses, "@"+username)
+	}
+	return q
+}
+
+// Lang matches tweets Twitter classified as the BCP 47 language code
+func (q *SearchQuery) Lang(code string) *SearchQuery {
+	return q.operator("lang", code)
+}
+
+// ConversationID matches the tweets of one conversation
+func (q *SearchQuery) ConversationID(id string) *SearchQuery {
+	return q.operator("conversation_id", id)
+}
+
+// IsReply only matches replies
+func (q *SearchQuery) IsReply() *SearchQuery {
+	q.clauses = append(q.clauses, "is:reply")
+	return q
+}
+
+// ExcludeReplies leaves out replies
+func (q *SearchQuery) ExcludeReplies() *SearchQuery {
+	q.clauses = append(q.clauses, "-is:reply")
+	return q
+}
+
+// ExcludeRetweets leaves out retweets, the v2 form of -filter:retweets
+func (q *SearchQuery) ExcludeRetweets() *SearchQuery {
+	q.clauses = append(q.clauses, "-is:retweet")
+	return q
+}
+
+// Raw adds a clause as is, for operators the builder does not cover
+func (q *SearchQuery) Raw(clause string) *SearchQuery {
+	if clause = strings.TrimSpace(clause); clause != "" {
+		q.clauses = append(q.clauses, clause)
+	}
+	return q
+}
+
+// String renders the query
+func (q *SearchQuery) String() string {
+	return strings.Join(q.clauses, " ")
+}
+
+// Build renders the query, failing when it is empty or too long for the API
+func (q *SearchQuery) Build() (string, error) {
+	query := q.String()
+	if query == "" {
+		return "", fmt.Errorf("search query is empty")
+	}
+	if len(query) > MaxSearchQueryLength {
+		return "", fmt.Errorf("search query is %d characters, longer than the %d character limit", len(query), MaxSearchQueryLength)
+	}
+	return query, nil
+}
+
+// operator adds name:value, skipping empty values
+func (q *SearchQuery) operator(name, value string) *SearchQuery {
+	if value = strings.TrimSpace(value); value != "" {
+		q.clauses = append(q.clauses, name+":"+value)
+	}
+	return q
+}
+
+// searchTerm quotes keywords that contain spaces so they match as phrases
+func searchTerm(keyword string) string {
+	keyword = strings.TrimSpace(keyword)
+	if strings.ContainsAny(keyword, " \t") {
+		return `"` + strings.ReplaceAll(keyword, `"`, "") + `"`
+	}
+	return keyword
+}
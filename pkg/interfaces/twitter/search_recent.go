@@ -0,0 +1,132 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SearchRecentParams holds the parameters for the SearchRecentTweets request
+type SearchRecentParams struct {
+	// MaxResults is the page size, between 10 and 100
+	MaxResults      int
+	PaginationToken string
+	// SinceID and UntilID only return tweets newer or older than the given IDs
+	SinceID string
+	UntilID string
+	// StartTime and EndTime bound the tweets by creation time, in RFC3339
+	StartTime string
+	EndTime   string
+	// SortOrder is "recency" or "relevancy"
+	SortOrder string
+}
+
+// SearchRecentTweets searches the tweets of the last seven days, sending one
+// response per page. Build query with SearchQuery.
+// Rate limit: 450/15m (app), 180/15m (user)
+func (c *TwitterClient) SearchRecentTweets(ctx context.Context, query string, params SearchRecentParams) (chan *TweetsResponse, chan error) {
+	dataChan := make(chan *TweetsResponse)
+	errChan := make(chan error)
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+
+		log := c.logger.WithFields(logrus.Fields{
+			"method": "SearchRecentTweets",
+			"query":  query,
+		})
+
+		if query == "" {
+			errChan <- fmt.Errorf("search query is required")
+			return
+		}
+
+		endpoint := c.config.SearchEndpoint
+
+		for {
+			select {
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			default:
+				queryParams := c.tweetQueryParams(
+					[]string{"conversation_id", "in_reply_to_user_id", "referenced_tweets", "lang"},
+					[]string{"referenced_tweets.id", "in_reply_to_user_id"},
+				)
+				queryParams["query"] = query
+				if params.MaxResults > 0 {
+					queryParams["max_results"] = strconv.Itoa(params.MaxResults)
+				}
+				for key, value := range map[string]string{
+					"pagination_token": params.PaginationToken,
+					"since_id":         params.SinceID,
+					"until_id":         params.UntilID,
+					"start_time":       params.StartTime,
+					"end_time":         params.EndTime,
+					"sort_order":       params.SortOrder,
+				} {
+					if value != "" {
+						queryParams[key] = value
+					}
+				}
+
+				log.WithFields(logrus.Fields{
+					"endpoint": endpoint,
+					"params":   queryParams,
+				}).Debug("Searching recent tweets")
+
+				resp, err := c.makeRequestWithParams(ctx, http.MethodGet, endpoint, queryParams)
+				if err != nil {
+					log.WithError(err).Error("Failed to search tweets")
+					errChan <- fmt.Errorf("failed to search tweets: %w", err)
+					return
+				}
+
+				var searchResp TweetsResponse
+				err = json.NewDecoder(resp.Body).Decode(&searchResp)
+				resp.Body.Close()
+				if err != nil {
+					log.WithError(err).Error("Failed to decode response")
+					errChan <- fmt.Errorf("failed to decode response: %w", err)
+					return
+				}
+
+				if len(searchResp.Errors) > 0 {
+					for _, apiErr := range searchResp.Errors {
+						log.WithFields(logrus.Fields{
+							"error_code":    apiErr.Code,
+							"error_message": apiErr.Message,
+						}).Error("Twitter API error")
+						errChan <- &apiErr
+					}
+					return
+				}
+
+				log.WithFields(logrus.Fields{
+					"tweets_found": len(searchResp.Data),
+					"meta":         searchResp.Meta,
+				}).Debug("Received search response")
+
+				// Send the response to the data channel
+				dataChan <- &searchResp
+
+				// Check if we have more pages
+				if searchResp.Meta == nil || searchResp.Meta.NextToken == "" {
+					log.Debug("No more pages to fetch")
+					return
+				}
+
+				// Update pagination token for next request
+				params.PaginationToken = searchResp.Meta.NextToken
+				log.WithField("next_token", params.PaginationToken).Debug("Fetching next page")
+			}
+		}
+	}()
+
+	return dataChan, errChan
+}
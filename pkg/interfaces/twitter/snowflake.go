@@ -0,0 +1,47 @@
+package twitter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// snowflakeEpoch is the start of tweet ID timestamps (2010-11-04), in milliseconds
+const snowflakeEpoch = 1288834974657
+
+// CompareIDs orders two tweet IDs by age, returning -1 when a is older than
+// b, 1 when it is newer and 0 when they are equal. IDs are compared as
+// decimal strings so they never overflow.
+func CompareIDs(a, b string) int {
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IDTime returns the time a tweet ID was generated at
+func IDTime(id string) (time.Time, error) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid tweet ID %q: %w", id, err)
+	}
+	return time.UnixMilli(int64(n>>22) + snowflakeEpoch), nil
+}
+
+// IDAt returns the smallest tweet ID generated at t, for use as since_id or
+// until_id; times before the first snowflake return "0"
+func IDAt(t time.Time) string {
+	millis := t.UnixMilli() - snowflakeEpoch
+	if millis <= 0 {
+		return "0"
+	}
+	return strconv.FormatUint(uint64(millis)<<22, 10)
+}
@@ -1,9 +1,6 @@
 package twitter
 
-import (
-	"encoding/json"
-	"fmt"
-)
+import "fmt"
 
 // Tweet represents a Twitter post with all v2 API fields
 type Tweet struct {
@@ -110,45 +107,88 @@ type Tweet struct {
 		LikeCount    int `json:"like_count"`
 		QuoteCount   int `json:"quote_count"`
 	} `json:"public_metrics,omitempty"`
-	ReferencedTweets []struct {
-		Type string `json:"type"` // "retweeted" or "quoted" or "replied_to"
-		ID   string `json:"id"`
-	} `json:"referenced_tweets,omitempty"`
-	ReplySettings string `json:"reply_settings,omitempty"` // "everyone", "mentionedUsers", "following"
-	Source        string `json:"source,omitempty"`
-	Withheld      struct {
+	ReferencedTweets []ReferencedTweet `json:"referenced_tweets,omitempty"`
+	ReplySettings    string            `json:"reply_settings,omitempty"` // "everyone", "mentionedUsers", "following"
+	Source           string            `json:"source,omitempty"`
+	Withheld         struct {
 		Copyright    bool     `json:"copyright,omitempty"`
 		CountryCodes []string `json:"country_codes,omitempty"`
 		Scope        string   `json:"scope,omitempty"` // "tweet" or "user"
 	} `json:"withheld,omitempty"`
 }
 
-// TweetResponse represents the Twitter API response format
-// It can handle both single Tweet and array responses
+// TweetResponse is the envelope of endpoints that return a single tweet,
+// such as a lookup by ID or a newly posted tweet
 type TweetResponse struct {
-	// Data can be either a single Tweet or an array of Tweets
-	Data     json.RawMessage `json:"data"`
-	Includes *TweetIncludes  `json:"includes,omitempty"`
-	Errors   []TwitterError  `json:"errors,omitempty"`
-	Meta     *Meta           `json:"meta,omitempty"`
+	Data     *Tweet         `json:"data,omitempty"`
+	Includes *TweetIncludes `json:"includes,omitempty"`
+	Errors   []TwitterError `json:"errors,omitempty"`
+	Meta     *Meta          `json:"meta,omitempty"`
 }
 
-// UnmarshalTweet handles unmarshaling a single Tweet response
+// UnmarshalTweet returns the tweet of the response. Without one it returns
+// the first API error of the response, if any.
 func (tr *TweetResponse) UnmarshalTweet() (*Tweet, error) {
-	var tweet Tweet
-	if err := json.Unmarshal(tr.Data, &tweet); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal single tweet: %w", err)
+	if tr == nil {
+		return nil, fmt.Errorf("tweet response is nil")
+	}
+	if tr.Data == nil {
+		if len(tr.Errors) > 0 {
+			return nil, fmt.Errorf("no tweet in response: %w", &tr.Errors[0])
+		}
+		return nil, fmt.Errorf("no tweet in response")
 	}
-	return &tweet, nil
+	return tr.Data, nil
 }
 
-// UnmarshalTweets handles unmarshaling an array of Tweets response
+// UnmarshalTweets returns the tweet of the response as a list of one
 func (tr *TweetResponse) UnmarshalTweets() ([]Tweet, error) {
-	var tweets []Tweet
-	if err := json.Unmarshal(tr.Data, &tweets); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tweet array: %w", err)
+	tweet, err := tr.UnmarshalTweet()
+	if err != nil {
+		return nil, err
 	}
-	return tweets, nil
+	return []Tweet{*tweet}, nil
+}
+
+// TweetsResponse is the envelope of endpoints that return a list of tweets,
+// such as timelines, searches and lookups of several tweets. Twitter leaves
+// out data on an empty page.
+type TweetsResponse struct {
+	Data     []Tweet        `json:"data,omitempty"`
+	Includes *TweetIncludes `json:"includes,omitempty"`
+	Errors   []TwitterError `json:"errors,omitempty"`
+	Meta     *Meta          `json:"meta,omitempty"`
+}
+
+// UnmarshalTweets returns the tweets of the response; an empty page has none
+func (tr *TweetsResponse) UnmarshalTweets() ([]Tweet, error) {
+	if tr == nil {
+		return nil, fmt.Errorf("tweets response is nil")
+	}
+	return tr.Data, nil
+}
+
+// UnmarshalTweet returns the first tweet of the response
+func (tr *TweetsResponse) UnmarshalTweet() (*Tweet, error) {
+	tweets, err := tr.UnmarshalTweets()
+	if err != nil {
+		return nil, err
+	}
+	if len(tweets) == 0 {
+		if len(tr.Errors) > 0 {
+			return nil, fmt.Errorf("no tweets in response: %w", &tr.Errors[0])
+		}
+		return nil, fmt.Errorf("no tweets in response")
+	}
+	return &tweets[0], nil
+}
+
+// GetConversationID returns the conversation ID of the first tweet in the response
+func (tr *TweetsResponse) GetConversationID() string {
+	if tr == nil || len(tr.Data) == 0 {
+		return ""
+	}
+	return tr.Data[0].ConversationID
 }
 
 // TweetIncludes contains the expanded objects in the response
@@ -160,6 +200,32 @@ type TweetIncludes struct {
 	Polls  []Poll  `json:"polls,omitempty"`
 }
 
+// User returns the included user with the given ID, or nil
+func (i *TweetIncludes) User(id string) *User {
+	if i == nil {
+		return nil
+	}
+	for n := range i.Users {
+		if i.Users[n].ID == id {
+			return &i.Users[n]
+		}
+	}
+	return nil
+}
+
+// Tweet returns the included tweet with the given ID, or nil
+func (i *TweetIncludes) Tweet(id string) *Tweet {
+	if i == nil {
+		return nil
+	}
+	for n := range i.Tweets {
+		if i.Tweets[n].ID == id {
+			return &i.Tweets[n]
+		}
+	}
+	return nil
+}
+
 // TwitterError represents an error returned by the Twitter API
 type TwitterError struct {
 	Code    int    `json:"code"`
@@ -344,6 +410,18 @@ type Trend struct {
 	Woeid       int    `json:"woeid"`
 }
 
+// Compliance event types the agent acts on. Deleted and dropped tweets, and
+// the tweets of deleted, protected or suspended users, must be removed; scrubbed
+// tweets must lose their location.
+const (
+	ComplianceDelete      = "delete"
+	ComplianceDrop        = "drop"
+	ComplianceScrubGeo    = "scrub_geo"
+	ComplianceUserDelete  = "user_delete"
+	ComplianceUserProtect = "user_protect"
+	ComplianceUserSuspend = "user_suspend"
+)
+
 // Compliance represents a Twitter compliance event
 type Compliance struct {
 	ID          string `json:"id"`
@@ -386,7 +464,8 @@ type TweetCountsResponse struct {
 		End        string `json:"end"`
 		TweetCount int    `json:"tweet_count"`
 	} `json:"data"`
-	Meta *Meta `json:"meta,omitempty"`
+	Meta   *Meta          `json:"meta,omitempty"`
+	Errors []TwitterError `json:"errors,omitempty"`
 }
 
 // ListResponse represents the response for list operations
@@ -404,29 +483,11 @@ type ListMembersResponse struct {
 	Error    *TwitterError  `json:"error,omitempty"`
 }
 
-// ConversationResponse represents the response from the conversation lookup endpoint
-type ConversationResponse struct {
-	Data     []Tweet        `json:"data"`
-	Includes *TweetIncludes `json:"includes,omitempty"`
-	Errors   []TwitterError `json:"errors,omitempty"`
-	Meta     *Meta          `json:"meta,omitempty"`
-}
-
-// MentionResponse represents the response from the mentions timeline endpoint
-type MentionResponse struct {
-	Data     []Tweet        `json:"data"`
-	Includes *TweetIncludes `json:"includes,omitempty"`
-	Meta     *Meta          `json:"meta,omitempty"`
-	Errors   []TwitterError `json:"errors,omitempty"`
-}
+// ConversationResponse is a page of a conversation search
+type ConversationResponse = TweetsResponse
 
-// UnmarshalTweets returns the tweets from a mention response
-func (mr *MentionResponse) UnmarshalTweets() ([]Tweet, error) {
-	if mr == nil {
-		return nil, fmt.Errorf("mention response is nil")
-	}
-	return mr.Data, nil
-}
+// MentionResponse is a page of the mentions timeline
+type MentionResponse = TweetsResponse
 
 type TwitterErrorResponse struct {
 	Errors []struct {
@@ -466,6 +527,6 @@ type MediaOptions struct {
 }
 
 type ReferencedTweet struct {
-	Type string `json:"type"`
+	Type string `json:"type"` // "retweeted" or "quoted" or "replied_to"
 	ID   string `json:"id"`
 }
@@ -0,0 +1,130 @@
+package twitter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxImageBytes is the largest image the simple media upload accepts
+const MaxImageBytes = 5 * 1024 * 1024
+
+// UploadMediaParams holds the parameters for uploading an image
+type UploadMediaParams struct {
+	Data []byte
+	// MediaType is the MIME type, e.g. image/png
+	MediaType string
+	// Category defaults to tweet_image
+	Category string
+}
+
+// UploadedMedia is media that can be attached to a tweet by ID
+type UploadedMedia struct {
+	ID       string `json:"id"`
+	MediaKey string `json:"media_key"`
+	Size     int    `json:"size"`
+}
+
+// UploadMedia uploads an image in a single request so its ID can be passed in
+// BaseOptions.MediaIDs. In dry-run mode nothing is uploaded and a synthetic
+// ID is returned.
+func (c *TwitterClient) UploadMedia(ctx context.Context, params UploadMediaParams) (*UploadedMedia, error) {
+	if len(params.Data) == 0 {
+		return nil, fmt.Errorf("media data is required")
+	}
+	if len(params.Data) > MaxImageBytes {
+		return nil, fmt.Errorf("media is %d bytes, larger than the %d byte limit", len(params.Data), MaxImageBytes)
+	}
+	if params.MediaType == "" {
+		params.MediaType = http.DetectContentType(params.Data)
+	}
+	if params.Category == "" {
+		params.Category = "tweet_image"
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"method":     "UploadMedia",
+		"media_type": params.MediaType,
+		"bytes":      len(params.Data),
+	})
+
+	if c.dryRun != nil {
+		log.Info("Dry run: skipping media upload")
+		return &UploadedMedia{ID: dryrun.NewTweetID(), Size: len(params.Data)}, nil
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("media_category", params.Category); err != nil {
+		return nil, fmt.Errorf("failed to write media category: %w", err)
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="media"; filename="media"`)
+	header.Set("Content-Type", params.MediaType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create media part: %w", err)
+	}
+	if _, err := part.Write(params.Data); err != nil {
+		return nil, fmt.Errorf("failed to write media: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close form: %w", err)
+	}
+
+	if err := c.budget.Reserve(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+c.config.MediaEndpoint, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+
+	log.Debug("Uploading media to Twitter API")
+
+	// The OAuth 1.0a client signs the request; multipart bodies are not part
+	// of the signature
+	resp, err := c.auth.GetClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if err := c.handleRateLimits(resp); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.handleResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data   UploadedMedia `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("twitter API error: %s", result.Errors[0].Message)
+	}
+	if result.Data.ID == "" {
+		return nil, fmt.Errorf("media upload returned no media ID")
+	}
+
+	log.WithField("media_id", result.Data.ID).Debug("Uploaded media")
+	return &result.Data, nil
+}
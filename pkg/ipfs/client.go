@@ -0,0 +1,119 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Client adds and pins content on an IPFS node
+type Client struct {
+	config *Config
+	http   *http.Client
+	logger *logrus.Logger
+}
+
+// Pin is content that was added to IPFS
+type Pin struct {
+	CID  string `json:"cid"`
+	Size int64  `json:"size"`
+}
+
+// URI returns the ipfs:// URI of the content
+func (p Pin) URI() string {
+	return "ipfs://" + p.CID
+}
+
+// NewClient creates a new IPFS client
+func NewClient(config *Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid IPFS config: %w", err)
+	}
+
+	return &Client{
+		config: config,
+		http:   &http.Client{Timeout: config.RequestTimeout},
+		logger: config.Logger,
+	}, nil
+}
+
+// GatewayURL returns an HTTP link to the content with the given CID
+func (c *Client) GatewayURL(cid string) string {
+	return c.config.GatewayURL + cid
+}
+
+// PinJSON encodes value as JSON, adds it to IPFS and pins it
+func (c *Client) PinJSON(ctx context.Context, name string, value interface{}) (*Pin, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode content: %w", err)
+	}
+	return c.Add(ctx, name, data)
+}
+
+// Add uploads data to IPFS as a single file and pins it. Content is added as
+// CIDv1 so the returned CID is the same on any node.
+func (c *Client) Add(ctx context.Context, name string, data []byte) (*Pin, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write form file: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.APIURL+"/api/v0/add?pin=true&cid-version=1", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	switch {
+	case c.config.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	case c.config.Username != "":
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"name":  name,
+		"bytes": len(data),
+	})
+	log.Debug("Adding content to IPFS")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("IPFS add failed (status %d): %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var added struct {
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if added.Hash == "" {
+		return nil, fmt.Errorf("IPFS add returned no CID")
+	}
+
+	pin := &Pin{CID: added.Hash, Size: int64(len(data))}
+	log.WithField("cid", pin.CID).Info("Pinned content to IPFS")
+	return pin, nil
+}
@@ -0,0 +1,57 @@
+// Package ipfs pins content to IPFS through a Kubo-compatible RPC API, such as
+// a local node or a hosted pinning service.
+package ipfs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default configuration values
+const (
+	// DefaultGatewayURL serves pinned content over HTTP
+	DefaultGatewayURL = "https://ipfs.io/ipfs/"
+	// DefaultRequestTimeout bounds each request to the RPC API
+	DefaultRequestTimeout = time.Minute
+)
+
+// Config holds the IPFS client configuration
+type Config struct {
+	// APIURL is the RPC API of the node or pinning service, e.g.
+	// http://localhost:5001
+	APIURL string
+	// Username and Password authenticate with HTTP basic auth, as hosted
+	// services like Infura expect; Token is sent as a bearer token instead
+	Username string
+	Password string
+	Token    string
+	// GatewayURL prefixes CIDs to build HTTP links
+	GatewayURL string
+
+	RequestTimeout time.Duration
+	Logger         *logrus.Logger
+}
+
+// Validate checks the required settings and fills unset values with defaults
+func (c *Config) Validate() error {
+	if c.Logger == nil {
+		c.Logger = logrus.StandardLogger()
+	}
+	if c.APIURL == "" {
+		return fmt.Errorf("IPFS API URL is required")
+	}
+	c.APIURL = strings.TrimRight(c.APIURL, "/")
+	if c.GatewayURL == "" {
+		c.GatewayURL = DefaultGatewayURL
+	}
+	if !strings.HasSuffix(c.GatewayURL, "/") {
+		c.GatewayURL += "/"
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = DefaultRequestTimeout
+	}
+	return nil
+}
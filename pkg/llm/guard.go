@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+var (
+	// ErrTimeout is returned when a call did not finish within its timeout
+	ErrTimeout = errors.New("LLM call timed out")
+	// ErrCircuitOpen is returned without calling the provider while it is
+	// considered down after repeated failures
+	ErrCircuitOpen = errors.New("LLM provider circuit breaker is open")
+)
+
+// GuardConfig bounds how long LLM calls may take and when to stop calling a
+// failing provider
+type GuardConfig struct {
+	// Timeout limits every call; defaults to 30 seconds
+	Timeout time.Duration
+	// TimeoutRetries is how many times a timed out call is retried; 0 uses
+	// the default of 1 and a negative value disables retries
+	TimeoutRetries int
+	// RetryTokenFactor scales the max tokens of each retry, so a retry asks
+	// for less and is more likely to finish; defaults to 0.5
+	RetryTokenFactor float64
+	// DefaultMaxTokens is scaled on retry when a call sets no max tokens
+	DefaultMaxTokens int
+	// BreakerThreshold is how many consecutive failed calls open the circuit
+	// breaker; defaults to 5
+	BreakerThreshold int
+	// BreakerCooldown is how long the breaker stays open before a trial call
+	// is let through; defaults to a minute
+	BreakerCooldown time.Duration
+	Logger          *logrus.Logger
+}
+
+// GuardedModel wraps an llms.Model with per-call timeouts, retries of timed
+// out calls with fewer max tokens, and a circuit breaker that fails calls
+// fast while the provider keeps failing
+type GuardedModel struct {
+	model  llms.Model
+	config GuardConfig
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// NewGuardedModel wraps model with the limits in config
+func NewGuardedModel(model llms.Model, config GuardConfig) *GuardedModel {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	switch {
+	case config.TimeoutRetries == 0:
+		config.TimeoutRetries = 1
+	case config.TimeoutRetries < 0:
+		config.TimeoutRetries = 0
+	}
+	if config.RetryTokenFactor <= 0 || config.RetryTokenFactor > 1 {
+		config.RetryTokenFactor = 0.5
+	}
+	if config.BreakerThreshold <= 0 {
+		config.BreakerThreshold = 5
+	}
+	if config.BreakerCooldown <= 0 {
+		config.BreakerCooldown = time.Minute
+	}
+	if config.Logger == nil {
+		config.Logger = logrus.New()
+	}
+	return &GuardedModel{model: model, config: config}
+}
+
+// GenerateContent implements llms.Model
+func (g *GuardedModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	if err := g.allow(); err != nil {
+		return nil, err
+	}
+
+	var (
+		resp    *llms.ContentResponse
+		err     error
+		maxToks = g.maxTokens(options)
+	)
+	for attempt := 0; ; attempt++ {
+		opts := options
+		if attempt > 0 && maxToks > 0 {
+			opts = append(opts[:len(opts):len(opts)], llms.WithMaxTokens(maxToks))
+		}
+
+		var streamed bool
+		opts = trackStreaming(opts, &streamed)
+
+		callCtx, cancel := context.WithTimeout(ctx, g.config.Timeout)
+		resp, err = g.model.GenerateContent(callCtx, messages, opts...)
+		timedOut := err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+
+		if err == nil {
+			g.record(nil)
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			// The caller gave up; that says nothing about the provider
+			g.release()
+			return nil, err
+		}
+		if !timedOut {
+			g.record(err)
+			return nil, err
+		}
+
+		err = fmt.Errorf("%w after %s: %v", ErrTimeout, g.config.Timeout, err)
+		// A retry would repeat chunks the caller already received
+		if streamed || attempt >= g.config.TimeoutRetries {
+			g.record(err)
+			return nil, err
+		}
+
+		maxToks = int(float64(maxToks) * g.config.RetryTokenFactor)
+		g.config.Logger.WithFields(logrus.Fields{
+			"attempt":    attempt + 1,
+			"timeout":    g.config.Timeout,
+			"max_tokens": maxToks,
+		}).Warn("LLM call timed out, retrying with fewer max tokens")
+	}
+}
+
+// Call implements llms.Model
+func (g *GuardedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, g, prompt, options...)
+}
+
+// maxTokens returns the max tokens the call options ask for
+func (g *GuardedModel) maxTokens(options []llms.CallOption) int {
+	var resolved llms.CallOptions
+	for _, opt := range options {
+		opt(&resolved)
+	}
+	if resolved.MaxTokens > 0 {
+		return resolved.MaxTokens
+	}
+	return g.config.DefaultMaxTokens
+}
+
+// allow reports whether a call may go to the provider. Once the cooldown of
+// an open breaker has passed, a single trial call is let through.
+func (g *GuardedModel) allow() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.failures < g.config.BreakerThreshold {
+		return nil
+	}
+	if wait := time.Until(g.openUntil); wait > 0 || g.trial {
+		return fmt.Errorf("%w (retry in %s)", ErrCircuitOpen, max(wait, 0).Round(time.Second))
+	}
+	g.trial = true
+	return nil
+}
+
+// record updates the breaker with the outcome of a call
+func (g *GuardedModel) record(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.trial = false
+	if err == nil {
+		if g.failures >= g.config.BreakerThreshold {
+			g.config.Logger.Info("LLM provider recovered, closing circuit breaker")
+		}
+		g.failures = 0
+		return
+	}
+
+	g.failures++
+	if g.failures >= g.config.BreakerThreshold {
+		g.openUntil = time.Now().Add(g.config.BreakerCooldown)
+		g.config.Logger.WithError(err).WithFields(logrus.Fields{
+			"failures": g.failures,
+			"cooldown": g.config.BreakerCooldown,
+		}).Error("LLM provider keeps failing, opening circuit breaker")
+	}
+}
+
+// release ends a trial call without judging the provider
+func (g *GuardedModel) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.trial = false
+}
+
+// trackStreaming wraps the streaming function of options, if any, to report
+// whether any chunk was delivered
+func trackStreaming(options []llms.CallOption, streamed *bool) []llms.CallOption {
+	var resolved llms.CallOptions
+	for _, opt := range options {
+		opt(&resolved)
+	}
+	if resolved.StreamingFunc == nil {
+		return options
+	}
+
+	fn := resolved.StreamingFunc
+	return append(options[:len(options):len(options)], llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		*streamed = true
+		return fn(ctx, chunk)
+	}))
+}
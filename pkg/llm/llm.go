@@ -9,6 +9,11 @@ type LLM interface {
 	Generate(ctx context.Context, prompt string, opts ...Option) (string, error)
 }
 
+// Embedder turns texts into embedding vectors, one per text
+type Embedder interface {
+	CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error)
+}
+
 // Option defines functional options for LLM configuration
 type Option func(*Options)
 
@@ -17,6 +22,8 @@ type Options struct {
 	Temperature float64
 	MaxTokens   int
 	Model       string
+	// StreamingFunc, when set, receives the completion chunk by chunk
+	StreamingFunc StreamFunc
 }
 
 // WithTemperature sets the temperature for generation
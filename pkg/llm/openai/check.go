@@ -0,0 +1,52 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultBaseURL is the OpenAI API, used when the config names no other
+const defaultBaseURL = "https://api.openai.com/v1"
+
+// checkClient retrieves the model for CheckKey. Its timeout bounds the check
+// even when ctx has no deadline.
+var checkClient = &http.Client{Timeout: 10 * time.Second}
+
+// CheckKey verifies that the API key is accepted and can use the configured
+// model by retrieving the model, which costs no tokens
+func CheckKey(ctx context.Context, config *OpenAIConfig) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	endpoint := strings.TrimRight(config.BaseURL, "/") + "/models/" + url.PathEscape(config.Model)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+config.APIKey)
+
+	resp, err := checkClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return fmt.Errorf("API key was rejected")
+	case http.StatusNotFound:
+		return fmt.Errorf("model %q is not available to this API key", config.Model)
+	default:
+		return fmt.Errorf("unexpected status checking API key: %s", resp.Status)
+	}
+}
@@ -2,9 +2,10 @@ package openai
 
 import (
 	"fmt"
-	"os"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,31 +15,97 @@ type OpenAIConfig struct {
 	Temperature float64
 	MaxTokens   int
 	Model       string
+	// BaseURL is the OpenAI-compatible API; defaults to OpenAI's
+	BaseURL string
+	// EmbeddingModel creates embeddings, e.g. to pick similar reply examples
+	EmbeddingModel string
+
+	// Timeout limits each call; a timed out call is retried TimeoutRetries
+	// times with fewer max tokens
+	Timeout        time.Duration
+	TimeoutRetries int
+	// BreakerThreshold consecutive failures stop calls for BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// FineTunedModels maps a generator, e.g. GeneratorReplies, to the
+	// fine-tuned model it uses instead of Model
+	FineTunedModels map[string]string
+	// FineTuneFallback retries failed fine-tuned calls on Model and keeps the
+	// generator on Model for FineTuneCooldown
+	FineTuneFallback bool
+	FineTuneCooldown time.Duration
+
+	// Routing picks the model of replies and judgments; routing is off
+	// without a cheap model
+	Routing llm.RoutingPolicy
+	// Prices estimate the cost of the usage of each model
+	Prices map[string]llm.ModelPrice
 }
 
 // NewOpenAIConfig creates a new OpenAIConfig with OpenAI-specific values from environment variables
 func NewOpenAIConfig() (*OpenAIConfig, error) {
-	// Load .env file if it exists
-	if err := godotenv.Load(); err != nil {
-		// It's okay if .env doesn't exist in production
-		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("error loading .env file: %w", err)
-		}
+	cfg, err := config.FromEnv()
+	if err != nil {
+		return nil, err
 	}
 
-	config := &OpenAIConfig{
-		APIKey:      os.Getenv("OPENAI_API_KEY"),
-		Model:       os.Getenv("OPENAI_MODEL"),
-		Temperature: 0.7,
-		MaxTokens:   1000,
-		Logger:      logrus.New(),
+	return NewOpenAIConfigFrom(cfg.OpenAI, logrus.New())
+}
+
+// NewOpenAIConfigFrom creates an OpenAIConfig from the central agent configuration
+func NewOpenAIConfigFrom(settings config.OpenAIConfig, logger *logrus.Logger) (*OpenAIConfig, error) {
+	openaiConfig := &OpenAIConfig{
+		APIKey:      settings.APIKey,
+		Model:       settings.Model,
+		Temperature: settings.Temperature,
+		MaxTokens:   settings.MaxTokens,
+		Logger:      logger,
+		BaseURL:     settings.BaseURL,
+
+		EmbeddingModel: settings.EmbeddingModel,
+
+		Timeout:          settings.Timeout,
+		TimeoutRetries:   settings.TimeoutRetries,
+		BreakerThreshold: settings.BreakerThreshold,
+		BreakerCooldown:  settings.BreakerCooldown,
+
+		FineTuneFallback: settings.FineTuned.Fallback,
+		FineTuneCooldown: settings.FineTuned.FallbackCooldown,
+
+		Routing: llm.RoutingPolicy{
+			CheapModel:       settings.Routing.CheapModel,
+			StrongModel:      settings.Routing.StrongModel,
+			MaxCheapThread:   settings.Routing.MaxCheapThread,
+			StrongCategories: settings.Routing.StrongCategories,
+			StrongFollowers:  settings.Routing.StrongFollowers,
+		},
+	}
+	for model, price := range settings.Prices {
+		if openaiConfig.Prices == nil {
+			openaiConfig.Prices = make(map[string]llm.ModelPrice)
+		}
+		openaiConfig.Prices[model] = llm.ModelPrice{Prompt: price.Prompt, Completion: price.Completion}
+	}
+	fineTuned := map[string]string{
+		GeneratorReplies:   settings.FineTuned.Replies,
+		GeneratorThoughts:  settings.FineTuned.Thoughts,
+		GeneratorJudgments: settings.FineTuned.Judgments,
+	}
+	for generator, modelID := range fineTuned {
+		if modelID != "" {
+			if openaiConfig.FineTunedModels == nil {
+				openaiConfig.FineTunedModels = make(map[string]string)
+			}
+			openaiConfig.FineTunedModels[generator] = modelID
+		}
 	}
 
-	if err := config.Validate(); err != nil {
+	if err := openaiConfig.Validate(); err != nil {
 		return nil, err
 	}
 
-	return config, nil
+	return openaiConfig, nil
 }
 
 func (c *OpenAIConfig) Validate() error {
@@ -61,5 +128,8 @@ func (c *OpenAIConfig) Validate() error {
 	if c.Model == "" {
 		c.Model = "gpt-4"
 	}
+	if c.BaseURL == "" {
+		c.BaseURL = defaultBaseURL
+	}
 	return nil
 }
@@ -0,0 +1,114 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Generators that can use a fine-tuned model instead of the base model
+const (
+	GeneratorReplies   = "replies"
+	GeneratorThoughts  = "thoughts"
+	GeneratorJudgments = "judgments"
+)
+
+// DefaultFineTuneCooldown is how long a generator stays on the base model
+// after its fine-tuned model failed
+const DefaultFineTuneCooldown = 10 * time.Minute
+
+// FineTunedModel sends every call to a fine-tuned model. When fallback is on,
+// a call the fine-tuned model fails is retried on the base model, and the
+// generator stays on the base model for the cooldown, so a broken or deleted
+// fine-tune costs one failed call per cooldown rather than one per generation.
+type FineTunedModel struct {
+	base     llms.Model
+	modelID  string
+	fallback bool
+	cooldown time.Duration
+	logger   *logrus.Logger
+
+	mu        sync.Mutex
+	baseUntil time.Time
+}
+
+// NewFineTunedModel routes the calls made through base to modelID
+func NewFineTunedModel(base llms.Model, modelID string, fallback bool, cooldown time.Duration, logger *logrus.Logger) *FineTunedModel {
+	if cooldown <= 0 {
+		cooldown = DefaultFineTuneCooldown
+	}
+	return &FineTunedModel{
+		base:     base,
+		modelID:  modelID,
+		fallback: fallback,
+		cooldown: cooldown,
+		logger:   logger,
+	}
+}
+
+// ModelID returns the fine-tuned model calls are sent to
+func (m *FineTunedModel) ModelID() string {
+	return m.modelID
+}
+
+// GenerateContent implements llms.Model
+func (m *FineTunedModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	if m.onBase() {
+		return m.base.GenerateContent(ctx, messages, options...)
+	}
+
+	var streamed bool
+	opts := append(options[:len(options):len(options)], llms.WithModel(m.modelID))
+	opts = trackStreaming(opts, &streamed)
+	resp, err := m.base.GenerateContent(ctx, messages, opts...)
+	if err == nil || !m.fallback || ctx.Err() != nil || streamed || errors.Is(err, llm.ErrCircuitOpen) {
+		// Nothing to gain from the base model when the caller gave up, the
+		// provider is down or chunks already reached the caller
+		return resp, err
+	}
+
+	m.mu.Lock()
+	m.baseUntil = time.Now().Add(m.cooldown)
+	m.mu.Unlock()
+	m.logger.WithError(err).WithFields(logrus.Fields{
+		"model":    m.modelID,
+		"cooldown": m.cooldown,
+	}).Warn("Fine-tuned model failed, falling back to the base model")
+
+	return m.base.GenerateContent(ctx, messages, options...)
+}
+
+// Call implements llms.Model
+func (m *FineTunedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// onBase reports whether calls go to the base model after a recent failure
+func (m *FineTunedModel) onBase() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return time.Now().Before(m.baseUntil)
+}
+
+// trackStreaming wraps the streaming function of options, if any, to record
+// whether a chunk was delivered
+func trackStreaming(options []llms.CallOption, streamed *bool) []llms.CallOption {
+	var resolved llms.CallOptions
+	for _, opt := range options {
+		opt(&resolved)
+	}
+	if resolved.StreamingFunc == nil {
+		return options
+	}
+
+	fn := resolved.StreamingFunc
+	return append(options[:len(options):len(options)], llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		*streamed = true
+		return fn(ctx, chunk)
+	}))
+}
@@ -11,9 +11,15 @@ import (
 )
 
 type OpenAIClient struct {
-	logger *logrus.Logger
-	llm    llms.Model
-	config *OpenAIConfig
+	logger   *logrus.Logger
+	llm      llms.Model
+	embedder llm.Embedder
+	config   *OpenAIConfig
+	// fineTuned holds the model of each generator with a fine-tune
+	fineTuned map[string]*FineTunedModel
+	// router picks the model of replies and judgments, nil without routing
+	router *llm.Router
+	usage  *llm.UsageTracker
 }
 
 // GetLLM returns the underlying LangChain LLM model
@@ -21,23 +27,96 @@ func (c *OpenAIClient) GetLLM() llms.Model {
 	return c.llm
 }
 
+// ModelFor returns the model a generator uses: its fine-tuned model when one
+// is configured, otherwise the routed model for replies and judgments when
+// routing is on, otherwise the base model
+func (c *OpenAIClient) ModelFor(generator string) llms.Model {
+	if model, ok := c.fineTuned[generator]; ok {
+		return model
+	}
+	if c.router != nil && routed(generator) {
+		return c.router
+	}
+	return c.llm
+}
+
+// Usage returns the calls and tokens of every model used so far
+func (c *OpenAIClient) Usage() []llm.ModelUsage {
+	return c.usage.Usage()
+}
+
+// routed reports whether the router picks the model of generator
+func routed(generator string) bool {
+	return generator == GeneratorReplies || generator == GeneratorJudgments
+}
+
+// Embedder returns the client creating embeddings with the configured
+// embedding model
+func (c *OpenAIClient) Embedder() llm.Embedder {
+	return c.embedder
+}
+
 func NewOpenAIClient(config *OpenAIConfig) (*OpenAIClient, error) {
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	llm, err := openai.New(
+	model, err := openai.New(
 		openai.WithToken(config.APIKey),
+		openai.WithBaseURL(config.BaseURL),
 		openai.WithModel(config.Model),
+		openai.WithEmbeddingModel(config.EmbeddingModel),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize OpenAI: %w", err)
 	}
 
+	// Usage is accounted below the guard, so retried calls are counted too
+	usage := llm.NewUsageTracker(model, config.Model, config.Prices)
+
+	// Every caller of the model shares the timeouts and the circuit breaker
+	guarded := llm.NewGuardedModel(usage, llm.GuardConfig{
+		Timeout:          config.Timeout,
+		TimeoutRetries:   config.TimeoutRetries,
+		DefaultMaxTokens: config.MaxTokens,
+		BreakerThreshold: config.BreakerThreshold,
+		BreakerCooldown:  config.BreakerCooldown,
+		Logger:           config.Logger,
+	})
+
+	var router *llm.Router
+	if config.Routing.CheapModel != "" {
+		router = llm.NewRouter(guarded, config.Routing, config.Logger)
+		config.Logger.WithFields(logrus.Fields{
+			"cheap_model":  config.Routing.CheapModel,
+			"strong_model": config.Routing.StrongModel,
+		}).Info("Routing replies between models")
+	}
+
+	// Fine-tuned calls go through the same guard as base model calls, and a
+	// failed fine-tuned reply falls back to the routed model
+	fineTuned := make(map[string]*FineTunedModel, len(config.FineTunedModels))
+	for generator, modelID := range config.FineTunedModels {
+		var base llms.Model = guarded
+		if router != nil && routed(generator) {
+			base = router
+		}
+		fineTuned[generator] = NewFineTunedModel(base, modelID, config.FineTuneFallback, config.FineTuneCooldown, config.Logger)
+		config.Logger.WithFields(logrus.Fields{
+			"generator": generator,
+			"model":     modelID,
+			"fallback":  config.FineTuneFallback,
+		}).Info("Using fine-tuned model")
+	}
+
 	return &OpenAIClient{
-		logger: config.Logger,
-		llm:    llm,
-		config: config,
+		logger:    config.Logger,
+		llm:       guarded,
+		embedder:  model,
+		config:    config,
+		fineTuned: fineTuned,
+		router:    router,
+		usage:     usage,
 	}, nil
 }
 
@@ -66,12 +145,18 @@ func (c *OpenAIClient) Generate(ctx context.Context, prompt string, opts ...llm.
 		"temperature": options.Temperature,
 		"maxTokens":   options.MaxTokens,
 		"model":       options.Model,
+		"streaming":   options.StreamingFunc != nil,
 	}).Debug("Generating completion")
 
-	completion, err := c.llm.Call(ctx, prompt,
+	callOpts := []llms.CallOption{
 		llms.WithTemperature(options.Temperature),
 		llms.WithMaxTokens(options.MaxTokens),
-	)
+	}
+	if options.StreamingFunc != nil {
+		callOpts = append(callOpts, llms.WithStreamingFunc(options.StreamingFunc))
+	}
+
+	completion, err := c.llm.Call(ctx, prompt, callOpts...)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate completion: %w", err)
 	}
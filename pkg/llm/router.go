@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Route describes a generation for the router to pick a model by
+type Route struct {
+	// Category is the category of the tweet answered
+	Category string
+	// ThreadLength counts the tweets of the conversation so far, including
+	// the one answered
+	ThreadLength int
+	// AuthorFollowers is the follower count of the author answered
+	AuthorFollowers int
+}
+
+type routeKey struct{}
+
+// WithRoute attaches route to the context of a generation
+func WithRoute(ctx context.Context, route Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFrom returns the route attached to ctx, if any
+func RouteFrom(ctx context.Context) (Route, bool) {
+	route, ok := ctx.Value(routeKey{}).(Route)
+	return route, ok
+}
+
+// RoutingPolicy decides which generations a cheap model can handle
+type RoutingPolicy struct {
+	// CheapModel answers short threads of ordinary authors
+	CheapModel string
+	// StrongModel answers everything else; empty keeps the model's default
+	StrongModel string
+	// MaxCheapThread is the longest thread the cheap model answers
+	MaxCheapThread int
+	// StrongCategories always go to the strong model
+	StrongCategories []string
+	// StrongFollowers sends authors with at least this many followers to the
+	// strong model; 0 ignores follower counts
+	StrongFollowers int
+}
+
+// Router wraps an llms.Model and sends each call to the cheap or the strong
+// model of its policy. Calls without a route, e.g. judgments, go to the
+// strong model. A model the caller or a wrapping fine-tune asks for takes
+// precedence, since the router's choice is added before their options.
+type Router struct {
+	model  llms.Model
+	policy RoutingPolicy
+	logger *logrus.Logger
+}
+
+// NewRouter routes the calls made through model by policy
+func NewRouter(model llms.Model, policy RoutingPolicy, logger *logrus.Logger) *Router {
+	return &Router{model: model, policy: policy, logger: logger}
+}
+
+// Choose returns the model for a call made with ctx; empty means the
+// model's default
+func (r *Router) Choose(ctx context.Context) string {
+	route, ok := RouteFrom(ctx)
+	if !ok || r.policy.CheapModel == "" {
+		return r.policy.StrongModel
+	}
+	if route.ThreadLength > r.policy.MaxCheapThread {
+		return r.policy.StrongModel
+	}
+	if r.policy.StrongFollowers > 0 && route.AuthorFollowers >= r.policy.StrongFollowers {
+		return r.policy.StrongModel
+	}
+	for _, category := range r.policy.StrongCategories {
+		if category == route.Category {
+			return r.policy.StrongModel
+		}
+	}
+	return r.policy.CheapModel
+}
+
+// GenerateContent implements llms.Model
+func (r *Router) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	model := r.Choose(ctx)
+	if model == "" {
+		return r.model.GenerateContent(ctx, messages, options...)
+	}
+	r.logger.WithField("model", model).Debug("Routing generation")
+
+	// Prepended, so an explicit model of the caller still wins
+	opts := append([]llms.CallOption{llms.WithModel(model)}, options...)
+	return r.model.GenerateContent(ctx, messages, opts...)
+}
+
+// Call implements llms.Model
+func (r *Router) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, r, prompt, options...)
+}
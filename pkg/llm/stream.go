@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// StreamFunc receives each chunk of a completion as it is generated.
+// Returning an error aborts the stream.
+type StreamFunc func(ctx context.Context, chunk []byte) error
+
+// WithStreamingFunc streams the completion through fn as it is generated
+func WithStreamingFunc(fn StreamFunc) Option {
+	return func(o *Options) {
+		o.StreamingFunc = fn
+	}
+}
+
+// StreamResult is the outcome of a limited streaming call
+type StreamResult struct {
+	// Text is the generated text, which may run past the limit by up to one chunk
+	Text string
+	// CutOff reports whether the stream was stopped at the limit before it finished
+	CutOff bool
+}
+
+// StreamWithLimit streams a completion from model and stops it once maxChars
+// characters, as measured by length, have been received, saving the tokens and
+// latency of the rest of the completion. A nil length counts runes. The caller
+// is expected to trim the text to a clean boundary. A non-positive maxChars
+// streams the whole completion.
+func StreamWithLimit(ctx context.Context, model llms.Model, prompt string, maxChars int, length func(string) int, opts ...llms.CallOption) (StreamResult, error) {
+	if length == nil {
+		length = utf8.RuneCountInString
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		text   strings.Builder
+		cutOff bool
+	)
+
+	onChunk := func(_ context.Context, chunk []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if cutOff {
+			return nil
+		}
+		text.Write(chunk)
+		if maxChars > 0 && length(text.String()) >= maxChars {
+			// Cancel rather than return an error so the client drains and closes
+			// the response body instead of leaving its reader blocked
+			cutOff = true
+			cancel()
+		}
+		return nil
+	}
+
+	completion, err := model.Call(streamCtx, prompt, append(opts, llms.WithStreamingFunc(onChunk))...)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if cutOff {
+		return StreamResult{Text: text.String(), CutOff: true}, nil
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			return StreamResult{}, ctx.Err()
+		}
+		return StreamResult{}, err
+	}
+	return StreamResult{Text: completion}, nil
+}
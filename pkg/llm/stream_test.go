@@ -0,0 +1,133 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// streamedReply is long enough to run past every limit below, with a ZWJ
+// sequence and a URL, which weigh less than their runes, for cuts to land in
+const streamedReply = "gm 👨‍👩‍👧 peasants, bow before https://laffy.xyz/the/throne/of/the/cat/lord forever and ever"
+
+// chunkedModel streams its answer in chunks of a few bytes, splitting runes
+// across chunks like a byte-level tokenizer, and counts the chunks it sent
+type chunkedModel struct {
+	answer string
+	size   int
+	sent   int
+}
+
+func (m *chunkedModel) GenerateContent(ctx context.Context, _ []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		for start := 0; start < len(m.answer); start += m.size {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			end := min(start+m.size, len(m.answer))
+			m.sent++
+			if err := opts.StreamingFunc(ctx, []byte(m.answer[start:end])); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: m.answer}}}, nil
+}
+
+func (m *chunkedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestStreamWithLimitCutsOff(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  string
+	}{
+		{"inside a ZWJ sequence", 4, "gm"},
+		{"inside a word", 10, "gm 👨‍👩‍👧"},
+		{"right after a word", 15, "gm 👨‍👩‍👧 peasants,"},
+		{"inside a URL", 35, "gm 👨‍👩‍👧 peasants, bow before"},
+		{"after a URL", 50, "gm 👨‍👩‍👧 peasants, bow before https://laffy.xyz/the/throne/of/the/cat/lord"},
+		{"further along", 60, "gm 👨‍👩‍👧 peasants, bow before https://laffy.xyz/the/throne/of/the/cat/lord forever"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Like the reply generators, stream one weighted character past
+			// the limit and trim what was streamed
+			model := &chunkedModel{answer: streamedReply, size: 3}
+			result, err := llm.StreamWithLimit(context.Background(), model, "Reply:", tt.limit+1, thoughts.WeightedLength)
+			if err != nil {
+				t.Fatalf("StreamWithLimit: %v", err)
+			}
+			if !result.CutOff {
+				t.Fatal("stream was not cut off")
+			}
+			if total := (len(streamedReply) + 2) / 3; model.sent >= total {
+				t.Errorf("model streamed all %d chunks", total)
+			}
+			if !strings.HasPrefix(streamedReply, result.Text) {
+				t.Errorf("streamed text %q is not a prefix of the reply", result.Text)
+			}
+			if thoughts.WeightedLength(result.Text) <= tt.limit {
+				t.Errorf("stream stopped at %d characters, within the limit of %d", thoughts.WeightedLength(result.Text), tt.limit)
+			}
+
+			got := thoughts.TruncateText(result.Text, tt.limit)
+			if got != tt.want {
+				t.Errorf("trimmed text = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) || thoughts.WeightedLength(got) > tt.limit {
+				t.Errorf("trimmed text %q is invalid or over the limit", got)
+			}
+			if rest := strings.TrimPrefix(streamedReply, got); rest == streamedReply || !strings.HasPrefix(rest, " ") {
+				t.Errorf("trimmed text %q splits a word or grapheme of the reply", got)
+			}
+		})
+	}
+}
+
+func TestStreamWithLimitCountsRunes(t *testing.T) {
+	model := &chunkedModel{answer: streamedReply, size: 3}
+	result, err := llm.StreamWithLimit(context.Background(), model, "Reply:", 20, nil)
+	if err != nil {
+		t.Fatalf("StreamWithLimit: %v", err)
+	}
+	if !result.CutOff || !strings.HasPrefix(streamedReply, result.Text) {
+		t.Fatalf("StreamWithLimit = %+v, want a cut-off prefix of the reply", result)
+	}
+	// Stopped within a chunk of the limit
+	if runes := utf8.RuneCountInString(result.Text); runes < 20 || runes > 20+3 {
+		t.Errorf("stream stopped at %d runes for a limit of 20", runes)
+	}
+}
+
+func TestStreamWithLimitFinishesUnderLimit(t *testing.T) {
+	model := &chunkedModel{answer: "gm, peasant", size: 3}
+	result, err := llm.StreamWithLimit(context.Background(), model, "Reply:", 280, nil)
+	if err != nil {
+		t.Fatalf("StreamWithLimit: %v", err)
+	}
+	if result.CutOff || result.Text != "gm, peasant" {
+		t.Errorf("StreamWithLimit = %+v, want the whole reply", result)
+	}
+}
+
+func TestStreamWithLimitCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	model := &chunkedModel{answer: streamedReply, size: 3}
+	if _, err := llm.StreamWithLimit(ctx, model, "Reply:", 10, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("StreamWithLimit error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ModelPrice is what a model costs in USD per million tokens
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// ModelUsage is what the calls to one model used
+type ModelUsage struct {
+	Model            string  `json:"model"`
+	Calls            int64   `json:"calls"`
+	Errors           int64   `json:"errors"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// UsageTracker wraps an llms.Model and accounts the calls and tokens of each
+// model the calls ask for. Calls that name no model are accounted to the
+// default model. Place it below retries and fallbacks, so every call that
+// reaches the provider is counted.
+type UsageTracker struct {
+	model        llms.Model
+	defaultModel string
+	prices       map[string]ModelPrice
+
+	mu    sync.Mutex
+	usage map[string]*ModelUsage
+}
+
+// NewUsageTracker accounts the calls made through model; prices, which may be
+// nil, estimate their cost
+func NewUsageTracker(model llms.Model, defaultModel string, prices map[string]ModelPrice) *UsageTracker {
+	return &UsageTracker{
+		model:        model,
+		defaultModel: defaultModel,
+		prices:       prices,
+		usage:        make(map[string]*ModelUsage),
+	}
+}
+
+// GenerateContent implements llms.Model
+func (t *UsageTracker) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+	model := opts.Model
+	if model == "" {
+		model = t.defaultModel
+	}
+
+	resp, err := t.model.GenerateContent(ctx, messages, options...)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage, ok := t.usage[model]
+	if !ok {
+		usage = &ModelUsage{Model: model}
+		t.usage[model] = usage
+	}
+	usage.Calls++
+	if err != nil {
+		usage.Errors++
+		return resp, err
+	}
+	// Every choice reports the usage of the whole call
+	if resp != nil && len(resp.Choices) > 0 {
+		prompt, completion := tokenCounts(resp.Choices[0].GenerationInfo)
+		usage.PromptTokens += prompt
+		usage.CompletionTokens += completion
+	}
+	return resp, nil
+}
+
+// Call implements llms.Model
+func (t *UsageTracker) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, t, prompt, options...)
+}
+
+// Usage returns the usage of every model called so far, ordered by model
+func (t *UsageTracker) Usage() []ModelUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := make([]ModelUsage, 0, len(t.usage))
+	for model, counted := range t.usage {
+		snapshot := *counted
+		if price, ok := t.prices[model]; ok {
+			snapshot.CostUSD = (float64(snapshot.PromptTokens)*price.Prompt +
+				float64(snapshot.CompletionTokens)*price.Completion) / 1e6
+		}
+		usage = append(usage, snapshot)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Model < usage[j].Model })
+	return usage
+}
+
+// tokenCounts reads the token counts providers report in the generation info
+func tokenCounts(info map[string]any) (prompt, completion int64) {
+	return tokenCount(info["PromptTokens"]), tokenCount(info["CompletionTokens"])
+}
+
+// tokenCount converts a reported token count, which providers give as
+// different integer types
+func tokenCount(value any) int64 {
+	switch n := value.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
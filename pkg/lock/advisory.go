@@ -0,0 +1,75 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// unlockTimeout bounds releasing a lock, which runs even after shutdown
+const unlockTimeout = 5 * time.Second
+
+// Advisory takes Postgres session-level advisory locks. Each held lock pins one
+// pooled connection until it is released; if that connection dies, Postgres
+// drops the lock with it, so a crashed instance never blocks the others.
+type Advisory struct {
+	db     *sql.DB
+	logger *logrus.Logger
+}
+
+// NewAdvisory creates an advisory locker on db
+func NewAdvisory(db *sql.DB, logger *logrus.Logger) *Advisory {
+	return &Advisory{db: db, logger: logger}
+}
+
+// TryLock implements Locker
+func (a *Advisory) TryLock(ctx context.Context, key string) (Release, bool, error) {
+	conn, err := a.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reserve connection for lock %q: %w", key, err)
+	}
+
+	id := keyID(key)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to acquire lock %q: %w", key, err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() { a.unlock(conn, key, id) })
+	}
+	return release, true, nil
+}
+
+// unlock releases the lock held by conn and returns conn to the pool
+func (a *Advisory) unlock(conn *sql.Conn, key string, id int64) {
+	unlockCtx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+	defer cancel()
+
+	if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", id); err != nil {
+		a.logger.WithError(err).WithField("key", key).Warn("Failed to release advisory lock, dropping its connection")
+		// Discard the session instead of returning it to the pool still
+		// holding the lock; closing it releases the lock server-side
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+}
+
+// keyID maps a key onto the bigint space of advisory locks
+func keyID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,43 @@
+package lock
+
+import (
+	"context"
+	"sync"
+)
+
+// Local holds locks in process memory. It serializes work within one process
+// only, which is all a SQLite deployment can run.
+type Local struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+// NewLocal creates an in-process locker
+func NewLocal() *Local {
+	return &Local{held: make(map[string]bool)}
+}
+
+// TryLock implements Locker
+func (l *Local) TryLock(ctx context.Context, key string) (Release, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held[key] {
+		return nil, false, nil
+	}
+	l.held[key] = true
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			l.mu.Lock()
+			delete(l.held, key)
+			l.mu.Unlock()
+		})
+	}
+	return release, true, nil
+}
@@ -0,0 +1,42 @@
+// Package lock coordinates agent instances that share one database, so only
+// one of them works on a given conversation or poll at a time.
+package lock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Release gives up a held lock. It is safe to call after ctx was cancelled.
+type Release func()
+
+// Locker hands out exclusive locks on string keys
+type Locker interface {
+	// TryLock acquires key without waiting. ok is false when another holder
+	// already has the key.
+	TryLock(ctx context.Context, key string) (release Release, ok bool, err error)
+}
+
+// New returns Postgres advisory locks, shared by every instance connected to
+// the same database, or in-process locks for SQLite, which only one process
+// can serve anyway
+func New(database *gorm.DB, logger *logrus.Logger) (Locker, error) {
+	if database.Dialector.Name() != "postgres" {
+		return NewLocal(), nil
+	}
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection for locks: %w", err)
+	}
+	return NewAdvisory(sqlDB, logger), nil
+}
+
+// Key joins parts into a lock key, e.g. Key("reply", botID, conversationID)
+func Key(parts ...string) string {
+	return strings.Join(parts, ":")
+}
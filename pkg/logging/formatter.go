@@ -9,9 +9,13 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/lisanmuaddib/agent-go/pkg/logging/redact"
 	"github.com/sirupsen/logrus"
 )
 
+// ColoredJSONFormatter writes entries as colored lines for terminals. Field
+// values and messages are passed through redact, so logging a config struct
+// does not leak its credentials.
 type ColoredJSONFormatter struct {
 	// Include timestamp in the output
 	TimestampFormat string
@@ -31,12 +35,12 @@ func NewColoredJSONFormatter() *ColoredJSONFormatter {
 func (f *ColoredJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	data := make(logrus.Fields)
 	for k, v := range entry.Data {
-		data[k] = v
+		data[k] = redact.Field(k, v)
 	}
 
 	// Add standard fields
 	data["level"] = entry.Level.String()
-	data["msg"] = entry.Message
+	data["msg"] = redact.String(entry.Message)
 	data["time"] = entry.Time.Format(f.TimestampFormat)
 
 	// Get field keys for sorting
@@ -0,0 +1,178 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/logging/redact"
+	"github.com/sirupsen/logrus"
+)
+
+// Log output formats selectable with LOG_FORMAT
+const (
+	// FormatText is the colored line format meant for terminals
+	FormatText = "text"
+	// FormatJSON writes one JSON object per line for log collectors
+	FormatJSON = "json"
+)
+
+// Standard field names of the JSON format. They follow the OpenTelemetry log
+// data model so collectors can map them without per-service configuration.
+const (
+	FieldTimestamp      = "timestamp"
+	FieldLevel          = "level"
+	FieldSeverityNumber = "severity_number"
+	FieldMessage        = "message"
+	FieldTraceID        = "trace_id"
+	FieldSpanID         = "span_id"
+	FieldCaller         = "caller"
+	FieldService        = "service.name"
+)
+
+// TraceExtractor returns the trace and span IDs of the span active in ctx, as
+// lowercase hex, or empty strings when there is none
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+// traceKey is the context key of the IDs stored by ContextWithTrace
+type traceKey struct{}
+
+type traceIDs struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithTrace returns a context whose log entries carry the given trace
+// and span IDs, for code paths not instrumented with a tracer
+func ContextWithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceIDs{traceID: traceID, spanID: spanID})
+}
+
+// StartTrace returns a context whose log entries carry a new random trace
+// ID, sized like a W3C trace context, and a new span ID. Request handlers,
+// supervised actions and ticks of periodic actions start one, so every entry
+// logged through WithContext on their behalf can be correlated.
+func StartTrace(ctx context.Context) context.Context {
+	return ContextWithTrace(ctx, randomHex(16), randomHex(8))
+}
+
+// randomHex returns n random bytes as lowercase hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TraceFromContext is the default TraceExtractor; it reads the IDs stored by
+// ContextWithTrace
+func TraceFromContext(ctx context.Context) (string, string) {
+	if ids, ok := ctx.Value(traceKey{}).(traceIDs); ok {
+		return ids.traceID, ids.spanID
+	}
+	return "", ""
+}
+
+// JSONFormatter writes each entry as a single JSON object with the standard
+// field names above and credentials masked. Entries logged with WithContext
+// carry the trace and span IDs of the context. With OpenTelemetry, set Trace
+// to read the active span:
+//
+//	formatter.Trace = func(ctx context.Context) (string, string) {
+//	    sc := trace.SpanContextFromContext(ctx)
+//	    if !sc.IsValid() {
+//	        return "", ""
+//	    }
+//	    return sc.TraceID().String(), sc.SpanID().String()
+//	}
+type JSONFormatter struct {
+	// Service is added as service.name when set
+	Service string
+	// Trace extracts trace IDs from entry contexts; defaults to TraceFromContext
+	Trace TraceExtractor
+
+	json *logrus.JSONFormatter
+}
+
+// NewJSONFormatter creates a JSON formatter with UTC nanosecond timestamps
+func NewJSONFormatter(service string) *JSONFormatter {
+	return &JSONFormatter{
+		Service: service,
+		Trace:   TraceFromContext,
+		json: &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime:  FieldTimestamp,
+				logrus.FieldKeyLevel: FieldLevel,
+				logrus.FieldKeyMsg:   FieldMessage,
+				logrus.FieldKeyFunc:  FieldCaller,
+			},
+		},
+	}
+}
+
+// Format implements logrus.Formatter
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data)+4)
+	for k, v := range entry.Data {
+		// Fields named like the standard ones would overwrite them
+		switch k {
+		case FieldSeverityNumber, FieldTraceID, FieldSpanID, FieldService:
+			k = "fields." + k
+		}
+		data[k] = redact.Field(k, v)
+	}
+
+	data[FieldSeverityNumber] = severityNumber(entry.Level)
+	if f.Service != "" {
+		data[FieldService] = f.Service
+	}
+	if entry.Context != nil && f.Trace != nil {
+		if traceID, spanID := f.Trace(entry.Context); traceID != "" {
+			data[FieldTraceID] = traceID
+			if spanID != "" {
+				data[FieldSpanID] = spanID
+			}
+		}
+	}
+
+	// Format a copy so hooks and other formatters see the original entry
+	formatted := *entry
+	formatted.Data = data
+	formatted.Message = redact.String(entry.Message)
+	formatted.Time = entry.Time.UTC()
+	return f.json.Format(&formatted)
+}
+
+// severityNumber maps a logrus level to the OpenTelemetry severity number
+func severityNumber(level logrus.Level) int {
+	switch level {
+	case logrus.TraceLevel:
+		return 1
+	case logrus.DebugLevel:
+		return 5
+	case logrus.InfoLevel:
+		return 9
+	case logrus.WarnLevel:
+		return 13
+	case logrus.ErrorLevel:
+		return 17
+	default: // fatal and panic
+		return 21
+	}
+}
+
+// NewFormatter returns the formatter for a LOG_FORMAT value: the colored
+// format for "text" or an empty value, JSON for "json"
+func NewFormatter(format, service string) (logrus.Formatter, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatText:
+		return NewColoredJSONFormatter(), nil
+	case FormatJSON:
+		return NewJSONFormatter(service), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected %s or %s", format, FormatText, FormatJSON)
+	}
+}
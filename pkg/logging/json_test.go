@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logJSON runs log with a logger writing JSON and decodes the line written
+func logJSON(t *testing.T, log func(*logrus.Logger)) map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(NewJSONFormatter("agent-go"))
+	log(logger)
+
+	var entry map[string]any
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("output is not a JSON object: %v\n%s", err, out.String())
+	}
+	return entry
+}
+
+func TestJSONFormatterTrace(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name   string
+		log    func(*logrus.Logger)
+		fields map[string]any
+		absent []string
+	}{
+		{
+			name: "entry logged with a traced context",
+			log: func(l *logrus.Logger) {
+				l.WithContext(ContextWithTrace(context.Background(), traceID, spanID)).WithField("tweet_id", "1").Info("Replied")
+			},
+			fields: map[string]any{FieldTraceID: traceID, FieldSpanID: spanID, "tweet_id": "1", FieldMessage: "Replied"},
+		},
+		{
+			name: "trace without a span",
+			log: func(l *logrus.Logger) {
+				l.WithContext(ContextWithTrace(context.Background(), traceID, "")).Info("Replied")
+			},
+			fields: map[string]any{FieldTraceID: traceID},
+			absent: []string{FieldSpanID},
+		},
+		{
+			name:   "entry logged without a context",
+			log:    func(l *logrus.Logger) { l.Info("Starting agent") },
+			absent: []string{FieldTraceID, FieldSpanID},
+		},
+		{
+			name:   "context without a trace",
+			log:    func(l *logrus.Logger) { l.WithContext(context.Background()).Info("Starting agent") },
+			absent: []string{FieldTraceID, FieldSpanID},
+		},
+		{
+			name: "field named like the trace ID",
+			log: func(l *logrus.Logger) {
+				l.WithContext(ContextWithTrace(context.Background(), traceID, spanID)).WithField(FieldTraceID, "spoofed").Info("Replied")
+			},
+			fields: map[string]any{FieldTraceID: traceID, "fields." + FieldTraceID: "spoofed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := logJSON(t, tt.log)
+			for key, want := range tt.fields {
+				if entry[key] != want {
+					t.Errorf("%s = %v, want %v", key, entry[key], want)
+				}
+			}
+			for _, key := range tt.absent {
+				if value, ok := entry[key]; ok {
+					t.Errorf("%s = %v, want it absent", key, value)
+				}
+			}
+			if entry[FieldService] != "agent-go" {
+				t.Errorf("%s = %v, want agent-go", FieldService, entry[FieldService])
+			}
+		})
+	}
+}
+
+func TestStartTrace(t *testing.T) {
+	ctx := StartTrace(context.Background())
+	traceID, spanID := TraceFromContext(ctx)
+	if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(traceID) {
+		t.Errorf("trace ID = %q, want 32 lowercase hex digits", traceID)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(spanID) {
+		t.Errorf("span ID = %q, want 16 lowercase hex digits", spanID)
+	}
+
+	if next, _ := TraceFromContext(StartTrace(ctx)); next == traceID {
+		t.Errorf("StartTrace reused trace ID %s", traceID)
+	}
+
+	entry := logJSON(t, func(l *logrus.Logger) { l.WithContext(ctx).Info("Tick") })
+	if entry[FieldTraceID] != traceID || entry[FieldSpanID] != spanID {
+		t.Errorf("entry has trace %v and span %v, want %s and %s", entry[FieldTraceID], entry[FieldSpanID], traceID, spanID)
+	}
+}
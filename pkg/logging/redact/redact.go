@@ -0,0 +1,324 @@
+// Package redact masks credentials in values about to be logged. Struct
+// fields and map keys are treated as secrets when they are tagged
+// `redact:"true"` or their name looks like one (password, secret, API key,
+// access token, private key...); `redact:"false"` exempts a field. Strings are
+// also scrubbed of bearer tokens, OAuth parameters and passwords in URLs.
+package redact
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted values
+const Mask = "REDACTED"
+
+// maxDepth bounds how deep values are walked, which also stops cycles
+const maxDepth = 8
+
+// sensitiveMarkers are matched against names lowercased with separators removed
+var sensitiveMarkers = []string{
+	"secret",
+	"password",
+	"passwd",
+	"privatekey",
+	"mnemonic",
+	"seedphrase",
+	"apikey",
+	"consumerkey",
+	"accesstoken",
+	"bearertoken",
+	"refreshtoken",
+	"authtoken",
+	"sessiontoken",
+	"apitoken",
+	"authorization",
+	"credential",
+	"signeruuid",
+	"jwt",
+}
+
+// patterns match credentials inside strings; the groups around the secret are kept
+var patterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[^\s"',]+()`),
+	regexp.MustCompile(`(?i)((?:oauth_[a-z_]+|consumer_secret|access_token(?:_secret)?|token_secret|client_secret|bearer_token|api_?key|password|private_key)"?\s*[=:]\s*"?)[^&"\s,]+()`),
+	regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+(@)`),
+}
+
+// IsSensitive reports whether a field or key name looks like it holds a secret
+func IsSensitive(name string) bool {
+	normalized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return -1
+		}
+	}, name)
+
+	for _, marker := range sensitiveMarkers {
+		if strings.Contains(normalized, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks bearer tokens, OAuth parameters, API key assignments and URL
+// passwords in s
+func String(s string) string {
+	for _, pattern := range patterns {
+		s = pattern.ReplaceAllString(s, "${1}"+Mask+"${2}")
+	}
+	return s
+}
+
+// Field returns a log field value with secrets masked: the whole value when
+// the name is sensitive, otherwise whatever Value masks inside it. Errors
+// whose message holds a credential are replaced by the scrubbed message.
+func Field(name string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if IsSensitive(name) {
+		if masked, changed := mask(reflect.ValueOf(value)); changed {
+			return masked.Interface()
+		}
+		return value
+	}
+	if err, ok := value.(error); ok {
+		if scrubbed := String(err.Error()); scrubbed != err.Error() {
+			return scrubbed
+		}
+		return value
+	}
+	return Value(value)
+}
+
+// Value returns v with the sensitive fields of structs and keys of maps
+// masked, walking pointers, slices and interfaces. v itself is never
+// modified: the parts holding secrets are copied, and v is returned as is
+// when it holds none.
+func Value(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	redacted, changed := walk(reflect.ValueOf(v), 0)
+	if !changed {
+		return v
+	}
+	return redacted.Interface()
+}
+
+// walk returns a copy of v with its secrets masked, and whether there were any
+func walk(v reflect.Value, depth int) (reflect.Value, bool) {
+	if depth > maxDepth || !v.IsValid() {
+		return v, false
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		scrubbed := String(v.String())
+		if scrubbed == v.String() {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(scrubbed)
+		return out, true
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := walk(v.Elem(), depth+1)
+		if !changed {
+			return v, false
+		}
+		out := reflect.New(elem.Type())
+		out.Elem().Set(elem)
+		return out, true
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := walk(v.Elem(), depth+1)
+		if !changed {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(elem)
+		return out, true
+
+	case reflect.Struct:
+		var out reflect.Value
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			var redacted reflect.Value
+			var changed bool
+			if sensitiveField(field) {
+				redacted, changed = mask(v.Field(i))
+			} else {
+				redacted, changed = walk(v.Field(i), depth+1)
+			}
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = reflect.New(t).Elem()
+				out.Set(v)
+			}
+			out.Field(i).Set(redacted)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v, false
+		}
+		changes := make(map[string]reflect.Value)
+		iter := v.MapRange()
+		for iter.Next() {
+			var redacted reflect.Value
+			var changed bool
+			if IsSensitive(iter.Key().String()) {
+				redacted, changed = mask(iter.Value())
+			} else {
+				redacted, changed = walk(iter.Value(), depth+1)
+			}
+			if changed {
+				changes[iter.Key().String()] = redacted
+			}
+		}
+		if len(changes) == 0 {
+			return v, false
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter = v.MapRange()
+		for iter.Next() {
+			value := iter.Value()
+			if redacted, ok := changes[iter.Key().String()]; ok {
+				value = redacted
+			}
+			out.SetMapIndex(iter.Key(), value)
+		}
+		return out, true
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 || (v.Kind() == reflect.Slice && v.IsNil()) {
+			return v, false
+		}
+		var out reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			redacted, changed := walk(v.Index(i), depth+1)
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = copyList(v)
+			}
+			out.Index(i).Set(redacted)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+	}
+
+	return v, false
+}
+
+// mask replaces a secret value: strings by Mask, lists element by element and
+// anything else by its zero value. Numbers and booleans are left alone, since
+// they are flags like "api_key_set" rather than credentials.
+func mask(v reflect.Value) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(Mask)
+		return out, true
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := mask(v.Elem())
+		if !changed {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(elem)
+		return out, true
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, false
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return reflect.Zero(v.Type()), v.Len() > 0
+		}
+		var out reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			masked, changed := mask(v.Index(i))
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = copyList(v)
+			}
+			out.Index(i).Set(masked)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return v, false
+		}
+		return reflect.Zero(v.Type()), true
+
+	case reflect.Struct:
+		return reflect.Zero(v.Type()), true
+	}
+
+	return v, false
+}
+
+// sensitiveField reports whether a struct field holds a secret, honoring the
+// redact tag over the name
+func sensitiveField(field reflect.StructField) bool {
+	switch field.Tag.Get("redact") {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return IsSensitive(field.Name)
+}
+
+// copyList returns a settable copy of a slice or array
+func copyList(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Array {
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		return out
+	}
+	out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(out, v)
+	return out
+}
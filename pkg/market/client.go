@@ -0,0 +1,331 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Source identifies where a quote came from
+type Source string
+
+const (
+	SourceCoinGecko   Source = "coingecko"
+	SourceDexScreener Source = "dexscreener"
+)
+
+// knownCoinIDs maps common symbols to their CoinGecko IDs
+var knownCoinIDs = map[string]string{
+	"BTC":   "bitcoin",
+	"ETH":   "ethereum",
+	"SOL":   "solana",
+	"BNB":   "binancecoin",
+	"MATIC": "matic-network",
+	"POL":   "polygon-ecosystem-token",
+	"ARB":   "arbitrum",
+	"OP":    "optimism",
+	"USDC":  "usd-coin",
+	"USDT":  "tether",
+	"DOGE":  "dogecoin",
+	"PEPE":  "pepe",
+	"MASA":  "masa-finance",
+}
+
+// Quote is a token's current price and 24h change
+type Quote struct {
+	Symbol    string
+	PriceUSD  float64
+	Change24h float64 // percentage change over the last 24 hours
+	Source    Source
+	FetchedAt time.Time
+}
+
+type cacheEntry struct {
+	quote     *Quote
+	expiresAt time.Time
+}
+
+// Client fetches token prices, preferring CoinGecko and falling back to DexScreener,
+// and caches results for the configured TTL
+type Client struct {
+	config  *Config
+	client  *http.Client
+	logger  *logrus.Logger
+	coinIDs map[string]string
+
+	mu    sync.RWMutex
+	cache map[string]cacheEntry
+}
+
+// NewClient creates a new market data client
+func NewClient(config *Config) *Client {
+	if config.Logger == nil {
+		config.Logger = logrus.StandardLogger()
+	}
+
+	coinIDs := make(map[string]string, len(knownCoinIDs)+len(config.CoinIDs))
+	for symbol, id := range knownCoinIDs {
+		coinIDs[symbol] = id
+	}
+	for symbol, id := range config.CoinIDs {
+		coinIDs[strings.ToUpper(symbol)] = id
+	}
+
+	return &Client{
+		config:  config,
+		client:  &http.Client{Timeout: config.RequestTimeout},
+		logger:  config.Logger,
+		coinIDs: coinIDs,
+		cache:   make(map[string]cacheEntry),
+	}
+}
+
+// GetQuote returns the current quote for a symbol, served from cache when fresh
+func (c *Client) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
+	symbol = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(symbol), "$"))
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+
+	if quote := c.cached(symbol); quote != nil {
+		return quote, nil
+	}
+
+	log := c.logger.WithFields(logrus.Fields{
+		"method": "GetQuote",
+		"symbol": symbol,
+	})
+
+	var quote *Quote
+	var err error
+	if id, ok := c.coinIDs[symbol]; ok {
+		quote, err = c.fetchCoinGecko(ctx, symbol, id)
+		if err != nil {
+			log.WithError(err).Warn("CoinGecko lookup failed, falling back to DexScreener")
+		}
+	}
+	if quote == nil {
+		quote, err = c.fetchDexScreener(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch price for %s: %w", symbol, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.cache[symbol] = cacheEntry{quote: quote, expiresAt: time.Now().Add(c.config.CacheTTL)}
+	c.mu.Unlock()
+
+	log.WithFields(logrus.Fields{
+		"price_usd":  quote.PriceUSD,
+		"change_24h": quote.Change24h,
+		"source":     quote.Source,
+	}).Debug("Fetched token quote")
+
+	return quote, nil
+}
+
+// GetQuotes returns quotes for each symbol, skipping symbols that could not be priced
+func (c *Client) GetQuotes(ctx context.Context, symbols []string) []*Quote {
+	quotes := make([]*Quote, 0, len(symbols))
+	for _, symbol := range symbols {
+		quote, err := c.GetQuote(ctx, symbol)
+		if err != nil {
+			c.logger.WithError(err).WithField("symbol", symbol).Warn("Skipping unpriced symbol")
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes
+}
+
+// Watchlist returns the symbols the summary covers
+func (c *Client) Watchlist() []string {
+	return append([]string(nil), c.config.Watchlist...)
+}
+
+// Summary renders the watchlist quotes as prompt-ready lines such as
+// "BTC: $67,012.50 (-12.03% 24h)". It returns an empty string when nothing could be priced.
+func (c *Client) Summary(ctx context.Context) string {
+	quotes := c.GetQuotes(ctx, c.config.Watchlist)
+
+	var b strings.Builder
+	for _, quote := range quotes {
+		fmt.Fprintf(&b, "%s\n", FormatQuote(quote))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// FormatQuote renders a single quote for display
+func FormatQuote(quote *Quote) string {
+	return fmt.Sprintf("%s: $%s (%+.2f%% 24h)", quote.Symbol, formatPrice(quote.PriceUSD), quote.Change24h)
+}
+
+func (c *Client) cached(symbol string) *Quote {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.cache[symbol]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	return entry.quote
+}
+
+// fetchCoinGecko queries the CoinGecko simple price endpoint
+func (c *Client) fetchCoinGecko(ctx context.Context, symbol, id string) (*Quote, error) {
+	params := url.Values{}
+	params.Set("ids", id)
+	params.Set("vs_currencies", "usd")
+	params.Set("include_24hr_change", "true")
+
+	headers := map[string]string{}
+	if c.config.CoinGeckoAPIKey != "" {
+		headers["x-cg-demo-api-key"] = c.config.CoinGeckoAPIKey
+	}
+
+	var response map[string]struct {
+		USD          float64 `json:"usd"`
+		USD24hChange float64 `json:"usd_24h_change"`
+	}
+	if err := c.getJSON(ctx, c.config.CoinGeckoURL+"/simple/price?"+params.Encode(), headers, &response); err != nil {
+		return nil, err
+	}
+
+	price, ok := response[id]
+	if !ok {
+		return nil, fmt.Errorf("no CoinGecko price for id %s", id)
+	}
+
+	return &Quote{
+		Symbol:    symbol,
+		PriceUSD:  price.USD,
+		Change24h: price.USD24hChange,
+		Source:    SourceCoinGecko,
+		FetchedAt: time.Now(),
+	}, nil
+}
+
+// fetchDexScreener looks up the DexScreener pairs of the symbol's pinned
+// contracts and uses the most liquid one. Searching by symbol would match any
+// token that copied it, so symbols without a pinned contract are not priced.
+func (c *Client) fetchDexScreener(ctx context.Context, symbol string) (*Quote, error) {
+	contracts := c.config.Contracts[symbol]
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("no contract pinned for %s", symbol)
+	}
+
+	addresses := make([]string, len(contracts))
+	for i, contract := range contracts {
+		addresses[i] = url.PathEscape(contract.Address)
+	}
+	var response struct {
+		Pairs []struct {
+			ChainID   string `json:"chainId"`
+			BaseToken struct {
+				Address string `json:"address"`
+			} `json:"baseToken"`
+			PriceUSD    string `json:"priceUsd"`
+			PriceChange struct {
+				H24 float64 `json:"h24"`
+			} `json:"priceChange"`
+			Liquidity struct {
+				USD float64 `json:"usd"`
+			} `json:"liquidity"`
+		} `json:"pairs"`
+	}
+	if err := c.getJSON(ctx, c.config.DexScreenerURL+"/tokens/"+strings.Join(addresses, ","), nil, &response); err != nil {
+		return nil, err
+	}
+
+	var best *Quote
+	var bestLiquidity float64
+	for _, pair := range response.Pairs {
+		if !pinned(contracts, pair.ChainID, pair.BaseToken.Address) || pair.Liquidity.USD <= bestLiquidity {
+			continue
+		}
+		price, err := strconv.ParseFloat(pair.PriceUSD, 64)
+		if err != nil {
+			continue
+		}
+		bestLiquidity = pair.Liquidity.USD
+		best = &Quote{
+			Symbol:    symbol,
+			PriceUSD:  price,
+			Change24h: pair.PriceChange.H24,
+			Source:    SourceDexScreener,
+			FetchedAt: time.Now(),
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no DEX pairs found for %s", symbol)
+	}
+	return best, nil
+}
+
+// pinned reports whether a pair's base token is one of the contracts. The
+// same address can belong to another token on another chain.
+func pinned(contracts []Contract, chain, address string) bool {
+	for _, contract := range contracts {
+		if strings.EqualFold(contract.Chain, chain) && strings.EqualFold(contract.Address, address) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, headers map[string]string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("rate limit exceeded")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
+
+// formatPrice renders prices with thousands separators for large values and
+// significant digits for small ones
+func formatPrice(price float64) string {
+	if price < 1 {
+		return strconv.FormatFloat(price, 'g', 4, 64)
+	}
+
+	whole := strconv.FormatFloat(price, 'f', 2, 64)
+	intPart, frac, _ := strings.Cut(whole, ".")
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + "." + frac
+}
@@ -0,0 +1,94 @@
+// Package market provides token price data from CoinGecko and DEX aggregators
+// for use in the agent's commentary and tools.
+package market
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Default configuration values
+const (
+	// DefaultCoinGeckoURL is the public CoinGecko API base URL
+	DefaultCoinGeckoURL = "https://api.coingecko.com/api/v3"
+	// DefaultDexScreenerURL is the DexScreener API base URL used for tokens not listed on CoinGecko
+	DefaultDexScreenerURL = "https://api.dexscreener.com/latest/dex"
+	// DefaultCacheTTL is how long a fetched quote is reused before refetching
+	DefaultCacheTTL = 5 * time.Minute
+	// DefaultRequestTimeout bounds each HTTP request to a price API
+	DefaultRequestTimeout = 10 * time.Second
+)
+
+// DefaultWatchlist is the set of symbols referenced in thought prompts when none is configured
+var DefaultWatchlist = []string{"BTC", "ETH", "SOL"}
+
+// Config holds the market data configuration
+type Config struct {
+	CoinGeckoURL    string
+	CoinGeckoAPIKey string
+	DexScreenerURL  string
+	CacheTTL        time.Duration
+	RequestTimeout  time.Duration
+	Watchlist       []string
+	// CoinIDs maps upper-case symbols to CoinGecko coin IDs, extending the built-in list
+	CoinIDs map[string]string
+	// Contracts maps upper-case symbols to the token contracts their DexScreener
+	// pairs are looked up by; symbols without one are not priced from DEX pairs
+	Contracts map[string][]Contract
+	Logger    *logrus.Logger
+}
+
+// Contract is a token contract on one chain
+type Contract struct {
+	// Chain is the DexScreener chain ID, e.g. ethereum, base or bsc
+	Chain   string
+	Address string
+}
+
+// NewConfigFrom creates a market Config from the central agent configuration,
+// filling unset values with defaults
+func NewConfigFrom(settings config.MarketConfig, logger *logrus.Logger) *Config {
+	marketConfig := &Config{
+		CoinGeckoURL:    settings.CoinGeckoURL,
+		CoinGeckoAPIKey: settings.CoinGeckoAPIKey,
+		DexScreenerURL:  settings.DexScreenerURL,
+		CacheTTL:        settings.CacheTTL,
+		RequestTimeout:  DefaultRequestTimeout,
+		CoinIDs:         map[string]string{},
+		Contracts:       map[string][]Contract{},
+		Logger:          logger,
+	}
+
+	for chain, tokens := range settings.Contracts {
+		for symbol, address := range tokens {
+			symbol = strings.ToUpper(symbol)
+			marketConfig.Contracts[symbol] = append(marketConfig.Contracts[symbol], Contract{
+				Chain:   strings.ToLower(chain),
+				Address: address,
+			})
+		}
+	}
+
+	if marketConfig.CoinGeckoURL == "" {
+		marketConfig.CoinGeckoURL = DefaultCoinGeckoURL
+	}
+	if marketConfig.DexScreenerURL == "" {
+		marketConfig.DexScreenerURL = DefaultDexScreenerURL
+	}
+	if marketConfig.CacheTTL <= 0 {
+		marketConfig.CacheTTL = DefaultCacheTTL
+	}
+
+	marketConfig.Watchlist = DefaultWatchlist
+	if len(settings.Watchlist) > 0 {
+		marketConfig.Watchlist = nil
+		for _, symbol := range settings.Watchlist {
+			marketConfig.Watchlist = append(marketConfig.Watchlist, strings.ToUpper(symbol))
+		}
+	}
+
+	return marketConfig
+}
@@ -3,9 +3,13 @@ package masatwitter
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -30,29 +34,35 @@ type SearchRequest struct {
 type SearchOptions struct {
 	// TweetCount specifies the maximum number of tweets to return
 	TweetCount int
+	// Timeout overrides the configured RequestTimeout for each request
+	Timeout time.Duration
+	// MaxTotal caps the tweets collected over several pages. When it is
+	// larger than TweetCount, older pages are requested with max_id windows
+	// until MaxTotal tweets are collected or the results run out; otherwise
+	// a single page is fetched.
+	MaxTotal int
 }
 
 // NewClient creates a new Masa Twitter API client with the provided configuration.
-// It initializes an HTTP client with the configured timeout.
+// Requests are bounded by the configured timeout through their context rather
+// than the HTTP client, so a single search can be given a different deadline.
 func NewClient(config *Config) *Client {
 	return &Client{
 		config: config,
-		client: &http.Client{
-			Timeout: config.RequestTimeout,
-		},
+		client: &http.Client{},
 		logger: config.Logger,
 	}
 }
 
 // Search performs a search request to the Masa Twitter API with default options.
 // It uses the configured default tweets per request count.
-func (c *Client) Search(query string) ([]Tweet, error) {
+func (c *Client) Search(ctx context.Context, query string) ([]Tweet, error) {
 	c.logger.WithFields(logrus.Fields{
 		"query":              query,
 		"tweets_per_request": c.config.TweetsPerRequest,
 	}).Debug("Performing search with default options")
 
-	return c.SearchWithOptions(query, SearchOptions{
+	return c.SearchWithOptions(ctx, query, SearchOptions{
 		TweetCount: c.config.TweetsPerRequest,
 	})
 }
@@ -64,15 +74,82 @@ func (c *Client) Search(query string) ([]Tweet, error) {
 // - Response handling and error processing
 // - Rate limit detection
 // - Response unmarshaling
-func (c *Client) SearchWithOptions(query string, opts SearchOptions) ([]Tweet, error) {
+// - Paging through older results when opts.MaxTotal exceeds opts.TweetCount
+//
+// A request is abandoned when ctx is cancelled, returning ctx's error, or
+// when the request timeout passes, returning a retryable *TimeoutError. An
+// error on a later page discards the pages already fetched.
+func (c *Client) SearchWithOptions(ctx context.Context, query string, opts SearchOptions) ([]Tweet, error) {
 	c.logger.WithFields(logrus.Fields{
 		"query":      query,
 		"tweetCount": opts.TweetCount,
+		"maxTotal":   opts.MaxTotal,
 	}).Debug("Starting search with custom options")
 
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = c.config.RequestTimeout
+	}
+
+	if opts.MaxTotal <= opts.TweetCount {
+		return c.searchPage(ctx, query, opts.TweetCount, timeout)
+	}
+
+	var collected []Tweet
+	seen := make(map[string]bool)
+	pageQuery := query
+	for page := 1; len(collected) < opts.MaxTotal; page++ {
+		count := min(opts.TweetCount, opts.MaxTotal-len(collected))
+		if count <= 0 {
+			count = opts.MaxTotal - len(collected)
+		}
+
+		tweets, err := c.searchPage(ctx, pageQuery, count, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		// Pages overlap when tweets share a max_id boundary; the oldest ID
+		// seen is the cursor for the next window
+		var oldest uint64
+		added := 0
+		for _, tweet := range tweets {
+			id, err := strconv.ParseUint(tweet.ID, 10, 64)
+			if err == nil && (oldest == 0 || id < oldest) {
+				oldest = id
+			}
+			if tweet.ID != "" && seen[tweet.ID] {
+				continue
+			}
+			seen[tweet.ID] = true
+			collected = append(collected, tweet)
+			added++
+			if len(collected) == opts.MaxTotal {
+				break
+			}
+		}
+
+		c.logger.WithFields(logrus.Fields{
+			"query":     query,
+			"page":      page,
+			"added":     added,
+			"collected": len(collected),
+		}).Debug("Fetched search page")
+
+		if added == 0 || oldest <= 1 {
+			break
+		}
+		pageQuery = fmt.Sprintf("%s max_id:%d", query, oldest-1)
+	}
+
+	return collected, nil
+}
+
+// searchPage sends a single search request
+func (c *Client) searchPage(ctx context.Context, query string, count int, timeout time.Duration) ([]Tweet, error) {
 	reqBody := SearchRequest{
 		Query: query,
-		Count: opts.TweetCount,
+		Count: count,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -83,7 +160,14 @@ func (c *Client) SearchWithOptions(query string, opts SearchOptions) ([]Tweet, e
 
 	c.logger.WithField("request_body", string(jsonBody)).Debug("Marshaled request body")
 
-	req, err := http.NewRequest("POST", c.config.APIEndpoint, bytes.NewBuffer(jsonBody))
+	reqCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, "POST", c.config.APIEndpoint, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		c.logger.WithError(err).Error("Failed to create HTTP request")
 		return nil, fmt.Errorf("error creating request: %w", err)
@@ -100,8 +184,7 @@ func (c *Client) SearchWithOptions(query string, opts SearchOptions) ([]Tweet, e
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		c.logger.WithError(err).Error("HTTP request failed")
-		return nil, &ConnectionError{Err: err}
+		return nil, c.requestError(ctx, reqCtx, timeout, err)
 	}
 	defer resp.Body.Close()
 
@@ -131,6 +214,9 @@ func (c *Client) SearchWithOptions(query string, opts SearchOptions) ([]Tweet, e
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		if reqCtx.Err() != nil {
+			return nil, c.requestError(ctx, reqCtx, timeout, err)
+		}
 		c.logger.WithError(err).Error("Failed to decode response")
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
@@ -145,3 +231,18 @@ func (c *Client) SearchWithOptions(query string, opts SearchOptions) ([]Tweet, e
 
 	return tweets, nil
 }
+
+// requestError classifies a failed request: cancelled by the caller, past its
+// deadline, or a connection problem
+func (c *Client) requestError(ctx, reqCtx context.Context, timeout time.Duration, err error) error {
+	if ctx.Err() != nil {
+		c.logger.WithError(ctx.Err()).Debug("Search cancelled")
+		return fmt.Errorf("search cancelled: %w", ctx.Err())
+	}
+	if errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+		c.logger.WithField("timeout", timeout).Warn("Search timed out")
+		return &TimeoutError{Operation: "search", Duration: timeout}
+	}
+	c.logger.WithError(err).Error("HTTP request failed")
+	return &ConnectionError{Err: err}
+}
@@ -4,11 +4,9 @@ package masatwitter
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
 	"github.com/sirupsen/logrus"
 )
 
@@ -44,92 +42,42 @@ type Config struct {
 func NewConfig() (*Config, error) {
 	logrus.Debug("Starting NewConfig initialization")
 
-	// Load .env file if it exists
-	if err := godotenv.Load(); err != nil {
-		if !os.IsNotExist(err) {
-			logrus.WithError(err).Error("Failed to load .env file")
-			return nil, fmt.Errorf("error loading .env file: %w", err)
-		}
-		logrus.Debug(".env file not found, continuing with environment variables")
-	} else {
-		logrus.Debug("Successfully loaded .env file")
-	}
-
-	timeoutStr := os.Getenv("MASA_TWITTER_REQUEST_TIMEOUT")
-	logrus.WithField("timeout_str", timeoutStr).Debug("Retrieved timeout from environment")
-
-	timeout := DefaultRequestTimeout
-	if timeoutStr != "" {
-		if t, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = t
-			logrus.WithField("timeout", timeout).Debug("Successfully parsed custom timeout")
-		} else {
-			logrus.WithFields(logrus.Fields{
-				"value":   timeoutStr,
-				"error":   err.Error(),
-				"default": DefaultRequestTimeout,
-			}).Debug("Failed to parse request timeout, using default")
-		}
-	} else {
-		logrus.WithField("default_timeout", DefaultRequestTimeout).Debug("Using default timeout")
-	}
-
-	tweetsPerReq := DefaultTweetsPerRequest
-	count := os.Getenv("MASA_TWITTER_TWEETS_PER_REQUEST")
-	logrus.WithField("tweets_per_request_str", count).Debug("Retrieved tweets per request from environment")
-
-	if count != "" {
-		if t, err := strconv.Atoi(count); err == nil {
-			tweetsPerReq = t
-			logrus.WithField("tweets_per_request", tweetsPerReq).Debug("Successfully parsed custom tweets per request")
-		} else {
-			logrus.WithFields(logrus.Fields{
-				"value":   count,
-				"error":   err.Error(),
-				"default": DefaultTweetsPerRequest,
-			}).Debug("Failed to parse tweets per request, using default")
-		}
-	} else {
-		logrus.WithField("default_tweets_per_request", DefaultTweetsPerRequest).Debug("Using default tweets per request")
+	cfg, err := config.FromEnv()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to load configuration")
+		return nil, err
 	}
 
-	apiEndpoint := getEnvOrDefault("MASA_TWITTER_API_ENDPOINT", DefaultAPIEndpoint)
-	logrus.WithFields(logrus.Fields{
-		"api_endpoint": apiEndpoint,
-		"is_default":   apiEndpoint == DefaultAPIEndpoint,
-	}).Debug("Retrieved API endpoint")
-
-	logrus.WithFields(logrus.Fields{
-		"api_endpoint":       apiEndpoint,
-		"request_timeout":    timeout,
-		"tweets_per_request": tweetsPerReq,
-	}).Debug("Creating new Masa Twitter config")
-
 	logger := logrus.New()
 	logger.SetLevel(logrus.DebugLevel)
 	logrus.Debug("Created new logger instance with debug level")
 
-	config := &Config{
-		APIEndpoint:      apiEndpoint,
-		RequestTimeout:   time.Duration(timeout) * time.Second,
-		TweetsPerRequest: tweetsPerReq,
+	return NewConfigFrom(cfg.Masa, logger)
+}
+
+// NewConfigFrom creates a Config from the central agent configuration.
+func NewConfigFrom(settings config.MasaConfig, logger *logrus.Logger) (*Config, error) {
+	masaConfig := &Config{
+		APIEndpoint:      settings.APIEndpoint,
+		RequestTimeout:   time.Duration(settings.RequestTimeoutSeconds) * time.Second,
+		TweetsPerRequest: settings.TweetsPerRequest,
 		Logger:           logger,
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"api_endpoint":       config.APIEndpoint,
-		"request_timeout":    config.RequestTimeout.String(),
-		"tweets_per_request": config.TweetsPerRequest,
-		"logger_level":       config.Logger.GetLevel().String(),
+		"api_endpoint":       masaConfig.APIEndpoint,
+		"request_timeout":    masaConfig.RequestTimeout.String(),
+		"tweets_per_request": masaConfig.TweetsPerRequest,
+		"is_default":         masaConfig.APIEndpoint == DefaultAPIEndpoint,
 	}).Debug("Created config instance")
 
-	if err := config.Validate(); err != nil {
+	if err := masaConfig.Validate(); err != nil {
 		logrus.WithError(err).Debug("Config validation failed")
 		return nil, err
 	}
 
 	logrus.Debug("Successfully created and validated Masa Twitter config")
-	return config, nil
+	return masaConfig, nil
 }
 
 // Validate checks if the configuration is valid according to the following rules:
@@ -174,20 +122,3 @@ func (c *Config) Validate() error {
 	logrus.Debug("Config validation successful")
 	return nil
 }
-
-// getEnvOrDefault retrieves an environment variable value by key,
-// returning the defaultValue if the environment variable is not set or empty.
-func getEnvOrDefault(key, defaultValue string) string {
-	value := os.Getenv(key)
-	logrus.WithFields(logrus.Fields{
-		"key":           key,
-		"value":         value,
-		"default":       defaultValue,
-		"using_default": value == "",
-	}).Debug("Getting environment variable")
-
-	if value != "" {
-		return value
-	}
-	return defaultValue
-}
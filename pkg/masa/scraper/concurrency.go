@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
+)
+
+// concurrencyController caps how many workers may query the Masa node at once.
+// The cap is halved whenever the node reports a rate limit and raised by one
+// worker after a run of successful requests, up to the configured worker count.
+type concurrencyController struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	max       int
+	active    int
+	successes int
+	rampAfter int
+}
+
+// newConcurrencyController starts at the full worker count
+func newConcurrencyController(max, rampAfter int) *concurrencyController {
+	c := &concurrencyController{
+		limit:     max,
+		max:       max,
+		rampAfter: rampAfter,
+	}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// acquire blocks until the worker may send a request
+func (c *concurrencyController) acquire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.active >= c.limit {
+		c.cond.Wait()
+	}
+	c.active++
+}
+
+// release records the outcome of a request and returns the cap and whether
+// it changed. Errors other than rate limits neither lower the cap nor count
+// towards raising it.
+func (c *concurrencyController) release(err error) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active--
+
+	previous := c.limit
+	switch {
+	case isRateLimit(err):
+		c.successes = 0
+		c.limit = max(1, c.limit/2)
+	case err == nil:
+		c.successes++
+		if c.successes >= c.rampAfter && c.limit < c.max {
+			c.limit++
+			c.successes = 0
+		}
+	}
+
+	c.cond.Broadcast()
+	return c.limit, c.limit != previous
+}
+
+// isRateLimit reports whether err means the Masa node or its workers are rate limited
+func isRateLimit(err error) bool {
+	var rateLimit *masatwitter.RateLimitError
+	var workerLimit *masatwitter.WorkerRateLimitError
+	return errors.As(err, &rateLimit) || errors.As(err, &workerLimit)
+}
@@ -25,6 +25,14 @@ const (
 
 	// DefaultStatusInterval defines how often the scraper reports its status
 	DefaultStatusInterval = 30 * time.Second
+
+	// DefaultRequestsPerSecond defines how many searches all workers together
+	// may send per second
+	DefaultRequestsPerSecond = 2
+
+	// DefaultRampUpAfter defines how many successful requests in a row add a
+	// worker back after a rate limit reduced the concurrency
+	DefaultRampUpAfter = 10
 )
 
 // QueryConfig represents a single search query configuration with time bounds.
@@ -76,6 +84,14 @@ type ScraperConfig struct {
 
 	// StatusInterval defines how often the scraper should report its status
 	StatusInterval time.Duration `json:"statusInterval"`
+
+	// RequestsPerSecond limits the searches of all workers combined; zero or
+	// less leaves them unlimited
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+
+	// RampUpAfter is how many successful requests in a row raise the
+	// concurrency by one worker after a rate limit halved it
+	RampUpAfter int `json:"rampUpAfter"`
 }
 
 // LoadConfig reads and parses a configuration file from the given path.
@@ -119,10 +135,12 @@ func LoadConfig(path string) (*ScraperConfig, error) {
 	}
 
 	config := &ScraperConfig{
-		MaxRetries:     DefaultMaxRetries,
-		RetryBackoffMs: DefaultRetryBackoffMs,
-		WorkerCount:    DefaultWorkerCount,
-		StatusInterval: DefaultStatusInterval,
+		MaxRetries:        DefaultMaxRetries,
+		RetryBackoffMs:    DefaultRetryBackoffMs,
+		WorkerCount:       DefaultWorkerCount,
+		StatusInterval:    DefaultStatusInterval,
+		RequestsPerSecond: DefaultRequestsPerSecond,
+		RampUpAfter:       DefaultRampUpAfter,
 	}
 
 	for _, q := range raw.Queries {
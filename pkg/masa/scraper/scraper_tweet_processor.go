@@ -1,33 +1,63 @@
 package scraper
 
 import (
+	"sync"
 	"time"
 
 	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
 	"github.com/sirupsen/logrus"
 )
 
-// TweetProcessor handles the processing of retrieved tweets
+// TweetProcessor handles the processing of retrieved tweets. Daily task
+// splitting and overlapping queries return the same tweet more than once, so
+// the processor remembers the IDs it has seen and skips repeats.
 type TweetProcessor struct {
 	logger *logrus.Logger
+
+	mu         sync.Mutex
+	seen       map[string]struct{}
+	unique     int
+	duplicates int
 }
 
 // NewTweetProcessor creates a new TweetProcessor instance
 func NewTweetProcessor(logger *logrus.Logger) *TweetProcessor {
 	return &TweetProcessor{
 		logger: logger,
+		seen:   make(map[string]struct{}),
 	}
 }
 
+// Reset forgets the tweets seen so far and zeroes the counters
+func (p *TweetProcessor) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seen = make(map[string]struct{})
+	p.unique = 0
+	p.duplicates = 0
+}
+
+// Counts returns how many unique tweets were processed and how many
+// duplicates were skipped since the last Reset
+func (p *TweetProcessor) Counts() (unique, duplicates int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.unique, p.duplicates
+}
+
 // ProcessTweets handles the processing of retrieved tweets, including logging
-// detailed information about each tweet in the batch.
-func (p *TweetProcessor) ProcessTweets(tweets []masatwitter.Tweet) {
+// detailed information about each tweet in the batch. Tweets already seen,
+// in this batch or an earlier one, are skipped; the new ones are returned.
+func (p *TweetProcessor) ProcessTweets(tweets []masatwitter.Tweet) []masatwitter.Tweet {
+	fresh := p.dedupe(tweets)
+
 	p.logger.WithFields(logrus.Fields{
-		"tweet_count": len(tweets),
+		"tweet_count": len(fresh),
+		"duplicates":  len(tweets) - len(fresh),
 		"start_time":  time.Now().Format(time.RFC3339),
 	}).Info("Processing batch of tweets")
 
-	for _, tweet := range tweets {
+	for _, tweet := range fresh {
 		p.logger.WithFields(logrus.Fields{
 			"tweet_id":        tweet.ID,
 			"author_id":       tweet.UserID,
@@ -44,7 +74,30 @@ func (p *TweetProcessor) ProcessTweets(tweets []masatwitter.Tweet) {
 	}
 
 	p.logger.WithFields(logrus.Fields{
-		"tweet_count": len(tweets),
+		"tweet_count": len(fresh),
 		"end_time":    time.Now().Format(time.RFC3339),
 	}).Info("Completed processing batch of tweets")
+
+	return fresh
+}
+
+// dedupe keeps the tweets whose IDs have not been seen and counts the rest.
+// Tweets without an ID cannot be matched and are always kept.
+func (p *TweetProcessor) dedupe(tweets []masatwitter.Tweet) []masatwitter.Tweet {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fresh := make([]masatwitter.Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if tweet.ID != "" {
+			if _, ok := p.seen[tweet.ID]; ok {
+				p.duplicates++
+				continue
+			}
+			p.seen[tweet.ID] = struct{}{}
+		}
+		p.unique++
+		fresh = append(fresh, tweet)
+	}
+	return fresh
 }
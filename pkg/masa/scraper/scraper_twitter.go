@@ -1,12 +1,14 @@
 package scraper
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 // Package scraper provides functionality for scraping Twitter data using concurrent workers
@@ -21,9 +23,23 @@ type Scraper struct {
 	processor *TweetProcessor
 	tasks     map[string]*Task
 	status    ScraperStatus
+	results   chan ScrapeResult
+	limiter   *rate.Limiter
+	workers   *concurrencyController
 	mu        sync.RWMutex
 }
 
+// resultsBuffer is how many finished tasks can wait in the Results channel
+// before workers block on a slow consumer
+const resultsBuffer = 64
+
+// taskResult carries a finished attempt and its tweets from a worker to the
+// result processor
+type taskResult struct {
+	task   *Task
+	tweets []masatwitter.Tweet
+}
+
 // NewScraper creates a new Scraper instance with the provided Twitter client and logger.
 // It initializes the scraper with empty task map and zero status.
 func NewScraper(client *masatwitter.Client, logger *logrus.Logger) *Scraper {
@@ -37,6 +53,23 @@ func NewScraper(client *masatwitter.Client, logger *logrus.Logger) *Scraper {
 	}
 }
 
+// Results returns a channel that receives the outcome of every task of the
+// next or currently running ProcessTasks call as soon as the task completes or
+// finally fails, so tweets can be stored or embedded while the campaign is
+// still running. The channel is closed when ProcessTasks returns.
+//
+// Once Results has been called, workers wait for the channel to be drained, so
+// it must be read until it is closed. Without a call to Results, tweets are
+// only handed to the tweet processor.
+func (s *Scraper) Results() <-chan ScrapeResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.results == nil {
+		s.results = make(chan ScrapeResult, resultsBuffer)
+	}
+	return s.results
+}
+
 // ProcessTasks executes the scraping tasks defined in the provided configuration.
 // It manages concurrent workers, handles retries with exponential backoff, and
 // provides periodic status updates. Returns an error if the processing fails.
@@ -44,24 +77,60 @@ func NewScraper(client *masatwitter.Client, logger *logrus.Logger) *Scraper {
 // The function will:
 // - Initialize worker pools based on config.WorkerCount
 // - Process tasks concurrently with retry logic
+// - Share config.RequestsPerSecond across workers and adapt concurrency to rate limits
 // - Report status at config.StatusInterval intervals
 // - Handle task completion and failure states
-func (s *Scraper) ProcessTasks(config *ScraperConfig) error {
+// - Stream finished tasks to the Results channel, if one was requested
+//
+// Cancelling ctx aborts in-flight searches, stops the workers and returns
+// ctx's error; tasks not finished by then are left unprocessed.
+func (s *Scraper) ProcessTasks(ctx context.Context, config *ScraperConfig) error {
+	workerCount := max(1, config.WorkerCount)
+	requestRate := rate.Inf
+	if config.RequestsPerSecond > 0 {
+		requestRate = rate.Limit(config.RequestsPerSecond)
+	}
+	rampUpAfter := config.RampUpAfter
+	if rampUpAfter <= 0 {
+		rampUpAfter = DefaultRampUpAfter
+	}
+
 	s.mu.Lock()
 	s.status = ScraperStatus{
-		TotalTasks: len(config.Tasks),
-		StartTime:  time.Now(),
+		TotalTasks:  len(config.Tasks),
+		Concurrency: workerCount,
+		StartTime:   time.Now(),
 	}
+	s.processor.Reset()
+	s.limiter = rate.NewLimiter(requestRate, 1)
+	s.workers = newConcurrencyController(workerCount, rampUpAfter)
 
 	// Initialize task map
 	s.tasks = make(map[string]*Task)
 	for _, task := range config.Tasks {
 		s.tasks[task.ID] = &task
 	}
+	stream := s.results
 	s.mu.Unlock()
 
+	// The stream belongs to this run; a later Results call starts a new one
+	defer func() {
+		s.mu.Lock()
+		if s.results == stream {
+			s.results = nil
+		}
+		s.mu.Unlock()
+		if stream != nil {
+			close(stream)
+		}
+	}()
+
+	if len(config.Tasks) == 0 {
+		return nil
+	}
+
 	taskCh := make(chan *Task, len(config.Tasks))
-	resultCh := make(chan *Task, len(config.Tasks))
+	resultCh := make(chan taskResult, len(config.Tasks))
 	stopReporter := make(chan bool)
 	done := make(chan struct{})
 
@@ -71,7 +140,17 @@ func (s *Scraper) ProcessTasks(config *ScraperConfig) error {
 	// Start result processor
 	go func() {
 		defer close(done)
-		for task := range resultCh {
+		defer close(stopReporter)
+		for {
+			var result taskResult
+			select {
+			case <-ctx.Done():
+				return
+			case result = <-resultCh:
+			}
+			task := result.task
+			var finished *ScrapeResult
+
 			s.mu.Lock()
 			s.tasks[task.ID] = task
 			switch task.Status {
@@ -83,6 +162,7 @@ func (s *Scraper) ProcessTasks(config *ScraperConfig) error {
 					"completed": s.status.CompletedTasks,
 					"total":     s.status.TotalTasks,
 				}).Debug("Task completed")
+				finished = &ScrapeResult{Task: *task, Tweets: result.tweets}
 			case TaskStatusFailed:
 				if task.RetryCount < config.MaxRetries {
 					task.Status = TaskStatusRetrying
@@ -95,18 +175,29 @@ func (s *Scraper) ProcessTasks(config *ScraperConfig) error {
 						"backoff": backoff.String(),
 					}).Info("Scheduling task retry")
 					time.AfterFunc(backoff, func() {
-						taskCh <- task
+						select {
+						case taskCh <- task:
+						case <-ctx.Done():
+						}
 					})
 				} else {
 					s.status.FailedTasks++
 					s.status.RetryingTasks = max(0, s.status.RetryingTasks-1)
+					finished = &ScrapeResult{Task: *task, Err: fmt.Errorf("task %s failed after %d retries: %s", task.ID, task.RetryCount, task.LastError)}
 				}
 			}
 			s.mu.Unlock()
 
+			if finished != nil && stream != nil {
+				select {
+				case stream <- *finished:
+				case <-ctx.Done():
+					return
+				}
+			}
+
 			// Check if all tasks are complete
 			if s.status.CompletedTasks+s.status.FailedTasks == s.status.TotalTasks {
-				close(stopReporter)
 				return
 			}
 		}
@@ -114,11 +205,11 @@ func (s *Scraper) ProcessTasks(config *ScraperConfig) error {
 
 	// Start workers
 	var wg sync.WaitGroup
-	for i := 0; i < config.WorkerCount; i++ {
+	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
 		go func(id int) {
 			defer wg.Done()
-			s.worker(id, taskCh, resultCh)
+			s.worker(ctx, id, taskCh, resultCh)
 		}(i)
 	}
 
@@ -128,17 +219,29 @@ func (s *Scraper) ProcessTasks(config *ScraperConfig) error {
 		taskCh <- &taskCopy
 	}
 
-	// Wait for workers to finish
-	go func() {
-		wg.Wait()
+	// Wait for result processing to complete, then let the idle workers exit.
+	// On cancellation the workers exit through ctx instead, as retries may
+	// still be queued for the task channel.
+	select {
+	case <-done:
 		close(taskCh)
-	}()
-
-	// Wait for result processing to complete
-	<-done
-	close(resultCh)
+	case <-ctx.Done():
+		<-done
+	}
+	wg.Wait()
 
-	return nil
+	s.mu.RLock()
+	s.logger.WithFields(logrus.Fields{
+		"total":      s.status.TotalTasks,
+		"completed":  s.status.CompletedTasks,
+		"failed":     s.status.FailedTasks,
+		"tweets":     s.status.UniqueTweets,
+		"duplicates": s.status.DuplicateTweets,
+		"duration":   time.Since(s.status.StartTime).String(),
+	}).Info("Scraper finished")
+	s.mu.RUnlock()
+
+	return ctx.Err()
 }
 
 // calculateBackoff determines the retry delay duration using exponential backoff.
@@ -172,11 +275,14 @@ func (s *Scraper) reportStatus(interval time.Duration, stop chan bool) {
 		case <-ticker.C:
 			s.mu.RLock()
 			s.logger.WithFields(logrus.Fields{
-				"total":     s.status.TotalTasks,
-				"completed": s.status.CompletedTasks,
-				"failed":    s.status.FailedTasks,
-				"retrying":  s.status.RetryingTasks,
-				"duration":  time.Since(s.status.StartTime).String(),
+				"total":      s.status.TotalTasks,
+				"completed":  s.status.CompletedTasks,
+				"failed":     s.status.FailedTasks,
+				"retrying":   s.status.RetryingTasks,
+				"workers":    s.status.Concurrency,
+				"tweets":     s.status.UniqueTweets,
+				"duplicates": s.status.DuplicateTweets,
+				"duration":   time.Since(s.status.StartTime).String(),
 			}).Info("Scraper status update")
 			s.mu.RUnlock()
 		case <-stop:
@@ -187,10 +293,21 @@ func (s *Scraper) reportStatus(interval time.Duration, stop chan bool) {
 
 // worker processes tasks from the input channel and sends results to the output channel.
 // It handles task execution, error handling, and logging of task progress.
-func (s *Scraper) worker(id int, tasks <-chan *Task, results chan<- *Task) {
+func (s *Scraper) worker(ctx context.Context, id int, tasks <-chan *Task, results chan<- taskResult) {
 	s.logger.WithField("worker_id", id).Debug("Worker started")
 
-	for task := range tasks {
+	for {
+		var task *Task
+		select {
+		case <-ctx.Done():
+			return
+		case next, ok := <-tasks:
+			if !ok {
+				return
+			}
+			task = next
+		}
+
 		s.logger.WithFields(logrus.Fields{
 			"worker_id":   id,
 			"task_id":     task.ID,
@@ -204,9 +321,7 @@ func (s *Scraper) worker(id int, tasks <-chan *Task, results chan<- *Task) {
 			task.StartDate.Format("2006-01-02"),
 		)
 
-		tweets, err := s.client.SearchWithOptions(queryStr, masatwitter.SearchOptions{
-			TweetCount: task.Count,
-		})
+		tweets, err := s.search(ctx, queryStr, task.Count)
 
 		if err != nil {
 			task.LastError = err.Error()
@@ -220,12 +335,22 @@ func (s *Scraper) worker(id int, tasks <-chan *Task, results chan<- *Task) {
 				"retries":   task.RetryCount,
 			}).Error("Task failed")
 
-			results <- task
+			select {
+			case results <- taskResult{task: task}:
+			case <-ctx.Done():
+				return
+			}
 			continue
 		}
 
-		// Process tweets and mark task as complete
-		s.processor.ProcessTweets(tweets)
+		// Process tweets, dropping those another task already returned, and
+		// mark task as complete
+		tweets = s.processor.ProcessTweets(tweets)
+		unique, duplicates := s.processor.Counts()
+		s.mu.Lock()
+		s.status.UniqueTweets = unique
+		s.status.DuplicateTweets = duplicates
+		s.mu.Unlock()
 
 		task.Status = TaskStatusComplete
 		task.LastAttempt = time.Now()
@@ -236,8 +361,42 @@ func (s *Scraper) worker(id int, tasks <-chan *Task, results chan<- *Task) {
 			"tweets":    len(tweets),
 		}).Debug("Task completed successfully")
 
-		results <- task
+		select {
+		case results <- taskResult{task: task, tweets: tweets}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// search sends one request once the shared rate limit and the current
+// concurrency allow it, and adapts the concurrency to the outcome
+func (s *Scraper) search(ctx context.Context, query string, count int) ([]masatwitter.Tweet, error) {
+	s.workers.acquire()
+	if err := s.limiter.Wait(ctx); err != nil {
+		s.workers.release(err)
+		return nil, err
+	}
+
+	tweets, err := s.client.SearchWithOptions(ctx, query, masatwitter.SearchOptions{
+		TweetCount: count,
+	})
+
+	rateLimited := isRateLimit(err)
+	limit, changed := s.workers.release(err)
+	if changed {
+		s.mu.Lock()
+		s.status.Concurrency = limit
+		s.mu.Unlock()
+
+		log := s.logger.WithField("workers", limit)
+		if rateLimited {
+			log.Warn("Masa node rate limited the scraper, reducing concurrency")
+		} else {
+			log.Info("Scraper requests succeeding again, raising concurrency")
+		}
 	}
+	return tweets, err
 }
 
 // Status provides thread-safe access to the current scraper status through a callback function.
@@ -278,7 +437,23 @@ scraper := NewScraper(client, logger)
 
 // 3. Process tasks defined in config
 
-if err := scraper.ProcessTasks(config); err != nil {
+if err := scraper.ProcessTasks(ctx, config); err != nil {
     log.Fatalf("Failed to process tasks: %v", err)
 }
+
+// Or consume tweets as tasks finish instead of waiting for the whole campaign
+
+results := scraper.Results()
+go func() {
+    if err := scraper.ProcessTasks(ctx, config); err != nil {
+        log.Printf("Failed to process tasks: %v", err)
+    }
+}()
+for result := range results {
+    if result.Err != nil {
+        log.Printf("Task %s failed: %v", result.Task.ID, result.Err)
+        continue
+    }
+    storeTweets(result.Tweets)
+}
 */
@@ -1,6 +1,10 @@
 package scraper
 
-import "time"
+import (
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
+)
 
 // ScraperStatus represents the current state of the scraper system,
 // including task statistics and runtime information.
@@ -13,6 +17,14 @@ type ScraperStatus struct {
 	FailedTasks int
 	// RetryingTasks is the number of tasks currently in retry state
 	RetryingTasks int
+	// Concurrency is how many workers may currently query the Masa node at
+	// once; rate limits lower it below the configured worker count
+	Concurrency int
+	// UniqueTweets is the number of distinct tweets retrieved
+	UniqueTweets int
+	// DuplicateTweets is the number of tweets skipped because an earlier task
+	// already returned them
+	DuplicateTweets int
 	// StartTime is when the scraper system was initialized
 	StartTime time.Time
 }
@@ -56,3 +68,14 @@ type Task struct {
 	// LastAttempt records when the task was last attempted
 	LastAttempt time.Time `json:"lastAttempt,omitempty"`
 }
+
+// ScrapeResult is the outcome of a finished task, streamed through Results.
+type ScrapeResult struct {
+	// Task is a snapshot of the task when it finished
+	Task Task
+	// Tweets are the tweets the task retrieved that no earlier task returned;
+	// empty when it failed
+	Tweets []masatwitter.Tweet
+	// Err is set when the task failed and exhausted its retries
+	Err error
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+// Analytics metric names
+const (
+	MetricMentionVolume          = "mention_volume"
+	MetricConversationEngagement = "conversation_engagement"
+)
+
+// AnalyticsRecord is one daily metric for a bot account, optionally scoped to a conversation
+type AnalyticsRecord struct {
+	BotID          string    `gorm:"column:bot_id;primaryKey"`
+	Metric         string    `gorm:"column:metric;primaryKey"`
+	PeriodStart    time.Time `gorm:"column:period_start;primaryKey"`
+	ConversationID string    `gorm:"column:conversation_id;primaryKey"`
+	TweetCount     int       `gorm:"column:tweet_count"`
+	BotReplyCount  int       `gorm:"column:bot_reply_count"`
+	LikeCount      int       `gorm:"column:like_count"`
+	RetweetCount   int       `gorm:"column:retweet_count"`
+	ReplyCount     int       `gorm:"column:reply_count"`
+	QuoteCount     int       `gorm:"column:quote_count"`
+	RecordedAt     time.Time `gorm:"column:recorded_at"`
+}
+
+// TableName specifies the table name for GORM
+func (AnalyticsRecord) TableName() string {
+	return "analytics"
+}
+
+// RecordMentionVolume stores how many times the bot was mentioned on the given day
+func (s *TweetStore) RecordMentionVolume(ctx context.Context, day time.Time, count int) error {
+	return s.upsertAnalytics(ctx, []AnalyticsRecord{{
+		BotID:       s.BotID(),
+		Metric:      MetricMentionVolume,
+		PeriodStart: startOfDay(day),
+		TweetCount:  count,
+		RecordedAt:  time.Now(),
+	}})
+}
+
+// RecordConversationEngagement aggregates the stored tweets created on the given day
+// into one engagement row per conversation and returns the number of rows recorded
+func (s *TweetStore) RecordConversationEngagement(ctx context.Context, day time.Time) (int, error) {
+	start := startOfDay(day)
+	end := start.Add(24 * time.Hour)
+
+	var rows []struct {
+		ConversationID string          `gorm:"column:conversation_id"`
+		AuthorID       string          `gorm:"column:author_id"`
+		PublicMetrics  json.RawMessage `gorm:"column:public_metrics;serializer:json"`
+	}
+
+	err := s.tweets(s.reader(ctx)).
+		Select("conversation_id, author_id, public_metrics").
+		Where("conversation_id <> '' AND created_at >= ? AND created_at < ?", start, end).
+		Find(&rows).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load tweets for engagement: %w", err)
+	}
+
+	botID := s.BotID()
+	now := time.Now()
+	byConversation := make(map[string]*AnalyticsRecord)
+	for _, row := range rows {
+		record, ok := byConversation[row.ConversationID]
+		if !ok {
+			record = &AnalyticsRecord{
+				BotID:          botID,
+				Metric:         MetricConversationEngagement,
+				PeriodStart:    start,
+				ConversationID: row.ConversationID,
+				RecordedAt:     now,
+			}
+			byConversation[row.ConversationID] = record
+		}
+
+		record.TweetCount++
+		if row.AuthorID == botID {
+			record.BotReplyCount++
+		}
+
+		if len(row.PublicMetrics) == 0 {
+			continue
+		}
+		var metrics struct {
+			RetweetCount int `json:"retweet_count"`
+			ReplyCount   int `json:"reply_count"`
+			LikeCount    int `json:"like_count"`
+			QuoteCount   int `json:"quote_count"`
+		}
+		if err := json.Unmarshal(row.PublicMetrics, &metrics); err != nil {
+			s.logger.WithError(err).WithField("conversation_id", row.ConversationID).
+				Debug("Skipping unreadable public metrics")
+			continue
+		}
+		record.LikeCount += metrics.LikeCount
+		record.RetweetCount += metrics.RetweetCount
+		record.ReplyCount += metrics.ReplyCount
+		record.QuoteCount += metrics.QuoteCount
+	}
+
+	records := make([]AnalyticsRecord, 0, len(byConversation))
+	for _, record := range byConversation {
+		records = append(records, *record)
+	}
+	if err := s.upsertAnalytics(ctx, records); err != nil {
+		return 0, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"day":           start.Format("2006-01-02"),
+		"conversations": len(records),
+		"tweets":        len(rows),
+	}).Debug("Recorded conversation engagement")
+
+	return len(records), nil
+}
+
+// upsertAnalytics inserts records, replacing the values of rows already recorded
+// for the same bot, metric, day and conversation
+func (s *TweetStore) upsertAnalytics(ctx context.Context, records []AnalyticsRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "bot_id"}, {Name: "metric"}, {Name: "period_start"}, {Name: "conversation_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"tweet_count", "bot_reply_count", "like_count",
+				"retweet_count", "reply_count", "quote_count", "recorded_at",
+			}),
+		}).
+		Create(&records).Error
+	if err != nil {
+		return fmt.Errorf("failed to save analytics: %w", err)
+	}
+	return nil
+}
+
+// startOfDay truncates t to midnight UTC
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
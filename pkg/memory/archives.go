@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationArchive records where a conversation was published for the
+// public archive. A conversation is archived again when it gets new tweets.
+type ConversationArchive struct {
+	ID             int64  `json:"id" gorm:"column:id;primaryKey"`
+	BotID          string `json:"bot_id" gorm:"column:bot_id"`
+	ConversationID string `json:"conversation_id" gorm:"column:conversation_id"`
+	TweetCount     int    `json:"tweet_count" gorm:"column:tweet_count"`
+	// LastTweetAt is the newest tweet included in the archive
+	LastTweetAt time.Time `json:"last_tweet_at" gorm:"column:last_tweet_at"`
+	// URI identifies the archived document, e.g. ipfs://<cid> or s3://bucket/key
+	URI string `json:"uri" gorm:"column:uri"`
+	// URL serves the document over HTTP
+	URL string `json:"url" gorm:"column:url"`
+	// SHA256 is the hex digest of the archived document
+	SHA256     string    `json:"sha256" gorm:"column:sha256"`
+	ArchivedAt time.Time `json:"archived_at" gorm:"column:archived_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ConversationArchive) TableName() string {
+	return "conversation_archives"
+}
+
+// ConversationsToArchive returns conversations the bot took part in that have
+// been quiet since the given time and were not archived with their latest
+// tweets yet, at most limit of them
+func (s *TweetStore) ConversationsToArchive(ctx context.Context, quietSince time.Time, limit int) ([]string, error) {
+	botID := s.BotID()
+	query := s.db.WithContext(ctx).
+		Table("tweets AS t").
+		Distinct("t.conversation_id").
+		Where("t.bot_id = ? AND t.is_participating = ? AND t.conversation_id <> ''", botID, true).
+		Where(`
+			NOT EXISTS (
+				SELECT 1 FROM tweets r
+				WHERE r.bot_id = t.bot_id AND r.conversation_id = t.conversation_id AND r.created_at >= ?
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM conversation_archives a
+				WHERE a.bot_id = t.bot_id AND a.conversation_id = t.conversation_id
+				AND NOT EXISTS (
+					SELECT 1 FROM tweets n
+					WHERE n.bot_id = a.bot_id AND n.conversation_id = a.conversation_id AND n.created_at > a.last_tweet_at
+				)
+			)
+		`, quietSince).
+		Order("t.conversation_id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var conversationIDs []string
+	if err := query.Pluck("t.conversation_id", &conversationIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find conversations to archive: %w", err)
+	}
+	return conversationIDs, nil
+}
+
+// ConversationTweets returns every stored tweet of a conversation that is not
+// deleted, oldest first
+func (s *TweetStore) ConversationTweets(ctx context.Context, conversationID string) ([]TweetNeedingReply, error) {
+	var tweets []TweetNeedingReply
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("conversation_id = ? AND deleted_at IS NULL", conversationID).
+		Order("created_at ASC, id ASC").
+		Find(&tweets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	return tweets, nil
+}
+
+// SaveConversationArchive records an archived conversation, replacing the
+// record of an earlier archive of it
+func (s *TweetStore) SaveConversationArchive(ctx context.Context, archive *ConversationArchive) error {
+	archive.BotID = s.BotID()
+	if archive.ArchivedAt.IsZero() {
+		archive.ArchivedAt = time.Now()
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "bot_id"}, {Name: "conversation_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tweet_count", "last_tweet_at", "uri", "url", "sha256", "archived_at"}),
+		}).
+		Create(archive).Error
+	if err != nil {
+		return fmt.Errorf("failed to save conversation archive: %w", err)
+	}
+	return nil
+}
+
+// GetConversationArchive returns the archive record of a conversation, or nil
+// if it was never archived
+func (s *TweetStore) GetConversationArchive(ctx context.Context, conversationID string) (*ConversationArchive, error) {
+	var archive ConversationArchive
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND conversation_id = ?", s.BotID(), conversationID).
+		First(&archive).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation archive: %w", err)
+	}
+	return &archive, nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+)
+
+// AuthorsWithoutProfile returns up to limit authors of stored tweets that were
+// saved without a username, because the author was missing from the
+// response's includes. The bot's own tweets are skipped.
+func (s *TweetStore) AuthorsWithoutProfile(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := s.tweets(s.reader(ctx)).
+		Where("(author_username IS NULL OR author_username = '')").
+		Where("author_id <> '' AND author_id <> ?", s.botID).
+		Distinct("author_id").
+		Order("author_id").
+		Limit(limit).
+		Pluck("author_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find authors without profile: %w", err)
+	}
+	return ids, nil
+}
+
+// SetAuthorProfile fills in the author's profile on every stored tweet of the
+// author, so later reply priority and prompts see their name and reach
+func (s *TweetStore) SetAuthorProfile(ctx context.Context, authorID, name, username string, followers int, verified bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("author_id = ?", authorID).
+		Updates(map[string]interface{}{
+			"author_name":      name,
+			"author_username":  username,
+			"author_followers": followers,
+			"author_verified":  verified,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to set author profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// purgedContent clears the content of deleted tweets. The ID, author ID and
+// conversation stay, so replies remain linked to the tweets they answered.
+func purgedContent(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"text":                "",
+		"attachments":         nil,
+		"context_annotations": nil,
+		"edit_controls":       nil,
+		"entities":            nil,
+		"geo":                 nil,
+		"withheld":            nil,
+		"needs_reply":         false,
+		"deleted_at":          now,
+		"last_updated":        now,
+	}
+}
+
+// DeleteTweets soft-deletes stored tweets that were removed from Twitter:
+// their content is purged and they leave the reply queue, so they are never
+// answered or used as conversation context again. It returns how many tweets
+// were deleted.
+func (s *TweetStore) DeleteTweets(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return s.softDelete(ctx, "id IN ?", []interface{}{ids}, purgedContent(time.Now()))
+}
+
+// DeleteUserTweets soft-deletes every stored tweet of a user who deleted,
+// protected or lost their account, purging the author's name as well
+func (s *TweetStore) DeleteUserTweets(ctx context.Context, userID string) (int64, error) {
+	updates := purgedContent(time.Now())
+	updates["author_name"] = ""
+	updates["author_username"] = ""
+	return s.softDelete(ctx, "author_id = ?", []interface{}{userID}, updates)
+}
+
+// softDelete applies updates to the bot's tweets matching where and removes
+// them from the reply queue, in one transaction. The summaries of their
+// conversations and authors are dropped too, since they may repeat the
+// deleted content; they are rebuilt from the remaining tweets.
+func (s *TweetStore) softDelete(ctx context.Context, where string, args []interface{}, updates map[string]interface{}) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		matching := s.tweets(tx).Select("id").Where(where, args...)
+		if err := tx.Where("bot_id = ? AND tweet_id IN (?)", s.botID, matching).Delete(&ReplyWorkItem{}).Error; err != nil {
+			return fmt.Errorf("failed to remove deleted tweets from the reply queue: %w", err)
+		}
+		conversations := s.tweets(tx).Select("conversation_id").Where(where, args...)
+		if err := tx.Where("bot_id = ? AND conversation_id IN (?)", s.botID, conversations).Delete(&ConversationSummary{}).Error; err != nil {
+			return fmt.Errorf("failed to drop conversation summaries of deleted tweets: %w", err)
+		}
+		authors := s.tweets(tx).Select("author_id").Where(where, args...)
+		if err := tx.Where("bot_id = ? AND user_id IN (?)", s.botID, authors).Delete(&UserSummary{}).Error; err != nil {
+			return fmt.Errorf("failed to drop user summaries of deleted tweets: %w", err)
+		}
+
+		result := s.tweets(tx).Where(where, args...).Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete tweets: %w", result.Error)
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"deleted": deleted,
+		"where":   where,
+	}).Info("Soft-deleted tweets")
+	return deleted, nil
+}
+
+// ScrubGeo removes the location of stored tweets
+func (s *TweetStore) ScrubGeo(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := s.tweets(s.db.WithContext(ctx)).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"geo":          nil,
+			"last_updated": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to scrub tweet locations: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
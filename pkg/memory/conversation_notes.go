@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ConversationNote is an operator instruction for replies in one
+// conversation, such as "stop mentioning the token price". Notes with an
+// empty BotID apply to every bot account.
+type ConversationNote struct {
+	ID             int64     `json:"id" gorm:"column:id;primaryKey"`
+	BotID          string    `json:"bot_id" gorm:"column:bot_id"`
+	ConversationID string    `json:"conversation_id" gorm:"column:conversation_id"`
+	Note           string    `json:"note" gorm:"column:note"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+	// ExpiresAt ends the note; nil keeps it until it is removed
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ConversationNote) TableName() string {
+	return "conversation_notes"
+}
+
+// Active reports whether the note still applies at the given time
+func (n ConversationNote) Active(now time.Time) bool {
+	return n.ExpiresAt == nil || now.Before(*n.ExpiresAt)
+}
+
+// AddConversationNote attaches an instruction to a conversation. A zero ttl
+// keeps the note until it is removed.
+func (s *TweetStore) AddConversationNote(ctx context.Context, conversationID, note string, ttl time.Duration) (*ConversationNote, error) {
+	entry := &ConversationNote{
+		BotID:          s.BotID(),
+		ConversationID: conversationID,
+		Note:           note,
+		CreatedAt:      time.Now(),
+	}
+	if ttl > 0 {
+		expires := entry.CreatedAt.Add(ttl)
+		entry.ExpiresAt = &expires
+	}
+
+	if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to add conversation note: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"bot_id":          entry.BotID,
+		"conversation_id": conversationID,
+		"note_id":         entry.ID,
+		"expires_at":      entry.ExpiresAt,
+	}).Info("Added conversation note")
+	return entry, nil
+}
+
+// RemoveConversationNote deletes a note. It reports whether the note existed.
+func (s *TweetStore) RemoveConversationNote(ctx context.Context, id int64) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Where("bot_id = ? AND id = ?", s.BotID(), id).
+		Delete(&ConversationNote{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove conversation note: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListConversationNotes returns the notes for this bot, including global and
+// expired ones, oldest first. An empty conversationID lists every conversation.
+func (s *TweetStore) ListConversationNotes(ctx context.Context, conversationID string) ([]ConversationNote, error) {
+	query := s.moderationScope(s.db.WithContext(ctx))
+	if conversationID != "" {
+		query = query.Where("conversation_id = ?", conversationID)
+	}
+
+	var notes []ConversationNote
+	if err := query.Order("created_at ASC, id ASC").Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list conversation notes: %w", err)
+	}
+	return notes, nil
+}
+
+// ActiveConversationNotes returns the text of the notes that currently apply
+// to replies in a conversation, oldest first
+func (s *TweetStore) ActiveConversationNotes(ctx context.Context, conversationID string) ([]string, error) {
+	if conversationID == "" {
+		return nil, nil
+	}
+
+	var notes []string
+	err := s.moderationScope(s.db.WithContext(ctx)).
+		Model(&ConversationNote{}).
+		Where("conversation_id = ?", conversationID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now()).
+		Order("created_at ASC, id ASC").
+		Pluck("note", &notes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation notes: %w", err)
+	}
+	return notes, nil
+}
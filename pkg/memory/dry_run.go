@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/sirupsen/logrus"
+)
+
+// DryRunPost is a tweet or transfer the agent would have published had it not
+// been running in dry-run mode
+type DryRunPost struct {
+	ID      int64  `json:"id" gorm:"column:id;primaryKey"`
+	BotID   string `json:"bot_id" gorm:"column:bot_id"`
+	Kind    string `json:"kind" gorm:"column:kind"`
+	Target  string `json:"target" gorm:"column:target"`
+	Content string `json:"content" gorm:"column:content"`
+	// Details is the JSON encoded request parameters
+	Details   string    `json:"details" gorm:"column:details"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (DryRunPost) TableName() string {
+	return "dry_run_posts"
+}
+
+// RecordDryRun implements dryrun.Recorder, storing what would have been
+// published so prompt changes can be reviewed against production data
+func (s *TweetStore) RecordDryRun(ctx context.Context, record dryrun.Record) error {
+	err := s.db.WithContext(ctx).Table(DryRunPost{}.TableName()).Create(map[string]interface{}{
+		"bot_id":     s.BotID(),
+		"kind":       string(record.Kind),
+		"target":     record.Target,
+		"content":    record.Content,
+		"details":    s.jsonColumn(record.Details),
+		"created_at": time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record dry-run post: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"kind":    record.Kind,
+		"target":  record.Target,
+		"content": record.Content,
+	}).Info("Dry run: recorded instead of publishing")
+	return nil
+}
+
+// ListDryRunPosts returns the most recent dry-run posts, newest first. An empty
+// bot ID lists the posts of every account.
+func (s *TweetStore) ListDryRunPosts(ctx context.Context, limit int) ([]DryRunPost, error) {
+	query := s.db.WithContext(ctx).Order("created_at DESC, id DESC")
+	if botID := s.BotID(); botID != "" {
+		query = query.Where("bot_id = ?", botID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var posts []DryRunPost
+	if err := query.Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list dry-run posts: %w", err)
+	}
+	return posts, nil
+}
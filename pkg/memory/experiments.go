@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// VariantStats is the engagement of the replies generated by one prompt variant
+type VariantStats struct {
+	Variant  string
+	Replies  int
+	Likes    int
+	Retweets int
+	Answers  int
+	Quotes   int
+}
+
+// AvgEngagement returns the mean engagement per reply (see ReplyPerformance.Engagement)
+func (v VariantStats) AvgEngagement() float64 {
+	if v.Replies == 0 {
+		return 0
+	}
+	return float64(v.Likes+v.Answers+2*(v.Retweets+v.Quotes)) / float64(v.Replies)
+}
+
+// SetReplyVariant records the prompt variant that generated one of the bot's replies
+func (s *TweetStore) SetReplyVariant(ctx context.Context, replyTweetID, variant string) error {
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("id = ?", replyTweetID).
+		Update("variant", variant).Error
+	if err != nil {
+		return fmt.Errorf("failed to record reply variant: %w", err)
+	}
+	return nil
+}
+
+// VariantPerformance correlates prompt variants with the engagement their
+// replies received since the given time, best average first. Metrics are only
+// as fresh as the last metrics refresh.
+func (s *TweetStore) VariantPerformance(ctx context.Context, since time.Time) ([]VariantStats, error) {
+	var rows []struct {
+		Variant string `gorm:"column:variant"`
+		ID      string `gorm:"column:id"`
+	}
+	err := s.tweets(s.reader(ctx)).
+		Select("variant, id").
+		Where("variant <> '' AND created_at >= ?", since).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variant replies: %w", err)
+	}
+
+	replies, err := s.TopReplies(ctx, since, 0)
+	if err != nil {
+		return nil, err
+	}
+	performance := make(map[string]ReplyPerformance, len(replies))
+	for _, reply := range replies {
+		performance[reply.TweetID] = reply
+	}
+
+	byVariant := make(map[string]*VariantStats)
+	for _, row := range rows {
+		stats, ok := byVariant[row.Variant]
+		if !ok {
+			stats = &VariantStats{Variant: row.Variant}
+			byVariant[row.Variant] = stats
+		}
+		stats.Replies++
+
+		reply := performance[row.ID]
+		stats.Likes += reply.Likes
+		stats.Retweets += reply.Retweets
+		stats.Answers += reply.Replies
+		stats.Quotes += reply.Quotes
+	}
+
+	result := make([]VariantStats, 0, len(byVariant))
+	for _, stats := range byVariant {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AvgEngagement() > result[j].AvgEngagement()
+	})
+	return result, nil
+}
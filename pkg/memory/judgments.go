@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JudgmentRecord is a structured rating the bot gave a user
+type JudgmentRecord struct {
+	ID              int64  `json:"id"`
+	SubjectID       string `json:"subject_id"`
+	SubjectUsername string `json:"subject_username"`
+	// TweetID is the tweet that asked for or announced the judgment, if any
+	TweetID string `json:"tweet_id"`
+	// SubjectTweetID is the subject's tweet the judgment answered, if any
+	SubjectTweetID string         `json:"subject_tweet_id"`
+	Scores         map[string]int `json:"scores"`
+	Overall        float64        `json:"overall"`
+	Verdict        string         `json:"verdict"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// judgmentRow is the judgments table layout; scores are stored as JSON
+type judgmentRow struct {
+	ID              int64      `gorm:"column:id;primaryKey"`
+	BotID           string     `gorm:"column:bot_id"`
+	SubjectID       string     `gorm:"column:subject_id"`
+	SubjectUsername string     `gorm:"column:subject_username"`
+	TweetID         string     `gorm:"column:tweet_id"`
+	SubjectTweetID  string     `gorm:"column:subject_tweet_id"`
+	Scores          string     `gorm:"column:scores"`
+	Overall         float64    `gorm:"column:overall"`
+	Verdict         string     `gorm:"column:verdict"`
+	CreatedAt       time.Time  `gorm:"column:created_at"`
+	CuratedAt       *time.Time `gorm:"column:curated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (judgmentRow) TableName() string {
+	return "judgments"
+}
+
+// SaveJudgment stores a rating given by the bot
+func (s *TweetStore) SaveJudgment(ctx context.Context, judgment *JudgmentRecord) error {
+	if judgment.CreatedAt.IsZero() {
+		judgment.CreatedAt = time.Now()
+	}
+
+	err := s.db.WithContext(ctx).Table(judgmentRow{}.TableName()).Create(map[string]interface{}{
+		"bot_id":           s.BotID(),
+		"subject_id":       judgment.SubjectID,
+		"subject_username": strings.ToLower(strings.TrimPrefix(judgment.SubjectUsername, "@")),
+		"tweet_id":         judgment.TweetID,
+		"subject_tweet_id": judgment.SubjectTweetID,
+		"scores":           s.jsonColumn(judgment.Scores),
+		"overall":          judgment.Overall,
+		"verdict":          judgment.Verdict,
+		"created_at":       judgment.CreatedAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save judgment: %w", err)
+	}
+	return nil
+}
+
+// RecentJudgments returns the bot's latest judgments of a user, newest first,
+// so repeat roasts can reference or stay consistent with earlier scores
+func (s *TweetStore) RecentJudgments(ctx context.Context, username string, limit int) ([]JudgmentRecord, error) {
+	query := s.db.WithContext(ctx).
+		Where("bot_id = ? AND subject_username = ?", s.BotID(), strings.ToLower(strings.TrimPrefix(username, "@"))).
+		Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var rows []judgmentRow
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load judgments: %w", err)
+	}
+	return s.judgmentRecords(rows), nil
+}
+
+// UncuratedJudgments returns the judgments overall scoring at least
+// minOverall that the curator has not handled yet, oldest first
+func (s *TweetStore) UncuratedJudgments(ctx context.Context, minOverall float64, limit int) ([]JudgmentRecord, error) {
+	query := s.db.WithContext(ctx).
+		Where("bot_id = ? AND curated_at IS NULL AND overall >= ?", s.BotID(), minOverall).
+		Order("created_at ASC, id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var rows []judgmentRow
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load uncurated judgments: %w", err)
+	}
+	return s.judgmentRecords(rows), nil
+}
+
+// MarkJudgmentCurated records that the curator has handled a judgment
+func (s *TweetStore) MarkJudgmentCurated(ctx context.Context, id int64) error {
+	err := s.db.WithContext(ctx).Model(&judgmentRow{}).
+		Where("bot_id = ? AND id = ?", s.BotID(), id).
+		Update("curated_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark judgment curated: %w", err)
+	}
+	return nil
+}
+
+// judgmentRecords converts judgment rows, skipping rows with unreadable scores
+func (s *TweetStore) judgmentRecords(rows []judgmentRow) []JudgmentRecord {
+	judgments := make([]JudgmentRecord, 0, len(rows))
+	for _, row := range rows {
+		judgment := JudgmentRecord{
+			ID:              row.ID,
+			SubjectID:       row.SubjectID,
+			SubjectUsername: row.SubjectUsername,
+			TweetID:         row.TweetID,
+			SubjectTweetID:  row.SubjectTweetID,
+			Overall:         row.Overall,
+			Verdict:         row.Verdict,
+			CreatedAt:       row.CreatedAt,
+		}
+		if err := json.Unmarshal([]byte(row.Scores), &judgment.Scores); err != nil {
+			s.logger.WithError(err).WithField("judgment_id", row.ID).Warn("Skipping judgment with unreadable scores")
+			continue
+		}
+		judgments = append(judgments, judgment)
+	}
+	return judgments
+}
+
+// RoastCandidate is a user who recently mentioned the bot and may be judged
+type RoastCandidate struct {
+	AuthorID string
+	Username string
+	// TweetID and ConversationID identify the author's latest mention, which
+	// the judgment is posted in reply to
+	TweetID        string
+	ConversationID string
+	MentionedAt    time.Time
+}
+
+// RoastCandidates returns the authors who mentioned the bot since the given
+// time, most recent mention first, leaving out anyone judged since judgedSince.
+// Blocked authors, muted conversations and spam are never candidates.
+func (s *TweetStore) RoastCandidates(ctx context.Context, since, judgedSince time.Time, limit int) ([]RoastCandidate, error) {
+	s.mu.RLock()
+	botID, spamThreshold := s.botID, s.spamThreshold
+	s.mu.RUnlock()
+
+	query := s.tweets(s.db.WithContext(ctx)).
+		Select("id, author_id, author_username, conversation_id, created_at").
+		Where("category = ? AND author_id != ? AND created_at >= ?", CategoryMention, botID, since).
+		Where(`
+			author_id NOT IN (
+				SELECT user_id FROM blocked_users WHERE bot_id IN (?, '')
+			)
+			AND conversation_id NOT IN (
+				SELECT conversation_id FROM muted_conversations WHERE bot_id IN (?, '')
+			)
+			AND author_id NOT IN (
+				SELECT subject_id FROM judgments WHERE bot_id = ? AND created_at >= ?
+			)
+		`, botID, botID, botID, judgedSince).
+		Order("created_at DESC")
+	if spamThreshold > 0 {
+		query = query.Where("spam_score < ?", spamThreshold)
+	}
+
+	var rows []struct {
+		ID             string    `gorm:"column:id"`
+		AuthorID       string    `gorm:"column:author_id"`
+		AuthorUsername string    `gorm:"column:author_username"`
+		ConversationID string    `gorm:"column:conversation_id"`
+		CreatedAt      time.Time `gorm:"column:created_at"`
+	}
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to query roast candidates: %w", err)
+	}
+
+	// Keep each author's latest mention only
+	seen := make(map[string]bool)
+	var candidates []RoastCandidate
+	for _, row := range rows {
+		if row.AuthorUsername == "" || seen[row.AuthorID] {
+			continue
+		}
+		seen[row.AuthorID] = true
+		candidates = append(candidates, RoastCandidate{
+			AuthorID:       row.AuthorID,
+			Username:       row.AuthorUsername,
+			TweetID:        row.ID,
+			ConversationID: row.ConversationID,
+			MentionedAt:    row.CreatedAt,
+		})
+		if limit > 0 && len(candidates) >= limit {
+			break
+		}
+	}
+	return candidates, nil
+}
+
+// AuthorTweets returns the stored tweets of an author, newest first
+func (s *TweetStore) AuthorTweets(ctx context.Context, authorID string, limit int) ([]StoredTweet, error) {
+	query := s.tweets(s.db.WithContext(ctx)).
+		Where("author_id = ?", authorID).
+		Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var tweets []StoredTweet
+	if err := query.Find(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to load author tweets: %w", err)
+	}
+	return tweets, nil
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/lens"
+)
+
+// LensPublicationRecord is a post or comment the bot published on Lens
+type LensPublicationRecord struct {
+	ID        int64  `json:"id" gorm:"column:id;primaryKey"`
+	BotID     string `json:"bot_id" gorm:"column:bot_id"`
+	Kind      string `json:"kind" gorm:"column:kind"`
+	CommentOn string `json:"comment_on" gorm:"column:comment_on"`
+	Content   string `json:"content" gorm:"column:content"`
+	// ContentURI is the ipfs:// URI of the pinned metadata
+	ContentURI string    `json:"content_uri" gorm:"column:content_uri"`
+	TxHash     string    `json:"tx_hash" gorm:"column:tx_hash"`
+	TxID       string    `json:"tx_id" gorm:"column:tx_id"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (LensPublicationRecord) TableName() string {
+	return "lens_publications"
+}
+
+// SaveLensPublication implements lens.Recorder, keeping the metadata URI and
+// transaction hash of every publication
+func (s *TweetStore) SaveLensPublication(ctx context.Context, publication *lens.Publication) error {
+	createdAt := publication.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	err := s.db.WithContext(ctx).Table(LensPublicationRecord{}.TableName()).Create(map[string]interface{}{
+		"bot_id":      s.BotID(),
+		"kind":        publication.Kind,
+		"comment_on":  publication.CommentOn,
+		"content":     publication.Content,
+		"content_uri": publication.ContentURI,
+		"tx_hash":     publication.TxHash,
+		"tx_id":       publication.TxID,
+		"created_at":  createdAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save lens publication: %w", err)
+	}
+	return nil
+}
+
+// ListLensPublications returns the bot's most recent Lens publications, newest first
+func (s *TweetStore) ListLensPublications(ctx context.Context, limit int) ([]LensPublicationRecord, error) {
+	query := s.db.WithContext(ctx).Where("bot_id = ?", s.BotID()).Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var publications []LensPublicationRecord
+	if err := query.Find(&publications).Error; err != nil {
+		return nil, fmt.Errorf("failed to list lens publications: %w", err)
+	}
+	return publications, nil
+}
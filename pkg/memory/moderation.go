@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlockedUser is an author whose tweets the agent ignores. Entries with an
+// empty BotID apply to every bot account.
+type BlockedUser struct {
+	BotID     string    `json:"bot_id" gorm:"column:bot_id;primaryKey"`
+	UserID    string    `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Username  string    `json:"username" gorm:"column:username"`
+	Reason    string    `json:"reason" gorm:"column:reason"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (BlockedUser) TableName() string {
+	return "blocked_users"
+}
+
+// MutedConversation is a thread the agent has disengaged from. Entries with an
+// empty BotID apply to every bot account.
+type MutedConversation struct {
+	BotID          string    `json:"bot_id" gorm:"column:bot_id;primaryKey"`
+	ConversationID string    `json:"conversation_id" gorm:"column:conversation_id;primaryKey"`
+	Reason         string    `json:"reason" gorm:"column:reason"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (MutedConversation) TableName() string {
+	return "muted_conversations"
+}
+
+// Blocklist is a snapshot of the blocked users and muted conversations that
+// apply to one bot account, for filtering a batch of tweets
+type Blocklist struct {
+	users         map[string]struct{}
+	conversations map[string]struct{}
+}
+
+// Skip reports whether a tweet by authorID in conversationID must be ignored,
+// and why
+func (b *Blocklist) Skip(authorID, conversationID string) (bool, string) {
+	if b == nil {
+		return false, ""
+	}
+	if _, ok := b.users[authorID]; ok {
+		return true, "blocked author"
+	}
+	if _, ok := b.conversations[conversationID]; conversationID != "" && ok {
+		return true, "muted conversation"
+	}
+	return false, ""
+}
+
+// Len returns the number of blocked users and muted conversations
+func (b *Blocklist) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.users) + len(b.conversations)
+}
+
+// BlockUser stops the agent from engaging with a user's tweets
+func (s *TweetStore) BlockUser(ctx context.Context, userID, username, reason string) error {
+	entry := BlockedUser{
+		BotID:     s.BotID(),
+		UserID:    userID,
+		Username:  username,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "bot_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"username", "reason"}),
+		}).
+		Create(&entry).Error
+	if err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"bot_id":  entry.BotID,
+		"user_id": userID,
+		"reason":  reason,
+	}).Info("Blocked user")
+	return nil
+}
+
+// UnblockUser removes a user from the blocklist. It reports whether the user was blocked.
+func (s *TweetStore) UnblockUser(ctx context.Context, userID string) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Where("bot_id = ? AND user_id = ?", s.BotID(), userID).
+		Delete(&BlockedUser{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to unblock user: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListBlockedUsers returns the users blocked for this bot, including global entries
+func (s *TweetStore) ListBlockedUsers(ctx context.Context) ([]BlockedUser, error) {
+	var users []BlockedUser
+	err := s.moderationScope(s.db.WithContext(ctx)).
+		Order("created_at ASC").
+		Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	return users, nil
+}
+
+// MuteConversation stops the agent from replying anywhere in a conversation
+func (s *TweetStore) MuteConversation(ctx context.Context, conversationID, reason string) error {
+	entry := MutedConversation{
+		BotID:          s.BotID(),
+		ConversationID: conversationID,
+		Reason:         reason,
+		CreatedAt:      time.Now(),
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "bot_id"}, {Name: "conversation_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason"}),
+		}).
+		Create(&entry).Error
+	if err != nil {
+		return fmt.Errorf("failed to mute conversation: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"bot_id":          entry.BotID,
+		"conversation_id": conversationID,
+		"reason":          reason,
+	}).Info("Muted conversation")
+	return nil
+}
+
+// UnmuteConversation lets the agent engage in a conversation again. It reports
+// whether the conversation was muted.
+func (s *TweetStore) UnmuteConversation(ctx context.Context, conversationID string) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Where("bot_id = ? AND conversation_id = ?", s.BotID(), conversationID).
+		Delete(&MutedConversation{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to unmute conversation: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListMutedConversations returns the conversations muted for this bot, including global entries
+func (s *TweetStore) ListMutedConversations(ctx context.Context) ([]MutedConversation, error) {
+	var conversations []MutedConversation
+	err := s.moderationScope(s.db.WithContext(ctx)).
+		Order("created_at ASC").
+		Find(&conversations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list muted conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+// LoadBlocklist loads the blocked users and muted conversations that apply to this bot
+func (s *TweetStore) LoadBlocklist(ctx context.Context) (*Blocklist, error) {
+	users, err := s.ListBlockedUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conversations, err := s.ListMutedConversations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blocklist := &Blocklist{
+		users:         make(map[string]struct{}, len(users)),
+		conversations: make(map[string]struct{}, len(conversations)),
+	}
+	for _, user := range users {
+		blocklist.users[user.UserID] = struct{}{}
+	}
+	for _, conversation := range conversations {
+		blocklist.conversations[conversation.ConversationID] = struct{}{}
+	}
+	return blocklist, nil
+}
+
+// moderationScope restricts a moderation query to this bot's entries and global ones
+func (s *TweetStore) moderationScope(db *gorm.DB) *gorm.DB {
+	return db.Where("bot_id IN (?, '')", s.BotID())
+}
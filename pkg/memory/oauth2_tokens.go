@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OAuth2TokenRow is an account's OAuth 2.0 token with both tokens encrypted
+type OAuth2TokenRow struct {
+	Account      string    `json:"account" gorm:"column:account;primaryKey"`
+	AccessToken  string    `json:"-" gorm:"column:access_token"`
+	RefreshToken string    `json:"-" gorm:"column:refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"column:expires_at"`
+	Scopes       string    `json:"scopes" gorm:"column:scopes"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (OAuth2TokenRow) TableName() string {
+	return "oauth2_tokens"
+}
+
+// OAuth2Tokens stores the OAuth 2.0 token of one account, encrypted with
+// AES-256-GCM. Tokens belong to the account rather than the bot ID, which is
+// only known once the token has been used.
+type OAuth2Tokens struct {
+	db      *gorm.DB
+	account string
+	aead    cipher.AEAD
+}
+
+// NewOAuth2Tokens returns the token store of account, encrypting with the
+// 32 byte key
+func NewOAuth2Tokens(db *gorm.DB, account string, key []byte) (*OAuth2Tokens, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token key: %w", err)
+	}
+	return &OAuth2Tokens{db: db, account: account, aead: aead}, nil
+}
+
+// LoadOAuth2Token implements twitter.OAuth2TokenStore
+func (t *OAuth2Tokens) LoadOAuth2Token(ctx context.Context) (*twitter.OAuth2Token, error) {
+	var rows []OAuth2TokenRow
+	err := t.db.WithContext(ctx).
+		Where("account = ?", t.account).
+		Limit(1).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OAuth 2.0 token: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	row := rows[0]
+
+	accessToken, err := t.decrypt(row.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := t.decrypt(row.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return &twitter.OAuth2Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Expiry:       row.ExpiresAt,
+		Scopes:       strings.Fields(row.Scopes),
+	}, nil
+}
+
+// SaveOAuth2Token implements twitter.OAuth2TokenStore, replacing the
+// account's token
+func (t *OAuth2Tokens) SaveOAuth2Token(ctx context.Context, token twitter.OAuth2Token) error {
+	accessToken, err := t.encrypt(token.AccessToken)
+	if err != nil {
+		return err
+	}
+	refreshToken, err := t.encrypt(token.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	row := OAuth2TokenRow{
+		Account:      t.account,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    token.Expiry,
+		Scopes:       strings.Join(token.Scopes, " "),
+		UpdatedAt:    time.Now(),
+	}
+	err = t.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account"}},
+		DoUpdates: clause.AssignmentColumns([]string{"access_token", "refresh_token", "expires_at", "scopes", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return fmt.Errorf("failed to save OAuth 2.0 token: %w", err)
+	}
+	return nil
+}
+
+// encrypt seals plaintext under a random nonce, bound to the account so a
+// token cannot be moved to another account's row. Empty values stay empty.
+func (t *OAuth2Tokens) encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := t.aead.Seal(nonce, nonce, []byte(plaintext), []byte(t.account))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a value sealed by encrypt
+func (t *OAuth2Tokens) decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode stored token: %w", err)
+	}
+	if len(sealed) < t.aead.NonceSize() {
+		return "", errors.New("stored token is truncated")
+	}
+	nonce, sealed := sealed[:t.aead.NonceSize()], sealed[t.aead.NonceSize():]
+	plaintext, err := t.aead.Open(nil, nonce, sealed, []byte(t.account))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt stored token, was the token key changed? %w", err)
+	}
+	return string(plaintext), nil
+}
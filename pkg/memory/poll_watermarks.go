@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Streams with a poll watermark
+const (
+	StreamMentions = "mentions"
+)
+
+// PollWatermark is the newest tweet ID a bot has seen on a polled timeline
+type PollWatermark struct {
+	BotID     string    `json:"bot_id" gorm:"column:bot_id;primaryKey"`
+	Stream    string    `json:"stream" gorm:"column:stream;primaryKey"`
+	NewestID  string    `json:"newest_id" gorm:"column:newest_id"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (PollWatermark) TableName() string {
+	return "poll_watermarks"
+}
+
+// Watermark returns the newest tweet ID seen on stream, or "" before the
+// first poll
+func (s *TweetStore) Watermark(ctx context.Context, stream string) (string, error) {
+	var watermarks []PollWatermark
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND stream = ?", s.BotID(), stream).
+		Limit(1).
+		Find(&watermarks).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to load %s watermark: %w", stream, err)
+	}
+	if len(watermarks) == 0 {
+		return "", nil
+	}
+	return watermarks[0].NewestID, nil
+}
+
+// SaveWatermark records newestID as the newest tweet ID seen on stream
+func (s *TweetStore) SaveWatermark(ctx context.Context, stream, newestID string) error {
+	row := PollWatermark{
+		BotID:     s.BotID(),
+		Stream:    stream,
+		NewestID:  newestID,
+		UpdatedAt: time.Now(),
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bot_id"}, {Name: "stream"}},
+		DoUpdates: clause.AssignmentColumns([]string{"newest_id", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return fmt.Errorf("failed to save %s watermark: %w", stream, err)
+	}
+	return nil
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -28,16 +28,32 @@ type TweetNeedingReply struct {
 	RepliedTo       bool            `json:"replied_to" gorm:"column:replied_to"`
 	ReplyCount      int             `json:"reply_count" gorm:"column:reply_count"`
 	InReplyToUserID string          `json:"in_reply_to_user_id" gorm:"column:in_reply_to_user_id"`
-	ConversationRef json.RawMessage `json:"conversation_ref" gorm:"column:conversation_ref"`
-	Entities        json.RawMessage `json:"entities" gorm:"column:entities"`
+	Sentiment       float64         `json:"sentiment" gorm:"column:sentiment"`
+	AuthorFollowers int             `json:"author_followers" gorm:"column:author_followers"`
+	AuthorVerified  bool            `json:"author_verified" gorm:"column:author_verified"`
+	ConversationRef json.RawMessage `json:"conversation_ref" gorm:"column:conversation_ref;serializer:json"`
+	Entities        json.RawMessage `json:"entities" gorm:"column:entities;serializer:json"`
 	Lang            string          `json:"lang" gorm:"column:lang"`
 }
 
 // ConversationThread represents a complete conversation thread needing reply
 type ConversationThread struct {
 	ConversationID string
-	Tweets         []TweetNeedingReply
-	LastReplyTime  time.Time
+	// Tweets is the whole stored conversation, oldest first, for context
+	Tweets []TweetNeedingReply
+	// NeedingReply are the tweets of the conversation matched by the recall, oldest first
+	NeedingReply  []TweetNeedingReply
+	LastReplyTime time.Time
+	// Priority is the thread's score when the store has priority weights
+	Priority float64
+}
+
+// Latest returns the newest tweet of the thread that needs a reply
+func (t ConversationThread) Latest() (TweetNeedingReply, bool) {
+	if len(t.NeedingReply) == 0 {
+		return TweetNeedingReply{}, false
+	}
+	return t.NeedingReply[len(t.NeedingReply)-1], true
 }
 
 // EnvConfig interface defines methods for accessing environment variables
@@ -50,199 +66,295 @@ type TwitterClient interface {
 	GetAuthenticatedUserID(ctx context.Context) (string, error)
 }
 
+// DefaultRecallCategories are the categories whose unanswered tweets need an
+// initial reply when RecallOptions.Categories is empty
+var DefaultRecallCategories = []TweetCategory{CategoryMention, CategoryConversation}
+
+// RecallOptions selects which tweets are recalled for a reply. The zero value
+// recalls unanswered mentions and conversation tweets of any age, plus new
+// activity in conversations the bot takes part in.
+type RecallOptions struct {
+	// Categories are the categories whose unanswered tweets need an initial
+	// reply; DefaultRecallCategories when empty
+	Categories []TweetCategory
+	// MaxAge leaves out tweets older than this; 0 means no limit
+	MaxAge time.Duration
+	// SkipParticipating leaves out new activity in conversations the bot
+	// already takes part in unless it matches one of the categories
+	SkipParticipating bool
+	// Limit caps the number of tweets recalled, oldest first; 0 means no limit
+	Limit int
+}
+
 // RecallTweetsNeedingReply finds conversations where:
 // 1. We have participated (replied) or are mentioned
 // 2. There are new replies after our last reply
 // 3. We haven't processed those replies yet
 func (s *TweetStore) RecallTweetsNeedingReply(ctx context.Context, client TwitterClient) ([]ConversationThread, error) {
+	return s.RecallTweetsNeedingReplyWithOptions(ctx, client, RecallOptions{})
+}
+
+// RecallTweetsNeedingReplyWithOptions is RecallTweetsNeedingReply with filters
+// on category, age and participation. Threads are ordered by priority score,
+// highest first, when the store has priority weights, and otherwise by their
+// oldest tweet needing a reply.
+func (s *TweetStore) RecallTweetsNeedingReplyWithOptions(ctx context.Context, client TwitterClient, opts RecallOptions) ([]ConversationThread, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	log := s.logger.WithField("method", "RecallTweetsNeedingReply")
 
-	// First try to get userID from env or stored botID
-	var userID string
-	if envUserID := s.env.GetString("TWITTER_USER_ID"); envUserID != "" {
-		userID = envUserID
-		log.WithField("source", "env").Debug("Using user ID from environment")
-	} else if s.botID != "" {
-		userID = s.botID
-		log.WithField("source", "store").Debug("Using stored bot ID")
-	} else {
-		// Fallback to API call if no stored ID
-		var err error
-		userID, err = client.GetAuthenticatedUserID(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get authenticated user ID: %w", err)
-		}
-		log.WithField("source", "api").Debug("Retrieved user ID from API")
+	userID, err := s.recallUserID(ctx, client)
+	if err != nil {
+		return nil, err
 	}
-
 	log.WithField("user_id", userID).Debug("Using user ID for tweet recall")
 
-	var needingReply []TweetNeedingReply
-
-	// Query the database for tweets needing reply
-	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		query := tx.Table("tweets").
-			Select(`
-				tweets.id,
-				tweets.text,
-				tweets.conversation_id,
-				tweets.created_at,
-				tweets.category,
-				tweets.author_id,
-				tweets.author_name,
-				tweets.author_username,
-				tweets.last_reply_id,
-				tweets.last_reply_time,
-				tweets.unread_replies,
-				tweets.is_participating,
-				tweets.replied_to,
-				tweets.reply_count,
-				tweets.in_reply_to_user_id,
-				tweets.conversation_ref,
-				tweets.entities,
-				tweets.lang
-			`).
-			Joins(`
-				LEFT JOIN (
-					SELECT 
-						conversation_id,
-						MAX(created_at) as bot_reply_time
-					FROM tweets
-					WHERE author_id = ? 
-					AND category = 'reply'
-					GROUP BY conversation_id
-				) last_bot_reply ON tweets.conversation_id = last_bot_reply.conversation_id
-			`, userID).
-			Where(`
-				tweets.author_id != ? AND (
-					-- Case 1: New mentions needing initial reply
-					(tweets.category = 'mention' AND tweets.replied_to = FALSE)
-					OR
-					-- Case 2: New conversation tweets needing response
-					(tweets.category = 'conversation' AND tweets.replied_to = FALSE)
-					OR
-					-- Case 3: Active conversations with new activity
-					(
-						tweets.conversation_id IN (
-							SELECT DISTINCT conversation_id 
-							FROM tweets 
-							WHERE is_participating = TRUE
-						)
-						AND tweets.replied_to = FALSE
-						AND (
-							tweets.unread_replies > 0
-							OR
-							tweets.created_at > COALESCE(last_bot_reply.bot_reply_time, '1970-01-01')
-						)
-					)
-				)
-			`, userID).
-			Order("tweets.created_at ASC")
-
-		// Add debug logging for the query
-		log.WithFields(logrus.Fields{
-			"sql":  query.Statement.SQL.String(),
-			"vars": query.Statement.Vars,
-		}).Debug("Executing recall query")
-
-		result := query.Find(&needingReply)
-		if result.Error != nil {
-			return fmt.Errorf("failed to query tweets needing reply: %w", result.Error)
-		}
+	categories := opts.Categories
+	if len(categories) == 0 {
+		categories = DefaultRecallCategories
+	}
 
-		log.WithFields(logrus.Fields{
-			"tweets_found": len(needingReply),
-			"query_time":   time.Now(),
-		}).Debug("Found tweets needing reply")
+	query := newReplyQuery(s.reader(ctx), s.botID, userID).
+		needingReply(categories, !opts.SkipParticipating).
+		excludeModerated().
+		belowSpamScore(s.spamThreshold).
+		limit(opts.Limit)
+	if opts.MaxAge > 0 {
+		query = query.since(time.Now().Add(-opts.MaxAge))
+	}
 
-		return nil
-	})
+	// Add debug logging for the query
+	log.WithFields(logrus.Fields{
+		"categories":    categories,
+		"max_age":       opts.MaxAge,
+		"participating": !opts.SkipParticipating,
+		"limit":         opts.Limit,
+	}).Debug("Executing recall query")
 
+	needingReply, err := query.find()
 	if err != nil {
-		return nil, fmt.Errorf("database transaction failed: %w", err)
+		return nil, err
 	}
 
-	// Group tweets by conversation with debug logging
-	conversationsMap := make(map[string]*ConversationThread)
-	for i := range needingReply {
-		tweet := needingReply[i]
+	threads, err := s.groupThreads(ctx, needingReply)
+	if err != nil {
+		return nil, err
+	}
+	s.priority.sortThreads(threads, userID, time.Now())
+
+	log.WithFields(logrus.Fields{
+		"conversations_found": len(threads),
+		"total_tweets":        len(needingReply),
+	}).Info("Completed recall of conversation threads needing reply")
+
+	return threads, nil
+}
+
+// recallUserID returns the bot's user ID, preferring the store's own bot ID,
+// which is per account, over the env setting and the API
+func (s *TweetStore) recallUserID(ctx context.Context, client TwitterClient) (string, error) {
+	if s.botID != "" {
+		return s.botID, nil
+	}
+	if envUserID := s.env.GetString("TWITTER_USER_ID"); envUserID != "" {
+		return envUserID, nil
+	}
+	userID, err := client.GetAuthenticatedUserID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get authenticated user ID: %w", err)
+	}
+	return userID, nil
+}
+
+// groupThreads loads the stored conversation of every recalled tweet and
+// groups the tweets by conversation, keeping the recall order
+func (s *TweetStore) groupThreads(ctx context.Context, needingReply []TweetNeedingReply) ([]ConversationThread, error) {
+	if len(needingReply) == 0 {
+		return nil, nil
+	}
 
-		thread, exists := conversationsMap[tweet.ConversationID]
+	var order []string
+	byConversation := make(map[string]*ConversationThread)
+	for _, tweet := range needingReply {
+		thread, exists := byConversation[tweet.ConversationID]
 		if !exists {
-			// Get full conversation context
-			var contextTweets []TweetNeedingReply
-			err := s.db.Table("tweets").
-				Where("conversation_id = ?", tweet.ConversationID).
-				Order("created_at ASC").
-				Find(&contextTweets).Error
-
-			if err != nil {
-				log.WithError(err).Error("Failed to get conversation context")
-				continue
-			}
-
-			thread = &ConversationThread{
-				ConversationID: tweet.ConversationID,
-				Tweets:         contextTweets,
-				LastReplyTime:  tweet.LastReplyTime,
-			}
-			conversationsMap[tweet.ConversationID] = thread
+			thread = &ConversationThread{ConversationID: tweet.ConversationID}
+			byConversation[tweet.ConversationID] = thread
+			order = append(order, tweet.ConversationID)
 		}
-
-		// Update thread metadata
+		thread.NeedingReply = append(thread.NeedingReply, tweet)
 		if tweet.LastReplyTime.After(thread.LastReplyTime) {
 			thread.LastReplyTime = tweet.LastReplyTime
 		}
+	}
 
-		log.WithFields(logrus.Fields{
-			"tweet_id":         tweet.TweetID,
-			"conversation_id":  tweet.ConversationID,
-			"context_tweets":   len(thread.Tweets),
-			"is_participating": tweet.IsParticipating,
-			"category":         tweet.Category,
-		}).Debug("Processing tweet with context")
-	}
-
-	// Convert map to slice and ensure tweets are properly ordered
-	var result []ConversationThread
-	for _, thread := range conversationsMap {
-		// Sort tweets within each thread by creation time
-		sort.Slice(thread.Tweets, func(i, j int) bool {
-			return thread.Tweets[i].CreatedAt.Before(thread.Tweets[j].CreatedAt)
-		})
-
-		// Check if thread needs reply
-		needsReply := false
-		for _, tweet := range thread.Tweets {
-			if tweet.AuthorID != userID && (tweet.UnreadReplies > 0 ||
-				(tweet.Category == "mention" && !tweet.RepliedTo) ||
-				(tweet.Category == "conversation" && !tweet.RepliedTo) ||
-				(tweet.IsParticipating && tweet.CreatedAt.After(thread.LastReplyTime))) {
-				needsReply = true
-				break
-			}
+	// Get full conversation context for every thread in one query
+	var contextTweets []TweetNeedingReply
+	err := s.tweets(s.reader(ctx)).
+		Where("conversation_id IN ? AND deleted_at IS NULL", order).
+		Order("created_at ASC").
+		Find(&contextTweets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation context: %w", err)
+	}
+	for _, tweet := range contextTweets {
+		if thread, ok := byConversation[tweet.ConversationID]; ok {
+			thread.Tweets = append(thread.Tweets, tweet)
 		}
+	}
 
-		log.WithFields(logrus.Fields{
-			"conversation_id": thread.ConversationID,
-			"tweets_count":    len(thread.Tweets),
-			"needs_reply":     needsReply,
-			"last_reply_time": thread.LastReplyTime,
-		}).Debug("Processing conversation thread")
+	threads := make([]ConversationThread, 0, len(order))
+	for _, conversationID := range order {
+		threads = append(threads, *byConversation[conversationID])
+	}
+	return threads, nil
+}
+
+// replyQuery builds the recall query one filter at a time
+type replyQuery struct {
+	db    *gorm.DB
+	botID string
+}
 
-		if needsReply {
-			result = append(result, *thread)
+// newReplyQuery starts a query on the bot's tweets written by others that are
+// not deleted, oldest first, joined with the time of the bot's last reply in
+// each conversation
+func newReplyQuery(db *gorm.DB, botID, userID string) *replyQuery {
+	query := db.Table("tweets").
+		Select(`
+			tweets.id,
+			tweets.text,
+			tweets.conversation_id,
+			tweets.created_at,
+			tweets.category,
+			tweets.author_id,
+			tweets.author_name,
+			tweets.author_username,
+			tweets.last_reply_id,
+			tweets.last_reply_time,
+			tweets.unread_replies,
+			tweets.is_participating,
+			tweets.replied_to,
+			tweets.reply_count,
+			tweets.in_reply_to_user_id,
+			tweets.conversation_ref,
+			tweets.entities,
+			tweets.lang,
+			tweets.sentiment,
+			tweets.author_followers,
+			tweets.author_verified
+		`).
+		Joins(`
+			LEFT JOIN (
+				SELECT
+					conversation_id,
+					MAX(created_at) as bot_reply_time
+				FROM tweets
+				WHERE author_id = ?
+				AND bot_id = ?
+				AND category = 'reply'
+				GROUP BY conversation_id
+			) last_bot_reply ON tweets.conversation_id = last_bot_reply.conversation_id
+		`, userID, botID).
+		Where("tweets.bot_id = ? AND tweets.author_id != ?", botID, userID).
+		Where("tweets.deleted_at IS NULL").
+		Order("tweets.created_at ASC")
+
+	return &replyQuery{db: query, botID: botID}
+}
+
+// needingReply keeps unanswered tweets of the given categories and, when
+// participating is set, unanswered new activity in conversations the bot
+// takes part in
+func (q *replyQuery) needingReply(categories []TweetCategory, participating bool) *replyQuery {
+	var cases []string
+	var args []interface{}
+
+	if len(categories) > 0 {
+		names := make([]string, len(categories))
+		for i, category := range categories {
+			names[i] = string(category)
 		}
+		// New tweets of these categories needing an initial reply
+		cases = append(cases, "(tweets.category IN ? AND tweets.replied_to = FALSE)")
+		args = append(args, names)
 	}
 
-	log.WithFields(logrus.Fields{
-		"conversations_found": len(result),
-		"total_tweets":        len(needingReply),
-		"query_time":          time.Now(),
-	}).Info("Completed recall of conversation threads needing reply")
+	if participating {
+		// Active conversations with new activity
+		cases = append(cases, `(
+			tweets.conversation_id IN (
+				SELECT DISTINCT conversation_id
+				FROM tweets
+				WHERE is_participating = TRUE
+				AND bot_id = ?
+			)
+			AND tweets.replied_to = FALSE
+			AND (
+				tweets.unread_replies > 0
+				OR
+				tweets.created_at > COALESCE(last_bot_reply.bot_reply_time, '1970-01-01')
+			)
+		)`)
+		args = append(args, q.botID)
+	}
 
-	return result, nil
+	if len(cases) == 0 {
+		q.db = q.db.Where("1 = 0")
+		return q
+	}
+	q.db = q.db.Where("("+strings.Join(cases, " OR ")+")", args...)
+	return q
+}
+
+// excludeModerated leaves out blocked authors and muted threads
+func (q *replyQuery) excludeModerated() *replyQuery {
+	q.db = q.db.Where(`
+		tweets.author_id NOT IN (
+			SELECT user_id FROM blocked_users WHERE bot_id IN (?, '')
+		)
+		AND tweets.conversation_id NOT IN (
+			SELECT conversation_id FROM muted_conversations WHERE bot_id IN (?, '')
+		)
+	`, q.botID, q.botID)
+	return q
+}
+
+// belowSpamScore leaves tweets the spam filter flagged in the store but out of
+// the queue; a threshold of 0 disables the filter
+func (q *replyQuery) belowSpamScore(threshold float64) *replyQuery {
+	if threshold > 0 {
+		q.db = q.db.Where("tweets.spam_score < ?", threshold)
+	}
+	return q
+}
+
+// since leaves out tweets created before t
+func (q *replyQuery) since(t time.Time) *replyQuery {
+	q.db = q.db.Where("tweets.created_at >= ?", t)
+	return q
+}
+
+// only restricts the query to a single tweet
+func (q *replyQuery) only(tweetID string) *replyQuery {
+	q.db = q.db.Where("tweets.id = ?", tweetID)
+	return q
+}
+
+// limit caps the number of tweets; 0 means no limit
+func (q *replyQuery) limit(n int) *replyQuery {
+	if n > 0 {
+		q.db = q.db.Limit(n)
+	}
+	return q
+}
+
+// find runs the query
+func (q *replyQuery) find() ([]TweetNeedingReply, error) {
+	var tweets []TweetNeedingReply
+	if err := q.db.Find(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to query tweets needing reply: %w", err)
+	}
+	return tweets, nil
 }
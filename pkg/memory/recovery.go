@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"gorm.io/gorm"
+)
+
+// SaveRecoveredReply records a reply the bot posted to originalTweetID but
+// failed to store, e.g. because the process crashed right after posting. The
+// original tweet is marked as replied to and taken off the reply queue, so it
+// is not answered twice.
+func (s *TweetStore) SaveRecoveredReply(ctx context.Context, originalTweetID string, reply twitter.Tweet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	postedAt := now
+	if created, err := time.Parse(time.RFC3339, reply.CreatedAt); err == nil {
+		postedAt = created
+	}
+
+	row := s.agentReplyRow(originalTweetID, reply.ID, reply.ConversationID, reply.Text, now)
+	row["created_at"] = postedAt
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("tweets").Create(row).Error; err != nil {
+			return fmt.Errorf("failed to save recovered reply: %w", err)
+		}
+
+		if err := s.tweets(tx).
+			Where("id = ?", originalTweetID).
+			Updates(map[string]interface{}{
+				"replied_to":       true,
+				"needs_reply":      false,
+				"last_reply_id":    reply.ID,
+				"last_reply_time":  postedAt,
+				"last_updated":     now,
+				"is_participating": true,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to update original tweet: %w", err)
+		}
+
+		if err := tx.Where("bot_id = ? AND tweet_id = ?", s.botID, originalTweetID).
+			Delete(&ReplyWorkItem{}).Error; err != nil {
+			return fmt.Errorf("failed to dequeue original tweet: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReleaseClaims makes the queued tweets claimed by workers whose name starts
+// with prefix visible again, except those of keep. Reply workers call it on
+// startup to hand back the claims of an earlier process on the same host,
+// which would otherwise stay hidden until their visibility timeout.
+func (s *TweetStore) ReleaseClaims(ctx context.Context, prefix, keep string) (int64, error) {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&ReplyWorkItem{}).
+		Where("bot_id = ? AND claimed_by LIKE ? AND claimed_by <> ? AND visible_at > ?", s.BotID(), prefix+"%", keep, now).
+		Updates(map[string]interface{}{
+			"visible_at": now,
+			"claimed_by": "",
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to release claims: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
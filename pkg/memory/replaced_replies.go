@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrReplyNotFound is returned for a tweet ID that is not the latest reply
+// of the bot to a stored tweet
+var ErrReplyNotFound = errors.New("reply not found")
+
+// RepliedTweet returns the tweet the bot answered with replyID. Only the
+// bot's latest reply to a tweet is linked to it.
+func (s *TweetStore) RepliedTweet(ctx context.Context, replyID string) (*TweetNeedingReply, error) {
+	var tweets []TweetNeedingReply
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("last_reply_id = ?", replyID).
+		Where("EXISTS (SELECT 1 FROM tweets r WHERE r.bot_id = ? AND r.id = ? AND r.author_id = ?)", s.BotID(), replyID, s.BotID()).
+		Limit(1).
+		Find(&tweets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tweet answered by %s: %w", replyID, err)
+	}
+	if len(tweets) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrReplyNotFound, replyID)
+	}
+	return &tweets[0], nil
+}
+
+// ReplaceAgentReply swaps a deleted reply of the bot for the one posted in
+// its place, in one transaction. An empty newReplyID only removes the old
+// reply and marks the original tweet as needing a reply again.
+func (s *TweetStore) ReplaceAgentReply(ctx context.Context, originalTweetID, oldReplyID, newReplyID, conversationID, replyText string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.tweets(tx).Where("id = ?", oldReplyID).Delete(&StoredTweet{}).Error; err != nil {
+			return fmt.Errorf("failed to remove replaced reply: %w", err)
+		}
+
+		// The replacement answers the tweet as it reads now, edits included
+		original := map[string]interface{}{
+			"replied_to":         false,
+			"needs_reply":        true,
+			"last_reply_id":      nil,
+			"last_updated":       now,
+			"last_reply_time":    nil,
+			"edited_after_reply": false,
+		}
+		if newReplyID != "" {
+			if err := tx.Table("tweets").Create(s.agentReplyRow(originalTweetID, newReplyID, conversationID, replyText, now)).Error; err != nil {
+				return fmt.Errorf("failed to save replacement reply: %w", err)
+			}
+			original = map[string]interface{}{
+				"replied_to":         true,
+				"needs_reply":        false,
+				"last_reply_id":      newReplyID,
+				"last_updated":       now,
+				"last_reply_time":    now,
+				"edited_after_reply": false,
+			}
+		}
+
+		if err := s.tweets(tx).Where("id = ?", originalTweetID).Updates(original).Error; err != nil {
+			return fmt.Errorf("failed to update original tweet: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserReplyCount is the number of replies the bot posted to a user on one UTC day
+type UserReplyCount struct {
+	BotID   string    `json:"bot_id" gorm:"column:bot_id;primaryKey"`
+	UserID  string    `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Day     time.Time `json:"day" gorm:"column:day;primaryKey"`
+	Replies int       `json:"replies" gorm:"column:replies"`
+}
+
+// TableName specifies the table name for GORM
+func (UserReplyCount) TableName() string {
+	return "user_reply_counts"
+}
+
+// ConversationReplyCount is the number of replies the bot posted in a conversation
+type ConversationReplyCount struct {
+	BotID          string    `json:"bot_id" gorm:"column:bot_id;primaryKey"`
+	ConversationID string    `json:"conversation_id" gorm:"column:conversation_id;primaryKey"`
+	Replies        int       `json:"replies" gorm:"column:replies"`
+	LastReplyAt    time.Time `json:"last_reply_at" gorm:"column:last_reply_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ConversationReplyCount) TableName() string {
+	return "conversation_reply_counts"
+}
+
+// ReplyCounts are the counters a reply to a user in a conversation is checked against
+type ReplyCounts struct {
+	// UserToday is the number of replies to the user since the start of the UTC day
+	UserToday int
+	// Conversation is the number of replies in the conversation
+	Conversation int
+	// LastConversationReply is when the bot last replied in the conversation,
+	// zero if it never did
+	LastConversationReply time.Time
+}
+
+// ReplyCounts loads the reply counters of a user and a conversation as of now
+func (s *TweetStore) ReplyCounts(ctx context.Context, userID, conversationID string, now time.Time) (ReplyCounts, error) {
+	var counts ReplyCounts
+
+	var user UserReplyCount
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND user_id = ? AND day = ?", s.BotID(), userID, startOfDay(now)).
+		Take(&user).Error
+	switch {
+	case err == nil:
+		counts.UserToday = user.Replies
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return counts, fmt.Errorf("failed to load user reply count: %w", err)
+	}
+
+	var conversation ConversationReplyCount
+	err = s.db.WithContext(ctx).
+		Where("bot_id = ? AND conversation_id = ?", s.BotID(), conversationID).
+		Take(&conversation).Error
+	switch {
+	case err == nil:
+		counts.Conversation = conversation.Replies
+		counts.LastConversationReply = conversation.LastReplyAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return counts, fmt.Errorf("failed to load conversation reply count: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountReply increments the counters of the user replied to and the
+// conversation for a reply posted at the given time
+func (s *TweetStore) CountReply(ctx context.Context, userID, conversationID string, at time.Time) error {
+	botID := s.BotID()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if userID != "" {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "bot_id"}, {Name: "user_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"replies": gorm.Expr("user_reply_counts.replies + 1"),
+				}),
+			}).Create(&UserReplyCount{BotID: botID, UserID: userID, Day: startOfDay(at), Replies: 1}).Error
+			if err != nil {
+				return fmt.Errorf("failed to count reply to user: %w", err)
+			}
+		}
+
+		if conversationID != "" {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "bot_id"}, {Name: "conversation_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"replies":       gorm.Expr("conversation_reply_counts.replies + 1"),
+					"last_reply_at": at,
+				}),
+			}).Create(&ConversationReplyCount{BotID: botID, ConversationID: conversationID, Replies: 1, LastReplyAt: at}).Error
+			if err != nil {
+				return fmt.Errorf("failed to count reply in conversation: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ReplyExample is a curated tweet and the ideal reply to it, shown to the
+// model as an example of the bot's voice. Negative examples are replies of
+// the bot that failed, shown as what not to write.
+type ReplyExample struct {
+	ID        int64  `json:"id"`
+	TweetText string `json:"tweet_text"`
+	ReplyText string `json:"reply_text"`
+	// Category optionally limits the example to one interaction category
+	Category string `json:"category,omitempty"`
+	Negative bool   `json:"negative,omitempty"`
+	// Reason why a negative example failed, e.g. ReasonDeleted
+	Reason string `json:"reason,omitempty"`
+	// SourceTweetID is the bot's reply a negative example was taken from
+	SourceTweetID string `json:"source_tweet_id,omitempty"`
+	// Embedding of TweetText; empty when no embedder was available
+	Embedding []float32 `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Reasons a reply became a negative example
+const (
+	// ReasonDeleted marks a reply that was deleted after posting
+	ReasonDeleted = "deleted"
+	// ReasonNoEngagement marks a reply nobody liked, retweeted, quoted or answered
+	ReasonNoEngagement = "no_engagement"
+)
+
+// replyExampleRow is the reply_examples table layout; the embedding is
+// stored as a JSON array
+type replyExampleRow struct {
+	ID            int64     `gorm:"column:id;primaryKey"`
+	BotID         string    `gorm:"column:bot_id"`
+	TweetText     string    `gorm:"column:tweet_text"`
+	ReplyText     string    `gorm:"column:reply_text"`
+	Category      string    `gorm:"column:category"`
+	Negative      bool      `gorm:"column:negative"`
+	Reason        string    `gorm:"column:reason"`
+	SourceTweetID string    `gorm:"column:source_tweet_id"`
+	Embedding     string    `gorm:"column:embedding"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (replyExampleRow) TableName() string {
+	return "reply_examples"
+}
+
+// SaveReplyExample stores a curated example and sets its ID
+func (s *TweetStore) SaveReplyExample(ctx context.Context, example *ReplyExample) error {
+	if example.CreatedAt.IsZero() {
+		example.CreatedAt = time.Now()
+	}
+
+	var embedding string
+	if len(example.Embedding) > 0 {
+		data, err := json.Marshal(example.Embedding)
+		if err != nil {
+			return fmt.Errorf("failed to encode reply example embedding: %w", err)
+		}
+		embedding = string(data)
+	}
+
+	row := replyExampleRow{
+		BotID:         s.BotID(),
+		TweetText:     example.TweetText,
+		ReplyText:     example.ReplyText,
+		Category:      example.Category,
+		Negative:      example.Negative,
+		Reason:        example.Reason,
+		SourceTweetID: example.SourceTweetID,
+		Embedding:     embedding,
+		CreatedAt:     example.CreatedAt,
+	}
+	if err := s.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return fmt.Errorf("failed to save reply example: %w", err)
+	}
+	example.ID = row.ID
+	return nil
+}
+
+// ReplyExamples returns the bot's curated and negative examples, oldest first
+func (s *TweetStore) ReplyExamples(ctx context.Context) ([]ReplyExample, error) {
+	var rows []replyExampleRow
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ?", s.BotID()).
+		Order("id ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reply examples: %w", err)
+	}
+
+	examples := make([]ReplyExample, 0, len(rows))
+	for _, row := range rows {
+		example := ReplyExample{
+			ID:            row.ID,
+			TweetText:     row.TweetText,
+			ReplyText:     row.ReplyText,
+			Category:      row.Category,
+			Negative:      row.Negative,
+			Reason:        row.Reason,
+			SourceTweetID: row.SourceTweetID,
+			CreatedAt:     row.CreatedAt,
+		}
+		if row.Embedding != "" {
+			if err := json.Unmarshal([]byte(row.Embedding), &example.Embedding); err != nil {
+				s.logger.WithError(err).WithField("example_id", row.ID).Warn("Ignoring unreadable reply example embedding")
+				example.Embedding = nil
+			}
+		}
+		examples = append(examples, example)
+	}
+	return examples, nil
+}
+
+// DeleteReplyExample removes a curated example. It reports whether the
+// example existed.
+func (s *TweetStore) DeleteReplyExample(ctx context.Context, id int64) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Where("bot_id = ? AND id = ?", s.BotID(), id).
+		Delete(&replyExampleRow{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete reply example: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PostedReply is one of the bot's replies with the tweet it answered
+type PostedReply struct {
+	TweetID   string
+	Text      string
+	CreatedAt time.Time
+	// ParentText is the text of the tweet the reply answered
+	ParentText string
+}
+
+// UnreviewedReplies returns the bot's replies posted since the given time that
+// are not yet recorded as negative examples, oldest first
+func (s *TweetStore) UnreviewedReplies(ctx context.Context, since time.Time) ([]PostedReply, error) {
+	var replies []PostedReply
+	err := s.reader(ctx).
+		Table("tweets AS r").
+		Select("r.id AS tweet_id, r.text, r.created_at, p.text AS parent_text").
+		Joins("JOIN tweets AS p ON p.bot_id = r.bot_id AND p.last_reply_id = r.id").
+		Where("r.bot_id = ? AND r.author_id = ? AND r.category = ? AND r.created_at >= ?",
+			s.BotID(), s.BotID(), CategoryReply, since).
+		Where("NOT EXISTS (SELECT 1 FROM reply_examples e WHERE e.bot_id = r.bot_id AND e.source_tweet_id = r.id)").
+		Order("r.created_at ASC").
+		Scan(&replies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unreviewed replies: %w", err)
+	}
+	return replies, nil
+}
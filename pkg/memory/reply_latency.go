@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ReplyLatency is how long the bot took to answer a mention
+type ReplyLatency struct {
+	BotID          string    `json:"-" gorm:"column:bot_id;primaryKey"`
+	ReplyID        string    `json:"reply_id" gorm:"column:reply_id;primaryKey"`
+	TweetID        string    `json:"tweet_id" gorm:"column:tweet_id"`
+	ConversationID string    `json:"conversation_id" gorm:"column:conversation_id"`
+	MentionedAt    time.Time `json:"mentioned_at" gorm:"column:mentioned_at"`
+	RepliedAt      time.Time `json:"replied_at" gorm:"column:replied_at"`
+	LatencyMillis  int64     `json:"latency_ms" gorm:"column:latency_ms"`
+}
+
+// TableName specifies the table name for GORM
+func (ReplyLatency) TableName() string {
+	return "reply_latency"
+}
+
+// Latency returns the time from the mention to the reply
+func (l ReplyLatency) Latency() time.Duration {
+	return time.Duration(l.LatencyMillis) * time.Millisecond
+}
+
+// DailyLatency holds the response latency percentiles of one UTC day
+type DailyLatency struct {
+	Day        time.Time `json:"day"`
+	Replies    int       `json:"replies"`
+	P50Millis  int64     `json:"p50_ms"`
+	P95Millis  int64     `json:"p95_ms"`
+	MaxMillis  int64     `json:"max_ms"`
+	MeanMillis int64     `json:"mean_ms"`
+}
+
+// P95 returns the 95th percentile latency as a duration
+func (d DailyLatency) P95() time.Duration {
+	return time.Duration(d.P95Millis) * time.Millisecond
+}
+
+// RecordReplyLatency stores how long the bot took to answer a mention. A
+// reply recorded before keeps its first record.
+func (s *TweetStore) RecordReplyLatency(ctx context.Context, latency *ReplyLatency) error {
+	latency.BotID = s.BotID()
+	latency.MentionedAt = latency.MentionedAt.UTC()
+	latency.RepliedAt = latency.RepliedAt.UTC()
+	latency.LatencyMillis = latency.RepliedAt.Sub(latency.MentionedAt).Milliseconds()
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(latency).Error
+	if err != nil {
+		return fmt.Errorf("failed to record reply latency: %w", err)
+	}
+	return nil
+}
+
+// DailyLatencies returns the response latency percentiles of each UTC day
+// with replies since the given time, oldest first
+func (s *TweetStore) DailyLatencies(ctx context.Context, since time.Time) ([]DailyLatency, error) {
+	var latencies []ReplyLatency
+	err := s.reader(ctx).
+		Select("replied_at, latency_ms").
+		Where("bot_id = ? AND replied_at >= ?", s.BotID(), since.UTC()).
+		Find(&latencies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reply latencies: %w", err)
+	}
+
+	byDay := make(map[time.Time][]int64)
+	for _, latency := range latencies {
+		day := startOfDay(latency.RepliedAt)
+		byDay[day] = append(byDay[day], latency.LatencyMillis)
+	}
+
+	days := make([]DailyLatency, 0, len(byDay))
+	for day, millis := range byDay {
+		sort.Slice(millis, func(i, j int) bool { return millis[i] < millis[j] })
+		var total int64
+		for _, value := range millis {
+			total += value
+		}
+		days = append(days, DailyLatency{
+			Day:        day,
+			Replies:    len(millis),
+			P50Millis:  percentile(millis, 0.50),
+			P95Millis:  percentile(millis, 0.95),
+			MaxMillis:  millis[len(millis)-1],
+			MeanMillis: total / int64(len(millis)),
+		})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Day.Before(days[j].Day) })
+	return days, nil
+}
+
+// percentile returns the nearest-rank percentile p of sorted values
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
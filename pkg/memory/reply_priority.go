@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// recencyHalfLife is the age at which a tweet's recency signal halves
+	recencyHalfLife = time.Hour
+	// heatWindow is how far back conversation activity counts towards heat
+	heatWindow = time.Hour
+	// hotConversation is the number of tweets within heatWindow at which a
+	// conversation counts as fully hot
+	hotConversation = 10
+)
+
+// PriorityWeights weigh the signals a reply's priority is scored from. Only
+// their ratios matter; all zero leaves recalled threads oldest first.
+type PriorityWeights struct {
+	// Recency favours tweets that are still fresh
+	Recency float64
+	// Followers favours widely followed and verified authors
+	Followers float64
+	// Heat favours conversations with a lot of recent activity
+	Heat float64
+	// Addressed favours tweets that mention or reply to the bot directly
+	Addressed float64
+}
+
+// DefaultPriorityWeights weigh every signal equally
+func DefaultPriorityWeights() PriorityWeights {
+	return PriorityWeights{Recency: 1, Followers: 1, Heat: 1, Addressed: 1}
+}
+
+// enabled reports whether any signal is weighted
+func (w PriorityWeights) enabled() bool {
+	return w.Recency > 0 || w.Followers > 0 || w.Heat > 0 || w.Addressed > 0
+}
+
+// ReplySignals are the inputs of a priority score, each between 0 and 1
+type ReplySignals struct {
+	Recency   float64
+	Followers float64
+	Heat      float64
+	Addressed float64
+}
+
+// Score is the weighted average of the signals, between 0 and 1
+func (w PriorityWeights) Score(signals ReplySignals) float64 {
+	total := w.Recency + w.Followers + w.Heat + w.Addressed
+	if total <= 0 {
+		return 0
+	}
+	return (w.Recency*signals.Recency +
+		w.Followers*signals.Followers +
+		w.Heat*signals.Heat +
+		w.Addressed*signals.Addressed) / total
+}
+
+// QueuePriority scales a score to the integer priority of the reply queue
+func QueuePriority(score float64) int {
+	return int(math.Round(score * 1000))
+}
+
+// RecencySignal halves every recencyHalfLife since the tweet was created
+func RecencySignal(createdAt, now time.Time) float64 {
+	age := now.Sub(createdAt)
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+}
+
+// FollowerSignal grows with the log of the follower count and reaches 1 at
+// saturation followers; verified authors always score 1
+func FollowerSignal(followers int, verified bool, saturation int) float64 {
+	if verified {
+		return 1
+	}
+	if followers <= 0 || saturation <= 0 {
+		return 0
+	}
+	return math.Min(1, math.Log10(float64(followers)+1)/math.Log10(float64(saturation)+1))
+}
+
+// HeatSignal grows with the tweets of a conversation posted within heatWindow
+func HeatSignal(recentTweets int) float64 {
+	return math.Min(1, float64(recentTweets)/hotConversation)
+}
+
+// AddressedSignal is 1 for mentions of the bot and replies to it
+func AddressedSignal(category, inReplyToUserID, botID string) float64 {
+	if category == string(CategoryMention) || (botID != "" && inReplyToUserID == botID) {
+		return 1
+	}
+	return 0
+}
+
+// ReplyPriority configures how recalled threads are ordered
+type ReplyPriority struct {
+	Weights PriorityWeights
+	// FollowerSaturation is the follower count at which the followers signal
+	// reaches its maximum
+	FollowerSaturation int
+}
+
+// ThreadSignals scores the newest tweet of a thread needing a reply
+func (p ReplyPriority) ThreadSignals(thread ConversationThread, botID string, now time.Time) ReplySignals {
+	latest, ok := thread.Latest()
+	if !ok {
+		return ReplySignals{}
+	}
+
+	recent := 0
+	for _, tweet := range thread.Tweets {
+		if now.Sub(tweet.CreatedAt) <= heatWindow {
+			recent++
+		}
+	}
+
+	return ReplySignals{
+		Recency:   RecencySignal(latest.CreatedAt, now),
+		Followers: FollowerSignal(latest.AuthorFollowers, latest.AuthorVerified, p.FollowerSaturation),
+		Heat:      HeatSignal(recent),
+		Addressed: AddressedSignal(latest.Category, latest.InReplyToUserID, botID),
+	}
+}
+
+// ThreadScore is the priority score of a thread
+func (p ReplyPriority) ThreadScore(thread ConversationThread, botID string, now time.Time) float64 {
+	return p.Weights.Score(p.ThreadSignals(thread, botID, now))
+}
+
+// sortThreads orders threads by score, highest first, keeping the recall
+// order between equal scores. Without weights the order is left alone.
+func (p ReplyPriority) sortThreads(threads []ConversationThread, botID string, now time.Time) {
+	if !p.Weights.enabled() {
+		return
+	}
+	for i := range threads {
+		threads[i].Priority = p.ThreadScore(threads[i], botID, now)
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Priority > threads[j].Priority
+	})
+}
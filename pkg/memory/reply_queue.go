@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claimCandidates is how many ready items a claim looks at, so concurrent
+// workers racing for the first one can fall back to the next
+const claimCandidates = 5
+
+// ReplyWorkItem is a tweet queued for a reply worker
+type ReplyWorkItem struct {
+	BotID          string `json:"bot_id" gorm:"column:bot_id;primaryKey"`
+	TweetID        string `json:"tweet_id" gorm:"column:tweet_id;primaryKey"`
+	ConversationID string `json:"conversation_id" gorm:"column:conversation_id"`
+	// Priority orders ready items, highest first
+	Priority int `json:"priority" gorm:"column:priority"`
+	// Attempts counts failed replies to the tweet
+	Attempts int `json:"attempts" gorm:"column:attempts"`
+	// VisibleAt is when the item can next be claimed
+	VisibleAt time.Time `json:"visible_at" gorm:"column:visible_at"`
+	ClaimedBy string    `json:"claimed_by" gorm:"column:claimed_by"`
+	LastError string    `json:"last_error" gorm:"column:last_error"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ReplyWorkItem) TableName() string {
+	return "reply_queue"
+}
+
+// EnqueueReplies queues tweets for the reply workers. Tweets already queued
+// keep their place.
+func (s *TweetStore) EnqueueReplies(ctx context.Context, items []ReplyWorkItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]ReplyWorkItem, len(items))
+	for i, item := range items {
+		item.BotID = s.BotID()
+		if item.VisibleAt.IsZero() {
+			item.VisibleAt = now
+		}
+		item.CreatedAt = now
+		rows[i] = item
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to enqueue replies: %w", err)
+	}
+	return nil
+}
+
+// ClaimReply leases the next ready item to worker, highest priority first and
+// then oldest, hiding it from other workers for visibility. It returns nil
+// when nothing is ready.
+func (s *TweetStore) ClaimReply(ctx context.Context, worker string, visibility time.Duration) (*ReplyWorkItem, error) {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	var candidates []ReplyWorkItem
+	err := db.Where("bot_id = ? AND visible_at <= ?", s.BotID(), now).
+		Order("priority DESC, created_at ASC").
+		Limit(claimCandidates).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reply queue: %w", err)
+	}
+
+	for _, item := range candidates {
+		// Only one worker's update still sees the item as visible
+		result := db.Model(&ReplyWorkItem{}).
+			Where("bot_id = ? AND tweet_id = ? AND visible_at <= ?", item.BotID, item.TweetID, now).
+			Updates(map[string]interface{}{
+				"visible_at": now.Add(visibility),
+				"claimed_by": worker,
+			})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to claim reply: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			item.VisibleAt = now.Add(visibility)
+			item.ClaimedBy = worker
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+// CompleteReply removes a finished item from the queue
+func (s *TweetStore) CompleteReply(ctx context.Context, tweetID string) error {
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND tweet_id = ?", s.BotID(), tweetID).
+		Delete(&ReplyWorkItem{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to complete reply: %w", err)
+	}
+	return nil
+}
+
+// RetryReply makes a claimed item visible again after delay. A non-nil cause
+// counts as a failed attempt and is recorded; deferrals pass nil.
+func (s *TweetStore) RetryReply(ctx context.Context, tweetID string, delay time.Duration, cause error) error {
+	updates := map[string]interface{}{
+		"visible_at": time.Now().Add(delay),
+		"claimed_by": "",
+	}
+	if cause != nil {
+		updates["attempts"] = gorm.Expr("attempts + 1")
+		updates["last_error"] = cause.Error()
+	}
+
+	err := s.db.WithContext(ctx).Model(&ReplyWorkItem{}).
+		Where("bot_id = ? AND tweet_id = ?", s.BotID(), tweetID).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("failed to requeue reply: %w", err)
+	}
+	return nil
+}
+
+// QueuedReplies counts the items waiting in the queue, claimed or not
+func (s *TweetStore) QueuedReplies(ctx context.Context) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&ReplyWorkItem{}).
+		Where("bot_id = ?", s.BotID()).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count queued replies: %w", err)
+	}
+	return count, nil
+}
+
+// QueuedTweetIDs returns the IDs of the tweets waiting in the queue, claimed or not
+func (s *TweetStore) QueuedTweetIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := s.db.WithContext(ctx).Model(&ReplyWorkItem{}).
+		Where("bot_id = ?", s.BotID()).
+		Order("created_at ASC").
+		Pluck("tweet_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued replies: %w", err)
+	}
+	return ids, nil
+}
+
+// ReplyThread loads the conversation of a queued tweet the way the recall
+// would. It returns nil when the tweet no longer needs a reply: it was
+// answered or deleted, its author was blocked, its thread muted or it was
+// flagged as spam. It reads the primary so replies posted moments ago are seen.
+func (s *TweetStore) ReplyThread(ctx context.Context, tweetID string) (*ConversationThread, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.botID == "" {
+		return nil, fmt.Errorf("bot user ID not set")
+	}
+
+	needingReply, err := newReplyQuery(s.db.WithContext(ctx), s.botID, s.botID).
+		needingReply(DefaultRecallCategories, true).
+		excludeModerated().
+		belowSpamScore(s.spamThreshold).
+		only(tweetID).
+		find()
+	if err != nil {
+		return nil, err
+	}
+
+	threads, err := s.groupThreads(ctx, needingReply)
+	if err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return nil, nil
+	}
+	return &threads[0], nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ReplyStages is the draft, critique and revision behind one of the bot's
+// replies in self-critique mode
+type ReplyStages struct {
+	BotID    string `json:"-" gorm:"column:bot_id;primaryKey"`
+	ReplyID  string `json:"reply_id" gorm:"column:reply_id;primaryKey"`
+	TweetID  string `json:"tweet_id" gorm:"column:tweet_id"`
+	Draft    string `json:"draft" gorm:"column:draft"`
+	Critique string `json:"critique" gorm:"column:critique"`
+	// Revision is empty when the critique approved the draft
+	Revision  string    `json:"revision" gorm:"column:revision"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ReplyStages) TableName() string {
+	return "reply_stages"
+}
+
+// SaveReplyStages records the stages behind a posted reply
+func (s *TweetStore) SaveReplyStages(ctx context.Context, stages *ReplyStages) error {
+	stages.BotID = s.BotID()
+	stages.CreatedAt = time.Now().UTC()
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(stages).Error
+	if err != nil {
+		return fmt.Errorf("failed to save reply stages: %w", err)
+	}
+	return nil
+}
+
+// ReplyStagesSince returns the stages of the replies posted since the given
+// time, newest first
+func (s *TweetStore) ReplyStagesSince(ctx context.Context, since time.Time) ([]ReplyStages, error) {
+	var stages []ReplyStages
+	err := s.reader(ctx).
+		Where("bot_id = ? AND created_at >= ?", s.BotID(), since.UTC()).
+		Order("created_at DESC, reply_id DESC").
+		Find(&stages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reply stages: %w", err)
+	}
+	return stages, nil
+}
@@ -0,0 +1,280 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+// DailyReport summarizes one day of the bot's engagement
+type DailyReport struct {
+	BotID       string    `json:"bot_id" gorm:"column:bot_id;primaryKey"`
+	PeriodStart time.Time `json:"period_start" gorm:"column:period_start;primaryKey"`
+	// RepliesPosted counts the replies the bot posted during the day
+	RepliesPosted int `json:"replies_posted" gorm:"column:replies_posted"`
+	// AvgResponseSeconds is the mean time from a tweet's creation to the bot's reply
+	AvgResponseSeconds int `json:"avg_response_seconds" gorm:"column:avg_response_seconds"`
+	// The received counts sum the public metrics of the tweets the bot posted that day
+	LikesReceived    int       `json:"likes_received" gorm:"column:likes_received"`
+	RetweetsReceived int       `json:"retweets_received" gorm:"column:retweets_received"`
+	RepliesReceived  int       `json:"replies_received" gorm:"column:replies_received"`
+	QuotesReceived   int       `json:"quotes_received" gorm:"column:quotes_received"`
+	GeneratedAt      time.Time `json:"generated_at" gorm:"column:generated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (DailyReport) TableName() string {
+	return "reports"
+}
+
+// AvgResponseTime returns the average response latency as a duration
+func (r *DailyReport) AvgResponseTime() time.Duration {
+	return time.Duration(r.AvgResponseSeconds) * time.Second
+}
+
+// Summary renders the report on one line, e.g. for a log entry or a tweet
+func (r *DailyReport) Summary() string {
+	return fmt.Sprintf("%s: %d replies posted, avg response %s, %d likes, %d retweets, %d replies, %d quotes received",
+		r.PeriodStart.Format("2006-01-02"), r.RepliesPosted, r.AvgResponseTime().Round(time.Second),
+		r.LikesReceived, r.RetweetsReceived, r.RepliesReceived, r.QuotesReceived)
+}
+
+// OwnTweetIDs returns the IDs of the tweets the bot posted since the given time
+func (s *TweetStore) OwnTweetIDs(ctx context.Context, since time.Time) ([]string, error) {
+	var ids []string
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("author_id = ? AND created_at >= ?", s.BotID(), since).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list own tweets: %w", err)
+	}
+	return ids, nil
+}
+
+// UpdatePublicMetrics stores freshly fetched public metrics for tweets already in the store
+func (s *TweetStore) UpdatePublicMetrics(ctx context.Context, tweets []twitter.Tweet) error {
+	now := time.Now()
+	for _, tweet := range tweets {
+		err := s.tweets(s.db.WithContext(ctx)).
+			Where("id = ?", tweet.ID).
+			Updates(map[string]interface{}{
+				"public_metrics": s.jsonColumn(tweet.PublicMetrics),
+				"last_updated":   now,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update public metrics for tweet %s: %w", tweet.ID, err)
+		}
+	}
+	return nil
+}
+
+// ReplyPerformance is one of the bot's replies with the engagement it received
+type ReplyPerformance struct {
+	TweetID   string
+	Text      string
+	CreatedAt time.Time
+	Likes     int
+	Retweets  int
+	Replies   int
+	Quotes    int
+}
+
+// Engagement weighs the reply's metrics into one score; amplification through
+// retweets and quotes counts double
+func (p ReplyPerformance) Engagement() int {
+	return p.Likes + p.Replies + 2*(p.Retweets+p.Quotes)
+}
+
+// replyPerformance reads the stored public metrics of a reply; replies without
+// metrics have none
+func replyPerformance(id, text string, createdAt time.Time, publicMetrics json.RawMessage) ReplyPerformance {
+	reply := ReplyPerformance{TweetID: id, Text: text, CreatedAt: createdAt}
+	if len(publicMetrics) > 0 {
+		var metrics struct {
+			RetweetCount int `json:"retweet_count"`
+			ReplyCount   int `json:"reply_count"`
+			LikeCount    int `json:"like_count"`
+			QuoteCount   int `json:"quote_count"`
+		}
+		if err := json.Unmarshal(publicMetrics, &metrics); err == nil {
+			reply.Likes = metrics.LikeCount
+			reply.Retweets = metrics.RetweetCount
+			reply.Replies = metrics.ReplyCount
+			reply.Quotes = metrics.QuoteCount
+		}
+	}
+	return reply
+}
+
+// TweetEngagement weighs the stored public metrics of a tweet like
+// ReplyPerformance.Engagement. Tweets without metrics score 0.
+func (s *TweetStore) TweetEngagement(ctx context.Context, tweetID string) (int, error) {
+	var rows [][]byte
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("id = ?", tweetID).
+		Pluck("public_metrics", &rows).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load public metrics of tweet %s: %w", tweetID, err)
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return 0, nil
+	}
+
+	var metrics struct {
+		RetweetCount int `json:"retweet_count"`
+		ReplyCount   int `json:"reply_count"`
+		LikeCount    int `json:"like_count"`
+		QuoteCount   int `json:"quote_count"`
+	}
+	if err := json.Unmarshal(rows[0], &metrics); err != nil {
+		return 0, fmt.Errorf("failed to decode public metrics of tweet %s: %w", tweetID, err)
+	}
+	return ReplyPerformance{
+		Likes:    metrics.LikeCount,
+		Retweets: metrics.RetweetCount,
+		Replies:  metrics.ReplyCount,
+		Quotes:   metrics.QuoteCount,
+	}.Engagement(), nil
+}
+
+// TopReplies returns the bot's best performing replies posted since the given
+// time, highest engagement first, for use as examples when tuning prompts
+func (s *TweetStore) TopReplies(ctx context.Context, since time.Time, limit int) ([]ReplyPerformance, error) {
+	var rows []struct {
+		ID            string          `gorm:"column:id"`
+		Text          string          `gorm:"column:text"`
+		CreatedAt     time.Time       `gorm:"column:created_at"`
+		PublicMetrics json.RawMessage `gorm:"column:public_metrics;serializer:json"`
+	}
+	err := s.tweets(s.reader(ctx)).
+		Select("id, text, created_at, public_metrics").
+		Where("author_id = ? AND category = ? AND created_at >= ?", s.BotID(), CategoryReply, since).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load own replies: %w", err)
+	}
+
+	replies := make([]ReplyPerformance, 0, len(rows))
+	for _, row := range rows {
+		replies = append(replies, replyPerformance(row.ID, row.Text, row.CreatedAt, row.PublicMetrics))
+	}
+
+	sort.SliceStable(replies, func(i, j int) bool {
+		return replies[i].Engagement() > replies[j].Engagement()
+	})
+	if limit > 0 && len(replies) > limit {
+		replies = replies[:limit]
+	}
+	return replies, nil
+}
+
+// BuildDailyReport aggregates the replies the bot posted on the given day, how
+// quickly it responded and the engagement those replies received
+func (s *TweetStore) BuildDailyReport(ctx context.Context, day time.Time) (*DailyReport, error) {
+	start := startOfDay(day)
+	end := start.Add(24 * time.Hour)
+	botID := s.BotID()
+
+	var posted []struct {
+		ID            string          `gorm:"column:id"`
+		Category      string          `gorm:"column:category"`
+		PublicMetrics json.RawMessage `gorm:"column:public_metrics;serializer:json"`
+	}
+	err := s.tweets(s.reader(ctx)).
+		Select("id, category, public_metrics").
+		Where("author_id = ? AND created_at >= ? AND created_at < ?", botID, start, end).
+		Find(&posted).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posted tweets: %w", err)
+	}
+
+	report := &DailyReport{
+		BotID:       botID,
+		PeriodStart: start,
+		GeneratedAt: time.Now(),
+	}
+
+	var replyIDs []string
+	for _, tweet := range posted {
+		if tweet.Category == string(CategoryReply) {
+			report.RepliesPosted++
+			replyIDs = append(replyIDs, tweet.ID)
+		}
+
+		if len(tweet.PublicMetrics) == 0 {
+			continue
+		}
+		var metrics struct {
+			RetweetCount int `json:"retweet_count"`
+			ReplyCount   int `json:"reply_count"`
+			LikeCount    int `json:"like_count"`
+			QuoteCount   int `json:"quote_count"`
+		}
+		if err := json.Unmarshal(tweet.PublicMetrics, &metrics); err != nil {
+			s.logger.WithError(err).WithField("tweet_id", tweet.ID).Debug("Skipping unreadable public metrics")
+			continue
+		}
+		report.LikesReceived += metrics.LikeCount
+		report.RetweetsReceived += metrics.RetweetCount
+		report.RepliesReceived += metrics.ReplyCount
+		report.QuotesReceived += metrics.QuoteCount
+	}
+
+	// Tweets the bot answered record the reply ID and time, so latency is the gap
+	// between their creation and last_reply_time
+	if len(replyIDs) > 0 {
+		var answered []struct {
+			CreatedAt     time.Time `gorm:"column:created_at"`
+			LastReplyTime time.Time `gorm:"column:last_reply_time"`
+		}
+		err := s.tweets(s.reader(ctx)).
+			Select("created_at, last_reply_time").
+			Where("last_reply_id IN ?", replyIDs).
+			Find(&answered).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to load answered tweets: %w", err)
+		}
+
+		var total time.Duration
+		var measured int
+		for _, tweet := range answered {
+			if latency := tweet.LastReplyTime.Sub(tweet.CreatedAt); latency > 0 {
+				total += latency
+				measured++
+			}
+		}
+		if measured > 0 {
+			report.AvgResponseSeconds = int((total / time.Duration(measured)).Seconds())
+		}
+	}
+
+	return report, nil
+}
+
+// SaveReport stores a daily report, replacing any earlier report for the same day
+func (s *TweetStore) SaveReport(ctx context.Context, report *DailyReport) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "bot_id"}, {Name: "period_start"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"replies_posted", "avg_response_seconds", "likes_received",
+				"retweets_received", "replies_received", "quotes_received", "generated_at",
+			}),
+		}).
+		Create(report).Error
+	if err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"bot_id": report.BotID,
+		"day":    report.PeriodStart.Format("2006-01-02"),
+	}).Debug("Saved daily report")
+	return nil
+}
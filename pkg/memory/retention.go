@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Tables the retention policy prunes
+const (
+	RetainTweets    = "tweets"
+	RetainJudgments = "judgments"
+	RetainAnalytics = "analytics"
+	RetainQuota     = "twitter_quota"
+	RetainLatency   = "reply_latency"
+	RetainStages    = "reply_stages"
+)
+
+// retainedTable describes how rows of a pruned table are dated and identified
+type retainedTable struct {
+	// timeColumn dates the rows
+	timeColumn string
+	// keyColumns identify a row next to bot_id
+	keyColumns []string
+}
+
+var retainedTables = map[string]retainedTable{
+	RetainTweets:    {timeColumn: "created_at", keyColumns: []string{"id"}},
+	RetainJudgments: {timeColumn: "created_at", keyColumns: []string{"id"}},
+	RetainAnalytics: {timeColumn: "period_start", keyColumns: []string{"metric", "period_start", "conversation_id"}},
+	RetainQuota:     {timeColumn: "window_reset", keyColumns: []string{"endpoint", "window_reset"}},
+	RetainLatency:   {timeColumn: "replied_at", keyColumns: []string{"reply_id"}},
+	RetainStages:    {timeColumn: "created_at", keyColumns: []string{"reply_id"}},
+}
+
+// ExpiredRows returns up to limit of the bot's rows of table dated before the
+// given time, oldest first, as column/value maps. Tweets expire by
+// conversation: a tweet is only returned once its whole conversation has
+// been quiet since before, and never while it waits in the reply queue.
+func (s *TweetStore) ExpiredRows(ctx context.Context, table string, before time.Time, limit int) ([]map[string]interface{}, error) {
+	retained, ok := retainedTables[table]
+	if !ok {
+		return nil, fmt.Errorf("no retention policy for table %q", table)
+	}
+
+	query := s.db.WithContext(ctx).
+		Table(table+" AS t").
+		Where("t.bot_id = ? AND t."+retained.timeColumn+" < ?", s.BotID(), before)
+	if table == RetainTweets {
+		query = query.Where(`
+			(t.conversation_id = '' OR NOT EXISTS (
+				SELECT 1 FROM tweets n
+				WHERE n.bot_id = t.bot_id AND n.conversation_id = t.conversation_id AND n.created_at >= ?
+			))
+			AND NOT EXISTS (
+				SELECT 1 FROM reply_queue q
+				WHERE q.bot_id = t.bot_id AND q.tweet_id = t.id
+			)
+		`, before)
+	}
+
+	order := "t." + retained.timeColumn
+	for _, column := range retained.keyColumns {
+		order += ", t." + column
+	}
+	query = query.Order(order)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to find expired %s: %w", table, err)
+	}
+	return rows, nil
+}
+
+// DeleteRows deletes the bot's rows of table identified by the key columns of
+// the given rows, as returned by ExpiredRows, and returns how many were
+// deleted. Callers keep batches small so each delete is a short transaction.
+func (s *TweetStore) DeleteRows(ctx context.Context, table string, rows []map[string]interface{}) (int64, error) {
+	retained, ok := retainedTables[table]
+	if !ok {
+		return 0, fmt.Errorf("no retention policy for table %q", table)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	keys := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		key := make([]interface{}, len(retained.keyColumns))
+		for j, column := range retained.keyColumns {
+			value, ok := row[column]
+			if !ok {
+				return 0, fmt.Errorf("%s row is missing key column %s", table, column)
+			}
+			key[j] = value
+		}
+		keys[i] = key
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := s.db.WithContext(ctx).Table(table).Where("bot_id = ?", s.botID)
+	if len(retained.keyColumns) == 1 {
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = key[0]
+		}
+		query = query.Where(retained.keyColumns[0]+" IN ?", values)
+	} else {
+		query = query.Where("("+strings.Join(retained.keyColumns, ", ")+") IN ?", keys)
+	}
+
+	result := query.Delete(map[string]interface{}{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired %s: %w", table, result.Error)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"table":   table,
+		"deleted": result.RowsAffected,
+	}).Debug("Deleted expired rows")
+	return result.RowsAffected, nil
+}
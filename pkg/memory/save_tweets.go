@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/model"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// saveTweetsBatchSize bounds the rows per INSERT statement, keeping the bound
+// parameters well under SQLite's limit
+const saveTweetsBatchSize = 100
+
+// TweetWithMeta is a tweet to store together with the metadata SaveTweet
+// takes as arguments
+type TweetWithMeta struct {
+	Tweet          twitter.Tweet
+	Category       TweetCategory
+	AuthorName     string
+	AuthorUsername string
+	// AuthorFollowers and AuthorVerified describe the author for reply priority
+	AuthorFollowers int
+	AuthorVerified  bool
+}
+
+// NewTweetWithMeta prepares a canonical tweet, from any source, for
+// SaveTweets. An empty category is derived from the tweet.
+func NewTweetWithMeta(tweet model.Tweet, category TweetCategory) TweetWithMeta {
+	converted := tweet.Twitter()
+	if category == "" {
+		category = DetermineTweetCategory(converted)
+	}
+	return TweetWithMeta{
+		Tweet:           converted,
+		Category:        category,
+		AuthorName:      tweet.Author.Name,
+		AuthorUsername:  tweet.Author.Username,
+		AuthorFollowers: tweet.Author.Followers,
+		AuthorVerified:  tweet.Author.Verified,
+	}
+}
+
+// SaveTweets stores a page of tweets like repeated SaveTweet calls, but with
+// one participation lookup, one propagation pass over replied-to tweets and
+// their conversations, and batched upserts, all in a single transaction.
+// Tweets repeated within the page are stored once.
+func (s *TweetStore) SaveTweets(ctx context.Context, tweets []TweetWithMeta) error {
+	if len(tweets) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	// Conversations the bot already takes part in
+	var conversationIDs []string
+	seenConversations := make(map[string]bool)
+	for _, t := range tweets {
+		if id := t.Tweet.ConversationID; id != "" && !seenConversations[id] {
+			seenConversations[id] = true
+			conversationIDs = append(conversationIDs, id)
+		}
+	}
+	participating := make(map[string]bool)
+	if len(conversationIDs) > 0 {
+		var ids []string
+		err := s.tweets(db).
+			Where("conversation_id IN ? AND is_participating = ?", conversationIDs, true).
+			Distinct("conversation_id").
+			Pluck("conversation_id", &ids).Error
+		if err != nil {
+			return fmt.Errorf("failed to load participating conversations: %w", err)
+		}
+		for _, id := range ids {
+			participating[id] = true
+		}
+	}
+
+	rows := make([]map[string]interface{}, 0, len(tweets))
+	repliesPerParent := make(map[string]int)
+	var parentIDs, repliedConversations []string
+	seenTweets := make(map[string]bool)
+	for _, t := range tweets {
+		// An upsert cannot touch the same row twice in one statement
+		if seenTweets[t.Tweet.ID] {
+			continue
+		}
+		seenTweets[t.Tweet.ID] = true
+
+		row := s.tweetRow(t.Tweet, t.Category, t.AuthorName, t.AuthorUsername, now, participating[t.Tweet.ConversationID])
+		row["author_followers"] = t.AuthorFollowers
+		row["author_verified"] = t.AuthorVerified
+		// Every row of a multi-row insert needs the same columns
+		if _, ok := row["edit_history_tweet_ids"]; !ok {
+			row["edit_history_tweet_ids"] = pq.StringArray(nil)
+		}
+		rows = append(rows, row)
+
+		if t.Tweet.ConversationID == "" {
+			continue
+		}
+		for _, ref := range t.Tweet.ReferencedTweets {
+			if ref.Type == "replied_to" {
+				if repliesPerParent[ref.ID] == 0 {
+					parentIDs = append(parentIDs, ref.ID)
+				}
+				repliesPerParent[ref.ID]++
+				repliedConversations = append(repliedConversations, t.Tweet.ConversationID)
+				break
+			}
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Mark replied-to tweets as having unread replies, one update per
+		// distinct number of new replies
+		parentsByReplies := make(map[int][]string)
+		for _, id := range parentIDs {
+			n := repliesPerParent[id]
+			parentsByReplies[n] = append(parentsByReplies[n], id)
+		}
+		for n, ids := range parentsByReplies {
+			err := s.tweets(tx).
+				Where("id IN ?", ids).
+				Updates(map[string]interface{}{
+					"unread_replies": gorm.Expr("unread_replies + ?", n),
+					"needs_reply":    true,
+					"last_updated":   now,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update parent tweets: %w", err)
+			}
+		}
+
+		// Replies mark the bot as participating in their conversations
+		if len(repliedConversations) > 0 {
+			err := s.tweets(tx).
+				Where("conversation_id IN ? AND id NOT IN ?", repliedConversations, parentIDs).
+				Updates(map[string]interface{}{
+					"is_participating": true,
+					"last_updated":     now,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update conversations: %w", err)
+			}
+		}
+
+		err := tx.Table("tweets").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "bot_id"}, {Name: "id"}},
+				DoUpdates: upsertAssignments(rows[0]),
+			}).
+			CreateInBatches(rows, saveTweetsBatchSize).Error
+		if err != nil {
+			return fmt.Errorf("failed to save tweets: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tweets":        len(rows),
+		"conversations": len(conversationIDs),
+		"replies":       len(repliedConversations),
+	}).Info("Saved tweet page to database")
+	return nil
+}
+
+// upsertAssignments overwrites every column of row except the key with the
+// inserted value. Edit history is only replaced when the new row has one, as
+// SaveTweet leaves it alone when the API did not return it.
+func upsertAssignments(row map[string]interface{}) clause.Set {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		if column != "bot_id" && column != "id" && column != "edit_history_tweet_ids" {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	set := clause.AssignmentColumns(columns)
+	return append(set, clause.Assignment{
+		Column: clause.Column{Name: "edit_history_tweet_ids"},
+		Value:  gorm.Expr("COALESCE(excluded.edit_history_tweet_ids, tweets.edit_history_tweet_ids)"),
+	})
+}
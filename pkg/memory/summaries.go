@@ -0,0 +1,205 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationSummary is a compact summary of a conversation the bot took
+// part in, folding in its tweets up to LastTweetID
+type ConversationSummary struct {
+	BotID          string    `json:"-" gorm:"column:bot_id;primaryKey"`
+	ConversationID string    `json:"conversation_id" gorm:"column:conversation_id;primaryKey"`
+	Summary        string    `json:"summary" gorm:"column:summary"`
+	TweetCount     int       `json:"tweet_count" gorm:"column:tweet_count"`
+	LastTweetID    string    `json:"last_tweet_id" gorm:"column:last_tweet_id"`
+	LastTweetAt    time.Time `json:"last_tweet_at" gorm:"column:last_tweet_at"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ConversationSummary) TableName() string {
+	return "conversation_summaries"
+}
+
+// UserSummary is a compact summary of what a user has said to the bot,
+// folding in their tweets up to LastTweetID
+type UserSummary struct {
+	BotID       string    `json:"-" gorm:"column:bot_id;primaryKey"`
+	UserID      string    `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Username    string    `json:"username" gorm:"column:username"`
+	Summary     string    `json:"summary" gorm:"column:summary"`
+	TweetCount  int       `json:"tweet_count" gorm:"column:tweet_count"`
+	LastTweetID string    `json:"last_tweet_id" gorm:"column:last_tweet_id"`
+	LastTweetAt time.Time `json:"last_tweet_at" gorm:"column:last_tweet_at"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (UserSummary) TableName() string {
+	return "user_summaries"
+}
+
+// ConversationsToSummarize returns conversations the bot took part in that
+// have tweets after their summary, or at least minTweets tweets when they
+// have no summary yet, most recently active first, at most limit of them
+func (s *TweetStore) ConversationsToSummarize(ctx context.Context, minTweets, limit int) ([]string, error) {
+	query := s.reader(ctx).
+		Table("tweets AS t").
+		Joins("LEFT JOIN conversation_summaries s ON s.bot_id = t.bot_id AND s.conversation_id = t.conversation_id").
+		Where("t.bot_id = ? AND t.conversation_id <> '' AND t.deleted_at IS NULL", s.BotID()).
+		Where(`EXISTS (
+			SELECT 1 FROM tweets p
+			WHERE p.bot_id = t.bot_id AND p.conversation_id = t.conversation_id AND p.is_participating = ?
+		)`, true).
+		Where(newSinceSummary).
+		Group("t.conversation_id").
+		Having("COUNT(*) >= ? OR MAX(s.last_tweet_id) IS NOT NULL", minTweets).
+		Order("MAX(t.created_at) DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var conversationIDs []string
+	if err := query.Pluck("t.conversation_id", &conversationIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find conversations to summarize: %w", err)
+	}
+	return conversationIDs, nil
+}
+
+// UsersToSummarize returns the authors, other than the bot, with tweets
+// after their summary, or at least minTweets tweets when they have no
+// summary yet, most recently active first, at most limit of them
+func (s *TweetStore) UsersToSummarize(ctx context.Context, minTweets, limit int) ([]string, error) {
+	botID := s.BotID()
+	query := s.reader(ctx).
+		Table("tweets AS t").
+		Joins("LEFT JOIN user_summaries s ON s.bot_id = t.bot_id AND s.user_id = t.author_id").
+		Where("t.bot_id = ? AND t.author_id <> '' AND t.author_id <> ? AND t.deleted_at IS NULL", botID, botID).
+		Where(newSinceSummary).
+		Group("t.author_id").
+		Having("COUNT(*) >= ? OR MAX(s.last_tweet_id) IS NOT NULL", minTweets).
+		Order("MAX(t.created_at) DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var userIDs []string
+	if err := query.Pluck("t.author_id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find users to summarize: %w", err)
+	}
+	return userIDs, nil
+}
+
+// newSinceSummary keeps the tweets t after the last tweet of summary s, in
+// the order tweets are paged in, or every tweet when there is no summary
+const newSinceSummary = "(s.last_tweet_id IS NULL OR (t.created_at, t.id) > (s.last_tweet_at, s.last_tweet_id))"
+
+// ConversationSummary returns the summary of a conversation, or nil when it
+// has none
+func (s *TweetStore) ConversationSummary(ctx context.Context, conversationID string) (*ConversationSummary, error) {
+	if conversationID == "" {
+		return nil, nil
+	}
+
+	var summary ConversationSummary
+	err := s.reader(ctx).
+		Where("bot_id = ? AND conversation_id = ?", s.BotID(), conversationID).
+		Take(&summary).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// UserSummary returns the summary of a user, or nil when they have none
+func (s *TweetStore) UserSummary(ctx context.Context, userID string) (*UserSummary, error) {
+	if userID == "" {
+		return nil, nil
+	}
+
+	var summary UserSummary
+	err := s.reader(ctx).
+		Where("bot_id = ? AND user_id = ?", s.BotID(), userID).
+		Take(&summary).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// SaveConversationSummary stores a conversation's summary, replacing the
+// previous one. LastTweetAt is taken from the stored LastTweetID, so it
+// compares exactly with the tweets' creation times.
+func (s *TweetStore) SaveConversationSummary(ctx context.Context, summary *ConversationSummary) error {
+	summary.BotID = s.BotID()
+	summary.UpdatedAt = time.Now()
+	row := map[string]interface{}{
+		"bot_id":          summary.BotID,
+		"conversation_id": summary.ConversationID,
+		"summary":         summary.Summary,
+		"tweet_count":     summary.TweetCount,
+		"last_tweet_id":   summary.LastTweetID,
+		"last_tweet_at":   s.createdAtOf(summary.LastTweetID),
+		"updated_at":      summary.UpdatedAt,
+	}
+	if err := s.upsertSummary(ctx, "conversation_summaries", "conversation_id", row); err != nil {
+		return fmt.Errorf("failed to save conversation summary: %w", err)
+	}
+	return nil
+}
+
+// SaveUserSummary stores a user's summary, replacing the previous one.
+// LastTweetAt is taken from the stored LastTweetID, so it compares exactly
+// with the tweets' creation times.
+func (s *TweetStore) SaveUserSummary(ctx context.Context, summary *UserSummary) error {
+	summary.BotID = s.BotID()
+	summary.UpdatedAt = time.Now()
+	row := map[string]interface{}{
+		"bot_id":        summary.BotID,
+		"user_id":       summary.UserID,
+		"username":      summary.Username,
+		"summary":       summary.Summary,
+		"tweet_count":   summary.TweetCount,
+		"last_tweet_id": summary.LastTweetID,
+		"last_tweet_at": s.createdAtOf(summary.LastTweetID),
+		"updated_at":    summary.UpdatedAt,
+	}
+	if err := s.upsertSummary(ctx, "user_summaries", "user_id", row); err != nil {
+		return fmt.Errorf("failed to save user summary: %w", err)
+	}
+	return nil
+}
+
+// createdAtOf selects the creation time of a stored tweet
+func (s *TweetStore) createdAtOf(tweetID string) clause.Expr {
+	return gorm.Expr("(SELECT created_at FROM tweets WHERE bot_id = ? AND id = ?)", s.BotID(), tweetID)
+}
+
+// upsertSummary inserts or replaces the summary row keyed by bot_id and key
+func (s *TweetStore) upsertSummary(ctx context.Context, table, key string, row map[string]interface{}) error {
+	updates := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		if column != "bot_id" && column != key {
+			updates[column] = value
+		}
+	}
+	return s.db.WithContext(ctx).
+		Table(table).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "bot_id"}, {Name: key}},
+			DoUpdates: clause.Assignments(updates),
+		}).
+		Create(row).Error
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// hashtagPattern matches hashtags in tweet text
+var hashtagPattern = regexp.MustCompile(`#[\pL\pN_]+`)
+
+// ThoughtTopicRecord is an original thought the bot posted, with its topic
+type ThoughtTopicRecord struct {
+	ID       int64  `json:"id" gorm:"column:id;primaryKey"`
+	BotID    string `json:"bot_id" gorm:"column:bot_id"`
+	Category string `json:"category" gorm:"column:category"`
+	Topic    string `json:"topic" gorm:"column:topic"`
+	TweetID  string `json:"tweet_id" gorm:"column:tweet_id"`
+	// Text is empty for thoughts posted before texts were recorded
+	Text     string    `json:"text" gorm:"column:text"`
+	PostedAt time.Time `json:"posted_at" gorm:"column:posted_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ThoughtTopicRecord) TableName() string {
+	return "thought_topics"
+}
+
+// SaveThoughtTopic records a posted thought and its topic
+func (s *TweetStore) SaveThoughtTopic(ctx context.Context, record *ThoughtTopicRecord) error {
+	if record.PostedAt.IsZero() {
+		record.PostedAt = time.Now()
+	}
+
+	err := s.db.WithContext(ctx).Table(ThoughtTopicRecord{}.TableName()).Create(map[string]interface{}{
+		"bot_id":    s.BotID(),
+		"category":  record.Category,
+		"topic":     record.Topic,
+		"tweet_id":  record.TweetID,
+		"text":      record.Text,
+		"posted_at": record.PostedAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save thought topic: %w", err)
+	}
+	return nil
+}
+
+// RecentThoughtTopics returns the thoughts posted since the given time, newest first
+func (s *TweetStore) RecentThoughtTopics(ctx context.Context, since time.Time) ([]ThoughtTopicRecord, error) {
+	var topics []ThoughtTopicRecord
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND posted_at >= ?", s.BotID(), since).
+		Order("posted_at DESC, id DESC").
+		Find(&topics).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load thought topics: %w", err)
+	}
+	return topics, nil
+}
+
+// LastThoughtTopic returns the most recently posted topic, or nil when the
+// bot has not posted a planned thought yet
+func (s *TweetStore) LastThoughtTopic(ctx context.Context) (*ThoughtTopicRecord, error) {
+	var topics []ThoughtTopicRecord
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ?", s.BotID()).
+		Order("posted_at DESC, id DESC").
+		Limit(1).
+		Find(&topics).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load last thought topic: %w", err)
+	}
+	if len(topics) == 0 {
+		return nil, nil
+	}
+	return &topics[0], nil
+}
+
+// TrendingHashtags returns the hashtags used most in tweets stored since the
+// given time, most used first. Each tweet counts a hashtag once.
+func (s *TweetStore) TrendingHashtags(ctx context.Context, since time.Time, limit int) ([]string, error) {
+	var texts []string
+	err := s.tweets(s.reader(ctx)).
+		Where("created_at >= ? AND author_id <> ? AND text LIKE ?", since, s.botID, "%#%").
+		Pluck("text", &texts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tweets for hashtags: %w", err)
+	}
+
+	counts := make(map[string]int)
+	spelling := make(map[string]string)
+	for _, text := range texts {
+		seen := make(map[string]bool)
+		for _, tag := range hashtagPattern.FindAllString(text, -1) {
+			key := strings.ToLower(tag)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[key]++
+			if _, ok := spelling[key]; !ok {
+				spelling[key] = tag
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	hashtags := make([]string, len(keys))
+	for i, key := range keys {
+		hashtags[i] = spelling[key]
+	}
+	return hashtags, nil
+}
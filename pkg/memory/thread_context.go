@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+// StoredTweetIDs returns which of the given tweets are already stored for this bot
+func (s *TweetStore) StoredTweetIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	stored := make(map[string]bool, len(ids))
+	if len(ids) == 0 {
+		return stored, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []string
+	if err := s.tweets(s.db.WithContext(ctx)).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up stored tweets: %w", err)
+	}
+	for _, id := range found {
+		stored[id] = true
+	}
+	return stored, nil
+}
+
+// SaveContextTweets stores the earlier tweets of a thread, such as the root
+// and the replies above a mention, so they appear in the conversation context
+// of later replies. They never need a reply themselves, keep their original
+// creation time, and never overwrite a tweet that is already stored.
+func (s *TweetStore) SaveContextTweets(ctx context.Context, tweets []TweetWithMeta) (int64, error) {
+	if len(tweets) == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	rows := make([]map[string]interface{}, 0, len(tweets))
+	seen := make(map[string]bool)
+	for _, t := range tweets {
+		if seen[t.Tweet.ID] {
+			continue
+		}
+		seen[t.Tweet.ID] = true
+
+		row := s.tweetRow(t.Tweet, t.Category, t.AuthorName, t.AuthorUsername, now, false)
+		row["needs_reply"] = false
+		row["author_followers"] = t.AuthorFollowers
+		row["author_verified"] = t.AuthorVerified
+		// Context must sort before the tweets it leads up to
+		createdAt := now
+		if parsed, err := time.Parse(time.RFC3339, t.Tweet.CreatedAt); err == nil {
+			createdAt = parsed
+		}
+		row["created_at"] = createdAt
+		if _, ok := row["edit_history_tweet_ids"]; !ok {
+			row["edit_history_tweet_ids"] = pq.StringArray(nil)
+		}
+		rows = append(rows, row)
+	}
+
+	result := s.db.WithContext(ctx).Table("tweets").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(rows, saveTweetsBatchSize)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to save context tweets: %w", result.Error)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tweets": len(rows),
+		"saved":  result.RowsAffected,
+	}).Debug("Saved thread context tweets")
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleTipper lets a user send tips from the bot's wallet with tip commands
+const RoleTipper = "tipper"
+
+// Tip statuses
+const (
+	// TipPending waits for an operator to approve or reject it
+	TipPending = "pending"
+	// TipSending is being sent; a tip stuck here was interrupted and needs
+	// checking on chain before anything is resent
+	TipSending = "sending"
+	// TipSent was sent; TxHash is its transaction
+	TipSent = "sent"
+	// TipConfirmed was sent and its transaction confirmed on chain
+	TipConfirmed = "confirmed"
+	// TipRejected was refused by the policy or an operator
+	TipRejected = "rejected"
+	// TipFailed could not be sent or its transaction reverted
+	TipFailed = "failed"
+)
+
+// Errors returned for tips
+var (
+	// ErrTipNotFound is returned for a tip that does not exist
+	ErrTipNotFound = errors.New("tip not found")
+	// ErrTipNotPending is returned when approving or rejecting a tip that is
+	// no longer waiting for approval
+	ErrTipNotPending = errors.New("tip is not pending")
+)
+
+// CommandRole grants a user a role for commands, e.g. RoleTipper
+type CommandRole struct {
+	BotID     string    `json:"-" gorm:"column:bot_id;primaryKey"`
+	UserID    string    `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Role      string    `json:"role" gorm:"column:role;primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (CommandRole) TableName() string {
+	return "command_roles"
+}
+
+// TipWallet is the address a user registered for receiving tips
+type TipWallet struct {
+	BotID     string    `json:"-" gorm:"column:bot_id;primaryKey"`
+	UserID    string    `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Username  string    `json:"username" gorm:"column:username"`
+	Address   string    `json:"address" gorm:"column:address"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TipWallet) TableName() string {
+	return "tip_wallets"
+}
+
+// Tip is a transfer requested with a tip command
+type Tip struct {
+	ID                int64  `json:"id" gorm:"column:id;primaryKey"`
+	BotID             string `json:"-" gorm:"column:bot_id"`
+	TweetID           string `json:"tweet_id" gorm:"column:tweet_id"`
+	ConversationID    string `json:"conversation_id" gorm:"column:conversation_id"`
+	SenderID          string `json:"sender_id" gorm:"column:sender_id"`
+	RecipientID       string `json:"recipient_id" gorm:"column:recipient_id"`
+	RecipientUsername string `json:"recipient_username" gorm:"column:recipient_username"`
+	RecipientAddress  string `json:"recipient_address" gorm:"column:recipient_address"`
+	Network           string `json:"network" gorm:"column:network"`
+	Token             string `json:"token" gorm:"column:token"`
+	Symbol            string `json:"symbol" gorm:"column:symbol"`
+	// Amount is in token base units, AmountUnits in whole tokens
+	Amount      string  `json:"amount" gorm:"column:amount"`
+	AmountUnits float64 `json:"amount_units" gorm:"column:amount_units"`
+	Status      string  `json:"status" gorm:"column:status"`
+	// Reason tells why a tip was held, rejected or failed
+	Reason    string    `json:"reason,omitempty" gorm:"column:reason"`
+	TxHash    string    `json:"tx_hash,omitempty" gorm:"column:tx_hash"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Tip) TableName() string {
+	return "tips"
+}
+
+// GrantRole gives a user a command role
+func (s *TweetStore) GrantRole(ctx context.Context, userID, role string) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&CommandRole{BotID: s.BotID(), UserID: userID, Role: role, CreatedAt: time.Now().UTC()}).Error
+	if err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+	return nil
+}
+
+// RevokeRole takes a command role from a user, reporting whether they had it
+func (s *TweetStore) RevokeRole(ctx context.Context, userID, role string) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Where("bot_id = ? AND user_id = ? AND role = ?", s.BotID(), userID, role).
+		Delete(&CommandRole{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke role: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// HasRole reports whether a user has a command role
+func (s *TweetStore) HasRole(ctx context.Context, userID, role string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&CommandRole{}).
+		Where("bot_id = ? AND user_id = ? AND role = ?", s.BotID(), userID, role).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check role: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ListRoles returns every command role granted, oldest first
+func (s *TweetStore) ListRoles(ctx context.Context) ([]CommandRole, error) {
+	var roles []CommandRole
+	err := s.reader(ctx).
+		Where("bot_id = ?", s.BotID()).
+		Order("created_at ASC, user_id ASC").
+		Find(&roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// SetTipWallet registers the address a user receives tips at, replacing any
+// earlier one
+func (s *TweetStore) SetTipWallet(ctx context.Context, userID, username, address string) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "bot_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"username", "address", "updated_at"}),
+		}).
+		Create(&TipWallet{
+			BotID:     s.BotID(),
+			UserID:    userID,
+			Username:  username,
+			Address:   address,
+			UpdatedAt: time.Now().UTC(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save tip wallet: %w", err)
+	}
+	return nil
+}
+
+// GetTipWallet returns the address a user registered for tips, or nil when
+// they have none
+func (s *TweetStore) GetTipWallet(ctx context.Context, userID string) (*TipWallet, error) {
+	var wallet TipWallet
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND user_id = ?", s.BotID(), userID).
+		Take(&wallet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tip wallet: %w", err)
+	}
+	return &wallet, nil
+}
+
+// CreateTip records a tip command and sets the tip's ID. It returns false,
+// without saving, when the command tweet already has a tip.
+func (s *TweetStore) CreateTip(ctx context.Context, tip *Tip) (bool, error) {
+	now := time.Now().UTC()
+	tip.BotID = s.BotID()
+	tip.CreatedAt = now
+	tip.UpdatedAt = now
+	result := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(tip)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to save tip: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetTip returns a tip by ID
+func (s *TweetStore) GetTip(ctx context.Context, id int64) (*Tip, error) {
+	var tip Tip
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND id = ?", s.BotID(), id).
+		Take(&tip).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTipNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tip: %w", err)
+	}
+	return &tip, nil
+}
+
+// TipForTweet returns the tip of a command tweet, or nil when it has none
+func (s *TweetStore) TipForTweet(ctx context.Context, tweetID string) (*Tip, error) {
+	var tip Tip
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND tweet_id = ?", s.BotID(), tweetID).
+		Take(&tip).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tip: %w", err)
+	}
+	return &tip, nil
+}
+
+// TipForTxHash returns the tip sent with a transaction hash, or nil when no
+// tip was
+func (s *TweetStore) TipForTxHash(ctx context.Context, txHash string) (*Tip, error) {
+	var tip Tip
+	err := s.db.WithContext(ctx).
+		Where("bot_id = ? AND tx_hash = ?", s.BotID(), txHash).
+		Take(&tip).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tip: %w", err)
+	}
+	return &tip, nil
+}
+
+// TipsByStatus returns the tips with a status, oldest first
+func (s *TweetStore) TipsByStatus(ctx context.Context, status string) ([]Tip, error) {
+	var tips []Tip
+	err := s.reader(ctx).
+		Where("bot_id = ? AND status = ?", s.BotID(), status).
+		Order("created_at ASC, id ASC").
+		Find(&tips).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tips: %w", err)
+	}
+	return tips, nil
+}
+
+// TransitionTip moves a tip from one status to another, recording why and
+// the transaction hash when there is one. It returns false when the tip is
+// no longer in the from status, e.g. because another request handled it.
+func (s *TweetStore) TransitionTip(ctx context.Context, id int64, from, to, reason, txHash string) (bool, error) {
+	updates := map[string]interface{}{
+		"status":     to,
+		"reason":     reason,
+		"updated_at": time.Now().UTC(),
+	}
+	if txHash != "" {
+		updates["tx_hash"] = txHash
+	}
+	result := s.db.WithContext(ctx).
+		Model(&Tip{}).
+		Where("bot_id = ? AND id = ? AND status = ?", s.BotID(), id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update tip: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TippedSince returns the whole tokens a sender has tipped since the given
+// time, counting tips that are sent or still on their way
+func (s *TweetStore) TippedSince(ctx context.Context, senderID string, since time.Time) (float64, error) {
+	var total float64
+	err := s.db.WithContext(ctx).
+		Model(&Tip{}).
+		Select("COALESCE(SUM(amount_units), 0)").
+		Where("bot_id = ? AND sender_id = ? AND created_at >= ? AND status IN ?",
+			s.BotID(), senderID, since.UTC(), []string{TipPending, TipSending, TipSent, TipConfirmed}).
+		Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum tips: %w", err)
+	}
+	return total, nil
+}
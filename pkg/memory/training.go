@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TrainingReply is one of the bot's replies with its engagement and the tweet
+// it answered, the raw material of a fine-tuning example
+type TrainingReply struct {
+	ReplyPerformance
+	ConversationID string
+	// ParentID and ParentText are the tweet the reply answered
+	ParentID   string
+	ParentText string
+}
+
+// TrainingReplies returns the bot's replies posted since the given time that
+// are not deleted, with the tweets they answered, oldest first
+func (s *TweetStore) TrainingReplies(ctx context.Context, since time.Time) ([]TrainingReply, error) {
+	var rows []struct {
+		ID             string          `gorm:"column:id"`
+		Text           string          `gorm:"column:text"`
+		CreatedAt      time.Time       `gorm:"column:created_at"`
+		PublicMetrics  json.RawMessage `gorm:"column:public_metrics;serializer:json"`
+		ConversationID string          `gorm:"column:conversation_id"`
+		ParentID       string          `gorm:"column:parent_id"`
+		ParentText     string          `gorm:"column:parent_text"`
+	}
+	err := s.reader(ctx).
+		Table("tweets AS r").
+		Select("r.id, r.text, r.created_at, r.public_metrics, r.conversation_id, p.id AS parent_id, p.text AS parent_text").
+		Joins("JOIN tweets AS p ON p.bot_id = r.bot_id AND p.last_reply_id = r.id").
+		Where("r.bot_id = ? AND r.author_id = ? AND r.category = ? AND r.created_at >= ?",
+			s.BotID(), s.BotID(), CategoryReply, since).
+		Where("r.deleted_at IS NULL AND p.deleted_at IS NULL").
+		Order("r.created_at ASC, r.id ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load replies for training: %w", err)
+	}
+
+	replies := make([]TrainingReply, 0, len(rows))
+	for _, row := range rows {
+		replies = append(replies, TrainingReply{
+			ReplyPerformance: replyPerformance(row.ID, row.Text, row.CreatedAt, row.PublicMetrics),
+			ConversationID:   row.ConversationID,
+			ParentID:         row.ParentID,
+			ParentText:       row.ParentText,
+		})
+	}
+	return replies, nil
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EditableTweet is a stored mention with the edit history it was last seen with
+type EditableTweet struct {
+	TweetID        string         `gorm:"column:id"`
+	ConversationID string         `gorm:"column:conversation_id"`
+	EditHistory    pq.StringArray `gorm:"column:edit_history_tweet_ids"`
+}
+
+// LatestVersionID returns the ID of the newest known version of the tweet
+func (t EditableTweet) LatestVersionID() string {
+	if len(t.EditHistory) == 0 {
+		return t.TweetID
+	}
+	return t.EditHistory[len(t.EditHistory)-1]
+}
+
+// EditedTweet is a stored tweet that was edited after the bot replied to it
+type EditedTweet struct {
+	TweetID        string    `json:"tweet_id" gorm:"column:id"`
+	ConversationID string    `json:"conversation_id" gorm:"column:conversation_id"`
+	Text           string    `json:"text" gorm:"column:text"`
+	LastReplyID    string    `json:"last_reply_id" gorm:"column:last_reply_id"`
+	EditedAt       time.Time `json:"edited_at" gorm:"column:edited_at"`
+}
+
+// EditableMentions returns the mentions stored since the given time, which
+// their authors may still be editing, oldest first
+func (s *TweetStore) EditableMentions(ctx context.Context, since time.Time) ([]EditableTweet, error) {
+	var tweets []EditableTweet
+	err := s.tweets(s.reader(ctx)).
+		Select("id, conversation_id, edit_history_tweet_ids").
+		Where("category = ? AND created_at >= ?", CategoryMention, since).
+		Order("created_at ASC").
+		Scan(&tweets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load editable mentions: %w", err)
+	}
+	return tweets, nil
+}
+
+// RecordTweetEdit stores the latest version of an edited tweet under the ID
+// the tweet was stored with: its text and edit history. A tweet the
+// bot already answered is flagged as edited after reply, since the reply was
+// written for an earlier version. It reports whether the tweet was flagged.
+func (s *TweetStore) RecordTweetEdit(ctx context.Context, id string, latest twitter.Tweet) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var flagged bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var replied []bool
+		if err := s.tweets(tx).Where("id = ?", id).Pluck("replied_to", &replied).Error; err != nil {
+			return fmt.Errorf("failed to load tweet %s: %w", id, err)
+		}
+		if len(replied) == 0 {
+			return fmt.Errorf("tweet not found: %s", id)
+		}
+		flagged = replied[0]
+
+		updates := map[string]interface{}{
+			"text":                   latest.Text,
+			"edit_history_tweet_ids": pq.StringArray(latest.EditHistoryTweetIDs),
+			"edited_at":              now,
+			"last_updated":           now,
+		}
+		if flagged {
+			updates["edited_after_reply"] = true
+		}
+		if err := s.tweets(tx).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to record edit of tweet %s: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tweet_id":           id,
+		"version_id":         latest.ID,
+		"versions":           len(latest.EditHistoryTweetIDs),
+		"edited_after_reply": flagged,
+	}).Info("Recorded tweet edit")
+	return flagged, nil
+}
+
+// EditedAfterReply returns the stored tweets that were edited after the bot
+// replied to them, oldest edit first. Replacing the reply clears the flag.
+func (s *TweetStore) EditedAfterReply(ctx context.Context) ([]EditedTweet, error) {
+	var tweets []EditedTweet
+	err := s.tweets(s.reader(ctx)).
+		Select("id, conversation_id, text, last_reply_id, edited_at").
+		Where("edited_after_reply = ?", true).
+		Order("edited_at ASC").
+		Scan(&tweets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tweets edited after reply: %w", err)
+	}
+	return tweets, nil
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultTweetPageSize is the page size when the options set no limit
+	DefaultTweetPageSize = 100
+	// MaxTweetPageSize caps the page size, so one page stays small in memory
+	MaxTweetPageSize = 1000
+)
+
+// TweetOrder is the order tweets are paged in, by creation time and then ID
+type TweetOrder string
+
+const (
+	OldestFirst TweetOrder = "asc"
+	NewestFirst TweetOrder = "desc"
+)
+
+// ErrInvalidCursor is returned for a page cursor that names no stored tweet
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// TweetPageOptions selects one page of stored tweets
+type TweetPageOptions struct {
+	// Limit is the page size; 0 means DefaultTweetPageSize and larger values
+	// are capped at MaxTweetPageSize
+	Limit int
+	// After is the Next cursor of the previous page; empty starts at the
+	// first page
+	After string
+	// Since and Until keep tweets created at or after Since and before Until;
+	// zero values leave that end open
+	Since time.Time
+	Until time.Time
+	// Order defaults to OldestFirst
+	Order TweetOrder
+}
+
+// TweetPage is one page of stored tweets
+type TweetPage struct {
+	Tweets []StoredTweet `json:"tweets"`
+	// Next is the cursor of the following page, empty on the last page
+	Next string `json:"next,omitempty"`
+}
+
+// Validate reports options the store cannot page by
+func (o TweetPageOptions) Validate() error {
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must not be negative, got %d", o.Limit)
+	}
+	if o.Order != "" && o.Order != OldestFirst && o.Order != NewestFirst {
+		return fmt.Errorf("order must be %q or %q, got %q", OldestFirst, NewestFirst, o.Order)
+	}
+	if !o.Since.IsZero() && !o.Until.IsZero() && !o.Until.After(o.Since) {
+		return errors.New("until must be after since")
+	}
+	return nil
+}
+
+// pageSize is the number of tweets a page holds
+func (o TweetPageOptions) pageSize() int {
+	switch {
+	case o.Limit == 0:
+		return DefaultTweetPageSize
+	case o.Limit > MaxTweetPageSize:
+		return MaxTweetPageSize
+	default:
+		return o.Limit
+	}
+}
+
+// tweetPage loads the page of the bot's tweets matching query. Pages are cut
+// by keyset on (created_at, id), so deep pages cost no more than the first
+// and tweets saved meanwhile do not shift later pages. The cursor is the ID
+// of the last tweet of the previous page.
+func (s *TweetStore) tweetPage(ctx context.Context, query *gorm.DB, options TweetPageOptions) (*TweetPage, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !options.Since.IsZero() {
+		query = query.Where("created_at >= ?", options.Since.UTC())
+	}
+	if !options.Until.IsZero() {
+		query = query.Where("created_at < ?", options.Until.UTC())
+	}
+
+	direction, comparison := "ASC", ">"
+	if options.Order == NewestFirst {
+		direction, comparison = "DESC", "<"
+	}
+	if options.After != "" {
+		var found int64
+		if err := s.tweets(s.reader(ctx)).Where("id = ?", options.After).Count(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up page cursor: %w", err)
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("%w: tweet %s is not stored", ErrInvalidCursor, options.After)
+		}
+		// Row values compare the stored columns as they are, whatever
+		// format the driver writes timestamps in
+		query = query.Where(
+			fmt.Sprintf("(created_at, id) %s (SELECT created_at, id FROM tweets WHERE bot_id = ? AND id = ?)", comparison),
+			s.botID, options.After,
+		)
+	}
+
+	size := options.pageSize()
+	var tweets []StoredTweet
+	err := query.
+		Order(fmt.Sprintf("created_at %s, id %s", direction, direction)).
+		Limit(size + 1).
+		Find(&tweets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tweets: %w", err)
+	}
+
+	page := &TweetPage{Tweets: tweets}
+	if len(tweets) > size {
+		page.Tweets = tweets[:size]
+		page.Next = page.Tweets[size-1].ID
+	}
+	return page, nil
+}
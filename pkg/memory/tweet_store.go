@@ -2,11 +2,14 @@ package memory
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
 	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -38,9 +41,11 @@ type StoredTweet struct {
 	ProcessedAt     time.Time        `json:"processed_at" gorm:"column:processed_at"`
 	LastUpdated     time.Time        `json:"last_updated" gorm:"column:last_updated"`
 	ProcessCount    int              `json:"process_count" gorm:"column:process_count"`
-	ConversationRef *ConversationRef `json:"conversation_ref" gorm:"column:conversation_ref;type:jsonb"`
+	ConversationRef *ConversationRef `json:"conversation_ref" gorm:"column:conversation_ref;type:jsonb;serializer:json"`
 	AuthorName      string           `json:"author_name" gorm:"column:author_name"`
 	AuthorUsername  string           `json:"author_username" gorm:"column:author_username"`
+	SpamScore       float64          `json:"spam_score" gorm:"column:spam_score"`
+	Sentiment       float64          `json:"sentiment" gorm:"column:sentiment"`
 }
 
 // TableName specifies the table name for GORM
@@ -61,24 +66,142 @@ type ConversationRef struct {
 	ReplyCount      int       `json:"reply_count"`       // Number of replies in this conversation
 }
 
+// TweetStore persists tweets for a single bot account. Every row is keyed by
+// bot_id so several accounts can share one database without seeing each other's state.
 type TweetStore struct {
-	mu     sync.RWMutex
-	logger *logrus.Logger
-	db     *gorm.DB
-	botID  string
-	env    EnvConfig
+	mu            sync.RWMutex
+	logger        *logrus.Logger
+	db            *gorm.DB
+	botID         string
+	env           EnvConfig
+	agentName     string
+	agentUsername string
+	// spamThreshold excludes tweets scoring at or above it from replies; 0 disables
+	spamThreshold float64
+	// priority orders recalled threads; the zero value keeps them oldest first
+	priority ReplyPriority
 }
 
 func NewTweetStore(logger *logrus.Logger, db *gorm.DB, botID string, env EnvConfig) (*TweetStore, error) {
 	return &TweetStore{
-		logger: logger,
-		db:     db,
-		botID:  botID,
-		env:    env,
+		logger:        logger,
+		db:            db,
+		botID:         botID,
+		env:           env,
+		agentName:     AgentName,
+		agentUsername: AgentUsername,
 	}, nil
 }
 
-func (s *TweetStore) SaveTweet(tweet twitter.Tweet, category TweetCategory, authorName, authorUsername string) error {
+// SetIdentity overrides the display name and username recorded on the bot's own replies
+func (s *TweetStore) SetIdentity(name, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name != "" {
+		s.agentName = name
+	}
+	if username != "" {
+		s.agentUsername = username
+	}
+}
+
+// SetSpamThreshold sets the spam score at or above which stored tweets are
+// excluded from replies. A threshold of 0 disables the exclusion.
+func (s *TweetStore) SetSpamThreshold(threshold float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spamThreshold = threshold
+}
+
+// SetReplyPriority sets how recalled threads and queued mentions are scored
+func (s *TweetStore) SetReplyPriority(priority ReplyPriority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priority = priority
+}
+
+// ReplyPriority returns how recalled threads and queued mentions are scored
+func (s *TweetStore) ReplyPriority() ReplyPriority {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.priority
+}
+
+// SetSentiment records how friendly a stored tweet is towards the bot, from -1
+// (hostile) to 1 (praise)
+func (s *TweetStore) SetSentiment(ctx context.Context, tweetID string, score float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("id = ?", tweetID).
+		Update("sentiment", score).Error
+	if err != nil {
+		return fmt.Errorf("failed to set sentiment: %w", err)
+	}
+	return nil
+}
+
+// SetSpamScore records the spam filter's score for a stored tweet
+func (s *TweetStore) SetSpamScore(ctx context.Context, tweetID string, score float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.tweets(s.db.WithContext(ctx)).
+		Where("id = ?", tweetID).
+		Update("spam_score", score).Error
+	if err != nil {
+		return fmt.Errorf("failed to set spam score: %w", err)
+	}
+	return nil
+}
+
+// ClaimUnassignedTweets assigns rows stored before tweets were partitioned by
+// bot account to this store's bot. Only call this when running a single account.
+func (s *TweetStore) ClaimUnassignedTweets(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := s.db.WithContext(ctx).Table("tweets").
+		Where("bot_id = ''").
+		Update("bot_id", s.botID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to claim unassigned tweets: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// tweets returns a query on the tweets table scoped to this store's bot
+func (s *TweetStore) tweets(db *gorm.DB) *gorm.DB {
+	return db.Table("tweets").Where("bot_id = ?", s.botID)
+}
+
+// jsonColumn prepares a structured value for a JSON column. Postgres encodes it
+// into jsonb itself; SQLite has no JSON type, so the value is stored as JSON text.
+// reader returns a handle for read-only recall and analytics queries, which
+// may be served by a read replica and so can lag slightly behind writes
+func (s *TweetStore) reader(ctx context.Context) *gorm.DB {
+	return db.Replica(s.db.WithContext(ctx))
+}
+
+func (s *TweetStore) jsonColumn(v interface{}) interface{} {
+	if s.db.Dialector.Name() != "sqlite" || v == nil {
+		return v
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to encode JSON column, storing NULL")
+		return nil
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	return string(data)
+}
+
+func (s *TweetStore) SaveTweet(ctx context.Context, tweet twitter.Tweet, category TweetCategory, authorName, authorUsername string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -93,75 +216,18 @@ func (s *TweetStore) SaveTweet(tweet twitter.Tweet, category TweetCategory, auth
 	}).Debug("Attempting to save tweet")
 
 	now := time.Now()
+	db := s.db.WithContext(ctx)
 
 	// Check if we're already participating in this conversation
 	var participatingCount int64
 	if tweet.ConversationID != "" {
-		s.db.Table("tweets").
+		s.tweets(db).
 			Where("conversation_id = ? AND is_participating = ?",
 				tweet.ConversationID, true).
 			Count(&participatingCount)
 	}
 
-	// Prepare conversation ref if exists
-	var conversationRef *ConversationRef
-	if tweet.ConversationID != "" {
-		s.logger.WithFields(logrus.Fields{
-			"tweet_id":        tweet.ID,
-			"conversation_id": tweet.ConversationID,
-		}).Debug("Processing tweet with conversation ID")
-
-		conversationRef = &ConversationRef{
-			ConversationID: tweet.ConversationID,
-			LastReplyAt:    now,
-		}
-
-		if tweet.ReferencedTweets != nil {
-			s.logger.WithField("referenced_tweets", tweet.ReferencedTweets).Debug("Tweet has referenced tweets")
-			for _, ref := range tweet.ReferencedTweets {
-				if ref.Type == "replied_to" {
-					conversationRef.ParentID = ref.ID
-					conversationRef.IsRoot = false
-					s.logger.WithFields(logrus.Fields{
-						"parent_id": ref.ID,
-						"type":      ref.Type,
-					}).Debug("Found parent tweet reference")
-				}
-			}
-		} else {
-			conversationRef.IsRoot = true
-			conversationRef.RootID = tweet.ID
-			s.logger.Debug("Tweet marked as conversation root")
-		}
-	}
-
-	// Prepare base tweet data
-	tweetData := map[string]interface{}{
-		"id":                  tweet.ID,
-		"text":                tweet.Text,
-		"conversation_id":     tweet.ConversationID,
-		"author_id":           tweet.AuthorID,
-		"author_name":         authorName,
-		"author_username":     authorUsername,
-		"category":            category,
-		"processed_at":        now,
-		"last_updated":        now,
-		"conversation_ref":    conversationRef,
-		"attachments":         tweet.Attachments,
-		"context_annotations": tweet.ContextAnnotations,
-		"edit_controls":       tweet.EditControls,
-		"entities":            tweet.Entities,
-		"geo":                 tweet.Geo,
-		"lang":                tweet.Lang,
-		"possibly_sensitive":  tweet.PossiblySensitive,
-		"public_metrics":      tweet.PublicMetrics,
-		"referenced_tweets":   tweet.ReferencedTweets,
-		"reply_settings":      tweet.ReplySettings,
-		"source":              tweet.Source,
-		"withheld":            tweet.Withheld,
-		"needs_reply":         true,
-		"is_participating":    participatingCount > 0,
-	}
+	tweetData := s.tweetRow(tweet, category, authorName, authorUsername, now, participatingCount > 0)
 
 	// Handle replies specifically
 	if tweet.ConversationID != "" && tweet.ReferencedTweets != nil {
@@ -174,7 +240,7 @@ func (s *TweetStore) SaveTweet(tweet twitter.Tweet, category TweetCategory, auth
 				}).Debug("Processing new reply in conversation")
 
 				// Update parent tweet
-				updateResult := s.db.Table("tweets").
+				updateResult := s.tweets(db).
 					Where("id = ?", ref.ID).
 					Updates(map[string]interface{}{
 						"unread_replies": gorm.Expr("unread_replies + 1"),
@@ -187,7 +253,7 @@ func (s *TweetStore) SaveTweet(tweet twitter.Tweet, category TweetCategory, auth
 				}
 
 				// Update all tweets in conversation
-				s.db.Table("tweets").
+				s.tweets(db).
 					Where("conversation_id = ? AND id != ?",
 						tweet.ConversationID, ref.ID).
 					Updates(map[string]interface{}{
@@ -199,21 +265,10 @@ func (s *TweetStore) SaveTweet(tweet twitter.Tweet, category TweetCategory, auth
 		}
 	}
 
-	// Handle edit_history_tweet_ids as a proper array
-	if tweet.EditHistoryTweetIDs != nil {
-		var historyIDs pq.StringArray
-		if len(tweet.EditHistoryTweetIDs) == 0 {
-			historyIDs = pq.StringArray{tweet.ID}
-		} else {
-			historyIDs = pq.StringArray(tweet.EditHistoryTweetIDs)
-		}
-		tweetData["edit_history_tweet_ids"] = historyIDs
-	}
-
 	// Perform upsert operation
-	result := s.db.Table("tweets").
+	result := db.Table("tweets").
 		Clauses(clause.OnConflict{
-			Columns:   []clause.Column{{Name: "id"}},
+			Columns:   []clause.Column{{Name: "bot_id"}, {Name: "id"}},
 			DoUpdates: clause.Assignments(tweetData),
 		}).
 		Create(tweetData)
@@ -235,51 +290,106 @@ func (s *TweetStore) SaveTweet(tweet twitter.Tweet, category TweetCategory, auth
 	return nil
 }
 
+// conversationRefFor describes the tweet's place in its conversation, or
+// returns nil when the tweet has no conversation ID
+func (s *TweetStore) conversationRefFor(tweet twitter.Tweet, now time.Time) *ConversationRef {
+	if tweet.ConversationID == "" {
+		return nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tweet_id":        tweet.ID,
+		"conversation_id": tweet.ConversationID,
+	}).Debug("Processing tweet with conversation ID")
+
+	conversationRef := &ConversationRef{
+		ConversationID: tweet.ConversationID,
+		LastReplyAt:    now,
+	}
+
+	if tweet.ReferencedTweets != nil {
+		s.logger.WithField("referenced_tweets", tweet.ReferencedTweets).Debug("Tweet has referenced tweets")
+		for _, ref := range tweet.ReferencedTweets {
+			if ref.Type == "replied_to" {
+				conversationRef.ParentID = ref.ID
+				conversationRef.IsRoot = false
+				s.logger.WithFields(logrus.Fields{
+					"parent_id": ref.ID,
+					"type":      ref.Type,
+				}).Debug("Found parent tweet reference")
+			}
+		}
+	} else {
+		conversationRef.IsRoot = true
+		conversationRef.RootID = tweet.ID
+		s.logger.Debug("Tweet marked as conversation root")
+	}
+	return conversationRef
+}
+
+// tweetRow maps a tweet to its columns in the tweets table
+func (s *TweetStore) tweetRow(tweet twitter.Tweet, category TweetCategory, authorName, authorUsername string, now time.Time, participating bool) map[string]interface{} {
+	row := map[string]interface{}{
+		"id":                  tweet.ID,
+		"bot_id":              s.botID,
+		"text":                tweet.Text,
+		"conversation_id":     tweet.ConversationID,
+		"author_id":           tweet.AuthorID,
+		"author_name":         authorName,
+		"author_username":     authorUsername,
+		"in_reply_to_user_id": tweet.InReplyToUserID,
+		"category":            category,
+		"processed_at":        now,
+		"last_updated":        now,
+		"conversation_ref":    s.jsonColumn(s.conversationRefFor(tweet, now)),
+		"attachments":         s.jsonColumn(tweet.Attachments),
+		"context_annotations": s.jsonColumn(tweet.ContextAnnotations),
+		"edit_controls":       s.jsonColumn(tweet.EditControls),
+		"entities":            s.jsonColumn(tweet.Entities),
+		"geo":                 s.jsonColumn(tweet.Geo),
+		"lang":                tweet.Lang,
+		"possibly_sensitive":  tweet.PossiblySensitive,
+		"public_metrics":      s.jsonColumn(tweet.PublicMetrics),
+		"referenced_tweets":   s.jsonColumn(tweet.ReferencedTweets),
+		"reply_settings":      tweet.ReplySettings,
+		"source":              tweet.Source,
+		"withheld":            s.jsonColumn(tweet.Withheld),
+		"needs_reply":         true,
+		"is_participating":    participating,
+	}
+
+	// Handle edit_history_tweet_ids as a proper array
+	if tweet.EditHistoryTweetIDs != nil {
+		var historyIDs pq.StringArray
+		if len(tweet.EditHistoryTweetIDs) == 0 {
+			historyIDs = pq.StringArray{tweet.ID}
+		} else {
+			historyIDs = pq.StringArray(tweet.EditHistoryTweetIDs)
+		}
+		row["edit_history_tweet_ids"] = historyIDs
+	}
+	return row
+}
+
 // SaveAgentReply stores our own replies in the database
-func (s *TweetStore) SaveAgentReply(originalTweetID, replyTweetID, conversationID string, replyText string) error {
+func (s *TweetStore) SaveAgentReply(ctx context.Context, originalTweetID, replyTweetID, conversationID string, replyText string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	now := time.Now()
 
 	// Save our reply with ALL required fields
-	tweetData := map[string]interface{}{
-		// Required fields
-		"processed_at":   now,
-		"process_count":  0,
-		"needs_reply":    false, // Our own tweets never need replies
-		"unread_replies": 0,
-		"reply_count":    0,
-
-		// Existing fields
-		"id":               replyTweetID,
-		"text":             replyText,
-		"conversation_id":  conversationID,
-		"created_at":       now,
-		"category":         CategoryReply,
-		"is_participating": true,
-		"replied_to":       false,
-		"last_updated":     now,
-		"author_id":        s.botID,
-		"author_name":      AgentName,
-		"author_username":  AgentUsername,
-		"conversation_ref": &ConversationRef{
-			ConversationID: conversationID,
-			ParentID:       originalTweetID,
-			IsRoot:         false,
-			LastReplyAt:    now,
-		},
-	}
+	tweetData := s.agentReplyRow(originalTweetID, replyTweetID, conversationID, replyText, now)
 
 	// Start a transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Save the reply tweet
 		if err := tx.Table("tweets").Create(tweetData).Error; err != nil {
 			return fmt.Errorf("failed to save agent reply: %w", err)
 		}
 
 		// Update the original tweet status
-		if err := tx.Table("tweets").
+		if err := s.tweets(tx).
 			Where("id = ?", originalTweetID).
 			Updates(map[string]interface{}{
 				"replied_to":       true,
@@ -293,7 +403,7 @@ func (s *TweetStore) SaveAgentReply(originalTweetID, replyTweetID, conversationI
 		}
 
 		// Update all tweets in the conversation
-		if err := tx.Table("tweets").
+		if err := s.tweets(tx).
 			Where("conversation_id = ?", conversationID).
 			Updates(map[string]interface{}{
 				"is_participating": true,
@@ -306,35 +416,113 @@ func (s *TweetStore) SaveAgentReply(originalTweetID, replyTweetID, conversationI
 	})
 }
 
-func (s *TweetStore) GetTweet(id string) (*StoredTweet, error) {
+// agentReplyRow is the tweets row of a reply the bot posted
+func (s *TweetStore) agentReplyRow(originalTweetID, replyTweetID, conversationID, replyText string, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		// Required fields
+		"processed_at":   now,
+		"process_count":  0,
+		"needs_reply":    false, // Our own tweets never need replies
+		"unread_replies": 0,
+		"reply_count":    0,
+
+		// Existing fields
+		"id":               replyTweetID,
+		"bot_id":           s.botID,
+		"text":             replyText,
+		"conversation_id":  conversationID,
+		"created_at":       now,
+		"category":         CategoryReply,
+		"is_participating": true,
+		"replied_to":       false,
+		"last_updated":     now,
+		"author_id":        s.botID,
+		"author_name":      s.agentName,
+		"author_username":  s.agentUsername,
+		"conversation_ref": s.jsonColumn(&ConversationRef{
+			ConversationID: conversationID,
+			ParentID:       originalTweetID,
+			IsRoot:         false,
+			LastReplyAt:    now,
+		}),
+	}
+}
+
+func (s *TweetStore) GetTweet(ctx context.Context, id string) (*StoredTweet, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var tweet StoredTweet
-	result := s.db.Where("id = ?", id).First(&tweet)
-	if result.Error != nil {
+	result := s.tweets(s.db.WithContext(ctx)).Where("id = ?", id).First(&tweet)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, fmt.Errorf("tweet not found: %s", id)
 	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get tweet %s: %w", id, result.Error)
+	}
 
 	return &tweet, nil
 }
 
-func (s *TweetStore) GetTweetsByCategory(category TweetCategory) []StoredTweet {
+// GetTweetsByCategory returns a page of the stored tweets of a category
+func (s *TweetStore) GetTweetsByCategory(ctx context.Context, category TweetCategory, options TweetPageOptions) (*TweetPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	page, err := s.tweetPage(ctx, s.tweets(s.reader(ctx)).Where("category = ?", category), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s tweets: %w", category, err)
+	}
+	return page, nil
+}
+
+// GetConversation returns a page of the stored tweets of a conversation
+func (s *TweetStore) GetConversation(ctx context.Context, conversationID string, options TweetPageOptions) (*TweetPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	page, err := s.tweetPage(ctx, s.tweets(s.reader(ctx)).Where("conversation_id = ?", conversationID), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation %s: %w", conversationID, err)
+	}
+	return page, nil
+}
+
+// GetTweetsByAuthor returns a page of the stored tweets of an author
+func (s *TweetStore) GetTweetsByAuthor(ctx context.Context, authorID string, options TweetPageOptions) (*TweetPage, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var tweets []StoredTweet
-	s.db.Where("category = ?", category).Find(&tweets)
-	return tweets
+	page, err := s.tweetPage(ctx, s.tweets(s.reader(ctx)).Where("author_id = ?", authorID), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tweets of %s: %w", authorID, err)
+	}
+	return page, nil
 }
 
-func (s *TweetStore) GetConversation(conversationID string) []StoredTweet {
+// HasTweet reports whether a tweet is already stored for this bot
+func (s *TweetStore) HasTweet(ctx context.Context, id string) (bool, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var tweets []StoredTweet
-	s.db.Where("conversation_id = ?", conversationID).Find(&tweets)
-	return tweets
+	var count int64
+	if err := s.tweets(s.db.WithContext(ctx)).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to look up tweet: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RepliedTo reports whether the bot has answered a stored tweet. It always
+// reads the primary so a reply just posted by another instance is seen.
+func (s *TweetStore) RepliedTo(ctx context.Context, id string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var repliedTo []bool
+	if err := s.tweets(s.db.WithContext(ctx)).Where("id = ?", id).Pluck("replied_to", &repliedTo).Error; err != nil {
+		return false, fmt.Errorf("failed to look up reply status: %w", err)
+	}
+	return len(repliedTo) > 0 && repliedTo[0], nil
 }
 
 // Helper method to determine tweet category based on tweet content
@@ -361,13 +549,13 @@ func DetermineTweetCategory(tweet twitter.Tweet) TweetCategory {
 }
 
 // UpdateTweetAfterReply updates the tweet status after we've posted a reply
-func (s *TweetStore) UpdateTweetAfterReply(tweetID string, replyTweetID string) error {
+func (s *TweetStore) UpdateTweetAfterReply(ctx context.Context, tweetID string, replyTweetID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	now := time.Now()
 
-	result := s.db.Table("tweets").
+	result := s.tweets(s.db.WithContext(ctx)).
 		Where("id = ?", tweetID).
 		Updates(map[string]interface{}{
 			"replied_to":       true,
@@ -398,3 +586,17 @@ func (ts *TweetStore) UpdateBotID(ctx context.Context, botID string) error {
 	ts.botID = botID
 	return nil
 }
+
+// BotID returns the ID of the account this store records replies for
+func (ts *TweetStore) BotID() string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.botID
+}
+
+// Username returns the handle of the account this store records replies for
+func (ts *TweetStore) Username() string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.agentUsername
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"gorm.io/gorm/clause"
+)
+
+// QuotaWindow is the consumption of one endpoint in one rate limit window
+type QuotaWindow struct {
+	BotID       string    `json:"-" gorm:"column:bot_id;primaryKey"`
+	Endpoint    string    `json:"endpoint" gorm:"column:endpoint;primaryKey"`
+	WindowReset time.Time `json:"window_reset" gorm:"column:window_reset;primaryKey"`
+	Limit       int       `json:"limit" gorm:"column:quota_limit"`
+	Remaining   int       `json:"remaining" gorm:"column:remaining"`
+	Requests    int       `json:"requests" gorm:"column:requests"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (QuotaWindow) TableName() string {
+	return "twitter_quota"
+}
+
+// SaveQuotaWindows records the bot's API consumption per endpoint and window,
+// replacing what was recorded for a window before
+func (s *TweetStore) SaveQuotaWindows(ctx context.Context, quotas []twitter.EndpointQuota) error {
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	botID := s.BotID()
+	windows := make([]QuotaWindow, 0, len(quotas))
+	for _, quota := range quotas {
+		windows = append(windows, QuotaWindow{
+			BotID:       botID,
+			Endpoint:    quota.Endpoint,
+			WindowReset: quota.Reset.UTC(),
+			Limit:       quota.Limit,
+			Remaining:   quota.Remaining,
+			Requests:    quota.Requests,
+			UpdatedAt:   quota.UpdatedAt,
+		})
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "bot_id"}, {Name: "endpoint"}, {Name: "window_reset"}},
+			DoUpdates: clause.AssignmentColumns([]string{"quota_limit", "remaining", "requests", "updated_at"}),
+		}).
+		Create(&windows).Error
+	if err != nil {
+		return fmt.Errorf("failed to save quota windows: %w", err)
+	}
+	return nil
+}
+
+// QuotaWindows returns the bot's recorded windows that reset after since,
+// newest first. An empty endpoint returns the windows of every endpoint.
+func (s *TweetStore) QuotaWindows(ctx context.Context, endpoint string, since time.Time) ([]QuotaWindow, error) {
+	query := s.reader(ctx).
+		Where("bot_id = ? AND window_reset > ?", s.BotID(), since.UTC())
+	if endpoint != "" {
+		query = query.Where("endpoint = ?", endpoint)
+	}
+
+	var windows []QuotaWindow
+	if err := query.Order("window_reset DESC, endpoint").Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load quota windows: %w", err)
+	}
+	return windows, nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"gorm.io/gorm/clause"
+)
+
+// WalletNonce is a nonce the wallet issued whose transaction has not been mined
+type WalletNonce struct {
+	Network string `json:"network" gorm:"column:network;primaryKey"`
+	Address string `json:"address" gorm:"column:address;primaryKey"`
+	Nonce   uint64 `json:"nonce" gorm:"column:nonce;primaryKey"`
+	// TxHash is empty while the nonce is only reserved
+	TxHash   string     `json:"tx_hash" gorm:"column:tx_hash"`
+	IssuedAt time.Time  `json:"issued_at" gorm:"column:issued_at"`
+	SentAt   *time.Time `json:"sent_at" gorm:"column:sent_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WalletNonce) TableName() string {
+	return "wallet_nonces"
+}
+
+// SaveNonce implements wallet.NonceStore, inserting or updating the record
+// of a nonce. Nonces belong to the wallet address rather than the bot, so
+// accounts sharing a wallet share its records.
+func (s *TweetStore) SaveNonce(ctx context.Context, record wallet.NonceRecord) error {
+	row := WalletNonce{
+		Network:  string(record.Network),
+		Address:  record.Address.Hex(),
+		Nonce:    record.Nonce,
+		IssuedAt: record.IssuedAt,
+	}
+	updates := []string{"issued_at"}
+	if record.TxHash != (common.Hash{}) {
+		row.TxHash = record.TxHash.Hex()
+		sentAt := record.SentAt
+		row.SentAt = &sentAt
+		updates = append(updates, "tx_hash", "sent_at")
+	}
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "network"}, {Name: "address"}, {Name: "nonce"}},
+		DoUpdates: clause.AssignmentColumns(updates),
+	}).Create(&row).Error
+	if err != nil {
+		return fmt.Errorf("failed to save wallet nonce: %w", err)
+	}
+	return nil
+}
+
+// DeleteNonce implements wallet.NonceStore
+func (s *TweetStore) DeleteNonce(ctx context.Context, network wallet.NetworkType, address common.Address, nonce uint64) error {
+	err := s.db.WithContext(ctx).
+		Where("network = ? AND address = ? AND nonce = ?", string(network), address.Hex(), nonce).
+		Delete(&WalletNonce{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete wallet nonce: %w", err)
+	}
+	return nil
+}
+
+// ListNonces implements wallet.NonceStore, returning the records of an
+// address on a network, lowest nonce first
+func (s *TweetStore) ListNonces(ctx context.Context, network wallet.NetworkType, address common.Address) ([]wallet.NonceRecord, error) {
+	var rows []WalletNonce
+	err := s.db.WithContext(ctx).
+		Where("network = ? AND address = ?", string(network), address.Hex()).
+		Order("nonce ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet nonces: %w", err)
+	}
+
+	records := make([]wallet.NonceRecord, len(rows))
+	for i, row := range rows {
+		records[i] = wallet.NonceRecord{
+			Network:  wallet.NetworkType(row.Network),
+			Address:  common.HexToAddress(row.Address),
+			Nonce:    row.Nonce,
+			IssuedAt: row.IssuedAt,
+		}
+		if row.TxHash != "" {
+			records[i].TxHash = common.HexToHash(row.TxHash)
+		}
+		if row.SentAt != nil {
+			records[i].SentAt = *row.SentAt
+		}
+	}
+	return records, nil
+}
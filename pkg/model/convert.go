@@ -0,0 +1,177 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
+)
+
+// FromTwitter converts a tweet of the v2 API. The author is usually found in
+// the response's includes; pass nil when it was not expanded.
+func FromTwitter(tweet twitter.Tweet, author *twitter.User) Tweet {
+	t := Tweet{
+		ID:              tweet.ID,
+		Text:            tweet.Text,
+		ConversationID:  tweet.ConversationID,
+		Lang:            tweet.Lang,
+		Source:          SourceTwitter,
+		Author:          Author{ID: tweet.AuthorID},
+		InReplyToUserID: tweet.InReplyToUserID,
+		Metrics: Metrics{
+			Likes:    tweet.PublicMetrics.LikeCount,
+			Retweets: tweet.PublicMetrics.RetweetCount,
+			Replies:  tweet.PublicMetrics.ReplyCount,
+			Quotes:   tweet.PublicMetrics.QuoteCount,
+		},
+	}
+	if createdAt, err := time.Parse(time.RFC3339, tweet.CreatedAt); err == nil {
+		t.CreatedAt = createdAt
+	}
+	if author != nil {
+		t.Author = Author{
+			ID:        author.ID,
+			Username:  author.Username,
+			Name:      author.Name,
+			Followers: author.PublicMetrics.FollowersCount,
+			Verified:  author.Verified,
+		}
+	}
+
+	for _, ref := range tweet.ReferencedTweets {
+		switch ref.Type {
+		case "replied_to":
+			t.InReplyToID = ref.ID
+		case "quoted":
+			t.QuotedID = ref.ID
+		case "retweeted":
+			t.RetweetedID = ref.ID
+		}
+	}
+
+	for _, hashtag := range tweet.Entities.Hashtags {
+		t.Hashtags = append(t.Hashtags, hashtag.Tag)
+	}
+	for _, cashtag := range tweet.Entities.Cashtags {
+		t.Cashtags = append(t.Cashtags, cashtag.Tag)
+	}
+	for _, mention := range tweet.Entities.Mentions {
+		t.Mentions = append(t.Mentions, mention.Username)
+	}
+	for _, url := range tweet.Entities.URLs {
+		if url.ExpandedURL != "" {
+			t.URLs = append(t.URLs, url.ExpandedURL)
+		} else {
+			t.URLs = append(t.URLs, url.URL)
+		}
+	}
+	return t
+}
+
+// FromMasa converts a tweet of a Masa scrape. Masa does not extract cashtags,
+// so they are read from the text.
+func FromMasa(tweet masatwitter.Tweet) Tweet {
+	t := Tweet{
+		ID:             tweet.ID,
+		Text:           tweet.Text,
+		ConversationID: tweet.ConversationID,
+		CreatedAt:      tweet.TimeParsed,
+		Source:         SourceMasa,
+		Author: Author{
+			ID:       tweet.UserID,
+			Username: tweet.Username,
+			Name:     tweet.Name,
+		},
+		InReplyToID: tweet.InReplyToStatusID,
+		QuotedID:    tweet.QuotedStatusID,
+		RetweetedID: tweet.RetweetedStatusID,
+		Hashtags:    tweet.Hashtags,
+		URLs:        tweet.URLs,
+		Metrics: Metrics{
+			Likes:    tweet.Likes,
+			Retweets: tweet.Retweets,
+			Replies:  tweet.Replies,
+			Views:    tweet.Views,
+		},
+	}
+	if t.CreatedAt.IsZero() && tweet.Timestamp > 0 {
+		t.CreatedAt = time.Unix(tweet.Timestamp, 0).UTC()
+	}
+	if tweet.InReplyToStatus != nil {
+		t.InReplyToUserID = tweet.InReplyToStatus.UserID
+	}
+	for _, mention := range tweet.Mentions {
+		t.Mentions = append(t.Mentions, mention.Username)
+	}
+	for _, word := range strings.Fields(tweet.Text) {
+		if len(word) > 1 && word[0] == '$' {
+			t.Cashtags = append(t.Cashtags, strings.TrimRight(word[1:], ".,!?:;"))
+		}
+	}
+	return t
+}
+
+// FromMasaTweets converts the tweets of a Masa scrape
+func FromMasaTweets(tweets []masatwitter.Tweet) []Tweet {
+	converted := make([]Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		converted = append(converted, FromMasa(tweet))
+	}
+	return converted
+}
+
+// Twitter renders the tweet in the v2 API shape the tweet store persists.
+// Entity offsets are not known for every source and are left at zero.
+func (t Tweet) Twitter() twitter.Tweet {
+	tweet := twitter.Tweet{
+		ID:              t.ID,
+		Text:            t.Text,
+		AuthorID:        t.Author.ID,
+		ConversationID:  t.ConversationID,
+		InReplyToUserID: t.InReplyToUserID,
+		Lang:            t.Lang,
+	}
+	if !t.CreatedAt.IsZero() {
+		tweet.CreatedAt = t.CreatedAt.UTC().Format(time.RFC3339)
+	}
+
+	tweet.PublicMetrics.LikeCount = t.Metrics.Likes
+	tweet.PublicMetrics.RetweetCount = t.Metrics.Retweets
+	tweet.PublicMetrics.ReplyCount = t.Metrics.Replies
+	tweet.PublicMetrics.QuoteCount = t.Metrics.Quotes
+
+	for _, ref := range []twitter.ReferencedTweet{
+		{Type: "replied_to", ID: t.InReplyToID},
+		{Type: "quoted", ID: t.QuotedID},
+		{Type: "retweeted", ID: t.RetweetedID},
+	} {
+		if ref.ID != "" {
+			tweet.ReferencedTweets = append(tweet.ReferencedTweets, ref)
+		}
+	}
+
+	for _, tag := range t.Hashtags {
+		tweet.Entities.Hashtags = append(tweet.Entities.Hashtags, struct {
+			Start int    `json:"start"`
+			End   int    `json:"end"`
+			Tag   string `json:"tag"`
+		}{Tag: tag})
+	}
+	for _, tag := range t.Cashtags {
+		tweet.Entities.Cashtags = append(tweet.Entities.Cashtags, struct {
+			Start int    `json:"start"`
+			End   int    `json:"end"`
+			Tag   string `json:"tag"`
+		}{Tag: tag})
+	}
+	for _, username := range t.Mentions {
+		tweet.Entities.Mentions = append(tweet.Entities.Mentions, struct {
+			Start    int    `json:"start"`
+			End      int    `json:"end"`
+			Username string `json:"username"`
+			ID       string `json:"id"`
+		}{Username: username})
+	}
+	return tweet
+}
@@ -0,0 +1,83 @@
+// Package model holds the canonical shapes shared by the agent's layers.
+// Tweets arrive from the official v2 API, from Masa scrapes and from
+// Farcaster casts; converting them to model.Tweet first lets memory, thoughts
+// and analytics handle all of them the same way.
+package model
+
+import "time"
+
+// Source names where a tweet was retrieved from
+type Source string
+
+const (
+	// SourceTwitter is the official Twitter v2 API
+	SourceTwitter Source = "twitter"
+	// SourceMasa is a Masa protocol scrape
+	SourceMasa Source = "masa"
+)
+
+// Tweet is the canonical tweet. Fields a source does not provide are left at
+// their zero value.
+type Tweet struct {
+	ID             string    `json:"id"`
+	Text           string    `json:"text"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	Lang           string    `json:"lang,omitempty"`
+	Source         Source    `json:"source"`
+
+	Author Author `json:"author"`
+
+	// InReplyToID is the tweet this one replies to
+	InReplyToID string `json:"in_reply_to_id,omitempty"`
+	// InReplyToUserID is the author of the tweet this one replies to
+	InReplyToUserID string `json:"in_reply_to_user_id,omitempty"`
+	// QuotedID is the tweet this one quotes
+	QuotedID string `json:"quoted_id,omitempty"`
+	// RetweetedID is the tweet this one retweets
+	RetweetedID string `json:"retweeted_id,omitempty"`
+
+	// Hashtags and Cashtags are without their # and $ prefix
+	Hashtags []string `json:"hashtags,omitempty"`
+	Cashtags []string `json:"cashtags,omitempty"`
+	// Mentions are usernames without the @ prefix
+	Mentions []string `json:"mentions,omitempty"`
+	URLs     []string `json:"urls,omitempty"`
+
+	Metrics Metrics `json:"metrics"`
+}
+
+// Author is the user who posted a tweet
+type Author struct {
+	ID        string `json:"id"`
+	Username  string `json:"username,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Followers int    `json:"followers,omitempty"`
+	Verified  bool   `json:"verified,omitempty"`
+}
+
+// Metrics are a tweet's public engagement counts
+type Metrics struct {
+	Likes    int `json:"likes"`
+	Retweets int `json:"retweets"`
+	Replies  int `json:"replies"`
+	Quotes   int `json:"quotes"`
+	// Views is only reported by Masa scrapes
+	Views int `json:"views,omitempty"`
+}
+
+// Engagement weighs the metrics into one score; amplification through
+// retweets and quotes counts double
+func (m Metrics) Engagement() int {
+	return m.Likes + m.Replies + 2*(m.Retweets+m.Quotes)
+}
+
+// IsReply reports whether the tweet replies to another tweet
+func (t Tweet) IsReply() bool {
+	return t.InReplyToID != ""
+}
+
+// IsRetweet reports whether the tweet is a plain retweet
+func (t Tweet) IsRetweet() bool {
+	return t.RetweetedID != ""
+}
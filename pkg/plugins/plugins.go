@@ -0,0 +1,153 @@
+// Package plugins lets third parties add actions to the agent without forking
+// it. A plugin is either compiled into a custom build of the agent, by
+// calling Register from an init function, or built separately with
+// `go build -buildmode=plugin` and loaded from its .so file at startup.
+package plugins
+
+import (
+	"fmt"
+	"plugin"
+	"sort"
+	"sync"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Symbol is the function a plugin file exports to build its actions. Its
+// signature must be func(plugins.Host) ([]actions.Action, error). A plugin
+// file may instead call Register from an init function.
+const Symbol = "NewActions"
+
+// Host holds the services of the account a plugin builds actions for
+type Host struct {
+	// Account is the name of the account, empty when a single account runs
+	Account       string
+	Logger        *logrus.Logger
+	TwitterClient *twitter.TwitterClient
+	TweetStore    *memory.TweetStore
+	LLM           llms.Model
+	// Events receives agent activity; it may be nil
+	Events *events.Bus
+	// Settings are the plugin's own settings from the configuration
+	Settings map[string]string
+}
+
+// Factory builds the actions of a plugin for one account. It is called once
+// per account, and the names of the actions it returns must be unique.
+type Factory func(host Host) ([]actions.Action, error)
+
+// Spec selects a plugin to load
+type Spec struct {
+	// Name identifies the plugin; plugins registered in-process are looked up
+	// by it
+	Name string
+	// Path is the .so file of a plugin built with -buildmode=plugin; empty
+	// uses the registered plugin Name
+	Path string
+	// Settings are passed to the plugin in Host.Settings
+	Settings map[string]string
+}
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register makes a plugin available under name. It panics when name is empty
+// or already registered, as it is meant to be called from init functions.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if name == "" || factory == nil {
+		panic("plugins: Register needs a name and a factory")
+	}
+	if _, exists := factories[name]; exists {
+		panic(fmt.Sprintf("plugins: %s registered twice", name))
+	}
+	factories[name] = factory
+}
+
+// Registered returns the names of the registered plugins, sorted
+func Registered() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load builds the actions of the plugins in specs for the account of host
+func Load(specs []Spec, host Host) ([]actions.Action, error) {
+	var loaded []actions.Action
+	for _, spec := range specs {
+		factory, err := resolve(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		pluginHost := host
+		pluginHost.Settings = spec.Settings
+		if pluginHost.Settings == nil {
+			pluginHost.Settings = map[string]string{}
+		}
+		built, err := factory(pluginHost)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", spec.Name, err)
+		}
+		for _, action := range built {
+			if action == nil {
+				return nil, fmt.Errorf("plugin %s returned a nil action", spec.Name)
+			}
+		}
+		if host.Logger != nil {
+			host.Logger.WithFields(logrus.Fields{
+				"plugin":  spec.Name,
+				"actions": len(built),
+			}).Info("Loaded plugin")
+		}
+		loaded = append(loaded, built...)
+	}
+	return loaded, nil
+}
+
+// resolve returns the factory of spec, opening its plugin file if it has one
+func resolve(spec Spec) (Factory, error) {
+	if spec.Path != "" {
+		// Opening the same file again returns the already loaded plugin, so
+		// every account can load it
+		p, err := plugin.Open(spec.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open plugin %s: %w", spec.Name, err)
+		}
+		sym, err := p.Lookup(Symbol)
+		if err == nil {
+			switch fn := sym.(type) {
+			case func(Host) ([]actions.Action, error):
+				return fn, nil
+			case *Factory:
+				return *fn, nil
+			default:
+				return nil, fmt.Errorf("plugin %s: %s has type %T", spec.Name, Symbol, sym)
+			}
+		}
+		// Without the symbol, the plugin registered itself when opened
+	}
+
+	mu.RLock()
+	factory, ok := factories[spec.Name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown plugin %s", spec.Name)
+	}
+	return factory, nil
+}
@@ -0,0 +1,223 @@
+// Package preflight runs the startup checks that catch broken credentials and
+// unreachable dependencies before the agent starts spending rate limits.
+// Checks run concurrently, each with its own timeout, and their outcomes are
+// collected in a Report that can be printed or logged.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTimeout bounds each check when Options.Timeout is not set
+const DefaultTimeout = 15 * time.Second
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	// StatusOK means the check passed
+	StatusOK Status = "ok"
+	// StatusFailed means the check failed
+	StatusFailed Status = "failed"
+	// StatusWarning means an optional check failed
+	StatusWarning Status = "warning"
+	// StatusSkipped means the check does not apply to the configuration
+	StatusSkipped Status = "skipped"
+)
+
+// skipError marks a check that does not apply
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// Skip returns an error that reports a check as skipped for reason
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// Check is a single preflight check
+type Check struct {
+	// Name identifies the check in the report, e.g. "database" or "twitter:main"
+	Name string
+	// Optional checks report failures as warnings, which do not fail the report
+	Optional bool
+	// Run performs the check and returns a short detail for the report, such
+	// as the resolved user ID. Returning Skip(reason) marks it skipped.
+	Run func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// Err is the error of a failed check, for callers classifying failures
+	Err error `json:"-"`
+}
+
+// Report holds the results of a preflight run, in the order of the checks
+type Report struct {
+	Results  []Result      `json:"results"`
+	Duration time.Duration `json:"duration"`
+}
+
+// OK reports whether no required check failed
+func (r *Report) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the results of the required checks that failed
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if result.Status == StatusFailed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of the failed checks, or returns nil
+func (r *Report) Err() error {
+	var errs []error
+	for _, result := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %s", result.Name, result.Error))
+	}
+	return errors.Join(errs...)
+}
+
+// Names returns the sorted names of results with the given status
+func (r *Report) Names(status Status) []string {
+	var names []string
+	for _, result := range r.Results {
+		if result.Status == status {
+			names = append(names, result.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Print writes the report as a table
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tTIME\tDETAIL")
+	for _, result := range r.Results {
+		detail := result.Detail
+		if result.Error != "" {
+			detail = result.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Name, strings.ToUpper(string(result.Status)),
+			result.Duration.Round(time.Millisecond), detail)
+	}
+	return tw.Flush()
+}
+
+// Log writes one entry per result, at warning level for failures
+func (r *Report) Log(logger *logrus.Logger) {
+	for _, result := range r.Results {
+		entry := logger.WithFields(logrus.Fields{
+			"check":    result.Name,
+			"status":   result.Status,
+			"duration": result.Duration.Round(time.Millisecond).String(),
+		})
+		switch result.Status {
+		case StatusFailed, StatusWarning:
+			entry.WithField("error", result.Error).Warn("Preflight check failed")
+		default:
+			entry.WithField("detail", result.Detail).Debug("Preflight check finished")
+		}
+	}
+}
+
+// Options configures Run
+type Options struct {
+	// Timeout bounds each check; defaults to DefaultTimeout
+	Timeout time.Duration
+}
+
+// Run performs the checks concurrently and returns their results in order
+func Run(ctx context.Context, checks []Check, opts Options) *Report {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	start := time.Now()
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check, opts.Timeout)
+		}(i, check)
+	}
+	wg.Wait()
+
+	return &Report{
+		Results:  results,
+		Duration: time.Since(start),
+	}
+}
+
+// run performs one check, turning panics into failures so that one broken
+// check cannot take the others down
+func run(ctx context.Context, check Check, timeout time.Duration) (result Result) {
+	result.Name = check.Name
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Status = failedStatus(check)
+			result.Error = fmt.Sprintf("check panicked: %v", recovered)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	detail, err := check.Run(ctx)
+	var skip *skipError
+	switch {
+	case errors.As(err, &skip):
+		result.Status = StatusSkipped
+		result.Detail = skip.reason
+	case err != nil:
+		result.Status = failedStatus(check)
+		result.Error = err.Error()
+		result.Err = err
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Error = fmt.Sprintf("timed out after %s: %s", timeout, err)
+		}
+	default:
+		result.Status = StatusOK
+		result.Detail = detail
+	}
+	return result
+}
+
+// failedStatus is the status of a failed check
+func failedStatus(check Check) Status {
+	if check.Optional {
+		return StatusWarning
+	}
+	return StatusFailed
+}
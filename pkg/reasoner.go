@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/tools"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// DefaultMaxIterations bounds how many tool-calling rounds a single Reason call may take
+const DefaultMaxIterations = 5
+
+// ErrMaxIterations is returned when the model keeps calling tools without producing an answer
+var ErrMaxIterations = errors.New("reasoning loop exceeded max iterations")
+
+// ReasonerConfig configures the tool-calling reasoning loop
+type ReasonerConfig struct {
+	LLM           llms.Model
+	Tools         *tools.Registry
+	Logger        *logrus.Logger
+	SystemPrompt  string
+	MaxIterations int
+	Temperature   float64
+}
+
+// Reasoner runs an LLM loop in which the model can call registered tools
+// before producing its final answer
+type Reasoner struct {
+	llm    llms.Model
+	tools  *tools.Registry
+	logger *logrus.Logger
+	config ReasonerConfig
+}
+
+// NewReasoner creates a new reasoning loop
+func NewReasoner(config ReasonerConfig) (*Reasoner, error) {
+	if config.LLM == nil {
+		return nil, fmt.Errorf("LLM is required")
+	}
+	if config.Tools == nil {
+		return nil, fmt.Errorf("tool registry is required")
+	}
+	if config.Logger == nil {
+		config.Logger = logrus.New()
+	}
+	if config.MaxIterations <= 0 {
+		config.MaxIterations = DefaultMaxIterations
+	}
+
+	return &Reasoner{
+		llm:    config.LLM,
+		tools:  config.Tools,
+		logger: config.Logger,
+		config: config,
+	}, nil
+}
+
+// Reason answers the input, letting the model call tools until it responds with plain
+// content. Tool failures are reported back to the model rather than aborting the loop.
+func (r *Reasoner) Reason(ctx context.Context, input string) (string, error) {
+	log := r.logger.WithField("method", "Reason")
+
+	var messages []llms.MessageContent
+	if r.config.SystemPrompt != "" {
+		messages = append(messages, llms.TextParts(llms.ChatMessageTypeSystem, r.config.SystemPrompt))
+	}
+	messages = append(messages, llms.TextParts(llms.ChatMessageTypeHuman, input))
+
+	definitions := r.tools.Definitions()
+
+	for iteration := 1; iteration <= r.config.MaxIterations; iteration++ {
+		resp, err := r.llm.GenerateContent(ctx, messages,
+			llms.WithTools(definitions),
+			llms.WithTemperature(r.config.Temperature),
+		)
+		if err != nil {
+			return "", fmt.Errorf("error generating content: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("model returned no choices")
+		}
+
+		choice := resp.Choices[0]
+		calls := functionCalls(choice.ToolCalls)
+		if len(calls) == 0 {
+			log.WithField("iterations", iteration).Debug("Reasoning loop completed")
+			return choice.Content, nil
+		}
+
+		// Echo the assistant's tool calls so the responses can reference them
+		assistant := llms.MessageContent{Role: llms.ChatMessageTypeAI}
+		for _, call := range calls {
+			assistant.Parts = append(assistant.Parts, call)
+		}
+		messages = append(messages, assistant)
+
+		for _, call := range calls {
+			toolLog := log.WithFields(logrus.Fields{
+				"iteration": iteration,
+				"tool":      call.FunctionCall.Name,
+				"arguments": call.FunctionCall.Arguments,
+			})
+			toolLog.Debug("Invoking tool")
+
+			content, err := r.tools.Invoke(ctx, call.FunctionCall.Name, call.FunctionCall.Arguments)
+			if err != nil {
+				toolLog.WithError(err).Warn("Tool call failed")
+				content = fmt.Sprintf("error: %v", err)
+			}
+
+			messages = append(messages, llms.MessageContent{
+				Role: llms.ChatMessageTypeTool,
+				Parts: []llms.ContentPart{
+					llms.ToolCallResponse{
+						ToolCallID: call.ID,
+						Name:       call.FunctionCall.Name,
+						Content:    content,
+					},
+				},
+			})
+		}
+	}
+
+	return "", fmt.Errorf("%w (%d)", ErrMaxIterations, r.config.MaxIterations)
+}
+
+// functionCalls returns the tool calls that name a function. Others cannot be
+// answered, so they are neither echoed nor given a response: the API rejects
+// an echoed call without one.
+func functionCalls(calls []llms.ToolCall) []llms.ToolCall {
+	var named []llms.ToolCall
+	for _, call := range calls {
+		if call.FunctionCall != nil {
+			named = append(named, call)
+		}
+	}
+	return named
+}
@@ -0,0 +1,195 @@
+// Package schedule decides when the agent may post. A Policy combines daily
+// quiet hours in the operator's timezone with one-off embargo periods; posts
+// that come due while posting is closed wait until it opens again.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxSteps bounds the search for the next open time, so overlapping quiet
+// hours and embargoes cannot loop forever
+const maxSteps = 64
+
+// quietHours is a daily range in minutes after local midnight. Ranges whose
+// end is before their start run past midnight.
+type quietHours struct {
+	start, end int
+}
+
+// contains reports whether the minute of day falls within the range
+func (q quietHours) contains(minute int) bool {
+	if q.start < q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+// embargo is a one-off period without posts
+type embargo struct {
+	start, end time.Time
+}
+
+// Policy decides when posting is allowed. A nil Policy always allows it.
+type Policy struct {
+	location  *time.Location
+	quiet     []quietHours
+	embargoes []embargo
+}
+
+// New creates a posting policy. timezone is an IANA name such as
+// "America/New_York" and defaults to UTC. quietHours are daily "HH:MM-HH:MM"
+// ranges in that timezone, such as "23:00-07:00". embargoes are RFC 3339
+// "start/end" periods. New returns nil when there are no quiet hours or
+// embargoes.
+func New(timezone string, quiet, embargoes []string) (*Policy, error) {
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+
+	p := &Policy{location: location}
+	for _, raw := range quiet {
+		hours, err := parseQuietHours(raw)
+		if err != nil {
+			return nil, err
+		}
+		p.quiet = append(p.quiet, hours)
+	}
+	for _, raw := range embargoes {
+		period, err := parseEmbargo(raw)
+		if err != nil {
+			return nil, err
+		}
+		p.embargoes = append(p.embargoes, period)
+	}
+
+	if len(p.quiet) == 0 && len(p.embargoes) == 0 {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// parseQuietHours parses an "HH:MM-HH:MM" range
+func parseQuietHours(raw string) (quietHours, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(raw), "-")
+	if !ok {
+		return quietHours{}, fmt.Errorf("invalid quiet hours %q: want HH:MM-HH:MM", raw)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return quietHours{}, fmt.Errorf("invalid quiet hours %q: %w", raw, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return quietHours{}, fmt.Errorf("invalid quiet hours %q: %w", raw, err)
+	}
+	if start == end {
+		return quietHours{}, fmt.Errorf("invalid quiet hours %q: start and end are equal", raw)
+	}
+	return quietHours{start: start, end: end}, nil
+}
+
+// parseClock parses an "HH:MM" time of day into minutes after midnight
+func parseClock(raw string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", raw)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseEmbargo parses an RFC 3339 "start/end" period
+func parseEmbargo(raw string) (embargo, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(raw), "/")
+	if !ok {
+		return embargo{}, fmt.Errorf("invalid embargo %q: want start/end in RFC 3339", raw)
+	}
+	start, err := time.Parse(time.RFC3339, strings.TrimSpace(from))
+	if err != nil {
+		return embargo{}, fmt.Errorf("invalid embargo %q: %w", raw, err)
+	}
+	end, err := time.Parse(time.RFC3339, strings.TrimSpace(to))
+	if err != nil {
+		return embargo{}, fmt.Errorf("invalid embargo %q: %w", raw, err)
+	}
+	if !end.After(start) {
+		return embargo{}, fmt.Errorf("invalid embargo %q: end is not after start", raw)
+	}
+	return embargo{start: start, end: end}, nil
+}
+
+// Open reports whether posting is allowed at t
+func (p *Policy) Open(t time.Time) bool {
+	return p.NextOpen(t).Equal(t)
+}
+
+// NextOpen returns the earliest time at or after t when posting is allowed
+func (p *Policy) NextOpen(t time.Time) time.Time {
+	if p == nil {
+		return t
+	}
+
+	for step := 0; step < maxSteps; step++ {
+		closedUntil, closed := p.closedUntil(t)
+		if !closed {
+			return t
+		}
+		t = closedUntil
+	}
+	return t
+}
+
+// closedUntil returns the end of the quiet hours or embargo t falls in
+func (p *Policy) closedUntil(t time.Time) (time.Time, bool) {
+	for _, period := range p.embargoes {
+		if !t.Before(period.start) && t.Before(period.end) {
+			return period.end, true
+		}
+	}
+
+	local := t.In(p.location)
+	minute := local.Hour()*60 + local.Minute()
+	for _, hours := range p.quiet {
+		if !hours.contains(minute) {
+			continue
+		}
+		// The evening part of a range past midnight ends tomorrow
+		day := local.Day()
+		if minute >= hours.end {
+			day++
+		}
+		end := time.Date(local.Year(), local.Month(), day, hours.end/60, hours.end%60, 0, 0, p.location)
+		if !end.After(t) {
+			// Daylight saving moved the end behind t; move on by a minute
+			end = t.Add(time.Minute)
+		}
+		return end, true
+	}
+	return time.Time{}, false
+}
+
+// Wait blocks until posting is allowed, returning at once when it already is
+func (p *Policy) Wait(ctx context.Context) error {
+	for {
+		now := time.Now()
+		delay := p.NextOpen(now).Sub(now)
+		if delay <= 0 {
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
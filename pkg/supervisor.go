@@ -0,0 +1,388 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// RestartPolicy decides whether a task is restarted after it exits
+type RestartPolicy string
+
+const (
+	// RestartAlways restarts the task whenever it exits
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure restarts the task only when it exits with an error
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartNever leaves the task stopped once it exits
+	RestartNever RestartPolicy = "never"
+)
+
+// TaskPolicy is how the supervisor restarts one task
+type TaskPolicy struct {
+	Restart RestartPolicy
+	// MaxRestarts is how many restarts are attempted before the task is given
+	// up on; negative means no limit
+	MaxRestarts int
+	// Backoff is the delay before the first restart; it doubles with every
+	// consecutive restart up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultTaskPolicy restarts failed tasks up to ten times, waiting from five
+// seconds up to five minutes between attempts
+func DefaultTaskPolicy() TaskPolicy {
+	return TaskPolicy{
+		Restart:     RestartOnFailure,
+		MaxRestarts: 10,
+		Backoff:     5 * time.Second,
+		MaxBackoff:  5 * time.Minute,
+	}
+}
+
+// TaskPolicyFrom converts task settings into a policy, using the defaults for
+// anything left unset
+func TaskPolicyFrom(settings config.TaskConfig) TaskPolicy {
+	policy := DefaultTaskPolicy()
+	if settings.Restart != "" {
+		policy.Restart = RestartPolicy(settings.Restart)
+	}
+	if settings.MaxRestarts != 0 {
+		policy.MaxRestarts = settings.MaxRestarts
+	}
+	if settings.Backoff > 0 {
+		policy.Backoff = settings.Backoff
+	}
+	if settings.MaxBackoff > 0 {
+		policy.MaxBackoff = settings.MaxBackoff
+	}
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = policy.Backoff
+	}
+	return policy
+}
+
+// restarts reports whether a task that exited with err should run again
+func (p TaskPolicy) restarts(err error) bool {
+	switch p.Restart {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// TaskState is where a supervised task is in its lifecycle
+type TaskState string
+
+const (
+	TaskPending TaskState = "pending"
+	TaskRunning TaskState = "running"
+	// TaskBackoff is waiting to be restarted after exiting
+	TaskBackoff TaskState = "backoff"
+	// TaskExited ended without an error and is not restarted
+	TaskExited TaskState = "exited"
+	// TaskFailed ended with an error and is not restarted
+	TaskFailed TaskState = "failed"
+	// TaskStopped was stopped by the agent shutting down
+	TaskStopped TaskState = "stopped"
+	// TaskDisabled is switched off by configuration or DisableTask
+	TaskDisabled TaskState = "disabled"
+)
+
+// ErrTaskNotFound is returned when enabling or disabling an unknown task
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskHealth is a snapshot of a supervised task
+type TaskHealth struct {
+	Name        string        `json:"name"`
+	State       TaskState     `json:"state"`
+	Policy      RestartPolicy `json:"restart_policy"`
+	Restarts    int           `json:"restarts"`
+	MaxRestarts int           `json:"max_restarts"`
+	LastError   string        `json:"last_error,omitempty"`
+	StartedAt   time.Time     `json:"started_at"`
+	ExitedAt    time.Time     `json:"exited_at"`
+	// NextRestart is when a task in backoff runs again
+	NextRestart time.Time `json:"next_restart"`
+}
+
+// Healthy reports whether the task is running
+func (h TaskHealth) Healthy() bool {
+	return h.State == TaskRunning
+}
+
+// task is an action under supervision
+type task struct {
+	action actions.Action
+
+	// policy, enabled, cancel and done are guarded by the agent's lock.
+	// cancel and done are set while the task has a supervisor goroutine.
+	policy  TaskPolicy
+	enabled bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu     sync.Mutex
+	health TaskHealth
+}
+
+func newTask(action actions.Action, policy TaskPolicy) *task {
+	return &task{
+		action:  action,
+		policy:  policy,
+		enabled: true,
+		health: TaskHealth{
+			Name:        action.Name(),
+			State:       TaskPending,
+			Policy:      policy.Restart,
+			MaxRestarts: policy.MaxRestarts,
+		},
+	}
+}
+
+// snapshot returns the task's current health
+func (t *task) snapshot() TaskHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.health
+}
+
+// update changes the task's health under its lock
+func (t *task) update(fn func(health *TaskHealth)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.health)
+}
+
+// startTask starts a supervisor goroutine for the task. The agent's lock must
+// be held and Run must have been called.
+func (a *Agent) startTask(t *task) {
+	ctx, cancel := context.WithCancel(a.runCtx)
+	done := make(chan struct{})
+	t.cancel, t.done = cancel, done
+	a.running++
+	policy := t.policy
+
+	go func() {
+		defer close(done)
+		err := a.supervise(ctx, t, policy)
+		if err != nil {
+			a.logger.WithError(err).WithField("action", t.health.Name).Error("Action will not be restarted")
+		}
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.running--
+		if ctx.Err() != nil {
+			// Stopped by DisableTask or shutdown, not ended by itself
+			return
+		}
+		t.cancel, t.done = nil, nil
+		cancel()
+		if err != nil {
+			a.failures = append(a.failures, err)
+		}
+		if a.running == 0 {
+			select {
+			case a.idle <- struct{}{}:
+			default:
+			}
+		}
+	}()
+}
+
+// EnableTask starts a task that is disabled or was given up on. The task
+// settings are re-read first, so a changed interval or restart policy applies.
+// Enabling a running task does nothing.
+func (a *Agent) EnableTask(name string) error {
+	var settings config.TasksConfig
+	if a.reload != nil {
+		reloaded, err := a.reload()
+		if err != nil {
+			return fmt.Errorf("failed to reload task settings: %w", err)
+		}
+		settings = reloaded
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	t, ok := a.tasks[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	if a.reload != nil {
+		a.policies = settings
+	}
+	t.enabled = true
+	if t.cancel != nil {
+		return nil
+	}
+
+	taskSettings := a.policies.For(name)
+	if setter, ok := t.action.(actions.IntervalSetter); ok && taskSettings.Interval > 0 {
+		setter.SetInterval(taskSettings.Interval)
+	}
+	t.policy = TaskPolicyFrom(taskSettings)
+	t.update(func(h *TaskHealth) {
+		h.State = TaskPending
+		h.Policy = t.policy.Restart
+		h.MaxRestarts = t.policy.MaxRestarts
+		h.Restarts = 0
+		h.NextRestart = time.Time{}
+	})
+
+	if a.runCtx == nil {
+		// Run starts it with the other enabled tasks
+		return nil
+	}
+	a.logger.WithField("action", name).Info("Enabling action")
+	a.startTask(t)
+	return nil
+}
+
+// DisableTask stops a task and keeps it from being restarted until it is
+// enabled again. It waits for the task to return.
+func (a *Agent) DisableTask(name string) error {
+	a.mu.Lock()
+	t, ok := a.tasks[name]
+	if !ok {
+		a.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	t.enabled = false
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	a.mu.Unlock()
+
+	if cancel != nil {
+		a.logger.WithField("action", name).Info("Disabling action")
+		cancel()
+		<-done
+	}
+	t.update(func(h *TaskHealth) {
+		h.State = TaskDisabled
+		h.NextRestart = time.Time{}
+	})
+	return nil
+}
+
+// supervise runs the task until the context is cancelled or its policy stops
+// restarting it. It returns the error the task was given up with, if any.
+func (a *Agent) supervise(ctx context.Context, t *task, policy TaskPolicy) error {
+	log := a.logger.WithField("action", t.health.Name)
+	backoff := policy.Backoff
+
+	for {
+		started := time.Now()
+		t.update(func(h *TaskHealth) {
+			h.State = TaskRunning
+			h.StartedAt = started
+			h.NextRestart = time.Time{}
+		})
+
+		// Each run is traced, so its entries can be told from earlier runs
+		runCtx := logging.StartTrace(ctx)
+		runLog := log.WithContext(runCtx)
+		runLog.Info("Starting action")
+		err := runAction(runCtx, t.action)
+		exited := time.Now()
+
+		if ctx.Err() != nil {
+			t.update(func(h *TaskHealth) {
+				h.State = TaskStopped
+				h.ExitedAt = exited
+			})
+			return nil
+		}
+
+		t.update(func(h *TaskHealth) {
+			h.ExitedAt = exited
+			if err != nil {
+				h.LastError = err.Error()
+			}
+		})
+		if err != nil {
+			runLog.WithError(err).Error("Action failed")
+		} else {
+			runLog.Warn("Action exited")
+		}
+
+		if !policy.restarts(err) {
+			return t.giveUp(err, "restart policy is "+string(policy.Restart))
+		}
+		if restarts := t.snapshot().Restarts; policy.MaxRestarts >= 0 && restarts >= policy.MaxRestarts {
+			return t.giveUp(err, fmt.Sprintf("restarted %d times", restarts))
+		}
+
+		// A task that ran for a while before exiting starts its backoff over
+		if exited.Sub(started) > policy.MaxBackoff {
+			backoff = policy.Backoff
+		}
+
+		next := exited.Add(backoff)
+		t.update(func(h *TaskHealth) {
+			h.State = TaskBackoff
+			h.NextRestart = next
+		})
+		runLog.WithFields(logrus.Fields{
+			"backoff":  backoff,
+			"restarts": t.snapshot().Restarts,
+		}).Warn("Restarting action after backoff")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			t.update(func(h *TaskHealth) {
+				h.State = TaskStopped
+				h.NextRestart = time.Time{}
+			})
+			return nil
+		case <-timer.C:
+		}
+
+		t.update(func(h *TaskHealth) { h.Restarts++ })
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// giveUp marks the task as no longer restarted
+func (t *task) giveUp(err error, reason string) error {
+	t.update(func(h *TaskHealth) {
+		if err != nil {
+			h.State = TaskFailed
+		} else {
+			h.State = TaskExited
+		}
+	})
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("action %s failed (%s): %w", t.health.Name, reason, err)
+}
+
+// runAction executes an action, turning a panic into an error so that it is
+// handled by the restart policy instead of crashing the process
+func runAction(ctx context.Context, action actions.Action) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("action panicked: %v", r)
+		}
+	}()
+	return action.Execute(ctx)
+}
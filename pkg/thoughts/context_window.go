@@ -0,0 +1,142 @@
+package thoughts
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultContextTokens is the token budget of a conversation's context
+	// when none is configured
+	DefaultContextTokens = 1500
+
+	// defaultEncoding tokenizes text for models tiktoken doesn't know
+	defaultEncoding = "cl100k_base"
+	// charsPerToken approximates token counts when no encoding can be loaded
+	charsPerToken = 4
+	// contextHeader opens every conversation context
+	contextHeader = "Previous conversation:\n"
+)
+
+// ContextPost is an earlier post of the conversation being answered
+type ContextPost struct {
+	AuthorUsername string
+	AuthorName     string
+	Text           string
+}
+
+// line renders the post as one line of the context
+func (p ContextPost) line() string {
+	return fmt.Sprintf("@%s (%s): %s\n", p.AuthorUsername, p.AuthorName, p.Text)
+}
+
+// ContextBuilder renders the earlier posts of a conversation as prompt context
+// that fits a token budget. It keeps the post that started the conversation,
+// since it sets the topic, and then as many of the most recent posts as fit,
+// noting in the context how many were left out.
+type ContextBuilder struct {
+	model  string
+	budget int
+
+	once     sync.Once
+	encoding *tiktoken.Tiktoken
+}
+
+// NewContextBuilder creates a context builder counting tokens the way model
+// does. A budget of 0 or less uses DefaultContextTokens.
+func NewContextBuilder(model string, budget int) *ContextBuilder {
+	if budget <= 0 {
+		budget = DefaultContextTokens
+	}
+	return &ContextBuilder{model: model, budget: budget}
+}
+
+// Budget returns the most tokens a built context uses
+func (b *ContextBuilder) Budget() int {
+	return b.budget
+}
+
+// CountTokens returns the number of tokens in text. The encoding is loaded on
+// first use; when it can't be loaded, tokens are estimated from the length.
+func (b *ContextBuilder) CountTokens(text string) int {
+	b.once.Do(b.loadEncoding)
+	if b.encoding == nil {
+		return (len([]rune(text)) + charsPerToken - 1) / charsPerToken
+	}
+	return len(b.encoding.EncodeOrdinary(text))
+}
+
+// loadEncoding loads the model's tiktoken encoding, or the default one for
+// models tiktoken doesn't know
+func (b *ContextBuilder) loadEncoding() {
+	encoding, err := tiktoken.EncodingForModel(b.model)
+	if err != nil {
+		encoding, err = tiktoken.GetEncoding(defaultEncoding)
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("model", b.model).Warn("Failed to load token encoding, estimating context tokens from length")
+		return
+	}
+	b.encoding = encoding
+}
+
+// Build renders posts, oldest first, as conversation context within the budget
+func (b *ContextBuilder) Build(posts []ContextPost) string {
+	lines := make([]string, len(posts))
+	tokens := make([]int, len(posts))
+	total := b.CountTokens(contextHeader)
+	for i, post := range posts {
+		lines[i] = post.line()
+		tokens[i] = b.CountTokens(lines[i])
+		total += tokens[i]
+	}
+
+	var context strings.Builder
+	context.WriteString(contextHeader)
+	if total <= b.budget {
+		for _, line := range lines {
+			context.WriteString(line)
+		}
+		return context.String()
+	}
+
+	// Leave room for the header and the note on omitted posts
+	remaining := b.budget - b.CountTokens(contextHeader) - b.CountTokens(omittedNote(len(posts)))
+
+	keepRoot := len(posts) > 1 && tokens[0] <= remaining/2
+	first := 0
+	if keepRoot {
+		remaining -= tokens[0]
+		first = 1
+	}
+
+	// Walk back from the newest post while the posts fit
+	start := len(posts)
+	for start > first && tokens[start-1] <= remaining {
+		remaining -= tokens[start-1]
+		start--
+	}
+
+	if keepRoot {
+		context.WriteString(lines[0])
+	}
+	if omitted := start - first; omitted > 0 {
+		context.WriteString(omittedNote(omitted))
+	}
+	for _, line := range lines[start:] {
+		context.WriteString(line)
+	}
+	return context.String()
+}
+
+// omittedNote tells the model that part of the conversation was left out
+func omittedNote(omitted int) string {
+	if omitted == 1 {
+		return "[1 post omitted to fit the context limit]\n"
+	}
+	return fmt.Sprintf("[%d posts omitted to fit the context limit]\n", omitted)
+}
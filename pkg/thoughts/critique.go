@@ -0,0 +1,173 @@
+package thoughts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	langchainprompts "github.com/tmc/langchaingo/prompts"
+)
+
+// critiqueTemperature keeps the critique focused on the listed checks
+const critiqueTemperature = 0.2
+
+// approvedVerdict starts a critique that found nothing to fix
+const approvedVerdict = "APPROVED"
+
+// ReplyReview holds the stages of a self-critiqued reply
+type ReplyReview struct {
+	Draft    string `json:"draft"`
+	Critique string `json:"critique"`
+	// Revision is the draft rewritten after the critique; empty when the
+	// critique approved the draft
+	Revision string `json:"revision,omitempty"`
+}
+
+// Final returns the reply to post: the revision, or the draft when the
+// critique approved it
+func (r ReplyReview) Final() string {
+	if r.Revision != "" {
+		return r.Revision
+	}
+	return r.Draft
+}
+
+// ReplyCritic checks a drafted reply and revises it once when the check
+// finds problems
+type ReplyCritic interface {
+	Review(ctx context.Context, config MentionReplyConfig, draft string) (ReplyReview, error)
+}
+
+// DefaultReplyCritic critiques a draft with one LLM call and revises it with
+// another when needed
+type DefaultReplyCritic struct {
+	llm llms.Model
+}
+
+// NewReplyCritic creates a new reply critic
+func NewReplyCritic(llm llms.Model) ReplyCritic {
+	return &DefaultReplyCritic{
+		llm: llm,
+	}
+}
+
+// Review asks whether the draft is in character, within the length limit and
+// not repetitive, then revises it once unless the critique approves it
+func (c *DefaultReplyCritic) Review(ctx context.Context, config MentionReplyConfig, draft string) (ReplyReview, error) {
+	review := ReplyReview{Draft: draft}
+
+	// The model cannot count characters or recall every earlier reply, so
+	// the measurable checks are done here and handed to it as findings
+	var findings []string
+	if length := WeightedLength(draft); config.MaxLength > 0 && length > config.MaxLength {
+		findings = append(findings, fmt.Sprintf("The draft is %d characters, over the limit of %d.", length, config.MaxLength))
+	}
+	if failed, similarity := MostSimilar(draft, config.Avoid); similarity >= DefaultSimilarityThreshold {
+		findings = append(findings, fmt.Sprintf("The draft nearly repeats this reply of yours that fell flat: %q", failed))
+	}
+
+	data := map[string]any{
+		"personality": formatPersonality(config),
+		"tweet":       config.TweetText,
+		"context":     config.ConversationContext,
+		"draft":       draft,
+		"maxLength":   config.MaxLength,
+		"findings":    strings.Join(findings, "\n"),
+		"approved":    approvedVerdict,
+		"notes":       formatNotes(config.Notes),
+		"facts":       config.Facts,
+	}
+	prompt, err := langchainprompts.NewPromptTemplate(
+		critiquePrompt,
+		[]string{"personality", "tweet", "context", "draft", "maxLength", "findings", "approved", "notes"},
+	).Format(data)
+	if err != nil {
+		return review, fmt.Errorf("error formatting critique prompt: %w", err)
+	}
+
+	critique, err := llms.GenerateFromSinglePrompt(ctx, c.llm, prompt, llms.WithTemperature(critiqueTemperature))
+	if err != nil {
+		return review, fmt.Errorf("error generating critique: %w", err)
+	}
+	review.Critique = strings.TrimSpace(critique)
+	if review.Critique == "" {
+		return review, fmt.Errorf("critique is empty")
+	}
+	if len(findings) == 0 && strings.HasPrefix(strings.ToUpper(review.Critique), approvedVerdict) {
+		return review, nil
+	}
+
+	data["critique"] = review.Critique
+	prompt, err = langchainprompts.NewPromptTemplate(
+		revisePrompt,
+		[]string{"personality", "tweet", "context", "draft", "maxLength", "critique", "notes", "facts"},
+	).Format(data)
+	if err != nil {
+		return review, fmt.Errorf("error formatting revision prompt: %w", err)
+	}
+
+	revision, err := generateWithinLength(ctx, c.llm, prompt, config.MaxLength, config.LengthRetries,
+		llms.WithTemperature(config.Temperature),
+		llms.WithMaxTokens(config.MaxLength),
+	)
+	if err != nil {
+		return review, fmt.Errorf("error revising reply: %w", err)
+	}
+	if revision == "" {
+		return review, fmt.Errorf("revision is empty")
+	}
+	review.Revision = revision
+	return review, nil
+}
+
+// critiquePrompt asks for the problems of a drafted reply
+const critiquePrompt = `You review the replies of a social media persona before they are posted. Here is the persona:
+
+{{.personality}}
+{{if .context}}
+CONVERSATION CONTEXT:
+{{.context}}
+{{end}}
+Tweet being answered: {{.tweet}}
+
+Drafted reply: {{.draft}}
+{{if .notes}}
+Operator notes for this conversation, the reply must follow them:
+{{.notes}}
+{{end}}
+Check the draft:
+1. Is it in character?
+2. Is it under {{.maxLength}} characters?
+3. Is it fresh, not repeating earlier replies in the conversation or itself?
+4. Does it answer the tweet?
+{{if .findings}}
+Problems already found:
+{{.findings}}
+{{end}}
+If the draft needs no change, answer with {{.approved}} only. Otherwise list each problem and how to fix it, one per line.`
+
+// revisePrompt asks for the drafted reply rewritten after its critique
+const revisePrompt = `You are a social media persona revising a reply before posting it. Here is your personality:
+
+{{.personality}}
+{{if .context}}
+CONVERSATION CONTEXT:
+{{.context}}
+{{end}}
+Tweet to respond to: {{.tweet}}
+
+Your draft: {{.draft}}
+
+Problems with the draft:
+{{.critique}}
+{{if .facts}}
+Facts you looked up, quote these exact figures and never make up others:
+{{.facts}}
+{{end}}{{if .notes}}
+Operator notes for this conversation, always follow them:
+{{.notes}}
+{{end}}
+Rewrite the reply to fix these problems. It MUST be under {{.maxLength}} characters. Answer with the reply only.
+
+Your reply:`
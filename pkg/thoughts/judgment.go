@@ -0,0 +1,266 @@
+package thoughts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	langchainprompts "github.com/tmc/langchaingo/prompts"
+)
+
+const (
+	// MaxJudgmentScore is the top of the 0-10 rating scale
+	MaxJudgmentScore = 10
+
+	// DefaultJudgmentRetries is how many times an unparseable or invalid
+	// judgment is regenerated before giving up
+	DefaultJudgmentRetries = 2
+)
+
+// DefaultJudgmentCategories are the roast ratings of the Judgment Throne
+var DefaultJudgmentCategories = []string{
+	"Bio Cringe Factor",
+	"Main Character Energy",
+	"Try-Hard Level",
+	"Timeline Tragedy",
+}
+
+// JudgmentConfig holds configuration for rating a user
+type JudgmentConfig struct {
+	// SubjectUsername is the handle of the user being judged
+	SubjectUsername string
+	// Material is what the judgment is based on, e.g. the bio and recent tweets
+	Material string
+	// Categories are the ratings to give; DefaultJudgmentCategories when empty
+	Categories []string
+	// MaxVerdictLength bounds the verdict text in weighted characters; 0 means no limit
+	MaxVerdictLength int
+	Temperature      float64
+	Personality      map[string]string // Optional: will use DefaultReplyPersonality if nil
+	// Retries is how many times an invalid answer is regenerated; 0 uses
+	// DefaultJudgmentRetries and a negative value disables retries
+	Retries int
+}
+
+// Judgment is a validated rating: a 0-10 score per category and a verdict
+type Judgment struct {
+	// Categories lists the rated categories in the order they were requested
+	Categories []string
+	Scores     map[string]int
+	Verdict    string
+}
+
+// Overall returns the mean score across categories
+func (j *Judgment) Overall() float64 {
+	if len(j.Scores) == 0 {
+		return 0
+	}
+	var total int
+	for _, score := range j.Scores {
+		total += score
+	}
+	return float64(total) / float64(len(j.Scores))
+}
+
+// ScoreLines renders the scores one per line, e.g. "Bio Cringe Factor: 7/10"
+func (j *Judgment) ScoreLines() string {
+	lines := make([]string, 0, len(j.Categories))
+	for _, category := range j.Categories {
+		lines = append(lines, fmt.Sprintf("%s: %d/%d", category, j.Scores[category], MaxJudgmentScore))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JudgmentGenerator rates users with structured scores
+type JudgmentGenerator interface {
+	Judge(ctx context.Context, config JudgmentConfig) (*Judgment, error)
+}
+
+// StructuredJudgment asks the LLM for a JSON judgment matching a schema and
+// validates it, regenerating with the validation error when it does not parse
+type StructuredJudgment struct {
+	llm llms.Model
+}
+
+// NewStructuredJudgment creates a new judgment generator
+func NewStructuredJudgment(llm llms.Model) JudgmentGenerator {
+	return &StructuredJudgment{
+		llm: llm,
+	}
+}
+
+// rawJudgment is the JSON shape the LLM is asked to produce
+type rawJudgment struct {
+	Scores  map[string]float64 `json:"scores"`
+	Verdict string             `json:"verdict"`
+}
+
+// Judge rates the subject in every configured category
+func (g *StructuredJudgment) Judge(ctx context.Context, config JudgmentConfig) (*Judgment, error) {
+	categories := config.Categories
+	if len(categories) == 0 {
+		categories = DefaultJudgmentCategories
+	}
+	personality := config.Personality
+	if personality == nil {
+		personality = DefaultReplyPersonality
+	}
+	retries := config.Retries
+	switch {
+	case retries == 0:
+		retries = DefaultJudgmentRetries
+	case retries < 0:
+		retries = 0
+	}
+
+	var personalityText strings.Builder
+	for section, content := range personality {
+		personalityText.WriteString(fmt.Sprintf("\n%s:\n%s\n", section, content))
+	}
+
+	prompt, err := langchainprompts.NewPromptTemplate(
+		judgmentPrompt,
+		[]string{"personality", "subject", "material", "schema", "maxLength"},
+	).Format(map[string]any{
+		"personality": personalityText.String(),
+		"subject":     config.SubjectUsername,
+		"material":    config.Material,
+		"schema":      judgmentSchema(categories),
+		"maxLength":   config.MaxVerdictLength,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error formatting judgment prompt: %w", err)
+	}
+
+	attemptPrompt := prompt
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		answer, err := llms.GenerateFromSinglePrompt(ctx, g.llm, attemptPrompt,
+			llms.WithTemperature(config.Temperature),
+			llms.WithJSONMode(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error generating judgment: %w", err)
+		}
+
+		judgment, err := parseJudgment(answer, categories, config.MaxVerdictLength)
+		if err == nil {
+			return judgment, nil
+		}
+		lastErr = err
+		attemptPrompt = withPromptNote(prompt, fmt.Sprintf(judgmentRetryNote, err))
+	}
+
+	return nil, fmt.Errorf("invalid judgment after %d attempts: %w", retries+1, lastErr)
+}
+
+// parseJudgment decodes and validates an LLM answer
+func parseJudgment(answer string, categories []string, maxVerdictLength int) (*Judgment, error) {
+	// Models sometimes wrap JSON in prose or code fences despite JSON mode
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("answer contains no JSON object")
+	}
+
+	var raw rawJudgment
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("answer is not valid JSON: %w", err)
+	}
+
+	judgment := &Judgment{
+		Categories: categories,
+		Scores:     make(map[string]int, len(categories)),
+		Verdict:    strings.TrimSpace(raw.Verdict),
+	}
+	for _, category := range categories {
+		value, ok := lookupScore(raw.Scores, category)
+		if !ok {
+			return nil, fmt.Errorf("missing score for %q", category)
+		}
+		if value < 0 || value > MaxJudgmentScore {
+			return nil, fmt.Errorf("score for %q is %v, outside 0-%d", category, value, MaxJudgmentScore)
+		}
+		judgment.Scores[category] = int(math.Round(value))
+	}
+
+	if judgment.Verdict == "" {
+		return nil, fmt.Errorf("verdict is empty")
+	}
+	if maxVerdictLength > 0 {
+		if length := WeightedLength(judgment.Verdict); length > maxVerdictLength {
+			return nil, fmt.Errorf("verdict is %d characters, over the limit of %d", length, maxVerdictLength)
+		}
+	}
+	return judgment, nil
+}
+
+// lookupScore finds a category's score, tolerating differences in case and spacing
+func lookupScore(scores map[string]float64, category string) (float64, bool) {
+	if value, ok := scores[category]; ok {
+		return value, true
+	}
+	want := normalizeCategory(category)
+	for key, value := range scores {
+		if normalizeCategory(key) == want {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+func normalizeCategory(category string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(category), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "")
+}
+
+// judgmentSchema describes the expected answer as a JSON schema
+func judgmentSchema(categories []string) string {
+	properties := make(map[string]any, len(categories))
+	for _, category := range categories {
+		properties[category] = map[string]any{"type": "integer", "minimum": 0, "maximum": MaxJudgmentScore}
+	}
+	schema := map[string]any{
+		"type":     "object",
+		"required": []string{"scores", "verdict"},
+		"properties": map[string]any{
+			"scores": map[string]any{
+				"type":       "object",
+				"required":   categories,
+				"properties": properties,
+			},
+			"verdict": map[string]any{"type": "string"},
+		},
+	}
+	data, _ := json.MarshalIndent(schema, "", "  ")
+	return string(data)
+}
+
+// judgmentRetryNote is added to the prompt when the previous answer was invalid
+const judgmentRetryNote = `IMPORTANT: Your previous answer was rejected: %v.
+Answer again with only a JSON object that matches the schema exactly.`
+
+// judgmentPrompt asks for the rating as JSON
+const judgmentPrompt = `You are judging a user from your throne. Here is your personality:
+
+{{.personality}}
+
+User being judged: @{{.subject}}
+
+What you know about them:
+{{.material}}
+
+Rate them in every category from 0 (flawless) to 10 (tragic) and write a verdict in character.
+
+Respond with only a JSON object matching this schema:
+{{.schema}}
+
+Requirements:
+1. Every score is a whole number from 0 to 10
+2. Stay in character in the verdict
+{{if .maxLength}}3. The verdict MUST be under {{.maxLength}} characters
+{{end}}
+JSON:`
@@ -0,0 +1,159 @@
+package thoughts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var testCategories = []string{"Bio Cringe Factor", "Main Character Energy"}
+
+func TestParseJudgment(t *testing.T) {
+	tests := []struct {
+		name      string
+		answer    string
+		maxLength int
+		scores    map[string]int
+		verdict   string
+		err       string
+	}{
+		{
+			name:    "valid",
+			answer:  `{"scores": {"Bio Cringe Factor": 7, "Main Character Energy": 3}, "verdict": "Mid."}`,
+			scores:  map[string]int{"Bio Cringe Factor": 7, "Main Character Energy": 3},
+			verdict: "Mid.",
+		},
+		{
+			name:    "wrapped in a code fence",
+			answer:  "Here you go:\n```json\n{\"scores\": {\"Bio Cringe Factor\": 1, \"Main Character Energy\": 2}, \"verdict\": \" Tolerable. \"}\n```",
+			scores:  map[string]int{"Bio Cringe Factor": 1, "Main Character Energy": 2},
+			verdict: "Tolerable.",
+		},
+		{
+			name:    "category keys in another case and spacing",
+			answer:  `{"scores": {"bio_cringe_factor": 10, "main-character-energy": 0}, "verdict": "Bow."}`,
+			scores:  map[string]int{"Bio Cringe Factor": 10, "Main Character Energy": 0},
+			verdict: "Bow.",
+		},
+		{
+			name:    "fractional scores are rounded",
+			answer:  `{"scores": {"Bio Cringe Factor": 6.6, "Main Character Energy": 2.4}, "verdict": "Hmm."}`,
+			scores:  map[string]int{"Bio Cringe Factor": 7, "Main Character Energy": 2},
+			verdict: "Hmm.",
+		},
+		{name: "no JSON object", answer: "The cat lord declines.", err: "answer contains no JSON object"},
+		{name: "malformed JSON", answer: `{"scores": {"Bio Cringe Factor": 7,}, "verdict": "Mid."}`, err: "answer is not valid JSON"},
+		{
+			name:   "missing category",
+			answer: `{"scores": {"Bio Cringe Factor": 7}, "verdict": "Mid."}`,
+			err:    `missing score for "Main Character Energy"`,
+		},
+		{
+			name:   "score above the scale",
+			answer: `{"scores": {"Bio Cringe Factor": 11, "Main Character Energy": 3}, "verdict": "Mid."}`,
+			err:    `score for "Bio Cringe Factor" is 11, outside 0-10`,
+		},
+		{
+			name:   "negative score",
+			answer: `{"scores": {"Bio Cringe Factor": 5, "Main Character Energy": -1}, "verdict": "Mid."}`,
+			err:    `score for "Main Character Energy" is -1, outside 0-10`,
+		},
+		{
+			name:   "empty verdict",
+			answer: `{"scores": {"Bio Cringe Factor": 5, "Main Character Energy": 5}, "verdict": "  "}`,
+			err:    "verdict is empty",
+		},
+		{
+			name:      "verdict over the limit",
+			answer:    `{"scores": {"Bio Cringe Factor": 5, "Main Character Energy": 5}, "verdict": "Thou art unworthy."}`,
+			maxLength: 10,
+			err:       "verdict is 18 characters, over the limit of 10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			judgment, err := parseJudgment(tt.answer, testCategories, tt.maxLength)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("parseJudgment error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJudgment: %v", err)
+			}
+			for category, want := range tt.scores {
+				if got := judgment.Scores[category]; got != want {
+					t.Errorf("score for %q = %d, want %d", category, got, want)
+				}
+			}
+			if judgment.Verdict != tt.verdict {
+				t.Errorf("verdict = %q, want %q", judgment.Verdict, tt.verdict)
+			}
+		})
+	}
+}
+
+func TestStructuredJudgmentRetriesInvalidAnswers(t *testing.T) {
+	const (
+		malformed = `{"scores": {"Bio Cringe Factor": 7, "Main Character Energy": }, "verdict": "Mid."}`
+		valid     = `{"scores": {"Bio Cringe Factor": 7, "Main Character Energy": 4}, "verdict": "Mid."}`
+	)
+
+	model := &scriptedModel{answers: []string{malformed, valid}}
+	judgment, err := NewStructuredJudgment(model).Judge(context.Background(), JudgmentConfig{
+		SubjectUsername: "alice",
+		Material:        "Bio: main character",
+		Categories:      testCategories,
+	})
+	if err != nil {
+		t.Fatalf("Judge: %v", err)
+	}
+	if judgment.Scores["Main Character Energy"] != 4 || judgment.Overall() != 5.5 {
+		t.Errorf("Judge = %+v, want the scores of the valid answer", judgment)
+	}
+	if len(model.prompts) != 2 {
+		t.Fatalf("model called %d times, want 2", len(model.prompts))
+	}
+	if strings.Contains(model.prompts[0], "previous answer was rejected") {
+		t.Errorf("first prompt already has the retry note")
+	}
+	if !strings.Contains(model.prompts[1], "previous answer was rejected: answer is not valid JSON") {
+		t.Errorf("retry prompt does not explain the rejection: %q", model.prompts[1])
+	}
+	if !strings.HasSuffix(model.prompts[1], "\nJSON:") {
+		t.Errorf("retry prompt does not end with the answer cue")
+	}
+}
+
+func TestStructuredJudgmentGivesUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		retries int
+		calls   int
+	}{
+		{"default retries", 0, DefaultJudgmentRetries + 1},
+		{"one retry", 1, 2},
+		{"no retries", -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &scriptedModel{answers: []string{"I refuse to be structured."}}
+			judgment, err := NewStructuredJudgment(model).Judge(context.Background(), JudgmentConfig{
+				SubjectUsername: "alice",
+				Categories:      testCategories,
+				Retries:         tt.retries,
+			})
+			if err == nil {
+				t.Fatalf("Judge = %+v, want an error", judgment)
+			}
+			if want := fmt.Sprintf("invalid judgment after %d attempts: answer contains no JSON object", tt.calls); err.Error() != want {
+				t.Errorf("Judge error = %q, want %q", err, want)
+			}
+			if len(model.prompts) != tt.calls {
+				t.Errorf("model called %d times, want %d", len(model.prompts), tt.calls)
+			}
+		})
+	}
+}
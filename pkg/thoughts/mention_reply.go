@@ -24,6 +24,120 @@ type MentionReplyConfig struct {
 	Category            string            `json:"category,omitempty"`        // Optional: type of interaction
 	Language            string            `json:"language,omitempty"`        // Optional: for language support
 	Personality         map[string]string // Optional: will use DefaultReplyPersonality if nil
+	LengthRetries       int               `json:"length_retries,omitempty"` // Optional: regenerations when too long, 0 uses the default
+	Instructions        string            `json:"instructions,omitempty"`   // Optional: extra requirements, e.g. from a prompt variant
+	Sentiment           float64           `json:"sentiment,omitempty"`      // Optional: -1 (hostile) to 1 (praise), sets the tone
+
+	// CategoryPersonalities replaces or adds Personality sections for the
+	// reply's Category, see traits.Persona
+	CategoryPersonalities map[string]map[string]string
+	// Examples are curated tweets with the ideal reply, shown to keep the
+	// voice consistent
+	Examples []ReplyExample
+	// Avoid are earlier replies that were deleted or ignored; the new reply
+	// is told not to resemble them and regenerated when it does anyway
+	Avoid []string
+	// Notes are operator instructions for the conversation, which take
+	// precedence over the personality
+	Notes []string
+	// ConversationSummary stands in for the posts of the conversation before
+	// ConversationContext; AuthorSummary recalls what the author has said
+	// before. Both are optional.
+	ConversationSummary string
+	AuthorSummary       string
+	// Facts were looked up with tools for this reply, such as treasury
+	// balances; the reply quotes them instead of guessing
+	Facts string
+}
+
+// ReplyExample is a tweet and the reply the bot would ideally give to it
+type ReplyExample struct {
+	Tweet string
+	Reply string
+}
+
+// formatPersonality renders the personality sections of the reply's
+// category for the prompt
+func formatPersonality(config MentionReplyConfig) string {
+	personality := config.Personality
+	if personality == nil {
+		personality = DefaultReplyPersonality
+	}
+	personality = traits.Persona{Sections: personality, Categories: config.CategoryPersonalities}.For(config.Category)
+
+	var b strings.Builder
+	for section, content := range personality {
+		b.WriteString(fmt.Sprintf("\n%s:\n%s\n", section, content))
+	}
+	return b.String()
+}
+
+// formatExamples renders reply examples for the prompt
+func formatExamples(examples []ReplyExample) string {
+	var b strings.Builder
+	for _, example := range examples {
+		fmt.Fprintf(&b, "Tweet: %s\nYour reply: %s\n\n", example.Tweet, example.Reply)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// formatAvoid renders failed replies for the prompt
+func formatAvoid(replies []string) string {
+	var b strings.Builder
+	for _, reply := range replies {
+		fmt.Fprintf(&b, "- %s\n", reply)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// formatNotes renders operator notes for the prompt
+func formatNotes(notes []string) string {
+	var b strings.Builder
+	for _, note := range notes {
+		fmt.Fprintf(&b, "- %s\n", note)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// formatMemory renders the summaries of the conversation and the author for
+// the prompt
+func formatMemory(config MentionReplyConfig) string {
+	var b strings.Builder
+	if config.ConversationSummary != "" {
+		fmt.Fprintf(&b, "The conversation so far: %s\n", config.ConversationSummary)
+	}
+	if config.AuthorSummary != "" {
+		author := "the author"
+		if config.AuthorUsername != "" {
+			author = "@" + config.AuthorUsername
+		}
+		fmt.Fprintf(&b, "What you know about %s: %s\n", author, config.AuthorSummary)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// avoidRetryNote is added to the prompt when a previous attempt resembled a failed reply
+const avoidRetryNote = `IMPORTANT: Your previous attempt was nearly identical to this reply of yours that fell flat:
+"%s"
+Write something clearly different in wording and angle.`
+
+// Sentiment scores at which replies change tone
+const (
+	HostileSentiment = -0.3
+	PraiseSentiment  = 0.3
+)
+
+// toneInstruction returns how to adapt the reply to the tweet's sentiment, or
+// an empty string for neutral tweets
+func toneInstruction(sentiment float64) string {
+	switch {
+	case sentiment <= HostileSentiment:
+		return "The tweet is hostile towards you. Answer with extra sass: a cutting, witty put-down worthy of a cat lord, never crude or hateful"
+	case sentiment >= PraiseSentiment:
+		return "The tweet praises you. Accept the tribute with gracious superiority, as a lord acknowledging a loyal subject"
+	default:
+		return ""
+	}
 }
 
 type MentionReplyGenerator interface {
@@ -42,11 +156,6 @@ func NewMentionReplyGenerator(llm llms.Model) MentionReplyGenerator {
 
 // GenerateReply creates a reply based on the tweet and personality
 func (g *DefaultMentionReplyGenerator) GenerateReply(ctx context.Context, config MentionReplyConfig) (string, error) {
-	personality := config.Personality
-	if personality == nil {
-		personality = DefaultReplyPersonality
-	}
-
 	// Use enhanced prompt if conversation context is available
 	var promptTemplate string
 	if config.ConversationContext != "" {
@@ -57,32 +166,26 @@ func (g *DefaultMentionReplyGenerator) GenerateReply(ctx context.Context, config
 
 	replyPrompt := langchainprompts.NewPromptTemplate(
 		promptTemplate,
-		[]string{"personality", "tweet", "maxLength", "context", "authorUsername", "authorName", "category"},
+		[]string{"personality", "tweet", "maxLength", "context", "authorUsername", "authorName", "category", "instructions", "tone", "examples", "avoid", "notes", "memory", "facts"},
 	)
 
-	// Format personality traits into a string
-	var personalityText strings.Builder
-	for section, content := range personality {
-		personalityText.WriteString(fmt.Sprintf("\n%s:\n%s\n", section, content))
-	}
-
-	// Prepare prompt data with optional fields
+	// Every template variable needs an entry; optional fields are empty when
+	// not set so their sections are left out
 	promptData := map[string]any{
-		"personality": personalityText.String(),
-		"tweet":       config.TweetText,
-		"maxLength":   config.MaxLength,
-		"context":     config.ConversationContext,
-	}
-
-	// Add optional fields if present
-	if config.AuthorUsername != "" {
-		promptData["authorUsername"] = config.AuthorUsername
-	}
-	if config.AuthorName != "" {
-		promptData["authorName"] = config.AuthorName
-	}
-	if config.Category != "" {
-		promptData["category"] = config.Category
+		"personality":    formatPersonality(config),
+		"tweet":          config.TweetText,
+		"maxLength":      config.MaxLength,
+		"context":        config.ConversationContext,
+		"authorUsername": config.AuthorUsername,
+		"authorName":     config.AuthorName,
+		"category":       config.Category,
+		"instructions":   config.Instructions,
+		"tone":           toneInstruction(config.Sentiment),
+		"examples":       formatExamples(config.Examples),
+		"avoid":          formatAvoid(config.Avoid),
+		"notes":          formatNotes(config.Notes),
+		"memory":         formatMemory(config),
+		"facts":          config.Facts,
 	}
 
 	formattedPrompt, err := replyPrompt.Format(promptData)
@@ -90,22 +193,53 @@ func (g *DefaultMentionReplyGenerator) GenerateReply(ctx context.Context, config
 		return "", fmt.Errorf("error formatting reply prompt: %w", err)
 	}
 
-	reply, err := g.llm.Call(ctx, formattedPrompt,
-		llms.WithTemperature(config.Temperature),
-		llms.WithMaxTokens(config.MaxLength),
-	)
-	if err != nil {
-		return "", fmt.Errorf("error generating reply: %w", err)
-	}
+	// Regenerate replies that resemble a failed one. A mention still deserves
+	// an answer, so after the last retry the least similar attempt is used.
+	var best string
+	bestSimilarity := 2.0
+	attemptPrompt := formattedPrompt
+	for attempt := 0; ; attempt++ {
+		// Stream the reply, stopping at the character limit, and regenerate with
+		// stricter instructions while it is too long
+		reply, err := generateWithinLength(ctx, g.llm, attemptPrompt, config.MaxLength, config.LengthRetries,
+			llms.WithTemperature(config.Temperature),
+			llms.WithMaxTokens(config.MaxLength),
+		)
+		if err != nil {
+			return "", fmt.Errorf("error generating reply: %w", err)
+		}
 
-	return reply, nil
+		failed, similarity := MostSimilar(reply, config.Avoid)
+		if similarity < DefaultSimilarityThreshold {
+			return reply, nil
+		}
+		if similarity < bestSimilarity {
+			best, bestSimilarity = reply, similarity
+		}
+		if attempt >= DefaultDuplicateRetries {
+			return best, nil
+		}
+		attemptPrompt = withPromptNote(formattedPrompt, fmt.Sprintf(avoidRetryNote, failed))
+	}
 }
 
 // standardReplyPrompt is the original prompt template for backward compatibility
 const standardReplyPrompt = `You are responding to a tweet. Here is your personality:
 
 {{.personality}}
-
+{{if .examples}}
+Examples of how you reply:
+{{.examples}}
+{{end}}{{if .avoid}}
+Replies of yours that fell flat, do not write anything like them:
+{{.avoid}}
+{{end}}{{if .memory}}
+What you remember:
+{{.memory}}
+{{end}}{{if .facts}}
+Facts you looked up, quote these exact figures and never make up others:
+{{.facts}}
+{{end}}
 Tweet to respond to: {{.tweet}}
 
 Requirements:
@@ -113,7 +247,12 @@ Requirements:
 2. Stay in character
 3. Be engaging and memorable
 4. Respond directly to the tweet's content
-
+{{if .instructions}}5. {{.instructions}}
+{{end}}{{if .tone}}Tone: {{.tone}}
+{{end}}{{if .notes}}
+Operator notes for this conversation, always follow them:
+{{.notes}}
+{{end}}
 Your reply:`
 
 // conversationalReplyPrompt is the enhanced prompt template for conversation context
@@ -121,7 +260,19 @@ const conversationalReplyPrompt = `You are responding to a tweet conversation. H
 
 {{.personality}}
 
-CONVERSATION CONTEXT:
+{{if .examples}}Examples of how you reply:
+{{.examples}}
+
+{{end}}{{if .avoid}}Replies of yours that fell flat, do not write anything like them:
+{{.avoid}}
+
+{{end}}{{if .memory}}What you remember:
+{{.memory}}
+
+{{end}}{{if .facts}}Facts you looked up, quote these exact figures and never make up others:
+{{.facts}}
+
+{{end}}CONVERSATION CONTEXT:
 {{.context}}
 
 Tweet to respond to: {{.tweet}}
@@ -135,5 +286,10 @@ Requirements:
 4. Consider the full conversation context
 5. Maintain conversation flow
 6. Use appropriate emojis when relevant
-
+{{if .instructions}}7. {{.instructions}}
+{{end}}{{if .tone}}Tone: {{.tone}}
+{{end}}{{if .notes}}
+Operator notes for this conversation, always follow them:
+{{.notes}}
+{{end}}
 Your reply:`
@@ -14,6 +14,21 @@ type OriginalThoughtConfig struct {
 	MaxLength   int
 	Temperature float64
 	Personality map[string]string
+	// MarketContext holds current token prices the thought may reference, if any
+	MarketContext string
+	// LengthRetries is how many times an over-long thought is regenerated before
+	// it is truncated; 0 uses DefaultLengthRetries and a negative value disables retries
+	LengthRetries int
+	// RecentThoughts are the bot's recent original tweets. A thought too
+	// similar to one of them is regenerated, since Twitter rejects duplicates.
+	RecentThoughts []string
+	// SimilarityThreshold is the Similarity from which a thought counts as a
+	// duplicate; 0 uses DefaultSimilarityThreshold
+	SimilarityThreshold float64
+	// DuplicateRetries is how many times a duplicate is regenerated before
+	// ErrDuplicateThought is returned; 0 uses DefaultDuplicateRetries and a
+	// negative value disables retries
+	DuplicateRetries int
 }
 
 // OriginalThoughtGenerator defines the interface for generating thoughts
@@ -42,7 +57,10 @@ func (g *DefaultOriginalThoughtGenerator) GenerateOriginalThought(ctx context.Co
 {{.personality}}
 
 The thought should be about: {{.topic}}
-
+{{if .market}}
+Current market data (use these exact numbers if you mention prices):
+{{.market}}
+{{end}}
 Requirements:
 1. Stay within character
 2. Be concise and impactful
@@ -50,7 +68,7 @@ Requirements:
 4. Be engaging and memorable
 
 Generated thought:`,
-		[]string{"personality", "topic", "maxLength"},
+		[]string{"personality", "topic", "maxLength", "market"},
 	)
 
 	// Format personality traits into a string
@@ -61,21 +79,45 @@ Generated thought:`,
 		"personality": personalityStr,
 		"topic":       config.Topic,
 		"maxLength":   config.MaxLength,
+		"market":      config.MarketContext,
 	})
 	if err != nil {
 		return "", fmt.Errorf("error formatting thought prompt: %w", err)
 	}
 
-	// Generate the thought using LangChain's Model interface
-	thought, err := g.llm.Call(ctx, formattedPrompt,
-		llms.WithTemperature(config.Temperature),
-		llms.WithMaxTokens(config.MaxLength),
-	)
-	if err != nil {
-		return "", fmt.Errorf("error generating thought: %w", err)
+	threshold := config.SimilarityThreshold
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+	retries := config.DuplicateRetries
+	switch {
+	case retries == 0:
+		retries = DefaultDuplicateRetries
+	case retries < 0:
+		retries = 0
 	}
 
-	return thought, nil
+	attemptPrompt := formattedPrompt
+	for attempt := 0; ; attempt++ {
+		// Stream the thought, stopping at the character limit, and regenerate with
+		// stricter instructions while it is too long
+		thought, err := generateWithinLength(ctx, g.llm, attemptPrompt, config.MaxLength, config.LengthRetries,
+			llms.WithTemperature(config.Temperature),
+			llms.WithMaxTokens(config.MaxLength),
+		)
+		if err != nil {
+			return "", fmt.Errorf("error generating thought: %w", err)
+		}
+
+		earlier, similarity := MostSimilar(thought, config.RecentThoughts)
+		if similarity < threshold {
+			return thought, nil
+		}
+		if attempt >= retries {
+			return "", fmt.Errorf("%w (similarity %.2f)", ErrDuplicateThought, similarity)
+		}
+		attemptPrompt = withPromptNote(formattedPrompt, fmt.Sprintf(duplicateRetryNote, earlier))
+	}
 }
 
 // formatPersonalityTraits converts personality map to formatted string
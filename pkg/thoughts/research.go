@@ -0,0 +1,48 @@
+package thoughts
+
+import (
+	"fmt"
+	"strings"
+
+	langchainprompts "github.com/tmc/langchaingo/prompts"
+)
+
+// NoFacts is the research answer for a tweet that needs no looked-up facts
+const NoFacts = "NONE"
+
+// ResearchRequest asks the tool-calling loop for the facts a reply to the
+// tweet needs, such as balances or recent transfers
+func ResearchRequest(config MentionReplyConfig) (string, error) {
+	request, err := langchainprompts.NewPromptTemplate(
+		researchPrompt,
+		[]string{"tweet", "context", "none"},
+	).Format(map[string]any{
+		"tweet":   config.TweetText,
+		"context": config.ConversationContext,
+		"none":    NoFacts,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error formatting research prompt: %w", err)
+	}
+	return request, nil
+}
+
+// ParseFacts returns the facts of a research answer, or an empty string when
+// the tweet needs none
+func ParseFacts(answer string) string {
+	facts := strings.TrimSpace(answer)
+	if strings.HasPrefix(strings.ToUpper(facts), NoFacts) {
+		return ""
+	}
+	return facts
+}
+
+// researchPrompt asks for the facts a reply needs, looked up with tools
+const researchPrompt = `You are about to reply to a tweet. Before you write the reply, find out whether it needs facts you can look up with your tools, such as wallet or token balances, the treasury's recent distributions or token prices.
+{{if .context}}
+CONVERSATION CONTEXT:
+{{.context}}
+{{end}}
+Tweet: {{.tweet}}
+
+If the tweet asks about something your tools can answer, call them and then list the facts you found, one per line, with exact figures. Do not write the reply itself. If no tool is needed, answer with {{.none}} only.`
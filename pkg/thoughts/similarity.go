@@ -0,0 +1,85 @@
+package thoughts
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultSimilarityThreshold is the trigram similarity from which a new
+	// thought counts as a near-duplicate of an earlier one
+	DefaultSimilarityThreshold = 0.6
+
+	// DefaultDuplicateRetries is how many times a near-duplicate thought is
+	// regenerated before giving up
+	DefaultDuplicateRetries = 2
+)
+
+// ErrDuplicateThought is returned when every generated thought was too close
+// to one the bot already posted
+var ErrDuplicateThought = errors.New("thought is a near-duplicate of a recent tweet")
+
+// duplicateRetryNote is added to the prompt when a previous attempt repeated an earlier tweet
+const duplicateRetryNote = `IMPORTANT: Your previous attempt was nearly identical to this tweet you already posted:
+"%s"
+Twitter rejects duplicates. Write something clearly different in wording and angle.`
+
+// handlePattern matches @mentions, which say little about what a tweet is about
+var handlePattern = regexp.MustCompile(`@\w+`)
+
+// Similarity returns how alike two tweets are, from 0 (nothing in common) to
+// 1 (the same text), as the Jaccard index of their character trigrams. Case,
+// punctuation, links and mentions are ignored.
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for gram := range ta {
+		if tb[gram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// MostSimilar returns the earlier tweet closest to text and its similarity
+func MostSimilar(text string, earlier []string) (string, float64) {
+	var closest string
+	var best float64
+	for _, candidate := range earlier {
+		if score := Similarity(text, candidate); score > best {
+			closest, best = candidate, score
+		}
+	}
+	return closest, best
+}
+
+// trigrams returns the set of character trigrams of the normalized text
+func trigrams(text string) map[string]bool {
+	text = urlPattern.ReplaceAllString(text, " ")
+	text = handlePattern.ReplaceAllString(text, " ")
+
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+
+	runes := []rune(strings.TrimSpace(b.String()))
+	grams := make(map[string]bool)
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])] = true
+	}
+	return grams
+}
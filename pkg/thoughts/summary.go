@@ -0,0 +1,92 @@
+package thoughts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	langchainprompts "github.com/tmc/langchaingo/prompts"
+)
+
+// DefaultSummaryWords is the length summaries are asked to stay within when
+// none is configured
+const DefaultSummaryWords = 120
+
+// SummaryConfig holds what a summary is written from
+type SummaryConfig struct {
+	// Subject says what is summarized, e.g. "the conversation" or "@fan"
+	Subject string
+	// Previous is the summary of the earlier posts; empty for a first summary
+	Previous string
+	// Posts are the posts since the previous summary, oldest first
+	Posts []ContextPost
+	// MaxWords bounds the summary; 0 uses DefaultSummaryWords
+	MaxWords    int
+	Temperature float64
+}
+
+// SummaryGenerator writes compact summaries that stand in for long histories
+// in reply prompts
+type SummaryGenerator interface {
+	Summarize(ctx context.Context, config SummaryConfig) (string, error)
+}
+
+// DefaultSummaryGenerator folds new posts into the previous summary with one
+// LLM call
+type DefaultSummaryGenerator struct {
+	llm llms.Model
+}
+
+// NewSummaryGenerator creates a new summary generator
+func NewSummaryGenerator(llm llms.Model) SummaryGenerator {
+	return &DefaultSummaryGenerator{
+		llm: llm,
+	}
+}
+
+// Summarize returns the previous summary updated with the posts
+func (g *DefaultSummaryGenerator) Summarize(ctx context.Context, config SummaryConfig) (string, error) {
+	maxWords := config.MaxWords
+	if maxWords <= 0 {
+		maxWords = DefaultSummaryWords
+	}
+
+	var posts strings.Builder
+	for _, post := range config.Posts {
+		posts.WriteString(post.line())
+	}
+
+	prompt, err := langchainprompts.NewPromptTemplate(
+		summaryPrompt,
+		[]string{"subject", "previous", "posts", "maxWords"},
+	).Format(map[string]any{
+		"subject":  config.Subject,
+		"previous": config.Previous,
+		"posts":    posts.String(),
+		"maxWords": maxWords,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error formatting summary prompt: %w", err)
+	}
+
+	summary, err := llms.GenerateFromSinglePrompt(ctx, g.llm, prompt, llms.WithTemperature(config.Temperature))
+	if err != nil {
+		return "", fmt.Errorf("error generating summary: %w", err)
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", fmt.Errorf("summary is empty")
+	}
+	return summary, nil
+}
+
+// summaryPrompt asks for a summary that a later reply prompt can rely on
+const summaryPrompt = `You keep the memory of a social media bot. Summarize {{.subject}} so the bot can reply later without rereading the posts.
+{{if .previous}}
+Summary so far:
+{{.previous}}
+{{end}}
+New posts, oldest first:
+{{.posts}}
+Write an updated summary in at most {{.maxWords}} words, in plain prose without lists. Keep the topics, stances, running jokes, promises and open questions, who said what when it matters, and how the other side treats the bot. Drop greetings and filler. Answer with the summary only.`
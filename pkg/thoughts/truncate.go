@@ -0,0 +1,49 @@
+package thoughts
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TruncateText trims text to at most maxLength weighted characters (see
+// WeightedLength). When it has to cut, it prefers the end of the last complete
+// sentence, then the last word boundary, so a stream stopped mid-word never
+// produces a broken tweet.
+func TruncateText(text string, maxLength int) string {
+	text = strings.TrimSpace(text)
+	if maxLength <= 0 || WeightedLength(text) <= maxLength {
+		return text
+	}
+
+	// Longest prefix that fits the weighted limit
+	runes := []rune(text)
+	n := sort.Search(len(runes)+1, func(i int) bool {
+		return WeightedLength(string(runes[:i])) > maxLength
+	}) - 1
+	if n <= 0 {
+		return ""
+	}
+	cut := runes[:n]
+
+	// Keep whole sentences when that still leaves at least half the limit
+	for i := len(cut) - 1; i >= n/2; i-- {
+		if strings.ContainsRune(".!?", cut[i]) && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			return strings.TrimSpace(string(cut[:i+1]))
+		}
+	}
+
+	// Otherwise drop the partial last word, unless the cut already lands on a boundary
+	if unicode.IsSpace(runes[n]) {
+		return strings.TrimSpace(string(cut))
+	}
+	for i := len(cut) - 1; i > 0; i-- {
+		if unicode.IsSpace(cut[i]) {
+			return strings.TrimRightFunc(string(cut[:i]), func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(",;:-", r)
+			})
+		}
+	}
+
+	return string(cut)
+}
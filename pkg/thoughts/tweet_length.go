@@ -0,0 +1,139 @@
+package thoughts
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	// transformedURLLength is the length Twitter counts for every URL, whatever its size
+	transformedURLLength = 23
+
+	// DefaultLengthRetries is how many times generation is retried with stricter
+	// instructions before an over-long text is truncated
+	DefaultLengthRetries = 2
+)
+
+// lengthRetryNote is added to the prompt when a previous attempt was too long
+const lengthRetryNote = `IMPORTANT: Your previous attempt was %s, over the hard limit of %d characters.
+Write a noticeably shorter version of at most %d characters. Count links as 23 characters and emojis as 2.`
+
+// urlPattern matches the URLs Twitter shortens to t.co links
+var urlPattern = regexp.MustCompile(`https?://[^\s]+|(?:[a-zA-Z0-9-]+\.)+(?:com|org|net|io|xyz|co|ai|app|dev|gg)\b(?:/[^\s]*)?`)
+
+// WeightedLength returns the length of text as counted by Twitter: every URL
+// counts as 23 characters, Latin and common punctuation count as one, and
+// other characters, including each emoji sequence, count as two.
+func WeightedLength(text string) int {
+	length := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		length += weightedTextLength(text[last:loc[0]]) + transformedURLLength
+		last = loc[1]
+	}
+	return length + weightedTextLength(text[last:])
+}
+
+// weightedTextLength weighs text that contains no URLs
+func weightedTextLength(text string) int {
+	length := 0
+	joined := false
+	for _, r := range text {
+		switch {
+		case r == '‍':
+			// Zero width joiner: the next emoji belongs to the current sequence
+			joined = true
+			continue
+		case isEmojiModifier(r):
+			continue
+		case joined && isEmoji(r):
+			joined = false
+			continue
+		}
+		joined = false
+		length += runeWeight(r)
+	}
+	return length
+}
+
+// runeWeight follows Twitter's weighted ranges: Latin-1 through Tamil, plus
+// general punctuation, count as one and everything else as two
+func runeWeight(r rune) int {
+	switch {
+	case r <= 4351,
+		r >= 8192 && r <= 8205,
+		r >= 8208 && r <= 8223,
+		r >= 8242 && r <= 8247:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// isEmoji reports whether r is a pictographic emoji code point
+func isEmoji(r rune) bool {
+	return (r >= 0x1F300 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) || unicode.Is(unicode.So, r)
+}
+
+// isEmojiModifier reports whether r only modifies the preceding emoji
+// (variation selectors and skin tones) and so adds no length
+func isEmojiModifier(r rune) bool {
+	return (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0x1F3FB && r <= 0x1F3FF)
+}
+
+// generateWithinLength streams a completion for prompt and validates its weighted
+// length. Over-long results are regenerated with stricter instructions up to
+// retries times; the last attempt is then truncated at a sentence boundary.
+func generateWithinLength(ctx context.Context, model llms.Model, prompt string, maxLength, retries int, opts ...llms.CallOption) (string, error) {
+	switch {
+	case retries == 0:
+		retries = DefaultLengthRetries
+	case retries < 0:
+		retries = 0
+	}
+
+	// Stream one weighted character past the limit so a cut-off always means too long
+	streamLimit := 0
+	if maxLength > 0 {
+		streamLimit = maxLength + 1
+	}
+
+	attemptPrompt := prompt
+	var text string
+	for attempt := 0; attempt <= retries; attempt++ {
+		result, err := llm.StreamWithLimit(ctx, model, attemptPrompt, streamLimit, WeightedLength, opts...)
+		if err != nil {
+			return "", err
+		}
+
+		text = strings.TrimSpace(result.Text)
+		length := WeightedLength(text)
+		if maxLength <= 0 || (!result.CutOff && length <= maxLength) {
+			return text, nil
+		}
+
+		was := fmt.Sprintf("%d characters", length)
+		if result.CutOff {
+			was = fmt.Sprintf("more than %d characters", maxLength)
+		}
+		attemptPrompt = withPromptNote(prompt, fmt.Sprintf(lengthRetryNote, was, maxLength, maxLength*9/10))
+	}
+
+	return TruncateText(text, maxLength), nil
+}
+
+// withPromptNote inserts note before the final line of prompt, which cues the answer
+func withPromptNote(prompt, note string) string {
+	trimmed := strings.TrimRight(prompt, "\n")
+	i := strings.LastIndex(trimmed, "\n")
+	if i < 0 {
+		return note + "\n\n" + prompt
+	}
+	return trimmed[:i] + "\n\n" + note + "\n" + trimmed[i:]
+}
@@ -0,0 +1,170 @@
+package thoughts
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// scriptedModel answers each call with the next of its answers, repeating the
+// last one, and streams them word by word when asked to
+type scriptedModel struct {
+	mu      sync.Mutex
+	answers []string
+	prompts []string
+}
+
+func (m *scriptedModel) next(prompt string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prompts = append(m.prompts, prompt)
+	answer := m.answers[0]
+	if len(m.answers) > 1 {
+		m.answers = m.answers[1:]
+	}
+	return answer
+}
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var prompt string
+	for _, part := range messages[len(messages)-1].Parts {
+		if text, ok := part.(llms.TextContent); ok {
+			prompt += text.Text
+		}
+	}
+	answer := m.next(prompt)
+
+	opts := llms.CallOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		for _, chunk := range strings.SplitAfter(answer, " ") {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := opts.StreamingFunc(ctx, []byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: answer}}}, nil
+}
+
+func (m *scriptedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestWeightedLength(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"plain text", "gm peasant", 10},
+		{"URL with a path", "see https://example.com/a/very/long/path?x=1 now", 31},
+		{"bare domain", "visit laffy.xyz today", 35},
+		{"bare domain with a path", "read laffy.io/docs now", 32},
+		{"word running on past a TLD", "the cats.coming back", 20},
+		{"word starting with a TLD", "hello world.networking", 22},
+		{"unknown TLD", "built with node.js", 18},
+		{"accented Latin", "café", 4},
+		{"general punctuation", "wait—what", 9},
+		{"CJK", "猫猫猫", 6},
+		{"CJK and Latin", "猫 cat", 6},
+		{"emoji", "gm 🐱", 5},
+		{"emoji with skin tone", "👍🏽", 2},
+		{"emoji with variation selector", "❤️", 2},
+		{"ZWJ sequence", "👨‍👩‍👧", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeightedLength(tt.text); got != tt.want {
+				t.Errorf("WeightedLength(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		want      string
+	}{
+		{"under the limit", "gm peasant", 20, "gm peasant"},
+		{"exactly at the limit", "hello world", 11, "hello world"},
+		{"no limit", "hello world", 0, "hello world"},
+		{"one over the limit", "hello world", 10, "hello"},
+		{"cut on a word boundary", "hello world again", 11, "hello world"},
+		{"keeps whole sentences", "The cat is king. Bow before the throne now", 25, "The cat is king."},
+		{"drops trailing punctuation", "one two, three", 11, "one two"},
+		{"weighs CJK as two", "猫猫猫猫猫", 5, "猫猫"},
+		{"keeps ZWJ sequences whole", "gm 👨‍👩‍👧 fam", 4, "gm"},
+		{"counts URLs as 23", "see https://example.com/x now", 27, "see https://example.com/x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateText(tt.text, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("TruncateText(%q, %d) = %q, want %q", tt.text, tt.maxLength, got, tt.want)
+			}
+			if tt.maxLength > 0 && WeightedLength(got) > tt.maxLength {
+				t.Errorf("TruncateText(%q, %d) is %d characters", tt.text, tt.maxLength, WeightedLength(got))
+			}
+		})
+	}
+}
+
+func TestGenerateWithinLength(t *testing.T) {
+	const (
+		prompt    = "You are the cat lord.\nReply:"
+		tooLong   = "Bow before the cat lord, peasant, for the throne is mine"
+		short     = "Bow, peasant."
+		maxLength = 20
+	)
+
+	tests := []struct {
+		name    string
+		answers []string
+		retries int
+		want    string
+		calls   int
+	}{
+		{"fits the first time", []string{short}, 0, short, 1},
+		{"regenerates an over-long reply", []string{tooLong, short}, 0, short, 2},
+		{"truncates after the last retry", []string{tooLong}, 1, "Bow before the cat", 2},
+		{"truncates without retries", []string{tooLong}, -1, "Bow before the cat", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &scriptedModel{answers: tt.answers}
+			got, err := generateWithinLength(context.Background(), model, prompt, maxLength, tt.retries)
+			if err != nil {
+				t.Fatalf("generateWithinLength: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("generateWithinLength = %q, want %q", got, tt.want)
+			}
+			if len(model.prompts) != tt.calls {
+				t.Fatalf("model called %d times, want %d", len(model.prompts), tt.calls)
+			}
+
+			if model.prompts[0] != prompt {
+				t.Errorf("first prompt = %q, want %q", model.prompts[0], prompt)
+			}
+			for _, retry := range model.prompts[1:] {
+				if !strings.Contains(retry, "previous attempt was more than 20 characters") {
+					t.Errorf("retry prompt does not explain the limit: %q", retry)
+				}
+				if !strings.HasSuffix(retry, "\nReply:") {
+					t.Errorf("retry prompt does not end with the answer cue: %q", retry)
+				}
+			}
+		})
+	}
+}
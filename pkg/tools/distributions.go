@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+)
+
+const (
+	// defaultDistributions is how many transfers are listed unless asked
+	defaultDistributions = 5
+
+	// maxDistributions caps the transfers listed in one call
+	maxDistributions = 20
+
+	// distributionBlocks is how many blocks back distributions are looked
+	// for, about a day on Base and two weeks on Ethereum
+	distributionBlocks = 100000
+)
+
+// RecentDistributionsTool lists the latest token transfers sent from the treasury
+type RecentDistributionsTool struct {
+	client   *wallet.Client
+	token    string
+	treasury string
+	networks []string
+}
+
+// NewRecentDistributionsTool creates a tool listing transfers of token sent
+// from treasury, an address or ENS name
+func NewRecentDistributionsTool(client *wallet.Client, token, treasury string) *RecentDistributionsTool {
+	var networks []string
+	for _, network := range client.Networks() {
+		networks = append(networks, string(network))
+	}
+	return &RecentDistributionsTool{
+		client:   client,
+		token:    token,
+		treasury: treasury,
+		networks: networks,
+	}
+}
+
+// Name implements the Tool interface
+func (t *RecentDistributionsTool) Name() string {
+	return "get_recent_distributions"
+}
+
+// Description implements the Tool interface
+func (t *RecentDistributionsTool) Description() string {
+	return "List the latest transfers of the project's token sent from the treasury, newest first, with amount, recipient and time."
+}
+
+// Parameters implements the Tool interface
+func (t *RecentDistributionsTool) Parameters() Schema {
+	return NewSchema(map[string]Property{
+		"network": {Type: TypeString, Description: "Blockchain network", Enum: t.networks},
+		"limit":   {Type: TypeInteger, Description: fmt.Sprintf("Number of transfers to list, at most %d", maxDistributions)},
+	}, "network")
+}
+
+// Call implements the Tool interface
+func (t *RecentDistributionsTool) Call(ctx context.Context, args map[string]any) (string, error) {
+	network := wallet.NetworkType(StringArg(args, "network", ""))
+	limit := min(max(IntArg(args, "limit", defaultDistributions), 1), maxDistributions)
+	if !common.IsHexAddress(t.token) {
+		return "", fmt.Errorf("no token contract configured")
+	}
+	if t.treasury == "" {
+		return "", fmt.Errorf("no treasury configured")
+	}
+
+	treasury, err := t.client.ResolveAddress(ctx, network, t.treasury)
+	if err != nil {
+		return "", err
+	}
+
+	distributions, err := t.client.RecentDistributions(ctx, network, common.HexToAddress(t.token), treasury, distributionBlocks, limit)
+	if err != nil {
+		return "", err
+	}
+	if len(distributions) == 0 {
+		return fmt.Sprintf("No transfers from %s on %s in the last %d blocks", t.client.DisplayAddress(ctx, treasury), network, distributionBlocks), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Latest transfers from %s on %s:\n", t.client.DisplayAddress(ctx, treasury), network)
+	for _, distribution := range distributions {
+		when := "unknown time"
+		if !distribution.Time.IsZero() {
+			when = distribution.Time.Format(time.RFC3339)
+		}
+		fmt.Fprintf(&b, "- %s %s to %s at %s (tx %s)\n",
+			distribution.FormattedAmount(), distribution.Symbol,
+			t.client.DisplayAddress(ctx, distribution.To), when, distribution.TxHash.Hex())
+	}
+	return strings.TrimSpace(b.String()), nil
+}
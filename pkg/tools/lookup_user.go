@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+)
+
+// LookupUserTool fetches a Twitter user's public profile
+type LookupUserTool struct {
+	client *twitter.TwitterClient
+}
+
+// NewLookupUserTool creates a user lookup tool backed by the Twitter client
+func NewLookupUserTool(client *twitter.TwitterClient) *LookupUserTool {
+	return &LookupUserTool{client: client}
+}
+
+// Name implements the Tool interface
+func (t *LookupUserTool) Name() string {
+	return "lookup_user"
+}
+
+// Description implements the Tool interface
+func (t *LookupUserTool) Description() string {
+	return "Look up a Twitter user's profile, bio and follower counts by username."
+}
+
+// Parameters implements the Tool interface
+func (t *LookupUserTool) Parameters() Schema {
+	return NewSchema(map[string]Property{
+		"username": {Type: TypeString, Description: "Twitter handle, with or without the leading @"},
+	}, "username")
+}
+
+// Call implements the Tool interface
+func (t *LookupUserTool) Call(ctx context.Context, args map[string]any) (string, error) {
+	user, err := t.client.GetUserByUsername(ctx, StringArg(args, "username", ""))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("@%s (%s)\nBio: %s\nLocation: %s\nFollowers: %d, Following: %d, Tweets: %d\nJoined: %s, Verified: %t",
+		user.Username,
+		user.Name,
+		user.Description,
+		user.Location,
+		user.PublicMetrics.FollowersCount,
+		user.PublicMetrics.FollowingCount,
+		user.PublicMetrics.TweetCount,
+		user.CreatedAt,
+		user.Verified,
+	), nil
+}
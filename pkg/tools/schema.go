@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Supported JSON schema property types
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// Schema is the JSON schema describing a tool's arguments object
+type Schema struct {
+	Type       string              `json:"type"`
+	Properties map[string]Property `json:"properties"`
+	Required   []string            `json:"required,omitempty"`
+}
+
+// Property describes a single tool argument
+type Property struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+}
+
+// NewSchema creates an object schema from its properties and required names
+func NewSchema(properties map[string]Property, required ...string) Schema {
+	return Schema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+}
+
+// Validate parses the model-provided JSON arguments and checks them against the schema.
+// Unknown arguments, missing required arguments, type mismatches and values outside
+// an enum are all rejected so tools never see malformed input.
+func (s Schema) Validate(raw string) (map[string]any, error) {
+	args := make(map[string]any)
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &args); err != nil {
+			return nil, fmt.Errorf("arguments must be a JSON object: %w", err)
+		}
+	}
+
+	for _, name := range s.Required {
+		if _, ok := args[name]; !ok {
+			return nil, fmt.Errorf("missing required argument %q", name)
+		}
+	}
+
+	for name, value := range args {
+		prop, ok := s.Properties[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+		if err := prop.check(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
+// check validates a single decoded JSON value against the property definition
+func (p Property) check(name string, value any) error {
+	switch p.Type {
+	case TypeString:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("argument %q must be a string", name)
+		}
+		if len(p.Enum) > 0 {
+			for _, allowed := range p.Enum {
+				if str == allowed {
+					return nil
+				}
+			}
+			return fmt.Errorf("argument %q must be one of %s", name, strings.Join(p.Enum, ", "))
+		}
+	case TypeInteger:
+		num, ok := value.(float64)
+		if !ok || num != math.Trunc(num) {
+			return fmt.Errorf("argument %q must be an integer", name)
+		}
+	case TypeNumber:
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("argument %q must be a number", name)
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("argument %q must be a boolean", name)
+		}
+	default:
+		return fmt.Errorf("argument %q has unsupported schema type %q", name, p.Type)
+	}
+	return nil
+}
+
+// StringArg returns a string argument or the fallback when absent
+func StringArg(args map[string]any, name, fallback string) string {
+	if v, ok := args[name].(string); ok {
+		return v
+	}
+	return fallback
+}
+
+// IntArg returns an integer argument or the fallback when absent
+func IntArg(args map[string]any, name string, fallback int) int {
+	if v, ok := args[name].(float64); ok {
+		return int(v)
+	}
+	return fallback
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
+	"github.com/lisanmuaddib/agent-go/pkg/model"
+)
+
+const (
+	defaultSearchCount = 5
+	maxSearchCount     = 20
+)
+
+// SearchTweetsTool searches recent tweets through the Masa Twitter API
+type SearchTweetsTool struct {
+	client *masatwitter.Client
+}
+
+// NewSearchTweetsTool creates a tweet search tool backed by the Masa client
+func NewSearchTweetsTool(client *masatwitter.Client) *SearchTweetsTool {
+	return &SearchTweetsTool{client: client}
+}
+
+// Name implements the Tool interface
+func (t *SearchTweetsTool) Name() string {
+	return "search_tweets"
+}
+
+// Description implements the Tool interface
+func (t *SearchTweetsTool) Description() string {
+	return "Search recent tweets matching a query (keywords, hashtags, cashtags or from:username)."
+}
+
+// Parameters implements the Tool interface
+func (t *SearchTweetsTool) Parameters() Schema {
+	return NewSchema(map[string]Property{
+		"query": {Type: TypeString, Description: "Twitter search query"},
+		"count": {Type: TypeInteger, Description: fmt.Sprintf("Number of tweets to return (1-%d)", maxSearchCount)},
+	}, "query")
+}
+
+// Call implements the Tool interface
+func (t *SearchTweetsTool) Call(ctx context.Context, args map[string]any) (string, error) {
+	query := StringArg(args, "query", "")
+	count := IntArg(args, "count", defaultSearchCount)
+	if count < 1 || count > maxSearchCount {
+		count = defaultSearchCount
+	}
+
+	tweets, err := t.client.SearchWithOptions(ctx, query, masatwitter.SearchOptions{
+		TweetCount: count,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search tweets: %w", err)
+	}
+
+	if len(tweets) == 0 {
+		return fmt.Sprintf("No tweets found for %q", query), nil
+	}
+
+	var result strings.Builder
+	for _, tweet := range model.FromMasaTweets(tweets) {
+		result.WriteString(fmt.Sprintf("@%s: %s (likes=%d, retweets=%d)\n",
+			tweet.Author.Username,
+			tweet.Text,
+			tweet.Metrics.Likes,
+			tweet.Metrics.Retweets,
+		))
+	}
+	return result.String(), nil
+}
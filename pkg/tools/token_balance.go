@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+)
+
+// TokenBalanceTool reports the ERC20 token balance of an address
+type TokenBalanceTool struct {
+	client         *wallet.Client
+	token          string
+	defaultAddress string
+	networks       []string
+}
+
+// NewTokenBalanceTool creates a token balance tool. token is the contract used
+// when the model does not name one, typically the project's token, and
+// defaultAddress the holder, typically the treasury.
+func NewTokenBalanceTool(client *wallet.Client, token, defaultAddress string) *TokenBalanceTool {
+	var networks []string
+	for _, network := range client.Networks() {
+		networks = append(networks, string(network))
+	}
+	return &TokenBalanceTool{
+		client:         client,
+		token:          token,
+		defaultAddress: defaultAddress,
+		networks:       networks,
+	}
+}
+
+// Name implements the Tool interface
+func (t *TokenBalanceTool) Name() string {
+	return "get_token_balance"
+}
+
+// Description implements the Tool interface
+func (t *TokenBalanceTool) Description() string {
+	return "Get the ERC20 token balance of a wallet address. Defaults to the project's token held by the treasury."
+}
+
+// Parameters implements the Tool interface
+func (t *TokenBalanceTool) Parameters() Schema {
+	return NewSchema(map[string]Property{
+		"network": {Type: TypeString, Description: "Blockchain network", Enum: t.networks},
+		"token":   {Type: TypeString, Description: "0x-prefixed token contract address; omit for the project's token"},
+		"address": {Type: TypeString, Description: "0x-prefixed wallet address or ENS name; omit for the treasury"},
+	}, "network")
+}
+
+// Call implements the Tool interface
+func (t *TokenBalanceTool) Call(ctx context.Context, args map[string]any) (string, error) {
+	network := wallet.NetworkType(StringArg(args, "network", ""))
+	token := StringArg(args, "token", t.token)
+	if !common.IsHexAddress(token) {
+		return "", fmt.Errorf("no valid token contract address provided")
+	}
+	address := StringArg(args, "address", t.defaultAddress)
+	if address == "" {
+		return "", fmt.Errorf("no address provided and no treasury configured")
+	}
+
+	resolved, err := t.client.ResolveAddress(ctx, network, address)
+	if err != nil {
+		return "", err
+	}
+
+	tokenAddress := common.HexToAddress(token)
+	metadata, err := t.client.GetTokenMetadata(ctx, network, tokenAddress)
+	if err != nil {
+		return "", err
+	}
+	balance, err := t.client.GetERC20Balance(ctx, network, tokenAddress, resolved)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s holds %s %s on %s", t.client.DisplayAddress(ctx, resolved), wallet.FormatUnits(balance, metadata.Decimals), metadata.Symbol, network), nil
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/lisanmuaddib/agent-go/pkg/market"
+)
+
+// PriceSource provides token quotes to the token price tool; *market.Client
+// implements it
+type PriceSource interface {
+	GetQuote(ctx context.Context, symbol string) (*market.Quote, error)
+}
+
+// TokenPriceTool fetches the current price of a token
+type TokenPriceTool struct {
+	source PriceSource
+}
+
+// NewTokenPriceTool creates a price tool backed by the given source
+func NewTokenPriceTool(source PriceSource) *TokenPriceTool {
+	return &TokenPriceTool{source: source}
+}
+
+// Name implements the Tool interface
+func (t *TokenPriceTool) Name() string {
+	return "get_token_price"
+}
+
+// Description implements the Tool interface
+func (t *TokenPriceTool) Description() string {
+	return "Get the current USD price and 24h change of a crypto token by its symbol."
+}
+
+// Parameters implements the Tool interface
+func (t *TokenPriceTool) Parameters() Schema {
+	return NewSchema(map[string]Property{
+		"symbol": {Type: TypeString, Description: "Token symbol such as BTC, ETH or LAFFY"},
+	}, "symbol")
+}
+
+// Call implements the Tool interface
+func (t *TokenPriceTool) Call(ctx context.Context, args map[string]any) (string, error) {
+	quote, err := t.source.GetQuote(ctx, StringArg(args, "symbol", ""))
+	if err != nil {
+		return "", err
+	}
+
+	return market.FormatQuote(quote), nil
+}
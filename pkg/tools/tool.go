@@ -0,0 +1,119 @@
+// Package tools defines the callable tools the agent's reasoning loop exposes
+// to the LLM through OpenAI function calling.
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Tool is a single capability the LLM can invoke during a reasoning loop
+type Tool interface {
+	// Name returns the function name advertised to the model
+	Name() string
+	// Description tells the model when the tool should be used
+	Description() string
+	// Parameters returns the JSON schema of the tool's arguments
+	Parameters() Schema
+	// Call executes the tool with arguments already validated against Parameters
+	Call(ctx context.Context, args map[string]any) (string, error)
+}
+
+// Registry holds the set of tools available to the reasoning loop
+type Registry struct {
+	tools map[string]Tool
+	mu    sync.RWMutex
+}
+
+// NewRegistry creates a registry with the provided tools
+func NewRegistry(tools ...Tool) (*Registry, error) {
+	r := &Registry{
+		tools: make(map[string]Tool),
+	}
+	for _, tool := range tools {
+		if err := r.Register(tool); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a tool to the registry
+func (r *Registry) Register(tool Tool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := tool.Name()
+	if name == "" {
+		return fmt.Errorf("tool name is required")
+	}
+	if _, exists := r.tools[name]; exists {
+		return fmt.Errorf("tool %s already registered", name)
+	}
+
+	r.tools[name] = tool
+	return nil
+}
+
+// Get returns the tool registered under name
+func (r *Registry) Get(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// Names returns the registered tool names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Definitions returns the function definitions sent to the model
+func (r *Registry) Definitions() []llms.Tool {
+	names := r.Names()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definitions := make([]llms.Tool, 0, len(names))
+	for _, name := range names {
+		tool := r.tools[name]
+		definitions = append(definitions, llms.Tool{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        tool.Name(),
+				Description: tool.Description(),
+				Parameters:  tool.Parameters(),
+			},
+		})
+	}
+	return definitions
+}
+
+// Invoke validates the raw JSON arguments for the named tool and calls it
+func (r *Registry) Invoke(ctx context.Context, name, rawArgs string) (string, error) {
+	tool, ok := r.Get(name)
+	if !ok {
+		return "", fmt.Errorf("unknown tool: %s", name)
+	}
+
+	args, err := tool.Parameters().Validate(rawArgs)
+	if err != nil {
+		return "", fmt.Errorf("invalid arguments for %s: %w", name, err)
+	}
+
+	return tool.Call(ctx, args)
+}
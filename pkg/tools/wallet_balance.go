@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+)
+
+// WalletBalanceTool reports the native token balance of an address
+type WalletBalanceTool struct {
+	client         *wallet.Client
+	defaultAddress string
+	networks       []string
+}
+
+// NewWalletBalanceTool creates a balance tool. defaultAddress is used when the
+// model does not name an address, typically the agent's own wallet.
+// The model may pick any network the client is connected to.
+func NewWalletBalanceTool(client *wallet.Client, defaultAddress string) *WalletBalanceTool {
+	var networks []string
+	for _, network := range client.Networks() {
+		networks = append(networks, string(network))
+	}
+	return &WalletBalanceTool{
+		client:         client,
+		defaultAddress: defaultAddress,
+		networks:       networks,
+	}
+}
+
+// Name implements the Tool interface
+func (t *WalletBalanceTool) Name() string {
+	return "get_wallet_balance"
+}
+
+// Description implements the Tool interface
+func (t *WalletBalanceTool) Description() string {
+	return "Get the native token balance of a wallet address. Defaults to the agent's own wallet."
+}
+
+// Parameters implements the Tool interface
+func (t *WalletBalanceTool) Parameters() Schema {
+	return NewSchema(map[string]Property{
+		"network": {Type: TypeString, Description: "Blockchain network", Enum: t.networks},
+		"address": {Type: TypeString, Description: "0x-prefixed wallet address or ENS name such as vitalik.eth"},
+	}, "network")
+}
+
+// Call implements the Tool interface
+func (t *WalletBalanceTool) Call(ctx context.Context, args map[string]any) (string, error) {
+	network := wallet.NetworkType(StringArg(args, "network", ""))
+	address := StringArg(args, "address", t.defaultAddress)
+	if address == "" {
+		return "", fmt.Errorf("no address provided and no default wallet configured")
+	}
+
+	resolved, err := t.client.ResolveAddress(ctx, network, address)
+	if err != nil {
+		return "", err
+	}
+
+	balance, err := t.client.GetBalance(ctx, network, resolved.Hex())
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s balance on %s: %s", t.client.DisplayAddress(ctx, resolved), network, formatUnits(balance, 18)), nil
+}
+
+// formatUnits renders a base-unit amount with the given number of decimals
+func formatUnits(amount *big.Int, decimals int) string {
+	value := new(big.Float).SetInt(amount)
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	formatted := new(big.Float).Quo(value, divisor).Text('f', 6)
+	formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	if formatted == "" {
+		return "0"
+	}
+	return formatted
+}
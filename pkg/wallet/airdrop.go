@@ -0,0 +1,422 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+)
+
+// DefaultDisperseAddress is the disperse contract (disperse.app), deployed at
+// the same address on Ethereum, Base and BSC
+var DefaultDisperseAddress = common.HexToAddress("0xD152f549545093347A162Dce210e7293f1452150")
+
+const (
+	// defaultAirdropBatchSize keeps each disperse transaction well below the block gas limit
+	defaultAirdropBatchSize = 200
+
+	// disperseBatchOverhead approximates the gas the disperse contract adds on
+	// top of the token transfers when a batch cannot be estimated directly
+	disperseBatchOverhead = 30000
+)
+
+// disperseABI is the disperse contract function used for ERC20 airdrops. It
+// pulls the tokens from the sender with transferFrom, so the contract must be
+// approved first.
+const disperseABI = `[
+	{
+		"constant": false,
+		"inputs": [
+			{"name": "token", "type": "address"},
+			{"name": "recipients", "type": "address[]"},
+			{"name": "values", "type": "uint256[]"}
+		],
+		"name": "disperseToken",
+		"outputs": [],
+		"type": "function"
+	}
+]`
+
+// AirdropRecipient is one recipient of a batch transfer
+type AirdropRecipient struct {
+	Address common.Address
+	Amount  *big.Int
+}
+
+// BatchTransferOptions configures BatchTransferERC20
+type BatchTransferOptions struct {
+	// BatchSize is the number of recipients per transaction; defaults to 200
+	BatchSize int
+
+	// Simulate checks and estimates every batch with eth_call without sending
+	// the approval or any transfer
+	Simulate bool
+}
+
+// BatchResult is the outcome of one disperse transaction
+type BatchResult struct {
+	// Index is the position of the batch, starting at 0
+	Index int
+
+	Recipients []AirdropRecipient
+
+	// Total is the sum of the batch's amounts
+	Total *big.Int
+
+	// GasEstimate is the estimated gas limit of the batch
+	GasEstimate uint64
+
+	// Approximate is set when the disperse call could not be simulated because
+	// the approval is missing, so the batch was checked transfer by transfer
+	// and GasEstimate includes an allowance for the contract overhead
+	Approximate bool
+
+	// Status is the mined transaction; nil when simulating or when the batch failed before sending
+	Status *TransactionStatus
+
+	// Err is why the batch failed; the other batches are still attempted
+	Err error
+}
+
+// BatchTransferResult reports every batch of a batch transfer
+type BatchTransferResult struct {
+	Network NetworkType
+	Token   common.Address
+
+	// Total is the sum of all amounts
+	Total *big.Int
+
+	// ApprovalNeeded is set when the disperse contract's allowance did not
+	// cover Total; unless simulating, an approval for Total was sent
+	ApprovalNeeded bool
+
+	// Approval is the approval transaction, if one was sent
+	Approval *TransactionStatus
+
+	Batches []BatchResult
+}
+
+// Failed returns the batches that failed
+func (r *BatchTransferResult) Failed() []BatchResult {
+	var failed []BatchResult
+	for _, batch := range r.Batches {
+		if batch.Err != nil {
+			failed = append(failed, batch)
+		}
+	}
+	return failed
+}
+
+// FailedRecipients returns the recipients of the failed batches, for retrying them
+func (r *BatchTransferResult) FailedRecipients() []AirdropRecipient {
+	var recipients []AirdropRecipient
+	for _, batch := range r.Failed() {
+		recipients = append(recipients, batch.Recipients...)
+	}
+	return recipients
+}
+
+// GasEstimate returns the summed gas estimate of all batches
+func (r *BatchTransferResult) GasEstimate() uint64 {
+	var total uint64
+	for _, batch := range r.Batches {
+		total += batch.GasEstimate
+	}
+	return total
+}
+
+// BatchTransferERC20 distributes an ERC20 token to many recipients through
+// the disperse contract, one transaction per batch of recipients. The
+// contract is approved for the total first if its allowance is short.
+//
+// Batches are estimated and sent in order. A failed batch does not stop the
+// rest: its error is reported in the result, and FailedRecipients can be
+// passed to a later call to retry them. An error is only returned when nothing
+// could be attempted, e.g. invalid recipients, an insufficient balance or a
+// failed approval.
+//
+// With opts.Simulate every batch is checked with eth_call and estimated, but
+// nothing is sent. In dry-run mode (SetDryRun) each batch is recorded instead.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Target blockchain network
+//   - tokenAddress: Address of the ERC20 token contract
+//   - recipients: Recipients and amounts in token base units
+//   - opts: Batch options, or nil for the defaults
+//
+// Returns:
+//   - *BatchTransferResult: Outcome of every batch
+//   - error: Error if the transfer could not be attempted
+//
+// Example:
+//
+//	result, err := client.BatchTransferERC20(ctx, BASE, laffyAddr, recipients, &BatchTransferOptions{Simulate: true})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%d batches, %d gas, %d failed\n", len(result.Batches), result.GasEstimate(), len(result.Failed()))
+func (c *Client) BatchTransferERC20(
+	ctx context.Context,
+	network NetworkType,
+	tokenAddress common.Address,
+	recipients []AirdropRecipient,
+	opts *BatchTransferOptions,
+) (*BatchTransferResult, error) {
+	if opts == nil {
+		opts = &BatchTransferOptions{}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultAirdropBatchSize
+	}
+
+	if len(recipients) == 0 {
+		return nil, NewWalletError(ErrCodeInvalidAddress, "no recipients to transfer to", nil, network)
+	}
+	total := new(big.Int)
+	for _, recipient := range recipients {
+		if recipient.Address == (common.Address{}) {
+			return nil, NewWalletError(ErrCodeInvalidAddress, "recipient is the zero address", nil, network)
+		}
+		if recipient.Amount == nil || recipient.Amount.Sign() <= 0 {
+			return nil, NewWalletError(ErrCodeTransactionFailed, fmt.Sprintf("amount for %s must be positive", recipient.Address.Hex()), nil, network)
+		}
+		total.Add(total, recipient.Amount)
+	}
+
+	_, config, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+	disperse := config.DisperseAddress
+	if disperse == (common.Address{}) {
+		disperse = DefaultDisperseAddress
+	}
+
+	result := &BatchTransferResult{
+		Network: network,
+		Token:   tokenAddress,
+		Total:   total,
+	}
+	for start := 0; start < len(recipients); start += batchSize {
+		batch := recipients[start:min(start+batchSize, len(recipients))]
+		batchTotal := new(big.Int)
+		for _, recipient := range batch {
+			batchTotal.Add(batchTotal, recipient.Amount)
+		}
+		result.Batches = append(result.Batches, BatchResult{
+			Index:      len(result.Batches),
+			Recipients: batch,
+			Total:      batchTotal,
+		})
+	}
+
+	if recorder := c.dryRunRecorder(); recorder != nil && !opts.Simulate {
+		return c.recordDryRunBatches(ctx, recorder, result, disperse)
+	}
+
+	owner := c.keyManager.GetAddress()
+	balance, err := c.GetERC20Balance(ctx, network, tokenAddress, owner)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(total) < 0 {
+		return nil, NewWalletError(ErrCodeInsufficientFunds, fmt.Sprintf("token balance %s is below the airdrop total %s", balance, total), nil, network)
+	}
+
+	erc20, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, NewWalletError(ErrCodeInvalidABI, "failed to parse ABI", err, network)
+	}
+	allowance, err := c.erc20Allowance(ctx, network, erc20, tokenAddress, owner, disperse)
+	if err != nil {
+		return nil, err
+	}
+	result.ApprovalNeeded = allowance.Cmp(total) < 0
+
+	if result.ApprovalNeeded && !opts.Simulate {
+		data, err := erc20.Pack("approve", disperse, total)
+		if err != nil {
+			return nil, NewWalletError(ErrCodeInvalidABI, "failed to pack approve call", err, network)
+		}
+		c.log.WithField("network", network).WithField("amount", total.String()).Info("Approving disperse contract for airdrop")
+		status, err := c.SendTransaction(ctx, network, tokenAddress, data, big.NewInt(0))
+		if err != nil {
+			return nil, err
+		}
+		if status.Status == types.ReceiptStatusFailed {
+			return nil, NewWalletError(ErrCodeTransactionFailed, "approval of disperse contract reverted", nil, network)
+		}
+		result.Approval = status
+	}
+
+	disperseContract, err := abi.JSON(strings.NewReader(disperseABI))
+	if err != nil {
+		return nil, NewWalletError(ErrCodeInvalidABI, "failed to parse disperse ABI", err, network)
+	}
+
+	for i := range result.Batches {
+		if err := ctx.Err(); err != nil {
+			result.Batches[i].Err = err
+			continue
+		}
+		batch := &result.Batches[i]
+		batch.Err = c.sendBatch(ctx, network, tokenAddress, disperse, disperseContract, erc20, batch, opts.Simulate && result.ApprovalNeeded, opts.Simulate)
+
+		entry := c.log.WithField("network", network).WithField("batch", batch.Index).
+			WithField("recipients", len(batch.Recipients)).WithField("gas", batch.GasEstimate)
+		if batch.Err != nil {
+			entry.WithError(batch.Err).Warn("Airdrop batch failed")
+		} else if batch.Status != nil {
+			entry.WithField("hash", batch.Status.Hash.Hex()).Info("Airdrop batch confirmed")
+		}
+	}
+
+	return result, nil
+}
+
+// sendBatch estimates one batch and, unless simulating, sends it. Without an
+// approval the disperse call would revert, so the batch's transfers are
+// checked one by one instead.
+func (c *Client) sendBatch(
+	ctx context.Context,
+	network NetworkType,
+	tokenAddress, disperse common.Address,
+	disperseContract, erc20 abi.ABI,
+	batch *BatchResult,
+	perTransfer, simulate bool,
+) error {
+	if perTransfer {
+		batch.Approximate = true
+		batch.GasEstimate = disperseBatchOverhead
+		for _, recipient := range batch.Recipients {
+			data, err := erc20.Pack("transfer", recipient.Address, recipient.Amount)
+			if err != nil {
+				return NewWalletError(ErrCodeInvalidABI, "failed to pack transfer call", err, network)
+			}
+			gas, err := c.simulateCall(ctx, network, tokenAddress, data)
+			if err != nil {
+				return fmt.Errorf("transfer to %s would fail: %w", recipient.Address.Hex(), err)
+			}
+			batch.GasEstimate += gas
+		}
+		return nil
+	}
+
+	addresses := make([]common.Address, len(batch.Recipients))
+	amounts := make([]*big.Int, len(batch.Recipients))
+	for i, recipient := range batch.Recipients {
+		addresses[i] = recipient.Address
+		amounts[i] = recipient.Amount
+	}
+	data, err := disperseContract.Pack("disperseToken", tokenAddress, addresses, amounts)
+	if err != nil {
+		return NewWalletError(ErrCodeInvalidABI, "failed to pack disperseToken call", err, network)
+	}
+
+	gas, err := c.simulateCall(ctx, network, disperse, data)
+	if err != nil {
+		return err
+	}
+	batch.GasEstimate = gas
+	if simulate {
+		return nil
+	}
+
+	status, err := c.SendTransaction(ctx, network, disperse, data, big.NewInt(0))
+	if err != nil {
+		return err
+	}
+	batch.Status = status
+	if status.Status == types.ReceiptStatusFailed {
+		return NewWalletError(ErrCodeTransactionFailed, "disperse transaction reverted", nil, network)
+	}
+	return nil
+}
+
+// simulateCall runs a call with eth_call, so a revert is reported with its
+// reason before any gas is spent, and returns its estimated gas limit
+func (c *Client) simulateCall(ctx context.Context, network NetworkType, to common.Address, data []byte) (uint64, error) {
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return 0, err
+	}
+
+	msg := ethereum.CallMsg{From: c.keyManager.GetAddress(), To: &to, Data: data}
+	if _, err := client.CallContract(ctx, msg, nil); err != nil {
+		return 0, NewWalletError(ErrCodeContractError, "simulation reverted", err, network)
+	}
+
+	gas, err := c.EstimateGas(ctx, network, to, data, big.NewInt(0))
+	if err != nil {
+		return 0, NewWalletError(ErrCodeGasEstimationFailed, "failed to estimate batch gas", err, network)
+	}
+	return gas, nil
+}
+
+// erc20Allowance returns how much spender may transfer from owner
+func (c *Client) erc20Allowance(ctx context.Context, network NetworkType, erc20 abi.ABI, tokenAddress, owner, spender common.Address) (*big.Int, error) {
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := erc20.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, NewWalletError(ErrCodeInvalidABI, "failed to pack allowance call", err, network)
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &tokenAddress, Data: data}, nil)
+	if err != nil {
+		return nil, NewWalletError(ErrCodeContractError, "failed to get token allowance", err, network)
+	}
+
+	out, err := erc20.Unpack("allowance", output)
+	if err != nil || len(out) == 0 {
+		return nil, NewWalletError(ErrCodeContractError, "failed to decode token allowance", err, network)
+	}
+	allowance, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, NewWalletError(ErrCodeContractError, "failed to convert allowance to *big.Int", nil, network)
+	}
+	return allowance, nil
+}
+
+// recordDryRunBatches records every batch in dry-run mode instead of sending it
+func (c *Client) recordDryRunBatches(ctx context.Context, recorder dryrun.Recorder, result *BatchTransferResult, disperse common.Address) (*BatchTransferResult, error) {
+	for i := range result.Batches {
+		batch := &result.Batches[i]
+		recipients := make([]string, len(batch.Recipients))
+		amounts := make([]string, len(batch.Recipients))
+		for j, recipient := range batch.Recipients {
+			recipients[j] = recipient.Address.Hex()
+			amounts[j] = recipient.Amount.String()
+		}
+
+		hash, err := c.recordDryRunTransfer(ctx, recorder, dryrun.Record{
+			Kind:    dryrun.KindERC20Transfer,
+			Target:  disperse.Hex(),
+			Content: fmt.Sprintf("disperse %s of token %s to %d recipients", batch.Total, result.Token.Hex(), len(batch.Recipients)),
+			Details: map[string]interface{}{
+				"token":      result.Token.Hex(),
+				"batch":      batch.Index,
+				"recipients": recipients,
+				"amounts":    amounts,
+			},
+		}, result.Network)
+		if err != nil {
+			return nil, err
+		}
+		batch.Status = &TransactionStatus{Hash: hash, State: TxStatePending, Timestamp: time.Now()}
+	}
+	return result, nil
+}
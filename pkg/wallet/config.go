@@ -3,6 +3,8 @@ package wallet
 import (
 	"math/big"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // NetworkConfig holds network-specific configuration parameters for blockchain interactions.
@@ -12,8 +14,9 @@ type NetworkConfig struct {
 	// Type identifies which blockchain network this config is for (e.g. ETH, BSC)
 	Type NetworkType
 
-	// RPCURL is the HTTP(S) endpoint for connecting to the network
-	RPCURL string
+	// RPCURL is the HTTP(S) endpoint for connecting to the network. Provider
+	// URLs often carry an API key in the path, so it is masked in logs.
+	RPCURL string `redact:"true"`
 
 	// ChainID is the unique identifier for the blockchain network
 	ChainID int64
@@ -31,15 +34,51 @@ type NetworkConfig struct {
 	// MaxGasPrice sets an upper bound on gas price to prevent overpaying
 	// Transactions will not be sent if gas price exceeds this value
 	MaxGasPrice *big.Int
+
+	// NativeSymbol is the symbol of the token fees are paid in; defaults to "ETH"
+	NativeSymbol string
+
+	// MinPriorityFee is the lowest tip the network's validators accept.
+	// Fee estimates never suggest less.
+	MinPriorityFee *big.Int
+
+	// L1Fee is how a rollup charges for its data on Ethereum, used by EstimateL1Fee
+	L1Fee L1FeeModel
+
+	// DisperseAddress is the disperse contract used by BatchTransferERC20.
+	// The zero address uses DefaultDisperseAddress.
+	DisperseAddress common.Address
+
+	// ExplorerURL is the block explorer transactions are linked to, e.g.
+	// "https://basescan.org"; empty leaves them unlinked
+	ExplorerURL string
 }
 
-// DefaultNetworkConfigs returns pre-configured settings for supported blockchain networks.
-// It provides sensible defaults for Ethereum mainnet, Base, and Binance Smart Chain.
+// L1FeeModel identifies how a rollup charges for posting its transactions to Ethereum
+type L1FeeModel string
+
+const (
+	// L1FeeNone is used by networks without an L1 data fee
+	L1FeeNone L1FeeModel = ""
+
+	// L1FeeOPStack charges a separate L1 data fee, quoted by the GasPriceOracle
+	// predeploy, on top of the L2 gas (Optimism, Base)
+	L1FeeOPStack L1FeeModel = "op-stack"
+
+	// L1FeeArbitrum adds the L1 cost to the gas limit itself, so estimates move
+	// with the L1 gas price; NodeInterface reports that share
+	L1FeeArbitrum L1FeeModel = "arbitrum"
+)
+
+// DefaultNetworkConfigs returns pre-configured settings for the built-in networks:
+// Ethereum mainnet, Base, Binance Smart Chain, Polygon, Arbitrum One and OP Mainnet.
 //
 // The defaults include:
+// - Public RPC endpoints, which are rate limited; set RPCURL for production use
 // - Conservative gas price limits
 // - 3 retry attempts with 1 second delay
-// - 20% buffer on gas estimates
+// - 20% buffer on gas estimates, 50% on Arbitrum where the gas limit includes the L1 cost
+// - Polygon's minimum priority fee of 30 gwei
 //
 // Example usage:
 //
@@ -49,27 +88,74 @@ func DefaultNetworkConfigs() []NetworkConfig {
 	return []NetworkConfig{
 		{
 			Type:               ETH,
+			RPCURL:             "https://ethereum-rpc.publicnode.com",
 			ChainID:            1,
 			MaxRetries:         3,
 			RetryDelay:         time.Second,
 			GasLimitMultiplier: 1.2,
 			MaxGasPrice:        big.NewInt(300000000000), // 300 gwei
+			NativeSymbol:       "ETH",
+			ExplorerURL:        "https://etherscan.io",
 		},
 		{
 			Type:               BASE,
+			RPCURL:             "https://mainnet.base.org",
 			ChainID:            8453,
 			MaxRetries:         3,
 			RetryDelay:         time.Second,
 			GasLimitMultiplier: 1.2,
 			MaxGasPrice:        big.NewInt(100000000000), // 100 gwei
+			NativeSymbol:       "ETH",
+			L1Fee:              L1FeeOPStack,
+			ExplorerURL:        "https://basescan.org",
 		},
 		{
 			Type:               BSC,
+			RPCURL:             "https://bsc-dataseed.bnbchain.org",
 			ChainID:            56,
 			MaxRetries:         3,
 			RetryDelay:         time.Second,
 			GasLimitMultiplier: 1.2,
 			MaxGasPrice:        big.NewInt(5000000000), // 5 gwei
+			NativeSymbol:       "BNB",
+			ExplorerURL:        "https://bscscan.com",
+		},
+		{
+			Type:               POLYGON,
+			RPCURL:             "https://polygon-rpc.com",
+			ChainID:            137,
+			MaxRetries:         3,
+			RetryDelay:         time.Second,
+			GasLimitMultiplier: 1.2,
+			MaxGasPrice:        big.NewInt(1000000000000), // 1000 gwei
+			NativeSymbol:       "POL",
+			ExplorerURL:        "https://polygonscan.com",
+			// Validators drop transactions tipping less than 30 gwei
+			MinPriorityFee: big.NewInt(30000000000),
+		},
+		{
+			Type:               ARBITRUM,
+			RPCURL:             "https://arb1.arbitrum.io/rpc",
+			ChainID:            42161,
+			MaxRetries:         3,
+			RetryDelay:         time.Second,
+			GasLimitMultiplier: 1.5,
+			MaxGasPrice:        big.NewInt(10000000000), // 10 gwei
+			NativeSymbol:       "ETH",
+			L1Fee:              L1FeeArbitrum,
+			ExplorerURL:        "https://arbiscan.io",
+		},
+		{
+			Type:               OPTIMISM,
+			RPCURL:             "https://mainnet.optimism.io",
+			ChainID:            10,
+			MaxRetries:         3,
+			RetryDelay:         time.Second,
+			GasLimitMultiplier: 1.2,
+			MaxGasPrice:        big.NewInt(100000000000), // 100 gwei
+			NativeSymbol:       "ETH",
+			L1Fee:              L1FeeOPStack,
+			ExplorerURL:        "https://optimistic.etherscan.io",
 		},
 	}
 }
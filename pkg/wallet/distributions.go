@@ -0,0 +1,154 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Distribution is an ERC20 transfer sent from an address, such as a payout
+// from the treasury
+type Distribution struct {
+	Network NetworkType
+	Token   common.Address
+	// Symbol and Decimals are empty when the token metadata could not be read
+	Symbol      string
+	Decimals    uint8
+	From        common.Address
+	To          common.Address
+	Amount      *big.Int
+	TxHash      common.Hash
+	BlockNumber uint64
+	LogIndex    uint
+	// Time is the block's timestamp; zero when the block could not be read
+	Time time.Time
+}
+
+// FormattedAmount renders the amount in whole tokens, e.g. "1.5"
+func (d Distribution) FormattedAmount() string {
+	return FormatUnits(d.Amount, d.Decimals)
+}
+
+// RecentDistributions returns the latest transfers of an ERC20 token sent from
+// an address, newest first. The chain is scanned backwards from the head in
+// ranges of 2000 blocks until limit transfers are found or maxBlocks blocks
+// were scanned.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Target blockchain network
+//   - tokenAddress: Address of the ERC20 token contract
+//   - from: Address the transfers were sent from
+//   - maxBlocks: How many blocks back to look
+//   - limit: Maximum number of transfers to return
+//
+// Returns:
+//   - []Distribution: Transfers found, newest first
+//   - error: Error if the network is invalid or a log query fails
+//
+// Example:
+//
+//	payouts, err := client.RecentDistributions(ctx, BASE, laffyAddr, client.Address(), 50000, 5)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, payout := range payouts {
+//	    fmt.Printf("%s %s to %s\n", payout.FormattedAmount(), payout.Symbol, payout.To.Hex())
+//	}
+func (c *Client) RecentDistributions(ctx context.Context, network NetworkType, tokenAddress, from common.Address, maxBlocks uint64, limit int) ([]Distribution, error) {
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+	if maxBlocks == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, NewWalletError(ErrCodeRPCError, "failed to get block number", err, network)
+	}
+
+	var metadata *TokenMetadata
+	if meta, err := c.GetTokenMetadata(ctx, network, tokenAddress); err == nil {
+		metadata = meta
+	} else {
+		c.log.WithError(err).WithField("token", tokenAddress.Hex()).Debug("Failed to read metadata of distributed token")
+	}
+
+	sender := common.BytesToHash(from.Bytes())
+	lowest := uint64(0)
+	if head+1 > maxBlocks {
+		lowest = head + 1 - maxBlocks
+	}
+
+	var found []Distribution
+	for to := head; len(found) < limit; {
+		start := lowest
+		if to+1-lowest > defaultWatchBlockRange {
+			start = to + 1 - defaultWatchBlockRange
+		}
+
+		entries, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{tokenAddress},
+			Topics:    [][]common.Hash{{transferTopic}, {sender}},
+		})
+		if err != nil {
+			return nil, NewWalletError(ErrCodeRPCError, "failed to query transfer logs", err, network)
+		}
+
+		// Logs come oldest first; walk them backwards to keep the newest
+		for i := len(entries) - 1; i >= 0 && len(found) < limit; i-- {
+			entry := entries[i]
+			// ERC721 transfers share the signature but index the token ID as well
+			if entry.Removed || len(entry.Topics) != 3 || len(entry.Data) != 32 {
+				continue
+			}
+			distribution := Distribution{
+				Network:     network,
+				Token:       entry.Address,
+				From:        from,
+				To:          common.BytesToAddress(entry.Topics[2].Bytes()),
+				Amount:      new(big.Int).SetBytes(entry.Data),
+				TxHash:      entry.TxHash,
+				BlockNumber: entry.BlockNumber,
+				LogIndex:    entry.Index,
+			}
+			if metadata != nil {
+				distribution.Symbol = metadata.Symbol
+				distribution.Decimals = metadata.Decimals
+			}
+			found = append(found, distribution)
+		}
+
+		if start == lowest {
+			break
+		}
+		to = start - 1
+	}
+
+	// Several transfers usually share a block, so each header is read once
+	times := make(map[uint64]time.Time)
+	for i := range found {
+		block := found[i].BlockNumber
+		if _, ok := times[block]; !ok {
+			header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+			if err != nil {
+				c.log.WithError(err).WithField("block", block).Debug("Failed to read block time of distribution")
+				times[block] = time.Time{}
+			} else {
+				times[block] = time.Unix(int64(header.Time), 0).UTC()
+			}
+		}
+		found[i].Time = times[block]
+	}
+
+	return found, nil
+}
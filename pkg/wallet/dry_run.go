@@ -0,0 +1,77 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+)
+
+// SetDryRun makes the client record transfers with recorder instead of
+// signing and broadcasting them. Balance and gas queries still reach the network.
+//
+// Parameters:
+//   - recorder: Sink for suppressed transfers, or nil to send transactions again
+func (c *Client) SetDryRun(recorder dryrun.Recorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dryRun = recorder
+}
+
+// dryRunRecorder returns the configured recorder, or nil when transactions are live
+func (c *Client) dryRunRecorder() dryrun.Recorder {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dryRun
+}
+
+// recordDryRunTransfer records a transfer that dry-run mode suppressed and
+// returns a synthetic hash for it. The hash is derived from the transfer and the
+// current time, so it never matches a real transaction.
+func (c *Client) recordDryRunTransfer(ctx context.Context, recorder dryrun.Recorder, record dryrun.Record, network NetworkType) (common.Hash, error) {
+	hash := crypto.Keccak256Hash(
+		[]byte(network),
+		[]byte(record.Target),
+		[]byte(record.Content),
+		big.NewInt(time.Now().UnixNano()).Bytes(),
+	)
+
+	record.Details["network"] = string(network)
+	record.Details["from"] = c.keyManager.address.Hex()
+	record.Details["hash"] = hash.Hex()
+	if err := recorder.RecordDryRun(ctx, record); err != nil {
+		return common.Hash{}, NewWalletError(ErrCodeTransactionFailed, "failed to record dry-run transfer", err, network)
+	}
+	return hash, nil
+}
+
+// dryRunTransaction records a native transfer or contract call
+func (c *Client) dryRunTransaction(ctx context.Context, recorder dryrun.Recorder, network NetworkType, to common.Address, data []byte, value *big.Int) (*TransactionStatus, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	record := dryrun.Record{
+		Kind:    dryrun.KindTransaction,
+		Target:  to.Hex(),
+		Content: fmt.Sprintf("send %s wei to %s", value, to.Hex()),
+		Details: map[string]interface{}{
+			"value": value.String(),
+			"data":  "0x" + hex.EncodeToString(data),
+		},
+	}
+
+	hash, err := c.recordDryRunTransfer(ctx, recorder, record, network)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionStatus{
+		Hash:      hash,
+		State:     TxStatePending,
+		Timestamp: time.Now(),
+	}, nil
+}
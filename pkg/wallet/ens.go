@@ -0,0 +1,294 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ensRegistryAddress is the ENS registry on Ethereum mainnet
+var ensRegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ensCacheTTL is how long resolved names and addresses are reused
+const ensCacheTTL = time.Hour
+
+// ensABI covers the registry's resolver lookup and the resolver's forward
+// and reverse records
+const ensABI = `[
+	{
+		"constant": true,
+		"inputs": [{"name": "node", "type": "bytes32"}],
+		"name": "resolver",
+		"outputs": [{"name": "", "type": "address"}],
+		"type": "function"
+	},
+	{
+		"constant": true,
+		"inputs": [{"name": "node", "type": "bytes32"}],
+		"name": "addr",
+		"outputs": [{"name": "", "type": "address"}],
+		"type": "function"
+	},
+	{
+		"constant": true,
+		"inputs": [{"name": "node", "type": "bytes32"}],
+		"name": "name",
+		"outputs": [{"name": "", "type": "string"}],
+		"type": "function"
+	}
+]`
+
+// ensCacheEntry is a cached forward or reverse resolution
+type ensCacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// IsENSName reports whether s looks like an ENS name such as "vitalik.eth"
+// rather than a hex address.
+//
+// Parameters:
+//   - s: Name or address to check
+//
+// Returns:
+//   - bool: true if s should be resolved through ENS
+func IsENSName(s string) bool {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || !strings.Contains(s, ".") {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveENS resolves an ENS name to the address it points to on Ethereum
+// mainnet. Names are lowercased; full UTS-46 normalization is not applied.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - name: ENS name, e.g. "vitalik.eth"
+//
+// Returns:
+//   - common.Address: Address the name resolves to
+//   - error: WalletError with ErrCodeENSNotFound if the name has no address record
+//
+// Example:
+//
+//	address, err := client.ResolveENS(ctx, "vitalik.eth")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(address.Hex())
+func (c *Client) ResolveENS(ctx context.Context, name string) (common.Address, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !IsENSName(name) {
+		return common.Address{}, NewWalletError(ErrCodeInvalidAddress, fmt.Sprintf("%q is not an ENS name", name), nil, ETH)
+	}
+
+	cacheKey := "name:" + name
+	if cached, ok := c.cachedENS(cacheKey); ok {
+		return common.HexToAddress(cached), nil
+	}
+
+	node := ensNamehash(name)
+	resolver, err := c.ensResolver(ctx, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, NewWalletError(ErrCodeENSNotFound, fmt.Sprintf("%s has no resolver", name), nil, ETH)
+	}
+
+	var out []interface{}
+	if err := c.ensCall(ctx, resolver, &out, "addr", node); err != nil {
+		return common.Address{}, NewWalletError(ErrCodeContractError, fmt.Sprintf("failed to resolve %s", name), err, ETH)
+	}
+	address, ok := out[0].(common.Address)
+	if !ok || address == (common.Address{}) {
+		return common.Address{}, NewWalletError(ErrCodeENSNotFound, fmt.Sprintf("%s has no address record", name), nil, ETH)
+	}
+
+	c.cacheENS(cacheKey, address.Hex())
+	return address, nil
+}
+
+// LookupENS returns the primary ENS name of an address. The name is only
+// returned when it resolves back to the same address, so a name set by
+// someone else cannot be used to impersonate the address.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - address: Address to look up
+//
+// Returns:
+//   - string: Primary ENS name, or "" if the address has none
+//   - error: Error if the lookup fails
+//
+// Example:
+//
+//	name, err := client.LookupENS(ctx, common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(name) // vitalik.eth
+func (c *Client) LookupENS(ctx context.Context, address common.Address) (string, error) {
+	cacheKey := "address:" + address.Hex()
+	if cached, ok := c.cachedENS(cacheKey); ok {
+		return cached, nil
+	}
+
+	node := ensNamehash(strings.ToLower(strings.TrimPrefix(address.Hex(), "0x")) + ".addr.reverse")
+	resolver, err := c.ensResolver(ctx, node)
+	if err != nil {
+		return "", err
+	}
+
+	var name string
+	if resolver != (common.Address{}) {
+		var out []interface{}
+		if err := c.ensCall(ctx, resolver, &out, "name", node); err != nil {
+			return "", NewWalletError(ErrCodeContractError, fmt.Sprintf("failed to look up the name of %s", address.Hex()), err, ETH)
+		}
+		name, _ = out[0].(string)
+	}
+
+	if name != "" {
+		forward, err := c.ResolveENS(ctx, name)
+		if err != nil || forward != address {
+			c.log.WithField("address", address.Hex()).WithField("name", name).
+				Debug("Ignoring ENS reverse record that does not resolve back to the address")
+			name = ""
+		}
+	}
+
+	c.cacheENS(cacheKey, name)
+	return name, nil
+}
+
+// ResolveAddress accepts either a hex address or an ENS name, as users write
+// them in tweets, and returns the validated address on network. ENS names are
+// always resolved on Ethereum mainnet.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Network the address will be used on
+//   - addressOrName: "0x..." address or ENS name such as "vitalik.eth"
+//
+// Returns:
+//   - common.Address: Resolved address
+//   - error: Error if the address is invalid or the name does not resolve
+//
+// Example:
+//
+//	recipient, err := client.ResolveAddress(ctx, BASE, "vitalik.eth")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Client) ResolveAddress(ctx context.Context, network NetworkType, addressOrName string) (common.Address, error) {
+	addressOrName = strings.TrimSpace(addressOrName)
+	if IsENSName(addressOrName) {
+		return c.ResolveENS(ctx, addressOrName)
+	}
+	if err := c.ValidateAddress(network, addressOrName); err != nil {
+		return common.Address{}, err
+	}
+	return common.HexToAddress(addressOrName), nil
+}
+
+// DisplayAddress renders an address for replies: its primary ENS name when
+// it has one, otherwise the shortened hex form such as "0xd8dA…6045".
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - address: Address to display
+//
+// Returns:
+//   - string: Human-readable form of the address
+func (c *Client) DisplayAddress(ctx context.Context, address common.Address) string {
+	name, err := c.LookupENS(ctx, address)
+	if err != nil {
+		c.log.WithError(err).WithField("address", address.Hex()).Debug("ENS lookup failed, showing hex address")
+	}
+	if name != "" {
+		return name
+	}
+	hex := address.Hex()
+	return hex[:6] + "…" + hex[len(hex)-4:]
+}
+
+// ensResolver returns the resolver the registry holds for node
+func (c *Client) ensResolver(ctx context.Context, node [32]byte) (common.Address, error) {
+	var out []interface{}
+	if err := c.ensCall(ctx, ensRegistryAddress, &out, "resolver", node); err != nil {
+		return common.Address{}, NewWalletError(ErrCodeContractError, "failed to query ENS registry", err, ETH)
+	}
+	resolver, _ := out[0].(common.Address)
+	return resolver, nil
+}
+
+// ensCall calls a read-only ENS contract method on Ethereum mainnet
+func (c *Client) ensCall(ctx context.Context, contractAddress common.Address, out *[]interface{}, method string, node [32]byte) error {
+	client, _, err := c.getClientAndConfig(ETH)
+	if err != nil {
+		return err
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(ensABI))
+	if err != nil {
+		return NewWalletError(ErrCodeInvalidABI, "failed to parse ENS ABI", err, ETH)
+	}
+
+	contract := bind.NewBoundContract(contractAddress, parsedABI, client, client, client)
+	if err := contract.Call(&bind.CallOpts{Context: ctx}, out, method, node); err != nil {
+		return err
+	}
+	if len(*out) == 0 {
+		return fmt.Errorf("%s returned nothing", method)
+	}
+	return nil
+}
+
+// cachedENS returns an unexpired cached resolution
+func (c *Client) cachedENS(key string) (string, bool) {
+	value, ok := c.ensCache.Load(key)
+	if !ok {
+		return "", false
+	}
+	entry := value.(ensCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.ensCache.Delete(key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+// cacheENS remembers a resolution for ensCacheTTL
+func (c *Client) cacheENS(key, value string) {
+	c.ensCache.Store(key, ensCacheEntry{value: value, expiresAt: time.Now().Add(ensCacheTTL)})
+}
+
+// ensNamehash computes the ENS namehash of a name (EIP-137)
+func ensNamehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		copy(node[:], crypto.Keccak256(node[:], labelHash))
+	}
+	return node
+}
@@ -38,6 +38,8 @@ const (
 	ErrCodeChainMismatch = "CHAIN_MISMATCH"
 	// ErrCodeGasPrice indicates gas price exceeds maximum allowed
 	ErrCodeGasPrice = "GAS_PRICE_TOO_HIGH"
+	// ErrCodeENSNotFound indicates an ENS name has no resolver or address record
+	ErrCodeENSNotFound = "ENS_NOT_FOUND"
 )
 
 // WalletError represents a wallet-specific error with additional context
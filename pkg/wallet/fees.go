@@ -0,0 +1,279 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GasSpeed selects how aggressively a fee suggestion prices block inclusion
+type GasSpeed string
+
+const (
+	// GasSlow uses the 10th percentile priority fee of recent blocks
+	GasSlow GasSpeed = "slow"
+	// GasStandard uses the median priority fee of recent blocks
+	GasStandard GasSpeed = "standard"
+	// GasFast uses the 90th percentile priority fee of recent blocks
+	GasFast GasSpeed = "fast"
+)
+
+const (
+	// feeHistoryBlocks is how many recent blocks are sampled for fee estimation
+	feeHistoryBlocks = 20
+
+	// defaultGasPollInterval is how often fees are re-estimated while waiting for lower gas
+	defaultGasPollInterval = 15 * time.Second
+
+	// defaultGasMaxWait bounds waiting for lower gas when the strategy sets no limit
+	defaultGasMaxWait = 30 * time.Minute
+)
+
+// feeHistoryPercentiles are the priority fee percentiles requested for slow, standard and fast
+var feeHistoryPercentiles = []float64{10, 50, 90}
+
+// FeeSuggestion is a suggested fee for one speed. For EIP-1559 networks MaxFee is
+// the fee cap and MaxPriorityFee the tip; on legacy networks MaxPriorityFee is nil
+// and MaxFee is the gas price.
+type FeeSuggestion struct {
+	MaxFee         *big.Int
+	MaxPriorityFee *big.Int
+}
+
+// FeeEstimate holds fee suggestions for each speed, derived from recent blocks
+type FeeEstimate struct {
+	// BaseFee is the base fee of the pending block, nil on legacy networks
+	BaseFee   *big.Int
+	Slow      FeeSuggestion
+	Standard  FeeSuggestion
+	Fast      FeeSuggestion
+	Timestamp time.Time
+}
+
+// Suggestion returns the suggestion for the given speed, defaulting to standard
+func (e *FeeEstimate) Suggestion(speed GasSpeed) FeeSuggestion {
+	switch speed {
+	case GasSlow:
+		return e.Slow
+	case GasFast:
+		return e.Fast
+	default:
+		return e.Standard
+	}
+}
+
+// Legacy reports whether the network did not provide EIP-1559 fee data
+func (e *FeeEstimate) Legacy() bool {
+	return e.BaseFee == nil
+}
+
+// EstimateFees samples the priority fees paid in recent blocks using the
+// eth_feeHistory RPC and returns percentile-based slow, standard and fast
+// suggestions. Each max fee allows the base fee to double before inclusion.
+// Networks without fee history fall back to eth_gasPrice for every speed. Tips
+// are never below the network's MinPriorityFee.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Target blockchain network
+//
+// Returns:
+//   - *FeeEstimate: Fee suggestions for each speed
+//   - error: Error if no fee data could be retrieved
+//
+// Example:
+//
+//	estimate, err := client.EstimateFees(ctx, ETH)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fast := estimate.Suggestion(GasFast)
+//	fmt.Printf("Fast max fee: %s wei\n", fast.MaxFee)
+func (c *Client) EstimateFees(ctx context.Context, network NetworkType) (*FeeEstimate, error) {
+	client, config, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := client.FeeHistory(ctx, feeHistoryBlocks, nil, feeHistoryPercentiles)
+	if err != nil || len(history.BaseFee) == 0 || len(history.Reward) == 0 {
+		c.log.WithFields(logrus.Fields{
+			"network": network,
+			"error":   err,
+		}).Debug("Fee history unavailable, falling back to legacy gas price")
+		return c.legacyFeeEstimate(ctx, network)
+	}
+
+	// The last base fee is the one projected for the pending block
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	suggestions := make([]FeeSuggestion, len(feeHistoryPercentiles))
+	for i := range feeHistoryPercentiles {
+		var samples []*big.Int
+		for _, blockRewards := range history.Reward {
+			if i < len(blockRewards) && blockRewards[i] != nil {
+				samples = append(samples, blockRewards[i])
+			}
+		}
+
+		tip := median(samples)
+		if config.MinPriorityFee != nil && tip.Cmp(config.MinPriorityFee) < 0 {
+			tip = new(big.Int).Set(config.MinPriorityFee)
+		}
+		maxFee := new(big.Int).Mul(baseFee, big.NewInt(2))
+		maxFee.Add(maxFee, tip)
+		suggestions[i] = FeeSuggestion{MaxFee: maxFee, MaxPriorityFee: tip}
+	}
+
+	estimate := &FeeEstimate{
+		BaseFee:   baseFee,
+		Slow:      suggestions[0],
+		Standard:  suggestions[1],
+		Fast:      suggestions[2],
+		Timestamp: time.Now(),
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"network":      network,
+		"base_fee":     baseFee.String(),
+		"slow_tip":     estimate.Slow.MaxPriorityFee.String(),
+		"standard_tip": estimate.Standard.MaxPriorityFee.String(),
+		"fast_tip":     estimate.Fast.MaxPriorityFee.String(),
+	}).Debug("Estimated fees from fee history")
+
+	return estimate, nil
+}
+
+// legacyFeeEstimate builds an estimate from eth_gasPrice for networks without fee history
+func (c *Client) legacyFeeEstimate(ctx context.Context, network NetworkType) (*FeeEstimate, error) {
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, NewWalletError(ErrCodeRPCError, "failed to get gas price", err, network)
+	}
+
+	suggestion := FeeSuggestion{MaxFee: gasPrice}
+	return &FeeEstimate{
+		Slow:      suggestion,
+		Standard:  suggestion,
+		Fast:      suggestion,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// SuggestFees returns the fee to use for a transaction under the given strategy.
+// The tip is raised to strategy.PriorityFee when that is higher, and the max fee
+// is capped at strategy.MaxGasPrice. When WaitForLowerGas is set and fees are
+// above the target, it blocks, re-estimating every PollInterval, until fees drop
+// or MaxWait elapses.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - network: Target blockchain network
+//   - strategy: Gas strategy to apply; nil uses DefaultGasStrategy
+//
+// Returns:
+//   - FeeSuggestion: Fee to use for the transaction
+//   - bool: True when the network only supports legacy gas pricing
+//   - error: ErrCodeGasPrice if fees stayed above the target for MaxWait
+func (c *Client) SuggestFees(ctx context.Context, network NetworkType, strategy *GasStrategy) (FeeSuggestion, bool, error) {
+	if strategy == nil {
+		strategy = DefaultGasStrategy()
+	}
+
+	target := strategy.TargetGasPrice
+	if target == nil {
+		target = strategy.MaxGasPrice
+	}
+
+	maxWait := strategy.MaxWait
+	if maxWait <= 0 {
+		maxWait = defaultGasMaxWait
+	}
+	pollInterval := strategy.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultGasPollInterval
+	}
+	deadline := time.Now().Add(maxWait)
+
+	for {
+		estimate, err := c.EstimateFees(ctx, network)
+		if err != nil {
+			return FeeSuggestion{}, false, err
+		}
+		suggestion := estimate.Suggestion(strategy.Speed)
+
+		// Compare the uncapped fee: capping at MaxGasPrice would always pass the target
+		if !strategy.WaitForLowerGas || target == nil || suggestion.MaxFee.Cmp(target) <= 0 {
+			return applyGasStrategy(suggestion, strategy), estimate.Legacy(), nil
+		}
+
+		if time.Now().After(deadline) {
+			return FeeSuggestion{}, false, NewWalletError(ErrCodeGasPrice,
+				fmt.Sprintf("gas price stayed above %s wei for %s", target, maxWait), nil, network)
+		}
+
+		c.log.WithFields(logrus.Fields{
+			"network":   network,
+			"max_fee":   suggestion.MaxFee.String(),
+			"target":    target.String(),
+			"speed":     strategy.Speed,
+			"retry_in":  pollInterval,
+			"wait_left": time.Until(deadline).Round(time.Second),
+		}).Info("Gas above target, holding transaction")
+
+		select {
+		case <-ctx.Done():
+			return FeeSuggestion{}, false, NewWalletError(ErrCodeTimeout, "context cancelled while waiting for lower gas", ctx.Err(), network)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// applyGasStrategy raises the tip to the strategy's priority fee and caps the max fee
+func applyGasStrategy(suggestion FeeSuggestion, strategy *GasStrategy) FeeSuggestion {
+	result := FeeSuggestion{MaxFee: new(big.Int).Set(suggestion.MaxFee)}
+	if suggestion.MaxPriorityFee != nil {
+		result.MaxPriorityFee = new(big.Int).Set(suggestion.MaxPriorityFee)
+		if strategy.PriorityFee != nil && result.MaxPriorityFee.Cmp(strategy.PriorityFee) < 0 {
+			result.MaxFee.Add(result.MaxFee, new(big.Int).Sub(strategy.PriorityFee, result.MaxPriorityFee))
+			result.MaxPriorityFee.Set(strategy.PriorityFee)
+		}
+	}
+
+	if strategy.MaxGasPrice != nil && result.MaxFee.Cmp(strategy.MaxGasPrice) > 0 {
+		result.MaxFee.Set(strategy.MaxGasPrice)
+	}
+	if result.MaxPriorityFee != nil && result.MaxPriorityFee.Cmp(result.MaxFee) > 0 {
+		result.MaxPriorityFee.Set(result.MaxFee)
+	}
+	return result
+}
+
+// median returns the median of values, or zero for an empty slice
+func median(values []*big.Int) *big.Int {
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+
+	sorted := make([]*big.Int, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return new(big.Int).Set(sorted[mid])
+	}
+	sum := new(big.Int).Add(sorted[mid-1], sorted[mid])
+	return sum.Div(sum, big.NewInt(2))
+}
@@ -4,6 +4,7 @@ package wallet
 
 import (
 	"math/big"
+	"time"
 )
 
 // GasStrategy defines parameters for gas price management and transaction retry behavior.
@@ -19,8 +20,22 @@ type GasStrategy struct {
 	// RetryOnHighGas indicates whether to retry transactions when gas price exceeds MaxGasPrice
 	RetryOnHighGas bool
 
-	// WaitForLowerGas indicates whether to wait for gas prices to decrease before retrying
+	// WaitForLowerGas indicates whether to wait for gas prices to decrease before retrying.
+	// Transactions are held until the suggested max fee drops to TargetGasPrice
+	// (or MaxGasPrice when no target is set), for at most MaxWait.
 	WaitForLowerGas bool
+
+	// Speed selects the fee percentile used for pricing (slow, standard or fast)
+	Speed GasSpeed
+
+	// TargetGasPrice is the max fee in wei a waiting transaction is released at
+	TargetGasPrice *big.Int
+
+	// MaxWait bounds how long a transaction waits for lower gas
+	MaxWait time.Duration
+
+	// PollInterval is how often fees are re-estimated while waiting
+	PollInterval time.Duration
 }
 
 // DefaultGasStrategy returns a default gas strategy with conservative settings.
@@ -28,7 +43,8 @@ type GasStrategy struct {
 //   - MaxGasPrice of 100 gwei to prevent overpaying for transactions
 //   - PriorityFee of 1.5 gwei to incentivize validators
 //   - Retry enabled when gas prices are high
-//   - Waiting enabled for gas prices to decrease
+//   - Waiting enabled for gas prices to decrease, for up to 30 minutes
+//   - Standard speed (median priority fee of recent blocks)
 //
 // Example usage:
 //
@@ -40,5 +56,8 @@ func DefaultGasStrategy() *GasStrategy {
 		PriorityFee:     big.NewInt(1500000000),   // 1.5 gwei
 		RetryOnHighGas:  true,
 		WaitForLowerGas: true,
+		Speed:           GasStandard,
+		MaxWait:         30 * time.Minute,
+		PollInterval:    15 * time.Second,
 	}
 }
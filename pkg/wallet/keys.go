@@ -6,8 +6,10 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
 // KeyManager handles wallet private keys and provides functionality for key management,
@@ -86,3 +88,44 @@ func (km *KeyManager) GetAddress() common.Address {
 func (km *KeyManager) Sign(data []byte) ([]byte, error) {
 	return crypto.Sign(crypto.Keccak256Hash(data).Bytes(), km.privateKey)
 }
+
+// SignMessage signs a message the way personal_sign does (EIP-191), as used by
+// sign-in challenges. The recovery byte is 27 or 28, as wallets return it.
+//
+// Parameters:
+//   - message: Message bytes to sign, without the EIP-191 prefix
+//
+// Returns:
+//   - []byte: The 65-byte signature
+//   - error: Error if signing fails
+func (km *KeyManager) SignMessage(message []byte) ([]byte, error) {
+	return km.signHash(accounts.TextHash(message))
+}
+
+// SignTypedData signs structured data as eth_signTypedData_v4 does (EIP-712).
+// The recovery byte is 27 or 28, as wallets return it.
+//
+// Parameters:
+//   - typedData: Types, domain and message to sign
+//
+// Returns:
+//   - []byte: The 65-byte signature
+//   - error: Error if the data cannot be hashed or signing fails
+func (km *KeyManager) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	return km.signHash(hash)
+}
+
+// signHash signs a 32-byte hash and shifts the recovery byte into the 27/28
+// range expected by contracts and off-chain verifiers
+func (km *KeyManager) signHash(hash []byte) ([]byte, error) {
+	signature, err := crypto.Sign(hash, km.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
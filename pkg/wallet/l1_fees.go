@@ -0,0 +1,143 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// opGasPriceOracle is the OP Stack predeploy quoting the L1 data fee
+	opGasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+	// arbitrumNodeInterface is Arbitrum's virtual contract for gas estimation helpers
+	arbitrumNodeInterface = common.HexToAddress("0x00000000000000000000000000000000000000C8")
+)
+
+// l1FeeABI covers the OP Stack oracle's getL1Fee and Arbitrum's gasEstimateL1Component
+const l1FeeABI = `[
+	{
+		"constant": true,
+		"inputs": [{"name": "_data", "type": "bytes"}],
+		"name": "getL1Fee",
+		"outputs": [{"name": "", "type": "uint256"}],
+		"type": "function"
+	},
+	{
+		"constant": false,
+		"inputs": [
+			{"name": "to", "type": "address"},
+			{"name": "contractCreation", "type": "bool"},
+			{"name": "data", "type": "bytes"}
+		],
+		"name": "gasEstimateL1Component",
+		"outputs": [
+			{"name": "gasEstimateForL1", "type": "uint64"},
+			{"name": "baseFee", "type": "uint256"},
+			{"name": "l1BaseFeeEstimate", "type": "uint256"}
+		],
+		"type": "function"
+	}
+]`
+
+// EstimateL1Fee returns the part of a transaction's cost, in wei, that pays for
+// posting it to Ethereum. On OP Stack networks this fee is charged on top of
+// the L2 gas; on Arbitrum it is already part of the gas limit, and the share is
+// reported for display. Networks without an L1 fee return zero.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Target blockchain network
+//   - to: Recipient address
+//   - data: Transaction data payload
+//
+// Returns:
+//   - *big.Int: L1 fee in wei
+//   - error: Error if the fee could not be quoted
+//
+// Example:
+//
+//	l1Fee, err := client.EstimateL1Fee(ctx, OPTIMISM, tokenAddr, data)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("L1 data fee: %s wei\n", l1Fee)
+func (c *Client) EstimateL1Fee(ctx context.Context, network NetworkType, to common.Address, data []byte) (*big.Int, error) {
+	client, config, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+	if config.L1Fee == L1FeeNone {
+		return new(big.Int), nil
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(l1FeeABI))
+	if err != nil {
+		return nil, NewWalletError(ErrCodeInvalidABI, "failed to parse L1 fee ABI", err, network)
+	}
+
+	switch config.L1Fee {
+	case L1FeeOPStack:
+		// The oracle prices the serialized transaction; an unsigned one is close
+		// enough, as the oracle accounts for the signature itself
+		unsigned, err := types.NewTx(&types.DynamicFeeTx{
+			ChainID: big.NewInt(config.ChainID),
+			To:      &to,
+			Data:    data,
+		}).MarshalBinary()
+		if err != nil {
+			return nil, NewWalletError(ErrCodeGasEstimationFailed, "failed to serialize transaction", err, network)
+		}
+		out, err := c.callL1FeeContract(ctx, client, parsedABI, opGasPriceOracle, "getL1Fee", unsigned)
+		if err != nil {
+			return nil, NewWalletError(ErrCodeGasEstimationFailed, "failed to quote L1 data fee", err, network)
+		}
+		fee, _ := out[0].(*big.Int)
+		if fee == nil {
+			fee = new(big.Int)
+		}
+		return fee, nil
+
+	case L1FeeArbitrum:
+		out, err := c.callL1FeeContract(ctx, client, parsedABI, arbitrumNodeInterface, "gasEstimateL1Component", to, false, data)
+		if err != nil || len(out) < 2 {
+			return nil, NewWalletError(ErrCodeGasEstimationFailed, "failed to estimate L1 gas component", err, network)
+		}
+		gasForL1, _ := out[0].(uint64)
+		baseFee, _ := out[1].(*big.Int)
+		if baseFee == nil {
+			return new(big.Int), nil
+		}
+		return new(big.Int).Mul(new(big.Int).SetUint64(gasForL1), baseFee), nil
+
+	default:
+		return nil, NewWalletError(ErrCodeInvalidNetwork, "unknown L1 fee model "+string(config.L1Fee), nil, network)
+	}
+}
+
+// callL1FeeContract calls a fee helper with eth_call and decodes its outputs
+func (c *Client) callL1FeeContract(ctx context.Context, client ethereum.ContractCaller, parsedABI abi.ABI, contract common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	input, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{From: c.keyManager.GetAddress(), To: &contract, Data: input}, nil)
+	if err != nil {
+		return nil, err
+	}
+	out, err := parsedABI.Unpack(method, output)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ethereum.NotFound
+	}
+	return out, nil
+}
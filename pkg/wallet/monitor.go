@@ -0,0 +1,483 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultMonitorInterval is how often tracked transactions are checked
+	defaultMonitorInterval = 30 * time.Second
+
+	// defaultBumpPercent raises the fee of a replacement transaction. Nodes require
+	// at least 10% to accept a replacement for the same nonce.
+	defaultBumpPercent = 15
+
+	// defaultMaxReplacements limits how often one nonce is re-priced automatically
+	defaultMaxReplacements = 5
+
+	// cancelGasLimit is the gas used by a plain value transfer
+	cancelGasLimit = 21000
+)
+
+// MonitorOptions configures the transaction monitor
+type MonitorOptions struct {
+	// Interval is how often tracked transactions are checked
+	Interval time.Duration
+
+	// BumpPercent is how much the fee is raised on each replacement
+	BumpPercent int64
+
+	// MaxReplacements is how many times a stuck transaction is re-priced before giving up
+	MaxReplacements int
+}
+
+// trackedKey identifies a tracked transaction by network and nonce, since every
+// replacement keeps the nonce but changes the hash
+type trackedKey struct {
+	network NetworkType
+	nonce   uint64
+}
+
+// trackedTransaction is a transaction sent by this client that has not been mined yet
+type trackedTransaction struct {
+	network      NetworkType
+	tx           *types.Transaction // latest signed version
+	hashes       []common.Hash      // every version sent, oldest first
+	sentAt       time.Time          // when the latest version was sent
+	replacements int
+}
+
+// track records a sent transaction so the monitor can follow and replace it
+func (c *Client) track(network NetworkType, tx *types.Transaction) {
+	c.nonceManager.markSent(network, tx.Nonce(), tx.Hash())
+
+	c.trackedMu.Lock()
+	defer c.trackedMu.Unlock()
+
+	key := trackedKey{network: network, nonce: tx.Nonce()}
+	if tracked, ok := c.tracked[key]; ok {
+		tracked.tx = tx
+		tracked.hashes = append(tracked.hashes, tx.Hash())
+		tracked.sentAt = time.Now()
+		return
+	}
+
+	c.tracked[key] = &trackedTransaction{
+		network: network,
+		tx:      tx,
+		hashes:  []common.Hash{tx.Hash()},
+		sentAt:  time.Now(),
+	}
+}
+
+// findTracked returns the tracked transaction any of whose versions has the given hash
+func (c *Client) findTracked(hash common.Hash) (trackedKey, *trackedTransaction, bool) {
+	c.trackedMu.Lock()
+	defer c.trackedMu.Unlock()
+
+	for key, tracked := range c.tracked {
+		for _, h := range tracked.hashes {
+			if h == hash {
+				return key, tracked, true
+			}
+		}
+	}
+	return trackedKey{}, nil, false
+}
+
+// untrack stops following a transaction once its nonce has been used. It
+// returns false when the transaction was no longer tracked, e.g. because the
+// monitor and WaitForReceipt saw the receipt at the same time.
+func (c *Client) untrack(key trackedKey) bool {
+	c.trackedMu.Lock()
+	_, ok := c.tracked[key]
+	delete(c.tracked, key)
+	c.trackedMu.Unlock()
+
+	c.nonceManager.mined(key.network, key.nonce)
+	return ok
+}
+
+// candidateHashes returns every version of the transaction with the given hash,
+// newest first, so a receipt is found whichever version was mined
+func (c *Client) candidateHashes(hash common.Hash) []common.Hash {
+	_, tracked, ok := c.findTracked(hash)
+	if !ok {
+		return []common.Hash{hash}
+	}
+
+	c.trackedMu.Lock()
+	defer c.trackedMu.Unlock()
+	hashes := make([]common.Hash, 0, len(tracked.hashes))
+	for i := len(tracked.hashes) - 1; i >= 0; i-- {
+		hashes = append(hashes, tracked.hashes[i])
+	}
+	return hashes
+}
+
+// StartMonitor runs the transaction monitor until ctx is cancelled. It checks every
+// transaction sent by this client, and when one is dropped from the mempool or
+// pending for too long (see TransactionStatus.NeedsResubmission) it re-signs it
+// with a bumped fee using the same nonce. Once a transaction is mined a
+// wallet_transfer_completed event is published with its gas used and explorer link.
+//
+// Parameters:
+//   - ctx: Context controlling the monitor's lifetime
+//   - opts: Monitor options; zero values use defaults
+//
+// Example:
+//
+//	go client.StartMonitor(ctx, MonitorOptions{Interval: time.Minute})
+func (c *Client) StartMonitor(ctx context.Context, opts MonitorOptions) {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultMonitorInterval
+	}
+	if opts.BumpPercent <= 0 {
+		opts.BumpPercent = defaultBumpPercent
+	}
+	if opts.MaxReplacements <= 0 {
+		opts.MaxReplacements = defaultMaxReplacements
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	c.log.WithField("interval", opts.Interval).Debug("Starting transaction monitor")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkTracked(ctx, opts)
+		}
+	}
+}
+
+// checkTracked inspects each tracked transaction once
+func (c *Client) checkTracked(ctx context.Context, opts MonitorOptions) {
+	c.trackedMu.Lock()
+	keys := make([]trackedKey, 0, len(c.tracked))
+	for key := range c.tracked {
+		keys = append(keys, key)
+	}
+	c.trackedMu.Unlock()
+
+	for _, key := range keys {
+		if err := c.checkTransaction(ctx, key, opts); err != nil {
+			c.log.WithError(err).WithFields(logrus.Fields{
+				"network": key.network,
+				"nonce":   key.nonce,
+			}).Warn("Failed to check tracked transaction")
+		}
+	}
+}
+
+// checkTransaction updates one tracked transaction and replaces it if it is stuck
+func (c *Client) checkTransaction(ctx context.Context, key trackedKey, opts MonitorOptions) error {
+	client, _, err := c.getClientAndConfig(key.network)
+	if err != nil {
+		return err
+	}
+
+	c.trackedMu.Lock()
+	tracked, ok := c.tracked[key]
+	if !ok {
+		c.trackedMu.Unlock()
+		return nil
+	}
+	latest := tracked.tx
+	hashes := append([]common.Hash(nil), tracked.hashes...)
+	sentAt := tracked.sentAt
+	replacements := tracked.replacements
+	c.trackedMu.Unlock()
+
+	log := c.log.WithFields(logrus.Fields{
+		"network": key.network,
+		"nonce":   key.nonce,
+		"hash":    latest.Hash().Hex(),
+	})
+
+	// Done once any version has been mined
+	for _, hash := range hashes {
+		if receipt, err := client.TransactionReceipt(ctx, hash); err == nil {
+			return c.completeTracked(ctx, client, key, hashes[0], receipt)
+		}
+	}
+
+	// Done if the nonce was used by a transaction we did not send through this client
+	confirmedNonce, err := client.NonceAt(ctx, c.keyManager.GetAddress(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to get account nonce: %w", err)
+	}
+	if confirmedNonce > key.nonce {
+		log.Info("Nonce consumed by another transaction, no longer tracking")
+		c.untrack(key)
+		return nil
+	}
+
+	status := &TransactionStatus{
+		Hash:      latest.Hash(),
+		State:     TxStatePending,
+		Timestamp: sentAt,
+	}
+	if _, _, err := client.TransactionByHash(ctx, latest.Hash()); errors.Is(err, ethereum.NotFound) {
+		status.State = TxStateDropped
+	}
+
+	if !status.NeedsResubmission() {
+		return nil
+	}
+
+	if replacements >= opts.MaxReplacements {
+		log.WithField("replacements", replacements).Warn("Transaction still stuck after maximum replacements")
+		return nil
+	}
+
+	log.WithFields(logrus.Fields{
+		"dropped":      status.State == TxStateDropped,
+		"pending_for":  time.Since(sentAt).Round(time.Second),
+		"replacements": replacements,
+	}).Warn("Transaction stuck, resubmitting with higher fee")
+
+	newHash, err := c.replace(ctx, key.network, latest, latest.To(), latest.Value(), latest.Data(), latest.Gas(), opts.BumpPercent)
+	if err != nil {
+		return err
+	}
+
+	c.trackedMu.Lock()
+	if tracked, ok := c.tracked[key]; ok {
+		tracked.replacements++
+	}
+	c.trackedMu.Unlock()
+
+	log.WithField("new_hash", newHash.Hex()).Info("Replacement transaction sent")
+	return nil
+}
+
+// completeTracked stops tracking a mined transaction once it has the minimum
+// confirmations and publishes its receipt, so confirmations are reported for
+// transactions nobody waits on
+func (c *Client) completeTracked(ctx context.Context, client *ethclient.Client, key trackedKey, sentHash common.Hash, receipt *types.Receipt) error {
+	currentBlock, err := client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current block number: %w", err)
+	}
+	confirmations := currentBlock - receipt.BlockNumber.Uint64()
+	if confirmations < minConfirmations {
+		return nil // Checked again on the next tick
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"network":    key.network,
+		"nonce":      key.nonce,
+		"mined_hash": receipt.TxHash.Hex(),
+		"gas_used":   receipt.GasUsed,
+	}).Debug("Tracked transaction mined")
+	if !c.untrack(key) {
+		return nil // WaitForReceipt published it
+	}
+
+	c.publishTransferCompleted(key.network, sentHash, &TransactionStatus{
+		Hash:              receipt.TxHash,
+		Status:            receipt.Status,
+		BlockNumber:       receipt.BlockNumber,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+		Confirmations:     confirmations,
+		State:             TxStateConfirmed,
+		Timestamp:         time.Now(),
+	})
+	return nil
+}
+
+// SpeedUpTransaction re-sends a pending transaction with the same nonce and a fee
+// raised by the default bump, returning the hash of the replacement.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Network the transaction was sent on
+//   - hash: Hash of any version of the pending transaction
+//
+// Returns:
+//   - common.Hash: Hash of the replacement transaction
+//   - error: Error if the transaction is not pending or cannot be replaced
+func (c *Client) SpeedUpTransaction(ctx context.Context, network NetworkType, hash common.Hash) (common.Hash, error) {
+	original, err := c.pendingTransaction(ctx, network, hash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return c.replace(ctx, network, original, original.To(), original.Value(), original.Data(), original.Gas(), defaultBumpPercent)
+}
+
+// CancelTransaction replaces a pending transaction with a zero-value transfer to
+// the wallet's own address using the same nonce and a bumped fee. Once the
+// replacement is mined the original can no longer be included.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Network the transaction was sent on
+//   - hash: Hash of any version of the pending transaction
+//
+// Returns:
+//   - common.Hash: Hash of the cancelling transaction
+//   - error: Error if the transaction is not pending or cannot be replaced
+//
+// Example:
+//
+//	cancelHash, err := client.CancelTransaction(ctx, ETH, stuckHash)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	status, err := client.WaitForReceipt(ctx, ETH, cancelHash)
+func (c *Client) CancelTransaction(ctx context.Context, network NetworkType, hash common.Hash) (common.Hash, error) {
+	original, err := c.pendingTransaction(ctx, network, hash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	self := c.keyManager.GetAddress()
+	return c.replace(ctx, network, original, &self, big.NewInt(0), nil, cancelGasLimit, defaultBumpPercent)
+}
+
+// pendingTransaction returns the latest version of a pending transaction sent from
+// this wallet, from the tracked set or, failing that, from the node's mempool
+func (c *Client) pendingTransaction(ctx context.Context, network NetworkType, hash common.Hash) (*types.Transaction, error) {
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range c.candidateHashes(hash) {
+		if _, err := client.TransactionReceipt(ctx, candidate); err == nil {
+			return nil, NewWalletError(ErrCodeTransactionFailed, "transaction already mined", nil, network)
+		}
+	}
+
+	if _, tracked, ok := c.findTracked(hash); ok && tracked.network == network {
+		c.trackedMu.Lock()
+		defer c.trackedMu.Unlock()
+		return tracked.tx, nil
+	}
+
+	tx, isPending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, NewWalletError(ErrCodeReceiptNotFound, "transaction not found", err, network)
+	}
+	if !isPending {
+		return nil, NewWalletError(ErrCodeTransactionFailed, "transaction already mined", nil, network)
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil || sender != c.keyManager.GetAddress() {
+		return nil, NewWalletError(ErrCodeInvalidAddress, "transaction was not sent from this wallet", err, network)
+	}
+	return tx, nil
+}
+
+// replace signs and sends a transaction with the original's nonce and a fee at
+// least bumpPercent higher than the original, or the current suggestion if that
+// is higher. The replacement is tracked in place of the original.
+func (c *Client) replace(
+	ctx context.Context,
+	network NetworkType,
+	original *types.Transaction,
+	to *common.Address,
+	value *big.Int,
+	data []byte,
+	gasLimit uint64,
+	bumpPercent int64,
+) (common.Hash, error) {
+	if to == nil {
+		return common.Hash{}, NewWalletError(ErrCodeTransactionFailed, "cannot replace contract creation", nil, network)
+	}
+
+	client, config, err := c.getClientAndConfig(network)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	estimate, err := c.EstimateFees(ctx, network)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	suggested := estimate.Suggestion(GasFast)
+
+	legacy := original.Type() == types.LegacyTxType || estimate.Legacy()
+	fee := FeeSuggestion{
+		MaxFee: maxBig(bump(original.GasFeeCap(), bumpPercent), suggested.MaxFee),
+	}
+	if !legacy {
+		tip := suggested.MaxPriorityFee
+		if tip == nil {
+			tip = new(big.Int)
+		}
+		fee.MaxPriorityFee = maxBig(bump(original.GasTipCap(), bumpPercent), tip)
+		if fee.MaxPriorityFee.Cmp(fee.MaxFee) > 0 {
+			fee.MaxFee = new(big.Int).Set(fee.MaxPriorityFee)
+		}
+	}
+
+	if config.MaxGasPrice != nil && fee.MaxFee.Cmp(config.MaxGasPrice) > 0 {
+		return common.Hash{}, NewWalletError(ErrCodeGasPrice,
+			fmt.Sprintf("replacement fee %s wei exceeds maximum %s wei", fee.MaxFee, config.MaxGasPrice), nil, network)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	tx := newTransaction(chainID, original.Nonce(), *to, value, gasLimit, data, fee, legacy)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), c.keyManager.privateKey)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign replacement transaction: %w", err)
+	}
+
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, NewWalletError(ErrCodeTransactionFailed, "failed to send replacement transaction", err, network)
+	}
+
+	c.track(network, signedTx)
+
+	c.log.WithFields(logrus.Fields{
+		"network":      network,
+		"nonce":        original.Nonce(),
+		"original":     original.Hash().Hex(),
+		"replacement":  signedTx.Hash().Hex(),
+		"max_fee":      fee.MaxFee.String(),
+		"priority_fee": fee.MaxPriorityFee,
+	}).Debug("Sent replacement transaction")
+
+	return signedTx.Hash(), nil
+}
+
+// bump raises value by percent, rounding up
+func bump(value *big.Int, percent int64) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	bumped := new(big.Int).Mul(value, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// maxBig returns the larger of a and b
+func maxBig(a, b *big.Int) *big.Int {
+	if b == nil || (a != nil && a.Cmp(b) >= 0) {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,185 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var (
+	// registeredNetworks holds the built-in networks and those added with RegisterNetwork
+	registeredNetworks   = make(map[NetworkType]NetworkConfig)
+	registeredNetworksMu sync.RWMutex
+)
+
+func init() {
+	for _, config := range DefaultNetworkConfigs() {
+		registeredNetworks[config.Type] = config
+	}
+}
+
+// RegisterNetwork adds an EVM network, or replaces the defaults of a built-in
+// one, so it can be used like the built-in networks without code changes: its
+// config supplies the defaults NewClient fills in, and addresses on it validate.
+//
+// Parameters:
+//   - config: Network defaults; Type and ChainID are required
+//
+// Returns:
+//   - error: Error if the config is incomplete or its chain ID belongs to another network
+//
+// Example:
+//
+//	err := wallet.RegisterNetwork(wallet.NetworkConfig{
+//	    Type:               "LINEA",
+//	    RPCURL:             "https://rpc.linea.build",
+//	    ChainID:            59144,
+//	    GasLimitMultiplier: 1.2,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client, err := wallet.NewClient(ctx, logger, []wallet.NetworkConfig{{Type: "LINEA"}}, privateKey)
+func RegisterNetwork(config NetworkConfig) error {
+	config.Type = NetworkType(strings.ToUpper(strings.TrimSpace(string(config.Type))))
+	if config.Type == "" {
+		return NewWalletError(ErrCodeInvalidNetwork, "network type is required", nil, "")
+	}
+	if config.ChainID <= 0 {
+		return NewWalletError(ErrCodeInvalidNetwork, "chain ID is required", nil, config.Type)
+	}
+
+	registeredNetworksMu.Lock()
+	defer registeredNetworksMu.Unlock()
+
+	for network, existing := range registeredNetworks {
+		if network != config.Type && existing.ChainID == config.ChainID {
+			return NewWalletError(ErrCodeChainMismatch,
+				fmt.Sprintf("chain ID %d is already registered as %s", config.ChainID, network), nil, config.Type)
+		}
+	}
+	registeredNetworks[config.Type] = config
+	return nil
+}
+
+// RegisteredNetwork returns the defaults of a built-in or registered network
+//
+// Parameters:
+//   - network: Network to look up
+//
+// Returns:
+//   - NetworkConfig: The network's defaults
+//   - bool: false if the network is unknown
+func RegisteredNetwork(network NetworkType) (NetworkConfig, bool) {
+	registeredNetworksMu.RLock()
+	defer registeredNetworksMu.RUnlock()
+	config, ok := registeredNetworks[network]
+	return config, ok
+}
+
+// RegisteredNetworks returns the built-in and registered networks, sorted by name
+func RegisteredNetworks() []NetworkType {
+	registeredNetworksMu.RLock()
+	defer registeredNetworksMu.RUnlock()
+
+	networks := make([]NetworkType, 0, len(registeredNetworks))
+	for network := range registeredNetworks {
+		networks = append(networks, network)
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i] < networks[j] })
+	return networks
+}
+
+// withNetworkDefaults fills the empty fields of config from its network's
+// registered defaults. A network that was never registered can still be used
+// with a complete config.
+func withNetworkDefaults(config NetworkConfig) NetworkConfig {
+	defaults, _ := RegisteredNetwork(config.Type)
+
+	if config.RPCURL == "" {
+		config.RPCURL = defaults.RPCURL
+	}
+	if config.ChainID == 0 {
+		config.ChainID = defaults.ChainID
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaults.MaxRetries
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	if config.GasLimitMultiplier == 0 {
+		config.GasLimitMultiplier = defaults.GasLimitMultiplier
+	}
+	if config.MaxGasPrice == nil {
+		config.MaxGasPrice = defaults.MaxGasPrice
+	}
+	if config.NativeSymbol == "" {
+		config.NativeSymbol = defaults.NativeSymbol
+	}
+	if config.MinPriorityFee == nil {
+		config.MinPriorityFee = defaults.MinPriorityFee
+	}
+	if config.L1Fee == L1FeeNone {
+		config.L1Fee = defaults.L1Fee
+	}
+	if config.DisperseAddress == (common.Address{}) {
+		config.DisperseAddress = defaults.DisperseAddress
+	}
+	if config.ExplorerURL == "" {
+		config.ExplorerURL = defaults.ExplorerURL
+	}
+
+	// Without a multiplier every gas estimate would be zero
+	if config.GasLimitMultiplier <= 0 {
+		config.GasLimitMultiplier = 1
+	}
+	if config.NativeSymbol == "" {
+		config.NativeSymbol = "ETH"
+	}
+	return config
+}
+
+// CheckNetwork connects to a network's RPC endpoint once, without a key and
+// without retries, and verifies that it serves the expected chain.
+//
+// Parameters:
+//   - ctx: Context bounding the connection
+//   - config: Network to check; empty fields take the registered defaults
+//
+// Returns:
+//   - uint64: The endpoint's latest block number
+//   - error: Error if the endpoint is unreachable or serves another chain
+func CheckNetwork(ctx context.Context, config NetworkConfig) (uint64, error) {
+	config = withNetworkDefaults(config)
+
+	client, err := ethclient.DialContext(ctx, config.RPCURL)
+	if err != nil {
+		return 0, NewWalletError(ErrCodeRPCError, "failed to connect to network", err, config.Type)
+	}
+	defer client.Close()
+
+	if config.ChainID != 0 {
+		chainID, err := client.ChainID(ctx)
+		if err != nil {
+			return 0, NewWalletError(ErrCodeRPCError, "failed to get chain ID", err, config.Type)
+		}
+		if chainID.Int64() != config.ChainID {
+			return 0, NewWalletError(ErrCodeChainMismatch,
+				fmt.Sprintf("RPC endpoint serves chain %s, expected %d", chainID, config.ChainID), nil, config.Type)
+		}
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, NewWalletError(ErrCodeRPCError, "failed to get block number", err, config.Type)
+	}
+	return head, nil
+}
@@ -6,16 +6,55 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
 )
 
+// nonceStoreTimeout bounds persistence calls made outside a caller's context
+const nonceStoreTimeout = 5 * time.Second
+
+// NonceRecord is a nonce issued to a transaction from the wallet. Records are
+// persisted so pending nonces survive a restart.
+type NonceRecord struct {
+	Network NetworkType
+	Address common.Address
+	Nonce   uint64
+
+	// TxHash is the latest version sent with the nonce; zero while the nonce
+	// is only reserved
+	TxHash common.Hash
+
+	// IssuedAt is when the nonce was reserved
+	IssuedAt time.Time
+
+	// SentAt is when TxHash was sent
+	SentAt time.Time
+}
+
+// NonceStore persists issued nonces until their transactions are mined
+type NonceStore interface {
+	// SaveNonce inserts or updates the record for its network, address and nonce
+	SaveNonce(ctx context.Context, record NonceRecord) error
+	// DeleteNonce removes the record of a nonce
+	DeleteNonce(ctx context.Context, network NetworkType, address common.Address, nonce uint64) error
+	// ListNonces returns the records of an address on a network, lowest nonce first
+	ListNonces(ctx context.Context, network NetworkType, address common.Address) ([]NonceRecord, error)
+}
+
 // NonceManager manages transaction nonces across different blockchain networks.
 // It tracks used and pending nonces to prevent nonce conflicts and ensure proper
 // transaction ordering. The manager is thread-safe and handles concurrent access
-// through mutex locking.
+// through mutex locking. With a store set, issued nonces are also persisted.
 type NonceManager struct {
 	nonces        map[NetworkType]uint64               // Tracks the latest nonce per network
 	pendingNonces map[NetworkType]map[uint64]time.Time // Tracks pending nonces and when they were issued
+	sentNonces    map[NetworkType]map[uint64]bool      // Pending nonces a transaction was sent with
 	mu            sync.RWMutex                         // Mutex for thread-safe access
+
+	store   NonceStore
+	address common.Address
+	log     *logrus.Logger
 }
 
 // newNonceManager creates a new nonce manager instance.
@@ -27,6 +66,7 @@ func newNonceManager() *NonceManager {
 	return &NonceManager{
 		nonces:        make(map[NetworkType]uint64),
 		pendingNonces: make(map[NetworkType]map[uint64]time.Time),
+		sentNonces:    make(map[NetworkType]map[uint64]bool),
 	}
 }
 
@@ -66,7 +106,9 @@ func (nm *NonceManager) GetNonce(ctx context.Context, client *Client, network Ne
 	// Find the next available nonce
 	for {
 		if _, isPending := nm.pendingNonces[network][nonce]; !isPending {
-			nm.pendingNonces[network][nonce] = time.Now()
+			issuedAt := time.Now()
+			nm.pendingNonces[network][nonce] = issuedAt
+			nm.persist(NonceRecord{Network: network, Nonce: nonce, IssuedAt: issuedAt})
 			return nonce, nil
 		}
 		nonce++
@@ -75,6 +117,8 @@ func (nm *NonceManager) GetNonce(ctx context.Context, client *Client, network Ne
 
 // ReleaseNonce releases a previously used nonce, making it available for reuse.
 // This should be called after a transaction is confirmed or fails permanently.
+// The persisted record of a nonce no transaction was sent with is removed; the
+// record of a sent one stays until its transaction is mined.
 //
 // Parameters:
 //   - network: Network the nonce was used on
@@ -86,4 +130,84 @@ func (nm *NonceManager) ReleaseNonce(network NetworkType, nonce uint64) {
 	if nm.pendingNonces[network] != nil {
 		delete(nm.pendingNonces[network], nonce)
 	}
+	if nm.sentNonces[network][nonce] {
+		delete(nm.sentNonces[network], nonce)
+		return
+	}
+	nm.forget(network, nonce)
+}
+
+// setStore makes the manager persist the nonces it issues for address
+func (nm *NonceManager) setStore(store NonceStore, address common.Address, log *logrus.Logger) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.store = store
+	nm.address = address
+	nm.log = log
+}
+
+// reserve marks a nonce recovered from the store as pending, so GetNonce skips
+// it while its transaction waits behind a gap
+func (nm *NonceManager) reserve(network NetworkType, nonce uint64, issuedAt time.Time) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	if nm.pendingNonces[network] == nil {
+		nm.pendingNonces[network] = make(map[uint64]time.Time)
+	}
+	nm.pendingNonces[network][nonce] = issuedAt
+}
+
+// markSent persists the transaction sent with a nonce
+func (nm *NonceManager) markSent(network NetworkType, nonce uint64, hash common.Hash) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	issuedAt, ok := nm.pendingNonces[network][nonce]
+	if ok {
+		if nm.sentNonces[network] == nil {
+			nm.sentNonces[network] = make(map[uint64]bool)
+		}
+		nm.sentNonces[network][nonce] = true
+	} else {
+		issuedAt = time.Now()
+	}
+	nm.persist(NonceRecord{Network: network, Nonce: nonce, TxHash: hash, IssuedAt: issuedAt, SentAt: time.Now()})
+}
+
+// mined removes the persisted record of a nonce whose transaction was mined
+func (nm *NonceManager) mined(network NetworkType, nonce uint64) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.forget(network, nonce)
+}
+
+// persist saves a record if a store is set. Failures are logged rather than
+// returned: losing a record must not stop a transaction. Callers hold nm.mu.
+func (nm *NonceManager) persist(record NonceRecord) {
+	if nm.store == nil {
+		return
+	}
+	record.Address = nm.address
+
+	ctx, cancel := context.WithTimeout(context.Background(), nonceStoreTimeout)
+	defer cancel()
+	if err := nm.store.SaveNonce(ctx, record); err != nil {
+		nm.log.WithError(err).WithField("network", record.Network).WithField("nonce", record.Nonce).
+			Warn("Failed to persist nonce")
+	}
+}
+
+// forget deletes the persisted record of a nonce if a store is set. Callers hold nm.mu.
+func (nm *NonceManager) forget(network NetworkType, nonce uint64) {
+	if nm.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nonceStoreTimeout)
+	defer cancel()
+	if err := nm.store.DeleteNonce(ctx, network, nm.address, nonce); err != nil {
+		nm.log.WithError(err).WithField("network", network).WithField("nonce", nonce).
+			Warn("Failed to delete persisted nonce")
+	}
 }
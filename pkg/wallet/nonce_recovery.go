@@ -0,0 +1,304 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+// NonceReport compares the nonces the wallet issued on a network with the chain
+type NonceReport struct {
+	Network NetworkType `json:"network"`
+
+	// ChainNonce is the number of mined transactions from the wallet
+	ChainNonce uint64 `json:"chain_nonce"`
+
+	// PendingNonce is the next nonce the node would assign, counting the
+	// mempool transactions that directly follow the mined ones
+	PendingNonce uint64 `json:"pending_nonce"`
+
+	// Pruned counts records removed because their nonce was mined, was
+	// reserved but never sent, or belongs to a dropped transaction
+	Pruned int `json:"pruned"`
+
+	// Pending are the nonces of sent transactions still in the mempool
+	Pending []uint64 `json:"pending"`
+
+	// Dropped are nonces whose transaction the node no longer knows
+	Dropped []uint64 `json:"dropped"`
+
+	// Gaps are unused nonces below a pending transaction; the transactions
+	// after a gap cannot be mined until it is filled
+	Gaps []uint64 `json:"gaps"`
+
+	// Filled are gaps RepairNonces filled with zero-value self transfers
+	Filled []string `json:"filled,omitempty"`
+
+	// Replaced is the transaction RepairNonces re-sent with a higher fee
+	// because it blocked every later nonce
+	Replaced string `json:"replaced,omitempty"`
+}
+
+// SetNonceStore makes the client persist the nonces it issues in store, so
+// pending transactions and nonce gaps can be recovered after a restart. Call
+// ReconcileNonces afterwards to pick up where the last run stopped.
+//
+// Parameters:
+//   - store: Persistence for issued nonces, or nil to keep them in memory only
+func (c *Client) SetNonceStore(store NonceStore) {
+	c.nonceManager.setStore(store, c.keyManager.GetAddress(), c.log)
+}
+
+// ReconcileNonces compares the persisted nonces of every configured network
+// with the chain. Records of mined nonces are removed, transactions still in
+// the mempool are tracked again so the monitor can re-price them, and gaps
+// that hold back later transactions are reported. Meant to run on startup.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - []NonceReport: One report per network
+//   - error: Error if no store is set or a network could not be reconciled
+//
+// Example:
+//
+//	client.SetNonceStore(tweetStore)
+//	reports, err := client.ReconcileNonces(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, report := range reports {
+//	    if len(report.Gaps) > 0 {
+//	        log.Printf("%s has nonce gaps %v", report.Network, report.Gaps)
+//	    }
+//	}
+func (c *Client) ReconcileNonces(ctx context.Context) ([]NonceReport, error) {
+	var reports []NonceReport
+	for _, network := range c.configuredNetworks() {
+		report, err := c.reconcileNetwork(ctx, network)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// RepairNonces reconciles the persisted nonces of a network and then unblocks
+// the account: every gap is filled with a zero-value transfer to the wallet's
+// own address, and the transaction at the chain nonce, if it has been pending
+// longer than the receipt timeout, is re-sent with a higher fee. Filling a gap
+// costs gas, so this is an admin operation rather than part of startup.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Network to repair
+//
+// Returns:
+//   - *NonceReport: State before the repair and the transactions sent
+//   - error: Error if reconciliation or a repair transaction fails
+//
+// Example:
+//
+//	report, err := client.RepairNonces(ctx, BASE)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Printf("Filled %d gaps", len(report.Filled))
+func (c *Client) RepairNonces(ctx context.Context, network NetworkType) (*NonceReport, error) {
+	report, err := c.reconcileNetwork(ctx, network)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nonce := range report.Gaps {
+		hash, err := c.fillNonce(ctx, network, nonce)
+		if err != nil {
+			return report, fmt.Errorf("failed to fill nonce %d: %w", nonce, err)
+		}
+		report.Filled = append(report.Filled, hash.Hex())
+	}
+
+	c.trackedMu.Lock()
+	blocking, ok := c.tracked[trackedKey{network: network, nonce: report.ChainNonce}]
+	var hash common.Hash
+	var sentAt time.Time
+	if ok {
+		hash, sentAt = blocking.tx.Hash(), blocking.sentAt
+	}
+	c.trackedMu.Unlock()
+
+	if ok && time.Since(sentAt) > defaultReceiptTimeout {
+		replacement, err := c.SpeedUpTransaction(ctx, network, hash)
+		if err != nil {
+			return report, fmt.Errorf("failed to speed up stuck nonce %d: %w", report.ChainNonce, err)
+		}
+		report.Replaced = replacement.Hex()
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"network":  network,
+		"filled":   len(report.Filled),
+		"replaced": report.Replaced != "",
+	}).Info("Repaired wallet nonces")
+	return report, nil
+}
+
+// reconcileNetwork reconciles the persisted nonces of one network
+func (c *Client) reconcileNetwork(ctx context.Context, network NetworkType) (*NonceReport, error) {
+	nm := c.nonceManager
+	nm.mu.RLock()
+	store := nm.store
+	nm.mu.RUnlock()
+	if store == nil {
+		return nil, fmt.Errorf("no nonce store set")
+	}
+
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, NewWalletError(ErrCodeInvalidNetwork, "failed to get network client", err, network)
+	}
+	address := c.keyManager.GetAddress()
+
+	records, err := store.ListNonces(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted nonces: %w", err)
+	}
+
+	report := &NonceReport{Network: network}
+	if report.ChainNonce, err = client.NonceAt(ctx, address, nil); err != nil {
+		return nil, NewWalletError(ErrCodeRPCError, "failed to get account nonce", err, network)
+	}
+	if report.PendingNonce, err = client.PendingNonceAt(ctx, address); err != nil {
+		return nil, NewWalletError(ErrCodeRPCError, "failed to get pending nonce", err, network)
+	}
+
+	for _, record := range records {
+		prune := func() error {
+			if err := store.DeleteNonce(ctx, network, address, record.Nonce); err != nil {
+				return fmt.Errorf("failed to delete persisted nonce: %w", err)
+			}
+			report.Pruned++
+			return nil
+		}
+
+		if record.Nonce < report.ChainNonce || record.TxHash == (common.Hash{}) {
+			if err := prune(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		tx, isPending, err := client.TransactionByHash(ctx, record.TxHash)
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			report.Dropped = append(report.Dropped, record.Nonce)
+			if err := prune(); err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, NewWalletError(ErrCodeRPCError, "failed to look up pending transaction", err, network)
+		case !isPending:
+			if err := prune(); err != nil {
+				return nil, err
+			}
+		default:
+			report.Pending = append(report.Pending, record.Nonce)
+			c.retrack(network, tx, record)
+		}
+	}
+
+	// The node counts the mempool up to the first missing nonce; anything we
+	// sent beyond that waits on the nonces in between
+	if len(report.Pending) > 0 {
+		highest := slices.Max(report.Pending)
+		for nonce := report.PendingNonce; nonce < highest; nonce++ {
+			if !slices.Contains(report.Pending, nonce) {
+				report.Gaps = append(report.Gaps, nonce)
+			}
+		}
+	}
+
+	log := c.log.WithFields(logrus.Fields{
+		"network":       network,
+		"chain_nonce":   report.ChainNonce,
+		"pending_nonce": report.PendingNonce,
+		"pending":       len(report.Pending),
+		"pruned":        report.Pruned,
+	})
+	if len(report.Gaps) > 0 || len(report.Dropped) > 0 {
+		log.WithField("gaps", report.Gaps).WithField("dropped", report.Dropped).
+			Warn("Wallet nonces need repair")
+	} else {
+		log.Debug("Reconciled wallet nonces")
+	}
+	return report, nil
+}
+
+// retrack follows a pending transaction recovered from the store, keeping the
+// time it was sent so the monitor judges how long it has been stuck
+func (c *Client) retrack(network NetworkType, tx *types.Transaction, record NonceRecord) {
+	c.nonceManager.reserve(network, record.Nonce, record.IssuedAt)
+
+	c.trackedMu.Lock()
+	defer c.trackedMu.Unlock()
+
+	key := trackedKey{network: network, nonce: record.Nonce}
+	if _, ok := c.tracked[key]; ok {
+		return
+	}
+	c.tracked[key] = &trackedTransaction{
+		network: network,
+		tx:      tx,
+		hashes:  []common.Hash{tx.Hash()},
+		sentAt:  record.SentAt,
+	}
+}
+
+// fillNonce sends a zero-value transfer to the wallet's own address with the
+// given nonce, priced to be mined quickly
+func (c *Client) fillNonce(ctx context.Context, network NetworkType, nonce uint64) (common.Hash, error) {
+	client, config, err := c.getClientAndConfig(network)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	estimate, err := c.EstimateFees(ctx, network)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	fee := estimate.Suggestion(GasFast)
+	if config.MaxGasPrice != nil && fee.MaxFee.Cmp(config.MaxGasPrice) > 0 {
+		return common.Hash{}, NewWalletError(ErrCodeGasPrice,
+			fmt.Sprintf("gap filling fee %s wei exceeds maximum %s wei", fee.MaxFee, config.MaxGasPrice), nil, network)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	self := c.keyManager.GetAddress()
+	tx := newTransaction(chainID, nonce, self, big.NewInt(0), cancelGasLimit, nil, fee, estimate.Legacy())
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), c.keyManager.privateKey)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign gap filling transaction: %w", err)
+	}
+
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, NewWalletError(ErrCodeTransactionFailed, "failed to send gap filling transaction", err, network)
+	}
+	c.track(network, signedTx)
+	return signedTx.Hash(), nil
+}
@@ -0,0 +1,291 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// nativeDecimals is the number of decimals of every supported network's native token
+const nativeDecimals = 18
+
+// TokenList names the ERC20 contracts to include in a portfolio, per network
+type TokenList map[NetworkType][]common.Address
+
+// TokenBalance is a single holding in a portfolio snapshot. Native balances have
+// Native set and a zero token address.
+type TokenBalance struct {
+	Network   NetworkType    `json:"network"`
+	Native    bool           `json:"native"`
+	Token     common.Address `json:"token,omitempty"`
+	Symbol    string         `json:"symbol"`
+	Name      string         `json:"name,omitempty"`
+	Decimals  uint8          `json:"decimals"`
+	Balance   *big.Int       `json:"balance"`
+	Formatted string         `json:"formatted"`
+}
+
+// PortfolioSnapshot is a point-in-time view of an address's native and token
+// balances across networks. Lookups that failed are listed in Errors so a
+// partial snapshot can still be used.
+type PortfolioSnapshot struct {
+	Address   common.Address `json:"address"`
+	Timestamp time.Time      `json:"timestamp"`
+	Balances  []TokenBalance `json:"balances"`
+	Errors    []string       `json:"errors,omitempty"`
+}
+
+// GetPortfolio concurrently fetches the native balance on each network and the
+// balance and metadata of each listed ERC20 token, returning a structured snapshot.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - address: Address to take the snapshot of
+//   - networks: Networks to include; nil means every configured network
+//   - tokens: ERC20 contracts to include per network; may be nil
+//
+// Returns:
+//   - *PortfolioSnapshot: Balances sorted by network and symbol
+//   - error: Error if the address or a network is invalid
+//
+// Example:
+//
+//	snapshot, err := client.GetPortfolio(ctx, addr, []NetworkType{ETH, BASE}, TokenList{
+//	    ETH: {common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(snapshot.Summary())
+func (c *Client) GetPortfolio(ctx context.Context, address string, networks []NetworkType, tokens TokenList) (*PortfolioSnapshot, error) {
+	if len(networks) == 0 {
+		networks = c.configuredNetworks()
+	}
+
+	for _, network := range networks {
+		if err := c.ValidateAddress(network, address); err != nil {
+			return nil, err
+		}
+		if _, _, err := c.getClientAndConfig(network); err != nil {
+			return nil, NewWalletError(ErrCodeInvalidNetwork, "network not configured", err, network)
+		}
+	}
+
+	owner := common.HexToAddress(address)
+	snapshot := &PortfolioSnapshot{
+		Address:   owner,
+		Timestamp: time.Now(),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	record := func(balance *TokenBalance, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			snapshot.Errors = append(snapshot.Errors, err.Error())
+			return
+		}
+		snapshot.Balances = append(snapshot.Balances, *balance)
+	}
+
+	for _, network := range networks {
+		network := network
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record(c.nativeBalance(ctx, network, owner))
+		}()
+
+		for _, token := range tokens[network] {
+			token := token
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				record(c.tokenBalance(ctx, network, token, owner))
+			}()
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(snapshot.Balances, func(i, j int) bool {
+		a, b := snapshot.Balances[i], snapshot.Balances[j]
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		if a.Native != b.Native {
+			return a.Native
+		}
+		return a.Symbol < b.Symbol
+	})
+	sort.Strings(snapshot.Errors)
+
+	c.log.WithFields(logrus.Fields{
+		"address":  address,
+		"networks": len(networks),
+		"balances": len(snapshot.Balances),
+		"errors":   len(snapshot.Errors),
+	}).Debug("Retrieved portfolio snapshot")
+
+	return snapshot, nil
+}
+
+// Summary renders the non-zero balances on one line, e.g. for a tweet:
+// "ETH: 1.25 ETH, 300 DAI | BASE: 0.4 ETH"
+func (p *PortfolioSnapshot) Summary() string {
+	var parts []string
+	var current NetworkType
+	var holdings []string
+
+	flush := func() {
+		if len(holdings) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", current, strings.Join(holdings, ", ")))
+		}
+		holdings = nil
+	}
+
+	for _, balance := range p.Balances {
+		if balance.Network != current {
+			flush()
+			current = balance.Network
+		}
+		if balance.Balance == nil || balance.Balance.Sign() == 0 {
+			continue
+		}
+		holdings = append(holdings, balance.Formatted+" "+balance.Symbol)
+	}
+	flush()
+
+	if len(parts) == 0 {
+		return "empty portfolio"
+	}
+	return strings.Join(parts, " | ")
+}
+
+// nativeBalance fetches the native token balance of owner on network
+func (c *Client) nativeBalance(ctx context.Context, network NetworkType, owner common.Address) (*TokenBalance, error) {
+	balance, err := c.GetBalance(ctx, network, owner.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("%s native balance: %w", network, err)
+	}
+
+	return &TokenBalance{
+		Network:   network,
+		Native:    true,
+		Symbol:    c.nativeSymbol(network),
+		Decimals:  nativeDecimals,
+		Balance:   balance,
+		Formatted: FormatUnits(balance, nativeDecimals),
+	}, nil
+}
+
+// tokenBalance fetches the balance and metadata of an ERC20 token held by owner
+func (c *Client) tokenBalance(ctx context.Context, network NetworkType, token, owner common.Address) (*TokenBalance, error) {
+	metadata, err := c.GetTokenMetadata(ctx, network, token)
+	if err != nil {
+		return nil, fmt.Errorf("%s token %s metadata: %w", network, token.Hex(), err)
+	}
+
+	balance, err := c.GetERC20Balance(ctx, network, token, owner)
+	if err != nil {
+		return nil, fmt.Errorf("%s token %s balance: %w", network, token.Hex(), err)
+	}
+
+	return &TokenBalance{
+		Network:   network,
+		Token:     token,
+		Symbol:    metadata.Symbol,
+		Name:      metadata.Name,
+		Decimals:  metadata.Decimals,
+		Balance:   balance,
+		Formatted: FormatUnits(balance, metadata.Decimals),
+	}, nil
+}
+
+// Networks returns the networks the client is connected to, sorted by name
+func (c *Client) Networks() []NetworkType {
+	return c.configuredNetworks()
+}
+
+// configuredNetworks returns the networks the client is connected to, sorted by name
+func (c *Client) configuredNetworks() []NetworkType {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	networks := make([]NetworkType, 0, len(c.clients))
+	for network := range c.clients {
+		networks = append(networks, network)
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i] < networks[j] })
+	return networks
+}
+
+// nativeSymbol returns the symbol of a network's native token
+func (c *Client) nativeSymbol(network NetworkType) string {
+	if _, config, err := c.getClientAndConfig(network); err == nil && config.NativeSymbol != "" {
+		return config.NativeSymbol
+	}
+	if config, ok := RegisteredNetwork(network); ok && config.NativeSymbol != "" {
+		return config.NativeSymbol
+	}
+	// Ethereum and its L2s pay fees in ETH
+	return "ETH"
+}
+
+// FormatUnits renders a base-unit amount as a decimal string with the given number
+// of decimals, trimming trailing zeros. For example, 1500000000000000000 with 18
+// decimals is "1.5".
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+
+	negative := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	if decimals > 0 {
+		if len(digits) <= int(decimals) {
+			digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+		}
+		split := len(digits) - int(decimals)
+		whole, frac := digits[:split], strings.TrimRight(digits[split:], "0")
+		digits = whole
+		if frac != "" {
+			digits += "." + frac
+		}
+	}
+
+	if negative {
+		return "-" + digits
+	}
+	return digits
+}
+
+// ParseUnits is the inverse of FormatUnits: it converts a decimal string in
+// whole tokens to base units, e.g. "1.5" with 18 decimals is
+// 1500000000000000000. Negative amounts and amounts with more fractional
+// digits than decimals are rejected rather than rounded.
+func ParseUnits(amount string, decimals uint8) (*big.Int, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok || value.Sign() < 0 || strings.ContainsAny(amount, "/eE") {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !value.IsInt() {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", amount, decimals)
+	}
+	return value.Num(), nil
+}
@@ -0,0 +1,70 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+)
+
+// NetworkConfigsFromSettings registers the custom networks of the wallet
+// settings and returns a config for every network with an RPC URL, ready for
+// NewClient. Built-in networks keep their defaults for everything but the URL.
+//
+// Parameters:
+//   - settings: Wallet section of the agent configuration
+//
+// Returns:
+//   - []NetworkConfig: Configs of the networks to connect to
+//   - error: Error if a custom network cannot be registered
+//
+// Example:
+//
+//	configs, err := wallet.NetworkConfigsFromSettings(cfg.Wallet)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client, err := wallet.NewClient(ctx, logger, configs, cfg.Wallet.PrivateKey)
+func NetworkConfigsFromSettings(settings config.WalletConfig) ([]NetworkConfig, error) {
+	var configs []NetworkConfig
+	for network, rpcURL := range map[NetworkType]string{
+		ETH:      settings.ETHRPCURL,
+		BASE:     settings.BaseRPCURL,
+		BSC:      settings.BSCRPCURL,
+		POLYGON:  settings.PolygonRPCURL,
+		ARBITRUM: settings.ArbitrumRPCURL,
+		OPTIMISM: settings.OptimismRPCURL,
+	} {
+		if rpcURL != "" {
+			configs = append(configs, NetworkConfig{Type: network, RPCURL: rpcURL})
+		}
+	}
+
+	for _, custom := range settings.Networks {
+		multiplier := custom.GasLimitMultiplier
+		if multiplier == 0 {
+			multiplier = 1.2
+		}
+		network := NetworkConfig{
+			Type:               NetworkType(strings.ToUpper(custom.Name)),
+			RPCURL:             custom.RPCURL,
+			ChainID:            custom.ChainID,
+			MaxRetries:         3,
+			RetryDelay:         time.Second,
+			GasLimitMultiplier: multiplier,
+			NativeSymbol:       custom.NativeSymbol,
+			L1Fee:              L1FeeModel(custom.L1Fee),
+			ExplorerURL:        custom.ExplorerURL,
+		}
+		if err := RegisterNetwork(network); err != nil {
+			return nil, err
+		}
+		configs = append(configs, network)
+	}
+
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Type < configs[j].Type })
+	return configs, nil
+}
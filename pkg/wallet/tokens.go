@@ -11,10 +11,13 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
 )
 
 // Standard ERC20 ABI defines the minimal ABI for interacting with ERC20 tokens.
-// It includes the balanceOf and transfer functions which are required for basic token operations.
+// It includes the balanceOf and transfer functions which are required for basic token
+// operations, the name, symbol and decimals getters used for token metadata, and
+// allowance and approve for spending through the disperse contract.
 const erc20ABI = `[
 	{
 		"constant": true,
@@ -23,6 +26,27 @@ const erc20ABI = `[
 		"outputs": [{"name": "balance", "type": "uint256"}],
 		"type": "function"
 	},
+	{
+		"constant": true,
+		"inputs": [],
+		"name": "name",
+		"outputs": [{"name": "", "type": "string"}],
+		"type": "function"
+	},
+	{
+		"constant": true,
+		"inputs": [],
+		"name": "symbol",
+		"outputs": [{"name": "", "type": "string"}],
+		"type": "function"
+	},
+	{
+		"constant": true,
+		"inputs": [],
+		"name": "decimals",
+		"outputs": [{"name": "", "type": "uint8"}],
+		"type": "function"
+	},
 	{
 		"constant": false,
 		"inputs": [
@@ -32,6 +56,26 @@ const erc20ABI = `[
 		"name": "transfer",
 		"outputs": [{"name": "", "type": "bool"}],
 		"type": "function"
+	},
+	{
+		"constant": true,
+		"inputs": [
+			{"name": "_owner", "type": "address"},
+			{"name": "_spender", "type": "address"}
+		],
+		"name": "allowance",
+		"outputs": [{"name": "", "type": "uint256"}],
+		"type": "function"
+	},
+	{
+		"constant": false,
+		"inputs": [
+			{"name": "_spender", "type": "address"},
+			{"name": "_value", "type": "uint256"}
+		],
+		"name": "approve",
+		"outputs": [{"name": "", "type": "bool"}],
+		"type": "function"
 	}
 ]`
 
@@ -56,6 +100,22 @@ const erc20ABI = `[
 //	    log.Fatal(err)
 //	}
 func (c *Client) TransferERC20(ctx context.Context, network NetworkType, tokenAddress, to common.Address, amount *big.Int) (*common.Hash, error) {
+	if recorder := c.dryRunRecorder(); recorder != nil {
+		hash, err := c.recordDryRunTransfer(ctx, recorder, dryrun.Record{
+			Kind:    dryrun.KindERC20Transfer,
+			Target:  to.Hex(),
+			Content: fmt.Sprintf("transfer %s of token %s to %s", amount, tokenAddress.Hex(), to.Hex()),
+			Details: map[string]interface{}{
+				"token":  tokenAddress.Hex(),
+				"amount": amount.String(),
+			},
+		}, network)
+		if err != nil {
+			return nil, err
+		}
+		return &hash, nil
+	}
+
 	client, _, err := c.getClientAndConfig(network)
 	if err != nil {
 		return nil, err
@@ -85,6 +145,8 @@ func (c *Client) TransferERC20(ctx context.Context, network NetworkType, tokenAd
 		return nil, fmt.Errorf("failed to transfer tokens: %w", err)
 	}
 
+	c.track(network, tx)
+
 	hash := tx.Hash()
 	return &hash, nil
 }
@@ -158,3 +220,67 @@ type TokenMetadata struct {
 	// Name is the full name of the token (e.g., "Dai Stablecoin")
 	Name string
 }
+
+// GetTokenMetadata retrieves the symbol, decimals and name of an ERC20 token by
+// calling the token contract. Results are cached per network and token address,
+// since metadata does not change after deployment.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - network: Target blockchain network
+//   - tokenAddress: Address of the ERC20 token contract
+//
+// Returns:
+//   - *TokenMetadata: Token metadata if successful
+//   - error: Error if the symbol or decimals cannot be read
+//
+// Example:
+//
+//	meta, err := client.GetTokenMetadata(ctx, ETH, tokenAddr)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%s has %d decimals\n", meta.Symbol, meta.Decimals)
+func (c *Client) GetTokenMetadata(ctx context.Context, network NetworkType, tokenAddress common.Address) (*TokenMetadata, error) {
+	cacheKey := string(network) + ":" + tokenAddress.Hex()
+	if cached, ok := c.tokenMetadata.Load(cacheKey); ok {
+		return cached.(*TokenMetadata), nil
+	}
+
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, NewWalletError(ErrCodeInvalidABI, "failed to parse ABI", err, network)
+	}
+
+	contract := bind.NewBoundContract(tokenAddress, parsedABI, client, client, client)
+	opts := &bind.CallOpts{Context: ctx}
+
+	var symbolOut, decimalsOut, nameOut []interface{}
+	if err := contract.Call(opts, &symbolOut, "symbol"); err != nil {
+		return nil, NewWalletError(ErrCodeContractError, "failed to get token symbol", err, network)
+	}
+	if err := contract.Call(opts, &decimalsOut, "decimals"); err != nil {
+		return nil, NewWalletError(ErrCodeContractError, "failed to get token decimals", err, network)
+	}
+
+	metadata := &TokenMetadata{Address: tokenAddress}
+	if len(symbolOut) > 0 {
+		metadata.Symbol, _ = symbolOut[0].(string)
+	}
+	if len(decimalsOut) > 0 {
+		metadata.Decimals, _ = decimalsOut[0].(uint8)
+	}
+
+	// name is optional in the ERC20 standard, so a failure here is not fatal
+	if err := contract.Call(opts, &nameOut, "name"); err == nil && len(nameOut) > 0 {
+		metadata.Name, _ = nameOut[0].(string)
+	}
+
+	c.tokenMetadata.Store(cacheKey, metadata)
+	return metadata, nil
+}
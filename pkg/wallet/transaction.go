@@ -5,10 +5,12 @@ package wallet
 import (
 	"context"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
 )
 
 // TransactionStatus represents the status of a transaction on the blockchain.
@@ -73,7 +75,8 @@ const (
 
 // WaitForReceipt waits for a transaction receipt and returns the transaction status.
 // It polls the network at regular intervals until the transaction is mined and
-// has reached the minimum number of confirmations.
+// has reached the minimum number of confirmations. If the transaction was replaced
+// by the monitor or a speed-up, the receipt and hash of the mined version are returned.
 //
 // Parameters:
 //   - ctx: Context for cancellation
@@ -109,8 +112,14 @@ func (c *Client) WaitForReceipt(ctx context.Context, network NetworkType, hash c
 		case <-timeout:
 			return nil, NewWalletError(ErrCodeTimeout, "timeout waiting for receipt", nil, network)
 		case <-ticker.C:
-			receipt, err := client.TransactionReceipt(ctx, hash)
-			if err != nil {
+			// The monitor may have replaced the transaction, so accept a receipt for any version
+			var receipt *types.Receipt
+			for _, candidate := range c.candidateHashes(hash) {
+				if receipt, err = client.TransactionReceipt(ctx, candidate); err == nil {
+					break
+				}
+			}
+			if receipt == nil {
 				continue // Receipt not found yet
 			}
 
@@ -124,8 +133,14 @@ func (c *Client) WaitForReceipt(ctx context.Context, network NetworkType, hash c
 				continue // Wait for minimum confirmations
 			}
 
-			return &TransactionStatus{
-				Hash:              hash,
+			// The monitor may have seen the receipt first and published it
+			publish := true
+			if key, _, ok := c.findTracked(hash); ok {
+				publish = c.untrack(key)
+			}
+
+			status := &TransactionStatus{
+				Hash:              receipt.TxHash,
 				Status:            receipt.Status,
 				BlockNumber:       receipt.BlockNumber,
 				GasUsed:           receipt.GasUsed,
@@ -133,11 +148,60 @@ func (c *Client) WaitForReceipt(ctx context.Context, network NetworkType, hash c
 				Confirmations:     confirmations,
 				State:             TxStateConfirmed,
 				Timestamp:         time.Now(),
-			}, nil
+			}
+			if publish {
+				c.publishTransferCompleted(network, hash, status)
+			}
+			return status, nil
 		}
 	}
 }
 
+// publishTransferCompleted emits a wallet_transfer_completed event for a
+// confirmed transaction. sentHash is the hash the transaction was sent with,
+// which differs from the mined hash when it was replaced.
+func (c *Client) publishTransferCompleted(network NetworkType, sentHash common.Hash, status *TransactionStatus) {
+	c.mu.RLock()
+	bus := c.events
+	c.mu.RUnlock()
+
+	data := map[string]interface{}{
+		"network":       string(network),
+		"hash":          status.Hash.Hex(),
+		"sent_hash":     sentHash.Hex(),
+		"success":       status.Status == types.ReceiptStatusSuccessful,
+		"block_number":  status.BlockNumber.String(),
+		"gas_used":      status.GasUsed,
+		"confirmations": status.Confirmations,
+	}
+	if status.EffectiveGasPrice != nil {
+		data["effective_gas_price"] = status.EffectiveGasPrice.String()
+	}
+	if url := c.TransactionURL(network, status.Hash); url != "" {
+		data["explorer_url"] = url
+	}
+	bus.Emit(events.WalletTransferCompleted, "", data)
+}
+
+// TransactionURL returns the block explorer page of a transaction, or an
+// empty string when the network has no explorer configured.
+//
+// Parameters:
+//   - network: Network the transaction was sent on
+//   - hash: Transaction hash
+//
+// Returns:
+//   - string: Explorer URL such as "https://basescan.org/tx/0x..."
+func (c *Client) TransactionURL(network NetworkType, hash common.Hash) string {
+	c.mu.RLock()
+	config, ok := c.configs[network]
+	c.mu.RUnlock()
+	if !ok || config.ExplorerURL == "" {
+		return ""
+	}
+	return strings.TrimRight(config.ExplorerURL, "/") + "/tx/" + hash.Hex()
+}
+
 // NeedsResubmission checks if a transaction needs to be resubmitted based on its current state.
 // Returns true if the transaction is dropped or has been pending too long.
 //
@@ -210,6 +274,10 @@ func (c *Client) SendTransactionWithOptions(
 	value *big.Int,
 	opts *TransactionOptions,
 ) (*TransactionStatus, error) {
+	if recorder := c.dryRunRecorder(); recorder != nil {
+		return c.dryRunTransaction(ctx, recorder, network, to, data, value)
+	}
+
 	if opts == nil {
 		opts = DefaultTransactionOptions()
 	}
@@ -219,11 +287,22 @@ func (c *Client) SendTransactionWithOptions(
 		return nil, err
 	}
 
+	if opts.GasStrategy == nil {
+		opts.GasStrategy = DefaultGasStrategy()
+	}
+
 	// Use config for gas price checks
 	if opts.GasStrategy.MaxGasPrice == nil {
 		opts.GasStrategy.MaxGasPrice = config.MaxGasPrice
 	}
 
+	// Price the transaction first: with WaitForLowerGas this holds the transaction
+	// until fees drop, and no nonce is reserved while it waits
+	fee, legacy, err := c.SuggestFees(ctx, network, opts.GasStrategy)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get or use provided nonce
 	var nonce uint64
 	if opts.Nonce != nil {
@@ -242,28 +321,15 @@ func (c *Client) SendTransactionWithOptions(
 		return nil, err
 	}
 
-	// Apply gas strategy
-	gasPrice, err := client.SuggestGasPrice(ctx)
+	chainID, err := client.ChainID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if opts.GasStrategy.MaxGasPrice != nil && gasPrice.Cmp(opts.GasStrategy.MaxGasPrice) > 0 {
-		if opts.GasStrategy.WaitForLowerGas {
-			return nil, NewWalletError(ErrCodeGasPrice, "gas price too high", nil, network)
-		}
-		gasPrice = opts.GasStrategy.MaxGasPrice
-	}
-
 	// Create and sign transaction
-	tx := types.NewTransaction(nonce, to, value, gasLimit, gasPrice, data)
+	tx := newTransaction(chainID, nonce, to, value, gasLimit, data, fee, legacy)
 
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), c.keyManager.privateKey)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), c.keyManager.privateKey)
 	if err != nil {
 		return nil, err
 	}
@@ -273,6 +339,7 @@ func (c *Client) SendTransactionWithOptions(
 	if err != nil {
 		return nil, NewWalletError(ErrCodeTransactionFailed, "failed to send transaction", err, network)
 	}
+	c.track(network, signedTx)
 
 	// Return immediately if not waiting for receipt
 	if !opts.WaitReceipt {
@@ -286,3 +353,22 @@ func (c *Client) SendTransactionWithOptions(
 	// Wait for receipt
 	return c.WaitForReceipt(ctx, network, signedTx.Hash())
 }
+
+// newTransaction builds an EIP-1559 dynamic fee transaction, or a legacy
+// transaction priced at fee.MaxFee on networks without EIP-1559 support
+func newTransaction(chainID *big.Int, nonce uint64, to common.Address, value *big.Int, gasLimit uint64, data []byte, fee FeeSuggestion, legacy bool) *types.Transaction {
+	if legacy || fee.MaxPriorityFee == nil {
+		return types.NewTransaction(nonce, to, value, gasLimit, fee.MaxFee, data)
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: fee.MaxPriorityFee,
+		GasFeeCap: fee.MaxFee,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	})
+}
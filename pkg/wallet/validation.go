@@ -56,20 +56,18 @@ func (c *Client) ValidateAddress(network NetworkType, address string) error {
 		)
 	}
 
-	// Network-specific validation
-	switch network {
-	case ETH, BASE:
-		// ETH and Base use the same address format
+	// Every supported network is EVM-compatible and shares Ethereum's address
+	// format, so the network only has to be known
+	if _, ok := RegisteredNetwork(network); ok {
 		return nil
-	case BSC:
-		// BSC uses the same format as ETH
+	}
+	if _, _, err := c.getClientAndConfig(network); err == nil {
 		return nil
-	default:
-		return NewWalletError(
-			ErrCodeInvalidNetwork,
-			fmt.Sprintf("unsupported network: %s", network),
-			nil,
-			network,
-		)
 	}
+	return NewWalletError(
+		ErrCodeInvalidNetwork,
+		fmt.Sprintf("unsupported network: %s", network),
+		nil,
+		network,
+	)
 }
@@ -13,6 +13,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
 	"github.com/sirupsen/logrus"
 )
 
@@ -26,6 +28,12 @@ const (
 	BASE NetworkType = "BASE"
 	// BSC represents the Binance Smart Chain network
 	BSC NetworkType = "BSC"
+	// POLYGON represents the Polygon PoS network
+	POLYGON NetworkType = "POLYGON"
+	// ARBITRUM represents the Arbitrum One network
+	ARBITRUM NetworkType = "ARBITRUM"
+	// OPTIMISM represents the OP Mainnet network
+	OPTIMISM NetworkType = "OPTIMISM"
 )
 
 // Client represents an EVM wallet client that manages connections and interactions
@@ -38,10 +46,27 @@ type Client struct {
 	nonceManager *NonceManager
 	mu           sync.RWMutex
 	log          *logrus.Logger
+
+	// tokenMetadata caches *TokenMetadata by "network:address"
+	tokenMetadata sync.Map
+	// ensCache holds ensCacheEntry values by "name:<name>" and "address:<hex>"
+	ensCache sync.Map
+
+	// tracked holds sent transactions that have not been mined, for the monitor
+	tracked   map[trackedKey]*trackedTransaction
+	trackedMu sync.Mutex
+
+	// events receives wallet_transfer_completed events; nil disables them
+	events *events.Bus
+
+	// dryRun, when set, records transfers instead of sending them
+	dryRun dryrun.Recorder
 }
 
 // NewClient creates a new wallet client with the provided configurations and private key.
 // It establishes connections to all configured networks and initializes the key manager.
+// Fields left empty in a config are filled from the built-in or registered config
+// of its network, and each RPC endpoint must serve the expected chain ID.
 //
 // Parameters:
 //   - ctx: Context for initialization operations
@@ -75,13 +100,29 @@ func NewClient(ctx context.Context, log *logrus.Logger, configs []NetworkConfig,
 		keyManager:   keyManager,
 		nonceManager: newNonceManager(),
 		log:          log,
+		tracked:      make(map[trackedKey]*trackedTransaction),
 	}
 
 	for _, config := range configs {
+		config = withNetworkDefaults(config)
 		ethClient, err := client.dialWithRetry(ctx, config)
 		if err != nil {
 			return nil, NewWalletError(ErrCodeRPCError, "failed to connect to network", err, config.Type)
 		}
+
+		if config.ChainID != 0 {
+			chainID, err := ethClient.ChainID(ctx)
+			if err != nil {
+				ethClient.Close()
+				return nil, NewWalletError(ErrCodeRPCError, "failed to get chain ID", err, config.Type)
+			}
+			if chainID.Int64() != config.ChainID {
+				ethClient.Close()
+				return nil, NewWalletError(ErrCodeChainMismatch,
+					fmt.Sprintf("RPC endpoint serves chain %s, expected %d", chainID, config.ChainID), nil, config.Type)
+			}
+		}
+
 		client.clients[config.Type] = ethClient
 		client.configs[config.Type] = config
 	}
@@ -89,6 +130,22 @@ func NewClient(ctx context.Context, log *logrus.Logger, configs []NetworkConfig,
 	return client, nil
 }
 
+// SetEventBus publishes an event to bus whenever a transaction awaited with
+// WaitForReceipt is confirmed.
+//
+// Parameters:
+//   - bus: Event bus to publish to, or nil to stop publishing
+func (c *Client) SetEventBus(bus *events.Bus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = bus
+}
+
+// Address returns the address of the wallet's key
+func (c *Client) Address() common.Address {
+	return c.keyManager.GetAddress()
+}
+
 // GetBalance retrieves the native token balance for an address on the specified network.
 //
 // Parameters:
@@ -205,6 +262,10 @@ func (c *Client) EstimateGas(ctx context.Context, network NetworkType, to common
 //	}
 //	fmt.Printf("Transaction confirmed in block %s\n", status.BlockNumber)
 func (c *Client) SendTransaction(ctx context.Context, network NetworkType, to common.Address, data []byte, value *big.Int) (*TransactionStatus, error) {
+	if recorder := c.dryRunRecorder(); recorder != nil {
+		return c.dryRunTransaction(ctx, recorder, network, to, data, value)
+	}
+
 	client, _, err := c.getClientAndConfig(network)
 	if err != nil {
 		return nil, err
@@ -242,6 +303,7 @@ func (c *Client) SendTransaction(ctx context.Context, network NetworkType, to co
 	if err != nil {
 		return nil, NewWalletError(ErrCodeTransactionFailed, "failed to send transaction", err, network)
 	}
+	c.track(network, signedTx)
 
 	// Wait for receipt and return status
 	return c.WaitForReceipt(ctx, network, signedTx.Hash())
@@ -0,0 +1,299 @@
+// Package wallet provides blockchain wallet functionality for managing transactions,
+// accounts, and interactions with various networks like Ethereum and BSC.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/sirupsen/logrus"
+)
+
+// transferTopic is the topic of the ERC20 Transfer(address,address,uint256) event
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+const (
+	// defaultWatchInterval is how often new blocks are scanned for transfers
+	defaultWatchInterval = 30 * time.Second
+
+	// defaultWatchConfirmations is how deep a block must be before its
+	// transfers are reported, so shallow reorgs do not produce phantom transfers
+	defaultWatchConfirmations = 2
+
+	// defaultWatchBlockRange caps the blocks of one eth_getLogs request, which
+	// many RPC providers limit
+	defaultWatchBlockRange = 2000
+)
+
+// IncomingTransfer is an ERC20 transfer to the wallet's address
+type IncomingTransfer struct {
+	Network NetworkType
+	Token   common.Address
+	// Symbol and Decimals are empty when the token metadata could not be read
+	Symbol      string
+	Decimals    uint8
+	From        common.Address
+	To          common.Address
+	Amount      *big.Int
+	TxHash      common.Hash
+	BlockNumber uint64
+	LogIndex    uint
+}
+
+// FormattedAmount renders the amount in whole tokens, e.g. "1.5"
+func (t IncomingTransfer) FormattedAmount() string {
+	return FormatUnits(t.Amount, t.Decimals)
+}
+
+// WatchOptions configures WatchIncomingTransfers
+type WatchOptions struct {
+	// Networks to watch; defaults to every configured network
+	Networks []NetworkType
+
+	// Tokens limits the watch to these token contracts per network; networks
+	// without an entry report transfers of any token
+	Tokens TokenList
+
+	// PollInterval is how often new blocks are scanned; defaults to 30 seconds
+	PollInterval time.Duration
+
+	// Confirmations is how deep a block must be before its transfers are
+	// reported; defaults to 2. Websocket subscriptions report at the head.
+	Confirmations uint64
+
+	// MaxBlockRange caps the blocks of one log query; defaults to 2000
+	MaxBlockRange uint64
+
+	// OnTransfer is called for every incoming transfer, after the
+	// wallet_transfer_received event is published
+	OnTransfer func(ctx context.Context, transfer IncomingTransfer)
+}
+
+// WatchIncomingTransfers reports ERC20 transfers to the wallet's address until
+// ctx is done. Each transfer is published on the event bus as
+// wallet_transfer_received and passed to opts.OnTransfer. Networks with a
+// websocket RPC URL use eth_subscribe; the others, and subscriptions that
+// fail, poll eth_getLogs. Only transfers in blocks after the watch started are
+// reported.
+//
+// Parameters:
+//   - ctx: Context that stops the watch
+//   - opts: Watch options
+//
+// Returns:
+//   - error: ctx.Err() once stopped, or an error if a network is not configured
+//
+// Example:
+//
+//	go client.WatchIncomingTransfers(ctx, WatchOptions{
+//	    Tokens: TokenList{BASE: {laffyAddr}},
+//	    OnTransfer: func(ctx context.Context, transfer IncomingTransfer) {
+//	        log.Printf("Received %s %s from %s", transfer.FormattedAmount(), transfer.Symbol, transfer.From.Hex())
+//	    },
+//	})
+func (c *Client) WatchIncomingTransfers(ctx context.Context, opts WatchOptions) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultWatchInterval
+	}
+	if opts.Confirmations == 0 {
+		opts.Confirmations = defaultWatchConfirmations
+	}
+	if opts.MaxBlockRange == 0 {
+		opts.MaxBlockRange = defaultWatchBlockRange
+	}
+
+	networks := opts.Networks
+	if len(networks) == 0 {
+		networks = c.configuredNetworks()
+	}
+	for _, network := range networks {
+		if _, _, err := c.getClientAndConfig(network); err != nil {
+			return NewWalletError(ErrCodeInvalidNetwork, "cannot watch transfers", err, network)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, network := range networks {
+		wg.Add(1)
+		go func(network NetworkType) {
+			defer wg.Done()
+			c.watchNetwork(ctx, network, opts)
+		}(network)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// watchNetwork watches one network, preferring a subscription when the RPC
+// endpoint supports one
+func (c *Client) watchNetwork(ctx context.Context, network NetworkType, opts WatchOptions) {
+	_, config, _ := c.getClientAndConfig(network)
+	log := c.log.WithField("network", network)
+
+	if strings.HasPrefix(config.RPCURL, "ws") {
+		err := c.subscribeTransfers(ctx, network, opts)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).Warn("Transfer subscription failed, polling for transfers instead")
+	}
+
+	log.WithField("interval", opts.PollInterval).Debug("Polling for incoming transfers")
+	c.pollTransfers(ctx, network, opts)
+}
+
+// subscribeTransfers delivers transfers from an eth_subscribe log subscription
+// until it fails or ctx is done
+func (c *Client) subscribeTransfers(ctx context.Context, network NetworkType, opts WatchOptions) error {
+	client, _, err := c.getClientAndConfig(network)
+	if err != nil {
+		return err
+	}
+
+	logs := make(chan types.Log, 64)
+	sub, err := client.SubscribeFilterLogs(ctx, c.transferQuery(network, opts, nil, nil), logs)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	c.log.WithField("network", network).Info("Subscribed to incoming transfers")
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return err
+		case entry := <-logs:
+			// Logs of blocks dropped in a reorg are sent again with Removed set
+			if !entry.Removed {
+				c.handleTransferLog(ctx, network, entry, opts)
+			}
+		}
+	}
+}
+
+// pollTransfers scans confirmed blocks for transfers with eth_getLogs until ctx is done
+func (c *Client) pollTransfers(ctx context.Context, network NetworkType, opts WatchOptions) {
+	log := c.log.WithField("network", network)
+
+	var next uint64
+	started := false
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		client, _, err := c.getClientAndConfig(network)
+		if err != nil {
+			log.WithError(err).Warn("Network no longer configured, stopping transfer watch")
+			return
+		}
+
+		head, err := client.BlockNumber(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				log.WithError(err).Warn("Failed to get block number for transfer watch")
+			}
+		case head+1 < opts.Confirmations:
+		default:
+			confirmed := head + 1 - opts.Confirmations
+			if !started {
+				next, started = confirmed+1, true
+			}
+			for next <= confirmed && ctx.Err() == nil {
+				to := min(next+opts.MaxBlockRange-1, confirmed)
+				entries, err := client.FilterLogs(ctx, c.transferQuery(network, opts, new(big.Int).SetUint64(next), new(big.Int).SetUint64(to)))
+				if err != nil {
+					log.WithError(err).WithField("from_block", next).WithField("to_block", to).
+						Warn("Failed to query transfer logs, retrying next poll")
+					break
+				}
+				for _, entry := range entries {
+					c.handleTransferLog(ctx, network, entry, opts)
+				}
+				next = to + 1
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// transferQuery filters Transfer events to the wallet's address
+func (c *Client) transferQuery(network NetworkType, opts WatchOptions, from, to *big.Int) ethereum.FilterQuery {
+	recipient := common.BytesToHash(c.keyManager.GetAddress().Bytes())
+	return ethereum.FilterQuery{
+		FromBlock: from,
+		ToBlock:   to,
+		Addresses: opts.Tokens[network],
+		Topics:    [][]common.Hash{{transferTopic}, nil, {recipient}},
+	}
+}
+
+// handleTransferLog decodes a Transfer log, publishes it and hands it to the callback
+func (c *Client) handleTransferLog(ctx context.Context, network NetworkType, entry types.Log, opts WatchOptions) {
+	// ERC721 transfers share the signature but index the token ID as well
+	if len(entry.Topics) != 3 || len(entry.Data) != 32 {
+		return
+	}
+
+	transfer := IncomingTransfer{
+		Network:     network,
+		Token:       entry.Address,
+		From:        common.BytesToAddress(entry.Topics[1].Bytes()),
+		To:          common.BytesToAddress(entry.Topics[2].Bytes()),
+		Amount:      new(big.Int).SetBytes(entry.Data),
+		TxHash:      entry.TxHash,
+		BlockNumber: entry.BlockNumber,
+		LogIndex:    entry.Index,
+	}
+	if metadata, err := c.GetTokenMetadata(ctx, network, entry.Address); err == nil {
+		transfer.Symbol = metadata.Symbol
+		transfer.Decimals = metadata.Decimals
+	} else {
+		c.log.WithError(err).WithField("token", entry.Address.Hex()).Debug("Failed to read metadata of received token")
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"network": network,
+		"token":   transfer.Token.Hex(),
+		"symbol":  transfer.Symbol,
+		"from":    transfer.From.Hex(),
+		"amount":  transfer.FormattedAmount(),
+		"hash":    transfer.TxHash.Hex(),
+	}).Info("Received token transfer")
+
+	c.mu.RLock()
+	bus := c.events
+	c.mu.RUnlock()
+	bus.Emit(events.WalletTransferReceived, "", map[string]interface{}{
+		"network":      string(network),
+		"token":        transfer.Token.Hex(),
+		"symbol":       transfer.Symbol,
+		"from":         transfer.From.Hex(),
+		"to":           transfer.To.Hex(),
+		"amount":       transfer.Amount.String(),
+		"amount_units": transfer.FormattedAmount(),
+		"hash":         transfer.TxHash.Hex(),
+		"block_number": fmt.Sprint(transfer.BlockNumber),
+	})
+
+	if opts.OnTransfer != nil {
+		opts.OnTransfer(ctx, transfer)
+	}
+}
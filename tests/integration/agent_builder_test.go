@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	agent "github.com/lisanmuaddib/agent-go/pkg"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// blockingAction runs until it is cancelled
+type blockingAction struct {
+	name    string
+	started chan struct{}
+}
+
+func (a *blockingAction) Name() string { return a.name }
+
+func (a *blockingAction) Execute(ctx context.Context) error {
+	close(a.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (a *blockingAction) Stop() {}
+
+var _ = Describe("Agent builder", func() {
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+	})
+
+	It("connects to Twitter, registers the actions and runs them until stopped", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		settings := config.Default().Twitter
+		settings.BaseURL = server.BaseURL()
+		settings.BearerToken = "test-token"
+		settings.ConsumerKey, settings.ConsumerSecret = "key", "secret"
+		settings.AccessToken, settings.AccessTokenSecret = "token", "token-secret"
+		client, botID, err := agent.ConnectTwitter(ctx, logger, settings)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(botID).NotTo(BeEmpty())
+
+		action := &blockingAction{name: "watch_scores", started: make(chan struct{})}
+		disabled := false
+		bot, err := agent.NewBuilder().
+			WithTwitter(client).
+			WithLLM(&modelRecorder{}).
+			WithLogger(logger).
+			WithTasks(config.TasksConfig{Overrides: map[string]config.TaskConfig{
+				"sleepy": {Enabled: &disabled},
+			}}, nil).
+			WithActions(action, &blockingAction{name: "sleepy", started: make(chan struct{})}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		done := make(chan error, 1)
+		go func() { done <- bot.RunUntilSignal(ctx) }()
+
+		Eventually(action.started).Should(BeClosed())
+		health := bot.TaskHealth()
+		Expect(health).To(HaveLen(2))
+		Expect(health[0].Name).To(Equal("sleepy"))
+		Expect(health[0].State).To(Equal(agent.TaskDisabled))
+		Expect(health[1].Name).To(Equal("watch_scores"))
+
+		bot.Stop()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+	})
+
+	It("tells rejected credentials from an unavailable API", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		settings := config.Default().Twitter
+		settings.BaseURL = server.BaseURL()
+		settings.BearerToken = "test-token"
+		settings.ConsumerKey, settings.ConsumerSecret = "key", "secret"
+		settings.AccessToken, settings.AccessTokenSecret = "token", "token-secret"
+		settings.RetryAttempts = 0
+
+		server.Fail(twittermock.Failure{Path: "/users/me", Status: http.StatusServiceUnavailable, Times: 1})
+		_, _, err := agent.ConnectTwitter(ctx, logger, settings)
+		Expect(err).To(MatchError(agent.ErrTwitterUnavailable))
+		Expect(err).NotTo(MatchError(agent.ErrTwitterAuth))
+
+		server.Fail(twittermock.Failure{Path: "/users/me", Status: http.StatusUnauthorized, Times: 1})
+		_, _, err = agent.ConnectTwitter(ctx, logger, settings)
+		Expect(err).To(MatchError(agent.ErrTwitterAuth))
+	})
+
+	It("requires Twitter and an LLM and rejects duplicate action names", func() {
+		_, err := agent.NewBuilder().WithLLM(&modelRecorder{}).Build()
+		Expect(err).To(MatchError("TwitterClient is required"))
+
+		client, err := twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = agent.NewBuilder().
+			WithTwitter(client).
+			WithLLM(&modelRecorder{}).
+			WithLogger(logger).
+			WithActions(
+				&blockingAction{name: "watch_scores"},
+				&blockingAction{name: "watch_scores"},
+			).
+			Build()
+		Expect(err).To(MatchError(ContainSubstring("action watch_scores already registered")))
+	})
+})
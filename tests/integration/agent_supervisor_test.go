@@ -0,0 +1,293 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	agent "github.com/lisanmuaddib/agent-go/pkg"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// exitingAction returns err, or panics with it when panics is set, every time
+// it runs, recording when each run started
+type exitingAction struct {
+	name   string
+	err    error
+	panics bool
+
+	mu     sync.Mutex
+	starts []time.Time
+}
+
+func (a *exitingAction) Name() string { return a.name }
+
+func (a *exitingAction) Execute(context.Context) error {
+	a.mu.Lock()
+	a.starts = append(a.starts, time.Now())
+	a.mu.Unlock()
+	if a.panics {
+		panic(a.err)
+	}
+	return a.err
+}
+
+func (a *exitingAction) Stop() {}
+
+func (a *exitingAction) runs() []time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]time.Time(nil), a.starts...)
+}
+
+// restartableAction runs until cancelled, announcing every run and counting
+// the runs that ended by cancellation and the calls to Stop
+type restartableAction struct {
+	name    string
+	started chan struct{}
+
+	mu        sync.Mutex
+	cancelled int
+	stops     int
+}
+
+func newRestartableAction(name string) *restartableAction {
+	return &restartableAction{name: name, started: make(chan struct{}, 4)}
+}
+
+func (a *restartableAction) Name() string { return a.name }
+
+func (a *restartableAction) Execute(ctx context.Context) error {
+	a.started <- struct{}{}
+	<-ctx.Done()
+	a.mu.Lock()
+	a.cancelled++
+	a.mu.Unlock()
+	return ctx.Err()
+}
+
+func (a *restartableAction) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stops++
+}
+
+func (a *restartableAction) counts() (cancelled, stops int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cancelled, a.stops
+}
+
+var _ = Describe("Agent supervisor", func() {
+	var (
+		logger *logrus.Logger
+		client *twitter.TwitterClient
+		bot    *agent.Agent
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		server := twittermock.NewServer()
+		DeferCleanup(server.Close)
+		var err error
+		client, err = twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+
+		bot, err = agent.New(agent.Config{LLM: &modelRecorder{}, TwitterClient: client, Logger: logger})
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	health := func(name string) agent.TaskHealth {
+		for _, h := range bot.TaskHealth() {
+			if h.Name == name {
+				return h
+			}
+		}
+		Fail("no task named " + name)
+		return agent.TaskHealth{}
+	}
+
+	It("restarts a failing action with doubling backoff until it gives up", func() {
+		action := &exitingAction{name: "flaky_feed", err: errors.New("feed unreachable")}
+		Expect(bot.RegisterActionWithPolicy(action, agent.TaskPolicy{
+			Restart:     agent.RestartOnFailure,
+			MaxRestarts: 3,
+			Backoff:     50 * time.Millisecond,
+			MaxBackoff:  150 * time.Millisecond,
+		})).To(Succeed())
+
+		err := bot.Run(ctx)
+		Expect(err).To(MatchError(ContainSubstring("action flaky_feed failed (restarted 3 times)")))
+		Expect(errors.Is(err, action.err)).To(BeTrue())
+
+		starts := action.runs()
+		Expect(starts).To(HaveLen(4))
+		// 50ms, doubled to 100ms, then capped at 150ms
+		for i, minimum := range []time.Duration{50, 100, 150} {
+			Expect(starts[i+1].Sub(starts[i])).To(BeNumerically(">=", minimum*time.Millisecond))
+		}
+		Expect(starts[3].Sub(starts[2])).To(BeNumerically("<", 300*time.Millisecond))
+
+		h := health("flaky_feed")
+		Expect(h.State).To(Equal(agent.TaskFailed))
+		Expect(h.Restarts).To(Equal(3))
+		Expect(h.LastError).To(Equal("feed unreachable"))
+		Expect(h.Healthy()).To(BeFalse())
+	})
+
+	It("treats a panic as a failure", func() {
+		action := &exitingAction{name: "panicky", err: errors.New("nil map"), panics: true}
+		Expect(bot.RegisterActionWithPolicy(action, agent.TaskPolicy{
+			Restart:     agent.RestartOnFailure,
+			MaxRestarts: 1,
+			Backoff:     10 * time.Millisecond,
+			MaxBackoff:  10 * time.Millisecond,
+		})).To(Succeed())
+
+		Expect(bot.Run(ctx)).To(MatchError(ContainSubstring("action panicked: nil map")))
+		Expect(action.runs()).To(HaveLen(2))
+		Expect(health("panicky").State).To(Equal(agent.TaskFailed))
+	})
+
+	It("does not restart an action that exits cleanly under the on-failure policy", func() {
+		action := &exitingAction{name: "one_shot"}
+		Expect(bot.RegisterActionWithPolicy(action, agent.TaskPolicy{
+			Restart:     agent.RestartOnFailure,
+			MaxRestarts: 3,
+			Backoff:     10 * time.Millisecond,
+			MaxBackoff:  10 * time.Millisecond,
+		})).To(Succeed())
+
+		Expect(bot.Run(ctx)).To(Succeed())
+		Expect(action.runs()).To(HaveLen(1))
+		h := health("one_shot")
+		Expect(h.State).To(Equal(agent.TaskExited))
+		Expect(h.Restarts).To(BeZero())
+		Expect(h.LastError).To(BeEmpty())
+	})
+
+	It("restarts an action that exits cleanly under the always policy", func() {
+		action := &exitingAction{name: "heartbeat"}
+		Expect(bot.RegisterActionWithPolicy(action, agent.TaskPolicy{
+			Restart:     agent.RestartAlways,
+			MaxRestarts: 2,
+			Backoff:     10 * time.Millisecond,
+			MaxBackoff:  10 * time.Millisecond,
+		})).To(Succeed())
+
+		Expect(bot.Run(ctx)).To(Succeed())
+		Expect(action.runs()).To(HaveLen(3))
+		Expect(health("heartbeat").State).To(Equal(agent.TaskExited))
+	})
+
+	It("keeps the other actions running when one is given up on", func() {
+		failing := &exitingAction{name: "flaky_feed", err: errors.New("feed unreachable")}
+		Expect(bot.RegisterActionWithPolicy(failing, agent.TaskPolicy{Restart: agent.RestartNever})).To(Succeed())
+		steady := &blockingAction{name: "watch_scores", started: make(chan struct{})}
+		Expect(bot.RegisterActionWithPolicy(steady, agent.DefaultTaskPolicy())).To(Succeed())
+
+		done := make(chan error, 1)
+		go func() { done <- bot.Run(ctx) }()
+
+		Eventually(func() agent.TaskState { return health("flaky_feed").State }).Should(Equal(agent.TaskFailed))
+		Expect(failing.runs()).To(HaveLen(1))
+		Expect(health("watch_scores").Healthy()).To(BeTrue())
+		Consistently(done, 200*time.Millisecond).ShouldNot(Receive())
+
+		bot.Stop()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+		Expect(health("watch_scores").State).To(Equal(agent.TaskStopped))
+	})
+
+	Context("enabling and disabling tasks", func() {
+		var (
+			action *restartableAction
+			done   chan error
+		)
+
+		// run starts the agent with the tasks settings, re-reading them from
+		// reload when a task is enabled
+		run := func(settings config.TasksConfig, reload func() (config.TasksConfig, error)) {
+			var err error
+			bot, err = agent.New(agent.Config{
+				LLM:           &modelRecorder{},
+				TwitterClient: client,
+				Logger:        logger,
+				Tasks:         settings,
+				ReloadTasks:   reload,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			action = newRestartableAction("watch_scores")
+			Expect(bot.RegisterAction(action)).To(Succeed())
+
+			done = make(chan error, 1)
+			go func() { done <- bot.Run(ctx) }()
+			DeferCleanup(func() {
+				bot.Stop()
+				Eventually(done).Should(Receive())
+			})
+		}
+
+		It("cancels a disabled task without stopping the agent and starts it again when enabled", func() {
+			run(config.TasksConfig{}, nil)
+			Eventually(action.started).Should(Receive())
+
+			Expect(bot.DisableTask("watch_scores")).To(Succeed())
+			// DisableTask waits for the task to return
+			cancelled, stops := action.counts()
+			Expect(cancelled).To(Equal(1))
+			Expect(stops).To(BeZero())
+			Expect(health("watch_scores").State).To(Equal(agent.TaskDisabled))
+			Consistently(done, 200*time.Millisecond).ShouldNot(Receive())
+			Expect(action.started).NotTo(Receive())
+
+			Expect(bot.EnableTask("watch_scores")).To(Succeed())
+			Eventually(action.started).Should(Receive())
+			Eventually(func() agent.TaskState { return health("watch_scores").State }).Should(Equal(agent.TaskRunning))
+
+			// Enabling a running task does nothing
+			Expect(bot.EnableTask("watch_scores")).To(Succeed())
+			Consistently(action.started, 200*time.Millisecond).ShouldNot(Receive())
+			_, stops = action.counts()
+			Expect(stops).To(BeZero())
+		})
+
+		It("starts a task disabled by configuration with the reloaded settings", func() {
+			disabled := false
+			run(config.TasksConfig{Overrides: map[string]config.TaskConfig{
+				"watch_scores": {Enabled: &disabled},
+			}}, func() (config.TasksConfig, error) {
+				return config.TasksConfig{Overrides: map[string]config.TaskConfig{
+					"watch_scores": {Restart: string(agent.RestartNever)},
+				}}, nil
+			})
+			Consistently(action.started, 200*time.Millisecond).ShouldNot(Receive())
+			Expect(health("watch_scores").State).To(Equal(agent.TaskDisabled))
+
+			Expect(bot.EnableTask("watch_scores")).To(Succeed())
+			Eventually(action.started).Should(Receive())
+			Expect(health("watch_scores").Policy).To(Equal(agent.RestartNever))
+		})
+
+		It("rejects unknown task names", func() {
+			run(config.TasksConfig{}, nil)
+
+			Expect(bot.EnableTask("watch_scorez")).To(MatchError(agent.ErrTaskNotFound))
+			Expect(bot.DisableTask("watch_scorez")).To(MatchError(agent.ErrTaskNotFound))
+			Eventually(action.started).Should(Receive())
+			Expect(health("watch_scores").State).To(Equal(agent.TaskRunning))
+		})
+	})
+})
@@ -0,0 +1,123 @@
+package integration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeS3 accepts PUTs whose SigV4 signature matches the request it received,
+// re-signing only the headers the client listed as signed
+type fakeS3 struct {
+	credentials aws.Credentials
+	region      string
+
+	mu      sync.Mutex
+	uploads []*http.Request
+	bodies  [][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	Expect(err).NotTo(HaveOccurred())
+
+	authorization := r.Header.Get("Authorization")
+	_, signedHeaders, _ := strings.Cut(authorization, "SignedHeaders=")
+	signedHeaders, _, _ = strings.Cut(signedHeaders, ",")
+
+	signingTime, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	Expect(err).NotTo(HaveOccurred())
+	resigned, err := http.NewRequest(r.Method, "http://"+r.Host+r.RequestURI, nil)
+	Expect(err).NotTo(HaveOccurred())
+	resigned.ContentLength = r.ContentLength
+	for _, header := range strings.Split(signedHeaders, ";") {
+		if header != "host" && header != "content-length" {
+			resigned.Header.Set(header, r.Header.Get(header))
+		}
+	}
+	signer := v4.NewSigner(func(options *v4.SignerOptions) { options.DisableURIPathEscaping = true })
+	Expect(signer.SignHTTP(context.Background(), s.credentials, resigned, r.Header.Get("X-Amz-Content-Sha256"), "s3", s.region, signingTime)).To(Succeed())
+
+	sum := sha256.Sum256(body)
+	if resigned.Header.Get("Authorization") != authorization || hex.EncodeToString(sum[:]) != r.Header.Get("X-Amz-Content-Sha256") {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads = append(s.uploads, r)
+	s.bodies = append(s.bodies, body)
+}
+
+var _ = Describe("S3 publisher", func() {
+	var (
+		bucket *fakeS3
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		bucket = &fakeS3{
+			credentials: aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", SessionToken: "session-token"},
+			region:      "us-east-1",
+		}
+		server = httptest.NewServer(bucket)
+		DeferCleanup(server.Close)
+	})
+
+	newPublisher := func(credentials aws.Credentials) *archive.S3Publisher {
+		publisher, err := archive.NewS3Publisher(archive.S3Config{
+			Bucket:          "laffy-archive",
+			Region:          "us-east-1",
+			Endpoint:        server.URL,
+			AccessKeyID:     credentials.AccessKeyID,
+			SecretAccessKey: credentials.SecretAccessKey,
+			SessionToken:    credentials.SessionToken,
+			Prefix:          "archive/",
+			PublicURL:       "https://cdn.example.com/",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return publisher
+	}
+
+	It("signs uploads with the session token and the key as S3 canonicalizes it", func() {
+		publisher := newPublisher(bucket.credentials)
+
+		location, err := publisher.Publish(context.Background(), "conversation:100/a b+c~é.json", []byte(`{"id":"100"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(location.URI).To(Equal("s3://laffy-archive/archive/conversation:100/a b+c~é.json"))
+		Expect(location.URL).To(Equal("https://cdn.example.com/archive/conversation%3A100/a%20b%2Bc~%C3%A9.json"))
+
+		Expect(bucket.uploads).To(HaveLen(1))
+		upload := bucket.uploads[0]
+		Expect(upload.Method).To(Equal(http.MethodPut))
+		Expect(upload.RequestURI).To(Equal("/laffy-archive/archive/conversation%3A100/a%20b%2Bc~%C3%A9.json"))
+		Expect(upload.Header.Get("X-Amz-Security-Token")).To(Equal("session-token"))
+		Expect(upload.Header.Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+		Expect(upload.Header.Get("Authorization")).To(ContainSubstring("/us-east-1/s3/aws4_request"))
+		Expect(upload.Header.Get("Authorization")).To(ContainSubstring("x-amz-security-token"))
+		Expect(string(bucket.bodies[0])).To(Equal(`{"id":"100"}`))
+	})
+
+	It("reports uploads the bucket rejects", func() {
+		credentials := bucket.credentials
+		credentials.SessionToken = "expired-token"
+		publisher := newPublisher(credentials)
+
+		_, err := publisher.Publish(context.Background(), "conversation-100.json", []byte(`{}`))
+		Expect(err).To(MatchError(ContainSubstring("S3 upload failed (status 403)")))
+		Expect(bucket.uploads).To(BeEmpty())
+	})
+})
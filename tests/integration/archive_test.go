@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/ipfs"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Conversation archive", func() {
+	var (
+		pinning  *fakeIPFS
+		server   *httptest.Server
+		store    *memory.TweetStore
+		archiver *archive.Archiver
+		ctx      context.Context
+		cancel   context.CancelFunc
+	)
+
+	// mention stores a mention of the bot in the given conversation
+	mention := func(id, conversationID, text string) {
+		Expect(store.SaveTweet(ctx, twitter.Tweet{
+			ID:             id,
+			Text:           text,
+			AuthorID:       "user-1",
+			ConversationID: conversationID,
+			CreatedAt:      time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		}, memory.CategoryMention, "User One", "userone")).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		pinning = &fakeIPFS{}
+		server = httptest.NewServer(pinning)
+
+		database := newTestDatabase(logger)
+
+		var err error
+		store, err = memory.NewTweetStore(logger, database, "bot-1", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		pinner, err := ipfs.NewClient(&ipfs.Config{APIURL: server.URL, Logger: logger})
+		Expect(err).NotTo(HaveOccurred())
+		archiver = archive.New(store, archive.NewIPFSPublisher(pinner), logger, archive.Options{QuietPeriod: 50 * time.Millisecond})
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("pins quiet conversations the bot replied in and records the CID", func() {
+		mention("100", "100", "hey @bot")
+		Expect(store.SaveAgentReply(ctx, "100", "101", "100", "hey yourself")).To(Succeed())
+		mention("200", "200", "ignored, the bot never replied")
+
+		By("waiting until the conversation has gone quiet")
+		time.Sleep(100 * time.Millisecond)
+
+		archived, err := archiver.ArchiveCompleted(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(archived).To(Equal(1))
+
+		var document archive.Document
+		Expect(pinning.added).To(HaveLen(1))
+		Expect(json.Unmarshal([]byte(pinning.added[0]), &document)).To(Succeed())
+		Expect(document.ConversationID).To(Equal("100"))
+		Expect(document.BotID).To(Equal("bot-1"))
+		Expect(document.Tweets).To(HaveLen(2))
+		Expect(document.Tweets[0].Text).To(Equal("hey @bot"))
+		Expect(document.Tweets[1].AuthorID).To(Equal("bot-1"))
+
+		record, err := store.GetConversationArchive(ctx, "100")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(record).NotTo(BeNil())
+		Expect(record.URI).To(Equal("ipfs://bafytestcid"))
+		Expect(record.URL).To(Equal("https://ipfs.io/ipfs/bafytestcid"))
+		Expect(record.TweetCount).To(Equal(2))
+		Expect(record.SHA256).To(HaveLen(64))
+
+		By("skipping conversations already archived")
+		archived, err = archiver.ArchiveCompleted(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(archived).To(BeZero())
+	})
+
+	It("archives a conversation again after new tweets", func() {
+		mention("100", "100", "hey @bot")
+		Expect(store.SaveAgentReply(ctx, "100", "101", "100", "hey yourself")).To(Succeed())
+		time.Sleep(100 * time.Millisecond)
+		Expect(archiver.ArchiveCompleted(ctx)).To(Equal(1))
+
+		Expect(store.SaveAgentReply(ctx, "101", "102", "100", "still here")).To(Succeed())
+
+		By("waiting while the conversation is active")
+		Expect(archiver.ArchiveCompleted(ctx)).To(BeZero())
+
+		time.Sleep(100 * time.Millisecond)
+		Expect(archiver.ArchiveCompleted(ctx)).To(Equal(1))
+
+		record, err := store.GetConversationArchive(ctx, "100")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(record.TweetCount).To(Equal(3))
+	})
+})
@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Compliance", func() {
+	var (
+		logger     *logrus.Logger
+		server     *twittermock.Server
+		store      *memory.TweetStore
+		compliance *actions.ComplianceAction
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		compliance = actions.NewComplianceAction(client, store, logger, actions.ComplianceOptions{Interval: time.Minute})
+	})
+
+	queue := func(tweets ...twitter.Tweet) {
+		var items []memory.ReplyWorkItem
+		for _, tweet := range tweets {
+			Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+			items = append(items, memory.ReplyWorkItem{TweetID: tweet.ID, ConversationID: tweet.ConversationID})
+		}
+		Expect(store.EnqueueReplies(ctx, items)).To(Succeed())
+	}
+
+	It("soft-deletes queued tweets that no longer exist", func() {
+		kept := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+		gone := twitter.Tweet{ID: "1700", Text: "@mockbot regrettable", AuthorID: "7", ConversationID: kept.ID}
+		queue(kept, gone)
+
+		Expect(compliance.Verify(ctx)).To(Succeed())
+
+		queued, err := store.QueuedTweetIDs(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(ConsistOf(kept.ID))
+
+		thread, err := store.ReplyThread(ctx, kept.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(thread).NotTo(BeNil())
+		Expect(thread.Tweets).To(HaveLen(1))
+
+		deleted, err := store.GetTweet(ctx, gone.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted.Text).To(BeEmpty())
+		thread, err = store.ReplyThread(ctx, gone.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(thread).To(BeNil())
+	})
+
+	It("purges deleted tweets and the tweets of protected users", func() {
+		first := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hi", AuthorID: "7"})
+		second := server.AddMention("1000", twitter.Tweet{Text: "@mockbot private", AuthorID: "8"})
+		third := server.AddMention("1000", twitter.Tweet{Text: "@mockbot still here", AuthorID: "9"})
+		queue(first, second, third)
+
+		changed, err := compliance.Apply(ctx, []twitter.Compliance{
+			{EventType: twitter.ComplianceDelete, TweetID: first.ID},
+			{EventType: twitter.ComplianceUserProtect, UserID: "8"},
+			{EventType: "user_profile_modification", UserID: "9"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(Equal(int64(2)))
+
+		protected, err := store.GetTweet(ctx, second.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(protected.Text).To(BeEmpty())
+		Expect(protected.AuthorUsername).To(BeEmpty())
+
+		queued, err := store.QueuedTweetIDs(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(ConsistOf(third.ID))
+	})
+})
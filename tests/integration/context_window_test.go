@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Conversation context", func() {
+	var posts []thoughts.ContextPost
+
+	BeforeEach(func() {
+		posts = nil
+		for i := 0; i < 40; i++ {
+			posts = append(posts, thoughts.ContextPost{
+				AuthorUsername: fmt.Sprintf("user%d", i),
+				AuthorName:     "User",
+				Text:           fmt.Sprintf("post %d: %s", i, strings.Repeat("blah ", 10)),
+			})
+		}
+	})
+
+	It("keeps the first and the most recent posts within the token budget", func() {
+		builder := thoughts.NewContextBuilder("gpt-4o-mini", 200)
+
+		context := builder.Build(posts)
+		Expect(builder.CountTokens(context)).To(BeNumerically("<=", 200))
+		Expect(context).To(HavePrefix("Previous conversation:\n@user0 (User): post 0:"))
+		Expect(context).To(ContainSubstring("posts omitted to fit the context limit]\n"))
+		Expect(context).To(HaveSuffix("@user39 (User): post 39: " + strings.Repeat("blah ", 10) + "\n"))
+		Expect(context).NotTo(ContainSubstring("@user1 (User)"))
+	})
+
+	It("includes short conversations in full", func() {
+		builder := thoughts.NewContextBuilder("gpt-4o-mini", 0)
+		Expect(builder.Budget()).To(Equal(thoughts.DefaultContextTokens))
+
+		context := builder.Build(posts[:3])
+		Expect(context).NotTo(ContainSubstring("omitted"))
+		Expect(strings.Count(context, "\n")).To(Equal(4))
+	})
+})
@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Conversation notes", func() {
+	It("puts the active notes of the conversation into the reply prompt", func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server := twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err := memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+		global, err := memory.NewTweetStore(logger, database, "", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+		other, err := memory.NewTweetStore(logger, database, "2000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot wen moon", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Degen", "degen")).To(Succeed())
+
+		_, err = global.AddConversationNote(ctx, tweet.ConversationID, "stop mentioning token price", 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = store.AddConversationNote(ctx, tweet.ConversationID, "be nicer to this user", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = store.AddConversationNote(ctx, tweet.ConversationID, "ask about their cat", time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = other.AddConversationNote(ctx, tweet.ConversationID, "speak only in haiku", 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = store.AddConversationNote(ctx, "elsewhere", "talk like a pirate", 0)
+		Expect(err).NotTo(HaveOccurred())
+		time.Sleep(10 * time.Millisecond)
+
+		generator := &recordingReply{}
+		responder := actions.NewTweetResponder(store, client, logger, generator)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+
+		Expect(generator.configs).To(HaveLen(1))
+		Expect(generator.configs[0].Notes).To(Equal([]string{"stop mentioning token price", "be nicer to this user"}))
+
+		notes, err := store.ListConversationNotes(ctx, tweet.ConversationID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Credential monitor", func() {
+	var (
+		logger   *logrus.Logger
+		server   *twittermock.Server
+		client   *twitter.TwitterClient
+		store    *memory.TweetStore
+		bus      *events.Bus
+		received chan events.Event
+		monitor  *actions.CredentialMonitor
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		var err error
+		client, err = twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		bus = events.NewBus(logger)
+		DeferCleanup(bus.Close)
+		received = make(chan events.Event, 10)
+		bus.Subscribe(func(event events.Event) { received <- event }, events.CredentialsChanged)
+
+		monitor = actions.NewCredentialMonitor(client, store, logger, actions.CredentialMonitorOptions{
+			Interval: time.Hour,
+			Events:   bus,
+		})
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	// readyz asks the admin API whether the account's credentials work
+	readyz := func() (int, map[string]actions.CredentialStatus) {
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleReadiness(map[string]admin.CredentialChecker{"catlord": monitor})
+		response := httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var body struct {
+			Accounts map[string]actions.CredentialStatus `json:"accounts"`
+		}
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		return response.Code, body.Accounts
+	}
+
+	// runWorkers answers the reply queue in the background until the spec ends
+	runWorkers := func() {
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "noted"})
+		pool := actions.NewReplyWorkerPool(responder, logger, actions.ReplyWorkerOptions{
+			TweetsPerWindow: 9000,
+			PollInterval:    10 * time.Millisecond,
+			RetryDelay:      time.Millisecond,
+			Credentials:     monitor,
+		})
+		workerCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pool.Execute(workerCtx)
+		}()
+		DeferCleanup(func() {
+			stop()
+			<-done
+		})
+	}
+
+	It("holds replies while the credentials are revoked and resumes once they work", func() {
+		server.Fail(twittermock.Failure{Path: "/users/me", Status: http.StatusUnauthorized})
+		status, err := monitor.Check(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.State).To(Equal(actions.CredentialsRevoked))
+
+		var event events.Event
+		Eventually(received).Should(Receive(&event))
+		Expect(event.BotID).To(Equal("1000"))
+		Expect(event.Data).To(HaveKeyWithValue("state", "revoked"))
+		Expect(event.Data).To(HaveKeyWithValue("previous", "ok"))
+
+		code, accounts := readyz()
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(accounts["catlord"].State).To(Equal(actions.CredentialsRevoked))
+
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		runWorkers()
+		Consistently(server.Posted, 200*time.Millisecond).Should(BeEmpty())
+
+		server.ClearFailures()
+		status, err = monitor.Check(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.State).To(Equal(actions.CredentialsOK))
+		Eventually(received).Should(Receive(&event))
+		Expect(event.Data).To(HaveKeyWithValue("state", "ok"))
+
+		Eventually(server.Posted).Should(HaveLen(1))
+		code, _ = readyz()
+		Expect(code).To(Equal(http.StatusOK))
+	})
+
+	It("learns of rejected credentials from a failed reply and answers the tweet once they work", func() {
+		server.Fail(twittermock.Failure{Method: http.MethodPost, Path: "/tweets", Status: http.StatusUnauthorized})
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		runWorkers()
+
+		Eventually(monitor.Healthy).Should(BeFalse())
+		Expect(monitor.Status().Error).To(ContainSubstring("status=401"))
+		Expect(server.Posted()).To(BeEmpty())
+
+		// Only the one rejected reply reached the API
+		posts := func() int {
+			count := 0
+			for _, request := range server.Requests() {
+				if request.Method == http.MethodPost && request.Path == "/tweets" {
+					count++
+				}
+			}
+			return count
+		}
+		Consistently(posts, 200*time.Millisecond).Should(Equal(1))
+
+		server.ClearFailures()
+		_, err := monitor.Check(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(server.Posted).Should(HaveLen(1))
+	})
+
+	It("tells suspended accounts from failures that say nothing about the credentials", func() {
+		server.Fail(twittermock.Failure{Path: "/users/me", Status: http.StatusForbidden, Times: 1})
+		status, err := monitor.Check(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.State).To(Equal(actions.CredentialsSuspended))
+
+		server.Fail(twittermock.Failure{Path: "/users/me", Status: http.StatusBadGateway})
+		status, err = monitor.Check(ctx)
+		Expect(err).To(MatchError(ContainSubstring("failed to check credentials")))
+		Expect(status.State).To(Equal(actions.CredentialsSuspended))
+
+		code, accounts := readyz()
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(accounts["catlord"].State).To(Equal(actions.CredentialsSuspended))
+	})
+})
@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// pipelineModel drafts, critiques and revises replies with canned answers and
+// records every prompt
+type pipelineModel struct {
+	mu       sync.Mutex
+	critique string
+	prompts  []string
+}
+
+func (m *pipelineModel) GenerateContent(_ context.Context, messages []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	var prompt string
+	for _, part := range messages[len(messages)-1].Parts {
+		if text, ok := part.(llms.TextContent); ok {
+			prompt += text.Text
+		}
+	}
+
+	m.mu.Lock()
+	m.prompts = append(m.prompts, prompt)
+	m.mu.Unlock()
+
+	answer := "gm, peasant"
+	switch {
+	case strings.Contains(prompt, "You review the replies"):
+		answer = m.critique
+	case strings.Contains(prompt, "revising a reply"):
+		answer = "gm, loyal subject. The moon awaits."
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: answer}}}, nil
+}
+
+func (m *pipelineModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+var _ = Describe("Reply self-critique", func() {
+	var (
+		logger *logrus.Logger
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	It("posts the revision and stores the draft, critique and revision", func() {
+		server := twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+		store, err := memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm, wen moon?", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+
+		model := &pipelineModel{critique: "Ignores the question about the moon."}
+		responder := actions.NewTweetResponder(store, client, logger, thoughts.NewMentionReplyGenerator(model)).
+			WithCritic(thoughts.NewReplyCritic(model))
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+
+		posted := server.Posted()
+		Expect(posted).To(HaveLen(1))
+		Expect(posted[0].Text).To(Equal("gm, loyal subject. The moon awaits."))
+		Expect(model.prompts).To(HaveLen(3))
+		Expect(model.prompts[1]).To(ContainSubstring("Drafted reply: gm, peasant"))
+		Expect(model.prompts[2]).To(ContainSubstring("Ignores the question about the moon."))
+
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleReplyStages(map[string]admin.ReplyStageLog{"catlord": store})
+		response := httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/replies/stages?since=1h", nil))
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body struct {
+			Replies []memory.ReplyStages `json:"replies"`
+		}
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Replies).To(HaveLen(1))
+		Expect(body.Replies[0].ReplyID).To(Equal(posted[0].ID))
+		Expect(body.Replies[0].TweetID).To(Equal(mention.ID))
+		Expect(body.Replies[0].Draft).To(Equal("gm, peasant"))
+		Expect(body.Replies[0].Critique).To(Equal("Ignores the question about the moon."))
+		Expect(body.Replies[0].Revision).To(Equal("gm, loyal subject. The moon awaits."))
+
+		response = httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/replies/stages?since=soon", nil))
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("keeps an approved draft unless it repeats a reply that fell flat", func() {
+		model := &pipelineModel{critique: "APPROVED"}
+		critic := thoughts.NewReplyCritic(model)
+		config := thoughts.MentionReplyConfig{TweetText: "@mockbot gm", MaxLength: 280, Temperature: 0.7}
+
+		review, err := critic.Review(ctx, config, "gm, peasant")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(review.Critique).To(Equal("APPROVED"))
+		Expect(review.Revision).To(BeEmpty())
+		Expect(review.Final()).To(Equal("gm, peasant"))
+		Expect(model.prompts).To(HaveLen(1))
+
+		config.Avoid = []string{"gm, peasant!"}
+		review, err = critic.Review(ctx, config, "gm, peasant")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(review.Final()).To(Equal("gm, loyal subject. The moon awaits."))
+		Expect(model.prompts).To(HaveLen(3))
+		Expect(model.prompts[1]).To(ContainSubstring("nearly repeats this reply of yours that fell flat"))
+	})
+})
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Curator", func() {
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+		client *twitter.TwitterClient
+		store  *memory.TweetStore
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		var err error
+		client, err = twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	judge := func(subjectID, username, tweetID string, overall float64) {
+		Expect(store.SaveJudgment(ctx, &memory.JudgmentRecord{
+			SubjectID:       subjectID,
+			SubjectUsername: username,
+			TweetID:         "decree-" + tweetID,
+			SubjectTweetID:  tweetID,
+			Scores:          map[string]int{"Bio Cringe Factor": int(overall)},
+			Overall:         overall,
+			Verdict:         "guilty",
+		})).To(Succeed())
+	}
+
+	It("lists and bookmarks highly scored subjects once", func() {
+		judge("7", "fan", "70", 8.5)
+		judge("8", "meh", "80", 3)
+
+		curator := actions.NewCuratorAction(client, store, logger, actions.CuratorOptions{})
+		Expect(curator.Curate(ctx)).To(Succeed())
+
+		lists := server.Lists()
+		Expect(lists).To(HaveLen(1))
+		Expect(lists[0].Name).To(Equal(actions.DefaultCuratorListName))
+		Expect(server.ListMembers(lists[0].ID)).To(ConsistOf("7"))
+		Expect(server.Bookmarks()).To(ConsistOf("70"))
+
+		judge("9", "star", "90", 9)
+		curator = actions.NewCuratorAction(client, store, logger, actions.CuratorOptions{})
+		Expect(curator.Curate(ctx)).To(Succeed())
+
+		Expect(server.Lists()).To(HaveLen(1), "the existing list is reused")
+		Expect(server.ListMembers(lists[0].ID)).To(Equal([]string{"7", "9"}))
+		Expect(server.Bookmarks()).To(Equal([]string{"70", "90"}))
+
+		pending, err := store.UncuratedJudgments(ctx, 7, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(BeEmpty())
+	})
+
+	It("reads list tweets and removes bookmarks", func() {
+		list, err := client.CreateList(ctx, twitter.CreateListParams{Name: "friends", Private: true})
+		Expect(err).NotTo(HaveOccurred())
+		member, err := client.AddListMember(ctx, list.ID, "7")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(member).To(BeTrue())
+
+		tweet := server.AddTweet(twitter.Tweet{Text: "gm", AuthorID: "7"})
+		server.AddTweet(twitter.Tweet{Text: "gm", AuthorID: "8"})
+
+		resp, err := client.GetListTweets(ctx, list.ID, twitter.GetListTweetsParams{MaxResults: 10})
+		Expect(err).NotTo(HaveOccurred())
+		tweets, err := resp.UnmarshalTweets()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tweets).To(HaveLen(1))
+		Expect(tweets[0].ID).To(Equal(tweet.ID))
+
+		Expect(client.AddBookmark(ctx, tweet.ID)).To(Succeed())
+		Expect(server.Bookmarks()).To(ConsistOf(tweet.ID))
+		Expect(client.RemoveBookmark(ctx, tweet.ID)).To(Succeed())
+		Expect(server.Bookmarks()).To(BeEmpty())
+	})
+})
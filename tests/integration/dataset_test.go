@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/dataset"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Training dataset", func() {
+	var (
+		store *memory.TweetStore
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		database := newTestDatabase(logger)
+
+		var err error
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+		store.SetIdentity("", "mockbot")
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		// A conversation the bot answered twice; the first reply was liked
+		Expect(store.SaveTweet(ctx, twitter.Tweet{ID: "100", Text: "@mockbot gm, mail me at fan@example.com", AuthorID: "7", ConversationID: "100"},
+			memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(store.SaveAgentReply(ctx, "100", "101", "100", "@fan gm human")).To(Succeed())
+		Expect(store.SaveTweet(ctx, twitter.Tweet{ID: "102", Text: "@mockbot see https://example.com", AuthorID: "7", ConversationID: "100"},
+			memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(store.SaveAgentReply(ctx, "102", "103", "100", "@fan no")).To(Succeed())
+
+		// Tweets saved within one second would tie on created_at
+		for i, id := range []string{"100", "101", "102", "103"} {
+			Expect(database.Table("tweets").Where("id = ?", id).
+				Update("created_at", time.Now().Add(time.Duration(i-10)*time.Minute)).Error).To(Succeed())
+		}
+
+		liked := twitter.Tweet{ID: "101"}
+		liked.PublicMetrics.LikeCount = 3
+		liked.PublicMetrics.RetweetCount = 1
+		Expect(store.UpdatePublicMetrics(ctx, []twitter.Tweet{liked})).To(Succeed())
+	})
+
+	build := func(options dataset.Options) []dataset.Example {
+		replies, err := store.TrainingReplies(ctx, time.Now().Add(-time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		examples, err := dataset.Build(ctx, store, replies, options)
+		Expect(err).NotTo(HaveOccurred())
+		return examples
+	}
+
+	It("pairs each reply with its conversation and engagement", func() {
+		examples := build(dataset.Options{KeepPII: true})
+		Expect(examples).To(HaveLen(2))
+
+		Expect(examples[0].ReplyID).To(Equal("101"))
+		Expect(examples[0].Context).To(Equal([]dataset.Turn{{Role: "user", Text: "@mockbot gm, mail me at fan@example.com"}}))
+		Expect(examples[0].Engagement).To(Equal(dataset.Engagement{Likes: 3, Retweets: 1, Score: 5}))
+
+		Expect(examples[1].ReplyID).To(Equal("103"))
+		Expect(examples[1].Context).To(Equal([]dataset.Turn{
+			{Role: "user", Text: "@mockbot gm, mail me at fan@example.com"},
+			{Role: "assistant", Text: "@fan gm human"},
+			{Role: "user", Text: "@mockbot see https://example.com"},
+		}))
+	})
+
+	It("redacts personal information and skips low engagement replies", func() {
+		examples := build(dataset.Options{MinEngagement: 1})
+		Expect(examples).To(HaveLen(1))
+		Expect(examples[0].Context[0].Text).To(Equal("@mockbot gm, mail me at <email>"))
+		Expect(examples[0].Reply).To(Equal("@user gm human"))
+	})
+
+	It("keeps conversations in one split and writes chat examples", func() {
+		examples := build(dataset.Options{ContextTweets: 2})
+		Expect(examples[1].Context).To(HaveLen(2))
+
+		train, validation := dataset.Split(examples, 0.5)
+		Expect(len(train) == 2 || len(validation) == 2).To(BeTrue())
+
+		var buf bytes.Buffer
+		Expect(dataset.Write(&buf, examples, dataset.FormatChat)).To(Succeed())
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		var chat struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		Expect(json.Unmarshal([]byte(lines[1]), &chat)).To(Succeed())
+		Expect(chat.Messages).To(HaveLen(3))
+		Expect(chat.Messages[2].Role).To(Equal("assistant"))
+		Expect(chat.Messages[2].Content).To(Equal("@user no"))
+	})
+})
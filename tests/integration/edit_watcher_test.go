@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Edit watcher", func() {
+	var (
+		logger  *logrus.Logger
+		server  *twittermock.Server
+		client  *twitter.TwitterClient
+		store   *memory.TweetStore
+		bus     *events.Bus
+		watcher *actions.EditWatcher
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		var err error
+		client, err = twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		bus = events.NewBus(logger)
+		DeferCleanup(bus.Close)
+		watcher = actions.NewEditWatcher(client, store, logger, actions.EditWatchOptions{
+			Interval: time.Minute,
+			Events:   bus,
+		})
+	})
+
+	It("stores the edited text of a mention waiting for a reply", func() {
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+
+		Expect(watcher.Check(ctx)).To(Succeed())
+		stored, err := store.GetTweet(ctx, mention.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Text).To(Equal("@mockbot gm"))
+
+		received := make(chan events.Event, 1)
+		bus.Subscribe(func(event events.Event) { received <- event }, events.TweetEdited)
+
+		edit := server.EditTweet(mention.ID, "@mockbot gm, how are the cats?")
+		Expect(watcher.Check(ctx)).To(Succeed())
+
+		stored, err = store.GetTweet(ctx, mention.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Text).To(Equal("@mockbot gm, how are the cats?"))
+		mentions, err := store.EditableMentions(ctx, time.Now().Add(-time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mentions).To(HaveLen(1))
+		Expect([]string(mentions[0].EditHistory)).To(Equal([]string{mention.ID, edit.ID}))
+
+		var event events.Event
+		Eventually(received).Should(Receive(&event))
+		Expect(event.Data["version_id"]).To(Equal(edit.ID))
+		Expect(event.Data["edited_after_reply"]).To(BeFalse())
+
+		edited, err := store.EditedAfterReply(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(edited).To(BeEmpty())
+
+		// Edits already recorded are not picked up again
+		Expect(watcher.Check(ctx)).To(Succeed())
+		Expect(countRequests(server, "GET", "/tweets")).To(Equal(4))
+	})
+
+	It("flags mentions edited after the bot replied until the reply is redone", func() {
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot wen token", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(store.SaveAgentReply(ctx, mention.ID, "1900", mention.ConversationID, "soon")).To(Succeed())
+
+		server.EditTweet(mention.ID, "@mockbot wen token (asking for a friend)")
+		Expect(watcher.Check(ctx)).To(Succeed())
+
+		edited, err := store.EditedAfterReply(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(edited).To(HaveLen(1))
+		Expect(edited[0].TweetID).To(Equal(mention.ID))
+		Expect(edited[0].LastReplyID).To(Equal("1900"))
+		Expect(edited[0].Text).To(Equal("@mockbot wen token (asking for a friend)"))
+
+		Expect(store.ReplaceAgentReply(ctx, mention.ID, "1900", "1901", mention.ConversationID, "soon, friend")).To(Succeed())
+		edited, err = store.EditedAfterReply(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(edited).To(BeEmpty())
+	})
+})
@@ -0,0 +1,170 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// natsMessage is a PUB a fakeNATSServer received
+type natsMessage struct {
+	subject string
+	payload []byte
+}
+
+// fakeNATSServer speaks enough of the NATS core protocol for a publisher:
+// it greets with INFO, answers PINGs and records CONNECT options and PUBs
+type fakeNATSServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	conns    []net.Conn
+	connects []map[string]any
+	messages []natsMessage
+}
+
+func newFakeNATSServer(addr string) *fakeNATSServer {
+	listener, err := net.Listen("tcp", addr)
+	Expect(err).NotTo(HaveOccurred())
+	server := &fakeNATSServer{listener: listener}
+	DeferCleanup(server.Close)
+	go server.serve()
+	return server
+}
+
+func (s *fakeNATSServer) URL(userinfo string) string {
+	return fmt.Sprintf("nats://%s%s", userinfo, s.listener.Addr())
+}
+
+func (s *fakeNATSServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+// Close stops accepting connections and drops the open ones
+func (s *fakeNATSServer) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *fakeNATSServer) handle(conn net.Conn) {
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"version\":\"2.10.0\",\"proto\":1,\"max_payload\":1048576}\r\n")
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch strings.ToUpper(op) {
+		case "CONNECT":
+			var options map[string]any
+			Expect(json.Unmarshal([]byte(args), &options)).To(Succeed())
+			s.mu.Lock()
+			s.connects = append(s.connects, options)
+			s.mu.Unlock()
+		case "PING":
+			io.WriteString(conn, "PONG\r\n")
+		case "PUB":
+			fields := strings.Fields(args)
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			Expect(err).NotTo(HaveOccurred())
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, natsMessage{subject: fields[0], payload: payload[:size]})
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *fakeNATSServer) Messages() []natsMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]natsMessage(nil), s.messages...)
+}
+
+func (s *fakeNATSServer) Connects() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.connects...)
+}
+
+var _ = Describe("NATS publisher", func() {
+	publish := func(publisher *events.NATSPublisher, timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return publisher.Publish(ctx, events.New(events.ReplyPosted, "1000", map[string]interface{}{"reply_id": "42"}))
+	}
+
+	It("publishes each event to its type's subject once the server has it", func() {
+		server := newFakeNATSServer("127.0.0.1:0")
+		publisher, err := events.NewNATSPublisher(server.URL("agent:s3cret@"), "agent.events")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(publisher.Close)
+
+		Expect(publish(publisher, 5*time.Second)).To(Succeed())
+		messages := server.Messages()
+		Expect(messages).To(HaveLen(1))
+		Expect(messages[0].subject).To(Equal("agent.events.reply_posted"))
+		var event events.Event
+		Expect(json.Unmarshal(messages[0].payload, &event)).To(Succeed())
+		Expect(event.Type).To(Equal(events.ReplyPosted))
+		Expect(event.Data).To(HaveKeyWithValue("reply_id", "42"))
+
+		connects := server.Connects()
+		Expect(connects).To(HaveLen(1))
+		Expect(connects[0]).To(HaveKeyWithValue("user", "agent"))
+		Expect(connects[0]).To(HaveKeyWithValue("pass", "s3cret"))
+		Expect(connects[0]).To(HaveKeyWithValue("name", "agent-go"))
+	})
+
+	It("reports events the server did not confirm and sends them after it comes up", func() {
+		// Reserve an address nothing listens on yet
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		publisher, err := events.NewNATSPublisher("nats://"+addr, "agent.events")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publish(publisher, 100*time.Millisecond)).To(MatchError(ContainSubstring("failed to publish to NATS")))
+
+		server := newFakeNATSServer(addr)
+		DeferCleanup(publisher.Close)
+		Eventually(server.Messages, 10*time.Second).Should(HaveLen(1))
+		Expect(server.Messages()[0].subject).To(Equal("agent.events.reply_posted"))
+		Expect(publish(publisher, 5*time.Second)).To(Succeed())
+		Expect(server.Messages()).To(HaveLen(2))
+	})
+
+	It("rejects subjects with whitespace", func() {
+		_, err := events.NewNATSPublisher("nats://127.0.0.1:4222", "agent events")
+		Expect(err).To(MatchError(ContainSubstring("invalid NATS subject")))
+	})
+})
@@ -0,0 +1,240 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/farcaster"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+const botFID = 4242
+
+// fakeNeynar serves canned notifications and records published casts
+type fakeNeynar struct {
+	mu            sync.Mutex
+	notifications []map[string]interface{}
+	published     []map[string]interface{}
+	apiKeys       []string
+}
+
+func (f *fakeNeynar) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /notifications", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.apiKeys = append(f.apiKeys, r.Header.Get("x-api-key"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"notifications": f.notifications,
+			"next":          map[string]string{"cursor": ""},
+		})
+	})
+	mux.HandleFunc("POST /cast", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.published = append(f.published, body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"cast": map[string]interface{}{
+				"hash":   fmt.Sprintf("0xreply%d", len(f.published)),
+				"author": map[string]interface{}{"fid": botFID},
+				"text":   body["text"],
+			},
+		})
+	})
+	return mux
+}
+
+// notification builds a Neynar mention notification
+func notification(hash, threadHash, parentHash string, authorFID int, username, text string, at time.Time) map[string]interface{} {
+	cast := map[string]interface{}{
+		"hash":        hash,
+		"thread_hash": threadHash,
+		"author":      map[string]interface{}{"fid": authorFID, "username": username, "display_name": "User " + username},
+		"text":        text,
+		"timestamp":   at.UTC().Format(time.RFC3339),
+	}
+	if parentHash != "" {
+		cast["parent_hash"] = parentHash
+		cast["parent_author"] = map[string]interface{}{"fid": botFID}
+	}
+	return map[string]interface{}{"type": "mention", "cast": cast}
+}
+
+// cannedReply always generates the same reply
+type cannedReply struct{ text string }
+
+func (c cannedReply) GenerateReply(ctx context.Context, config thoughts.MentionReplyConfig) (string, error) {
+	return c.text, nil
+}
+
+var _ = Describe("Farcaster", func() {
+	var (
+		neynar *fakeNeynar
+		server *httptest.Server
+		client *farcaster.Client
+		logger *logrus.Logger
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		neynar = &fakeNeynar{}
+		server = httptest.NewServer(neynar.handler())
+
+		var err error
+		client, err = farcaster.NewClient(&farcaster.Config{
+			FID:          botFID,
+			Username:     "catlord",
+			NeynarAPIKey: "test-key",
+			NeynarURL:    server.URL,
+			SignerUUID:   "test-signer",
+			Logger:       logger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("converts mentions into tweets with namespaced authors", func() {
+		neynar.notifications = []map[string]interface{}{
+			notification("0xb", "0xa", "0xa", 7, "fan", "gm @catlord", time.Now()),
+		}
+
+		page, err := client.GetMentions(ctx, farcaster.GetMentionsParams{Limit: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Casts).To(HaveLen(1))
+		Expect(neynar.apiKeys).To(ConsistOf("test-key"))
+
+		tweet := page.Casts[0].ToTweet()
+		Expect(tweet.ID).To(Equal("0xb"))
+		Expect(tweet.AuthorID).To(Equal("fc:7"))
+		Expect(tweet.ConversationID).To(Equal("0xa"))
+		Expect(tweet.InReplyToUserID).To(Equal(farcaster.UserID(botFID)))
+		Expect(tweet.ReferencedTweets).To(HaveLen(1))
+		Expect(tweet.ReferencedTweets[0].ID).To(Equal("0xa"))
+	})
+
+	It("reads mentions from a hub", func() {
+		hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v1/castsByMention"))
+			Expect(r.URL.Query().Get("fid")).To(Equal("4242"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"messages": []map[string]interface{}{
+					{
+						"hash": "0xc",
+						"data": map[string]interface{}{
+							"type":        "MESSAGE_TYPE_CAST_ADD",
+							"fid":         9,
+							"timestamp":   100,
+							"castAddBody": map[string]interface{}{"text": " hello"},
+						},
+					},
+					{
+						"hash": "0xd",
+						"data": map[string]interface{}{"type": "MESSAGE_TYPE_REACTION_ADD", "fid": 9},
+					},
+				},
+			})
+		}))
+		defer hub.Close()
+
+		hubClient, err := farcaster.NewClient(&farcaster.Config{
+			FID:          botFID,
+			NeynarAPIKey: "test-key",
+			HubURL:       hub.URL,
+			Logger:       logger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		page, err := hubClient.GetMentions(ctx, farcaster.GetMentionsParams{Limit: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Casts).To(HaveLen(1))
+		Expect(page.Casts[0].Author.FID).To(BeEquivalentTo(9))
+		Expect(page.Casts[0].Timestamp).To(Equal(time.Date(2021, 1, 1, 0, 1, 40, 0, time.UTC)))
+		Expect(page.Casts[0].ToTweet().ConversationID).To(Equal("0xc"))
+	})
+
+	It("stores mentions once and replies to them in the thread", func() {
+		database := newTestDatabase(logger)
+
+		store, err := memory.NewTweetStore(logger, database, farcaster.UserID(botFID), config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		neynar.notifications = []map[string]interface{}{
+			notification("0xb", "0xb", "", 7, "fan", "gm @catlord", time.Now().Add(-time.Minute)),
+			notification("0xself", "0xself", "", botFID, "catlord", "my own cast", time.Now()),
+		}
+
+		handler := actions.NewFarcasterMentionsHandler(client, store, cannedReply{text: "gm fren"}, logger, actions.FarcasterMentionsOptions{})
+
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+		Expect(handler.ReplyToMentions(ctx)).To(Succeed())
+
+		Expect(neynar.published).To(HaveLen(1))
+		Expect(neynar.published[0]).To(HaveKeyWithValue("parent", "0xb"))
+		Expect(neynar.published[0]).To(HaveKeyWithValue("signer_uuid", "test-signer"))
+		Expect(neynar.published[0]).To(HaveKeyWithValue("text", "gm fren"))
+
+		Expect(store.HasTweet(ctx, "0xb")).To(BeTrue())
+		Expect(store.HasTweet(ctx, "0xself")).To(BeFalse())
+
+		By("not replying twice")
+		Expect(handler.ReplyToMentions(ctx)).To(Succeed())
+		Expect(neynar.published).To(HaveLen(1))
+	})
+
+	It("records replies instead of publishing them in dry-run mode", func() {
+		database := newTestDatabase(logger)
+
+		store, err := memory.NewTweetStore(logger, database, farcaster.UserID(botFID), config.Default())
+		Expect(err).NotTo(HaveOccurred())
+		client.SetDryRun(store)
+
+		neynar.notifications = []map[string]interface{}{
+			notification("0xb", "0xb", "", 7, "fan", "gm @catlord", time.Now().Add(-time.Minute)),
+		}
+
+		handler := actions.NewFarcasterMentionsHandler(client, store, cannedReply{text: "gm fren"}, logger, actions.FarcasterMentionsOptions{})
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+		Expect(handler.ReplyToMentions(ctx)).To(Succeed())
+		Expect(neynar.published).To(BeEmpty())
+
+		recorded, err := store.ListDryRunPosts(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0].Kind).To(Equal(string(dryrun.KindCast)))
+		Expect(recorded[0].Target).To(Equal("0xb"))
+		Expect(recorded[0].Content).To(Equal("gm fren"))
+
+		By("not drafting the reply twice")
+		Expect(handler.ReplyToMentions(ctx)).To(Succeed())
+		recorded, err = store.ListDryRunPosts(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorded).To(HaveLen(1))
+	})
+})
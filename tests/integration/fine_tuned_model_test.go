@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm/openai"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// modelRecorder records the model each call asked for and fails the calls to
+// models listed in broken
+type modelRecorder struct {
+	mu     sync.Mutex
+	broken map[string]bool
+	models []string
+}
+
+func (m *modelRecorder) GenerateContent(_ context.Context, _ []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	m.mu.Lock()
+	m.models = append(m.models, opts.Model)
+	broken := m.broken[opts.Model]
+	m.mu.Unlock()
+
+	if broken {
+		return nil, errors.New("model not found")
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "ok from " + opts.Model}}}, nil
+}
+
+func (m *modelRecorder) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *modelRecorder) calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.models...)
+}
+
+var _ = Describe("Fine-tuned models", func() {
+	const fineTune = "ft:gpt-4o-mini:acme::abc123"
+
+	var (
+		logger *logrus.Logger
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	It("sends calls to the fine-tuned model", func() {
+		base := &modelRecorder{}
+		model := openai.NewFineTunedModel(base, fineTune, true, time.Minute, logger)
+
+		answer, err := model.Call(ctx, "gm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(answer).To(Equal("ok from " + fineTune))
+		Expect(base.calls()).To(Equal([]string{fineTune}))
+	})
+
+	It("falls back to the base model and stays there for the cooldown", func() {
+		base := &modelRecorder{broken: map[string]bool{fineTune: true}}
+		model := openai.NewFineTunedModel(base, fineTune, true, time.Minute, logger)
+
+		answer, err := model.Call(ctx, "gm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(answer).To(Equal("ok from "))
+
+		_, err = model.Call(ctx, "gm again")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(base.calls()).To(Equal([]string{fineTune, "", ""}))
+	})
+
+	It("tries the fine-tuned model again after the cooldown", func() {
+		base := &modelRecorder{broken: map[string]bool{fineTune: true}}
+		model := openai.NewFineTunedModel(base, fineTune, true, 50*time.Millisecond, logger)
+
+		_, err := model.Call(ctx, "gm")
+		Expect(err).NotTo(HaveOccurred())
+		time.Sleep(100 * time.Millisecond)
+		_, err = model.Call(ctx, "gm again")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(base.calls()).To(Equal([]string{fineTune, "", fineTune, ""}))
+	})
+
+	It("returns the error when fallback is off", func() {
+		base := &modelRecorder{broken: map[string]bool{fineTune: true}}
+		model := openai.NewFineTunedModel(base, fineTune, false, time.Minute, logger)
+
+		_, err := model.Call(ctx, "gm")
+		Expect(err).To(MatchError(ContainSubstring("model not found")))
+		Expect(base.calls()).To(Equal([]string{fineTune}))
+	})
+})
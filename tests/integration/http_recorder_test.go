@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+var _ = Describe("HTTP recording", func() {
+	var (
+		logger *logrus.Logger
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("records redacted exchanges that replay without the API", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "http", "default.jsonl")
+		recorder, err := twitter.NewFileRecorder(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		server := twittermock.NewServer()
+		server.AddUser(twitter.User{ID: "7", Name: "Fan", Username: "fan"})
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello", AuthorID: "7"})
+
+		client, err := twitter.NewTwitterClient(server.Config(logger), twitter.WithHTTPRecorder(recorder))
+		Expect(err).NotTo(HaveOccurred())
+		recorded, err := fetchMentions(ctx, client, twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10})
+		Expect(err).NotTo(HaveOccurred())
+		server.Close()
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"Authorization":["REDACTED"]`))
+		Expect(string(data)).NotTo(ContainSubstring("mock-access-token"))
+		Expect(string(data)).NotTo(ContainSubstring("mock-bearer-token"))
+
+		replay, err := twitter.NewReplayTransport(path)
+		Expect(err).NotTo(HaveOccurred())
+		cfg := server.Config(logger)
+		cfg.HTTPClient = &http.Client{Transport: replay}
+		client, err = twitter.NewTwitterClient(cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		replayed, err := fetchMentions(ctx, client, twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10})
+		Expect(err).NotTo(HaveOccurred())
+		tweets, err := replayed.UnmarshalTweets()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tweets).To(HaveLen(1))
+		Expect(tweets[0].ID).To(Equal(mention.ID))
+		Expect(replayed.Includes.Users).To(Equal(recorded.Includes.Users))
+	})
+
+	It("logs a sample of successful requests and every failure, redacted", func() {
+		logger.SetLevel(logrus.DebugLevel)
+		hook := test.NewLocal(logger)
+
+		server := twittermock.NewServer()
+		defer server.Close()
+		server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello", AuthorID: "7"})
+
+		client, err := twitter.NewTwitterClient(server.Config(logger),
+			twitter.WithRequestLogging(twitter.RequestLogOptions{SampleRate: 1000, BodyLimit: 16}))
+		Expect(err).NotTo(HaveOccurred())
+
+		params := twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10}
+		for i := 0; i < 3; i++ {
+			_, err := fetchMentions(ctx, client, params)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		server.Fail(twittermock.Failure{Path: "/users/1000/mentions", Status: http.StatusServiceUnavailable})
+		_, err = fetchMentions(ctx, client, params)
+		Expect(err).To(HaveOccurred())
+
+		var sampled, failed []*logrus.Entry
+		for _, entry := range hook.AllEntries() {
+			switch entry.Message {
+			case "Twitter API request":
+				sampled = append(sampled, entry)
+			case "Twitter API request failed":
+				failed = append(failed, entry)
+			}
+			Expect(fmt.Sprint(entry.Data)).NotTo(ContainSubstring("mock-access-token"))
+			Expect(fmt.Sprint(entry.Data)).NotTo(ContainSubstring("mock-bearer-token"))
+		}
+
+		Expect(sampled).To(HaveLen(1))
+		Expect(sampled[0].Data).To(HaveKeyWithValue("sample_rate", 1000))
+		Expect(sampled[0].Data["headers"]).To(HaveKeyWithValue("Authorization", []string{"REDACTED"}))
+		Expect(failed).To(HaveLen(1))
+		Expect(failed[0].Data).To(HaveKeyWithValue("status_code", http.StatusServiceUnavailable))
+		Expect(failed[0].Data["response_body"]).To(HaveSuffix("...<truncated>"))
+	})
+})
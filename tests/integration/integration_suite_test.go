@@ -1,13 +1,34 @@
 package integration
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/db"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 func TestIntegration(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Integration Suite")
 }
+
+// newTestDatabase opens a migrated SQLite database in the spec's temporary
+// directory and closes it when the spec ends
+func newTestDatabase(logger *logrus.Logger) *gorm.DB {
+	settings := config.Default().Database
+	settings.Driver = "sqlite"
+	settings.Path = filepath.Join(GinkgoT().TempDir(), "agent.db")
+	database, err := db.SetupDatabaseWithConfig(logger, settings)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() {
+		sqlDB, err := database.DB()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sqlDB.Close()).To(Succeed())
+	})
+	return database
+}
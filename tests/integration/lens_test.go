@@ -0,0 +1,283 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/lens"
+	"github.com/lisanmuaddib/agent-go/pkg/ipfs"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// Well-known development key; never holds funds
+const lensTestKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// fakeLens answers the Lens API operations used for publishing
+type fakeLens struct {
+	mu          sync.Mutex
+	operations  []string
+	requests    []map[string]interface{}
+	tokens      []string
+	loginSigned string
+	broadcasts  []map[string]interface{}
+}
+
+const lensChallengeText = "agent-go wants you to sign in with your Ethereum account"
+
+func (f *fakeLens) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	request, _ := body.Variables["request"].(map[string]interface{})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var data interface{}
+	switch {
+	case strings.Contains(body.Query, "challenge("):
+		f.operations = append(f.operations, "challenge")
+		data = map[string]interface{}{"challenge": map[string]string{"id": "challenge-1", "text": lensChallengeText}}
+	case strings.Contains(body.Query, "authenticate("):
+		f.operations = append(f.operations, "authenticate")
+		f.loginSigned, _ = request["signature"].(string)
+		data = map[string]interface{}{"authenticate": map[string]string{"accessToken": "token-1", "refreshToken": "refresh-1"}}
+	case strings.Contains(body.Query, "createOnchainPostTypedData("):
+		f.operations = append(f.operations, "post")
+		f.requests = append(f.requests, request)
+		f.tokens = append(f.tokens, r.Header.Get("x-access-token"))
+		data = map[string]interface{}{"createOnchainPostTypedData": typedDataFixture("Post", request)}
+	case strings.Contains(body.Query, "createOnchainCommentTypedData("):
+		f.operations = append(f.operations, "comment")
+		f.requests = append(f.requests, request)
+		f.tokens = append(f.tokens, r.Header.Get("x-access-token"))
+		data = map[string]interface{}{"createOnchainCommentTypedData": typedDataFixture("Comment", request)}
+	case strings.Contains(body.Query, "broadcastOnchain("):
+		f.operations = append(f.operations, "broadcast")
+		f.broadcasts = append(f.broadcasts, request)
+		data = map[string]interface{}{"broadcastOnchain": map[string]string{
+			"__typename": "RelaySuccess",
+			"txHash":     "0xfeed",
+			"txId":       "relay-1",
+		}}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]string{{"message": "unknown operation"}}})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// typedDataFixture mirrors the typed data the API returns for a publication
+func typedDataFixture(primaryType string, request map[string]interface{}) map[string]interface{} {
+	fields := []map[string]string{
+		{"name": "profileId", "type": "uint256"},
+		{"name": "contentURI", "type": "string"},
+		{"name": "actionModules", "type": "address[]"},
+		{"name": "actionModulesInitDatas", "type": "bytes[]"},
+		{"name": "referenceModule", "type": "address"},
+		{"name": "referenceModuleInitData", "type": "bytes"},
+		{"name": "nonce", "type": "uint256"},
+		{"name": "deadline", "type": "uint256"},
+	}
+	value := map[string]interface{}{
+		"nonce":                   0,
+		"deadline":                1700000000,
+		"profileId":               "0x01",
+		"contentURI":              request["contentURI"],
+		"actionModules":           []string{},
+		"actionModulesInitDatas":  []string{},
+		"referenceModule":         "0x0000000000000000000000000000000000000000",
+		"referenceModuleInitData": "0x",
+	}
+	if primaryType == "Comment" {
+		fields = append(fields, map[string]string{"name": "pointedProfileId", "type": "uint256"}, map[string]string{"name": "pointedPubId", "type": "uint256"})
+		value["pointedProfileId"] = "0x02"
+		value["pointedPubId"] = "0x03"
+	}
+
+	return map[string]interface{}{
+		"id": "typed-data-1",
+		"typedData": map[string]interface{}{
+			"types": map[string]interface{}{primaryType: fields},
+			"domain": map[string]interface{}{
+				"name":              "Lens Protocol Profiles",
+				"chainId":           80002,
+				"version":           "2",
+				"verifyingContract": "0xA2574D9DdB6A325Ad2Be838Bd854228B80215148",
+			},
+			"value": value,
+		},
+	}
+}
+
+// fakeIPFS pins by returning a fixed CID and keeps what was added
+type fakeIPFS struct {
+	mu    sync.Mutex
+	added []string
+	auth  []string
+}
+
+func (f *fakeIPFS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	Expect(r.URL.Path).To(Equal("/api/v0/add"))
+	Expect(r.URL.Query().Get("pin")).To(Equal("true"))
+
+	file, _, err := r.FormFile("file")
+	Expect(err).NotTo(HaveOccurred())
+	content, _ := io.ReadAll(file)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.added = append(f.added, string(content))
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	json.NewEncoder(w).Encode(map[string]string{"Name": "metadata.json", "Hash": "bafytestcid", "Size": "42"})
+}
+
+var _ = Describe("Lens", func() {
+	var (
+		lensAPI *fakeLens
+		pinning *fakeIPFS
+		servers []*httptest.Server
+		signer  *wallet.KeyManager
+		store   *memory.TweetStore
+		client  *lens.Client
+		logger  *logrus.Logger
+		ctx     context.Context
+		cancel  context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		lensAPI = &fakeLens{}
+		pinning = &fakeIPFS{}
+		lensServer := httptest.NewServer(lensAPI)
+		ipfsServer := httptest.NewServer(pinning)
+		servers = []*httptest.Server{lensServer, ipfsServer}
+
+		database := newTestDatabase(logger)
+
+		var err error
+		store, err = memory.NewTweetStore(logger, database, "bot-1", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		signer, err = wallet.NewKeyManager(lensTestKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		pinner, err := ipfs.NewClient(&ipfs.Config{APIURL: ipfsServer.URL, Token: "pin-token", Logger: logger})
+		Expect(err).NotTo(HaveOccurred())
+
+		client, err = lens.NewClient(&lens.Config{
+			ProfileID: "0x01",
+			APIURL:    lensServer.URL,
+			Signer:    signer,
+			IPFS:      pinner,
+			Recorder:  store,
+			Logger:    logger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		for _, server := range servers {
+			server.Close()
+		}
+	})
+
+	It("pins metadata, signs in with the wallet and broadcasts a post", func() {
+		publication, err := client.Post(ctx, "gm lens")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publication.ContentURI).To(Equal("ipfs://bafytestcid"))
+		Expect(publication.TxHash).To(Equal("0xfeed"))
+
+		By("pinning Lens text-only metadata")
+		Expect(pinning.auth).To(ConsistOf("Bearer pin-token"))
+		var metadata map[string]interface{}
+		Expect(json.Unmarshal([]byte(pinning.added[0]), &metadata)).To(Succeed())
+		Expect(metadata).To(HaveKeyWithValue("$schema", ContainSubstring("text-only")))
+		Expect(metadata["lens"]).To(HaveKeyWithValue("content", "gm lens"))
+
+		By("signing the login challenge as the wallet")
+		signature, err := hexutil.Decode(lensAPI.loginSigned)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signature).To(HaveLen(65))
+		signature[64] -= 27
+		pub, err := crypto.SigToPub(accounts.TextHash([]byte(lensChallengeText)), signature)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crypto.PubkeyToAddress(*pub)).To(Equal(signer.GetAddress()))
+
+		By("creating typed data with the access token and broadcasting its signature")
+		Expect(lensAPI.operations).To(Equal([]string{"challenge", "authenticate", "post", "broadcast"}))
+		Expect(lensAPI.tokens).To(ConsistOf("Bearer token-1"))
+		Expect(lensAPI.requests[0]).To(HaveKeyWithValue("contentURI", "ipfs://bafytestcid"))
+		Expect(lensAPI.broadcasts[0]).To(HaveKeyWithValue("id", "typed-data-1"))
+		Expect(lensAPI.broadcasts[0]["signature"]).To(HaveLen(132))
+
+		By("recording the publication")
+		stored, err := store.ListLensPublications(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored).To(HaveLen(1))
+		Expect(stored[0].Kind).To(Equal(lens.KindPost))
+		Expect(stored[0].ContentURI).To(Equal("ipfs://bafytestcid"))
+		Expect(stored[0].TxHash).To(Equal("0xfeed"))
+	})
+
+	It("comments on a publication reusing the access token", func() {
+		_, err := client.Post(ctx, "first")
+		Expect(err).NotTo(HaveOccurred())
+
+		publication, err := client.Comment(ctx, "0x02-0x03", "reply")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publication.Kind).To(Equal(lens.KindComment))
+
+		Expect(lensAPI.operations).To(Equal([]string{"challenge", "authenticate", "post", "broadcast", "comment", "broadcast"}))
+		Expect(lensAPI.requests[1]).To(HaveKeyWithValue("commentOn", "0x02-0x03"))
+
+		stored, err := store.ListLensPublications(ctx, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored).To(HaveLen(1))
+		Expect(stored[0].CommentOn).To(Equal("0x02-0x03"))
+	})
+
+	It("records publications without pinning or broadcasting them in dry-run mode", func() {
+		client.SetDryRun(store)
+
+		publication, err := client.Comment(ctx, "0x02-0x03", "reply")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publication.TxHash).To(BeEmpty())
+		Expect(pinning.added).To(BeEmpty())
+		Expect(lensAPI.operations).To(BeEmpty())
+
+		recorded, err := store.ListDryRunPosts(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0].Kind).To(Equal(string(dryrun.KindLens)))
+		Expect(recorded[0].Target).To(Equal("0x02-0x03"))
+		Expect(recorded[0].Content).To(Equal("reply"))
+
+		stored, err := store.ListLensPublications(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored).To(BeEmpty())
+	})
+})
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// scriptedModel answers "ok" unless the call is scripted to hang until its
+// context ends or to fail; it records the max tokens of every call
+type scriptedModel struct {
+	mu        sync.Mutex
+	hang      int
+	fail      int
+	maxTokens []int
+}
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, _ []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	m.mu.Lock()
+	m.maxTokens = append(m.maxTokens, opts.MaxTokens)
+	hang := m.hang > 0
+	if hang {
+		m.hang--
+	}
+	fail := !hang && m.fail > 0
+	if fail {
+		m.fail--
+	}
+	m.mu.Unlock()
+
+	switch {
+	case hang:
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case fail:
+		return nil, errors.New("provider unavailable")
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "ok"}}}, nil
+}
+
+func (m *scriptedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *scriptedModel) calls() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int(nil), m.maxTokens...)
+}
+
+var _ = Describe("Guarded LLM", func() {
+	var (
+		logger *logrus.Logger
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("retries a timed out call with fewer max tokens", func() {
+		model := &scriptedModel{hang: 1}
+		guarded := llm.NewGuardedModel(model, llm.GuardConfig{Timeout: 50 * time.Millisecond, Logger: logger})
+
+		text, err := guarded.Call(ctx, "hi", llms.WithMaxTokens(400))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("ok"))
+		Expect(model.calls()).To(Equal([]int{400, 200}))
+	})
+
+	It("gives up after the timeout retries", func() {
+		model := &scriptedModel{hang: 2}
+		guarded := llm.NewGuardedModel(model, llm.GuardConfig{Timeout: 50 * time.Millisecond, Logger: logger})
+
+		_, err := guarded.Call(ctx, "hi", llms.WithMaxTokens(400))
+		Expect(errors.Is(err, llm.ErrTimeout)).To(BeTrue(), "got %v", err)
+		Expect(model.calls()).To(HaveLen(2))
+	})
+
+	It("fails fast while the provider keeps failing", func() {
+		model := &scriptedModel{fail: 2}
+		guarded := llm.NewGuardedModel(model, llm.GuardConfig{
+			BreakerThreshold: 2,
+			BreakerCooldown:  100 * time.Millisecond,
+			Logger:           logger,
+		})
+
+		for i := 0; i < 2; i++ {
+			_, err := guarded.Call(ctx, "hi")
+			Expect(err).To(HaveOccurred())
+		}
+		_, err := guarded.Call(ctx, "hi")
+		Expect(errors.Is(err, llm.ErrCircuitOpen)).To(BeTrue(), "got %v", err)
+		Expect(model.calls()).To(HaveLen(2))
+
+		// After the cooldown a trial call goes through and closes the breaker
+		time.Sleep(150 * time.Millisecond)
+		Expect(guarded.Call(ctx, "hi")).To(Equal("ok"))
+		Expect(guarded.Call(ctx, "hi")).To(Equal("ok"))
+	})
+})
@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/lock"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Conversation locks", func() {
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+		client *twitter.TwitterClient
+		store  *memory.TweetStore
+		locker lock.Locker
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	respond := func() {
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "mine"}).
+			WithLocker(locker)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		var err error
+		client, err = twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+		locker, err = lock.New(database, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("hands a key to one holder at a time", func() {
+		release, ok, err := locker.TryLock(ctx, lock.Key("reply", "1000", "c1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		_, ok, err = locker.TryLock(ctx, lock.Key("reply", "1000", "c1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		other, ok, err := locker.TryLock(ctx, lock.Key("reply", "1000", "c2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		other()
+
+		release()
+		release()
+		again, ok, err := locker.TryLock(ctx, lock.Key("reply", "1000", "c1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		again()
+	})
+
+	It("skips conversations another instance is answering", func() {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "User 7", "user7")).To(Succeed())
+
+		release, ok, err := locker.TryLock(ctx, lock.Key("reply", "1000", tweet.ConversationID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		respond()
+		Expect(server.Posted()).To(BeEmpty())
+
+		release()
+		respond()
+		Expect(server.Posted()).To(HaveLen(1))
+	})
+
+	It("sees replies posted by another instance", func() {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "User 7", "user7")).To(Succeed())
+
+		repliedTo, err := store.RepliedTo(ctx, tweet.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repliedTo).To(BeFalse())
+
+		Expect(store.SaveAgentReply(ctx, tweet.ID, "9999", tweet.ConversationID, "theirs")).To(Succeed())
+		repliedTo, err = store.RepliedTo(ctx, tweet.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repliedTo).To(BeTrue())
+	})
+})
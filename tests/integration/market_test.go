@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/market"
+	"github.com/lisanmuaddib/agent-go/pkg/tools"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	laffyOnBase = "0x1aff000000000000000000000000000000000001"
+	laffyOnBSC  = "0x1aff000000000000000000000000000000000002"
+)
+
+// dexPair is a DexScreener pair as returned by the tokens endpoint
+func dexPair(chain, address, symbol, price string, change, liquidity float64) map[string]any {
+	return map[string]any{
+		"chainId":     chain,
+		"baseToken":   map[string]any{"address": address, "symbol": symbol},
+		"priceUsd":    price,
+		"priceChange": map[string]any{"h24": change},
+		"liquidity":   map[string]any{"usd": liquidity},
+	}
+}
+
+var _ = Describe("Market prices", func() {
+	var (
+		server *httptest.Server
+		client *market.Client
+
+		mu       sync.Mutex
+		requests []string
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests = append(requests, r.URL.EscapedPath())
+			mu.Unlock()
+
+			Expect(r.URL.Path).To(HavePrefix("/tokens/"))
+			Expect(json.NewEncoder(w).Encode(map[string]any{"pairs": []any{
+				// A copycat with the same symbol and more liquidity
+				dexPair("base", "0xc0b1ca7000000000000000000000000000000000", "LAFFY", "9.99", 500, 5_000_000),
+				// The pinned address deployed by someone else on another chain
+				dexPair("solana", laffyOnBase, "LAFFY", "7.77", 0, 1_000_000),
+				dexPair("base", laffyOnBase, "LAFFY", "0.0042", 12.5, 250_000),
+				dexPair("bsc", laffyOnBSC, "LAFFY", "0.0041", 11, 80_000),
+			}})).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+
+		logger := logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		marketConfig := market.NewConfigFrom(config.MarketConfig{
+			CoinGeckoURL:   server.URL,
+			DexScreenerURL: server.URL,
+			Contracts: map[string]map[string]string{
+				"Base": {"laffy": laffyOnBase},
+				"BSC":  {"LAFFY": laffyOnBSC},
+			},
+		}, logger)
+		client = market.NewClient(marketConfig)
+	})
+
+	It("prices a token from the most liquid pair of its pinned contracts", func() {
+		quote, err := client.GetQuote(context.Background(), "$laffy")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quote.Symbol).To(Equal("LAFFY"))
+		Expect(quote.PriceUSD).To(Equal(0.0042))
+		Expect(quote.Change24h).To(Equal(12.5))
+		Expect(quote.Source).To(Equal(market.SourceDexScreener))
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0]).To(SatisfyAny(
+			Equal("/tokens/"+laffyOnBase+","+laffyOnBSC),
+			Equal("/tokens/"+laffyOnBSC+","+laffyOnBase),
+		))
+	})
+
+	It("does not price symbols without a pinned contract from DEX pairs", func() {
+		_, err := client.GetQuote(context.Background(), "CATNIP")
+		Expect(err).To(MatchError(ContainSubstring("no contract pinned for CATNIP")))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("serves the token price tool from the market client", func() {
+		registry, err := tools.NewRegistry(tools.NewTokenPriceTool(client))
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := registry.Invoke(context.Background(), "get_token_price", `{"symbol": "LAFFY"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("LAFFY: $0.0042 (+12.50% 24h)"))
+	})
+})
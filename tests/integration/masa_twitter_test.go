@@ -1,9 +1,15 @@
 package integration
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -63,7 +69,7 @@ var _ = Describe("TwitterScraper", func() {
 	})
 
 	It("should fetch golang tweets", func() {
-		tweets, err := twitterClient.SearchWithOptions(DefaultSearchQuery, masatwitter.SearchOptions{
+		tweets, err := twitterClient.SearchWithOptions(context.Background(), DefaultSearchQuery, masatwitter.SearchOptions{
 			TweetCount: DefaultTweetCount,
 		})
 
@@ -83,3 +89,68 @@ var _ = Describe("TwitterScraper", func() {
 		}).Info("Received tweet data")
 	})
 })
+
+var _ = Describe("Masa search paging", func() {
+	var (
+		server  *httptest.Server
+		queries []string
+		client  *masatwitter.Client
+	)
+
+	BeforeEach(func() {
+		queries = nil
+		maxID := regexp.MustCompile(`max_id:(\d+)`)
+
+		// Serves tweet IDs 1..25, newest first, honouring max_id windows
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request masatwitter.SearchRequest
+			Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			queries = append(queries, request.Query)
+
+			newest := 25
+			if match := maxID.FindStringSubmatch(request.Query); match != nil {
+				newest, _ = strconv.Atoi(match[1])
+			}
+
+			var data []map[string]any
+			for id := newest; id >= 1 && len(data) < request.Count; id-- {
+				data = append(data, map[string]any{"Tweet": map[string]any{"id": fmt.Sprint(id)}})
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]any{"data": data})).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+
+		client = masatwitter.NewClient(&masatwitter.Config{
+			APIEndpoint:    server.URL,
+			RequestTimeout: 5 * time.Second,
+			Logger:         logrus.New(),
+		})
+	})
+
+	It("fetches older pages until MaxTotal is reached", func() {
+		tweets, err := client.SearchWithOptions(context.Background(), "#laffy", masatwitter.SearchOptions{
+			TweetCount: 10,
+			MaxTotal:   22,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tweets).To(HaveLen(22))
+		Expect(tweets[21].ID).To(Equal("4"))
+		Expect(queries).To(Equal([]string{"#laffy", "#laffy max_id:15", "#laffy max_id:5"}))
+	})
+
+	It("stops when the results run out", func() {
+		tweets, err := client.SearchWithOptions(context.Background(), "#laffy", masatwitter.SearchOptions{
+			TweetCount: 10,
+			MaxTotal:   100,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tweets).To(HaveLen(25))
+	})
+
+	It("fetches a single page without MaxTotal", func() {
+		tweets, err := client.SearchWithOptions(context.Background(), "#laffy", masatwitter.SearchOptions{TweetCount: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tweets).To(HaveLen(10))
+		Expect(queries).To(HaveLen(1))
+	})
+})
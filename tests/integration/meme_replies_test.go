@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/imagegen"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// fakeImageGenerator returns a fixed image and remembers the prompts it got
+type fakeImageGenerator struct {
+	prompts []string
+	err     error
+}
+
+func (g *fakeImageGenerator) Generate(ctx context.Context, prompt string) (*imagegen.Image, error) {
+	g.prompts = append(g.prompts, prompt)
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &imagegen.Image{Data: pngHeader, MediaType: "image/png"}, nil
+}
+
+var _ = Describe("Meme replies", func() {
+	var (
+		server    *twittermock.Server
+		store     *memory.TweetStore
+		generator *fakeImageGenerator
+		responder *actions.TweetResponder
+		ctx       context.Context
+		cancel    context.CancelFunc
+	)
+
+	// mention adds a mention of the bot to the API and the store with the
+	// given number of likes
+	mention := func(text string, likes int) twitter.Tweet {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: text, AuthorID: "7"})
+		tweet.PublicMetrics.LikeCount = likes
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		return tweet
+	}
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		server.AddUser(twitter.User{ID: "7", Name: "Fan", Username: "fan"})
+		client, err := twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		generator = &fakeImageGenerator{}
+		responder = actions.NewTweetResponder(store, client, logger, cannedReply{text: "bow before me, peasant"}).
+			WithMemes(generator, actions.MemeOptions{Probability: 1, MinEngagement: 10})
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	reply := func() {
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+	}
+
+	It("attaches a generated meme to replies to high-engagement tweets", func() {
+		mention("@mockbot your ears are folded", 25)
+
+		reply()
+
+		Expect(generator.prompts).To(HaveLen(1))
+		Expect(generator.prompts[0]).To(ContainSubstring("your ears are folded"))
+		Expect(generator.prompts[0]).To(ContainSubstring("bow before me, peasant"))
+
+		uploads := server.Uploads()
+		Expect(uploads).To(HaveLen(1))
+		Expect(uploads[0].MediaType).To(Equal("image/png"))
+		Expect(uploads[0].Category).To(Equal("tweet_image"))
+
+		posted := server.Posted()
+		Expect(posted).To(HaveLen(1))
+		Expect(posted[0].Text).To(Equal("bow before me, peasant"))
+		Expect(posted[0].Attachments.MediaKeys).To(ConsistOf(uploads[0].MediaKey))
+	})
+
+	It("replies without a meme below the engagement threshold", func() {
+		mention("@mockbot hi", 2)
+
+		reply()
+
+		Expect(generator.prompts).To(BeEmpty())
+		Expect(server.Uploads()).To(BeEmpty())
+		Expect(server.Posted()).To(HaveLen(1))
+		Expect(server.Posted()[0].Attachments.MediaKeys).To(BeEmpty())
+	})
+
+	It("still replies when the image cannot be generated", func() {
+		generator.err = errors.New("content policy violation")
+		mention("@mockbot draw yourself", 50)
+
+		reply()
+
+		Expect(server.Uploads()).To(BeEmpty())
+		Expect(server.Posted()).To(HaveLen(1))
+		Expect(server.Posted()[0].Attachments.MediaKeys).To(BeEmpty())
+	})
+})
@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/llm"
+	"github.com/lisanmuaddib/agent-go/pkg/llm/openai"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// usageModel answers every call with fixed token counts
+type usageModel struct{}
+
+func (usageModel) GenerateContent(_ context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		Content:        "ok",
+		GenerationInfo: map[string]any{"PromptTokens": 1000, "CompletionTokens": 200},
+	}}}, nil
+}
+
+func (m usageModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+var _ = Describe("Model routing", func() {
+	policy := llm.RoutingPolicy{
+		CheapModel:       "gpt-4o-mini",
+		StrongModel:      "gpt-4o",
+		MaxCheapThread:   2,
+		StrongCategories: []string{"roast"},
+		StrongFollowers:  10000,
+	}
+
+	var (
+		logger *logrus.Logger
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	It("sends short replies to the cheap model and the rest to the strong model", func() {
+		base := &modelRecorder{}
+		router := llm.NewRouter(base, policy, logger)
+
+		simple := llm.Route{Category: "mention", ThreadLength: 1, AuthorFollowers: 50}
+		long := simple
+		long.ThreadLength = 3
+		famous := simple
+		famous.AuthorFollowers = 20000
+		roast := simple
+		roast.Category = "roast"
+
+		for _, route := range []llm.Route{simple, long, famous, roast} {
+			_, err := router.Call(llm.WithRoute(ctx, route), "gm")
+			Expect(err).NotTo(HaveOccurred())
+		}
+		// Judgments and other calls without a route
+		_, err := router.Call(ctx, "judge")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(base.calls()).To(Equal([]string{"gpt-4o-mini", "gpt-4o", "gpt-4o", "gpt-4o", "gpt-4o"}))
+	})
+
+	It("lets a fine-tuned model take precedence and fall back to the routed model", func() {
+		const fineTune = "ft:gpt-4o-mini:acme::abc123"
+		base := &modelRecorder{broken: map[string]bool{fineTune: true}}
+		model := openai.NewFineTunedModel(llm.NewRouter(base, policy, logger), fineTune, true, time.Minute, logger)
+
+		_, err := model.Call(llm.WithRoute(ctx, llm.Route{ThreadLength: 1}), "gm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(base.calls()).To(Equal([]string{fineTune, "gpt-4o-mini"}))
+	})
+
+	It("accounts calls, tokens and cost per model", func() {
+		tracker := llm.NewUsageTracker(usageModel{}, "gpt-4o", map[string]llm.ModelPrice{
+			"gpt-4o-mini": {Prompt: 0.15, Completion: 0.6},
+		})
+
+		_, err := tracker.Call(ctx, "gm", llms.WithModel("gpt-4o-mini"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tracker.Call(ctx, "gm", llms.WithModel("gpt-4o-mini"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tracker.Call(ctx, "gm")
+		Expect(err).NotTo(HaveOccurred())
+
+		usage := tracker.Usage()
+		Expect(usage).To(HaveLen(2))
+		Expect(usage[0]).To(Equal(llm.ModelUsage{Model: "gpt-4o", Calls: 1, PromptTokens: 1000, CompletionTokens: 200}))
+		Expect(usage[1].Model).To(Equal("gpt-4o-mini"))
+		Expect(usage[1].Calls).To(Equal(int64(2)))
+		Expect(usage[1].PromptTokens).To(Equal(int64(2000)))
+		Expect(usage[1].CostUSD).To(BeNumerically("~", 0.00054, 1e-9))
+	})
+})
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/masa/masatwitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tweet model", func() {
+	It("converts v2 API tweets with their author", func() {
+		var tweet twitter.Tweet
+		Expect(json.Unmarshal([]byte(`{
+			"id": "200",
+			"text": "@catlord $LAFFY to the moon #cats",
+			"author_id": "7",
+			"conversation_id": "100",
+			"created_at": "2024-05-01T12:00:00Z",
+			"referenced_tweets": [{"type": "replied_to", "id": "150"}],
+			"entities": {
+				"hashtags": [{"start": 28, "end": 33, "tag": "cats"}],
+				"cashtags": [{"start": 9, "end": 15, "tag": "LAFFY"}],
+				"mentions": [{"start": 0, "end": 8, "username": "catlord", "id": "42"}]
+			},
+			"public_metrics": {"like_count": 3, "retweet_count": 1, "reply_count": 2, "quote_count": 0}
+		}`), &tweet)).To(Succeed())
+		author := &twitter.User{ID: "7", Username: "fan", Name: "Fan", Verified: true}
+
+		converted := model.FromTwitter(tweet, author)
+		Expect(converted.Source).To(Equal(model.SourceTwitter))
+		Expect(converted.Author.Username).To(Equal("fan"))
+		Expect(converted.InReplyToID).To(Equal("150"))
+		Expect(converted.IsReply()).To(BeTrue())
+		Expect(converted.Hashtags).To(Equal([]string{"cats"}))
+		Expect(converted.Cashtags).To(Equal([]string{"LAFFY"}))
+		Expect(converted.Mentions).To(Equal([]string{"catlord"}))
+		Expect(converted.CreatedAt).To(Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+		Expect(converted.Metrics.Engagement()).To(Equal(7))
+	})
+
+	It("converts Masa tweets into the stored shape", func() {
+		converted := model.FromMasa(masatwitter.Tweet{
+			ID:                "300",
+			Text:              "buying more $LAFFY.",
+			ConversationID:    "250",
+			InReplyToStatusID: "250",
+			UserID:            "9",
+			Username:          "degen",
+			Name:              "Degen",
+			Likes:             5,
+			Views:             80,
+			Timestamp:         1714564800,
+		})
+		Expect(converted.Source).To(Equal(model.SourceMasa))
+		Expect(converted.Cashtags).To(Equal([]string{"LAFFY"}))
+		Expect(converted.CreatedAt).To(Equal(time.Unix(1714564800, 0).UTC()))
+
+		meta := memory.NewTweetWithMeta(converted, memory.CategoryMention)
+		Expect(meta.AuthorUsername).To(Equal("degen"))
+		Expect(meta.Tweet.AuthorID).To(Equal("9"))
+		Expect(meta.Tweet.CreatedAt).To(Equal("2024-05-01T12:00:00Z"))
+		Expect(meta.Tweet.ReferencedTweets).To(Equal([]twitter.ReferencedTweet{{Type: "replied_to", ID: "250"}}))
+		Expect(meta.Tweet.PublicMetrics.LikeCount).To(Equal(5))
+	})
+})
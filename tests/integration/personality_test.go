@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/lisanmuaddib/agent-go/internal/personality/traits"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Personality", func() {
+	It("swaps prompt sections per interaction category", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "doglord.yaml")
+		Expect(os.WriteFile(path, []byte(`
+Personality: A loyal dog lord
+Interaction Style: Friendly and eager
+categories:
+  roast:
+    Interaction Style: Merciless, but never crude
+    Judging: Rate every bio out of ten
+  quote:
+    Interaction Style: ""
+`), 0o644)).To(Succeed())
+
+		persona, err := traits.LoadPersona(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(persona.Sections).To(Equal(map[string]string{
+			"Personality":       "A loyal dog lord",
+			"Interaction Style": "Friendly and eager",
+		}))
+
+		Expect(persona.For("mention")).To(Equal(persona.Sections))
+		Expect(persona.For("roast")).To(Equal(map[string]string{
+			"Personality":       "A loyal dog lord",
+			"Interaction Style": "Merciless, but never crude",
+			"Judging":           "Rate every bio out of ten",
+		}))
+		Expect(persona.For("quote")).To(Equal(map[string]string{
+			"Personality": "A loyal dog lord",
+		}))
+		Expect(persona.Sections).To(HaveKeyWithValue("Interaction Style", "Friendly and eager"))
+
+		sections, err := traits.LoadPromptSections(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sections).To(Equal(persona.Sections))
+	})
+})
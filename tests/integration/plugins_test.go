@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/plugins"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// commentaryAction is an out-of-tree action as a plugin would provide it
+type commentaryAction struct {
+	name   string
+	league string
+}
+
+func (a *commentaryAction) Name() string                      { return a.name }
+func (a *commentaryAction) Execute(ctx context.Context) error { return nil }
+func (a *commentaryAction) Stop()                             {}
+
+func init() {
+	plugins.Register("test-sports", func(host plugins.Host) ([]actions.Action, error) {
+		return []actions.Action{&commentaryAction{
+			name:   "sports_commentary",
+			league: host.Settings["league"],
+		}}, nil
+	})
+	plugins.Register("test-broken", func(plugins.Host) ([]actions.Action, error) {
+		return nil, errors.New("missing api key")
+	})
+}
+
+var _ = Describe("Plugins", func() {
+	var host plugins.Host
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		host = plugins.Host{Account: "cat", Logger: logger}
+	})
+
+	It("builds the actions of registered plugins with their settings", func() {
+		Expect(plugins.Registered()).To(ContainElements("test-broken", "test-sports"))
+
+		loaded, err := plugins.Load([]plugins.Spec{{
+			Name:     "test-sports",
+			Settings: map[string]string{"league": "nba"},
+		}}, host)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(HaveLen(1))
+		Expect(loaded[0].Name()).To(Equal("sports_commentary"))
+		Expect(loaded[0].(*commentaryAction).league).To(Equal("nba"))
+	})
+
+	It("fails on unknown plugins, unreadable plugin files and factory errors", func() {
+		_, err := plugins.Load([]plugins.Spec{{Name: "weather"}}, host)
+		Expect(err).To(MatchError(ContainSubstring("unknown plugin weather")))
+
+		_, err = plugins.Load([]plugins.Spec{{Name: "sports", Path: "/nonexistent/sports.so"}}, host)
+		Expect(err).To(MatchError(ContainSubstring("failed to open plugin sports")))
+
+		_, err = plugins.Load([]plugins.Spec{{Name: "test-broken"}}, host)
+		Expect(err).To(MatchError("plugin test-broken: missing api key"))
+	})
+
+	It("panics when a plugin name is registered twice", func() {
+		Expect(func() {
+			plugins.Register("test-sports", func(plugins.Host) ([]actions.Action, error) { return nil, nil })
+		}).To(Panic())
+	})
+})
@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/llm/openai"
+	"github.com/lisanmuaddib/agent-go/pkg/preflight"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Preflight", func() {
+	check := func(name string, optional bool, run func(ctx context.Context) (string, error)) preflight.Check {
+		return preflight.Check{Name: name, Optional: optional, Run: run}
+	}
+
+	It("reports every check in order and fails only on required failures", func() {
+		report := preflight.Run(context.Background(), []preflight.Check{
+			check("database", false, func(ctx context.Context) (string, error) { return "postgres", nil }),
+			check("wallet:base", true, func(ctx context.Context) (string, error) { return "", errors.New("connection refused") }),
+			check("wallet", false, func(ctx context.Context) (string, error) { return "", preflight.Skip("no RPC URL") }),
+		}, preflight.Options{})
+
+		Expect(report.Results).To(HaveLen(3))
+		Expect(report.Results[0].Status).To(Equal(preflight.StatusOK))
+		Expect(report.Results[0].Detail).To(Equal("postgres"))
+		Expect(report.Results[1].Status).To(Equal(preflight.StatusWarning))
+		Expect(report.Results[2].Status).To(Equal(preflight.StatusSkipped))
+		Expect(report.OK()).To(BeTrue())
+		Expect(report.Err()).NotTo(HaveOccurred())
+
+		var out bytes.Buffer
+		Expect(report.Print(&out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("wallet:base"))
+		Expect(out.String()).To(ContainSubstring("connection refused"))
+	})
+
+	It("turns timeouts and panics into failures without blocking the others", func() {
+		report := preflight.Run(context.Background(), []preflight.Check{
+			check("twitter:main", false, func(ctx context.Context) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}),
+			check("openai", false, func(ctx context.Context) (string, error) { panic("boom") }),
+			check("config", false, func(ctx context.Context) (string, error) { return "1 account(s)", nil }),
+		}, preflight.Options{Timeout: 50 * time.Millisecond})
+
+		Expect(report.OK()).To(BeFalse())
+		Expect(report.Names(preflight.StatusFailed)).To(Equal([]string{"openai", "twitter:main"}))
+		Expect(report.Results[0].Error).To(ContainSubstring("timed out"))
+		Expect(report.Results[1].Error).To(ContainSubstring("panicked"))
+		Expect(report.Results[2].Status).To(Equal(preflight.StatusOK))
+		Expect(report.Err()).To(MatchError(ContainSubstring("twitter:main")))
+	})
+
+	It("checks the OpenAI key against the configured base URL", func() {
+		var authorization []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = append(authorization, r.Header.Get("Authorization"))
+			switch r.URL.Path {
+			case "/v1/models/gpt-4":
+				w.WriteHeader(http.StatusOK)
+			case "/v1/models/gpt-5":
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		settings := config.Default().OpenAI
+		settings.APIKey = "sk-test"
+		settings.BaseURL = server.URL + "/v1/"
+		openaiConfig, err := openai.NewOpenAIConfigFrom(settings, logrus.New())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(openai.CheckKey(context.Background(), openaiConfig)).To(Succeed())
+		Expect(authorization).To(Equal([]string{"Bearer sk-test"}))
+
+		openaiConfig.Model = "gpt-5"
+		Expect(openai.CheckKey(context.Background(), openaiConfig)).To(MatchError(ContainSubstring(`model "gpt-5" is not available`)))
+		openaiConfig.BaseURL = server.URL + "/rejected"
+		Expect(openai.CheckKey(context.Background(), openaiConfig)).To(MatchError(ContainSubstring("API key was rejected")))
+	})
+})
@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("API quota", func() {
+	const (
+		meEndpoint       = "GET /2/users/me"
+		usernameEndpoint = "GET /2/users/by/username/:username"
+	)
+
+	var (
+		logger   *logrus.Logger
+		server   *twittermock.Server
+		client   *twitter.TwitterClient
+		store    *memory.TweetStore
+		recorder *actions.QuotaRecorder
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		server.AddUser(twitter.User{ID: "7", Name: "Fan", Username: "fan"})
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		var err error
+		client, err = twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder = actions.NewQuotaRecorder(client, store, logger, actions.QuotaRecorderOptions{Interval: time.Hour})
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	// get asks the admin API's quota dashboard for the path
+	get := func(path string) *httptest.ResponseRecorder {
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleQuota(
+			map[string]admin.QuotaReporter{"catlord": client},
+			map[string]admin.QuotaHistory{"catlord": store},
+		)
+		response := httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+		return response
+	}
+
+	It("follows the remaining requests of each endpoint from successful responses", func() {
+		for i := 0; i < 3; i++ {
+			_, err := client.CheckCredentials(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err := client.GetUserByUsername(ctx, "fan")
+		Expect(err).NotTo(HaveOccurred())
+
+		quotas := client.Quota()
+		Expect(quotas).To(HaveLen(2))
+		Expect(quotas[0].Endpoint).To(Equal(usernameEndpoint))
+		Expect(quotas[1].Endpoint).To(Equal(meEndpoint))
+		Expect(quotas[1].Limit).To(Equal(180))
+		Expect(quotas[1].Remaining).To(Equal(177))
+		Expect(quotas[1].Used).To(Equal(3))
+		Expect(quotas[1].Requests).To(Equal(3))
+		Expect(quotas[1].Reset).To(BeTemporally(">", time.Now()))
+
+		response := get("/twitter/quota")
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body struct {
+			Endpoints []struct {
+				Endpoint    string  `json:"endpoint"`
+				Remaining   int     `json:"remaining"`
+				UsedPercent float64 `json:"used_percent"`
+			} `json:"endpoints"`
+		}
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Endpoints).To(HaveLen(2))
+		// Closest to its limit first
+		Expect(body.Endpoints[0].Endpoint).To(Equal(meEndpoint))
+		Expect(body.Endpoints[0].Remaining).To(Equal(177))
+		Expect(body.Endpoints[0].UsedPercent).To(BeNumerically("~", 100.0*3/180, 0.001))
+		Expect(body.Endpoints[1].Endpoint).To(Equal(usernameEndpoint))
+	})
+
+	It("persists each window's consumption and keeps ended windows apart", func() {
+		_, err := client.CheckCredentials(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetUserByUsername(ctx, "fan")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Record(ctx)).To(Succeed())
+
+		server.ExpireQuotaWindows()
+		for i := 0; i < 2; i++ {
+			_, err = client.CheckCredentials(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(recorder.Record(ctx)).To(Succeed())
+
+		windows, err := store.QuotaWindows(ctx, meEndpoint, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(windows).To(HaveLen(2))
+		// Newest first
+		Expect(windows[0].WindowReset).To(BeTemporally(">", windows[1].WindowReset))
+		Expect(windows[0].Requests).To(Equal(2))
+		Expect(windows[0].Remaining).To(Equal(178))
+		Expect(windows[1].Requests).To(Equal(1))
+		Expect(windows[1].Remaining).To(Equal(179))
+
+		all, err := store.QuotaWindows(ctx, "", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(HaveLen(3))
+
+		response := get("/twitter/quota/history?endpoint=GET%20/2/users/me&since=1h")
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body struct {
+			Windows []memory.QuotaWindow `json:"windows"`
+		}
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Windows).To(HaveLen(2))
+		Expect(body.Windows[0].Endpoint).To(Equal(meEndpoint))
+
+		Expect(get("/twitter/quota/history?since=yesterday").Code).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -2,12 +2,10 @@ package integration
 
 import (
 	"context"
-	"encoding/json"
-	"os"
-	"path/filepath"
 	"time"
 
-	"github.com/lisanmuaddib/agent-go/pkg/db"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
 	"github.com/lisanmuaddib/agent-go/pkg/memory"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -16,7 +14,7 @@ import (
 )
 
 const (
-	testUserID = "1848122847585017856" // Your actual user ID from tweets.json
+	testUserID = "1848122847585017856"
 )
 
 // MockTwitterClient implements just the GetAuthenticatedUserID method
@@ -26,113 +24,173 @@ func (m *MockTwitterClient) GetAuthenticatedUserID(ctx context.Context) (string,
 	return testUserID, nil
 }
 
+// threadIDs returns the conversation IDs of the recalled threads in order
+func threadIDs(threads []memory.ConversationThread) []string {
+	ids := make([]string, len(threads))
+	for i, thread := range threads {
+		ids[i] = thread.ConversationID
+	}
+	return ids
+}
+
 var _ = Describe("RecallTweetsNeedingReply", func() {
 	var (
 		store  *memory.TweetStore
-		logger *logrus.Logger
+		client *MockTwitterClient
 		ctx    context.Context
 		cancel context.CancelFunc
 		testDB *gorm.DB
+		base   time.Time
 	)
 
+	// save stores a tweet by author in a conversation, optionally replying to parentID
+	save := func(id, conversationID, authorID string, category memory.TweetCategory, parentID string) {
+		tweet := twitter.Tweet{ID: id, Text: "tweet " + id, ConversationID: conversationID, AuthorID: authorID}
+		if parentID != "" {
+			tweet.ReferencedTweets = []twitter.ReferencedTweet{{Type: "replied_to", ID: parentID}}
+		}
+		Expect(store.SaveTweet(ctx, tweet, category, "User "+authorID, "user"+authorID)).To(Succeed())
+	}
+
+	// at sets a tweet's creation time relative to the start of the test
+	at := func(id string, offset time.Duration) {
+		Expect(testDB.Exec("UPDATE tweets SET created_at = ? WHERE id = ?", base.Add(offset), id).Error).To(Succeed())
+	}
+
 	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		testDB = newTestDatabase(logger)
+
 		var err error
-		// Setup logger
-		logger = logrus.New()
-		logger.SetLevel(logrus.DebugLevel)
+		store, err = memory.NewTweetStore(logger, testDB, testUserID, config.Default())
+		Expect(err).NotTo(HaveOccurred(), "Failed to initialize tweet store")
+
+		client = &MockTwitterClient{}
+		base = time.Now().Add(-time.Hour)
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
 
-		// Change to project root directory for correct file access
-		err = os.Chdir("../..")
-		Expect(err).NotTo(HaveOccurred(), "Failed to change to project root directory")
+	AfterEach(func() {
+		cancel()
+	})
 
-		// Setup test database
-		testDB, err = db.SetupDatabase(logger)
-		Expect(err).NotTo(HaveOccurred(), "Failed to setup database")
+	It("recalls unanswered mentions with their conversation", func() {
+		save("m1", "c1", "7", memory.CategoryMention, "")
+		save("x1", "c1", "8", memory.CategoryConversation, "")
+		at("m1", 0)
+		at("x1", time.Minute)
 
-		// Initialize tweet store with database
-		store, err = memory.NewTweetStore(logger, testDB)
-		Expect(err).NotTo(HaveOccurred(), "Failed to initialize tweet store")
+		threads, err := store.RecallTweetsNeedingReply(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threads).To(HaveLen(1))
+		Expect(threads[0].ConversationID).To(Equal("c1"))
+		Expect(threads[0].Tweets).To(HaveLen(2))
+		Expect(threads[0].NeedingReply).To(HaveLen(2))
+
+		latest, ok := threads[0].Latest()
+		Expect(ok).To(BeTrue())
+		Expect(latest.TweetID).To(Equal("x1"))
+	})
 
-		// Load test data from JSON file
-		tweetsFile := "data/tweets/tweets.json"
-		_, err = os.Stat(tweetsFile)
-		Expect(err).NotTo(HaveOccurred(), "tweets.json should exist")
+	It("leaves out answered tweets and the bot's own tweets", func() {
+		save("m1", "c1", "7", memory.CategoryMention, "")
+		Expect(store.SaveAgentReply(ctx, "m1", "r1", "c1", "gm")).To(Succeed())
+		save("own", "c2", testUserID, memory.CategoryMention, "")
 
-		// Get and log absolute path for verification
-		absPath, err := filepath.Abs(tweetsFile)
+		threads, err := store.RecallTweetsNeedingReply(ctx, client)
 		Expect(err).NotTo(HaveOccurred())
-		logger.WithField("tweets_file", absPath).Info("Loading tweets from")
+		Expect(threads).To(BeEmpty())
+	})
 
-		// Read and parse test data
-		data, err := os.ReadFile(tweetsFile)
-		Expect(err).NotTo(HaveOccurred(), "Failed to read tweets.json")
+	It("recalls new activity in conversations the bot takes part in", func() {
+		save("m1", "c1", "7", memory.CategoryMention, "")
+		at("m1", 0)
+		Expect(store.SaveAgentReply(ctx, "m1", "r1", "c1", "gm")).To(Succeed())
+		at("r1", time.Minute)
+		save("u2", "c1", "7", memory.CategoryReply, "r1")
+		at("u2", 2*time.Minute)
 
-		var tweets []memory.StoredTweet
-		err = json.Unmarshal(data, &tweets)
-		Expect(err).NotTo(HaveOccurred(), "Failed to parse tweets.json")
+		threads, err := store.RecallTweetsNeedingReply(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c1"}))
+		latest, _ := threads[0].Latest()
+		Expect(latest.TweetID).To(Equal("u2"))
 
-		// Store test tweets
-		for _, tweet := range tweets {
-			err := store.SaveTweet(tweet.Tweet, tweet.Category, tweet.AuthorName, tweet.AuthorUsername)
-			Expect(err).NotTo(HaveOccurred(), "Failed to save test tweet")
-		}
+		By("skipping participation")
+		threads, err = store.RecallTweetsNeedingReplyWithOptions(ctx, client, memory.RecallOptions{SkipParticipating: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threads).To(BeEmpty())
+	})
 
-		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	It("filters by category", func() {
+		save("m1", "c1", "7", memory.CategoryMention, "")
+		save("x2", "c2", "8", memory.CategoryConversation, "")
+
+		threads, err := store.RecallTweetsNeedingReplyWithOptions(ctx, client, memory.RecallOptions{
+			Categories:        []memory.TweetCategory{memory.CategoryMention},
+			SkipParticipating: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c1"}))
 	})
 
-	AfterEach(func() {
-		cancel()
-		if testDB != nil {
-			sqlDB, err := testDB.DB()
-			Expect(err).NotTo(HaveOccurred())
-			err = sqlDB.Close()
-			Expect(err).NotTo(HaveOccurred())
-		}
+	It("filters by age and limits the result oldest first", func() {
+		save("old", "c0", "7", memory.CategoryMention, "")
+		save("m1", "c1", "7", memory.CategoryMention, "")
+		save("m2", "c2", "7", memory.CategoryMention, "")
+		save("m3", "c3", "7", memory.CategoryMention, "")
+		at("old", -48*time.Hour)
+		at("m1", 0)
+		at("m2", time.Minute)
+		at("m3", 2*time.Minute)
+
+		threads, err := store.RecallTweetsNeedingReplyWithOptions(ctx, client, memory.RecallOptions{MaxAge: 24 * time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c1", "c2", "c3"}))
+
+		threads, err = store.RecallTweetsNeedingReplyWithOptions(ctx, client, memory.RecallOptions{Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c0", "c1"}))
 	})
 
-	Context("when checking stored tweets for needed replies", func() {
-		It("should identify tweets needing replies", func() {
-			client := &MockTwitterClient{}
-			tweets, err := store.RecallTweetsNeedingReply(ctx, client)
-			Expect(err).NotTo(HaveOccurred())
-
-			// Log the results
-			logger.WithFields(logrus.Fields{
-				"tweets_found": len(tweets),
-				"tweets":       tweets,
-			}).Info("Found tweets needing reply")
-
-			// Verify the results
-			for _, tweet := range tweets {
-				By("checking tweet properties")
-				Expect(tweet.ConversationID).NotTo(BeEmpty())
-				Expect(tweet.TweetID).NotTo(BeEmpty())
-
-				By("verifying conversation participation")
-				Expect(tweet.IsParticipating).To(BeTrue(),
-					"Expected to be participating in conversation %s",
-					tweet.ConversationID)
-
-				By("checking unread replies")
-				Expect(tweet.UnreadReplies).To(BeNumerically(">", 0),
-					"Expected unread replies in conversation %s",
-					tweet.ConversationID)
-
-				// Get the actual tweet content
-				storedTweet, err := store.GetTweet(tweet.TweetID)
-				Expect(err).NotTo(HaveOccurred())
-
-				logger.WithFields(logrus.Fields{
-					"tweet_id":         tweet.TweetID,
-					"conversation_id":  tweet.ConversationID,
-					"unread_replies":   tweet.UnreadReplies,
-					"last_reply_id":    tweet.LastReplyID,
-					"last_reply_time":  tweet.LastReplyTime,
-					"is_participating": tweet.IsParticipating,
-					"text":             storedTweet.Text,
-					"author_id":        storedTweet.AuthorID,
-				}).Info("Tweet details")
-			}
-		})
+	It("leaves out blocked authors, muted conversations and spam", func() {
+		save("m1", "c1", "7", memory.CategoryMention, "")
+		save("m2", "c2", "8", memory.CategoryMention, "")
+		save("m3", "c3", "9", memory.CategoryMention, "")
+		save("m4", "c4", "10", memory.CategoryMention, "")
+
+		Expect(store.BlockUser(ctx, "7", "user7", "abusive")).To(Succeed())
+		Expect(store.MuteConversation(ctx, "c2", "flame war")).To(Succeed())
+		store.SetSpamThreshold(0.5)
+		Expect(store.SetSpamScore(ctx, "m3", 0.9)).To(Succeed())
+
+		threads, err := store.RecallTweetsNeedingReply(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c4"}))
+	})
+
+	It("orders threads by their priority score", func() {
+		save("m1", "c1", "7", memory.CategoryMention, "")
+		save("m2", "c2", "8", memory.CategoryMention, "")
+		at("m1", 0)
+		at("m2", 50*time.Minute)
+		Expect(testDB.Exec("UPDATE tweets SET author_verified = ? WHERE id = ?", true, "m1").Error).To(Succeed())
+
+		threads, err := store.RecallTweetsNeedingReply(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c1", "c2"}))
+
+		store.SetReplyPriority(memory.ReplyPriority{Weights: memory.PriorityWeights{Recency: 1}})
+		threads, err = store.RecallTweetsNeedingReply(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c2", "c1"}))
+		Expect(threads[0].Priority).To(BeNumerically(">", threads[1].Priority))
+
+		store.SetReplyPriority(memory.ReplyPriority{Weights: memory.PriorityWeights{Recency: 1, Followers: 3}})
+		threads, err = store.RecallTweetsNeedingReply(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threadIDs(threads)).To(Equal([]string{"c1", "c2"}))
 	})
 })
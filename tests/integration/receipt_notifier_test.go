@@ -0,0 +1,130 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Receipt notifier", func() {
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+		store  *memory.TweetStore
+		bus    *events.Bus
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		bus = events.NewBus(logger)
+		DeferCleanup(bus.Close)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		notifier := actions.NewReceiptNotifier(bus, client, store, logger)
+		go func() { _ = notifier.Execute(ctx) }()
+	})
+
+	// sentTip stores a tip command and its sent tip
+	sentTip := func(txHash string) *memory.Tip {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot tip @alice 25 LAFFY", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Treasurer", "treasurer")).To(Succeed())
+		tip := &memory.Tip{
+			TweetID:           tweet.ID,
+			ConversationID:    tweet.ConversationID,
+			SenderID:          "7",
+			RecipientID:       "42",
+			RecipientUsername: "alice",
+			Network:           "BASE",
+			Symbol:            "LAFFY",
+			Amount:            "25000000000000000000",
+			AmountUnits:       25,
+			Status:            memory.TipSent,
+			TxHash:            txHash,
+		}
+		created, err := store.CreateTip(ctx, tip)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeTrue())
+		return tip
+	}
+
+	// confirm publishes the receipt of a transaction as the wallet does
+	confirm := func(sentHash, minedHash string, success bool) {
+		bus.Emit(events.WalletTransferCompleted, "", map[string]interface{}{
+			"network":       "BASE",
+			"hash":          minedHash,
+			"sent_hash":     sentHash,
+			"success":       success,
+			"block_number":  "123456",
+			"gas_used":      uint64(51234),
+			"confirmations": uint64(1),
+			"explorer_url":  "https://basescan.org/tx/" + minedHash,
+		})
+	}
+
+	It("replies once in the tip's conversation with the explorer link and gas used", func() {
+		tip := sentTip("0xsent")
+
+		// Published again until the subscription is in place; the tip is
+		// followed up only once
+		Eventually(func() []twitter.Tweet {
+			confirm("0xsent", "0xmined", true)
+			return server.Posted()
+		}).Should(HaveLen(1))
+		Consistently(func() []twitter.Tweet {
+			confirm("0xsent", "0xmined", true)
+			return server.Posted()
+		}, 300*time.Millisecond).Should(HaveLen(1))
+
+		reply := server.Posted()[0]
+		Expect(reply.Text).To(ContainSubstring("25 LAFFY reached @alice in block 123456"))
+		Expect(reply.Text).To(ContainSubstring("51234 gas used"))
+		Expect(reply.Text).To(ContainSubstring("https://basescan.org/tx/0xmined"))
+		Expect(reply.ConversationID).To(Equal(tip.ConversationID))
+
+		saved, err := store.GetTip(ctx, tip.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved.Status).To(Equal(memory.TipConfirmed))
+	})
+
+	It("reports reverted tips and ignores transactions sent for nothing", func() {
+		tip := sentTip("0xreverted")
+
+		Eventually(func() []twitter.Tweet {
+			confirm("0xunrelated", "0xunrelated", true)
+			confirm("0xreverted", "0xreverted", false)
+			return server.Posted()
+		}).Should(HaveLen(1))
+		Expect(server.Posted()[0].Text).To(ContainSubstring("reverted on chain"))
+
+		saved, err := store.GetTip(ctx, tip.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved.Status).To(Equal(memory.TipFailed))
+		Expect(saved.Reason).To(Equal("transaction reverted"))
+	})
+})
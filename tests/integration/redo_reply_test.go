@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Redoing a reply", func() {
+	var (
+		logger    *logrus.Logger
+		server    *twittermock.Server
+		client    *twitter.TwitterClient
+		store     *memory.TweetStore
+		generator *recordingReply
+		responder *actions.TweetResponder
+		ctx       context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		var err error
+		client, err = twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		generator = &recordingReply{}
+		responder = actions.NewTweetResponder(store, client, logger, generator).
+			WithExamples(actions.NewReplyExamples(store, nil, logger, 3))
+	})
+
+	It("deletes the reply, posts a guided one and relinks the tweet", func() {
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		Expect(server.Posted()).To(HaveLen(1))
+		first := server.Posted()[0].ID
+
+		posted, err := responder.Redo(ctx, first, "mention the morning coffee")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(server.Deleted()).To(ConsistOf(first))
+		Expect(server.Posted()).To(HaveLen(2))
+		Expect(generator.configs).To(HaveLen(2))
+		Expect(generator.configs[1].Instructions).To(Equal("mention the morning coffee"))
+
+		answered, err := store.RepliedTweet(ctx, posted.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(answered.TweetID).To(Equal(mention.ID))
+		_, err = store.RepliedTweet(ctx, first)
+		Expect(err).To(MatchError(memory.ErrReplyNotFound))
+		Expect(store.HasTweet(ctx, first)).To(BeFalse())
+
+		negative, err := actions.NewReplyExamples(store, nil, logger, 3).Avoid(ctx, "gm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(negative).To(ConsistOf("as you were, peasant"))
+	})
+
+	It("records the deletion instead of deleting the live reply in dry-run mode", func() {
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		first := server.Posted()[0].ID
+
+		client.SetDryRun(store)
+		_, err := responder.Redo(ctx, first, "shorter")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Deleted()).To(BeEmpty())
+		Expect(server.Posted()).To(HaveLen(1))
+
+		recorded, err := store.ListDryRunPosts(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorded).To(HaveLen(2))
+		Expect(recorded[1].Kind).To(Equal(string(dryrun.KindDelete)))
+		Expect(recorded[1].Target).To(Equal(first))
+		Expect(recorded[0].Kind).To(Equal(string(dryrun.KindReply)))
+	})
+
+	It("answers unknown replies with 404 through the admin API", func() {
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleReplies(map[string]admin.ReplyRedoer{"catlord": responder})
+
+		response := httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/replies/424242/redo",
+			strings.NewReader(`{"guidance": "shorter"}`)))
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+		Expect(generator.configs).To(BeEmpty())
+	})
+})
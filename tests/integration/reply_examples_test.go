@@ -0,0 +1,195 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// keywordEmbedder embeds a text as which of its keywords it contains
+type keywordEmbedder struct {
+	keywords []string
+}
+
+func (e keywordEmbedder) CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = make([]float32, len(e.keywords))
+		for j, keyword := range e.keywords {
+			if strings.Contains(strings.ToLower(text), keyword) {
+				embeddings[i][j] = 1
+			}
+		}
+	}
+	return embeddings, nil
+}
+
+var _ = Describe("Reply examples", func() {
+	var (
+		logger   *logrus.Logger
+		examples *actions.ReplyExamples
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		database := newTestDatabase(logger)
+
+		store, err := memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+		embedder := keywordEmbedder{keywords: []string{"gm", "price", "dog"}}
+		examples = actions.NewReplyExamples(store, embedder, logger, 2)
+	})
+
+	It("picks the examples closest to the tweet", func() {
+		ctx := context.Background()
+		_, err := examples.Add(ctx, "gm cat lord", "The court is in session", "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = examples.Add(ctx, "what is the price of $CAT", "Priceless, like me", "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = examples.Add(ctx, "dogs are better", "Bold words for a peasant", "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = examples.Add(ctx, "gm, price check?", "Quoted for the archives", "quote")
+		Expect(err).NotTo(HaveOccurred())
+
+		selected, err := examples.Select(ctx, "@CatLordLaffy price prediction?", "mention")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).To(HaveLen(2))
+		Expect(selected[0].Reply).To(Equal("Priceless, like me"))
+
+		selected, err = examples.Select(ctx, "gm gm, price?", "quote")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected[0].Reply).To(Equal("Quoted for the archives"))
+	})
+
+	It("is managed through the admin API", func() {
+		server := admin.NewServer(config.AdminConfig{}, logger)
+		server.HandleExamples(map[string]admin.ExampleLibrary{"catlord": examples})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/examples",
+			strings.NewReader(`{"tweet": "gm cat lord", "reply": "The court is in session"}`)))
+		Expect(response.Code).To(Equal(http.StatusCreated))
+		var created memory.ReplyExample
+		Expect(json.Unmarshal(response.Body.Bytes(), &created)).To(Succeed())
+		Expect(created.ID).NotTo(BeZero())
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/examples?account=catlord", nil))
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(ContainSubstring("The court is in session"))
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/examples?account=doglord", nil))
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/examples/"+strconv.FormatInt(created.ID, 10), nil))
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+	})
+})
+
+var _ = Describe("Reply quality tracker", func() {
+	var (
+		server   *twittermock.Server
+		store    *memory.TweetStore
+		examples *actions.ReplyExamples
+		tracker  *actions.ReplyQualityTracker
+		ctx      context.Context
+		cancel   context.CancelFunc
+	)
+
+	// answered stores a mention and the bot's reply to it
+	answered := func(mentionID, mention, replyID, reply string) {
+		Expect(store.SaveTweet(ctx, twitter.Tweet{
+			ID:             mentionID,
+			Text:           mention,
+			AuthorID:       "user-1",
+			ConversationID: mentionID,
+			CreatedAt:      time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		}, memory.CategoryMention, "User One", "userone")).To(Succeed())
+		Expect(store.SaveAgentReply(ctx, mentionID, replyID, mentionID, reply)).To(Succeed())
+		Expect(store.UpdateTweetAfterReply(ctx, mentionID, replyID)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+		examples = actions.NewReplyExamples(store, nil, logger, 2)
+		tracker = actions.NewReplyQualityTracker(client, store, examples, logger, actions.ReplyQualityOptions{
+			Interval: time.Hour,
+			MinAge:   time.Millisecond,
+			Lookback: time.Hour,
+		})
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("records deleted and ignored replies as negative examples once", func() {
+		answered("100", "what is the price of $CAT", "101", "Priceless, like me")
+		answered("200", "gm cat lord", "201", "Meow meow meow meow")
+		answered("300", "dogs are better", "301", "Woof? I mean, no")
+
+		liked := twitter.Tweet{ID: "101", Text: "Priceless, like me", AuthorID: "1000"}
+		liked.PublicMetrics.LikeCount = 3
+		server.AddTweet(liked)
+		server.AddTweet(twitter.Tweet{ID: "301", Text: "Woof? I mean, no", AuthorID: "1000"})
+
+		Expect(tracker.Review(ctx)).To(Succeed())
+		Expect(tracker.Review(ctx)).To(Succeed())
+
+		recorded, err := examples.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorded).To(HaveLen(2))
+		Expect(recorded).To(ContainElement(And(
+			HaveField("SourceTweetID", "201"),
+			HaveField("Reason", memory.ReasonDeleted),
+			HaveField("TweetText", "gm cat lord"),
+			HaveField("Negative", true),
+		)))
+		Expect(recorded).To(ContainElement(And(
+			HaveField("SourceTweetID", "301"),
+			HaveField("Reason", memory.ReasonNoEngagement),
+		)))
+
+		avoid, err := examples.Avoid(ctx, "gm gm cat lord")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(avoid).To(HaveLen(2))
+		Expect(avoid[0]).To(Equal("Meow meow meow meow"))
+
+		selected, err := examples.Select(ctx, "gm gm cat lord", "mention")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).To(BeEmpty())
+	})
+})
@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Reply latency", func() {
+	var (
+		logger   *logrus.Logger
+		database *gorm.DB
+		store    *memory.TweetStore
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		database = newTestDatabase(logger)
+		var err error
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	It("records each reply's latency and reports replies slower than the SLO", func() {
+		server := twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		bus := events.NewBus(logger)
+		DeferCleanup(bus.Close)
+		received := make(chan events.Event, 10)
+		bus.Subscribe(func(event events.Event) { received <- event }, events.ReplyLatencyExceeded)
+
+		late := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello?", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, late, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(database.Table("tweets").Where("id = ?", late.ID).
+			Update("created_at", time.Now().Add(-2*time.Hour)).Error).To(Succeed())
+
+		responder := actions.NewTweetResponder(store, client, logger, &recordingReply{}).
+			WithEvents(bus).
+			WithLatencySLO(time.Hour)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		Expect(server.Posted()).To(HaveLen(1))
+
+		var event events.Event
+		Eventually(received).Should(Receive(&event))
+		Expect(event.BotID).To(Equal("1000"))
+		Expect(event.Data["reply_to_id"]).To(Equal(late.ID))
+		Expect(event.Data["latency_ms"]).To(BeNumerically(">=", (2 * time.Hour).Milliseconds()))
+
+		prompt := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "8"})
+		Expect(store.SaveTweet(ctx, prompt, memory.CategoryMention, "Other", "other")).To(Succeed())
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		Expect(server.Posted()).To(HaveLen(2))
+		Consistently(received, 200*time.Millisecond).ShouldNot(Receive())
+
+		days, err := store.DailyLatencies(ctx, time.Now().Add(-24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(days).To(HaveLen(1))
+		Expect(days[0].Replies).To(Equal(2))
+		Expect(days[0].P95()).To(BeNumerically(">=", 2*time.Hour))
+		Expect(days[0].P50Millis).To(BeNumerically("<", time.Minute.Milliseconds()))
+	})
+
+	It("serves daily percentiles against the SLO on the admin API", func() {
+		today := time.Now().UTC().Truncate(24 * time.Hour).Add(time.Hour)
+		for i := 1; i <= 100; i++ {
+			Expect(store.RecordReplyLatency(ctx, &memory.ReplyLatency{
+				ReplyID:     fmt.Sprintf("today-%d", i),
+				TweetID:     fmt.Sprint(i),
+				MentionedAt: today.Add(-time.Duration(i) * time.Second),
+				RepliedAt:   today,
+			})).To(Succeed())
+		}
+		yesterday := today.Add(-24 * time.Hour)
+		for i := 1; i <= 4; i++ {
+			Expect(store.RecordReplyLatency(ctx, &memory.ReplyLatency{
+				ReplyID:     fmt.Sprintf("yesterday-%d", i),
+				TweetID:     fmt.Sprint(200 + i),
+				MentionedAt: yesterday.Add(-time.Duration(i) * time.Hour),
+				RepliedAt:   yesterday,
+			})).To(Succeed())
+		}
+
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleLatency(map[string]admin.LatencyReporter{"catlord": store}, 2*time.Minute)
+		get := func(path string) (int, []map[string]interface{}) {
+			response := httptest.NewRecorder()
+			adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+			var body struct {
+				SLO  int64                    `json:"slo_ms"`
+				Days []map[string]interface{} `json:"days"`
+			}
+			if response.Code == http.StatusOK {
+				Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+				Expect(body.SLO).To(Equal(int64(120000)))
+			}
+			return response.Code, body.Days
+		}
+
+		code, days := get("/replies/latency")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(days).To(HaveLen(2))
+		Expect(days[0]).To(HaveKeyWithValue("replies", BeNumerically("==", 4)))
+		Expect(days[0]).To(HaveKeyWithValue("p50_ms", BeNumerically("==", (2*time.Hour).Milliseconds())))
+		Expect(days[0]).To(HaveKeyWithValue("within_slo", false))
+		Expect(days[1]).To(HaveKeyWithValue("replies", BeNumerically("==", 100)))
+		Expect(days[1]).To(HaveKeyWithValue("p50_ms", BeNumerically("==", 50000)))
+		Expect(days[1]).To(HaveKeyWithValue("p95_ms", BeNumerically("==", 95000)))
+		Expect(days[1]).To(HaveKeyWithValue("max_ms", BeNumerically("==", 100000)))
+		Expect(days[1]).To(HaveKeyWithValue("within_slo", true))
+
+		code, days = get("/replies/latency?days=1")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(days).To(HaveLen(1))
+
+		for _, path := range []string{"/replies/latency?days=0", "/replies/latency?days=week"} {
+			code, _ = get(path)
+			Expect(code).To(Equal(http.StatusBadRequest), path)
+		}
+	})
+})
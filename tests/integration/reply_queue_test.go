@@ -0,0 +1,360 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/filters"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Reply queue", func() {
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+		client *twitter.TwitterClient
+		store  *memory.TweetStore
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		var err error
+		client, err = twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("leases the highest priority item and hides it until it is retried", func() {
+		Expect(store.EnqueueReplies(ctx, []memory.ReplyWorkItem{
+			{TweetID: "1", ConversationID: "1"},
+			{TweetID: "2", ConversationID: "2", Priority: 2},
+		})).To(Succeed())
+
+		item, err := store.ClaimReply(ctx, "w1", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item.TweetID).To(Equal("2"))
+
+		item, err = store.ClaimReply(ctx, "w2", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item.TweetID).To(Equal("1"))
+
+		item, err = store.ClaimReply(ctx, "w3", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item).To(BeNil())
+
+		Expect(store.RetryReply(ctx, "2", 0, errors.New("boom"))).To(Succeed())
+		item, err = store.ClaimReply(ctx, "w3", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item.TweetID).To(Equal("2"))
+		Expect(item.Attempts).To(Equal(1))
+		Expect(item.LastError).To(Equal("boom"))
+
+		Expect(store.CompleteReply(ctx, "2")).To(Succeed())
+		queued, err := store.QueuedReplies(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(Equal(int64(1)))
+	})
+
+	It("queues checked mentions and answers verified authors first", func() {
+		server.AddUser(twitter.User{ID: "7", Name: "Nobody", Username: "nobody"})
+		server.AddUser(twitter.User{ID: "8", Name: "Famous", Username: "famous", Verified: true})
+		first := server.AddMention("1000", twitter.Tweet{Text: "@mockbot first", AuthorID: "7"})
+		second := server.AddMention("1000", twitter.Tweet{Text: "@mockbot second", AuthorID: "8"})
+		store.SetReplyPriority(memory.ReplyPriority{Weights: memory.DefaultPriorityWeights(), FollowerSaturation: 10000})
+
+		handler, err := actions.NewMentionsHandler(client, nil, logger, store, actions.MentionsOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+		Expect(server.Posted()).To(BeEmpty())
+
+		queued, err := store.QueuedReplies(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(Equal(int64(2)))
+
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "noted"})
+		pool := actions.NewReplyWorkerPool(responder, logger, actions.ReplyWorkerOptions{})
+		for {
+			processed, err := pool.ProcessNext(ctx, "w1")
+			Expect(err).NotTo(HaveOccurred())
+			if !processed {
+				break
+			}
+		}
+
+		posted := server.Posted()
+		Expect(posted).To(HaveLen(2))
+		Expect(posted[0].ReferencedTweets[0].ID).To(Equal(second.ID))
+		Expect(posted[1].ReferencedTweets[0].ID).To(Equal(first.ID))
+
+		queued, err = store.QueuedReplies(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(BeZero())
+	})
+
+	It("records replies a crash kept from being saved and releases stale claims on startup", func() {
+		server.AddUser(twitter.User{ID: "7", Name: "Fan", Username: "fan"})
+		answered := server.AddMention("1000", twitter.Tweet{Text: "@mockbot answered before the crash", AuthorID: "7"})
+		claimed := server.AddMention("1000", twitter.Tweet{Text: "@mockbot claimed before the crash", AuthorID: "7"})
+
+		handler, err := actions.NewMentionsHandler(client, nil, logger, store, actions.MentionsOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+
+		// The reply to the first mention made it to Twitter but not into the
+		// store, and the second was claimed by a worker of the dead process
+		lost := server.AddTweet(twitter.Tweet{
+			Text:             "@fan noted",
+			AuthorID:         "1000",
+			ConversationID:   answered.ConversationID,
+			ReferencedTweets: []twitter.ReferencedTweet{{Type: "replied_to", ID: answered.ID}},
+		})
+		host, err := os.Hostname()
+		Expect(err).NotTo(HaveOccurred())
+		for {
+			item, err := store.ClaimReply(ctx, host+"-1", time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			if item == nil {
+				break
+			}
+		}
+
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "noted"})
+		pool := actions.NewReplyWorkerPool(responder, logger, actions.ReplyWorkerOptions{
+			Recovery: actions.RecoveryOptions{Lookback: time.Hour},
+		})
+		report, err := pool.Recover(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Recorded).To(Equal(1))
+		Expect(report.Released).To(Equal(int64(1)))
+
+		repliedTo, err := store.RepliedTo(ctx, answered.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repliedTo).To(BeTrue())
+		reply, err := store.GetTweet(ctx, lost.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reply.AuthorID).To(Equal("1000"))
+
+		queued, err := store.QueuedTweetIDs(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(Equal([]string{claimed.ID}))
+
+		processed, err := pool.ProcessNext(ctx, "w1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(BeTrue())
+		posted := server.Posted()
+		Expect(posted).To(HaveLen(1))
+		Expect(posted[0].ReferencedTweets[0].ID).To(Equal(claimed.ID))
+
+		// A second pass finds nothing left to repair
+		report, err = pool.Recover(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Recorded).To(BeZero())
+		Expect(report.Released).To(BeZero())
+	})
+
+	It("skips its own content and mentions from bots", func() {
+		server.AddUser(twitter.User{ID: "7", Name: "Fan", Username: "fan"})
+		server.AddUser(twitter.User{ID: "8", Name: "Price Bot", Username: "price_bot"})
+		server.AddUser(twitter.User{ID: "9", Name: "Echo", Username: "echo", Description: "I'm a bot replying to everyone"})
+		own := server.AddTweet(twitter.Tweet{Text: "cats rule", AuthorID: "1000"})
+		server.AddMention("1000", twitter.Tweet{Text: "@mockbot talking to myself", AuthorID: "1000"})
+		server.AddMention("1000", twitter.Tweet{Text: "RT @mockbot: cats rule", AuthorID: "7",
+			ReferencedTweets: []twitter.ReferencedTweet{{Type: "retweeted", ID: own.ID}}})
+		server.AddMention("1000", twitter.Tweet{Text: "@mockbot https://t.co/abc", AuthorID: "7",
+			ReferencedTweets: []twitter.ReferencedTweet{{Type: "quoted", ID: own.ID}}})
+		quote := server.AddMention("1000", twitter.Tweet{Text: "@mockbot dogs rule actually", AuthorID: "7",
+			ReferencedTweets: []twitter.ReferencedTweet{{Type: "quoted", ID: own.ID}}})
+		server.AddMention("1000", twitter.Tweet{Text: "@mockbot BTC is $1", AuthorID: "8"})
+		server.AddMention("1000", twitter.Tweet{Text: "@mockbot beep", AuthorID: "9"})
+		store.SetIdentity("", "mockbot")
+
+		handler, err := actions.NewMentionsHandler(client, nil, logger, store, actions.MentionsOptions{
+			Bots: filters.NewBotGuard([]string{"@Price_Bot"}, true),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+
+		// Only the quote with a comment of its own is answered
+		item, err := store.ClaimReply(ctx, "w1", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item.TweetID).To(Equal(quote.ID))
+		queued, err := store.QueuedReplies(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(Equal(int64(1)))
+	})
+
+	It("hydrates authors missing from the includes through one cached lookup", func() {
+		server.AddUser(twitter.User{ID: "9", Name: "Shy", Username: "shy"})
+		server.AddUser(twitter.User{ID: "11", Name: "Earlier", Username: "earlier"})
+		server.OmitFromIncludes("9")
+		Expect(store.SaveTweet(ctx, twitter.Tweet{
+			ID:             "500",
+			Text:           "@mockbot saved before hydration",
+			AuthorID:       "11",
+			ConversationID: "500",
+		}, memory.CategoryMention, "", "")).To(Succeed())
+		first := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello", AuthorID: "9"})
+		server.AddMention("1000", twitter.Tweet{Text: "@mockbot gone", AuthorID: "404"})
+
+		handler, err := actions.NewMentionsHandler(client, nil, logger, store, actions.MentionsOptions{
+			Authors: actions.NewAuthorResolver(client, logger, 0),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+
+		saved, err := store.GetTweet(ctx, first.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved.AuthorUsername).To(Equal("shy"))
+		earlier, err := store.GetTweet(ctx, "500")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(earlier.AuthorName).To(Equal("Earlier"))
+		Expect(countRequests(server, "GET", "/users")).To(Equal(2))
+
+		// Cached authors and unknown ones are not looked up again
+		server.AddMention("1000", twitter.Tweet{Text: "@mockbot again", AuthorID: "9"})
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+		Expect(countRequests(server, "GET", "/users")).To(Equal(2))
+	})
+
+	It("stores the thread above a mention that lands mid-thread", func() {
+		hoursAgo := func(n int) string {
+			return time.Now().Add(-time.Duration(n) * time.Hour).UTC().Format(time.RFC3339)
+		}
+		server.AddUser(twitter.User{ID: "7", Name: "Starter", Username: "starter"})
+		root := server.AddTweet(twitter.Tweet{Text: "gm, what are we building", AuthorID: "7", CreatedAt: hoursAgo(3)})
+		reply := func(parent twitter.Tweet, author, text string, age int) twitter.Tweet {
+			return server.AddTweet(twitter.Tweet{
+				Text:             text,
+				AuthorID:         author,
+				ConversationID:   root.ID,
+				CreatedAt:        hoursAgo(age),
+				ReferencedTweets: []twitter.ReferencedTweet{{Type: "replied_to", ID: parent.ID}},
+			})
+		}
+		first := reply(root, "8", "an agent", 2)
+		second := reply(first, "7", "which one?", 1)
+		mention := server.AddMention("1000", twitter.Tweet{
+			Text:             "@mockbot this one",
+			AuthorID:         "8",
+			ConversationID:   root.ID,
+			ReferencedTweets: []twitter.ReferencedTweet{{Type: "replied_to", ID: second.ID}},
+		})
+
+		handler, err := actions.NewMentionsHandler(client, nil, logger, store, actions.MentionsOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+
+		threads, err := store.RecallTweetsNeedingReply(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(threads).To(HaveLen(1))
+		var ids []string
+		for _, tweet := range threads[0].Tweets {
+			ids = append(ids, tweet.TweetID)
+		}
+		Expect(ids).To(Equal([]string{root.ID, first.ID, second.ID, mention.ID}))
+		Expect(threads[0].Tweets[0].AuthorUsername).To(Equal("starter"))
+		latest, _ := threads[0].Latest()
+		Expect(latest.TweetID).To(Equal(mention.ID))
+	})
+
+	It("polls only mentions newer than the stored watermark", func() {
+		first := server.AddMention("1000", twitter.Tweet{Text: "@mockbot first", AuthorID: "7"})
+		handler, err := actions.NewMentionsHandler(client, nil, logger, store, actions.MentionsOptions{
+			MaxResults: 5,
+			Overlap:    -1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+		Expect(lastRequest(server, "/users/1000/mentions").Query).NotTo(HaveKey("since_id"))
+		watermark, err := store.Watermark(ctx, memory.StreamMentions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watermark).To(Equal(first.ID))
+
+		// Two pages of new mentions, and an edit of the first one
+		for i := 0; i < 6; i++ {
+			server.AddMention("1000", twitter.Tweet{Text: "@mockbot more", AuthorID: "7"})
+		}
+		edited := server.AddMention("1000", twitter.Tweet{
+			Text:     "@mockbot first, edited",
+			AuthorID: "7",
+		})
+		edited.EditHistoryTweetIDs = []string{first.ID, edited.ID}
+		server.AddTweet(edited)
+
+		Expect(handler.CheckMentions(ctx)).To(Succeed())
+		request := lastRequest(server, "/users/1000/mentions")
+		Expect(request.Query).To(HaveKeyWithValue("since_id", first.ID))
+		Expect(request.Query).To(HaveKey("pagination_token"))
+
+		watermark, err = store.Watermark(ctx, memory.StreamMentions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watermark).To(Equal(edited.ID))
+
+		// The first mention and the six new ones; the edit is not answered twice
+		queued, err := store.QueuedReplies(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queued).To(Equal(int64(7)))
+	})
+
+	It("polls mentions faster while active and slower while quiet", func() {
+		polling := actions.AdaptiveInterval{Min: 30 * time.Second, Max: 10 * time.Minute}
+		Expect(polling.Clamp(2 * time.Minute)).To(Equal(2 * time.Minute))
+		Expect(polling.Next(2*time.Minute, 3)).To(Equal(time.Minute))
+		Expect(polling.Next(time.Minute, 0)).To(Equal(90 * time.Second))
+		Expect(polling.Next(40*time.Second, 1)).To(Equal(30 * time.Second))
+		Expect(polling.Next(8*time.Minute, 0)).To(Equal(10 * time.Minute))
+
+		fixed := actions.AdaptiveInterval{}
+		Expect(fixed.Next(2*time.Minute, 3)).To(Equal(2 * time.Minute))
+	})
+
+	It("keeps throttled mentions queued without counting an attempt", func() {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot again", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "User 7", "user7")).To(Succeed())
+		Expect(store.CountReply(ctx, "7", tweet.ConversationID, time.Now())).To(Succeed())
+
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "later"}).
+			WithThrottle(actions.ThrottleOptions{ThreadCooldown: time.Hour})
+		pool := actions.NewReplyWorkerPool(responder, logger, actions.ReplyWorkerOptions{})
+		Expect(pool.Backfill(ctx)).To(Succeed())
+
+		processed, err := pool.ProcessNext(ctx, "w1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(BeTrue())
+		Expect(server.Posted()).To(BeEmpty())
+
+		Expect(store.RetryReply(ctx, tweet.ID, 0, nil)).To(Succeed())
+		item, err := store.ClaimReply(ctx, "w2", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item.TweetID).To(Equal(tweet.ID))
+		Expect(item.Attempts).To(BeZero())
+	})
+})
@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// cannedResearcher answers every research request with the same answer and
+// records the requests
+type cannedResearcher struct {
+	answer   string
+	err      error
+	requests []string
+}
+
+func (r *cannedResearcher) Reason(_ context.Context, input string) (string, error) {
+	r.requests = append(r.requests, input)
+	return r.answer, r.err
+}
+
+var _ = Describe("Reply research", func() {
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+		client *twitter.TwitterClient
+		store  *memory.TweetStore
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		var err error
+		client, err = twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	mention := func(text, author string) twitter.Tweet {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: text, AuthorID: author})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Fan", "fan"+author)).To(Succeed())
+		return tweet
+	}
+
+	It("puts the facts it looked up in the reply prompt", func() {
+		mention("@mockbot how much $LAFFY is left in the treasury?", "7")
+
+		researcher := &cannedResearcher{answer: "0x1234…abcd holds 4200000 LAFFY on BASE"}
+		model := &pipelineModel{}
+		responder := actions.NewTweetResponder(store, client, logger, thoughts.NewMentionReplyGenerator(model)).
+			WithResearcher(researcher)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+
+		Expect(server.Posted()).To(HaveLen(1))
+		Expect(researcher.requests).To(HaveLen(1))
+		Expect(researcher.requests[0]).To(ContainSubstring("how much $LAFFY is left in the treasury?"))
+		Expect(model.prompts).To(HaveLen(1))
+		Expect(model.prompts[0]).To(ContainSubstring("Facts you looked up"))
+		Expect(model.prompts[0]).To(ContainSubstring("holds 4200000 LAFFY on BASE"))
+	})
+
+	It("replies without facts when none are needed or the lookup fails", func() {
+		mention("@mockbot gm", "7")
+
+		researcher := &cannedResearcher{answer: thoughts.NoFacts}
+		model := &pipelineModel{}
+		responder := actions.NewTweetResponder(store, client, logger, thoughts.NewMentionReplyGenerator(model)).
+			WithResearcher(researcher)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		Expect(server.Posted()).To(HaveLen(1))
+		Expect(model.prompts[0]).NotTo(ContainSubstring("Facts you looked up"))
+
+		mention("@mockbot wen airdrop?", "8")
+		researcher.err = errors.New("rpc unreachable")
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		Expect(server.Posted()).To(HaveLen(2))
+		Expect(researcher.requests).To(HaveLen(2))
+		Expect(model.prompts[1]).NotTo(ContainSubstring("Facts you looked up"))
+	})
+})
@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Reply throttling", func() {
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+		client *twitter.TwitterClient
+		store  *memory.TweetStore
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	// mention adds a mention of the bot by authorID to the API and the store
+	mention := func(authorID, text string) twitter.Tweet {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: text, AuthorID: authorID})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "User "+authorID, "user"+authorID)).To(Succeed())
+		return tweet
+	}
+
+	respond := func(options actions.ThrottleOptions) {
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "begone"}).
+			WithThrottle(options)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		var err error
+		client, err = twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("counts replies per user per day and per conversation", func() {
+		now := time.Now()
+		Expect(store.CountReply(ctx, "7", "c1", now)).To(Succeed())
+		Expect(store.CountReply(ctx, "7", "c1", now)).To(Succeed())
+		Expect(store.CountReply(ctx, "7", "c2", now.Add(-48*time.Hour))).To(Succeed())
+
+		counts, err := store.ReplyCounts(ctx, "7", "c1", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts.UserToday).To(Equal(2))
+		Expect(counts.Conversation).To(Equal(2))
+		Expect(counts.LastConversationReply).To(BeTemporally("~", now, time.Second))
+
+		counts, err = store.ReplyCounts(ctx, "8", "c3", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(Equal(memory.ReplyCounts{}))
+	})
+
+	It("stops replying to a user after the daily cap", func() {
+		mention("7", "@mockbot first")
+		mention("7", "@mockbot second")
+		mention("8", "@mockbot someone else")
+
+		respond(actions.ThrottleOptions{MaxRepliesPerUser: 1})
+
+		Expect(server.Posted()).To(HaveLen(2))
+		counts, err := store.ReplyCounts(ctx, "7", "", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts.UserToday).To(Equal(1))
+	})
+
+	It("caps the depth of a conversation and waits out the thread cooldown", func() {
+		root := mention("7", "@mockbot fight me")
+		Expect(store.CountReply(ctx, "9", root.ConversationID, time.Now())).To(Succeed())
+
+		respond(actions.ThrottleOptions{ThreadCooldown: time.Hour})
+		Expect(server.Posted()).To(BeEmpty())
+
+		respond(actions.ThrottleOptions{MaxConversationDepth: 1})
+		Expect(server.Posted()).To(BeEmpty())
+
+		respond(actions.ThrottleOptions{MaxConversationDepth: 2})
+		Expect(server.Posted()).To(HaveLen(1))
+	})
+
+	It("closes a conversation going in circles with a sign-off", func() {
+		loops := actions.LoopOptions{Repeats: 3, SignOff: "This audience is over."}
+		closeLoops := func() {
+			responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "begone"}).
+				WithLoopDetection(loops)
+			Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		}
+
+		root := mention("7", "@mockbot send me free tokens now")
+		for _, text := range []string{"@mockbot send me free tokens now!", "@mockbot send me free tokens NOW"} {
+			tweet := server.AddMention("1000", twitter.Tweet{Text: text, AuthorID: "7", ConversationID: root.ConversationID})
+			Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "User 7", "user7")).To(Succeed())
+		}
+
+		closeLoops()
+		posted := server.Posted()
+		Expect(posted).To(HaveLen(1))
+		Expect(posted[0].Text).To(Equal("This audience is over."))
+
+		muted, err := store.ListMutedConversations(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(muted).To(HaveLen(1))
+		Expect(muted[0].ConversationID).To(Equal(root.ConversationID))
+		Expect(muted[0].Reason).To(ContainSubstring("3 near-identical tweets"))
+
+		// Closed conversations get no more replies
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello?", AuthorID: "7", ConversationID: root.ConversationID})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "User 7", "user7")).To(Succeed())
+		closeLoops()
+		Expect(server.Posted()).To(HaveLen(1))
+	})
+
+	It("closes a conversation silently after the bot replied too often", func() {
+		root := mention("7", "@mockbot fight me")
+		Expect(store.SaveAgentReply(ctx, root.ID, "9001", root.ConversationID, "no")).To(Succeed())
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot coward", AuthorID: "7", ConversationID: root.ConversationID})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "User 7", "user7")).To(Succeed())
+
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "begone"}).
+			WithLoopDetection(actions.LoopOptions{MaxBotReplies: 1})
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+
+		Expect(server.Posted()).To(BeEmpty())
+		muted, err := store.ListMutedConversations(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(muted).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/archive"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Retention", func() {
+	var (
+		logger   *logrus.Logger
+		database *gorm.DB
+		store    *memory.TweetStore
+		dir      string
+		sink     *archive.FilePublisher
+		ctx      context.Context
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		database = newTestDatabase(logger)
+		var err error
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		dir = GinkgoT().TempDir()
+		sink, err = archive.NewFilePublisher(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+		now = time.Now()
+	})
+
+	// save stores a tweet as if it was stored age ago
+	save := func(id, conversationID string, age time.Duration) {
+		tweet := twitter.Tweet{
+			ID:             id,
+			Text:           "@mockbot tweet " + id,
+			AuthorID:       "7",
+			ConversationID: conversationID,
+		}
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(database.Table("tweets").Where("id = ?", id).Update("created_at", now.Add(-age)).Error).To(Succeed())
+	}
+
+	storedIDs := func(ids ...string) []string {
+		stored, err := store.StoredTweetIDs(ctx, ids)
+		Expect(err).NotTo(HaveOccurred())
+		var found []string
+		for id := range stored {
+			found = append(found, id)
+		}
+		return found
+	}
+
+	archivedRows := func(table string) []map[string]interface{} {
+		files, err := filepath.Glob(filepath.Join(dir, "1000", table, "*.jsonl.gz"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var rows []map[string]interface{}
+		for _, path := range files {
+			file, err := os.Open(path)
+			Expect(err).NotTo(HaveOccurred())
+			zr, err := gzip.NewReader(file)
+			Expect(err).NotTo(HaveOccurred())
+			scanner := bufio.NewScanner(zr)
+			for scanner.Scan() {
+				var row map[string]interface{}
+				Expect(json.Unmarshal(scanner.Bytes(), &row)).To(Succeed())
+				rows = append(rows, row)
+			}
+			Expect(scanner.Err()).NotTo(HaveOccurred())
+			file.Close()
+		}
+		return rows
+	}
+
+	It("archives and prunes conversations quiet for longer than their retention", func() {
+		save("100", "100", 100*24*time.Hour)
+		save("101", "100", 95*24*time.Hour)
+		// An old root whose conversation is still active is kept
+		save("200", "200", 100*24*time.Hour)
+		save("201", "200", time.Hour)
+
+		retention := actions.NewRetentionAction(store, logger, actions.RetentionOptions{
+			Interval:  time.Hour,
+			Policies:  []actions.RetentionPolicy{{Table: memory.RetainTweets, MaxAge: 90 * 24 * time.Hour}},
+			BatchSize: 1,
+			Sink:      sink,
+		})
+		pruned, err := retention.Prune(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(Equal(map[string]int64{memory.RetainTweets: 2}))
+
+		Expect(storedIDs("100", "101", "200", "201")).To(ConsistOf("200", "201"))
+
+		rows := archivedRows(memory.RetainTweets)
+		Expect(rows).To(HaveLen(2))
+		Expect([]interface{}{rows[0]["id"], rows[1]["id"]}).To(ConsistOf("100", "101"))
+		Expect(rows[0]["text"]).To(HavePrefix("@mockbot tweet"))
+	})
+
+	It("keeps tweets waiting in the reply queue", func() {
+		save("100", "100", 100*24*time.Hour)
+		Expect(store.EnqueueReplies(ctx, []memory.ReplyWorkItem{{TweetID: "100", ConversationID: "100"}})).To(Succeed())
+
+		retention := actions.NewRetentionAction(store, logger, actions.RetentionOptions{
+			Policies: []actions.RetentionPolicy{{Table: memory.RetainTweets, MaxAge: 90 * 24 * time.Hour}},
+		})
+		pruned, err := retention.Prune(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(BeEmpty())
+		Expect(storedIDs("100")).To(ConsistOf("100"))
+	})
+
+	It("keeps rows whose policy has no age", func() {
+		save("100", "100", 100*24*time.Hour)
+		Expect(store.SaveJudgment(ctx, &memory.JudgmentRecord{
+			SubjectUsername: "fan",
+			Scores:          map[string]int{"wit": 3},
+			Verdict:         "mid",
+			CreatedAt:       now.Add(-400 * 24 * time.Hour),
+		})).To(Succeed())
+
+		retention := actions.NewRetentionAction(store, logger, actions.RetentionOptions{
+			Policies: []actions.RetentionPolicy{
+				{Table: memory.RetainTweets, MaxAge: 90 * 24 * time.Hour},
+				{Table: memory.RetainJudgments},
+			},
+			Sink: sink,
+		})
+		_, err := retention.Prune(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		judgments, err := store.RecentJudgments(ctx, "fan", 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(judgments).To(HaveLen(1))
+		Expect(archivedRows(memory.RetainJudgments)).To(BeEmpty())
+	})
+
+	It("prunes analytics rows by their composite key", func() {
+		Expect(store.RecordMentionVolume(ctx, now.Add(-40*24*time.Hour), 3)).To(Succeed())
+		Expect(store.RecordMentionVolume(ctx, now.Add(-39*24*time.Hour), 4)).To(Succeed())
+		Expect(store.RecordMentionVolume(ctx, now, 5)).To(Succeed())
+
+		retention := actions.NewRetentionAction(store, logger, actions.RetentionOptions{
+			Policies:  []actions.RetentionPolicy{{Table: memory.RetainAnalytics, MaxAge: 30 * 24 * time.Hour}},
+			BatchSize: 1,
+			Sink:      sink,
+		})
+		pruned, err := retention.Prune(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(Equal(map[string]int64{memory.RetainAnalytics: 2}))
+
+		rows := archivedRows(memory.RetainAnalytics)
+		Expect(rows).To(HaveLen(2))
+		Expect(rows[0]["metric"]).To(Equal(memory.MetricMentionVolume))
+	})
+})
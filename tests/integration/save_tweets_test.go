@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("SaveTweets", func() {
+	var (
+		database *gorm.DB
+		store    *memory.TweetStore
+		ctx      context.Context
+		cancel   context.CancelFunc
+	)
+
+	// page builds a mention, replying to parentID when set
+	page := func(id, conversationID, parentID, text string) memory.TweetWithMeta {
+		tweet := twitter.Tweet{ID: id, Text: text, ConversationID: conversationID, AuthorID: "7"}
+		if parentID != "" {
+			tweet.ReferencedTweets = []twitter.ReferencedTweet{{Type: "replied_to", ID: parentID}}
+		}
+		return memory.TweetWithMeta{Tweet: tweet, Category: memory.CategoryMention, AuthorName: "Fan", AuthorUsername: "fan"}
+	}
+
+	// column reads one column of a stored tweet
+	column := func(id, name string, dest interface{}) {
+		Expect(database.Table("tweets").Where("id = ?", id).Select(name).Scan(dest).Error).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		database = newTestDatabase(logger)
+		var err error
+
+		store, err = memory.NewTweetStore(logger, database, "bot-1", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("stores a page of mentions and counts new replies on their parents", func() {
+		Expect(store.SaveTweet(ctx, page("100", "100", "", "root").Tweet, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+
+		Expect(store.SaveTweets(ctx, []memory.TweetWithMeta{
+			page("101", "100", "100", "first reply"),
+			page("102", "100", "100", "second reply"),
+			page("200", "200", "", "another thread"),
+			page("200", "200", "", "duplicate in the page"),
+		})).To(Succeed())
+
+		for _, id := range []string{"101", "102", "200"} {
+			var username string
+			column(id, "author_username", &username)
+			Expect(username).To(Equal("fan"), id)
+		}
+		var text string
+		column("200", "text", &text)
+		Expect(text).To(Equal("another thread"))
+
+		var unread int
+		column("100", "unread_replies", &unread)
+		Expect(unread).To(Equal(2))
+	})
+
+	It("updates tweets that are already stored", func() {
+		first := page("300", "300", "", "before")
+		first.Tweet.EditHistoryTweetIDs = []string{"300"}
+		Expect(store.SaveTweets(ctx, []memory.TweetWithMeta{first})).To(Succeed())
+
+		second := page("300", "300", "", "after")
+		second.Tweet.PublicMetrics.LikeCount = 5
+		Expect(store.SaveTweets(ctx, []memory.TweetWithMeta{second})).To(Succeed())
+
+		var text string
+		column("300", "text", &text)
+		Expect(text).To(Equal("after"))
+
+		var history string
+		column("300", "edit_history_tweet_ids", &history)
+		Expect(history).To(ContainSubstring("300"))
+
+		engagement, err := store.TweetEngagement(ctx, "300")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(engagement).To(Equal(5))
+	})
+
+	It("does not write once the context is cancelled", func() {
+		cancelled, stop := context.WithCancel(context.Background())
+		stop()
+
+		Expect(store.SaveTweet(cancelled, page("400", "400", "", "late").Tweet, memory.CategoryMention, "Fan", "fan")).NotTo(Succeed())
+
+		var count int64
+		Expect(database.Table("tweets").Where("id = ?", "400").Count(&count).Error).To(Succeed())
+		Expect(count).To(BeZero())
+	})
+})
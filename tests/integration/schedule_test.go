@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/schedule"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Posting schedule", func() {
+	It("holds posts during quiet hours and embargoes", func() {
+		policy, err := schedule.New("America/New_York",
+			[]string{"23:00-07:00"},
+			[]string{"2026-11-03T12:00:00Z/2026-11-03T18:00:00Z"},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		newYork, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+
+		afternoon := time.Date(2026, 10, 16, 15, 0, 0, 0, newYork)
+		Expect(policy.Open(afternoon)).To(BeTrue())
+
+		// Quiet hours run past midnight
+		late := time.Date(2026, 10, 16, 23, 30, 0, 0, newYork)
+		Expect(policy.Open(late)).To(BeFalse())
+		Expect(policy.NextOpen(late)).To(BeTemporally("==", time.Date(2026, 10, 17, 7, 0, 0, 0, newYork)))
+
+		// The embargo starts as the quiet hours end and holds posts until it is over
+		morning := time.Date(2026, 11, 3, 6, 0, 0, 0, newYork)
+		Expect(policy.NextOpen(morning)).To(BeTemporally("==", time.Date(2026, 11, 3, 18, 0, 0, 0, time.UTC)))
+	})
+
+	It("posts at any time without quiet hours or embargoes", func() {
+		policy, err := schedule.New("Europe/Berlin", nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy).To(BeNil())
+		Expect(policy.Open(time.Now())).To(BeTrue())
+
+		_, err = schedule.New("", []string{"7-8"}, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = schedule.New("Mars/Olympus", nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("releases a queued reply when the embargo ends", func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		server := twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+		store, err := memory.NewTweetStore(logger, newTestDatabase(logger), "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		// RFC 3339 drops the fraction, so the embargo ends one to two seconds from now
+		opens := time.Now().Add(2 * time.Second).Truncate(time.Second)
+		policy, err := schedule.New("", nil, []string{
+			time.Now().Add(-time.Hour).UTC().Format(time.RFC3339) + "/" + opens.UTC().Format(time.RFC3339),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+
+		responder := actions.NewTweetResponder(store, client, logger, cannedReply{text: "gm, peasant"})
+		pool := actions.NewReplyWorkerPool(responder, logger, actions.ReplyWorkerOptions{
+			TweetsPerWindow: 9000,
+			PollInterval:    10 * time.Millisecond,
+			RetryDelay:      time.Millisecond,
+			Schedule:        policy,
+		})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pool.Execute(ctx)
+		}()
+		DeferCleanup(func() {
+			cancel()
+			<-done
+		})
+
+		Consistently(server.Posted, 500*time.Millisecond).Should(BeEmpty())
+		Eventually(server.Posted, 5*time.Second).Should(HaveLen(1))
+		Expect(time.Now()).To(BeTemporally(">=", opens))
+		posted := server.Posted()[0]
+		Expect(posted.Text).To(Equal("gm, peasant"))
+		Expect(posted.ReferencedTweets[0].ID).To(Equal(mention.ID))
+	})
+})
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -61,7 +62,7 @@ var _ = Describe("Scraper Integration", func() {
 			expectedTasks := len(config.Tasks)
 
 			// Process tasks synchronously
-			err := scr.ProcessTasks(config)
+			err := scr.ProcessTasks(context.Background(), config)
 			Expect(err).NotTo(HaveOccurred())
 
 			// Verify final status
@@ -70,6 +71,45 @@ var _ = Describe("Scraper Integration", func() {
 			Expect(finalStatus.CompletedTasks + finalStatus.FailedTasks).To(Equal(finalStatus.TotalTasks))
 			Expect(finalStatus.FailedTasks).To(Equal(0))
 			Expect(finalStatus.RetryingTasks).To(Equal(0))
+			Expect(finalStatus.Concurrency).To(BeNumerically(">=", 1))
+			Expect(finalStatus.Concurrency).To(BeNumerically("<=", config.WorkerCount))
 		})
+
+		It("should stream every finished task", func() {
+			results := scr.Results()
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- scr.ProcessTasks(context.Background(), config)
+			}()
+
+			seen := make(map[string]bool)
+			for result := range results {
+				Expect(result.Err).NotTo(HaveOccurred())
+				seen[result.Task.ID] = true
+			}
+
+			Eventually(errCh, waitTimeout).Should(Receive(BeNil()))
+			Expect(seen).To(HaveLen(len(config.Tasks)))
+		})
+	})
+})
+
+var _ = Describe("TweetProcessor", func() {
+	It("skips tweets an earlier batch already returned", func() {
+		processor := scraper.NewTweetProcessor(logrus.New())
+
+		first := processor.ProcessTweets([]masatwitter.Tweet{{ID: "1"}, {ID: "2"}, {ID: "2"}})
+		Expect(first).To(HaveLen(2))
+
+		second := processor.ProcessTweets([]masatwitter.Tweet{{ID: "2"}, {ID: "3"}})
+		Expect(second).To(HaveLen(1))
+		Expect(second[0].ID).To(Equal("3"))
+
+		unique, duplicates := processor.Counts()
+		Expect(unique).To(Equal(3))
+		Expect(duplicates).To(Equal(2))
+
+		processor.Reset()
+		Expect(processor.ProcessTweets([]masatwitter.Tweet{{ID: "1"}})).To(HaveLen(1))
 	})
 })
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/filters"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// recordingReply answers every tweet and remembers the configs it was given
+type recordingReply struct {
+	configs []thoughts.MentionReplyConfig
+}
+
+func (r *recordingReply) GenerateReply(ctx context.Context, config thoughts.MentionReplyConfig) (string, error) {
+	r.configs = append(r.configs, config)
+	return "as you were, peasant", nil
+}
+
+var _ = Describe("Sentiment", func() {
+	DescribeTable("scores mentions with the local lexicon",
+		func(text string, matcher OmegaMatcher) {
+			Expect(filters.LexiconSentiment(text)).To(matcher)
+		},
+		Entry("praise", "@CatLordLaffy you are adorable and majestic 👑", BeNumerically(">=", thoughts.PraiseSentiment)),
+		Entry("hostility", "@CatLordLaffy stupid ugly cat, your coin is a scam", BeNumerically("<=", thoughts.HostileSentiment)),
+		Entry("negated praise", "@CatLordLaffy you are not very cute", BeNumerically("<", 0)),
+		Entry("neutral", "@CatLordLaffy what time is it", BeZero()),
+	)
+
+	It("passes the stored sentiment of a mention to the reply generator", func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server := twittermock.NewServer()
+		DeferCleanup(server.Close)
+		client, err := twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+
+		database := newTestDatabase(logger)
+		store, err := memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		tweet := server.AddMention("1000", twitter.Tweet{Text: "@mockbot you are trash", AuthorID: "7"})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Hater", "hater")).To(Succeed())
+		analyzer := filters.NewSentimentAnalyzer(nil, logger)
+		Expect(store.SetSentiment(ctx, tweet.ID, analyzer.Score(ctx, tweet.Text))).To(Succeed())
+
+		generator := &recordingReply{}
+		responder := actions.NewTweetResponder(store, client, logger, generator)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+
+		Expect(generator.configs).To(HaveLen(1))
+		Expect(generator.configs[0].Sentiment).To(BeNumerically("<", 0))
+		Expect(server.Posted()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// recordingSummary appends the texts of the posts to the previous summary
+type recordingSummary struct {
+	configs []thoughts.SummaryConfig
+}
+
+func (r *recordingSummary) Summarize(ctx context.Context, config thoughts.SummaryConfig) (string, error) {
+	r.configs = append(r.configs, config)
+	parts := []string{}
+	if config.Previous != "" {
+		parts = append(parts, config.Previous)
+	}
+	for _, post := range config.Posts {
+		parts = append(parts, post.Text)
+	}
+	return strings.Join(parts, "|"), nil
+}
+
+var _ = Describe("Memory summaries", func() {
+	var (
+		logger   *logrus.Logger
+		server   *twittermock.Server
+		database *gorm.DB
+		store    *memory.TweetStore
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+
+		database = newTestDatabase(logger)
+		var err error
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	It("folds only new tweets into the summaries and drops them on deletion", func() {
+		gm := twitter.Tweet{ID: "100", Text: "@mockbot gm", AuthorID: "7", ConversationID: "100"}
+		Expect(store.SaveTweet(ctx, gm, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		Expect(store.SaveAgentReply(ctx, "100", "101", "100", "gm peasant")).To(Succeed())
+		moon := twitter.Tweet{ID: "102", Text: "@mockbot wen moon", AuthorID: "7", ConversationID: "100"}
+		Expect(store.SaveTweet(ctx, moon, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		elsewhere := twitter.Tweet{ID: "200", Text: "not talking to you", AuthorID: "9", ConversationID: "200"}
+		Expect(store.SaveTweet(ctx, elsewhere, memory.CategoryConversation, "Other", "other")).To(Succeed())
+		for i, id := range []string{"100", "101", "102"} {
+			createdAt := time.Now().Add(time.Duration(i-3) * time.Hour)
+			Expect(database.Table("tweets").Where("id = ?", id).Update("created_at", createdAt).Error).To(Succeed())
+		}
+
+		generator := &recordingSummary{}
+		summarizer := actions.NewMemorySummarizer(store, generator, logger, actions.SummarizerOptions{MinTweets: 2, Chunk: 2})
+
+		conversations, users, err := summarizer.SummarizeRecent(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conversations).To(Equal(1))
+		Expect(users).To(Equal(1))
+		Expect(generator.configs).To(HaveLen(3))
+
+		summary, err := store.ConversationSummary(ctx, "100")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Summary).To(Equal("@mockbot gm|gm peasant|@mockbot wen moon"))
+		Expect(summary.TweetCount).To(Equal(3))
+		Expect(summary.LastTweetID).To(Equal("102"))
+
+		fan, err := store.UserSummary(ctx, "7")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fan.Summary).To(Equal("@mockbot gm|@mockbot wen moon"))
+		Expect(fan.Username).To(Equal("fan"))
+		Expect(fan.TweetCount).To(Equal(2))
+
+		conversations, users, err = summarizer.SummarizeRecent(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conversations + users).To(BeZero())
+		Expect(generator.configs).To(HaveLen(3))
+
+		metoo := twitter.Tweet{ID: "103", Text: "me too", AuthorID: "8", ConversationID: "100"}
+		Expect(store.SaveTweet(ctx, metoo, memory.CategoryConversation, "Other", "other")).To(Succeed())
+		conversations, users, err = summarizer.SummarizeRecent(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conversations).To(Equal(1))
+		Expect(users).To(BeZero())
+		Expect(generator.configs).To(HaveLen(4))
+		Expect(generator.configs[3].Previous).To(Equal("@mockbot gm|gm peasant|@mockbot wen moon"))
+		Expect(generator.configs[3].Posts).To(HaveLen(1))
+
+		summary, err = store.ConversationSummary(ctx, "100")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.TweetCount).To(Equal(4))
+		Expect(summary.LastTweetID).To(Equal("103"))
+
+		_, err = store.DeleteTweets(ctx, []string{"102"})
+		Expect(err).NotTo(HaveOccurred())
+		summary, err = store.ConversationSummary(ctx, "100")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(BeNil())
+		fan, err = store.UserSummary(ctx, "7")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fan).To(BeNil())
+	})
+
+	It("puts the summaries into the reply prompt in place of the tweets they cover", func() {
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		client, err := twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot so wen moon", AuthorID: "7"})
+		for i := 0; i < 5; i++ {
+			earlier := twitter.Tweet{ID: fmt.Sprint(50 + i), Text: fmt.Sprintf("earlier %d", i), AuthorID: "7", ConversationID: mention.ConversationID}
+			Expect(store.SaveTweet(ctx, earlier, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+			Expect(database.Table("tweets").Where("id = ?", earlier.ID).Updates(map[string]interface{}{
+				"created_at":       time.Now().Add(time.Duration(i-10) * time.Hour),
+				"needs_reply":      false,
+				"is_participating": true,
+			}).Error).To(Succeed())
+		}
+		Expect(store.SaveTweet(ctx, mention, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+
+		Expect(store.SaveConversationSummary(ctx, &memory.ConversationSummary{
+			ConversationID: mention.ConversationID,
+			Summary:        "the fan keeps asking about the moon",
+			TweetCount:     4,
+			LastTweetID:    "53",
+		})).To(Succeed())
+		Expect(store.SaveUserSummary(ctx, &memory.UserSummary{
+			UserID:      "7",
+			Username:    "fan",
+			Summary:     "a loyal degen",
+			TweetCount:  5,
+			LastTweetID: "54",
+		})).To(Succeed())
+
+		generator := &recordingReply{}
+		responder := actions.NewTweetResponder(store, client, logger, generator)
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+
+		Expect(generator.configs).To(HaveLen(1))
+		reply := generator.configs[0]
+		Expect(reply.ConversationSummary).To(Equal("the fan keeps asking about the moon"))
+		Expect(reply.AuthorSummary).To(Equal("a loyal degen"))
+		Expect(reply.ConversationContext).NotTo(ContainSubstring("earlier 0"))
+		Expect(reply.ConversationContext).NotTo(ContainSubstring("earlier 1"))
+		for _, kept := range []string{"earlier 2", "earlier 3", "earlier 4"} {
+			Expect(reply.ConversationContext).To(ContainSubstring(kept))
+		}
+	})
+})
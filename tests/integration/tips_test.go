@@ -0,0 +1,200 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/dryrun"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeChain sends tips without a chain, recording the transfers
+type fakeChain struct {
+	amounts    []*big.Int
+	recipients []common.Address
+}
+
+func (c *fakeChain) ResolveAddress(_ context.Context, _ wallet.NetworkType, addressOrName string) (common.Address, error) {
+	if !common.IsHexAddress(addressOrName) {
+		return common.Address{}, fmt.Errorf("invalid address %q", addressOrName)
+	}
+	return common.HexToAddress(addressOrName), nil
+}
+
+func (c *fakeChain) DisplayAddress(_ context.Context, address common.Address) string {
+	return address.Hex()
+}
+
+func (c *fakeChain) GetTokenMetadata(context.Context, wallet.NetworkType, common.Address) (*wallet.TokenMetadata, error) {
+	return &wallet.TokenMetadata{Symbol: "LAFFY", Decimals: 18}, nil
+}
+
+func (c *fakeChain) TransferERC20(_ context.Context, _ wallet.NetworkType, _, to common.Address, amount *big.Int) (*common.Hash, error) {
+	c.amounts = append(c.amounts, amount)
+	c.recipients = append(c.recipients, to)
+	hash := common.BigToHash(big.NewInt(int64(len(c.amounts))))
+	return &hash, nil
+}
+
+var _ = Describe("Tips", func() {
+	const aliceWallet = "0x00000000000000000000000000000000000a11ce"
+
+	var (
+		logger *logrus.Logger
+		server *twittermock.Server
+		client *twitter.TwitterClient
+		store  *memory.TweetStore
+		chain  *fakeChain
+		model  *pipelineModel
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		clientConfig := server.Config(logger)
+		clientConfig.UserID = "1000"
+		var err error
+		client, err = twitter.NewTwitterClient(clientConfig)
+		Expect(err).NotTo(HaveOccurred())
+		server.AddUser(twitter.User{ID: "42", Username: "alice", Name: "Alice"})
+		server.AddUser(twitter.User{ID: "43", Username: "bob", Name: "Bob"})
+
+		database := newTestDatabase(logger)
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		chain = &fakeChain{}
+		model = &pipelineModel{}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	newResponder := func(policy actions.TipPolicy) (*actions.TweetResponder, *actions.Tipper) {
+		tipper := actions.NewTipper(chain, client, store, logger, actions.TipOptions{
+			Network: wallet.BASE,
+			Token:   common.HexToAddress("0x0000000000000000000000000000000000001aff"),
+			Policy:  policy,
+		})
+		responder := actions.NewTweetResponder(store, client, logger, thoughts.NewMentionReplyGenerator(model)).
+			WithTipper(tipper)
+		return responder, tipper
+	}
+
+	// command stores a mention and answers it, returning the bot's reply
+	command := func(responder *actions.TweetResponder, text, authorID, username string) string {
+		tweet := server.AddMention("1000", twitter.Tweet{Text: text, AuthorID: authorID})
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, username, username)).To(Succeed())
+		before := len(server.Posted())
+		Expect(responder.ProcessTweetsInBatches(ctx, actions.BatchProcessConfig{BatchSize: 10})).To(Succeed())
+		posted := server.Posted()
+		Expect(posted).To(HaveLen(before + 1))
+		return posted[len(posted)-1].Text
+	}
+
+	It("sends a tipper's tip to the wallet the recipient registered", func() {
+		responder, _ := newResponder(actions.TipPolicy{MaxAmount: 1000})
+		Expect(store.GrantRole(ctx, "7", memory.RoleTipper)).To(Succeed())
+
+		reply := command(responder, "@mockbot wallet "+aliceWallet, "42", "alice")
+		Expect(reply).To(ContainSubstring(common.HexToAddress(aliceWallet).Hex()))
+
+		reply = command(responder, "@mockbot tip @alice 2.5 $laffy", "7", "treasurer")
+		Expect(chain.amounts).To(HaveLen(1))
+		Expect(chain.amounts[0].String()).To(Equal("2500000000000000000"))
+		Expect(chain.recipients[0]).To(Equal(common.HexToAddress(aliceWallet)))
+		Expect(reply).To(ContainSubstring("2.5 LAFFY to @alice"))
+		Expect(reply).To(ContainSubstring(common.BigToHash(big.NewInt(1)).Hex()))
+		Expect(model.prompts).To(BeEmpty())
+
+		sent, err := store.TipsByStatus(ctx, memory.TipSent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sent).To(HaveLen(1))
+		Expect(sent[0].RecipientID).To(Equal("42"))
+	})
+
+	It("refuses unauthorized tips and holds large ones for approval", func() {
+		responder, tipper := newResponder(actions.TipPolicy{MaxAmount: 1000, ApprovalAbove: 100})
+		Expect(store.GrantRole(ctx, "7", memory.RoleTipper)).To(Succeed())
+		Expect(store.SetTipWallet(ctx, "42", "alice", aliceWallet)).To(Succeed())
+
+		Expect(command(responder, "@mockbot tip @alice 10 LAFFY", "8", "stranger")).To(ContainSubstring("treasurers"))
+		Expect(command(responder, "@mockbot tip @bob 10 LAFFY", "7", "treasurer")).To(ContainSubstring("@bob has no wallet"))
+		Expect(command(responder, "@mockbot tip @alice 5000 LAFFY", "7", "treasurer")).To(ContainSubstring("maximum"))
+		Expect(command(responder, "@mockbot tip @alice 500 LAFFY", "7", "treasurer")).To(ContainSubstring("approval"))
+		Expect(chain.amounts).To(BeEmpty())
+
+		pending, err := tipper.PendingTips(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(HaveLen(1))
+
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleTips(map[string]admin.TipManager{"catlord": tipper})
+		response := httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tips/%d/approve", pending[0].ID), nil))
+		Expect(response.Code).To(Equal(http.StatusOK))
+
+		var approved memory.Tip
+		Expect(json.Unmarshal(response.Body.Bytes(), &approved)).To(Succeed())
+		Expect(approved.Status).To(Equal(memory.TipSent))
+		Expect(chain.amounts).To(HaveLen(1))
+		posted := server.Posted()
+		Expect(posted[len(posted)-1].Text).To(ContainSubstring("500 LAFFY to @alice"))
+
+		// A tip is only approved once
+		response = httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tips/%d/approve", pending[0].ID), nil))
+		Expect(response.Code).To(Equal(http.StatusConflict))
+	})
+
+	It("records tips in dry-run mode instead of broadcasting them", func() {
+		chainWithTokens := newTestChain(logger, big.NewInt(1e18), nil)
+		chainWithTokens.client.SetDryRun(store)
+		tipper := actions.NewTipper(chainWithTokens.client, client, store, logger, actions.TipOptions{
+			Network: devnet,
+			Token:   testTokenAddress,
+			Policy:  actions.TipPolicy{MaxAmount: 1000},
+		})
+		responder := actions.NewTweetResponder(store, client, logger, thoughts.NewMentionReplyGenerator(model)).
+			WithTipper(tipper)
+		Expect(store.GrantRole(ctx, "7", memory.RoleTipper)).To(Succeed())
+		Expect(store.SetTipWallet(ctx, "42", "alice", aliceWallet)).To(Succeed())
+
+		reply := command(responder, "@mockbot tip @alice 0.25 $laffy", "7", "treasurer")
+		Expect(reply).To(ContainSubstring("0.25 LAFFY to @alice"))
+
+		recorded, err := store.ListDryRunPosts(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0].Kind).To(Equal(string(dryrun.KindERC20Transfer)))
+		Expect(recorded[0].Target).To(Equal(common.HexToAddress(aliceWallet).Hex()))
+
+		// Nothing reached the chain
+		nonce, err := chainWithTokens.backend.Client().PendingNonceAt(ctx, chainWithTokens.Owner())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nonce).To(BeZero())
+		Expect(chainWithTokens.TokenBalance(ctx, common.HexToAddress(aliceWallet)).Int64()).To(BeZero())
+		Expect(chainWithTokens.TokenBalance(ctx, chainWithTokens.Owner()).Int64()).To(Equal(int64(1e18)))
+	})
+})
@@ -0,0 +1,200 @@
+package integration
+
+import (
+	"context"
+	"sync"
+
+	agent "github.com/lisanmuaddib/agent-go/pkg"
+	"github.com/lisanmuaddib/agent-go/pkg/tools"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// echoTool returns its validated arguments and counts its calls
+type echoTool struct {
+	calls int
+}
+
+func (t *echoTool) Name() string        { return "echo" }
+func (t *echoTool) Description() string { return "Echo the text" }
+
+func (t *echoTool) Parameters() tools.Schema {
+	return tools.NewSchema(map[string]tools.Property{
+		"text": {Type: tools.TypeString},
+		"mood": {Type: tools.TypeString, Enum: []string{"regal", "grumpy"}},
+	}, "text")
+}
+
+func (t *echoTool) Call(_ context.Context, args map[string]any) (string, error) {
+	t.calls++
+	return tools.StringArg(args, "mood", "regal") + ": " + tools.StringArg(args, "text", ""), nil
+}
+
+// toolCallingModel asks for the given tool calls once, then answers with the
+// responses it got back. It records the messages of every request.
+type toolCallingModel struct {
+	mu       sync.Mutex
+	calls    []llms.ToolCall
+	requests [][]llms.MessageContent
+}
+
+func (m *toolCallingModel) GenerateContent(_ context.Context, messages []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, messages)
+
+	if len(m.requests) == 1 {
+		return &llms.ContentResponse{Choices: []*llms.ContentChoice{{ToolCalls: m.calls}}}, nil
+	}
+	var answer string
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			if response, ok := part.(llms.ToolCallResponse); ok {
+				answer += response.Content
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: answer}}}, nil
+}
+
+func (m *toolCallingModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+var _ = Describe("Tools", func() {
+	Describe("Schema", func() {
+		schema := tools.NewSchema(map[string]tools.Property{
+			"query":  {Type: tools.TypeString},
+			"count":  {Type: tools.TypeInteger},
+			"ratio":  {Type: tools.TypeNumber},
+			"exact":  {Type: tools.TypeBoolean},
+			"sort":   {Type: tools.TypeString, Enum: []string{"recent", "top"}},
+			"broken": {Type: "array"},
+		}, "query")
+
+		It("returns the arguments that match their properties", func() {
+			args, err := schema.Validate(`{"query": "$LAFFY", "count": 5, "ratio": 0.5, "exact": true, "sort": "top"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(args).To(Equal(map[string]any{
+				"query": "$LAFFY",
+				"count": float64(5),
+				"ratio": 0.5,
+				"exact": true,
+				"sort":  "top",
+			}))
+			Expect(tools.StringArg(args, "query", "")).To(Equal("$LAFFY"))
+			Expect(tools.IntArg(args, "count", 0)).To(Equal(5))
+			Expect(tools.IntArg(args, "missing", 3)).To(Equal(3))
+		})
+
+		It("treats empty arguments as an empty object", func() {
+			args, err := tools.NewSchema(map[string]tools.Property{"query": {Type: tools.TypeString}}).Validate("  ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(args).To(BeEmpty())
+
+			_, err = schema.Validate("")
+			Expect(err).To(MatchError(ContainSubstring(`missing required argument "query"`)))
+		})
+
+		DescribeTable("rejects malformed arguments",
+			func(raw, message string) {
+				_, err := schema.Validate(raw)
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("not JSON", `query=catnip`, "must be a JSON object"),
+			Entry("not an object", `["catnip"]`, "must be a JSON object"),
+			Entry("an unknown argument", `{"query": "catnip", "limit": 5}`, `unknown argument "limit"`),
+			Entry("a number for a string", `{"query": 5}`, `argument "query" must be a string`),
+			Entry("a fraction for an integer", `{"query": "catnip", "count": 2.5}`, `argument "count" must be an integer`),
+			Entry("a string for an integer", `{"query": "catnip", "count": "5"}`, `argument "count" must be an integer`),
+			Entry("a string for a number", `{"query": "catnip", "ratio": "half"}`, `argument "ratio" must be a number`),
+			Entry("a string for a boolean", `{"query": "catnip", "exact": "yes"}`, `argument "exact" must be a boolean`),
+			Entry("a value outside the enum", `{"query": "catnip", "sort": "oldest"}`, `argument "sort" must be one of recent, top`),
+			Entry("an unsupported property type", `{"query": "catnip", "broken": []}`, `unsupported schema type "array"`),
+		)
+	})
+
+	Describe("Registry", func() {
+		It("validates arguments before calling the tool", func() {
+			tool := &echoTool{}
+			registry, err := tools.NewRegistry(tool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registry.Register(&echoTool{})).To(MatchError(ContainSubstring("already registered")))
+
+			_, err = registry.Invoke(context.Background(), "echo", `{"mood": "sleepy", "text": "gm"}`)
+			Expect(err).To(MatchError(ContainSubstring("invalid arguments for echo")))
+			_, err = registry.Invoke(context.Background(), "nap", `{}`)
+			Expect(err).To(MatchError(ContainSubstring("unknown tool: nap")))
+			Expect(tool.calls).To(BeZero())
+
+			result, err := registry.Invoke(context.Background(), "echo", `{"mood": "grumpy", "text": "gm"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("grumpy: gm"))
+
+			definitions := registry.Definitions()
+			Expect(definitions).To(HaveLen(1))
+			Expect(definitions[0].Function.Name).To(Equal("echo"))
+			Expect(definitions[0].Function.Parameters).To(Equal(tool.Parameters()))
+		})
+	})
+
+	Describe("Reasoner", func() {
+		var (
+			tool     *echoTool
+			reasoner *agent.Reasoner
+			model    *toolCallingModel
+		)
+
+		BeforeEach(func() {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			tool = &echoTool{}
+			registry, err := tools.NewRegistry(tool)
+			Expect(err).NotTo(HaveOccurred())
+			model = &toolCallingModel{}
+			reasoner, err = agent.NewReasoner(agent.ReasonerConfig{LLM: model, Tools: registry, Logger: logger})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("answers every tool call it echoes and drops calls without a function", func() {
+			model.calls = []llms.ToolCall{
+				{ID: "call_1", Type: "function", FunctionCall: &llms.FunctionCall{Name: "echo", Arguments: `{"text": "gm"}`}},
+				{ID: "call_2", Type: "code_interpreter"},
+				{ID: "call_3", Type: "function", FunctionCall: &llms.FunctionCall{Name: "echo", Arguments: `{"text": 1}`}},
+			}
+
+			answer, err := reasoner.Reason(context.Background(), "say gm")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(answer).To(ContainSubstring("regal: gm"))
+			Expect(answer).To(ContainSubstring(`error: invalid arguments for echo`))
+			Expect(tool.calls).To(Equal(1))
+
+			Expect(model.requests).To(HaveLen(2))
+			var echoed, answered []string
+			for _, message := range model.requests[1] {
+				for _, part := range message.Parts {
+					switch part := part.(type) {
+					case llms.ToolCall:
+						echoed = append(echoed, part.ID)
+					case llms.ToolCallResponse:
+						answered = append(answered, part.ToolCallID)
+					}
+				}
+			}
+			Expect(echoed).To(Equal([]string{"call_1", "call_3"}))
+			Expect(answered).To(Equal(echoed))
+		})
+
+		It("takes the content as the answer when no call names a function", func() {
+			model.calls = []llms.ToolCall{{ID: "call_1", Type: "code_interpreter"}}
+
+			_, err := reasoner.Reason(context.Background(), "say gm")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(model.requests).To(HaveLen(1))
+			Expect(tool.calls).To(BeZero())
+		})
+	})
+})
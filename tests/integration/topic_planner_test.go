@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/actions"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/thoughts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Topic planner", func() {
+	var (
+		logger *logrus.Logger
+		store  *memory.TweetStore
+		ctx    context.Context
+		cancel context.CancelFunc
+		clock  time.Time
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		database := newTestDatabase(logger)
+
+		var err error
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		// The last Wednesday, which has no recurring segment
+		now := time.Now()
+		clock = now.AddDate(0, 0, -int((now.Weekday()-time.Wednesday+7)%7))
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	newPlanner := func() *actions.TopicPlanner {
+		return actions.NewTopicPlanner(store, logger, actions.TopicPlannerOptions{
+			Tokens: []string{"btc"},
+			Now:    func() time.Time { return clock },
+		})
+	}
+
+	// post plans the next topic and records it as posted an hour later
+	post := func(planner *actions.TopicPlanner) actions.PlannedTopic {
+		topic, err := planner.Next(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(planner.Posted(ctx, topic, &twitter.Tweet{ID: "tweet", Text: "a thought about " + topic.Topic})).To(Succeed())
+		clock = clock.Add(time.Hour)
+		return topic
+	}
+
+	It("rotates through the calendar without repeating topics", func() {
+		tweet := twitter.Tweet{ID: "1", Text: "@CatLordLaffy #gm #GM #wagmi", ConversationID: "1", AuthorID: "7"}
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		tweet = twitter.Tweet{ID: "2", Text: "@CatLordLaffy #gm", ConversationID: "2", AuthorID: "8"}
+		Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Other", "other")).To(Succeed())
+
+		planner := newPlanner()
+		Expect(post(planner)).To(Equal(actions.PlannedTopic{Category: actions.TopicCategoryDrama, Topic: "exchange implosions"}))
+		Expect(post(planner)).To(Equal(actions.PlannedTopic{Category: actions.TopicCategoryTrend, Topic: "the #gm trend"}))
+		Expect(post(planner)).To(Equal(actions.PlannedTopic{Category: actions.TopicCategoryToken, Topic: "today's $BTC price action"}))
+		Expect(post(planner)).To(Equal(actions.PlannedTopic{Category: actions.TopicCategoryDrama, Topic: "founder escapades"}))
+		Expect(post(planner)).To(Equal(actions.PlannedTopic{Category: actions.TopicCategoryTrend, Topic: "the #wagmi trend"}))
+
+		// A week later the rotation moves on to topics that are fair game again
+		clock = clock.AddDate(0, 0, 7)
+		Expect(post(planner).Topic).To(Equal("today's $BTC price action"))
+		Expect(post(planner).Topic).To(Equal("exchange implosions"))
+	})
+
+	It("runs recurring segments once on their day", func() {
+		clock = clock.AddDate(0, 0, 3)
+		Expect(clock.Weekday()).To(Equal(time.Saturday))
+
+		planner := newPlanner()
+		Expect(post(planner).Category).To(Equal(actions.TopicCategorySegment))
+		Expect(post(planner)).To(Equal(actions.PlannedTopic{Category: actions.TopicCategoryDrama, Topic: "exchange implosions"}))
+	})
+})
+
+var _ = Describe("Thought similarity", func() {
+	It("scores rephrasings of a tweet as near-duplicates", func() {
+		earlier := []string{
+			"Exchange imploded again? We told you peasants to keep your $LAFFY in the royal litter box #CatLordSupremacy",
+			"Monday Market Meowdown: BTC naps, ETH stretches, SOL knocks things off the table",
+		}
+
+		closest, similarity := thoughts.MostSimilar("exchange imploded AGAIN?? we told you peasants to keep your $LAFFY in the royal litter box https://t.co/x", earlier)
+		Expect(closest).To(Equal(earlier[0]))
+		Expect(similarity).To(BeNumerically(">=", thoughts.DefaultSimilarityThreshold))
+
+		_, similarity = thoughts.MostSimilar("Founders on yachts again while the network is down. Purring in public, plotting your downfall", earlier)
+		Expect(similarity).To(BeNumerically("<", thoughts.DefaultSimilarityThreshold))
+
+		Expect(thoughts.Similarity("gm", "gm")).To(BeZero(), "too short to compare")
+		Expect(thoughts.Similarity("same words here", "Same words, here!")).To(Equal(1.0))
+	})
+})
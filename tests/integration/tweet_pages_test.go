@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Tweet pages", func() {
+	var (
+		logger   *logrus.Logger
+		database *gorm.DB
+		store    *memory.TweetStore
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		database = newTestDatabase(logger)
+		var err error
+
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx = context.Background()
+		for i := 0; i < 5; i++ {
+			tweet := twitter.Tweet{ID: fmt.Sprint(100 + i), Text: "@mockbot gm", AuthorID: "7", ConversationID: "100"}
+			Expect(store.SaveTweet(ctx, tweet, memory.CategoryMention, "Fan", "fan")).To(Succeed())
+		}
+		other := twitter.Tweet{ID: "200", Text: "unrelated", AuthorID: "8", ConversationID: "200"}
+		Expect(store.SaveTweet(ctx, other, memory.CategoryConversation, "Other", "other")).To(Succeed())
+	})
+
+	// ids lists the IDs of the tweets of a page
+	ids := func(page *memory.TweetPage) []string {
+		ids := make([]string, 0, len(page.Tweets))
+		for _, tweet := range page.Tweets {
+			ids = append(ids, tweet.ID)
+		}
+		return ids
+	}
+
+	It("pages through a category by cursor in either order", func() {
+		var seen []string
+		options := memory.TweetPageOptions{Limit: 2}
+		for pages := 0; ; pages++ {
+			Expect(pages).To(BeNumerically("<", 3))
+			page, err := store.GetTweetsByCategory(ctx, memory.CategoryMention, options)
+			Expect(err).NotTo(HaveOccurred())
+			seen = append(seen, ids(page)...)
+			if page.Next == "" {
+				break
+			}
+			options.After = page.Next
+		}
+		Expect(seen).To(Equal([]string{"100", "101", "102", "103", "104"}))
+
+		page, err := store.GetTweetsByCategory(ctx, memory.CategoryMention, memory.TweetPageOptions{Limit: 3, Order: memory.NewestFirst})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(page)).To(Equal([]string{"104", "103", "102"}))
+		page, err = store.GetTweetsByCategory(ctx, memory.CategoryMention, memory.TweetPageOptions{Limit: 3, Order: memory.NewestFirst, After: page.Next})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(page)).To(Equal([]string{"101", "100"}))
+		Expect(page.Next).To(BeEmpty())
+
+		_, err = store.GetTweetsByCategory(ctx, memory.CategoryMention, memory.TweetPageOptions{After: "999"})
+		Expect(err).To(MatchError(memory.ErrInvalidCursor))
+	})
+
+	It("keeps to the time range", func() {
+		twoDaysAgo := time.Now().Add(-48 * time.Hour)
+		Expect(database.Table("tweets").Where("id IN ?", []string{"100", "101"}).Update("created_at", twoDaysAgo).Error).To(Succeed())
+		dayAgo := time.Now().Add(-24 * time.Hour)
+
+		page, err := store.GetConversation(ctx, "100", memory.TweetPageOptions{Since: dayAgo})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(page)).To(Equal([]string{"102", "103", "104"}))
+
+		page, err = store.GetConversation(ctx, "100", memory.TweetPageOptions{Until: dayAgo})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(page)).To(Equal([]string{"100", "101"}))
+
+		_, err = store.GetConversation(ctx, "100", memory.TweetPageOptions{Since: dayAgo, Until: twoDaysAgo})
+		Expect(err).To(MatchError(ContainSubstring("until must be after since")))
+	})
+
+	It("serves the pages on the admin API", func() {
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleTweets(map[string]admin.TweetBrowser{"catlord": store})
+		get := func(path string) (int, memory.TweetPage) {
+			response := httptest.NewRecorder()
+			adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+			var page memory.TweetPage
+			if response.Code == http.StatusOK {
+				Expect(json.Unmarshal(response.Body.Bytes(), &page)).To(Succeed())
+			}
+			return response.Code, page
+		}
+
+		code, page := get("/conversations/100/tweets?limit=4&order=desc")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(ids(&page)).To(Equal([]string{"104", "103", "102", "101"}))
+		Expect(page.Next).To(Equal("101"))
+
+		code, page = get("/conversations/100/tweets?limit=4&order=desc&after=101")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(ids(&page)).To(Equal([]string{"100"}))
+
+		code, page = get("/tweets?category=conversation")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(ids(&page)).To(Equal([]string{"200"}))
+
+		for _, path := range []string{
+			"/tweets",
+			"/tweets?category=mention&order=sideways",
+			"/tweets?category=mention&limit=-1",
+			"/tweets?category=mention&since=yesterday",
+			"/tweets?category=mention&after=999",
+		} {
+			code, _ = get(path)
+			Expect(code).To(Equal(http.StatusBadRequest), path)
+		}
+	})
+})
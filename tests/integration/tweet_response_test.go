@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// roundTrip decodes body into T, encodes it again and decodes the result,
+// returning both decodings
+func roundTrip[T any](body string) (T, T) {
+	var first, second T
+	Expect(json.Unmarshal([]byte(body), &first)).To(Succeed())
+	encoded, err := json.Marshal(first)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(json.Unmarshal(encoded, &second)).To(Succeed())
+	return first, second
+}
+
+var _ = Describe("Tweet responses", func() {
+	Context("for a single tweet", func() {
+		const body = `{
+			"data": {
+				"id": "20",
+				"text": "@mockbot gm",
+				"author_id": "7",
+				"conversation_id": "10",
+				"in_reply_to_user_id": "1000",
+				"referenced_tweets": [{"type": "replied_to", "id": "10"}]
+			},
+			"includes": {
+				"users": [{"id": "7", "name": "Fan", "username": "fan"}],
+				"tweets": [{"id": "10", "text": "thread", "author_id": "1000"}]
+			}
+		}`
+
+		It("survives a JSON round trip", func() {
+			first, second := roundTrip[twitter.TweetResponse](body)
+			Expect(second).To(Equal(first))
+
+			tweet, err := second.UnmarshalTweet()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweet.ID).To(Equal("20"))
+			Expect(tweet.ConversationID).To(Equal("10"))
+			Expect(tweet.ReferencedTweets).To(ConsistOf(twitter.ReferencedTweet{Type: "replied_to", ID: "10"}))
+		})
+
+		It("exposes the tweet as a list and its includes by ID", func() {
+			response, _ := roundTrip[twitter.TweetResponse](body)
+
+			tweets, err := response.UnmarshalTweets()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweets).To(HaveLen(1))
+			Expect(tweets[0].ID).To(Equal("20"))
+
+			Expect(response.Includes.User("7")).To(HaveField("Username", "fan"))
+			Expect(response.Includes.Tweet("10")).To(HaveField("AuthorID", "1000"))
+			Expect(response.Includes.User("8")).To(BeNil())
+		})
+
+		It("reports the API error when there is no tweet", func() {
+			response, second := roundTrip[twitter.TweetResponse](`{
+				"errors": [{"code": 144, "message": "No status found with that ID."}]
+			}`)
+			Expect(second).To(Equal(response))
+			Expect(response.Data).To(BeNil())
+
+			_, err := response.UnmarshalTweet()
+			var apiErr *twitter.TwitterError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.Code).To(Equal(144))
+		})
+	})
+
+	Context("for a list of tweets", func() {
+		const body = `{
+			"data": [
+				{"id": "21", "text": "@mockbot one", "author_id": "7", "conversation_id": "21"},
+				{"id": "22", "text": "@mockbot two", "author_id": "8", "conversation_id": "21"}
+			],
+			"includes": {"users": [{"id": "7", "name": "Fan", "username": "fan"}]},
+			"meta": {"result_count": 2, "newest_id": "22", "oldest_id": "21", "next_token": "abc"}
+		}`
+
+		It("survives a JSON round trip", func() {
+			first, second := roundTrip[twitter.TweetsResponse](body)
+			Expect(second).To(Equal(first))
+
+			tweets, err := second.UnmarshalTweets()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweets).To(HaveLen(2))
+			Expect(second.Meta.NextToken).To(Equal("abc"))
+			Expect(second.GetConversationID()).To(Equal("21"))
+		})
+
+		It("decodes mention and conversation pages the same way", func() {
+			var mentions twitter.MentionResponse
+			Expect(json.Unmarshal([]byte(body), &mentions)).To(Succeed())
+			var conversation twitter.ConversationResponse
+			Expect(json.Unmarshal([]byte(body), &conversation)).To(Succeed())
+			Expect(conversation).To(Equal(mentions))
+
+			tweet, err := mentions.UnmarshalTweet()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweet.ID).To(Equal("21"))
+			Expect(mentions.Includes.User(tweet.AuthorID)).To(HaveField("Name", "Fan"))
+		})
+
+		It("treats a page without data as empty", func() {
+			first, second := roundTrip[twitter.TweetsResponse](`{"meta": {"result_count": 0}}`)
+			Expect(second).To(Equal(first))
+
+			tweets, err := second.UnmarshalTweets()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweets).To(BeEmpty())
+
+			_, err = second.UnmarshalTweet()
+			Expect(err).To(HaveOccurred())
+			Expect(second.GetConversationID()).To(BeEmpty())
+		})
+	})
+})
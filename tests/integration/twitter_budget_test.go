@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Twitter request budget", func() {
+	const window = 300 * time.Millisecond
+
+	var (
+		server *twittermock.Server
+		budget *twitter.Budget
+		client *twitter.TwitterClient
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+		server.AddTweet(twitter.Tweet{Text: "cats rule", AuthorID: "1000"})
+
+		budget = twitter.NewBudget(10, window, map[string]float64{
+			twitter.BudgetReplies: 0.6,
+			twitter.BudgetReads:   0.2,
+		})
+		var err error
+		client, err = twitter.NewTwitterClient(server.Config(logger), twitter.WithBudget(budget))
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	read := func(ctx context.Context) error {
+		_, err := client.GetUserTimeline(ctx, "1000", twitter.GetUserTimelineParams{})
+		return err
+	}
+
+	It("makes a share that is used up wait for the next window while the others carry on", func() {
+		reads := twitter.WithBudgetShare(ctx, twitter.BudgetReads)
+		start := time.Now()
+		Expect(read(reads)).To(Succeed())
+		Expect(read(reads)).To(Succeed())
+
+		// Replies and requests without a share are not held up by reads
+		Expect(read(twitter.WithBudgetShare(ctx, twitter.BudgetReplies))).To(Succeed())
+		Expect(read(ctx)).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically("<", window))
+
+		usage := budget.Usage()
+		Expect(usage).To(HaveLen(2))
+		Expect(usage[0].Share).To(Equal("reads"))
+		Expect(usage[0].Limit).To(Equal(2))
+		Expect(usage[0].Used).To(Equal(2))
+		Expect(usage[1].Share).To(Equal("replies"))
+		Expect(usage[1].Limit).To(Equal(6))
+		Expect(usage[1].Used).To(Equal(1))
+
+		Expect(read(reads)).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", window))
+		Expect(budget.Usage()[0].Used).To(Equal(1))
+	})
+
+	It("gives up waiting when the action is stopped", func() {
+		reads, cancel := context.WithTimeout(twitter.WithBudgetShare(ctx, twitter.BudgetReads), window/3)
+		defer cancel()
+		Expect(read(reads)).To(Succeed())
+		Expect(read(reads)).To(Succeed())
+		Expect(read(reads)).To(MatchError(ContainSubstring("budget share reads used up")))
+	})
+})
@@ -0,0 +1,410 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// collect drains a streaming client call into its responses and last error
+func collect[T any](dataChan <-chan T, errChan <-chan error) ([]T, error) {
+	var responses []T
+	var err error
+	for dataChan != nil || errChan != nil {
+		select {
+		case data, ok := <-dataChan:
+			if !ok {
+				dataChan = nil
+				continue
+			}
+			responses = append(responses, data)
+		case e, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			err = e
+		}
+	}
+	return responses, err
+}
+
+// fetchMentions drains a GetUserMentions call into its response and error
+func fetchMentions(ctx context.Context, client *twitter.TwitterClient, params twitter.GetUserMentionsParams) (*twitter.MentionResponse, error) {
+	responses, err := collect(client.GetUserMentions(ctx, params))
+	if len(responses) == 0 {
+		return nil, err
+	}
+	return responses[len(responses)-1], err
+}
+
+// lastRequest returns the last request the server received for path
+func lastRequest(server *twittermock.Server, path string) twittermock.Request {
+	var found twittermock.Request
+	for _, request := range server.Requests() {
+		if request.Path == path {
+			found = request
+		}
+	}
+	Expect(found.Path).To(Equal(path), "no request for %s", path)
+	return found
+}
+
+// countRequests returns how many requests the server received for method and path
+func countRequests(server *twittermock.Server, method, path string) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if request.Method == method && request.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+var _ = Describe("Twitter client against the mock API", func() {
+	var (
+		server *twittermock.Server
+		client *twitter.TwitterClient
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		server = twittermock.NewServer()
+
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		var err error
+		client, err = twitter.NewTwitterClient(server.Config(logger))
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("resolves the authenticated user", func() {
+		server.SetAuthenticatedUser(twitter.User{ID: "42", Name: "Cat Lord", Username: "catlord"})
+
+		userID, err := client.GetAuthenticatedUserID(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(userID).To(Equal("42"))
+	})
+
+	It("returns mentions newest first with their authors", func() {
+		server.AddUser(twitter.User{ID: "7", Name: "Fan", Username: "fan"})
+		first := server.AddMention("1000", twitter.Tweet{Text: "@mockbot hello", AuthorID: "7"})
+		second := server.AddMention("1000", twitter.Tweet{Text: "@mockbot again", AuthorID: "7"})
+
+		resp, err := fetchMentions(ctx, client, twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).NotTo(BeNil())
+
+		tweets, err := resp.UnmarshalTweets()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tweets).To(HaveLen(2))
+		Expect(tweets[0].ID).To(Equal(second.ID))
+		Expect(tweets[1].ID).To(Equal(first.ID))
+		Expect(resp.Includes).NotTo(BeNil())
+		Expect(resp.Includes.Users).To(ContainElement(HaveField("Username", "fan")))
+	})
+
+	It("posts replies into the conversation and deletes them", func() {
+		mention := server.AddMention("1000", twitter.Tweet{Text: "@mockbot gm", AuthorID: "7"})
+
+		reply, err := client.PostReplyThread(ctx, twitter.PostReplyThreadParams{
+			Text:           "gm fren",
+			ReplyToID:      mention.ID,
+			ConversationID: mention.ConversationID,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reply.ID).NotTo(BeEmpty())
+
+		posted := server.Posted()
+		Expect(posted).To(HaveLen(1))
+		Expect(posted[0].Text).To(Equal("gm fren"))
+		Expect(posted[0].ConversationID).To(Equal(mention.ConversationID))
+		Expect(posted[0].ReferencedTweets).To(HaveLen(1))
+		Expect(posted[0].ReferencedTweets[0].ID).To(Equal(mention.ID))
+
+		deleted, err := client.DeleteTweet(ctx, reply.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeTrue())
+		Expect(server.Deleted()).To(ConsistOf(reply.ID))
+	})
+
+	It("reports scripted rate limits with their reset time", func() {
+		reset := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+		server.RateLimit(twittermock.RateLimit{Path: "/users/1000/mentions", Times: 1, Reset: reset})
+
+		_, err := fetchMentions(ctx, client, twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10})
+		var rateLimitErr *twitter.RateLimitError
+		Expect(errors.As(err, &rateLimitErr)).To(BeTrue(), "expected a rate limit error, got %v", err)
+		Expect(rateLimitErr.Reset.Unix()).To(Equal(reset.Unix()))
+
+		// The scenario only rejects one request
+		_, err = fetchMentions(ctx, client, twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("transient failures", func() {
+		BeforeEach(func() {
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+			cfg := server.Config(logger)
+			cfg.RetryAttempts = 2
+			cfg.RetryBackoff = time.Millisecond
+
+			var err error
+			client, err = twitter.NewTwitterClient(cfg)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("retries reads after server errors and reset connections", func() {
+			server.Fail(twittermock.Failure{Path: "/users/1000/mentions", Status: http.StatusBadGateway, Times: 1})
+			server.Fail(twittermock.Failure{Path: "/users/1000/mentions", Times: 1})
+
+			_, err := fetchMentions(ctx, client, twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countRequests(server, http.MethodGet, "/users/1000/mentions")).To(Equal(3))
+		})
+
+		It("gives up once the attempts are used", func() {
+			server.Fail(twittermock.Failure{Path: "/users/1000/mentions", Status: http.StatusServiceUnavailable})
+
+			_, err := fetchMentions(ctx, client, twitter.GetUserMentionsParams{UserID: "1000", MaxResults: 10})
+			Expect(err).To(HaveOccurred())
+			Expect(countRequests(server, http.MethodGet, "/users/1000/mentions")).To(Equal(3))
+		})
+
+		It("only retries posts the API did not accept", func() {
+			server.Fail(twittermock.Failure{Method: http.MethodPost, Path: "/tweets", Status: http.StatusInternalServerError, Times: 1})
+			_, err := client.PostTweet(ctx, "gm", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(countRequests(server, http.MethodPost, "/tweets")).To(Equal(1))
+
+			server.Fail(twittermock.Failure{Method: http.MethodPost, Path: "/tweets", Status: http.StatusServiceUnavailable, Times: 1})
+			tweet, err := client.PostTweet(ctx, "gm again", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweet.Text).To(Equal("gm again"))
+			Expect(countRequests(server, http.MethodPost, "/tweets")).To(Equal(3))
+			Expect(server.Posted()).To(HaveLen(1))
+		})
+	})
+
+	Describe("tweet lookups", func() {
+		var author twitter.User
+
+		BeforeEach(func() {
+			author = twitter.User{ID: "7", Name: "Fan", Username: "fan"}
+			server.AddUser(author)
+		})
+
+		It("sends the fields and expansions of a single tweet as a query string", func() {
+			root := server.AddTweet(twitter.Tweet{Text: "gm", AuthorID: author.ID})
+			reply := server.AddTweet(twitter.Tweet{
+				Text:             "gm back",
+				AuthorID:         "1000",
+				ConversationID:   root.ConversationID,
+				InReplyToUserID:  author.ID,
+				ReferencedTweets: []twitter.ReferencedTweet{{Type: "replied_to", ID: root.ID}},
+			})
+
+			responses, err := collect(client.GetTweetByID(ctx, twitter.GetTweetByIDParams{TweetID: reply.ID}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(1))
+
+			request := lastRequest(server, "/tweets/"+reply.ID)
+			Expect(request.Body).To(BeNil())
+			Expect(strings.Split(request.Query["tweet.fields"], ",")).To(ContainElements("conversation_id", "in_reply_to_user_id", "referenced_tweets"))
+			Expect(strings.Split(request.Query["expansions"], ",")).To(ContainElements("author_id", "referenced_tweets.id"))
+
+			tweet, err := responses[0].UnmarshalTweet()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweet.ConversationID).To(Equal(root.ConversationID))
+			Expect(tweet.InReplyToUserID).To(Equal(author.ID))
+			Expect(tweet.ReferencedTweets).To(HaveLen(1))
+			Expect(responses[0].Includes).NotTo(BeNil())
+			Expect(responses[0].Includes.Tweets).To(ContainElement(HaveField("ID", root.ID)))
+		})
+
+		It("lists each field once", func() {
+			tweet := server.AddTweet(twitter.Tweet{Text: "gm", AuthorID: author.ID})
+
+			_, err := collect(client.GetTweetByID(ctx, twitter.GetTweetByIDParams{TweetID: tweet.ID}))
+			Expect(err).NotTo(HaveOccurred())
+
+			request := lastRequest(server, "/tweets/"+tweet.ID)
+			for _, key := range []string{"tweet.fields", "expansions"} {
+				values := strings.Split(request.Query[key], ",")
+				seen := make(map[string]bool)
+				for _, value := range values {
+					Expect(seen).NotTo(HaveKey(value), "%s repeats %s", key, value)
+					seen[value] = true
+				}
+			}
+		})
+
+		It("looks up several tweets with their authors", func() {
+			first := server.AddTweet(twitter.Tweet{Text: "one", AuthorID: author.ID})
+			second := server.AddTweet(twitter.Tweet{Text: "two", AuthorID: author.ID})
+
+			responses, err := collect(client.GetTweets(ctx, twitter.GetTweetsParams{TweetIDs: []string{first.ID, second.ID}}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(1))
+
+			request := lastRequest(server, "/tweets")
+			Expect(request.Body).To(BeNil())
+			Expect(request.Query["ids"]).To(Equal(first.ID + "," + second.ID))
+			Expect(request.Query).NotTo(HaveKey("max_results"))
+
+			tweets, err := responses[0].UnmarshalTweets()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweets).To(HaveLen(2))
+			for _, tweet := range tweets {
+				Expect(tweet.AuthorID).To(Equal(author.ID))
+				Expect(tweet.ConversationID).To(Equal(tweet.ID))
+			}
+			Expect(responses[0].Includes).NotTo(BeNil())
+			Expect(responses[0].Includes.Users).To(ContainElement(HaveField("Username", "fan")))
+		})
+
+		It("searches a conversation page by page", func() {
+			root := server.AddTweet(twitter.Tweet{Text: "thread", AuthorID: author.ID})
+			for i := 0; i < 6; i++ {
+				server.AddTweet(twitter.Tweet{
+					Text:             "reply",
+					AuthorID:         author.ID,
+					ConversationID:   root.ConversationID,
+					ReferencedTweets: []twitter.ReferencedTweet{{Type: "replied_to", ID: root.ID}},
+				})
+			}
+
+			responses, err := collect(client.GetConversation(ctx, twitter.GetConversationParams{
+				ConversationID: root.ConversationID,
+				MaxResults:     5,
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(2))
+
+			var tweets []twitter.Tweet
+			for _, resp := range responses {
+				tweets = append(tweets, resp.Data...)
+			}
+			Expect(tweets).To(HaveLen(7))
+			for _, tweet := range tweets {
+				Expect(tweet.ConversationID).To(Equal(root.ConversationID))
+			}
+
+			request := lastRequest(server, "/tweets/search/recent")
+			Expect(request.Body).To(BeNil())
+			Expect(request.Query["query"]).To(Equal("conversation_id:" + root.ConversationID))
+			Expect(request.Query["max_results"]).To(Equal("5"))
+			Expect(request.Query["pagination_token"]).NotTo(BeEmpty())
+			Expect(strings.Split(request.Query["tweet.fields"], ",")).To(ContainElement("referenced_tweets"))
+		})
+
+		It("pages through a user's timeline without replies and retweets", func() {
+			root := server.AddTweet(twitter.Tweet{Text: "someone else", AuthorID: "8"})
+			for i := 0; i < 103; i++ {
+				server.AddTweet(twitter.Tweet{Text: "original", AuthorID: author.ID})
+			}
+			server.AddTweet(twitter.Tweet{
+				Text:             "reply",
+				AuthorID:         author.ID,
+				ConversationID:   root.ConversationID,
+				ReferencedTweets: []twitter.ReferencedTweet{{Type: "replied_to", ID: root.ID}},
+			})
+			server.AddTweet(twitter.Tweet{
+				Text:             "RT someone else",
+				AuthorID:         author.ID,
+				ReferencedTweets: []twitter.ReferencedTweet{{Type: "retweeted", ID: root.ID}},
+			})
+
+			tweets, err := client.GetUserTimeline(ctx, author.ID, twitter.GetUserTimelineParams{
+				MaxTweets: 150,
+				Exclude:   []string{twitter.ExcludeRetweets, twitter.ExcludeReplies},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweets).To(HaveLen(103))
+			for _, tweet := range tweets {
+				Expect(tweet.Text).To(Equal("original"))
+			}
+
+			request := lastRequest(server, "/users/"+author.ID+"/tweets")
+			Expect(request.Query["exclude"]).To(Equal("retweets,replies"))
+			Expect(request.Query["max_results"]).To(Equal("50"))
+			Expect(request.Query["pagination_token"]).NotTo(BeEmpty())
+
+			tweets, err = client.GetUserTimeline(ctx, author.ID, twitter.GetUserTimelineParams{MaxTweets: 5})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweets).To(HaveLen(5))
+			Expect(tweets[0].Text).To(Equal("RT someone else"))
+		})
+
+		It("searches recent tweets with a built query", func() {
+			server.AddUser(twitter.User{ID: "8", Name: "Other", Username: "other"})
+			root := server.AddTweet(twitter.Tweet{Text: "good morning cats", AuthorID: author.ID, Lang: "en"})
+			server.AddTweet(twitter.Tweet{Text: "gm", AuthorID: author.ID, Lang: "en"})
+			server.AddTweet(twitter.Tweet{Text: "gm", AuthorID: "8", Lang: "en"})
+			server.AddTweet(twitter.Tweet{Text: "gm", AuthorID: author.ID, Lang: "fr"})
+			server.AddTweet(twitter.Tweet{
+				Text:             "RT good morning cats",
+				AuthorID:         author.ID,
+				Lang:             "en",
+				ReferencedTweets: []twitter.ReferencedTweet{{Type: "retweeted", ID: root.ID}},
+			})
+
+			query, err := twitter.NewSearchQuery().
+				AnyOf("gm", "good morning").
+				From("@fan").
+				Lang("en").
+				ExcludeRetweets().
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal(`(gm OR "good morning") from:fan lang:en -is:retweet`))
+
+			responses, err := collect(client.SearchRecentTweets(ctx, query, twitter.SearchRecentParams{MaxResults: 10}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(1))
+			tweets, err := responses[0].UnmarshalTweets()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tweets).To(HaveLen(2))
+			for _, tweet := range tweets {
+				Expect(tweet.AuthorID).To(Equal(author.ID))
+				Expect(tweet.Lang).To(Equal("en"))
+			}
+
+			request := lastRequest(server, "/tweets/search/recent")
+			Expect(request.Query["query"]).To(Equal(query))
+		})
+	})
+
+	It("builds search queries", func() {
+		query := twitter.NewSearchQuery().
+			Keywords("cat lord", "").
+			IsReply().
+			To("mockbot").
+			Mentioning("@fan").
+			Without("spam").
+			String()
+		Expect(query).To(Equal(`"cat lord" is:reply to:mockbot @fan -spam`))
+
+		_, err := twitter.NewSearchQuery().Build()
+		Expect(err).To(HaveOccurred())
+		_, err = twitter.NewSearchQuery().Keywords(strings.Repeat("x", twitter.MaxSearchQueryLength+1)).Build()
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/tests/twittermock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Twitter OAuth 2.0 user context", func() {
+	var (
+		logger   *logrus.Logger
+		server   *twittermock.Server
+		database *gorm.DB
+		tokens   *memory.OAuth2Tokens
+		key      []byte
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+		server = twittermock.NewServer()
+		DeferCleanup(server.Close)
+
+		database = newTestDatabase(logger)
+		var err error
+
+		key = make([]byte, 32)
+		_, err = rand.Read(key)
+		Expect(err).NotTo(HaveOccurred())
+		tokens, err = memory.NewOAuth2Tokens(database, "catlord", key)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	// oauth2Config authenticates against the mock with the OAuth 2.0 client only
+	oauth2Config := func(scopes ...string) *twitter.TwitterConfig {
+		cfg := server.Config(logger)
+		cfg.ConsumerKey, cfg.ConsumerSecret = "", ""
+		cfg.AccessToken, cfg.AccessTokenSecret = "", ""
+		cfg.BearerToken = ""
+		cfg.OAuth2 = twitter.OAuth2Settings{
+			ClientID:    "client-id",
+			RedirectURL: "http://127.0.0.1:8765/callback",
+			Scopes:      scopes,
+		}
+		return cfg
+	}
+
+	// login runs the code exchange the login command performs after the redirect
+	login := func(cfg *twitter.TwitterConfig, granted ...string) (twitter.OAuth2Token, error) {
+		Expect(cfg.Validate()).To(Succeed())
+		pkce, err := twitter.NewPKCE()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.OAuth2.AuthCodeURL("state", pkce)).To(ContainSubstring("code_challenge=" + pkce.Challenge))
+
+		code := server.AuthorizeOAuth2(pkce.Challenge, granted...)
+		return twitter.ExchangeOAuth2Code(ctx, http.DefaultClient, cfg.OAuth2, code, pkce)
+	}
+
+	It("stores the token encrypted and refreshes it before it expires", func() {
+		cfg := oauth2Config()
+		token, err := login(cfg, twitter.DefaultOAuth2Scopes...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token.RefreshToken).NotTo(BeEmpty())
+
+		// Close to expiry, so the first request refreshes it
+		token.Expiry = time.Now().Add(time.Minute)
+		Expect(tokens.SaveOAuth2Token(ctx, token)).To(Succeed())
+
+		var row memory.OAuth2TokenRow
+		Expect(database.First(&row, "account = ?", "catlord").Error).To(Succeed())
+		Expect(row.AccessToken).NotTo(ContainSubstring(token.AccessToken))
+		Expect(row.RefreshToken).NotTo(ContainSubstring(token.RefreshToken))
+
+		client, err := twitter.NewTwitterClient(cfg, twitter.WithOAuth2Tokens(tokens))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetUserTimeline(ctx, "1000", twitter.GetUserTimelineParams{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetUserTimeline(ctx, "1000", twitter.GetUserTimelineParams{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.OAuth2Refreshes()).To(Equal(1))
+
+		stored, err := tokens.LoadOAuth2Token(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.AccessToken).NotTo(Equal(token.AccessToken))
+		Expect(stored.RefreshToken).NotTo(Equal(token.RefreshToken))
+		Expect(stored.Expiry).To(BeTemporally(">", time.Now().Add(time.Hour)))
+
+		var timeline []string
+		for _, request := range server.Requests() {
+			if strings.HasSuffix(request.Path, "/tweets") {
+				timeline = append(timeline, request.Authorization)
+			}
+		}
+		Expect(timeline).To(Equal([]string{"Bearer " + stored.AccessToken, "Bearer " + stored.AccessToken}))
+
+		// Another key cannot read the tokens
+		other := append([]byte(nil), key...)
+		other[0] ^= 0xff
+		wrongKey, err := memory.NewOAuth2Tokens(database, "catlord", other)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = wrongKey.LoadOAuth2Token(ctx)
+		Expect(err).To(MatchError(ContainSubstring("failed to decrypt stored token")))
+	})
+
+	It("rejects tokens without the configured scopes", func() {
+		cfg := oauth2Config("tweet.read", "users.read", twitter.ScopeOfflineAccess)
+		_, err := login(cfg, "tweet.read", twitter.ScopeOfflineAccess)
+		Expect(err).To(MatchError(twitter.ErrOAuth2Scopes))
+		Expect(err).To(MatchError(ContainSubstring("users.read")))
+
+		token, err := login(cfg, "tweet.read", "users.read", twitter.ScopeOfflineAccess)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tokens.SaveOAuth2Token(ctx, token)).To(Succeed())
+
+		// Bookmarks need a scope the account did not grant
+		client, err := twitter.NewTwitterClient(cfg, twitter.WithOAuth2Tokens(tokens))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.AddBookmark(ctx, "42")).To(MatchError(twitter.ErrOAuth2Scopes))
+		Expect(server.Bookmarks()).To(BeEmpty())
+	})
+
+	It("fails requests until the account has authorized the app", func() {
+		client, err := twitter.NewTwitterClient(oauth2Config(), twitter.WithOAuth2Tokens(tokens))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetUserTimeline(ctx, "1000", twitter.GetUserTimelineParams{})
+		Expect(err).To(MatchError(twitter.ErrOAuth2NotAuthorized))
+
+		_, err = twitter.NewTwitterClient(oauth2Config())
+		Expect(err).To(MatchError(ContainSubstring("OAuth 2.0 requires a token store")))
+	})
+})
@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Wallet airdrop", func() {
+	var (
+		logger *logrus.Logger
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 2*time.Minute)
+		DeferCleanup(cancel)
+	})
+
+	recipients := func(amounts ...int64) []wallet.AirdropRecipient {
+		list := make([]wallet.AirdropRecipient, len(amounts))
+		for i, amount := range amounts {
+			list[i] = wallet.AirdropRecipient{
+				Address: common.BigToAddress(big.NewInt(int64(0xa11ce0 + i))),
+				Amount:  big.NewInt(amount),
+			}
+		}
+		return list
+	}
+
+	It("approves the disperse contract and sends every batch", func() {
+		chain := newTestChain(logger, big.NewInt(1000), nil)
+		airdrop := recipients(100, 200, 300)
+
+		result, err := chain.client.BatchTransferERC20(ctx, devnet, testTokenAddress, airdrop, &wallet.BatchTransferOptions{BatchSize: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Total.Int64()).To(Equal(int64(600)))
+		Expect(result.ApprovalNeeded).To(BeTrue())
+		Expect(result.Approval).NotTo(BeNil())
+		Expect(result.Approval.Status).To(Equal(types.ReceiptStatusSuccessful))
+		Expect(result.Failed()).To(BeEmpty())
+
+		Expect(result.Batches).To(HaveLen(2))
+		Expect(result.Batches[0].Recipients).To(Equal(airdrop[:2]))
+		Expect(result.Batches[0].Total.Int64()).To(Equal(int64(300)))
+		Expect(result.Batches[1].Recipients).To(Equal(airdrop[2:]))
+		for _, batch := range result.Batches {
+			Expect(batch.Approximate).To(BeFalse())
+			Expect(batch.GasEstimate).To(BeNumerically(">", 21000))
+			Expect(batch.Status).NotTo(BeNil())
+			Expect(batch.Status.Status).To(Equal(types.ReceiptStatusSuccessful))
+		}
+
+		for _, recipient := range airdrop {
+			Expect(chain.TokenBalance(ctx, recipient.Address)).To(Equal(recipient.Amount))
+		}
+		Expect(chain.TokenBalance(ctx, chain.Owner()).Int64()).To(Equal(int64(400)))
+	})
+
+	It("sends nothing when the balance does not cover the total", func() {
+		chain := newTestChain(logger, big.NewInt(500), nil)
+
+		result, err := chain.client.BatchTransferERC20(ctx, devnet, testTokenAddress, recipients(100, 200, 300), nil)
+		Expect(err).To(HaveOccurred())
+		Expect(wallet.IsWalletError(err, wallet.ErrCodeInsufficientFunds)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("token balance 500 is below the airdrop total 600"))
+		Expect(result).To(BeNil())
+
+		nonce, err := chain.backend.Client().NonceAt(ctx, chain.Owner(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nonce).To(BeZero())
+		Expect(chain.TokenBalance(ctx, chain.Owner()).Int64()).To(Equal(int64(500)))
+	})
+
+	It("reports a failed batch with its recipients and still sends the others", func() {
+		chain := newTestChain(logger, big.NewInt(1000), big.NewInt(1000))
+		airdrop := recipients(100, 200, 300)
+		airdrop[1].Address = blockedAddress
+
+		result, err := chain.client.BatchTransferERC20(ctx, devnet, testTokenAddress, airdrop, &wallet.BatchTransferOptions{BatchSize: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.ApprovalNeeded).To(BeFalse())
+		Expect(result.Approval).To(BeNil())
+
+		Expect(result.Batches).To(HaveLen(3))
+		Expect(result.Failed()).To(HaveLen(1))
+		failed := result.Failed()[0]
+		Expect(failed.Index).To(Equal(1))
+		Expect(wallet.IsWalletError(failed.Err, wallet.ErrCodeContractError)).To(BeTrue())
+		Expect(failed.Status).To(BeNil())
+		Expect(result.FailedRecipients()).To(Equal(airdrop[1:2]))
+
+		Expect(result.Batches[0].Status.Status).To(Equal(types.ReceiptStatusSuccessful))
+		Expect(result.Batches[2].Status.Status).To(Equal(types.ReceiptStatusSuccessful))
+		Expect(chain.TokenBalance(ctx, airdrop[0].Address).Int64()).To(Equal(int64(100)))
+		Expect(chain.TokenBalance(ctx, blockedAddress).Int64()).To(BeZero())
+		Expect(chain.TokenBalance(ctx, airdrop[2].Address).Int64()).To(Equal(int64(300)))
+		Expect(chain.TokenBalance(ctx, chain.Owner()).Int64()).To(Equal(int64(600)))
+	})
+})
@@ -0,0 +1,357 @@
+package integration
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// devnet is the network the wallet knows the simulated chain by
+const devnet wallet.NetworkType = "DEVNET"
+
+var (
+	// testTokenAddress holds the ERC20 token deployed on every simulated chain
+	testTokenAddress = common.HexToAddress("0x000000000000000000000000000000000000701c")
+
+	// testDisperseAddress holds the disperse contract deployed on every simulated chain
+	testDisperseAddress = common.HexToAddress("0x000000000000000000000000000000000000d15e")
+
+	// blockedAddress is refused by the token, like a stablecoin's blocklist
+	blockedAddress = common.HexToAddress("0x00000000000000000000000000000000000b10cc")
+)
+
+// evmProgram assembles EVM bytecode, resolving jump labels
+type evmProgram struct {
+	code   []byte
+	labels map[string]int
+	jumps  map[int]string
+}
+
+func newEVMProgram() *evmProgram {
+	return &evmProgram{labels: make(map[string]int), jumps: make(map[int]string)}
+}
+
+func (p *evmProgram) op(ops ...vm.OpCode) *evmProgram {
+	for _, op := range ops {
+		p.code = append(p.code, byte(op))
+	}
+	return p
+}
+
+// push pushes value with the shortest PUSH that holds it
+func (p *evmProgram) push(value *big.Int) *evmProgram {
+	data := value.Bytes()
+	if len(data) == 0 {
+		data = []byte{0}
+	}
+	p.code = append(p.code, byte(vm.PUSH1)+byte(len(data)-1))
+	p.code = append(p.code, data...)
+	return p
+}
+
+func (p *evmProgram) pushInt(value int64) *evmProgram {
+	return p.push(big.NewInt(value))
+}
+
+func (p *evmProgram) pushAddress(address common.Address) *evmProgram {
+	return p.push(new(big.Int).SetBytes(address.Bytes()))
+}
+
+// arg pushes the 32-byte calldata word at offset
+func (p *evmProgram) arg(offset int64) *evmProgram {
+	return p.pushInt(offset).op(vm.CALLDATALOAD)
+}
+
+func (p *evmProgram) label(name string) *evmProgram {
+	p.labels[name] = len(p.code)
+	return p.op(vm.JUMPDEST)
+}
+
+func (p *evmProgram) pushLabel(name string) *evmProgram {
+	p.jumps[len(p.code)+1] = name
+	p.code = append(p.code, byte(vm.PUSH2), 0, 0)
+	return p
+}
+
+func (p *evmProgram) jump(name string) *evmProgram {
+	return p.pushLabel(name).op(vm.JUMP)
+}
+
+// jumpIf jumps when the top of the stack is non-zero
+func (p *evmProgram) jumpIf(name string) *evmProgram {
+	return p.pushLabel(name).op(vm.JUMPI)
+}
+
+// dispatch jumps to label when the selector on the stack is the method's
+func (p *evmProgram) dispatch(signature, name string) *evmProgram {
+	selector := crypto.Keccak256([]byte(signature))[:4]
+	return p.op(vm.DUP1).push(new(big.Int).SetBytes(selector)).op(vm.EQ).jumpIf(name)
+}
+
+// returnWord returns the top of the stack as a 32-byte word
+func (p *evmProgram) returnWord() *evmProgram {
+	return p.pushInt(0).op(vm.MSTORE).pushInt(32).pushInt(0).op(vm.RETURN)
+}
+
+func (p *evmProgram) bytes() []byte {
+	for position, name := range p.jumps {
+		target, ok := p.labels[name]
+		Expect(ok).To(BeTrue(), "undefined label %s", name)
+		p.code[position] = byte(target >> 8)
+		p.code[position+1] = byte(target)
+	}
+	return p.code
+}
+
+// testTokenCode is a minimal ERC20 token with the symbol LAFFY and 18
+// decimals. Balances are stored at the holder's address and allowances at
+// keccak256(owner, spender); transfers to blockedAddress revert.
+func testTokenCode() []byte {
+	p := newEVMProgram()
+	p.arg(0).pushInt(224).op(vm.SHR)
+	p.dispatch("symbol()", "symbol")
+	p.dispatch("decimals()", "decimals")
+	p.dispatch("balanceOf(address)", "balanceOf")
+	p.dispatch("allowance(address,address)", "allowance")
+	p.dispatch("approve(address,uint256)", "approve")
+	p.dispatch("transfer(address,uint256)", "transfer")
+	p.dispatch("transferFrom(address,address,uint256)", "transferFrom")
+	p.label("revert").pushInt(0).pushInt(0).op(vm.REVERT)
+
+	// allowanceSlot pushes the allowance slot of owner and spender
+	allowanceSlot := func(owner, spender func()) {
+		owner()
+		p.pushInt(0).op(vm.MSTORE)
+		spender()
+		p.pushInt(32).op(vm.MSTORE)
+		p.pushInt(64).pushInt(0).op(vm.KECCAK256)
+	}
+	// move transfers amount from one balance to another, reverting when the
+	// sender's balance is short or the recipient is blocked
+	move := func(from, to, amount func()) {
+		to()
+		p.pushAddress(blockedAddress).op(vm.EQ).jumpIf("revert")
+		amount()
+		from()
+		p.op(vm.SLOAD, vm.LT).jumpIf("revert")
+		amount()
+		from()
+		p.op(vm.SLOAD, vm.SUB)
+		from()
+		p.op(vm.SSTORE)
+		amount()
+		to()
+		p.op(vm.SLOAD, vm.ADD)
+		to()
+		p.op(vm.SSTORE)
+		p.pushInt(1).returnWord()
+	}
+	caller := func() { p.op(vm.CALLER) }
+	arg := func(offset int64) func() { return func() { p.arg(offset) } }
+
+	// symbol returns the ABI encoding of a string: offset, length and data
+	p.label("symbol").pushInt(32).pushInt(0).op(vm.MSTORE)
+	p.pushInt(5).pushInt(32).op(vm.MSTORE)
+	p.push(new(big.Int).SetBytes(common.RightPadBytes([]byte("LAFFY"), 32))).pushInt(64).op(vm.MSTORE)
+	p.pushInt(96).pushInt(0).op(vm.RETURN)
+
+	p.label("decimals").pushInt(18).returnWord()
+
+	p.label("balanceOf").arg(4).op(vm.SLOAD).returnWord()
+
+	p.label("allowance")
+	allowanceSlot(arg(4), arg(36))
+	p.op(vm.SLOAD).returnWord()
+
+	p.label("approve")
+	p.arg(36)
+	allowanceSlot(caller, arg(4))
+	p.op(vm.SSTORE).pushInt(1).returnWord()
+
+	p.label("transfer")
+	move(caller, arg(4), arg(36))
+
+	p.label("transferFrom")
+	allowanceSlot(arg(4), caller)
+	p.arg(68).op(vm.DUP2, vm.SLOAD, vm.LT).jumpIf("revert")
+	p.arg(68).op(vm.DUP2, vm.SLOAD, vm.SUB, vm.SWAP1, vm.SSTORE)
+	move(arg(4), arg(36), arg(68))
+
+	return p.bytes()
+}
+
+// testDisperseCode implements disperseToken(token, recipients, values) like
+// the disperse contract: every value is pulled from the caller with
+// transferFrom, and any failed transfer reverts the whole call
+func testDisperseCode() []byte {
+	transferFrom := crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4]
+
+	p := newEVMProgram()
+	p.arg(0).pushInt(224).op(vm.SHR)
+	p.dispatch("disperseToken(address,address[],uint256[])", "disperse")
+	p.label("revert").pushInt(0).pushInt(0).op(vm.REVERT)
+
+	// The stack holds the index of the next recipient
+	p.label("disperse").pushInt(0)
+	p.label("loop")
+	p.arg(36).pushInt(4).op(vm.ADD, vm.CALLDATALOAD, vm.DUP2, vm.LT, vm.ISZERO).jumpIf("done")
+
+	// transferFrom(caller, recipients[i], values[i])
+	p.push(new(big.Int).SetBytes(transferFrom)).pushInt(224).op(vm.SHL).pushInt(0).op(vm.MSTORE)
+	p.op(vm.CALLER).pushInt(4).op(vm.MSTORE)
+	p.op(vm.DUP1).pushInt(32).op(vm.MUL).arg(36).pushInt(36).op(vm.ADD, vm.ADD, vm.CALLDATALOAD).pushInt(36).op(vm.MSTORE)
+	p.op(vm.DUP1).pushInt(32).op(vm.MUL).arg(68).pushInt(36).op(vm.ADD, vm.ADD, vm.CALLDATALOAD).pushInt(68).op(vm.MSTORE)
+	p.pushInt(32).pushInt(0).pushInt(100).pushInt(0).pushInt(0).arg(4).op(vm.GAS, vm.CALL)
+	p.op(vm.ISZERO).jumpIf("revert")
+	p.pushInt(0).op(vm.MLOAD).pushInt(1).op(vm.EQ, vm.ISZERO).jumpIf("revert")
+
+	p.pushInt(1).op(vm.ADD).jump("loop")
+	p.label("done").op(vm.STOP)
+
+	return p.bytes()
+}
+
+// testChain is a simulated chain served over HTTP with the token and
+// disperse contracts deployed, and a wallet client connected to it
+type testChain struct {
+	backend *simulated.Backend
+	client  *wallet.Client
+	key     *ecdsa.PrivateKey
+	rpcURL  string
+
+	mu     sync.Mutex
+	mining bool
+}
+
+// newTestChain starts a chain on which the wallet holds ether for gas and
+// tokens token units. allowance pre-approves the disperse contract; nil
+// leaves it unapproved. Blocks are mined continuously until Pause.
+func newTestChain(logger *logrus.Logger, tokens, allowance *big.Int) *testChain {
+	key, err := crypto.GenerateKey()
+	Expect(err).NotTo(HaveOccurred())
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+
+	tokenStorage := map[common.Hash]common.Hash{
+		common.BytesToHash(owner.Bytes()): common.BigToHash(tokens),
+	}
+	if allowance != nil {
+		slot := crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.LeftPadBytes(testDisperseAddress.Bytes(), 32))
+		tokenStorage[slot] = common.BigToHash(allowance)
+	}
+
+	// Reserve a port for the node's HTTP endpoint
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	port := listener.Addr().(*net.TCPAddr).Port
+	Expect(listener.Close()).To(Succeed())
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		owner:               {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+		testTokenAddress:    {Code: testTokenCode(), Storage: tokenStorage},
+		testDisperseAddress: {Code: testDisperseCode()},
+	}, func(nodeConf *node.Config, _ *ethconfig.Config) {
+		nodeConf.HTTPHost = "127.0.0.1"
+		nodeConf.HTTPPort = port
+		nodeConf.HTTPModules = []string{"eth", "net"}
+		nodeConf.HTTPVirtualHosts = []string{"*"}
+	})
+	DeferCleanup(backend.Close)
+
+	chain := &testChain{backend: backend, key: key, rpcURL: fmt.Sprintf("http://127.0.0.1:%d", port), mining: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	DeferCleanup(cancel)
+	go chain.mine(ctx)
+
+	// The node reports transactions as being indexed, rather than unknown,
+	// until it has mined past genesis
+	Eventually(func() (uint64, error) { return backend.Client().BlockNumber(ctx) }).Should(BeNumerically(">=", 2))
+
+	chain.client = chain.NewClient(logger)
+	return chain
+}
+
+// NewClient connects another wallet client with the same key to the chain,
+// as the agent would after a restart
+func (c *testChain) NewClient(logger *logrus.Logger) *wallet.Client {
+	client, err := wallet.NewClient(context.Background(), logger, []wallet.NetworkConfig{{
+		Type:               devnet,
+		RPCURL:             c.rpcURL,
+		ChainID:            1337,
+		GasLimitMultiplier: 1.2,
+		// The node's txpool refuses tips below 1 gwei
+		MinPriorityFee:  big.NewInt(1e9),
+		DisperseAddress: testDisperseAddress,
+		ExplorerURL:     "https://explorer.devnet.test",
+	}}, common.Bytes2Hex(crypto.FromECDSA(c.key)))
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(client.Close)
+	return client
+}
+
+// mine seals a block every 50ms while mining is on
+func (c *testChain) mine(ctx context.Context) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			if c.mining {
+				c.backend.Commit()
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Pause stops mining, so sent transactions stay pending
+func (c *testChain) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mining = false
+}
+
+// Resume mines blocks again
+func (c *testChain) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mining = true
+}
+
+// DropPending removes every pending transaction from the mempool, as a node
+// evicting underpriced transactions would
+func (c *testChain) DropPending() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.backend.Rollback()
+}
+
+// Owner returns the wallet's address
+func (c *testChain) Owner() common.Address {
+	return crypto.PubkeyToAddress(c.key.PublicKey)
+}
+
+// TokenBalance returns the token balance of account
+func (c *testChain) TokenBalance(ctx context.Context, account common.Address) *big.Int {
+	balance, err := c.client.GetERC20Balance(ctx, devnet, testTokenAddress, account)
+	Expect(err).NotTo(HaveOccurred())
+	return balance
+}
@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lisanmuaddib/agent-go/pkg/events"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+var _ = Describe("Wallet monitor", func() {
+	var (
+		logger *logrus.Logger
+		hook   *test.Hook
+		chain  *testChain
+		ctx    context.Context
+
+		mu        sync.Mutex
+		completed []events.Event
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.InfoLevel)
+		logger.SetOutput(GinkgoWriter)
+		hook = test.NewLocal(logger)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+		DeferCleanup(cancel)
+
+		chain = newTestChain(logger, big.NewInt(0), nil)
+
+		bus := events.NewBus(logger)
+		DeferCleanup(bus.Close)
+		completed = nil
+		bus.Subscribe(func(event events.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			completed = append(completed, event)
+		}, events.WalletTransferCompleted)
+		chain.client.SetEventBus(bus)
+	})
+
+	completedEvents := func() []events.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]events.Event(nil), completed...)
+	}
+
+	// logged returns the entries logged with message
+	logged := func(message string) []logrus.Entry {
+		var entries []logrus.Entry
+		for _, entry := range hook.AllEntries() {
+			if entry.Message == message {
+				entries = append(entries, *entry)
+			}
+		}
+		return entries
+	}
+
+	// send sends a plain transfer without waiting for it to be mined
+	send := func() common.Hash {
+		options := wallet.DefaultTransactionOptions()
+		options.WaitReceipt = false
+		status, err := chain.client.SendTransactionWithOptions(ctx, devnet, common.HexToAddress("0xa11ce"), nil, big.NewInt(1), options)
+		Expect(err).NotTo(HaveOccurred())
+		return status.Hash
+	}
+
+	startMonitor := func(opts wallet.MonitorOptions) {
+		opts.Interval = 100 * time.Millisecond
+		monitorCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go chain.client.StartMonitor(monitorCtx, opts)
+	}
+
+	It("publishes the receipt of a transaction nobody waits on", func() {
+		hash := send()
+		startMonitor(wallet.MonitorOptions{})
+
+		Eventually(completedEvents, 10*time.Second).Should(HaveLen(1))
+		event := completedEvents()[0]
+		Expect(event.Data).To(HaveKeyWithValue("network", "DEVNET"))
+		Expect(event.Data).To(HaveKeyWithValue("hash", hash.Hex()))
+		Expect(event.Data).To(HaveKeyWithValue("sent_hash", hash.Hex()))
+		Expect(event.Data).To(HaveKeyWithValue("success", true))
+		Expect(event.Data).To(HaveKeyWithValue("gas_used", uint64(21000)))
+		Expect(event.Data).To(HaveKeyWithValue("explorer_url", "https://explorer.devnet.test/tx/"+hash.Hex()))
+
+		// Published once; the transaction is no longer tracked
+		Consistently(completedEvents, 500*time.Millisecond).Should(HaveLen(1))
+		_, err := chain.client.SpeedUpTransaction(ctx, devnet, hash)
+		Expect(err).To(MatchError(ContainSubstring("transaction already mined")))
+	})
+
+	It("resubmits a dropped transaction with a higher fee and reports it under the hash it was sent with", func() {
+		chain.Pause()
+		hash := send()
+		original, _, err := chain.backend.Client().TransactionByHash(ctx, hash)
+		Expect(err).NotTo(HaveOccurred())
+
+		chain.DropPending()
+		startMonitor(wallet.MonitorOptions{BumpPercent: 20})
+
+		Eventually(func() []logrus.Entry { return logged("Replacement transaction sent") }, 10*time.Second).Should(HaveLen(1))
+		Expect(logged("Transaction stuck, resubmitting with higher fee")[0].Data).To(HaveKeyWithValue("dropped", true))
+		replacementHash := common.HexToHash(logged("Replacement transaction sent")[0].Data["new_hash"].(string))
+		Expect(replacementHash).NotTo(Equal(hash))
+
+		replacement, pending, err := chain.backend.Client().TransactionByHash(ctx, replacementHash)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(BeTrue())
+		Expect(replacement.Nonce()).To(Equal(original.Nonce()))
+		Expect(replacement.To()).To(Equal(original.To()))
+		Expect(replacement.Value()).To(Equal(original.Value()))
+		minimumFee := new(big.Int).Div(new(big.Int).Mul(original.GasFeeCap(), big.NewInt(120)), big.NewInt(100))
+		Expect(replacement.GasFeeCap().Cmp(minimumFee)).To(BeNumerically(">=", 0))
+
+		chain.Resume()
+		Eventually(completedEvents, 10*time.Second).Should(HaveLen(1))
+		event := completedEvents()[0]
+		Expect(event.Data).To(HaveKeyWithValue("hash", replacementHash.Hex()))
+		Expect(event.Data).To(HaveKeyWithValue("sent_hash", hash.Hex()))
+		Expect(event.Data).To(HaveKeyWithValue("success", true))
+	})
+
+	It("warns instead of re-pricing a transaction past the maximum replacements", func() {
+		chain.Pause()
+		send()
+		chain.DropPending()
+		startMonitor(wallet.MonitorOptions{MaxReplacements: 1})
+
+		Eventually(func() []logrus.Entry { return logged("Replacement transaction sent") }, 10*time.Second).Should(HaveLen(1))
+		chain.DropPending()
+
+		Eventually(func() []logrus.Entry {
+			return logged("Transaction still stuck after maximum replacements")
+		}, 10*time.Second).ShouldNot(BeEmpty())
+		warning := logged("Transaction still stuck after maximum replacements")[0]
+		Expect(warning.Level).To(Equal(logrus.WarnLevel))
+		Expect(warning.Data).To(HaveKeyWithValue("replacements", 1))
+		Consistently(func() []logrus.Entry { return logged("Replacement transaction sent") }, 500*time.Millisecond).Should(HaveLen(1))
+		Expect(completedEvents()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,206 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lisanmuaddib/agent-go/pkg/admin"
+	"github.com/lisanmuaddib/agent-go/pkg/config"
+	"github.com/lisanmuaddib/agent-go/pkg/memory"
+	"github.com/lisanmuaddib/agent-go/pkg/wallet"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Wallet nonce store", func() {
+	var (
+		store   *memory.TweetStore
+		ctx     context.Context
+		cancel  context.CancelFunc
+		address = common.HexToAddress("0x742d35Cc6634C0532925a3b844Bc454e4438f44e")
+	)
+
+	BeforeEach(func() {
+		logger := logrus.New()
+		logger.SetLevel(logrus.WarnLevel)
+
+		database := newTestDatabase(logger)
+
+		var err error
+		store, err = memory.NewTweetStore(logger, database, "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("keeps reserved nonces and attaches the transaction once sent", func() {
+		issuedAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+		Expect(store.SaveNonce(ctx, wallet.NonceRecord{Network: wallet.BASE, Address: address, Nonce: 8, IssuedAt: issuedAt})).To(Succeed())
+		Expect(store.SaveNonce(ctx, wallet.NonceRecord{Network: wallet.BASE, Address: address, Nonce: 7, IssuedAt: issuedAt})).To(Succeed())
+		Expect(store.SaveNonce(ctx, wallet.NonceRecord{Network: wallet.ETH, Address: address, Nonce: 3, IssuedAt: issuedAt})).To(Succeed())
+
+		hash := common.HexToHash("0xabc")
+		Expect(store.SaveNonce(ctx, wallet.NonceRecord{
+			Network: wallet.BASE, Address: address, Nonce: 7, TxHash: hash, IssuedAt: issuedAt, SentAt: time.Now(),
+		})).To(Succeed())
+
+		records, err := store.ListNonces(ctx, wallet.BASE, address)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Nonce).To(Equal(uint64(7)))
+		Expect(records[0].TxHash).To(Equal(hash))
+		Expect(records[0].SentAt).NotTo(BeZero())
+		Expect(records[1].Nonce).To(Equal(uint64(8)))
+		Expect(records[1].TxHash).To(Equal(common.Hash{}))
+
+		Expect(store.DeleteNonce(ctx, wallet.BASE, address, 7)).To(Succeed())
+		records, err = store.ListNonces(ctx, wallet.BASE, address)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+
+		records, err = store.ListNonces(ctx, wallet.ETH, address)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Wallet nonce recovery", func() {
+	var (
+		logger *logrus.Logger
+		store  *memory.TweetStore
+		chain  *testChain
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		logger = logrus.New()
+		logger.SetLevel(logrus.FatalLevel)
+
+		var err error
+		store, err = memory.NewTweetStore(logger, newTestDatabase(logger), "1000", config.Default())
+		Expect(err).NotTo(HaveOccurred())
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+		DeferCleanup(cancel)
+
+		chain = newTestChain(logger, big.NewInt(0), nil)
+		chain.client.SetNonceStore(store)
+	})
+
+	// send sends a plain transfer, waiting for its receipt when wait is set
+	send := func(wait bool) common.Hash {
+		options := wallet.DefaultTransactionOptions()
+		options.WaitReceipt = wait
+		status, err := chain.client.SendTransactionWithOptions(ctx, devnet, common.HexToAddress("0xa11ce"), nil, big.NewInt(1), options)
+		Expect(err).NotTo(HaveOccurred())
+		return status.Hash
+	}
+
+	// restart connects a new client to the persisted nonces, as the agent does on startup
+	restart := func() *wallet.Client {
+		client := chain.NewClient(logger)
+		client.SetNonceStore(store)
+		return client
+	}
+
+	It("prunes mined and dropped nonces and tracks pending transactions again after a restart", func() {
+		send(true)
+		chain.Pause()
+		pending := send(false)
+		dropped := send(false)
+		droppedTx, _, err := chain.backend.Client().TransactionByHash(ctx, dropped)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Evict both, then let the node hear of the first one again
+		pendingTx, _, err := chain.backend.Client().TransactionByHash(ctx, pending)
+		Expect(err).NotTo(HaveOccurred())
+		chain.DropPending()
+		Expect(chain.backend.Client().SendTransaction(ctx, pendingTx)).To(Succeed())
+
+		restarted := restart()
+		reports, err := restarted.ReconcileNonces(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reports).To(HaveLen(1))
+		report := reports[0]
+		Expect(report.Network).To(Equal(devnet))
+		Expect(report.ChainNonce).To(Equal(uint64(1)))
+		Expect(report.PendingNonce).To(Equal(uint64(2)))
+		Expect(report.Pending).To(Equal([]uint64{1}))
+		Expect(report.Dropped).To(Equal([]uint64{droppedTx.Nonce()}))
+		Expect(report.Gaps).To(BeEmpty())
+
+		records, err := store.ListNonces(ctx, devnet, chain.Owner())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].TxHash).To(Equal(pending))
+
+		// The recovered transaction can be re-priced like one sent by this run
+		replacement, err := restarted.SpeedUpTransaction(ctx, devnet, pending)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replacement).NotTo(Equal(pending))
+	})
+
+	It("fills a nonce gap holding back a pending transaction", func() {
+		chain.Pause()
+		send(false)
+		blocked := send(false)
+		blockedTx, _, err := chain.backend.Client().TransactionByHash(ctx, blocked)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The node loses the first transaction but keeps the one after it
+		chain.DropPending()
+		Expect(chain.backend.Client().SendTransaction(ctx, blockedTx)).To(Succeed())
+
+		adminServer := admin.NewServer(config.AdminConfig{}, logger)
+		adminServer.HandleWallet(restart())
+
+		response := httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/wallet/nonces", nil))
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var reconciled struct {
+			Networks []wallet.NonceReport `json:"networks"`
+		}
+		Expect(json.Unmarshal(response.Body.Bytes(), &reconciled)).To(Succeed())
+		Expect(reconciled.Networks).To(HaveLen(1))
+		Expect(reconciled.Networks[0].Pending).To(Equal([]uint64{1}))
+		Expect(reconciled.Networks[0].Gaps).To(Equal([]uint64{0}))
+
+		response = httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/wallet/nonces/DEVNET/repair", nil))
+		Expect(response.Code).To(Equal(http.StatusOK), response.Body.String())
+		var repaired wallet.NonceReport
+		Expect(json.Unmarshal(response.Body.Bytes(), &repaired)).To(Succeed())
+		Expect(repaired.Gaps).To(Equal([]uint64{0}))
+		Expect(repaired.Filled).To(HaveLen(1))
+		Expect(repaired.Replaced).To(BeEmpty())
+
+		// The gap filler unblocks the transaction behind it
+		chain.Resume()
+		Eventually(func() (uint64, error) {
+			return chain.backend.Client().NonceAt(ctx, chain.Owner(), nil)
+		}, 10*time.Second).Should(Equal(uint64(2)))
+		receipt, err := chain.backend.Client().TransactionReceipt(ctx, blocked)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(receipt.Status).To(Equal(types.ReceiptStatusSuccessful))
+		filler, _, err := chain.backend.Client().TransactionByHash(ctx, common.HexToHash(repaired.Filled[0]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*filler.To()).To(Equal(chain.Owner()))
+		Expect(filler.Value().Int64()).To(BeZero())
+
+		response = httptest.NewRecorder()
+		adminServer.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/wallet/nonces/MOONNET/repair", nil))
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,107 @@
+package twittermock
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Failure scripts failures, such as outages or rejected credentials, for the
+// endpoints matching Path
+type Failure struct {
+	// Method restricts the failure to one HTTP method; empty matches any
+	Method string
+	// Path is matched as a prefix of the request path without the /2 version
+	// prefix; empty matches every endpoint
+	Path string
+	// Status is the error status answered, e.g. 503 or 401; 0 resets the
+	// connection instead
+	Status int
+	// Times is how many matching requests fail; 0 fails until cleared
+	Times int
+}
+
+// failure is a scripted failure with its progress
+type failure struct {
+	Failure
+	failed int
+}
+
+// fails reports whether the request must fail, consuming one failure of the
+// scenario
+func (f *failure) fails(r *http.Request, path string) bool {
+	if f.Method != "" && f.Method != r.Method {
+		return false
+	}
+	if !strings.HasPrefix(path, f.Path) {
+		return false
+	}
+	if f.Times > 0 && f.failed >= f.Times {
+		return false
+	}
+	f.failed++
+	return true
+}
+
+// Fail adds a failure scenario. Scenarios are checked in the order they were
+// added; the first one that fails a request answers it.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{Failure: f})
+}
+
+// ClearFailures removes every failure scenario
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// failing answers requests with an error status or a reset connection while
+// a failure scenario applies
+func (s *Server) failing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+
+		s.mu.Lock()
+		var hit *failure
+		for _, f := range s.failures {
+			if f.fails(r, path) {
+				hit = f
+				break
+			}
+		}
+		s.mu.Unlock()
+
+		switch {
+		case hit == nil:
+			next.ServeHTTP(w, r)
+		case hit.Status == 0:
+			resetConnection(w)
+		default:
+			writeJSON(w, hit.Status, map[string]interface{}{
+				"title":  http.StatusText(hit.Status),
+				"detail": http.StatusText(hit.Status),
+				"type":   "about:blank",
+				"status": hit.Status,
+			})
+		}
+	})
+}
+
+// resetConnection drops the connection without answering, so the client sees
+// the response end early
+func resetConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("twittermock: connection cannot be hijacked")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(err)
+	}
+	if tcp, ok := conn.(interface{ SetLinger(int) error }); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
@@ -0,0 +1,146 @@
+package twittermock
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+)
+
+// Lists returns the lists created through the API, oldest first
+func (s *Server) Lists() []twitter.List {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]twitter.List(nil), s.lists...)
+}
+
+// ListMembers returns the IDs of the users added to a list, in order
+func (s *Server) ListMembers(listID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.members[listID]...)
+}
+
+// Bookmarks returns the IDs of the bot's bookmarked tweets, in order
+func (s *Server) Bookmarks() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bookmarks...)
+}
+
+func (s *Server) handleCreateList(w http.ResponseWriter, r *http.Request) {
+	body := bodyFromContext(r.Context())
+	name, _ := body["name"].(string)
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("name is required"))
+		return
+	}
+	description, _ := body["description"].(string)
+	private, _ := body["private"].(bool)
+
+	s.mu.Lock()
+	s.nextID++
+	list := twitter.List{
+		ID:          strconv.FormatInt(s.nextID, 10),
+		Name:        name,
+		Description: description,
+		Private:     private,
+		OwnerID:     s.me.ID,
+	}
+	s.lists = append(s.lists, list)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data": map[string]string{"id": list.ID, "name": list.Name},
+	})
+}
+
+func (s *Server) handleOwnedLists(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	s.mu.Lock()
+	var owned []twitter.List
+	for _, list := range s.lists {
+		if list.OwnerID == userID {
+			list.MemberCount = len(s.members[list.ID])
+			owned = append(owned, list)
+		}
+	}
+	s.mu.Unlock()
+
+	response := map[string]interface{}{"meta": twitter.Meta{ResultCount: len(owned)}}
+	if len(owned) > 0 {
+		response["data"] = owned
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleAddListMember(w http.ResponseWriter, r *http.Request) {
+	listID := r.PathValue("id")
+	userID, _ := bodyFromContext(r.Context())["user_id"].(string)
+	if userID == "" {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("user_id is required"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.ContainsFunc(s.lists, func(list twitter.List) bool { return list.ID == listID }) {
+		writeJSON(w, http.StatusNotFound, invalidRequest("list not found"))
+		return
+	}
+	if !slices.Contains(s.members[listID], userID) {
+		s.members[listID] = append(s.members[listID], userID)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]bool{"is_member": true},
+	})
+}
+
+// handleListTweets pages through the tweets of a list's members, newest first
+func (s *Server) handleListTweets(w http.ResponseWriter, r *http.Request) {
+	listID := r.PathValue("id")
+
+	s.mu.Lock()
+	members := s.members[listID]
+	var tweets []twitter.Tweet
+	for _, tweet := range s.tweets {
+		if slices.Contains(members, tweet.AuthorID) {
+			tweets = append(tweets, tweet)
+		}
+	}
+	s.mu.Unlock()
+
+	s.writeTimeline(w, r, tweets)
+}
+
+func (s *Server) handleAddBookmark(w http.ResponseWriter, r *http.Request) {
+	tweetID, _ := bodyFromContext(r.Context())["tweet_id"].(string)
+	if tweetID == "" {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("tweet_id is required"))
+		return
+	}
+
+	s.mu.Lock()
+	if !slices.Contains(s.bookmarks, tweetID) {
+		s.bookmarks = append(s.bookmarks, tweetID)
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]bool{"bookmarked": true},
+	})
+}
+
+func (s *Server) handleRemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	tweetID := r.PathValue("tweet_id")
+
+	s.mu.Lock()
+	s.bookmarks = slices.DeleteFunc(s.bookmarks, func(id string) bool { return id == tweetID })
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]bool{"bookmarked": false},
+	})
+}
@@ -0,0 +1,126 @@
+package twittermock
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// oauth2Server is the state of the OAuth 2.0 token endpoint
+type oauth2Server struct {
+	codes     map[string]oauth2Grant // authorization code -> grant
+	refresh   map[string][]string    // unused refresh token -> scopes
+	lifetime  time.Duration
+	issued    int
+	refreshes int
+}
+
+// oauth2Grant is an authorization the account owner gave on the authorize page
+type oauth2Grant struct {
+	challenge string
+	scopes    []string
+}
+
+func newOAuth2Server() oauth2Server {
+	return oauth2Server{
+		codes:    make(map[string]oauth2Grant),
+		refresh:  make(map[string][]string),
+		lifetime: 2 * time.Hour,
+	}
+}
+
+// AuthorizeOAuth2 stands in for the account owner approving the authorize
+// page: it returns the code the redirect would carry, which the token
+// endpoint exchanges once for a token granting scopes to the holder of the
+// verifier of challenge
+func (s *Server) AuthorizeOAuth2(challenge string, scopes ...string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	code := fmt.Sprintf("code-%d", s.nextID)
+	s.oauth2.codes[code] = oauth2Grant{challenge: challenge, scopes: scopes}
+	return code
+}
+
+// SetOAuth2TokenLifetime changes how long issued access tokens are valid
+func (s *Server) SetOAuth2TokenLifetime(lifetime time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.oauth2.lifetime = lifetime
+}
+
+// OAuth2Refreshes counts the tokens issued for a refresh token
+func (s *Server) OAuth2Refreshes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.oauth2.refreshes
+}
+
+func (s *Server) handleOAuth2Token(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	form, err := url.ParseQuery(string(raw))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, oauth2Error("invalid_request", "malformed form"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var scopes []string
+	switch form.Get("grant_type") {
+	case "authorization_code":
+		grant, ok := s.oauth2.codes[form.Get("code")]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, oauth2Error("invalid_grant", "unknown authorization code"))
+			return
+		}
+		sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+			writeJSON(w, http.StatusBadRequest, oauth2Error("invalid_grant", "code verifier does not match the challenge"))
+			return
+		}
+		delete(s.oauth2.codes, form.Get("code"))
+		scopes = grant.scopes
+	case "refresh_token":
+		granted, ok := s.oauth2.refresh[form.Get("refresh_token")]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, oauth2Error("invalid_grant", "refresh token is invalid or already used"))
+			return
+		}
+		// Refresh tokens are single use
+		delete(s.oauth2.refresh, form.Get("refresh_token"))
+		s.oauth2.refreshes++
+		scopes = granted
+	default:
+		writeJSON(w, http.StatusBadRequest, oauth2Error("unsupported_grant_type", form.Get("grant_type")))
+		return
+	}
+
+	s.oauth2.issued++
+	response := map[string]interface{}{
+		"token_type":   "bearer",
+		"expires_in":   int64(s.oauth2.lifetime / time.Second),
+		"access_token": fmt.Sprintf("oauth2-access-%d", s.oauth2.issued),
+		"scope":        strings.Join(scopes, " "),
+	}
+	if slices.Contains(scopes, "offline.access") {
+		refreshToken := fmt.Sprintf("oauth2-refresh-%d", s.oauth2.issued)
+		s.oauth2.refresh[refreshToken] = scopes
+		response["refresh_token"] = refreshToken
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func oauth2Error(code, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"error":             code,
+		"error_description": description,
+	}
+}
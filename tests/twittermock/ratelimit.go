@@ -0,0 +1,156 @@
+package twittermock
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit scripts 429 responses for the endpoints matching Path
+type RateLimit struct {
+	// Path is matched as a prefix of the request path without the /2 version
+	// prefix, e.g. "/users/1000/mentions"; empty matches every endpoint
+	Path string
+	// After lets this many matching requests succeed before limiting starts
+	After int
+	// Times is how many matching requests are rejected; 0 rejects until cleared
+	Times int
+	// Reset is reported as the time the limit resets; defaults to a minute from now
+	Reset time.Time
+	// Daily reports the limit through the 24-hour user limit headers instead
+	// of the per-endpoint ones
+	Daily bool
+}
+
+// windowLimit and windowLength describe the rate limit window every endpoint
+// reports on the responses it answers
+const (
+	windowLimit  = 180
+	windowLength = 15 * time.Minute
+)
+
+// quotaWindow is the current rate limit window of an endpoint
+type quotaWindow struct {
+	remaining int
+	reset     time.Time
+}
+
+// rateLimit is a scripted limit with its progress
+type rateLimit struct {
+	RateLimit
+	seen     int
+	rejected int
+}
+
+// limits reports whether the request must be rejected, consuming one
+// request of the scenario
+func (l *rateLimit) limits(path string) bool {
+	if !strings.HasPrefix(path, l.Path) {
+		return false
+	}
+	l.seen++
+	if l.seen <= l.After {
+		return false
+	}
+	if l.Times > 0 && l.rejected >= l.Times {
+		return false
+	}
+	l.rejected++
+	return true
+}
+
+// RateLimit adds a rate-limit scenario. Scenarios are checked in the order
+// they were added; the first one that limits a request answers it.
+func (s *Server) RateLimit(limit RateLimit) {
+	if limit.Reset.IsZero() {
+		limit.Reset = time.Now().Add(time.Minute)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = append(s.limits, &rateLimit{RateLimit: limit})
+}
+
+// ClearRateLimits removes every rate-limit scenario
+func (s *Server) ClearRateLimits() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = nil
+}
+
+// ExpireQuotaWindows ends the rate limit window of every endpoint, as if
+// the windows had run out, so the next request to each starts a new window
+// that resets later
+func (s *Server) ExpireQuotaWindows() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows = make(map[string]*quotaWindow)
+	s.expired++
+}
+
+// consumeQuota counts a request against the window of its endpoint, keyed by
+// method and path, and returns the window. The caller holds s.mu.
+func (s *Server) consumeQuota(key string) quotaWindow {
+	now := time.Now()
+	window, ok := s.windows[key]
+	if !ok || !now.Before(window.reset) {
+		reset := now.Add(time.Duration(s.expired+1) * windowLength).Truncate(time.Second)
+		window = &quotaWindow{remaining: windowLimit, reset: reset}
+		s.windows[key] = window
+	}
+	if window.remaining > 0 {
+		window.remaining--
+	}
+	return *window
+}
+
+// rateLimited answers requests with 429 while a scenario applies, and reports
+// the endpoint's window in the rate limit headers of every other response
+func (s *Server) rateLimited(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+
+		s.mu.Lock()
+		var hit *rateLimit
+		for _, limit := range s.limits {
+			if limit.limits(path) {
+				hit = limit
+				break
+			}
+		}
+		var window quotaWindow
+		if hit == nil {
+			window = s.consumeQuota(r.Method + " " + path)
+		}
+		s.mu.Unlock()
+
+		header := w.Header()
+		if hit == nil {
+			header.Set("x-rate-limit-limit", strconv.Itoa(windowLimit))
+			header.Set("x-rate-limit-remaining", strconv.Itoa(window.remaining))
+			header.Set("x-rate-limit-reset", strconv.FormatInt(window.reset.Unix(), 10))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reset := hit.Reset
+		if hit.Daily {
+			header.Set("x-rate-limit-remaining", strconv.Itoa(windowLimit-1))
+			header.Set("x-rate-limit-reset", strconv.FormatInt(time.Now().Add(windowLength).Unix(), 10))
+			header.Set("x-user-limit-24hour-limit", "17")
+			header.Set("x-user-limit-24hour-remaining", "0")
+			header.Set("x-user-limit-24hour-reset", strconv.FormatInt(reset.Unix(), 10))
+		} else {
+			header.Set("x-rate-limit-limit", strconv.Itoa(windowLimit))
+			header.Set("x-rate-limit-remaining", "0")
+			header.Set("x-rate-limit-reset", strconv.FormatInt(reset.Unix(), 10))
+		}
+		writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+			"title":  "Too Many Requests",
+			"detail": "Too Many Requests",
+			"type":   "about:blank",
+			"status": http.StatusTooManyRequests,
+		})
+	})
+}
@@ -0,0 +1,118 @@
+package twittermock
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+)
+
+// searchClause is one clause of a search query; a tweet must match one of
+// its terms, or none of them when the clause is negated
+type searchClause struct {
+	negated bool
+	terms   []string
+}
+
+// parseSearchQuery splits a recent search query into clauses, supporting
+// keywords, "quoted phrases", @mentions, (a OR b) groups, negation and the
+// conversation_id, from, to, lang and is operators
+func parseSearchQuery(query string) ([]searchClause, error) {
+	var clauses []searchClause
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		clause := searchClause{}
+		if strings.HasPrefix(rest, "-") {
+			clause.negated = true
+			rest = rest[1:]
+		}
+
+		var token string
+		switch {
+		case strings.HasPrefix(rest, "("):
+			end := strings.Index(rest, ")")
+			if end < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in %q", query)
+			}
+			token, rest = rest[1:end], rest[end+1:]
+			for _, term := range strings.Split(token, " OR ") {
+				clause.terms = append(clause.terms, strings.TrimSpace(term))
+			}
+		case strings.HasPrefix(rest, `"`):
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("unbalanced quotes in %q", query)
+			}
+			token, rest = rest[:end+2], rest[end+2:]
+			clause.terms = []string{token}
+		default:
+			token, rest, _ = strings.Cut(rest, " ")
+			clause.terms = []string{token}
+		}
+
+		for _, term := range clause.terms {
+			if name, _, ok := strings.Cut(term, ":"); ok && !strings.HasPrefix(term, `"`) {
+				switch name {
+				case "conversation_id", "from", "to", "lang", "is":
+				default:
+					return nil, fmt.Errorf("operator %s: is not supported by the mock", name)
+				}
+			}
+		}
+		clauses = append(clauses, clause)
+		rest = strings.TrimSpace(rest)
+	}
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("query is required")
+	}
+	return clauses, nil
+}
+
+// matchesSearch reports whether tweet matches every clause. The caller
+// holds s.mu.
+func (s *Server) matchesSearch(tweet twitter.Tweet, clauses []searchClause) bool {
+	for _, clause := range clauses {
+		matched := false
+		for _, term := range clause.terms {
+			if s.matchesTerm(tweet, term) {
+				matched = true
+				break
+			}
+		}
+		if matched == clause.negated {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesTerm matches a single keyword, phrase, mention or operator
+func (s *Server) matchesTerm(tweet twitter.Tweet, term string) bool {
+	text := strings.ToLower(tweet.Text)
+	if strings.HasPrefix(term, `"`) {
+		return strings.Contains(text, strings.ToLower(strings.Trim(term, `"`)))
+	}
+
+	name, value, ok := strings.Cut(term, ":")
+	if !ok {
+		return strings.Contains(text, strings.ToLower(term))
+	}
+	switch name {
+	case "conversation_id":
+		return tweet.ConversationID == value
+	case "from":
+		return strings.EqualFold(s.users[tweet.AuthorID].Username, value)
+	case "to":
+		return tweet.InReplyToUserID != "" && strings.EqualFold(s.users[tweet.InReplyToUserID].Username, value)
+	case "lang":
+		return tweet.Lang == value
+	case "is":
+		kind := map[string]string{"reply": "replied_to", "retweet": "retweeted", "quote": "quoted"}[value]
+		for _, ref := range tweet.ReferencedTweets {
+			if ref.Type == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,814 @@
+// Package twittermock is an in-memory stand-in for the Twitter API v2 endpoints
+// used by the twitter client (users/me, mentions, tweet lookup, conversation
+// search, post, delete and media upload) and the OAuth 2.0 token endpoint, so
+// integration tests run without credentials or network access. Rate limits can be scripted per endpoint to exercise backoff.
+// Every other response reports the endpoint's rate limit window, which counts
+// down per request like the real one.
+// Like the real API, tweets only carry the fields asked for in tweet.fields and
+// includes only hold the objects asked for in expansions.
+package twittermock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lisanmuaddib/agent-go/pkg/interfaces/twitter"
+	"github.com/sirupsen/logrus"
+)
+
+// apiPrefix mirrors the version prefix of https://api.twitter.com/2
+const apiPrefix = "/2"
+
+// firstTweetID seeds generated IDs so they look like snowflakes and sort by age
+const firstTweetID int64 = 1850000000000000000
+
+// Request is a request the server received, for assertions
+type Request struct {
+	Method        string
+	Path          string
+	Query         map[string]string
+	Body          map[string]interface{}
+	Authorization string
+}
+
+// Upload is media received by POST /media/upload
+type Upload struct {
+	ID        string
+	MediaKey  string
+	Category  string
+	MediaType string
+	Data      []byte
+}
+
+// Server is a mock Twitter API. The zero value is not usable; call NewServer.
+type Server struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	me        twitter.User
+	users     map[string]twitter.User
+	unlisted  map[string]bool // user IDs left out of includes
+	tweets    map[string]twitter.Tweet
+	mentions  map[string][]string // user ID -> mentioning tweet IDs
+	posted    []twitter.Tweet
+	deleted   []string
+	uploads   []Upload
+	lists     []twitter.List
+	members   map[string][]string // list ID -> member user IDs
+	bookmarks []string
+	requests  []Request
+	limits    []*rateLimit
+	windows   map[string]*quotaWindow // method and path -> current window
+	expired   int                     // windows ended by ExpireQuotaWindows
+	failures  []*failure
+	nextID    int64
+	oauth2    oauth2Server
+}
+
+// NewServer starts a mock API authenticated as a default bot user. Close it
+// when the test is done.
+func NewServer() *Server {
+	s := &Server{
+		me:       twitter.User{ID: "1000", Name: "Mock Bot", Username: "mockbot"},
+		users:    make(map[string]twitter.User),
+		unlisted: make(map[string]bool),
+		tweets:   make(map[string]twitter.Tweet),
+		mentions: make(map[string][]string),
+		members:  make(map[string][]string),
+		windows:  make(map[string]*quotaWindow),
+		nextID:   firstTweetID,
+		oauth2:   newOAuth2Server(),
+	}
+	s.users[s.me.ID] = s.me
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /2/users", s.handleLookupUsers)
+	mux.HandleFunc("GET /2/users/me", s.handleMe)
+	mux.HandleFunc("GET /2/users/by/username/{username}", s.handleUserByUsername)
+	mux.HandleFunc("GET /2/users/{id}/mentions", s.handleMentions)
+	mux.HandleFunc("GET /2/users/{id}/tweets", s.handleUserTweets)
+	mux.HandleFunc("GET /2/tweets", s.handleLookupTweets)
+	mux.HandleFunc("GET /2/tweets/{id}", s.handleGetTweet)
+	mux.HandleFunc("GET /2/tweets/search/recent", s.handleSearchRecent)
+	mux.HandleFunc("POST /2/tweets", s.handlePostTweet)
+	mux.HandleFunc("DELETE /2/tweets/{id}", s.handleDeleteTweet)
+	mux.HandleFunc("POST /2/media/upload", s.handleUploadMedia)
+	mux.HandleFunc("POST /2/lists", s.handleCreateList)
+	mux.HandleFunc("GET /2/users/{id}/owned_lists", s.handleOwnedLists)
+	mux.HandleFunc("POST /2/lists/{id}/members", s.handleAddListMember)
+	mux.HandleFunc("GET /2/lists/{id}/tweets", s.handleListTweets)
+	mux.HandleFunc("POST /2/users/{id}/bookmarks", s.handleAddBookmark)
+	mux.HandleFunc("DELETE /2/users/{id}/bookmarks/{tweet_id}", s.handleRemoveBookmark)
+	mux.HandleFunc("POST /2/oauth2/token", s.handleOAuth2Token)
+
+	s.server = httptest.NewServer(s.record(s.failing(s.rateLimited(mux))))
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// BaseURL is the API root to use as TwitterConfig.BaseURL
+func (s *Server) BaseURL() string {
+	return s.server.URL + apiPrefix
+}
+
+// Config returns a client configuration pointed at the mock, with placeholder
+// OAuth 1.0a credentials (requests are signed but not verified)
+func (s *Server) Config(logger *logrus.Logger) *twitter.TwitterConfig {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &twitter.TwitterConfig{
+		ConsumerKey:       "mock-consumer-key",
+		ConsumerSecret:    "mock-consumer-secret",
+		AccessToken:       "mock-access-token",
+		AccessTokenSecret: "mock-access-token-secret",
+		BearerToken:       "mock-bearer-token",
+		BaseURL:           s.BaseURL(),
+		TweetEndpoint:     "/tweets",
+		UserEndpoint:      "/users",
+		SearchEndpoint:    "/tweets/search/recent",
+		TimelineEndpoint:  "/users/:id/tweets",
+		MediaEndpoint:     "/media/upload",
+		ListEndpoint:      "/lists",
+		RateLimit:         180,
+		RateWindow:        int(15 * time.Minute / time.Second),
+		DefaultFields:     []string{"id", "text", "created_at", "conversation_id", "author_id"},
+		MetricFields:      []string{"like_count", "reply_count", "retweet_count", "quote_count"},
+		ExpansionFields:   []string{"author_id", "referenced_tweets.id", "in_reply_to_user_id"},
+		Logger:            logger,
+	}
+}
+
+// SetAuthenticatedUser changes the user returned by users/me and used as the
+// author of posted tweets
+func (s *Server) SetAuthenticatedUser(user twitter.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.me = user
+	s.users[user.ID] = user
+}
+
+// AddUser makes a user known, e.g. as the author of mentions
+func (s *Server) AddUser(user twitter.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+}
+
+// OmitFromIncludes leaves a known user out of expanded includes, as the API
+// sometimes does, so the user can only be found through the users lookup
+func (s *Server) OmitFromIncludes(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unlisted[userID] = true
+}
+
+// AddTweet stores a tweet, assigning an ID, conversation and creation time when
+// missing, and returns it as stored
+func (s *Server) AddTweet(tweet twitter.Tweet) twitter.Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTweetLocked(tweet)
+}
+
+// AddMention stores a tweet that mentions userID, so it is returned by the
+// mentions timeline of that user
+func (s *Server) AddMention(userID string, tweet twitter.Tweet) twitter.Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tweet = s.addTweetLocked(tweet)
+	s.mentions[userID] = append(s.mentions[userID], tweet.ID)
+	return tweet
+}
+
+// EditTweet edits a tweet the way the API does: the new text becomes a new
+// version with its own ID, and every version lists the whole edit history.
+// Mentions timelines return the latest version. It returns the new version.
+func (s *Server) EditTweet(id, text string) twitter.Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	original := s.tweets[id]
+	history := original.EditHistoryTweetIDs
+	s.nextID++
+	version := original
+	version.ID = strconv.FormatInt(s.nextID, 10)
+	version.Text = text
+	version.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	version.EditHistoryTweetIDs = append(append([]string(nil), history...), version.ID)
+	s.tweets[version.ID] = version
+
+	for _, versionID := range version.EditHistoryTweetIDs {
+		edited := s.tweets[versionID]
+		edited.EditHistoryTweetIDs = version.EditHistoryTweetIDs
+		s.tweets[versionID] = edited
+	}
+	for userID, ids := range s.mentions {
+		for i, mentionID := range ids {
+			if mentionID == history[len(history)-1] {
+				s.mentions[userID][i] = version.ID
+			}
+		}
+	}
+	return version
+}
+
+func (s *Server) addTweetLocked(tweet twitter.Tweet) twitter.Tweet {
+	if tweet.ID == "" {
+		s.nextID++
+		tweet.ID = strconv.FormatInt(s.nextID, 10)
+	}
+	if tweet.ConversationID == "" {
+		tweet.ConversationID = tweet.ID
+	}
+	if tweet.CreatedAt == "" {
+		tweet.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if len(tweet.EditHistoryTweetIDs) == 0 {
+		tweet.EditHistoryTweetIDs = []string{tweet.ID}
+	}
+	s.tweets[tweet.ID] = tweet
+	return tweet
+}
+
+// Posted returns the tweets created through POST /tweets, oldest first
+func (s *Server) Posted() []twitter.Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]twitter.Tweet(nil), s.posted...)
+}
+
+// Deleted returns the IDs of the tweets deleted through the API
+func (s *Server) Deleted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.deleted...)
+}
+
+// Uploads returns the media uploaded through the API, oldest first
+func (s *Server) Uploads() []Upload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Upload(nil), s.uploads...)
+}
+
+// Requests returns every request received, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// record logs each request before it is handled
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := Request{
+			Method:        r.Method,
+			Path:          strings.TrimPrefix(r.URL.Path, apiPrefix),
+			Query:         make(map[string]string),
+			Authorization: r.Header.Get("Authorization"),
+		}
+		for key, values := range r.URL.Query() {
+			request.Query[key] = strings.Join(values, ",")
+		}
+		if r.Body != nil && r.ContentLength != 0 {
+			// Keep the raw body readable for handlers of non-JSON requests
+			raw, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(raw))
+			var body map[string]interface{}
+			if err := json.Unmarshal(raw, &body); err == nil {
+				request.Body = body
+			}
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, request)
+		s.mu.Unlock()
+
+		next.ServeHTTP(w, withBody(r, request.Body))
+	})
+}
+
+// bodyKey carries the decoded JSON request body from the recorder to handlers
+type bodyKey struct{}
+
+// withBody lets handlers read the decoded body the recorder already consumed
+func withBody(r *http.Request, body map[string]interface{}) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), bodyKey{}, body))
+}
+
+func bodyFromContext(ctx context.Context) map[string]interface{} {
+	body, _ := ctx.Value(bodyKey{}).(map[string]interface{})
+	return body
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	me := s.me
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": me})
+}
+
+func (s *Server) handleUserByUsername(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users {
+		if strings.EqualFold(user.Username, username) {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": user})
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, notFound("user", username))
+}
+
+func (s *Server) handleMentions(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	s.mu.Lock()
+	tweets := make([]twitter.Tweet, 0, len(s.mentions[userID]))
+	for _, id := range s.mentions[userID] {
+		if tweet, ok := s.tweets[id]; ok {
+			tweets = append(tweets, tweet)
+		}
+	}
+	s.mu.Unlock()
+
+	s.writeTimeline(w, r, tweets)
+}
+
+func (s *Server) handleUserTweets(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	exclude := strings.Split(r.URL.Query().Get("exclude"), ",")
+
+	s.mu.Lock()
+	var tweets []twitter.Tweet
+	for _, tweet := range s.tweets {
+		if tweet.AuthorID != userID || excluded(tweet, exclude) {
+			continue
+		}
+		tweets = append(tweets, tweet)
+	}
+	s.mu.Unlock()
+
+	s.writeTimeline(w, r, tweets)
+}
+
+// excluded reports whether a timeline's exclude parameter leaves tweet out
+func excluded(tweet twitter.Tweet, exclude []string) bool {
+	for _, kind := range exclude {
+		for _, ref := range tweet.ReferencedTweets {
+			if (kind == "retweets" && ref.Type == "retweeted") || (kind == "replies" && ref.Type == "replied_to") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeTimeline pages through tweets newest first, honouring max_results,
+// since_id, until_id and pagination_token like the timeline endpoints
+func (s *Server) writeTimeline(w http.ResponseWriter, r *http.Request, tweets []twitter.Tweet) {
+	query := r.URL.Query()
+	sortNewestFirst(tweets)
+
+	sinceID, untilID := query.Get("since_id"), query.Get("until_id")
+	filtered := tweets[:0]
+	for _, tweet := range tweets {
+		if sinceID != "" && !idAfter(tweet.ID, sinceID) {
+			continue
+		}
+		if untilID != "" && !idAfter(untilID, tweet.ID) {
+			continue
+		}
+		filtered = append(filtered, tweet)
+	}
+
+	maxResults := 10
+	if value := query.Get("max_results"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 5 || n > 100 {
+			writeJSON(w, http.StatusBadRequest, invalidRequest("max_results must be between 5 and 100"))
+			return
+		}
+		maxResults = n
+	}
+
+	start := 0
+	if token := query.Get("pagination_token"); token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 0 || n > len(filtered) {
+			writeJSON(w, http.StatusBadRequest, invalidRequest("invalid pagination_token"))
+			return
+		}
+		start = n
+	}
+	end := start + maxResults
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	page := filtered[start:end]
+
+	meta := twitter.Meta{ResultCount: len(page)}
+	if len(page) > 0 {
+		meta.NewestID = page[0].ID
+		meta.OldestID = page[len(page)-1].ID
+	}
+	if end < len(filtered) {
+		meta.NextToken = strconv.Itoa(end)
+	}
+	if start > 0 {
+		meta.PreviousToken = strconv.Itoa(start - maxResults)
+	}
+
+	response := map[string]interface{}{"meta": meta}
+	if len(page) > 0 {
+		response["data"] = tweetFields(r, page)
+		response["includes"] = s.includes(r, page)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleLookupTweets(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ids"), ",")
+	if len(ids) == 0 || ids[0] == "" {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("ids is required"))
+		return
+	}
+
+	s.mu.Lock()
+	var found []twitter.Tweet
+	var errs []map[string]interface{}
+	for _, id := range ids {
+		if tweet, ok := s.tweets[id]; ok {
+			found = append(found, tweet)
+		} else {
+			errs = append(errs, notFoundError("tweet", id))
+		}
+	}
+	s.mu.Unlock()
+
+	response := map[string]interface{}{}
+	if len(found) > 0 {
+		response["data"] = tweetFields(r, found)
+		response["includes"] = s.includes(r, found)
+	}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleLookupUsers(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ids"), ",")
+	if len(ids) == 0 || ids[0] == "" {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("ids is required"))
+		return
+	}
+
+	s.mu.Lock()
+	var found []twitter.User
+	var errs []map[string]interface{}
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			found = append(found, user)
+		} else {
+			errs = append(errs, notFoundError("user", id))
+		}
+	}
+	s.mu.Unlock()
+
+	response := map[string]interface{}{}
+	if len(found) > 0 {
+		response["data"] = found
+	}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleGetTweet(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	tweet, ok := s.tweets[id]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusOK, notFound("tweet", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":     tweetFields(r, []twitter.Tweet{tweet})[0],
+		"includes": s.includes(r, []twitter.Tweet{tweet}),
+	})
+}
+
+// handleSearchRecent supports the subset of the query language parseSearchQuery
+// understands
+func (s *Server) handleSearchRecent(w http.ResponseWriter, r *http.Request) {
+	clauses, err := parseSearchQuery(r.URL.Query().Get("query"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, invalidRequest(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	var tweets []twitter.Tweet
+	for _, tweet := range s.tweets {
+		if s.matchesSearch(tweet, clauses) {
+			tweets = append(tweets, tweet)
+		}
+	}
+	s.mu.Unlock()
+
+	s.writeTimeline(w, r, tweets)
+}
+
+func (s *Server) handlePostTweet(w http.ResponseWriter, r *http.Request) {
+	body := bodyFromContext(r.Context())
+	text, _ := body["text"].(string)
+	if strings.TrimSpace(text) == "" {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("text is required"))
+		return
+	}
+
+	replyTo, _ := body["in_reply_to_tweet_id"].(string)
+	if reply, ok := body["reply"].(map[string]interface{}); ok {
+		replyTo, _ = reply["in_reply_to_tweet_id"].(string)
+	}
+	quoteID, _ := body["quote_tweet_id"].(string)
+	var mediaIDs []string
+	if media, ok := body["media"].(map[string]interface{}); ok {
+		ids, _ := media["media_ids"].([]interface{})
+		for _, id := range ids {
+			if id, ok := id.(string); ok {
+				mediaIDs = append(mediaIDs, id)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	tweet := twitter.Tweet{Text: text, AuthorID: s.me.ID}
+	if replyTo != "" {
+		parent, ok := s.tweets[replyTo]
+		if !ok {
+			s.mu.Unlock()
+			writeJSON(w, http.StatusBadRequest, invalidRequest("in_reply_to_tweet_id does not exist: "+replyTo))
+			return
+		}
+		tweet.ConversationID = parent.ConversationID
+		tweet.InReplyToUserID = parent.AuthorID
+		tweet.ReferencedTweets = append(tweet.ReferencedTweets, referencedTweet("replied_to", replyTo))
+	}
+	if quoteID != "" {
+		tweet.ReferencedTweets = append(tweet.ReferencedTweets, referencedTweet("quoted", quoteID))
+	}
+	for _, id := range mediaIDs {
+		upload, ok := s.uploadLocked(id)
+		if !ok {
+			s.mu.Unlock()
+			writeJSON(w, http.StatusBadRequest, invalidRequest("media_ids does not exist: "+id))
+			return
+		}
+		tweet.Attachments.MediaKeys = append(tweet.Attachments.MediaKeys, upload.MediaKey)
+	}
+	tweet = s.addTweetLocked(tweet)
+	s.posted = append(s.posted, tweet)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":                     tweet.ID,
+			"text":                   tweet.Text,
+			"edit_history_tweet_ids": tweet.EditHistoryTweetIDs,
+		},
+	})
+}
+
+// handleUploadMedia accepts a multipart upload with media and media_category
+// fields, as sent by the simple (non-chunked) upload
+func (s *Server) handleUploadMedia(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("expected a multipart form: "+err.Error()))
+		return
+	}
+	file, header, err := r.FormFile("media")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("media is required"))
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil || len(data) == 0 {
+		writeJSON(w, http.StatusBadRequest, invalidRequest("media is empty"))
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	upload := Upload{
+		ID:        strconv.FormatInt(s.nextID, 10),
+		Category:  r.FormValue("media_category"),
+		MediaType: header.Header.Get("Content-Type"),
+		Data:      data,
+	}
+	upload.MediaKey = "3_" + upload.ID
+	s.uploads = append(s.uploads, upload)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":        upload.ID,
+			"media_key": upload.MediaKey,
+			"size":      len(data),
+		},
+	})
+}
+
+func (s *Server) uploadLocked(id string) (Upload, bool) {
+	for _, upload := range s.uploads {
+		if upload.ID == id {
+			return upload, true
+		}
+	}
+	return Upload{}, false
+}
+
+func (s *Server) handleDeleteTweet(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	tweet, ok := s.tweets[id]
+	if ok && tweet.AuthorID != s.me.ID {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{
+			"title":  "Forbidden",
+			"detail": "You are not allowed to delete a Tweet you do not own.",
+			"status": http.StatusForbidden,
+		})
+		return
+	}
+	if ok {
+		delete(s.tweets, id)
+		s.deleted = append(s.deleted, id)
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]bool{"deleted": ok},
+	})
+}
+
+// defaultTweetFields are returned whether or not tweet.fields asks for them
+var defaultTweetFields = []string{"id", "text", "edit_history_tweet_ids"}
+
+// tweetFields drops the fields of tweets that the request's tweet.fields does
+// not ask for
+func tweetFields(r *http.Request, tweets []twitter.Tweet) []map[string]interface{} {
+	keep := make(map[string]bool)
+	for _, field := range defaultTweetFields {
+		keep[field] = true
+	}
+	for _, field := range queryList(r, "tweet.fields") {
+		keep[field] = true
+	}
+
+	shaped := make([]map[string]interface{}, 0, len(tweets))
+	for _, tweet := range tweets {
+		raw, _ := json.Marshal(tweet)
+		var fields map[string]interface{}
+		_ = json.Unmarshal(raw, &fields)
+		for field := range fields {
+			if !keep[field] {
+				delete(fields, field)
+			}
+		}
+		shaped = append(shaped, fields)
+	}
+	return shaped
+}
+
+// queryList splits a comma separated query parameter
+func queryList(r *http.Request, key string) []string {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// includes expands the authors, replied-to users and referenced tweets of
+// tweets, as far as the request's expansions ask for them
+func (s *Server) includes(r *http.Request, tweets []twitter.Tweet) map[string]interface{} {
+	expansions := make(map[string]bool)
+	for _, expansion := range queryList(r, "expansions") {
+		expansions[expansion] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []twitter.User
+	var referenced []twitter.Tweet
+	seenUsers := make(map[string]bool)
+	seenTweets := make(map[string]bool)
+	addUser := func(id string) {
+		if user, ok := s.users[id]; ok && !seenUsers[user.ID] && !s.unlisted[user.ID] {
+			seenUsers[user.ID] = true
+			users = append(users, user)
+		}
+	}
+	for _, tweet := range tweets {
+		if expansions["author_id"] {
+			addUser(tweet.AuthorID)
+		}
+		if expansions["in_reply_to_user_id"] && tweet.InReplyToUserID != "" {
+			addUser(tweet.InReplyToUserID)
+		}
+		if !expansions["referenced_tweets.id"] {
+			continue
+		}
+		for _, ref := range tweet.ReferencedTweets {
+			if parent, ok := s.tweets[ref.ID]; ok && !seenTweets[ref.ID] {
+				seenTweets[ref.ID] = true
+				referenced = append(referenced, parent)
+			}
+		}
+	}
+
+	includes := make(map[string]interface{})
+	if len(users) > 0 {
+		includes["users"] = users
+	}
+	if len(referenced) > 0 {
+		includes["tweets"] = referenced
+	}
+	return includes
+}
+
+// referencedTweet builds an entry of Tweet.ReferencedTweets
+func referencedTweet(refType, id string) twitter.ReferencedTweet {
+	return twitter.ReferencedTweet{Type: refType, ID: id}
+}
+
+// idAfter reports whether snowflake ID a is newer than b
+func idAfter(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
+}
+
+func sortNewestFirst(tweets []twitter.Tweet) {
+	sort.Slice(tweets, func(i, j int) bool {
+		return idAfter(tweets[i].ID, tweets[j].ID)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func invalidRequest(message string) map[string]interface{} {
+	return map[string]interface{}{
+		"errors": []map[string]interface{}{{"message": message}},
+		"title":  "Invalid Request",
+		"detail": "One or more parameters to your request was invalid.",
+		"type":   "https://api.twitter.com/2/problems/invalid-request",
+	}
+}
+
+func notFoundError(resourceType, id string) map[string]interface{} {
+	return map[string]interface{}{
+		"value":         id,
+		"detail":        fmt.Sprintf("Could not find %s with id: [%s].", resourceType, id),
+		"title":         "Not Found Error",
+		"resource_type": resourceType,
+		"resource_id":   id,
+		"type":          "https://api.twitter.com/2/problems/resource-not-found",
+	}
+}
+
+func notFound(resourceType, id string) map[string]interface{} {
+	return map[string]interface{}{
+		"errors": []map[string]interface{}{notFoundError(resourceType, id)},
+	}
+}